@@ -272,6 +272,10 @@ type StoreSpec struct {
 	EncryptionOptions []byte
 	// ProvisionedRateSpec is optional.
 	ProvisionedRateSpec ProvisionedRateSpec
+	// RaftLogPath, if set, is the directory of a separate storage engine that
+	// holds the raft log of the store, with its own WAL. It is typically placed
+	// on a different device than the store.
+	RaftLogPath string
 }
 
 // String returns a fully parsable version of the store spec.
@@ -314,6 +318,9 @@ func (ss StoreSpec) String() string {
 		fmt.Fprint(&buffer, optsStr)
 		fmt.Fprint(&buffer, ",")
 	}
+	if len(ss.RaftLogPath) > 0 {
+		fmt.Fprintf(&buffer, "raft-log-dir=%s,", ss.RaftLogPath)
+	}
 	if len(ss.ProvisionedRateSpec.DiskName) > 0 {
 		fmt.Fprintf(&buffer, "provisioned-rate=disk-name=%s",
 			ss.ProvisionedRateSpec.DiskName)
@@ -370,6 +377,8 @@ var fractionRegex = regexp.MustCompile(`^([-]?([0-9]+\.[0-9]*|[0-9]*\.[0-9]+|[0-
 //     provisioned-rate can be used for admission control for operations on the
 //     store. The bandwidth is optional, and if unspecified, a cluster setting
 //     (kvadmission.store.provisioned_bandwidth) will be used.
+//   - raft-log-dir=xxx The optional directory of a separate storage engine for
+//     the raft log of the store.
 //
 // Note that commas are forbidden within any field name or value.
 func NewStoreSpec(value string) (StoreSpec, error) {
@@ -499,6 +508,8 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 				return StoreSpec{}, err
 			}
 			ss.ProvisionedRateSpec = rateSpec
+		case "raft-log-dir":
+			ss.RaftLogPath = value
 
 		default:
 			return StoreSpec{}, fmt.Errorf("%s is not a valid store field", field)
@@ -515,8 +526,13 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 		if ss.BallastSize != nil {
 			return StoreSpec{}, fmt.Errorf("ballast-size specified for in memory store")
 		}
+		if ss.RaftLogPath != "" {
+			return StoreSpec{}, fmt.Errorf("raft-log-dir specified for in memory store")
+		}
 	} else if ss.Path == "" {
 		return StoreSpec{}, fmt.Errorf("no path specified")
+	} else if ss.RaftLogPath == ss.Path {
+		return StoreSpec{}, fmt.Errorf("raft-log-dir must differ from the store path")
 	}
 	return ss, nil
 }
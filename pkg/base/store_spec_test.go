@@ -158,6 +158,11 @@ target_file_size=2097152`
 			Path: "/mnt/hda1", ProvisionedRateSpec: base.ProvisionedRateSpec{
 				DiskName: "sdb", ProvisionedBandwidth: 0}}},
 
+		// raft log dir
+		{"path=/mnt/hda1,raft-log-dir=/mnt/nvme1", "", StoreSpec{Path: "/mnt/hda1", RaftLogPath: "/mnt/nvme1"}},
+		{"path=/mnt/hda1,raft-log-dir=/mnt/hda1", "raft-log-dir must differ from the store path", StoreSpec{}},
+		{"type=mem,size=20GiB,raft-log-dir=/mnt/nvme1", "raft-log-dir specified for in memory store", StoreSpec{}},
+
 		// RocksDB
 		{"path=/,rocksdb=key1=val1;key2=val2", "", StoreSpec{Path: "/", RocksDBOptions: "key1=val1;key2=val2"}},
 
@@ -0,0 +1,251 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// chunkGCGracePeriod is how long a chunk must have been recorded as a
+// candidate for deletion before the chunk GC deletes it. A deduplicated backup
+// only publishes the chunks that it uses when it checkpoints, and does not
+// reuse chunks that it found to be candidates, but it only rereads the
+// candidates every chunkGCCandidatesCacheTTL. A chunk that a running backup
+// reused before it became a candidate is thus deleted from under it if the
+// backup does not checkpoint within the grace period, which must therefore
+// comfortably exceed both the checkpoint interval and that TTL.
+var chunkGCGracePeriod = settings.RegisterDurationSetting(
+	settings.ApplicationLevel,
+	"bulkio.backup.chunk_gc.grace_period",
+	"the minimum time that a chunk of a deduplicated backup collection must be "+
+		"unreferenced before it is deleted",
+	72*time.Hour,
+	settings.NonNegativeDuration,
+)
+
+// backupChunkGCResumer deletes the chunks in the chunk pool of a collection
+// that no backup of the collection references.
+//
+// Deletion is two-phase. A run that finds no GC-CANDIDATES file in the pool
+// records the unreferenced chunks in it. A later run, at least the grace
+// period after the candidates were recorded, deletes the candidates that are
+// still unreferenced and records a new set of candidates.
+type backupChunkGCResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &backupChunkGCResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *backupChunkGCResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	details := r.job.Details().(jobspb.BackupChunkGCDetails)
+
+	collection, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, details.CollectionURI, p.User())
+	if err != nil {
+		return err
+	}
+	defer collection.Close()
+	poolURI, err := backuputils.AppendPaths([]string{details.CollectionURI}, backupbase.ChunkPoolSubdir)
+	if err != nil {
+		return err
+	}
+	pool, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, poolURI[0], p.User())
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	referenced, err := listReferencedChunks(ctx, collection)
+	if err != nil {
+		return err
+	}
+	var unreferenced []string
+	if err := pool.List(ctx, backupbase.ListingDelimDataSlash, "", func(p string) error {
+		name := backupbase.ListingDelimDataSlash + strings.TrimPrefix(p, "/")
+		if strings.HasSuffix(name, ".sst") && !referenced[name] {
+			unreferenced = append(unreferenced, name)
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "listing chunk pool")
+	}
+
+	now := timeutil.Now()
+	markedAt, candidates, err := readChunkGCCandidates(ctx, pool)
+	if err != nil {
+		return err
+	}
+	var deleted int
+	if !markedAt.IsZero() {
+		if grace := chunkGCGracePeriod.Get(&execCfg.Settings.SV); now.Sub(markedAt) < grace {
+			log.Infof(ctx, "chunk GC candidates of %s were recorded at %s, not deleting them before %s",
+				backuputils.RedactURIForErrorMessage(details.CollectionURI), markedAt, markedAt.Add(grace))
+			return r.updateProgress(ctx, len(referenced), 0)
+		}
+		isUnreferenced := make(map[string]bool, len(unreferenced))
+		for _, c := range unreferenced {
+			isUnreferenced[c] = true
+		}
+		for _, c := range candidates {
+			if !isUnreferenced[c] {
+				continue
+			}
+			if err := pool.Delete(ctx, c); err != nil {
+				return errors.Wrapf(err, "deleting chunk %s", c)
+			}
+			delete(isUnreferenced, c)
+			deleted++
+		}
+		unreferenced = unreferenced[:0]
+		for c := range isUnreferenced {
+			unreferenced = append(unreferenced, c)
+		}
+	}
+	if err := writeChunkGCCandidates(ctx, pool, now, unreferenced); err != nil {
+		return err
+	}
+	log.Infof(ctx, "chunk GC of %s deleted %d chunks, %d chunks are referenced and %d are candidates for deletion",
+		backuputils.RedactURIForErrorMessage(details.CollectionURI), deleted, len(referenced), len(unreferenced))
+	telemetry.Count("backup.chunk_gc.succeeded")
+	return r.updateProgress(ctx, len(referenced), deleted)
+}
+
+func (r *backupChunkGCResumer) updateProgress(ctx context.Context, referenced, deleted int) error {
+	return r.job.NoTxn().Update(ctx, func(txn isql.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+		md.Progress.Details = jobspb.WrapProgressDetails(jobspb.BackupChunkGCProgress{
+			ReferencedChunks: int64(referenced),
+			DeletedChunks:    int64(deleted),
+		})
+		ju.UpdateProgress(md.Progress)
+		return nil
+	})
+}
+
+// listReferencedChunks returns the chunks that the BACKUP-CHUNKS files of the
+// backups in a collection reference.
+func listReferencedChunks(
+	ctx context.Context, collection cloud.ExternalStorage,
+) (map[string]bool, error) {
+	var lists []string
+	if err := collection.List(ctx, "", backupbase.ListingDelimDataSlash, func(p string) error {
+		if path.Base(p) == backupbase.BackupChunksName {
+			lists = append(lists, strings.TrimPrefix(p, "/"))
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "listing backup collection")
+	}
+	referenced := make(map[string]bool)
+	for _, l := range lists {
+		chunks, err := backupinfo.ReadChunkList(ctx, collection, l)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", l)
+		}
+		for _, c := range chunks {
+			referenced[c] = true
+		}
+	}
+	return referenced, nil
+}
+
+// readChunkGCCandidates reads the GC-CANDIDATES file of a chunk pool. The
+// first line of the file is the time at which the candidates were recorded. It
+// returns a zero time if the file does not exist.
+func readChunkGCCandidates(
+	ctx context.Context, pool cloud.ExternalStorage,
+) (time.Time, []string, error) {
+	lines, err := backupinfo.ReadChunkList(ctx, pool, backupbase.ChunkGCCandidatesName)
+	if errors.Is(err, cloud.ErrFileDoesNotExist) {
+		return time.Time{}, nil, nil
+	} else if err != nil {
+		return time.Time{}, nil, err
+	}
+	if len(lines) == 0 {
+		return time.Time{}, nil, errors.Newf("%s is empty", backupbase.ChunkGCCandidatesName)
+	}
+	nanos, err := strconv.ParseInt(lines[0], 10, 64)
+	if err != nil {
+		return time.Time{}, nil, errors.Wrapf(err, "parsing %s", backupbase.ChunkGCCandidatesName)
+	}
+	return timeutil.Unix(0, nanos), lines[1:], nil
+}
+
+func writeChunkGCCandidates(
+	ctx context.Context, pool cloud.ExternalStorage, now time.Time, candidates []string,
+) error {
+	// The time sorts before the chunk names, which all start with "data/".
+	lines := append([]string{fmt.Sprint(now.UnixNano())}, candidates...)
+	return backupinfo.WriteChunkList(ctx, pool, backupbase.ChunkGCCandidatesName, lines)
+}
+
+// createBackupChunkGCJob creates a job that garbage collects the chunk pool of
+// a collection.
+func createBackupChunkGCJob(
+	ctx context.Context, execCfg *sql.ExecutorConfig, collectionURI string, user username.SQLUsername,
+) error {
+	jr := jobs.Record{
+		Description: fmt.Sprintf("GC backup chunks in %s",
+			backuputils.RedactURIForErrorMessage(collectionURI)),
+		Details:  jobspb.BackupChunkGCDetails{CollectionURI: collectionURI},
+		Progress: jobspb.BackupChunkGCProgress{},
+		Username: user,
+	}
+	return execCfg.InternalDB.Txn(ctx, func(ctx context.Context, txn isql.Txn) error {
+		jobID := execCfg.JobRegistry.MakeJobID()
+		if _, err := execCfg.JobRegistry.CreateAdoptableJobWithTxn(ctx, jr, jobID, txn); err != nil {
+			return err
+		}
+		log.Infof(ctx, "created job %d to GC backup chunks", jobID)
+		return nil
+	})
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. A failed chunk GC
+// leaves the pool in a state that the next run picks up from.
+func (r *backupChunkGCResumer) OnFailOrCancel(context.Context, interface{}, error) error {
+	return nil
+}
+
+// CollectProfile is part of the jobs.Resumer interface.
+func (r *backupChunkGCResumer) CollectProfile(context.Context, interface{}) error {
+	return nil
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeBackupChunkGC,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &backupChunkGCResumer{job: job}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
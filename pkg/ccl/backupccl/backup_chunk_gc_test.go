@@ -0,0 +1,97 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBackupDeduplicate checks that deduplicated full backups share the chunks
+// of unchanged data, can be restored, and that the chunk GC deletes the chunks
+// of backups that were removed from the collection.
+func TestBackupDeduplicate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, sqlDB, tempDir, cleanupFn := backupRestoreTestSetupWithParams(t, singleNode, numAccounts,
+		InitManualReplication, base.TestClusterArgs{
+			ServerArgs: base.TestServerArgs{
+				DefaultTestTenant: base.TODOTestTenantDisabled,
+				Knobs: base.TestingKnobs{
+					JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+				},
+			},
+		})
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.chunk_gc.grace_period = '0s'`)
+
+	collection := filepath.Join(tempDir, "foo")
+	countChunks := func() int {
+		entries, err := os.ReadDir(filepath.Join(collection, backupbase.ChunkPoolSubdir, "data"))
+		require.NoError(t, err)
+		return len(entries)
+	}
+	backupAndGC := func() {
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO $1 WITH deduplicate`, localFoo)
+		sqlDB.CheckQueryResultsRetry(t, `SELECT count(*) FROM [SHOW JOBS]
+WHERE job_type = 'BACKUP CHUNK GC' AND status != 'succeeded'`, [][]string{{"0"}})
+	}
+
+	backupAndGC()
+	chunks := countChunks()
+	require.Less(t, 0, chunks)
+
+	// A second full backup of the same data writes no new chunks.
+	backupAndGC()
+	require.Equal(t, chunks, countChunks())
+
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+	backupAndGC()
+	changed := countChunks()
+	require.Less(t, chunks, changed)
+
+	sqlDB.Exec(t, `CREATE DATABASE data2`)
+	sqlDB.Exec(t, `RESTORE TABLE data.bank FROM LATEST IN $1 WITH into_db = 'data2'`, localFoo)
+	sqlDB.CheckQueryResults(t, `SELECT count(*), sum(balance) FROM data2.bank`,
+		sqlDB.QueryStr(t, `SELECT count(*), sum(balance) FROM data.bank`))
+
+	// Remove the two backups that were taken before the update. The chunks that
+	// only they referenced are deleted by the second GC that runs after that.
+	var paths []string
+	for _, row := range sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, localFoo)[:2] {
+		paths = append(paths, row[0])
+	}
+	for _, p := range paths {
+		require.NoError(t, os.RemoveAll(filepath.Join(collection, strings.TrimPrefix(p, "/"))))
+	}
+	backupAndGC()
+	require.Equal(t, changed, countChunks())
+	backupAndGC()
+	backups := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, localFoo)
+	latest := strings.TrimPrefix(backups[len(backups)-1][0], "/")
+	referenced, err := os.ReadFile(filepath.Join(collection, latest, backupbase.BackupChunksName))
+	require.NoError(t, err)
+	require.Equal(t, len(strings.Fields(string(referenced))), countChunks())
+	require.Greater(t, changed, countChunks())
+
+	sqlDB.ExpectErr(t, "the deduplicate option requires the `BACKUP INTO <collection>` syntax",
+		`BACKUP DATABASE data TO $1 WITH deduplicate`, localFoo+"/to")
+}
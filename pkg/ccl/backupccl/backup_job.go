@@ -53,6 +53,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	bulkutil "github.com/cockroachdb/cockroach/pkg/util/bulk"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logutil"
@@ -198,6 +199,15 @@ func backup(
 		return roachpb.RowCount{}, 0, errors.Wrap(err, "failed to determine nodes on which to run")
 	}
 
+	var chunkPoolURI string
+	if backupManifest.ChunkPool != "" {
+		poolURIs, err := backuputils.AppendPaths([]string{defaultURI}, backupManifest.ChunkPool)
+		if err != nil {
+			return roachpb.RowCount{}, 0, err
+		}
+		chunkPoolURI = poolURIs[0]
+	}
+
 	job := resumer.job
 	backupSpecs, err := distBackupPlanSpecs(
 		ctx,
@@ -210,6 +220,7 @@ func backup(
 		pkIDs,
 		defaultURI,
 		urisByLocalityKV,
+		chunkPoolURI,
 		encryption,
 		&kmsEnv,
 		kvpb.MVCCFilter(backupManifest.MVCCFilter),
@@ -322,6 +333,13 @@ func backup(
 				if err != nil {
 					log.Errorf(ctx, "unable to checkpoint backup descriptor: %+v", err)
 				}
+				// Chunks that a deduplicated backup has written or reused so far
+				// must be visible to the chunk GC before the backup completes.
+				if backupManifest.ChunkPool != "" {
+					if err := backupinfo.WriteBackupChunks(ctx, defaultStore, backupManifest.Files); err != nil {
+						log.Errorf(ctx, "unable to checkpoint backup chunks: %+v", err)
+					}
+				}
 				lastCheckpoint = timeutil.Now()
 				if execCtx.ExecCfg().TestingKnobs.AfterBackupCheckpoint != nil {
 					execCtx.ExecCfg().TestingKnobs.AfterBackupCheckpoint()
@@ -415,6 +433,12 @@ func backup(
 		}
 	}
 
+	if backupManifest.ChunkPool != "" {
+		if err := backupinfo.WriteBackupChunks(ctx, defaultStore, backupManifest.Files); err != nil {
+			return roachpb.RowCount{}, 0, err
+		}
+	}
+
 	// Write a `BACKUP_MANIFEST` file to support backups in mixed-version clusters
 	// with 22.2 nodes.
 	//
@@ -555,6 +579,13 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		return err
 	}
 
+	if details.Compaction != nil {
+		return b.compactBackup(ctx, p, details)
+	}
+	if details.ChangeLog != nil {
+		return b.writeChangeLog(ctx, p, details)
+	}
+
 	kmsEnv := backupencryption.MakeBackupKMSEnv(
 		p.ExecCfg().Settings,
 		&p.ExecCfg().ExternalIODirConfig,
@@ -897,6 +928,15 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		}
 	}
 
+	// Now that the new full backup references the chunks it needs, the chunks
+	// of backups that were deleted since the last full backup can be GC'd. A
+	// failure to create the job only delays that until the next full backup.
+	if backupManifest.ChunkPool != "" {
+		if err := createBackupChunkGCJob(ctx, p.ExecCfg(), details.CollectionURI, p.User()); err != nil {
+			log.Warningf(ctx, "failed to create job to GC backup chunks: %v", err)
+		}
+	}
+
 	b.backupStats = res
 
 	// Collect telemetry.
@@ -927,6 +967,12 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		logutil.LogJobCompletion(ctx, b.getTelemetryEventType(), b.job.ID(), true, nil, res.Rows)
 	}
 
+	// A failure to kick off compaction of the chain should not fail the backup
+	// that was just written; the next incremental backup will try again.
+	if err := maybeCompactScheduledBackupChain(ctx, p.ExecCfg(), details, p.User()); err != nil {
+		log.Warningf(ctx, "failed to create job to compact backup chain: %v", err)
+	}
+
 	return b.maybeNotifyScheduledJobCompletion(
 		ctx, jobs.StatusSucceeded, p.ExecCfg().JobsKnobs(), p.ExecCfg().InternalDB,
 	)
@@ -1026,6 +1072,15 @@ func getBackupDetailAndManifest(
 			return jobspb.BackupDetails{}, backuppb.BackupManifest{}, err
 		}
 		defer mem.Shrink(ctx, memSize)
+
+		// Layers that have been compacted are replaced by the compacted layer,
+		// so that the new layer is planned against the chain a restore reads.
+		layers := backupinfo.ElideCompactedLayers(prevBackups, hlc.Timestamp{})
+		elided := make([]backuppb.BackupManifest, len(layers))
+		for i, layer := range layers {
+			elided[i] = prevBackups[layer]
+		}
+		prevBackups = elided
 	}
 
 	if len(prevBackups) > 0 {
@@ -1226,7 +1281,27 @@ func (b *backupResumer) OnFailOrCancel(
 	cfg := p.ExecCfg()
 	details := b.job.Details().(jobspb.BackupDetails)
 
-	b.deleteCheckpoint(ctx, cfg, p.User())
+	if details.Compaction != nil || details.ChangeLog != nil {
+		if err := releaseBackupLock(ctx, cfg, details, b.job.ID(), p.User()); err != nil {
+			log.Warningf(ctx, "failed to release backup lock: %+v", err)
+		}
+	} else {
+		b.deleteCheckpoint(ctx, cfg, p.User())
+	}
+	if details.Deduplicate {
+		// Release the chunks that this backup referenced so that the chunk GC can
+		// delete the ones that no other backup uses.
+		if err := func() error {
+			store, err := cfg.DistSQLSrv.ExternalStorageFromURI(ctx, details.URI, p.User())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			return store.Delete(ctx, backupbase.BackupChunksName)
+		}(); err != nil {
+			log.Warningf(ctx, "unable to delete backup chunk list: %+v", err)
+		}
+	}
 	if err := cfg.InternalDB.Txn(ctx, func(ctx context.Context, txn isql.Txn) error {
 		pts := cfg.ProtectedTimestampProvider.WithTxn(txn)
 		return releaseProtectedTimestamp(ctx, pts, details.ProtectedTimestampRecord)
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprotectedts"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
 	"github.com/cockroachdb/cockroach/pkg/multitenant/mtinfopb"
@@ -366,6 +367,47 @@ func backupPrivilegesDeprecationNotice(
 	return notice
 }
 
+// checkChangeLogOptions checks that a BACKUP with the change_log option, which
+// starts a job that writes the change log of a collection until it is
+// canceled, is used with options that the change log supports.
+func checkChangeLogOptions(
+	p sql.PlanHookState,
+	backupStmt *annotatedBackupStatement,
+	subdir string,
+	to []string,
+	incrementalStorage []string,
+	encryptionMode jobspb.EncryptionMode,
+	includeAllSecondaryTenants bool,
+	detached bool,
+) error {
+	if err := requireEnterprise(p.ExecCfg(), "change_log"); err != nil {
+		return err
+	}
+	if !backupStmt.Nested || backupStmt.AppendToLatest || subdir != "" {
+		return errors.New("the change_log option requires the `BACKUP INTO <collection>` syntax")
+	}
+	if !detached {
+		return errors.New("the change_log option requires the detached option, as its job runs until it is canceled")
+	}
+	if len(to) > 1 {
+		return errors.New("the change_log option is not supported with locality aware backups")
+	}
+	if len(incrementalStorage) > 0 {
+		return errors.New("the change_log option is not supported with the incremental_location option")
+	}
+	if encryptionMode != jobspb.EncryptionMode_None {
+		return errors.New("the change_log option is not supported with encrypted backups")
+	}
+	if includeAllSecondaryTenants || (backupStmt.Targets != nil && backupStmt.Targets.TenantID.IsSet()) {
+		return errors.New("the change_log option is not supported with backups of virtual clusters")
+	}
+	// The change log is written from a rangefeed on the targets.
+	if !kvserver.RangefeedEnabled.Get(&p.ExecCfg().Settings.SV) {
+		return errors.New("the change_log option requires the kv.rangefeed.enabled setting")
+	}
+	return nil
+}
+
 // checkPrivilegesForBackup is the driver method for privilege checks for all
 // flavours of backup. It checks that the user has sufficient privileges to read
 // the targets in the database, as well as use the External Storage URIs passed
@@ -534,6 +576,8 @@ func backupTypeCheck(
 			backupStmt.Options.CaptureRevisionHistory,
 			backupStmt.Options.IncludeAllSecondaryTenants,
 			backupStmt.Options.UpdatesClusterMonitoringMetrics,
+			backupStmt.Options.ChangeLog,
+			backupStmt.Options.Deduplicate,
 		}); err != nil {
 		return false, nil, err
 	}
@@ -670,6 +714,22 @@ func backupPlanHook(
 		}
 	}
 
+	var changeLog bool
+	if backupStmt.Options.ChangeLog != nil {
+		changeLog, err = exprEval.Bool(ctx, backupStmt.Options.ChangeLog)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	var deduplicate bool
+	if backupStmt.Options.Deduplicate != nil {
+		deduplicate, err = exprEval.Bool(ctx, backupStmt.Options.Deduplicate)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		// TODO(dan): Move this span into sql.
 		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
@@ -697,6 +757,29 @@ func backupPlanHook(
 			return errors.New("the include_all_virtual_clusters option is only supported for full cluster backups")
 		}
 
+		if changeLog {
+			if err := checkChangeLogOptions(p, backupStmt, subdir, to, incrementalStorage,
+				encryptionParams.Mode, includeAllSecondaryTenants, detached); err != nil {
+				return err
+			}
+		}
+
+		if deduplicate {
+			if err := requireEnterprise(p.ExecCfg(), "deduplicate"); err != nil {
+				return err
+			}
+			// The chunk pool is shared by the backups of a collection.
+			if !backupStmt.Nested {
+				return errors.New("the deduplicate option requires the `BACKUP INTO <collection>` syntax")
+			}
+			if len(to) > 1 {
+				return errors.New("the deduplicate option is not supported with locality aware backups")
+			}
+			if changeLog {
+				return errors.New("the deduplicate option is not supported with the change_log option")
+			}
+		}
+
 		var asOfInterval int64
 		endTime := p.ExecCfg().Clock.Now()
 		if backupStmt.AsOf.Expr != nil {
@@ -780,6 +863,18 @@ func backupPlanHook(
 		if backupStmt.CreatedByInfo != nil && backupStmt.CreatedByInfo.Name == jobs.CreatedByScheduledJobs {
 			initialDetails.ScheduleID = backupStmt.CreatedByInfo.ID
 		}
+		if changeLog {
+			// The change log is written as a sequence of layers with revision
+			// history, starting at the time of the statement.
+			initialDetails.ChangeLog = &jobspb.BackupDetails_ChangeLog{Frontier: endTime}
+			initialDetails.RevisionHistory = true
+			initialDetails.CollectionURI = to[0]
+			telemetry.Count("backup.change_log.started")
+		}
+		if deduplicate {
+			initialDetails.Deduplicate = true
+			telemetry.Count("backup.deduplicate.started")
+		}
 
 		// For backups of specific targets, those targets were resolved with this
 		// planner's session, so we need to store the result of resolution. For
@@ -1594,6 +1689,15 @@ func createBackupManifest(
 		StatisticsFilenames: statsFiles,
 		DescriptorCoverage:  coverage,
 	}
+	// Only the full backups of a deduplicated chain write their data to the
+	// chunk pool, as incremental backups rarely rewrite unchanged data.
+	if jobDetails.Deduplicate && startTime.IsEmpty() {
+		chunkPool, err := backupinfo.ChunkPoolPath(jobDetails.CollectionURI, jobDetails.URI)
+		if err != nil {
+			return backuppb.BackupManifest{}, err
+		}
+		backupManifest.ChunkPool = chunkPool
+	}
 	if err := checkCoverage(ctx, backupManifest.Spans, append(prevBackups, backupManifest)); err != nil {
 		return backuppb.BackupManifest{}, errors.Wrap(err, "new backup would not cover expected time")
 	}
@@ -359,6 +359,11 @@ func runBackupProcessor(
 			log.Infof(ctx, "backing up %d spans to default locality because backup localities %s have no match in node's localities %s", totalSpans, backupLocalities, nodeLocalities)
 		}
 	}
+	// Deduplicated backups write their data to the chunk pool of the collection.
+	// These are never locality aware, so this does not override a locality.
+	if spec.ChunkPoolURI != "" {
+		destURI = spec.ChunkPoolURI
+	}
 	if testingDiscardBackupData {
 		destURI = "null:///discard"
 	}
@@ -368,10 +373,12 @@ func runBackupProcessor(
 	}
 
 	sinkConf := sstSinkConf{
-		id:       flowCtx.NodeID.SQLInstanceID(),
-		enc:      spec.Encryption,
-		progCh:   progCh,
-		settings: &flowCtx.Cfg.Settings.SV,
+		id:               flowCtx.NodeID.SQLInstanceID(),
+		enc:              spec.Encryption,
+		progCh:           progCh,
+		settings:         &flowCtx.Cfg.Settings.SV,
+		contentAddressed: spec.ChunkPoolURI != "",
+		mon:              memAcc.Monitor(),
 	}
 	storage, err := flowCtx.Cfg.ExternalStorage(ctx, dest)
 	if err != nil {
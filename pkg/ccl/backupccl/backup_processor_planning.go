@@ -41,6 +41,7 @@ func distBackupPlanSpecs(
 	pkIDs map[uint64]bool,
 	defaultURI string,
 	urisByLocalityKV map[string]string,
+	chunkPoolURI string,
 	encryption *jobspb.BackupEncryptionOptions,
 	kmsEnv cloud.KMSEnv,
 	mvccFilter kvpb.MVCCFilter,
@@ -101,6 +102,7 @@ func distBackupPlanSpecs(
 			Spans:            partition.Spans,
 			DefaultURI:       defaultURI,
 			URIsByLocalityKV: urisByLocalityKV,
+			ChunkPoolURI:     chunkPoolURI,
 			MVCCFilter:       mvccFilter,
 			Encryption:       fileEncryption,
 			PKIDs:            pkIDs,
@@ -123,6 +125,7 @@ func distBackupPlanSpecs(
 				IntroducedSpans:  partition.Spans,
 				DefaultURI:       defaultURI,
 				URIsByLocalityKV: urisByLocalityKV,
+				ChunkPoolURI:     chunkPoolURI,
 				MVCCFilter:       mvccFilter,
 				Encryption:       fileEncryption,
 				PKIDs:            pkIDs,
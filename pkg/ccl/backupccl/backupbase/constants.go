@@ -56,6 +56,30 @@ const (
 	// incremental backups will be written.
 	DefaultIncrementalsSubdir = "incrementals"
 
+	// ChangeLogSubdir is the name of the subdirectory of a collection to which
+	// the change log of the collection is written. Each segment of the change
+	// log is written to <day>/<start>-<end> within it, where start and end are
+	// the wall times in nanoseconds that the segment covers.
+	ChangeLogSubdir = "changelog"
+
+	// ChangeLogDayFormat is the format of the day a change log segment ends in.
+	ChangeLogDayFormat = "20060102"
+
+	// ChunkPoolSubdir is the name of the subdirectory of a collection to which
+	// deduplicated full backups write their data, as chunks that are named after
+	// a hash of their content.
+	ChunkPoolSubdir = "chunks"
+
+	// BackupChunksName is the name of the file that lists the chunks of the
+	// chunk pool that a deduplicated backup references. It is not encrypted, so
+	// that the pool can be garbage collected without the keys of the backups.
+	BackupChunksName = "BACKUP-CHUNKS"
+
+	// ChunkGCCandidatesName is the name of the file in the chunk pool that lists
+	// the chunks that no backup referenced when the pool was last garbage
+	// collected.
+	ChunkGCCandidatesName = "GC-CANDIDATES"
+
 	// ListingDelimDataSlash is used when listing to find backups/backup metadata
 	// and groups all the data sst files in each backup, which start with "data/",
 	// into a single result that can be skipped over quickly.
@@ -511,6 +511,10 @@ func ListFullBackupsInCollection(
 // provided, it is inspected to see if it contains "appended" layers internally
 // that are then expanded into the result layers returned, similar to if those
 // layers had been specified in `from` explicitly.
+//
+// If changeLogURI is set and endTime is after the end of the backup chain, the
+// segments of the change log at changeLogURI that cover the time from the end
+// of the chain to endTime are appended to the result as further layers.
 func ResolveBackupManifests(
 	ctx context.Context,
 	mem *mon.BoundAccount,
@@ -519,6 +523,7 @@ func ResolveBackupManifests(
 	mkStore cloud.ExternalStorageFromURIFactory,
 	fullyResolvedBaseDirectory []string,
 	fullyResolvedIncrementalsDirectory []string,
+	changeLogURI string,
 	endTime hlc.Timestamp,
 	encryption *jobspb.BackupEncryptionOptions,
 	kmsEnv cloud.KMSEnv,
@@ -629,15 +634,55 @@ func ResolveBackupManifests(
 		}
 	}
 
+	if chainEnd := mainBackupManifests[numLayers-1].EndTime; changeLogURI != "" &&
+		chainEnd.Less(endTime) {
+		// The change log is written unencrypted, so it can only be replayed on
+		// top of unencrypted backups.
+		if encryption != nil {
+			return nil, nil, nil, 0, errors.WithHintf(
+				errors.Newf("invalid RESTORE timestamp: the backup chain ends at %s, and "+
+					"the change log of the collection can not be applied to encrypted backups",
+					timeutil.Unix(0, chainEnd.WallTime).UTC()),
+				"restore to a time at or before the end of the backup chain")
+		}
+		logURIs, logManifests, memSize, err := resolveChangeLogLayers(ctx, mem, mkStore,
+			changeLogURI, chainEnd, endTime, user)
+		if err != nil {
+			return nil, nil, nil, 0, err
+		}
+		ownedMemSize += memSize
+		defaultURIs = append(defaultURIs, logURIs...)
+		mainBackupManifests = append(mainBackupManifests, logManifests...)
+		localityInfo = append(localityInfo,
+			make([]jobspb.RestoreDetails_BackupLocalityInfo, len(logManifests))...)
+		numLayers = len(mainBackupManifests)
+	}
+
 	totalMemSize := ownedMemSize
 	ownedMemSize = 0
 
+	// Layers that have been compacted are replaced by the compacted layer.
+	if layers := backupinfo.ElideCompactedLayers(mainBackupManifests, endTime); len(layers) != numLayers {
+		elidedURIs := make([]string, len(layers))
+		elidedManifests := make([]backuppb.BackupManifest, len(layers))
+		elidedLocalityInfo := make([]jobspb.RestoreDetails_BackupLocalityInfo, len(layers))
+		for i, layer := range layers {
+			elidedURIs[i] = defaultURIs[layer]
+			elidedManifests[i] = mainBackupManifests[layer]
+			elidedLocalityInfo[i] = localityInfo[layer]
+		}
+		defaultURIs, mainBackupManifests, localityInfo = elidedURIs, elidedManifests, elidedLocalityInfo
+	}
+
 	validatedDefaultURIs, validatedMainBackupManifests, validatedLocalityInfo, err := backupinfo.ValidateEndTimeAndTruncate(
 		defaultURIs, mainBackupManifests, localityInfo, endTime)
 
 	if err != nil {
 		return nil, nil, nil, 0, err
 	}
+	if err := backupinfo.ResolveChunkPoolDirs(validatedMainBackupManifests, validatedDefaultURIs, user); err != nil {
+		return nil, nil, nil, 0, err
+	}
 	return validatedDefaultURIs, validatedMainBackupManifests, validatedLocalityInfo, totalMemSize, nil
 }
 
@@ -717,5 +762,8 @@ func DeprecatedResolveBackupManifestsExplicitIncrementals(
 	if err != nil {
 		return nil, nil, nil, 0, err
 	}
+	if err := backupinfo.ResolveChunkPoolDirs(validatedMainBackupManifests, validatedDefaultURIs, user); err != nil {
+		return nil, nil, nil, 0, err
+	}
 	return validatedDefaultURIs, validatedMainBackupManifests, validatedLocalityInfo, totalMemSize, nil
 }
@@ -0,0 +1,163 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupdest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// ChangeLogURI returns the URI of the change log of the collection.
+func ChangeLogURI(collectionURI string) (string, error) {
+	u, err := url.Parse(collectionURI)
+	if err != nil {
+		return "", err
+	}
+	u.Path = backuputils.JoinURLPath(u.Path, backupbase.ChangeLogSubdir)
+	return u.String(), nil
+}
+
+// ChangeLogSegmentPath returns the path of the segment of a change log that
+// covers the time from start to end, relative to the change log. Segments are
+// grouped by the day they end in, so that the segments needed to restore to a
+// time can be found by listing the days from the end of the backup chain
+// onwards.
+func ChangeLogSegmentPath(start, end hlc.Timestamp) string {
+	return fmt.Sprintf("%s/%d-%d",
+		timeutil.Unix(0, end.WallTime).UTC().Format(backupbase.ChangeLogDayFormat),
+		start.WallTime, end.WallTime)
+}
+
+type changeLogSegment struct {
+	path       string
+	start, end hlc.Timestamp
+}
+
+// findChangeLogSegments returns the segments of the change log in store that
+// form a contiguous chain from the segment containing from to the segment
+// containing endTime.
+func findChangeLogSegments(
+	ctx context.Context, store cloud.ExternalStorage, from, endTime hlc.Timestamp,
+) ([]changeLogSegment, error) {
+	ctx, sp := tracing.ChildSpan(ctx, "backupdest.findChangeLogSegments")
+	defer sp.Finish()
+
+	var segments []changeLogSegment
+	day := timeutil.Unix(0, from.WallTime).UTC().Truncate(24 * time.Hour)
+	last := timeutil.Now().UTC()
+	for reached := false; !reached && !day.After(last); day = day.AddDate(0, 0, 1) {
+		prefix := day.Format(backupbase.ChangeLogDayFormat) + "/"
+		if err := store.List(ctx, prefix, backupbase.ListingDelimDataSlash, func(p string) error {
+			dir, ok := strings.CutSuffix(strings.TrimPrefix(p, "/"), "/"+backupbase.BackupManifestName)
+			if !ok {
+				return nil
+			}
+			var startWall, endWall int64
+			if _, err := fmt.Sscanf(dir, "%d-%d", &startWall, &endWall); err != nil {
+				return nil //nolint:returnerrcheck
+			}
+			s := changeLogSegment{
+				path:  prefix + dir,
+				start: hlc.Timestamp{WallTime: startWall},
+				end:   hlc.Timestamp{WallTime: endWall},
+			}
+			if from.Less(s.end) {
+				segments = append(segments, s)
+				reached = reached || endTime.LessEq(s.end)
+			}
+			return nil
+		}); err != nil {
+			return nil, errors.Wrap(err, "listing change log segments")
+		}
+	}
+
+	// A segment may have been written twice, with different end times, if the
+	// job writing the change log was resumed after writing it but before
+	// recording its progress. Both copies contain all changes in their bounds,
+	// so pick the one reaching furthest at each step.
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].start.Less(segments[j].start)
+	})
+	var chain []changeLogSegment
+	cur := from
+	for cur.Less(endTime) {
+		next := -1
+		for i, s := range segments {
+			if s.start.LessEq(cur) && cur.Less(s.end) && (next == -1 || segments[next].end.Less(s.end)) {
+				next = i
+			}
+		}
+		if next == -1 {
+			return nil, errors.Errorf(
+				"invalid RESTORE timestamp: change log of the collection does not cover %s to %s",
+				timeutil.Unix(0, cur.WallTime).UTC(), timeutil.Unix(0, endTime.WallTime).UTC())
+		}
+		chain = append(chain, segments[next])
+		cur = segments[next].end
+	}
+	return chain, nil
+}
+
+// resolveChangeLogLayers returns the URIs and manifests of the segments of the
+// change log at changeLogURI that cover the time from the end of a backup chain
+// to endTime, so that they can be restored as layers on top of the chain. The
+// first segment starts at the end of the chain, since it only needs to replay
+// the changes after it.
+func resolveChangeLogLayers(
+	ctx context.Context,
+	mem *mon.BoundAccount,
+	mkStore cloud.ExternalStorageFromURIFactory,
+	changeLogURI string,
+	chainEnd, endTime hlc.Timestamp,
+	user username.SQLUsername,
+) ([]string, []backuppb.BackupManifest, int64, error) {
+	store, err := mkStore(ctx, changeLogURI, user)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer store.Close()
+
+	segments, err := findChangeLogSegments(ctx, store, chainEnd, endTime)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	base, err := url.Parse(changeLogURI)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	uris := make([]string, len(segments))
+	for i, s := range segments {
+		u := *base
+		u.Path = backuputils.JoinURLPath(u.Path, s.path)
+		uris[i] = u.String()
+	}
+	manifests, memSize, err := backupinfo.GetBackupManifests(ctx, mem, user, mkStore, uris,
+		nil /* encryption */, nil /* kmsEnv */)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	manifests[0].StartTime = chainEnd
+	return uris, manifests, memSize, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupinfo
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/util/ioctx"
+	"github.com/cockroachdb/errors"
+)
+
+// ChunkPoolPath returns the path of the chunk pool of the collection relative
+// to the directory of a backup in the collection.
+func ChunkPoolPath(collectionURI, backupURI string) (string, error) {
+	collection, err := url.Parse(collectionURI)
+	if err != nil {
+		return "", err
+	}
+	backup, err := url.Parse(backupURI)
+	if err != nil {
+		return "", err
+	}
+	collectionPath := strings.TrimSuffix(path.Clean("/"+collection.Path), "/")
+	backupPath := path.Clean("/" + backup.Path)
+	if collection.Scheme != backup.Scheme || collection.Host != backup.Host ||
+		!strings.HasPrefix(backupPath, collectionPath+"/") {
+		return "", errors.Newf("backup %s is not in collection %s",
+			backuputils.RedactURIForErrorMessage(backupURI),
+			backuputils.RedactURIForErrorMessage(collectionURI))
+	}
+	depth := strings.Count(strings.TrimPrefix(backupPath, collectionPath), "/")
+	return strings.Repeat("../", depth) + backupbase.ChunkPoolSubdir, nil
+}
+
+// ResolveChunkPoolDirs sets the ChunkPoolDir of each deduplicated manifest,
+// given the URIs of the directories that the manifests were read from.
+func ResolveChunkPoolDirs(
+	manifests []backuppb.BackupManifest, uris []string, user username.SQLUsername,
+) error {
+	for i := range manifests {
+		if manifests[i].ChunkPool == "" {
+			continue
+		}
+		poolURI, err := backuputils.AppendPaths([]string{uris[i]}, manifests[i].ChunkPool)
+		if err != nil {
+			return err
+		}
+		manifests[i].ChunkPoolDir, err = cloud.ExternalStorageConfFromURI(poolURI[0], user)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteBackupChunks writes the list of the chunks that the files of a
+// deduplicated backup reference to the directory of the backup.
+func WriteBackupChunks(
+	ctx context.Context, store cloud.ExternalStorage, files []backuppb.BackupManifest_File,
+) error {
+	chunks := make([]string, 0, len(files))
+	for i := range files {
+		chunks = append(chunks, files[i].Path)
+	}
+	return WriteChunkList(ctx, store, backupbase.BackupChunksName, chunks)
+}
+
+// WriteChunkList writes a sorted list of chunk paths, one per line.
+func WriteChunkList(
+	ctx context.Context, store cloud.ExternalStorage, filename string, chunks []string,
+) error {
+	sort.Strings(chunks)
+	var buf bytes.Buffer
+	for i, c := range chunks {
+		if i > 0 && chunks[i-1] == c {
+			continue
+		}
+		buf.WriteString(c)
+		buf.WriteByte('\n')
+	}
+	return cloud.WriteFile(ctx, store, filename, &buf)
+}
+
+// ReadChunkList reads a list of chunk paths written by WriteChunkList.
+func ReadChunkList(
+	ctx context.Context, store cloud.ExternalStorage, filename string,
+) ([]string, error) {
+	r, _, err := store.ReadFile(ctx, filename, cloud.ReadOptions{NoFileSize: true})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close(ctx)
+	data, err := ioctx.ReadAll(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
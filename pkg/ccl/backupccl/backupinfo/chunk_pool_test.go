@@ -0,0 +1,59 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupinfo_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkPoolPath(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		collection, backup string
+		expected, pool     string
+	}{
+		{
+			collection: "nodelocal://1/coll",
+			backup:     "nodelocal://1/coll/2023/10/19-120000.00",
+			expected:   "../../../chunks",
+			pool:       "nodelocal://1/coll/chunks",
+		},
+		{
+			collection: "s3://bucket/?AUTH=implicit",
+			backup:     "s3://bucket/daily?AUTH=implicit",
+			expected:   "../chunks",
+			pool:       "s3://bucket/chunks?AUTH=implicit",
+		},
+		{
+			collection: "nodelocal://1/coll/",
+			backup:     "nodelocal://1/coll/custom/subdir/",
+			expected:   "../../chunks",
+			pool:       "nodelocal://1/coll/chunks",
+		},
+	} {
+		t.Run(tc.backup, func(t *testing.T) {
+			rel, err := backupinfo.ChunkPoolPath(tc.collection, tc.backup)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, rel)
+
+			pool, err := backuputils.AppendPaths([]string{tc.backup}, rel)
+			require.NoError(t, err)
+			require.Equal(t, []string{tc.pool}, pool)
+		})
+	}
+
+	_, err := backupinfo.ChunkPoolPath("nodelocal://1/coll", "nodelocal://1/other/2023/10/19-120000.00")
+	require.ErrorContains(t, err, "is not in collection")
+}
@@ -952,6 +952,73 @@ func ValidateEndTimeAndTruncate(
 	)
 }
 
+// ElideCompactedLayers returns the indexes of the layers of a backup chain
+// that should be read to restore it as of endTime, or to its end if endTime is
+// empty. A chain that has been compacted contains both the compacted layer and
+// the layers it was built from, which share its start and end times. Walking
+// back from the last layer needed, the layer reaching furthest back is picked
+// each time so that compacted layers replace the layers they were built from.
+//
+// The full backup at index 0 is always returned. If the chain contains no
+// compacted layers, or no chain can be formed out of them, the indexes of all
+// layers are returned.
+func ElideCompactedLayers(manifests []backuppb.BackupManifest, endTime hlc.Timestamp) []int {
+	all := make([]int, len(manifests))
+	for i := range all {
+		all[i] = i
+	}
+	if len(manifests) <= 2 {
+		return all
+	}
+	ends := make(map[hlc.Timestamp]struct{}, len(manifests))
+	for _, m := range manifests {
+		ends[m.EndTime] = struct{}{}
+	}
+	if len(ends) == len(manifests) {
+		return all
+	}
+
+	// The chain needs to reach the end of the layer containing endTime, or the
+	// end of the last layer if there is no endTime.
+	var target hlc.Timestamp
+	for _, m := range manifests[1:] {
+		if endTime.IsEmpty() {
+			target.Forward(m.EndTime)
+		} else if endTime.LessEq(m.EndTime) && (target.IsEmpty() || m.EndTime.Less(target)) {
+			target = m.EndTime
+		}
+	}
+	if target.IsEmpty() {
+		return all
+	}
+
+	var chain []int
+	for cur := target; manifests[0].EndTime.Less(cur); {
+		next := -1
+		for i := 1; i < len(manifests); i++ {
+			m := manifests[i]
+			if !m.EndTime.Equal(cur) || m.StartTime.Less(manifests[0].EndTime) {
+				continue
+			}
+			if next == -1 || m.StartTime.Less(manifests[next].StartTime) {
+				next = i
+			}
+		}
+		if next == -1 || !manifests[next].StartTime.Less(cur) {
+			return all
+		}
+		chain = append(chain, next)
+		cur = manifests[next].StartTime
+	}
+
+	res := make([]int, 0, len(chain)+1)
+	res = append(res, 0)
+	for i := len(chain) - 1; i >= 0; i-- {
+		res = append(res, chain[i])
+	}
+	return res
+}
+
 // GetBackupIndexAtTime returns the index of the latest backup in
 // `backupManifests` with a StartTime >= asOf.
 func GetBackupIndexAtTime(
@@ -287,3 +287,78 @@ func mustCreateFileIterFactory(
 		return it
 	}
 }
+
+// TestElideCompactedLayers tests that the layers a compacted layer was built
+// from are elided from a chain of backups.
+func TestElideCompactedLayers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	layer := func(start, end int64) backuppb.BackupManifest {
+		return backuppb.BackupManifest{
+			StartTime: hlc.Timestamp{WallTime: start},
+			EndTime:   hlc.Timestamp{WallTime: end},
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		layers   []backuppb.BackupManifest
+		endTime  int64
+		expected []int
+	}{
+		{
+			name:     "full only",
+			layers:   []backuppb.BackupManifest{layer(0, 10)},
+			expected: []int{0},
+		},
+		{
+			name: "not compacted",
+			layers: []backuppb.BackupManifest{
+				layer(0, 10), layer(10, 20), layer(20, 30),
+			},
+			expected: []int{0, 1, 2},
+		},
+		{
+			name: "compacted",
+			layers: []backuppb.BackupManifest{
+				layer(0, 10), layer(10, 20), layer(20, 30), layer(30, 40), layer(10, 40),
+			},
+			expected: []int{0, 4},
+		},
+		{
+			name: "compacted then appended",
+			layers: []backuppb.BackupManifest{
+				layer(0, 10), layer(10, 20), layer(20, 30), layer(10, 30), layer(30, 40),
+			},
+			expected: []int{0, 3, 4},
+		},
+		{
+			name: "compacted middle",
+			layers: []backuppb.BackupManifest{
+				layer(0, 10), layer(10, 20), layer(20, 30), layer(30, 40), layer(20, 40), layer(40, 50),
+			},
+			expected: []int{0, 1, 4, 5},
+		},
+		{
+			name: "end time within compacted layer",
+			layers: []backuppb.BackupManifest{
+				layer(0, 10), layer(10, 20), layer(20, 30), layer(10, 30), layer(30, 40),
+			},
+			endTime:  15,
+			expected: []int{0, 1},
+		},
+		{
+			name: "end time after compacted layer",
+			layers: []backuppb.BackupManifest{
+				layer(0, 10), layer(10, 20), layer(20, 30), layer(10, 30), layer(30, 40),
+			},
+			endTime:  30,
+			expected: []int{0, 3},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected,
+				backupinfo.ElideCompactedLayers(tc.layers, hlc.Timestamp{WallTime: tc.endTime}))
+		})
+	}
+}
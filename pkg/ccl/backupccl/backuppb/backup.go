@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudpb"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/multitenant/mtinfopb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -91,6 +92,16 @@ func (m *BackupManifest) HasTenants() bool {
 	return len(m.Tenants) > 0 || len(m.TenantsDeprecated) > 0
 }
 
+// DataDir returns the location that the paths of the files of the backup are
+// relative to, which is the chunk pool of its collection if it is
+// deduplicated.
+func (m *BackupManifest) DataDir() cloudpb.ExternalStorage {
+	if m.ChunkPool != "" {
+		return m.ChunkPoolDir
+	}
+	return m.Dir
+}
+
 // MarshalJSONPB implements jsonpb.JSONPBMarshaller to provide a custom Marshaller
 // for jsonpb that redacts secrets in URI fields.
 func (m ScheduledBackupExecutionArgs) MarshalJSONPB(marshaller *jsonpb.Marshaler) ([]byte, error) {
@@ -0,0 +1,853 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupresolver"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// changeLogFlushInterval is how often a change log job writes a segment, and so
+// how far behind the present the latest time that can be restored from the
+// change log is.
+var changeLogFlushInterval = settings.RegisterDurationSetting(
+	settings.ApplicationLevel,
+	"backup.change_log.flush_interval",
+	"the time between the segments of the change log written by a BACKUP with the change_log option",
+	10*time.Second,
+	settings.PositiveDuration,
+)
+
+// changeLogMaxBufferSize bounds the memory a change log job uses to buffer the
+// changes of a segment until it is written.
+var changeLogMaxBufferSize = settings.RegisterByteSizeSetting(
+	settings.ApplicationLevel,
+	"backup.change_log.max_buffer_size",
+	"the maximum amount of memory a BACKUP with the change_log option uses to buffer changes "+
+		"until they are written to the change log",
+	64<<20, // 64 MiB
+)
+
+// changeLogCompactionThreshold is the number of segments a change log job
+// writes before it merges them into one, so that a restore from the change log
+// has fewer layers to read.
+var changeLogCompactionThreshold = settings.RegisterIntSetting(
+	settings.ApplicationLevel,
+	"backup.change_log.compaction_threshold",
+	"number of segments of the change log written by a BACKUP with the change_log option "+
+		"after which they are merged into one; 0 disables compaction",
+	100,
+	settings.NonNegativeInt,
+)
+
+// errChangeLogSpansChanged is returned when a segment of the change log has to
+// cover spans that the rangefeed of the job does not watch, e.g. because a
+// table was created in a backed up database, so that the rangefeed is restarted
+// with them.
+var errChangeLogSpansChanged = errors.New("spans of the change log changed")
+
+// errChangeLogBufferFull is returned when the changes buffered for the next
+// segment of the change log exceed backup.change_log.max_buffer_size. The job
+// writes what it can and restarts the rangefeed from the new frontier, or fails
+// if the frontier can not advance.
+var errChangeLogBufferFull = errors.New("change log buffer is full")
+
+// writeChangeLog runs the job of a BACKUP with the change_log option. It
+// watches the targets of the backup with a rangefeed, and every
+// backup.change_log.flush_interval writes the changes up to the resolved
+// timestamp of the rangefeed to the change log of the collection as a segment,
+// which is a backup layer with revision history. A RESTORE AS OF SYSTEM TIME
+// later than the last backup of a chain in the collection restores the segments
+// that cover the time since on top of the chain. The job runs until it is
+// canceled.
+func (b *backupResumer) writeChangeLog(
+	ctx context.Context, p sql.JobExecContext, details jobspb.BackupDetails,
+) error {
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.writeChangeLog")
+	defer span.Finish()
+
+	execCfg := p.ExecCfg()
+	w := &changeLogWriter{
+		execCfg:  execCfg,
+		job:      b.job,
+		user:     p.User(),
+		details:  details,
+		frontier: details.ChangeLog.Frontier,
+		errCh:    make(chan error, 1),
+	}
+
+	memMon := mon.NewMonitorInheritWithLimit("backup-change-log",
+		changeLogMaxBufferSize.Get(&execCfg.Settings.SV), execCfg.RootMemoryMonitor)
+	memMon.StartNoReserved(ctx, execCfg.RootMemoryMonitor)
+	defer memMon.Stop(ctx)
+	w.mu.acc = memMon.MakeBoundAccount()
+	defer func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.mu.acc.Close(ctx)
+	}()
+
+	spans, err := w.targetSpans(ctx, w.frontier)
+	if err != nil {
+		return err
+	}
+	w.spans, w.prevSpans = spans, spans
+
+	// Lay claim to the change log of the collection, and protect the data that
+	// the rangefeed has yet to read from garbage collection, the first time the
+	// job runs.
+	if details.URI == "" {
+		uri, err := backupdest.ChangeLogURI(details.CollectionURI)
+		if err != nil {
+			return err
+		}
+		w.details.URI = uri
+		if err := backupinfo.CheckForPreviousBackup(ctx, execCfg, uri, b.job.ID(),
+			p.User()); err != nil {
+			return errors.Wrap(err, "the change log of the collection is already being written")
+		}
+		if err := backupinfo.WriteBackupLock(ctx, execCfg, uri, b.job.ID(),
+			p.User()); err != nil {
+			return err
+		}
+
+		descs, completeDBs, err := changeLogTargets(ctx, execCfg, details, w.frontier)
+		if err != nil {
+			return err
+		}
+		m := backuppb.BackupManifest{
+			EndTime:            w.frontier,
+			Spans:              spans,
+			CompleteDbs:        completeDBs,
+			DescriptorCoverage: changeLogCoverage(details),
+		}
+		for _, desc := range descs {
+			m.Descriptors = append(m.Descriptors, *desc.DescriptorProto())
+		}
+		protectedtsID := uuid.MakeV4()
+		w.details.ProtectedTimestampRecord = &protectedtsID
+		if err := execCfg.InternalDB.Txn(ctx, func(ctx context.Context, txn isql.Txn) error {
+			ptp := execCfg.ProtectedTimestampProvider.WithTxn(txn)
+			if err := protectTimestampForBackup(ctx, b.job.ID(), ptp, &m, w.details); err != nil {
+				return err
+			}
+			return b.job.WithTxn(txn).SetDetails(ctx, w.details)
+		}); err != nil {
+			return err
+		}
+	}
+
+	var scanSpans []roachpb.Span
+	for {
+		prevFrontier := w.frontier
+		err := w.runFeed(ctx, scanSpans)
+		switch {
+		case errors.Is(err, errChangeLogSpansChanged):
+			scanSpans = w.newSpans
+			log.Infof(ctx, "restarting the change log rangefeed at %s to watch %d new spans",
+				w.frontier, len(scanSpans))
+		case errors.Is(err, errChangeLogBufferFull):
+			// The events that did not fit in the buffer were dropped, so the
+			// rangefeed has to start over from the frontier, which must have
+			// advanced for the job to make progress.
+			if !prevFrontier.Less(w.frontier) {
+				return errors.WithHintf(err,
+					"increase %s or decrease %s", changeLogMaxBufferSize.Name(),
+					changeLogFlushInterval.Name())
+			}
+			scanSpans = nil
+			log.Infof(ctx, "restarting the change log rangefeed at %s after its buffer filled up",
+				w.frontier)
+		default:
+			return err
+		}
+	}
+}
+
+// changeLogTargets returns the descriptors that a segment of the change log
+// ending at asOf contains, along with the databases that are backed up in
+// full. These are the targets of the backup as of asOf, which includes tables
+// created in a backed up database since the job started.
+func changeLogTargets(
+	ctx context.Context, execCfg *sql.ExecutorConfig, details jobspb.BackupDetails, asOf hlc.Timestamp,
+) ([]catalog.Descriptor, []descpb.ID, error) {
+	if details.FullCluster {
+		return fullClusterTargetsBackup(ctx, execCfg, asOf)
+	}
+	allDescs, err := backupresolver.LoadAllDescs(ctx, execCfg, asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved := make(map[descpb.ID]struct{}, len(details.ResolvedTargets))
+	for i := range details.ResolvedTargets {
+		id, _, _, _, _ := descpb.GetDescriptorMetadata(&details.ResolvedTargets[i])
+		resolved[id] = struct{}{}
+	}
+	completeDBs := make(map[descpb.ID]struct{}, len(details.ResolvedCompleteDbs))
+	for _, id := range details.ResolvedCompleteDbs {
+		completeDBs[id] = struct{}{}
+	}
+	var descs []catalog.Descriptor
+	for _, desc := range allDescs {
+		if desc.Dropped() {
+			continue
+		}
+		_, isTarget := resolved[desc.GetID()]
+		_, inCompleteDB := completeDBs[desc.GetParentID()]
+		if isTarget || inCompleteDB {
+			descs = append(descs, desc)
+		}
+	}
+	return descs, details.ResolvedCompleteDbs, nil
+}
+
+func changeLogCoverage(details jobspb.BackupDetails) tree.DescriptorCoverage {
+	if details.FullCluster {
+		return tree.AllDescriptors
+	}
+	return tree.RequestedDescriptors
+}
+
+// changeLogWriter buffers the events of the rangefeed of a change log job and
+// writes them to the segments of the change log.
+type changeLogWriter struct {
+	execCfg *sql.ExecutorConfig
+	job     *jobs.Job
+	user    username.SQLUsername
+	details jobspb.BackupDetails
+
+	// frontier is the end time of the last segment that was written.
+	frontier hlc.Timestamp
+	// spans are the spans that the rangefeed watches, and newSpans the spans
+	// that were added to them when the rangefeed was last restarted.
+	spans, newSpans []roachpb.Span
+	// prevSpans are the spans of the last segment that was written.
+	prevSpans []roachpb.Span
+
+	// segments are the segments that were written since the change log was
+	// last compacted.
+	segments []backuppb.BackupManifest
+
+	errCh chan error
+
+	mu struct {
+		syncutil.Mutex
+		points    []storage.MVCCKeyValue
+		rangeKeys []storage.MVCCRangeKeyValue
+		// acc accounts for the memory of the buffered events.
+		acc mon.BoundAccount
+		// full is set when an event did not fit in the buffer and was dropped.
+		// The resolved timestamp no longer advances, so that the events up to it
+		// can still be written.
+		full bool
+		// resolved is the time up to which the rangefeed delivered all events.
+		resolved hlc.Timestamp
+	}
+}
+
+// targetSpans returns the spans of the targets of the backup as of asOf.
+func (w *changeLogWriter) targetSpans(ctx context.Context, asOf hlc.Timestamp) ([]roachpb.Span, error) {
+	descs, _, err := changeLogTargets(ctx, w.execCfg, w.details, asOf)
+	if err != nil {
+		return nil, err
+	}
+	var tables []catalog.TableDescriptor
+	for _, desc := range descs {
+		if t, ok := desc.(catalog.TableDescriptor); ok {
+			tables = append(tables, t)
+		}
+	}
+	return spansForAllTableIndexes(w.execCfg, tables, nil /* revs */)
+}
+
+func (w *changeLogWriter) setError(err error) {
+	select {
+	case w.errCh <- err:
+	default:
+	}
+}
+
+// growLocked reserves memory for size bytes of buffered events, and returns
+// false if the buffer is full and the events must be dropped.
+func (w *changeLogWriter) growLocked(ctx context.Context, size int64) bool {
+	if w.mu.full {
+		return false
+	}
+	if err := w.mu.acc.Grow(ctx, size); err != nil {
+		w.mu.full = true
+		w.setError(errors.Mark(err, errChangeLogBufferFull))
+		return false
+	}
+	return true
+}
+
+func changeLogPointSize(kv storage.MVCCKeyValue) int64 {
+	return int64(kv.Key.EncodedSize() + len(kv.Value))
+}
+
+func changeLogRangeKeySize(rkv storage.MVCCRangeKeyValue) int64 {
+	return int64(rkv.RangeKey.EncodedSize() + len(rkv.Value))
+}
+
+// runFeed runs a rangefeed on the spans of the writer from its frontier, and
+// writes a segment every backup.change_log.flush_interval until it fails or the
+// spans of the change log change. The scanSpans, which were just added to the
+// spans, are first scanned as of the frontier, since their data up to the
+// frontier is not in any earlier segment.
+func (w *changeLogWriter) runFeed(ctx context.Context, scanSpans []roachpb.Span) error {
+	w.mu.Lock()
+	w.mu.points, w.mu.rangeKeys, w.mu.resolved = nil, nil, w.frontier
+	w.mu.full = false
+	w.mu.acc.Clear(ctx)
+	w.mu.Unlock()
+
+	onInternalError := func(ctx context.Context, err error) {
+		w.setError(err)
+	}
+	feed, err := w.execCfg.RangeFeedFactory.RangeFeed(ctx, "backup-change-log", w.spans, w.frontier,
+		w.onValue,
+		rangefeed.WithOnDeleteRange(w.onDeleteRange),
+		rangefeed.WithOnSSTable(w.onSSTable),
+		rangefeed.WithOnFrontierAdvance(w.onFrontierAdvance),
+		rangefeed.WithOnInternalError(onInternalError),
+	)
+	if err != nil {
+		return err
+	}
+	defer feed.Close()
+
+	scanned := make(chan struct{})
+	if len(scanSpans) > 0 {
+		// Events after the frontier are also delivered by the main rangefeed, and
+		// dropped as duplicates when they are written.
+		scan, err := w.execCfg.RangeFeedFactory.RangeFeed(ctx, "backup-change-log-scan", scanSpans,
+			w.frontier, w.onValue,
+			rangefeed.WithInitialScan(func(ctx context.Context) { close(scanned) }),
+			rangefeed.WithRowTimestampInInitialScan(true),
+			rangefeed.WithOnInternalError(onInternalError),
+		)
+		if err != nil {
+			return err
+		}
+		defer scan.Close()
+	} else {
+		close(scanned)
+	}
+
+	var timer timeutil.Timer
+	defer timer.Stop()
+	timer.Reset(changeLogFlushInterval.Get(&w.execCfg.Settings.SV))
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-w.errCh:
+			if errors.Is(err, errChangeLogBufferFull) {
+				// Write the events up to the resolved timestamp, which stopped
+				// advancing when the buffer filled up, so that the rangefeed can
+				// restart from there.
+				select {
+				case <-scanned:
+					if err := w.maybeFlush(ctx); err != nil {
+						return err
+					}
+				default:
+				}
+			}
+			return err
+		case <-timer.C:
+			timer.Read = true
+			timer.Reset(changeLogFlushInterval.Get(&w.execCfg.Settings.SV))
+			select {
+			case <-scanned:
+			default:
+				continue
+			}
+			if err := w.maybeFlush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *changeLogWriter) onValue(ctx context.Context, v *kvpb.RangeFeedValue) {
+	value, err := storage.EncodeMVCCValue(storage.MVCCValue{Value: v.Value})
+	if err != nil {
+		w.setError(err)
+		return
+	}
+	kv := storage.MVCCKeyValue{
+		Key:   storage.MVCCKey{Key: v.Key, Timestamp: v.Value.Timestamp},
+		Value: value,
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.growLocked(ctx, changeLogPointSize(kv)) {
+		return
+	}
+	w.mu.points = append(w.mu.points, kv)
+}
+
+func (w *changeLogWriter) onDeleteRange(ctx context.Context, d *kvpb.RangeFeedDeleteRange) {
+	value, err := storage.EncodeMVCCValue(storage.MVCCValue{})
+	if err != nil {
+		w.setError(err)
+		return
+	}
+	rkv := storage.MVCCRangeKeyValue{
+		RangeKey: storage.MVCCRangeKey{
+			StartKey:  d.Span.Key,
+			EndKey:    d.Span.EndKey,
+			Timestamp: d.Timestamp,
+		},
+		Value: value,
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.growLocked(ctx, changeLogRangeKeySize(rkv)) {
+		return
+	}
+	w.mu.rangeKeys = append(w.mu.rangeKeys, rkv)
+}
+
+// onSSTable buffers the keys of an ingested SST. The SST may contain keys
+// outside of the span that the rangefeed was registered on, which are skipped.
+func (w *changeLogWriter) onSSTable(
+	ctx context.Context, sst *kvpb.RangeFeedSSTable, registeredSpan roachpb.Span,
+) {
+	iter, err := storage.NewMemSSTIterator(sst.Data, false /* verify */, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsAndRanges,
+		LowerBound: registeredSpan.Key,
+		UpperBound: registeredSpan.EndKey,
+	})
+	if err != nil {
+		w.setError(err)
+		return
+	}
+	defer iter.Close()
+
+	var points []storage.MVCCKeyValue
+	var rangeKeys []storage.MVCCRangeKeyValue
+	var size int64
+	for iter.SeekGE(storage.MVCCKey{Key: registeredSpan.Key}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			w.setError(err)
+			return
+		} else if !ok {
+			break
+		}
+		hasPoint, hasRange := iter.HasPointAndRange()
+		if hasRange && iter.RangeKeyChanged() {
+			rks := iter.RangeKeys()
+			for _, v := range rks.Versions {
+				rkv := storage.MVCCRangeKeyValue{
+					RangeKey: rks.AsRangeKey(v).Clone(),
+					Value:    append([]byte(nil), v.Value...),
+				}
+				rangeKeys = append(rangeKeys, rkv)
+				size += changeLogRangeKeySize(rkv)
+			}
+		}
+		if hasPoint {
+			v, err := iter.UnsafeValue()
+			if err != nil {
+				w.setError(err)
+				return
+			}
+			kv := storage.MVCCKeyValue{
+				Key:   iter.UnsafeKey().Clone(),
+				Value: append([]byte(nil), v...),
+			}
+			points = append(points, kv)
+			size += changeLogPointSize(kv)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.growLocked(ctx, size) {
+		return
+	}
+	w.mu.points = append(w.mu.points, points...)
+	w.mu.rangeKeys = append(w.mu.rangeKeys, rangeKeys...)
+}
+
+func (w *changeLogWriter) onFrontierAdvance(ctx context.Context, ts hlc.Timestamp) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.mu.full {
+		return
+	}
+	w.mu.resolved.Forward(ts)
+}
+
+// takeEvents removes the buffered events at or before end from the buffer and
+// returns them, along with the memory they use, which the caller releases once
+// it no longer needs them.
+func (w *changeLogWriter) takeEvents(
+	end hlc.Timestamp,
+) ([]storage.MVCCKeyValue, []storage.MVCCRangeKeyValue, int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var size int64
+	var points, keptPoints []storage.MVCCKeyValue
+	for _, kv := range w.mu.points {
+		if kv.Key.Timestamp.LessEq(end) {
+			points = append(points, kv)
+			size += changeLogPointSize(kv)
+		} else {
+			keptPoints = append(keptPoints, kv)
+		}
+	}
+	var rangeKeys, keptRangeKeys []storage.MVCCRangeKeyValue
+	for _, rkv := range w.mu.rangeKeys {
+		if rkv.RangeKey.Timestamp.LessEq(end) {
+			rangeKeys = append(rangeKeys, rkv)
+			size += changeLogRangeKeySize(rkv)
+		} else {
+			keptRangeKeys = append(keptRangeKeys, rkv)
+		}
+	}
+	w.mu.points, w.mu.rangeKeys = keptPoints, keptRangeKeys
+	return points, rangeKeys, size
+}
+
+// releaseEvents releases the memory of events returned by takeEvents.
+func (w *changeLogWriter) releaseEvents(ctx context.Context, size int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.mu.acc.Shrink(ctx, size)
+}
+
+// maybeFlush writes a segment with the changes from the frontier of the writer
+// to the resolved timestamp of the rangefeed, if it advanced.
+func (w *changeLogWriter) maybeFlush(ctx context.Context) error {
+	w.mu.Lock()
+	resolved := w.mu.resolved
+	w.mu.Unlock()
+
+	// Segments are named after the wall times of their bounds, so they end on
+	// a wall time.
+	end := hlc.Timestamp{WallTime: resolved.WallTime}
+	if end.LessEq(w.frontier) {
+		return nil
+	}
+
+	manifest, err := w.makeSegmentManifest(ctx, w.frontier, end)
+	if err != nil {
+		return err
+	}
+	var watched roachpb.SpanGroup
+	watched.Add(w.spans...)
+	if !watched.Encloses(manifest.Spans...) {
+		w.newSpans = filterSpans(manifest.Spans, w.spans)
+		watched.Add(manifest.Spans...)
+		w.spans = watched.Slice()
+		return errChangeLogSpansChanged
+	}
+
+	points, rangeKeys, size := w.takeEvents(end)
+	defer w.releaseEvents(ctx, size)
+
+	u, err := url.Parse(w.details.URI)
+	if err != nil {
+		return err
+	}
+	u.Path = backuputils.JoinURLPath(u.Path, backupdest.ChangeLogSegmentPath(w.frontier, end))
+	store, err := w.execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, u.String(), w.user)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := w.writeSegmentData(ctx, store, &manifest, points, rangeKeys); err != nil {
+		return err
+	}
+	manifest.Dir = store.Conf()
+	if err := backupinfo.WriteBackupManifest(ctx, store, backupbase.BackupManifestName,
+		nil /* encryption */, nil /* kmsEnv */, &manifest); err != nil {
+		return err
+	}
+	log.VEventf(ctx, 2, "wrote change log segment from %s to %s with %d keys",
+		w.frontier, end, len(points))
+
+	w.details.ChangeLog.Frontier = end
+	if err := w.execCfg.InternalDB.Txn(ctx, func(ctx context.Context, txn isql.Txn) error {
+		if err := w.job.WithTxn(txn).SetDetails(ctx, w.details); err != nil {
+			return err
+		}
+		pts := w.execCfg.ProtectedTimestampProvider.WithTxn(txn)
+		return pts.UpdateTimestamp(ctx, *w.details.ProtectedTimestampRecord, end)
+	}); err != nil {
+		return err
+	}
+	w.frontier = end
+	w.prevSpans = manifest.Spans
+
+	w.segments = append(w.segments, manifest)
+	if threshold := changeLogCompactionThreshold.Get(&w.execCfg.Settings.SV); threshold > 0 &&
+		int64(len(w.segments)) >= threshold {
+		// A failed compaction leaves the segments it would have merged in place,
+		// so it is not worth failing the job for.
+		if err := w.compactSegments(ctx); err != nil {
+			log.Warningf(ctx, "failed to compact %d change log segments: %v", len(w.segments), err)
+		}
+		w.segments = nil
+	}
+	return nil
+}
+
+// compactSegments merges the segments that were written since the last
+// compaction into one segment that spans their times. Restores from the change
+// log prefer the segment that reaches furthest, so they read the merged segment
+// in place of the ones it was built from, which are left for restores that are
+// already reading them.
+func (w *changeLogWriter) compactSegments(ctx context.Context) error {
+	if len(w.segments) < 2 {
+		return nil
+	}
+	first, last := w.segments[0], w.segments[len(w.segments)-1]
+	u, err := url.Parse(w.details.URI)
+	if err != nil {
+		return err
+	}
+	u.Path = backuputils.JoinURLPath(u.Path,
+		backupdest.ChangeLogSegmentPath(first.StartTime, last.EndTime))
+	store, err := w.execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, u.String(), w.user)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	manifest, err := mergeBackupLayers(ctx, w.execCfg, store,
+		compactionLayers{manifests: w.segments}, nil /* encryption */, nil /* kmsEnv */)
+	if err != nil {
+		return err
+	}
+	if err := backupinfo.WriteBackupManifest(ctx, store, backupbase.BackupManifestName,
+		nil /* encryption */, nil /* kmsEnv */, manifest); err != nil {
+		return err
+	}
+	log.Infof(ctx, "compacted %d change log segments from %s to %s",
+		len(w.segments), first.StartTime, last.EndTime)
+	return nil
+}
+
+// makeSegmentManifest returns the manifest of a segment of the change log from
+// start to end, without its files.
+func (w *changeLogWriter) makeSegmentManifest(
+	ctx context.Context, start, end hlc.Timestamp,
+) (backuppb.BackupManifest, error) {
+	descs, completeDBs, err := changeLogTargets(ctx, w.execCfg, w.details, end)
+	if err != nil {
+		return backuppb.BackupManifest{}, err
+	}
+	descriptorProtos := make([]descpb.Descriptor, 0, len(descs))
+	var tables []catalog.TableDescriptor
+	for _, desc := range descs {
+		descriptorProtos = append(descriptorProtos, *desc.DescriptorProto())
+		if t, ok := desc.(catalog.TableDescriptor); ok {
+			tables = append(tables, t)
+		}
+	}
+
+	priorIDs := make(map[descpb.ID]descpb.ID)
+	revs, err := getRelevantDescChanges(ctx, w.execCfg, start, end, descs, completeDBs,
+		priorIDs, w.details.FullCluster)
+	if err != nil {
+		return backuppb.BackupManifest{}, err
+	}
+	spans, err := spansForAllTableIndexes(w.execCfg, tables, revs)
+	if err != nil {
+		return backuppb.BackupManifest{}, err
+	}
+
+	return backuppb.BackupManifest{
+		ID:                 uuid.MakeV4(),
+		StartTime:          start,
+		EndTime:            end,
+		MVCCFilter:         backuppb.MVCCFilter_All,
+		Descriptors:        descriptorProtos,
+		DescriptorChanges:  revs,
+		CompleteDbs:        completeDBs,
+		Spans:              spans,
+		IntroducedSpans:    filterSpans(spans, w.prevSpans),
+		FormatVersion:      backupinfo.BackupFormatDescriptorTrackingVersion,
+		BuildInfo:          build.GetInfo(),
+		ClusterVersion:     w.execCfg.Settings.Version.ActiveVersion(ctx).Version,
+		ClusterID:          w.execCfg.NodeInfo.LogicalClusterID(),
+		DescriptorCoverage: changeLogCoverage(w.details),
+	}, nil
+}
+
+// writeSegmentData writes the events of a segment that fall in the spans of its
+// manifest to a single SST, and adds a file for each span with data to the
+// manifest.
+func (w *changeLogWriter) writeSegmentData(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	manifest *backuppb.BackupManifest,
+	points []storage.MVCCKeyValue,
+	rangeKeys []storage.MVCCRangeKeyValue,
+) error {
+	if len(points) == 0 && len(rangeKeys) == 0 {
+		return nil
+	}
+
+	// The rangefeed may deliver an event more than once.
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Key.Less(points[j].Key)
+	})
+	fragments := fragmentRangeKeys(rangeKeys)
+
+	pkIDs := make(map[uint64]bool)
+	for i := range manifest.Descriptors {
+		if t, _, _, _, _ := descpb.GetDescriptors(&manifest.Descriptors[i]); t != nil {
+			pkIDs[kvpb.BulkOpSummaryID(uint64(t.ID), uint64(t.PrimaryIndex.ID))] = true
+		}
+	}
+
+	name := generateUniqueSSTName(w.execCfg.NodeInfo.NodeID.SQLInstanceID())
+	out, err := store.Writer(ctx, name)
+	if err != nil {
+		return err
+	}
+	sst := storage.MakeBackupSSTWriter(ctx, store.Settings(), out)
+	defer sst.Close()
+
+	var files []backuppb.BackupManifest_File
+	for _, sp := range manifest.Spans {
+		var counter storage.RowCounter
+		var prev storage.MVCCKey
+		for _, kv := range points {
+			if !sp.ContainsKey(kv.Key.Key) || (prev.Key != nil && kv.Key.Equal(prev)) {
+				continue
+			}
+			prev = kv.Key
+			if err := sst.PutRawMVCC(kv.Key, kv.Value); err != nil {
+				return err
+			}
+			counter.DataSize += int64(len(kv.Key.Key) + len(kv.Value))
+			if err := counter.Count(kv.Key.Key); err != nil {
+				return err
+			}
+		}
+		for _, f := range fragments {
+			clipped := f.RangeKey
+			fragmentSpan := roachpb.Span{Key: clipped.StartKey, EndKey: clipped.EndKey}
+			if !sp.Overlaps(fragmentSpan) {
+				continue
+			}
+			isect := sp.Intersect(fragmentSpan)
+			clipped.StartKey, clipped.EndKey = isect.Key, isect.EndKey
+			if err := sst.PutRawMVCCRangeKey(clipped, f.Value); err != nil {
+				return err
+			}
+			counter.DataSize += int64(len(clipped.StartKey) + len(clipped.EndKey) + len(f.Value))
+		}
+		if counter.DataSize == 0 {
+			continue
+		}
+		files = append(files, backuppb.BackupManifest_File{
+			Span:        sp,
+			Path:        name,
+			EntryCounts: countRows(counter.BulkOpSummary, pkIDs),
+		})
+	}
+
+	if err := sst.Finish(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return errors.Wrap(err, "writing SST")
+	}
+	for i := range files {
+		files[i].BackingFileSize = sst.Meta.Size
+		manifest.EntryCounts.Add(files[i].EntryCounts)
+	}
+	manifest.Files = files
+	return nil
+}
+
+// fragmentRangeKeys splits the given range keys at each other's bounds, so that
+// the fragments are either disjoint or have the same bounds, and returns the
+// fragments ordered by start key and then by descending timestamp, without
+// duplicates. This is the order in which range keys are written to an SST.
+func fragmentRangeKeys(rangeKeys []storage.MVCCRangeKeyValue) []storage.MVCCRangeKeyValue {
+	if len(rangeKeys) == 0 {
+		return nil
+	}
+	bounds := make([]roachpb.Key, 0, 2*len(rangeKeys))
+	for _, rkv := range rangeKeys {
+		bounds = append(bounds, rkv.RangeKey.StartKey, rkv.RangeKey.EndKey)
+	}
+	sort.Slice(bounds, func(i, j int) bool {
+		return bounds[i].Compare(bounds[j]) < 0
+	})
+
+	var fragments []storage.MVCCRangeKeyValue
+	for i := 0; i+1 < len(bounds); i++ {
+		start, end := bounds[i], bounds[i+1]
+		if start.Equal(end) {
+			continue
+		}
+		var versions []storage.MVCCRangeKeyValue
+		for _, rkv := range rangeKeys {
+			if rkv.RangeKey.StartKey.Compare(start) <= 0 && end.Compare(rkv.RangeKey.EndKey) <= 0 {
+				versions = append(versions, storage.MVCCRangeKeyValue{
+					RangeKey: storage.MVCCRangeKey{StartKey: start, EndKey: end, Timestamp: rkv.RangeKey.Timestamp},
+					Value:    rkv.Value,
+				})
+			}
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[j].RangeKey.Timestamp.Less(versions[i].RangeKey.Timestamp)
+		})
+		for j, v := range versions {
+			if j > 0 && v.RangeKey.Timestamp.Equal(versions[j-1].RangeKey.Timestamp) {
+				continue
+			}
+			fragments = append(fragments, v)
+		}
+	}
+	return fragments
+}
@@ -0,0 +1,817 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/bulk"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// compactedLayerStartFormat is the format of the start time that is appended
+// to the directory name of a compacted incremental layer. The directory is
+// otherwise named after its end time like any other incremental layer, so that
+// it sorts right after the last layer it was built from.
+const compactedLayerStartFormat = compactedLayerMarker + "20060102-150405.00"
+
+// compactedLayerMarker identifies the directory of a compacted incremental
+// layer.
+const compactedLayerMarker = "-compacted-"
+
+// compactionLayers is the part of a backup chain that is merged by a
+// compaction job.
+type compactionLayers struct {
+	// manifests are the manifests of the merged layers, in order.
+	manifests []backuppb.BackupManifest
+	// intoFull is true if manifests starts at the full backup of the chain.
+	intoFull bool
+	// atEndOfChain is true if the last of the manifests is the last layer of
+	// the chain.
+	atEndOfChain bool
+}
+
+// compactBackup merges the layers of a backup chain into a single layer that
+// is written next to them. It only reads the files of the backup and never the
+// cluster, so it can compact a backup of data that has since been deleted.
+func (b *backupResumer) compactBackup(
+	ctx context.Context, p sql.JobExecContext, details jobspb.BackupDetails,
+) error {
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.compactBackup")
+	defer span.Finish()
+
+	execCfg := p.ExecCfg()
+	kmsEnv := backupencryption.MakeBackupKMSEnv(
+		execCfg.Settings, &execCfg.ExternalIODirConfig, execCfg.InternalDB, p.User(),
+	)
+
+	mem := execCfg.RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+
+	dest := details.Destination
+	fullyResolvedBaseDirectory, err := backuputils.AppendPaths(dest.To, dest.Subdir)
+	if err != nil {
+		return err
+	}
+	fullyResolvedIncrementalsDirectory, err := backupdest.ResolveIncrementalsBackupLocation(
+		ctx, p.User(), execCfg, dest.IncrementalStorage, dest.To, dest.Subdir,
+	)
+	if err != nil {
+		return err
+	}
+
+	mkStore := execCfg.DistSQLSrv.ExternalStorageFromURI
+	baseStores, cleanupBase, err := backupdest.MakeBackupDestinationStores(ctx, p.User(), mkStore,
+		fullyResolvedBaseDirectory)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanupBase(); err != nil {
+			log.Warningf(ctx, "failed to close base store: %+v", err)
+		}
+	}()
+	incStores, cleanupInc, err := backupdest.MakeBackupDestinationStores(ctx, p.User(), mkStore,
+		fullyResolvedIncrementalsDirectory)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanupInc(); err != nil {
+			log.Warningf(ctx, "failed to close incremental store: %+v", err)
+		}
+	}()
+
+	var encryption *jobspb.BackupEncryptionOptions
+	if details.EncryptionOptions != nil {
+		encryption = details.EncryptionOptions
+		// The encryption options are only raw when the job was created by COMPACT
+		// BACKUP, rather than by a scheduled backup that already resolved them.
+		if encryption.RawPassphrase != "" || len(encryption.RawKmsUris) > 0 {
+			encryption, err = backupencryption.GetEncryptionFromBase(ctx, p.User(), mkStore,
+				fullyResolvedBaseDirectory[0], *details.EncryptionOptions, &kmsEnv)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, manifests, localityInfo, memSize, err := backupdest.ResolveBackupManifests(
+		ctx, &mem, baseStores, incStores, mkStore, fullyResolvedBaseDirectory,
+		fullyResolvedIncrementalsDirectory, "" /* changeLogURI */, hlc.Timestamp{}, encryption,
+		&kmsEnv, p.User(),
+	)
+	if err != nil {
+		return err
+	}
+	defer mem.Shrink(ctx, memSize)
+	for _, info := range localityInfo {
+		if len(info.URIsByOriginalLocalityKV) > 0 {
+			return errors.New("compacting locality-aware backups is not supported")
+		}
+	}
+
+	layers, err := selectCompactionLayers(manifests, details)
+	if err != nil {
+		return err
+	}
+	first, last := layers.manifests[0], layers.manifests[len(layers.manifests)-1]
+
+	// Resolve the location of the compacted layer and persist it, along with the
+	// exact bounds of the compacted layers, the first time the job runs.
+	if details.URI == "" {
+		if layers.intoFull {
+			uris, err := backuputils.AppendPaths(dest.To,
+				last.EndTime.GoTime().Format(backupbase.DateBasedIntoFolderName))
+			if err != nil {
+				return err
+			}
+			details.URI = uris[0]
+		} else {
+			u, err := url.Parse(fullyResolvedIncrementalsDirectory[0])
+			if err != nil {
+				return err
+			}
+			u.Path = backuputils.JoinURLPath(u.Path,
+				last.EndTime.GoTime().Format(backupbase.DateBasedIncFolderName)+
+					first.StartTime.GoTime().Format(compactedLayerStartFormat))
+			details.URI = u.String()
+		}
+		details.StartTime, details.EndTime = first.StartTime, last.EndTime
+
+		if err := backupinfo.CheckForPreviousBackup(ctx, execCfg, details.URI, b.job.ID(),
+			p.User()); err != nil {
+			return err
+		}
+		if err := backupinfo.WriteBackupLock(ctx, execCfg, details.URI, b.job.ID(),
+			p.User()); err != nil {
+			return err
+		}
+		if err := b.job.NoTxn().Update(ctx, func(txn isql.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+			if err := md.CheckRunningOrReverting(); err != nil {
+				return err
+			}
+			md.Payload.Details = jobspb.WrapPayloadDetails(details)
+			ju.UpdatePayload(md.Payload)
+			return nil
+		}); err != nil {
+			return err
+		}
+	} else if !first.StartTime.Equal(details.StartTime) || !last.EndTime.Equal(details.EndTime) {
+		return errors.AssertionFailedf("layers of the backup chain changed while being compacted")
+	}
+
+	if err := execCfg.JobRegistry.CheckPausepoint("backup.compaction.before_merge"); err != nil {
+		return err
+	}
+
+	destStore, err := mkStore(ctx, details.URI, p.User())
+	if err != nil {
+		return err
+	}
+	defer destStore.Close()
+
+	if layers.intoFull && encryption != nil {
+		// A new full backup has to carry the encryption info that incremental
+		// layers and restores read from the full backup.
+		opts, err := backupencryption.ReadEncryptionOptions(ctx, baseStores[0])
+		if err != nil {
+			return err
+		}
+		if err := backupencryption.WriteEncryptionInfoIfNotExists(ctx, &opts[0], destStore); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := mergeBackupLayers(ctx, execCfg, destStore, layers, encryption, &kmsEnv)
+	if err != nil {
+		return err
+	}
+
+	if err := writeCompactedManifest(ctx, execCfg, destStore, baseStores[0], layers, manifest,
+		encryption, &kmsEnv); err != nil {
+		return err
+	}
+
+	// A new full backup that covers the end of the chain is where the next
+	// backup into LATEST should append to.
+	if layers.intoFull && layers.atEndOfChain && details.CollectionURI != "" {
+		c, err := mkStore(ctx, details.CollectionURI, p.User())
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		if err := backupdest.WriteNewLatestFile(ctx, execCfg.Settings, c,
+			details.EndTime.GoTime().Format(backupbase.DateBasedIntoFolderName)); err != nil {
+			return err
+		}
+	}
+
+	b.backupStats = manifest.EntryCounts
+	telemetry.Count("backup.compaction.succeeded")
+	if layers.intoFull {
+		telemetry.Count("backup.compaction.into-full")
+	}
+	telemetry.CountBucketed("backup.compaction.layers", int64(len(layers.manifests)))
+	return b.maybeNotifyScheduledJobCompletion(
+		ctx, jobs.StatusSucceeded, execCfg.JobsKnobs(), execCfg.InternalDB,
+	)
+}
+
+// selectCompactionLayers returns the layers of the chain that a compaction job
+// merges: the layers from the one that starts at details.StartTime, or the
+// first incremental layer, to the one that ends at details.EndTime, or the
+// last layer.
+func selectCompactionLayers(
+	manifests []backuppb.BackupManifest, details jobspb.BackupDetails,
+) (compactionLayers, error) {
+	lo, hi := 1, len(manifests)-1
+	intoFull := details.Compaction.IntoFull
+	if intoFull {
+		lo = 0
+		if !details.StartTime.IsEmpty() {
+			return compactionLayers{}, errors.New("a start time cannot be specified when compacting into a full backup")
+		}
+	} else if !details.StartTime.IsEmpty() {
+		lo = -1
+		for i := 1; i < len(manifests); i++ {
+			if layerBoundMatches(manifests[i].StartTime, details.StartTime) {
+				lo = i
+				break
+			}
+		}
+		if lo == -1 {
+			return compactionLayers{}, errors.Newf(
+				"no incremental layer of the backup starts at %s", details.StartTime.GoTime())
+		}
+	}
+	if !details.EndTime.IsEmpty() {
+		hi = -1
+		for i := range manifests {
+			if layerBoundMatches(manifests[i].EndTime, details.EndTime) {
+				hi = i
+				break
+			}
+		}
+		if hi == -1 {
+			return compactionLayers{}, errors.Newf(
+				"no layer of the backup ends at %s", details.EndTime.GoTime())
+		}
+	}
+	if hi-lo < 1 {
+		return compactionLayers{}, errors.New("compaction requires at least two backup layers")
+	}
+	return compactionLayers{
+		manifests:    manifests[lo : hi+1],
+		intoFull:     intoFull,
+		atEndOfChain: hi == len(manifests)-1,
+	}, nil
+}
+
+// layerBoundMatches returns true if the start or end time of a layer matches
+// the requested time. Since SHOW BACKUP displays the bounds of layers with
+// microsecond precision, a requested time that is a whole microsecond matches
+// bounds in that microsecond.
+func layerBoundMatches(bound, requested hlc.Timestamp) bool {
+	if bound.Equal(requested) {
+		return true
+	}
+	const micro = int64(time.Microsecond)
+	return requested.Logical == 0 && requested.WallTime%micro == 0 &&
+		bound.WallTime/micro == requested.WallTime/micro
+}
+
+// mergeBackupLayers writes the data of the given layers into new files in
+// destStore, and returns the manifest of the compacted layer. If all the layers
+// have revision history, every revision is kept. Otherwise only the latest
+// revision of each key is kept, and tombstones are dropped when compacting into
+// a full backup.
+func mergeBackupLayers(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	destStore cloud.ExternalStorage,
+	layers compactionLayers,
+	encryption *jobspb.BackupEncryptionOptions,
+	kmsEnv cloud.KMSEnv,
+) (*backuppb.BackupManifest, error) {
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.mergeBackupLayers")
+	defer span.Finish()
+
+	manifests := layers.manifests
+	first, last := manifests[0], manifests[len(manifests)-1]
+
+	layerToIterFactory, err := backupinfo.GetBackupManifestIterFactories(ctx,
+		execCfg.DistSQLSrv.ExternalStorage, manifests, encryption, kmsEnv)
+	if err != nil {
+		return nil, err
+	}
+	lastIterFactory := layerToIterFactory[len(manifests)-1]
+
+	// The compacted layer is the last layer as of its end time, but covers the
+	// time and data of all the layers it was built from.
+	manifest := last
+	manifest.ID = uuid.MakeV4()
+	manifest.StartTime = first.StartTime
+	manifest.Dir = destStore.Conf()
+	manifest.HasExternalManifestSSTs = false
+	manifest.Files = nil
+	manifest.EntryCounts = roachpb.RowCount{}
+	manifest.Descriptors, err = collectDescriptors(lastIterFactory.NewDescIter(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	revisionHistory := true
+	for _, m := range manifests {
+		if m.MVCCFilter != backuppb.MVCCFilter_All {
+			revisionHistory = false
+		}
+	}
+	manifest.DescriptorChanges = nil
+	if revisionHistory {
+		manifest.MVCCFilter = backuppb.MVCCFilter_All
+		for i, m := range manifests {
+			manifest.RevisionStartTime.Forward(m.RevisionStartTime)
+			changes, err := bulk.CollectToSlice(layerToIterFactory[i].NewDescriptorChangesIter(ctx))
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range changes {
+				manifest.DescriptorChanges = append(manifest.DescriptorChanges, *c)
+			}
+		}
+		sort.SliceStable(manifest.DescriptorChanges, func(i, j int) bool {
+			return backupinfo.DescChangesLess(&manifest.DescriptorChanges[i], &manifest.DescriptorChanges[j])
+		})
+	} else {
+		manifest.MVCCFilter = backuppb.MVCCFilter_Latest
+		manifest.RevisionStartTime = hlc.Timestamp{}
+	}
+
+	// Data of spans introduced in any of the layers must not be merged with
+	// data of the layers before it, which is what an introduced span of the
+	// compacted layer tells restore.
+	manifest.IntroducedSpans = nil
+	if !layers.intoFull {
+		var introduced roachpb.SpanGroup
+		for _, m := range manifests {
+			introduced.Add(m.IntroducedSpans...)
+		}
+		manifest.IntroducedSpans = introduced.Slice()
+	}
+
+	pkIDs := make(map[uint64]bool)
+	for i := range manifest.Descriptors {
+		if t, _, _, _, _ := descpb.GetDescriptors(&manifest.Descriptors[i]); t != nil {
+			pkIDs[kvpb.BulkOpSummaryID(uint64(t.ID), uint64(t.PrimaryIndex.ID))] = true
+		}
+	}
+
+	var fileEncryption *kvpb.FileEncryptionOptions
+	if encryption != nil {
+		key, err := backupencryption.GetEncryptionKey(ctx, encryption, kmsEnv)
+		if err != nil {
+			return nil, err
+		}
+		fileEncryption = &kvpb.FileEncryptionOptions{Key: key}
+	}
+
+	introducedSpanFrontier, err := createIntroducedSpanFrontier(manifests, hlc.Timestamp{})
+	if err != nil {
+		return nil, err
+	}
+	filter, err := makeSpanCoveringFilter(
+		nil, /* checkpointFrontier */
+		nil, /* highWater */
+		introducedSpanFrontier,
+		targetRestoreSpanSize.Get(&execCfg.Settings.SV),
+		false, /* useFrontierCheckpointing */
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &compactedLayerWriter{
+		execCfg:         execCfg,
+		dest:            destStore,
+		fileEncryption:  fileEncryption,
+		pkIDs:           pkIDs,
+		endTime:         last.EndTime,
+		revisionHistory: revisionHistory,
+		intoFull:        layers.intoFull,
+	}
+	defer w.close()
+
+	entries := make(chan execinfrapb.RestoreSpanEntry, 16)
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		defer close(entries)
+		return generateAndSendImportSpans(
+			ctx,
+			last.Spans,
+			manifests,
+			layerToIterFactory,
+			nil, /* backupLocalityMap */
+			filter,
+			false, /* useSimpleImportSpans */
+			entries,
+		)
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		for entry := range entries {
+			if err := w.writeEntry(ctx, entry); err != nil {
+				return err
+			}
+		}
+		return w.flush(ctx)
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	manifest.Files = w.files
+	for _, f := range manifest.Files {
+		manifest.EntryCounts.Add(f.EntryCounts)
+	}
+	return &manifest, nil
+}
+
+func collectDescriptors(it bulk.Iterator[*descpb.Descriptor]) ([]descpb.Descriptor, error) {
+	descs, err := bulk.CollectToSlice(it)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]descpb.Descriptor, len(descs))
+	for i := range descs {
+		res[i] = *descs[i]
+	}
+	return res, nil
+}
+
+// compactedLayerWriter writes the merged data of the entries of a restore span
+// cover to the files of a compacted layer.
+type compactedLayerWriter struct {
+	execCfg         *sql.ExecutorConfig
+	dest            cloud.ExternalStorage
+	fileEncryption  *kvpb.FileEncryptionOptions
+	pkIDs           map[uint64]bool
+	endTime         hlc.Timestamp
+	revisionHistory bool
+	intoFull        bool
+
+	sst     storage.SSTWriter
+	out     io.WriteCloser
+	outName string
+	// pending are the files written to the open SST, and pendingSize their
+	// combined size.
+	pending     []backuppb.BackupManifest_File
+	pendingSize int64
+	// files are the files of all flushed SSTs.
+	files []backuppb.BackupManifest_File
+}
+
+func (w *compactedLayerWriter) open(ctx context.Context) error {
+	w.outName = generateUniqueSSTName(w.execCfg.NodeInfo.NodeID.SQLInstanceID())
+	out, err := w.dest.Writer(ctx, w.outName)
+	if err != nil {
+		return err
+	}
+	w.out = out
+	if w.fileEncryption != nil {
+		e, err := storageccl.EncryptingWriter(out, w.fileEncryption.Key)
+		if err != nil {
+			return err
+		}
+		w.out = e
+	}
+	w.sst = storage.MakeBackupSSTWriter(ctx, w.dest.Settings(), w.out)
+	return nil
+}
+
+func (w *compactedLayerWriter) flush(ctx context.Context) error {
+	if w.out == nil {
+		return nil
+	}
+	if err := w.sst.Finish(); err != nil {
+		return err
+	}
+	if err := w.out.Close(); err != nil {
+		return errors.Wrap(err, "writing SST")
+	}
+	w.out = nil
+	for i := range w.pending {
+		w.pending[i].BackingFileSize = w.sst.Meta.Size
+	}
+	log.VEventf(ctx, 2, "wrote compacted backup file %s with %d spans", w.outName, len(w.pending))
+	w.files = append(w.files, w.pending...)
+	w.pending = nil
+	w.pendingSize = 0
+	return nil
+}
+
+func (w *compactedLayerWriter) close() {
+	if w.out != nil {
+		w.sst.Close()
+		_ = w.out.Close()
+		w.out = nil
+	}
+}
+
+// writeEntry merges the data of the files of the entry within its span.
+func (w *compactedLayerWriter) writeEntry(
+	ctx context.Context, entry execinfrapb.RestoreSpanEntry,
+) error {
+	storeFiles := make([]storageccl.StoreFile, 0, len(entry.Files))
+	defer func() {
+		for _, f := range storeFiles {
+			if err := f.Store.Close(); err != nil {
+				log.Warningf(ctx, "close export storage failed %v", err)
+			}
+		}
+	}()
+	for _, file := range entry.Files {
+		dir, err := w.execCfg.DistSQLSrv.ExternalStorage(ctx, file.Dir)
+		if err != nil {
+			return err
+		}
+		storeFiles = append(storeFiles, storageccl.StoreFile{Store: dir, FilePath: file.Path})
+	}
+
+	// SSTs have to be written in key order, which the entries of a cover are
+	// unless the spans of the chain are themselves out of order.
+	if n := len(w.pending); n > 0 && entry.Span.Key.Compare(w.pending[n-1].Span.EndKey) < 0 {
+		if err := w.flush(ctx); err != nil {
+			return err
+		}
+	}
+	if w.out == nil {
+		if err := w.open(ctx); err != nil {
+			return err
+		}
+	}
+
+	var counter storage.RowCounter
+	if w.intoFull && !w.revisionHistory {
+		if err := w.copyLatest(ctx, storeFiles, entry.Span, &counter); err != nil {
+			return err
+		}
+	} else {
+		if err := w.copyPointKeys(ctx, storeFiles, entry.Span, &counter); err != nil {
+			return err
+		}
+		if err := w.copyRangeKeys(ctx, storeFiles, entry.Span, &counter); err != nil {
+			return err
+		}
+	}
+	if counter.DataSize == 0 {
+		return nil
+	}
+
+	w.pending = append(w.pending, backuppb.BackupManifest_File{
+		Span:        entry.Span,
+		Path:        w.outName,
+		EntryCounts: countRows(counter.BulkOpSummary, w.pkIDs),
+	})
+	w.pendingSize += counter.DataSize
+	if w.pendingSize > targetFileSize.Get(&w.execCfg.Settings.SV) {
+		return w.flush(ctx)
+	}
+	return nil
+}
+
+// copyLatest writes the live value of every key in the span as of the end
+// time of the compacted layers, as a full backup contains.
+func (w *compactedLayerWriter) copyLatest(
+	ctx context.Context,
+	storeFiles []storageccl.StoreFile,
+	span roachpb.Span,
+	counter *storage.RowCounter,
+) error {
+	iterOpts := storage.IterOptions{
+		RangeKeyMaskingBelow: w.endTime,
+		KeyTypes:             storage.IterKeyTypePointsAndRanges,
+		LowerBound:           span.Key,
+		UpperBound:           span.EndKey,
+	}
+	sstIter, err := storageccl.ExternalSSTReader(ctx, storeFiles, w.fileEncryption, iterOpts)
+	if err != nil {
+		return err
+	}
+	iter := storage.NewReadAsOfIterator(sstIter, w.endTime)
+	defer iter.Close()
+
+	for iter.SeekGE(storage.MVCCKey{Key: span.Key}); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		if err := w.putPointKey(iter.UnsafeKey(), iter.UnsafeValue, counter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyPointKeys writes the point keys in the span. With revision history every
+// revision is written, otherwise only the latest revision of each key.
+func (w *compactedLayerWriter) copyPointKeys(
+	ctx context.Context,
+	storeFiles []storageccl.StoreFile,
+	span roachpb.Span,
+	counter *storage.RowCounter,
+) error {
+	iterOpts := storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsOnly,
+		LowerBound: span.Key,
+		UpperBound: span.EndKey,
+	}
+	iter, err := storageccl.ExternalSSTReader(ctx, storeFiles, w.fileEncryption, iterOpts)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for iter.SeekGE(storage.MVCCKey{Key: span.Key}); ; {
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		if err := w.putPointKey(iter.UnsafeKey(), iter.UnsafeValue, counter); err != nil {
+			return err
+		}
+		if w.revisionHistory {
+			iter.Next()
+		} else {
+			iter.NextKey()
+		}
+	}
+	return nil
+}
+
+func (w *compactedLayerWriter) putPointKey(
+	k storage.MVCCKey, value func() ([]byte, error), counter *storage.RowCounter,
+) error {
+	v, err := value()
+	if err != nil {
+		return err
+	}
+	if k.Timestamp.IsEmpty() {
+		if err := w.sst.PutUnversioned(k.Key, v); err != nil {
+			return err
+		}
+	} else if err := w.sst.PutRawMVCC(k, v); err != nil {
+		return err
+	}
+	counter.DataSize += int64(len(k.Key) + len(v))
+	return counter.Count(k.Key)
+}
+
+// copyRangeKeys writes the range keys in the span, which are always kept since
+// range tombstones may delete keys of earlier layers.
+func (w *compactedLayerWriter) copyRangeKeys(
+	ctx context.Context,
+	storeFiles []storageccl.StoreFile,
+	span roachpb.Span,
+	counter *storage.RowCounter,
+) error {
+	iterOpts := storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypeRangesOnly,
+		LowerBound: span.Key,
+		UpperBound: span.EndKey,
+	}
+	iter, err := storageccl.ExternalSSTReader(ctx, storeFiles, w.fileEncryption, iterOpts)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for iter.SeekGE(storage.MVCCKey{Key: span.Key}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		rangeKeys := iter.RangeKeys()
+		for _, v := range rangeKeys.Versions {
+			if err := w.sst.PutRawMVCCRangeKey(rangeKeys.AsRangeKey(v), v.Value); err != nil {
+				return err
+			}
+			counter.DataSize += int64(len(rangeKeys.Bounds.Key) + len(rangeKeys.Bounds.EndKey) + len(v.Value))
+		}
+	}
+	return nil
+}
+
+// writeCompactedManifest writes the manifest of a compacted layer, along with
+// the statistics of its last layer.
+func writeCompactedManifest(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	destStore cloud.ExternalStorage,
+	baseStore cloud.ExternalStorage,
+	layers compactionLayers,
+	manifest *backuppb.BackupManifest,
+	encryption *jobspb.BackupEncryptionOptions,
+	kmsEnv cloud.KMSEnv,
+) error {
+	// The statistics of a layer are stored in its own directory.
+	last := layers.manifests[len(layers.manifests)-1]
+	statsStore := baseStore
+	if !last.StartTime.IsEmpty() {
+		s, err := execCfg.DistSQLSrv.ExternalStorage(ctx, last.Dir)
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+		statsStore = s
+	}
+	statistics, err := backupinfo.GetStatisticsFromBackup(ctx, statsStore, encryption, kmsEnv, last)
+	if err != nil {
+		return err
+	}
+	statsTable := backuppb.StatsTable{Statistics: statistics}
+
+	if err := backupinfo.WriteBackupManifest(ctx, destStore, backupbase.BackupManifestName,
+		encryption, kmsEnv, manifest); err != nil {
+		return err
+	}
+	if backupinfo.WriteMetadataWithExternalSSTsEnabled.Get(&execCfg.Settings.SV) {
+		if err := backupinfo.WriteMetadataWithExternalSSTs(ctx, destStore, encryption,
+			kmsEnv, manifest); err != nil {
+			return err
+		}
+	}
+	if err := backupinfo.WriteTableStatistics(ctx, destStore, encryption, kmsEnv, &statsTable); err != nil {
+		return err
+	}
+	if backupinfo.WriteMetadataSST.Get(&execCfg.Settings.SV) {
+		if err := backupinfo.WriteBackupMetadataSST(ctx, destStore, encryption, kmsEnv, manifest,
+			statsTable.Statistics); err != nil {
+			err = errors.Wrap(err, "writing forward-compat metadata sst")
+			if !build.IsRelease() {
+				return err
+			}
+			log.Warningf(ctx, "%+v", err)
+		}
+	}
+	return nil
+}
+
+// releaseBackupLock removes the lock that a failed job holds on the location it
+// writes to, so that the same layers can be compacted again or the change log
+// of the collection can be restarted.
+func releaseBackupLock(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	details jobspb.BackupDetails,
+	jobID jobspb.JobID,
+	user username.SQLUsername,
+) error {
+	if details.URI == "" {
+		return nil
+	}
+	store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, details.URI, user)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.Delete(ctx, backupinfo.BackupLockFilePrefix+strconv.FormatInt(int64(jobID), 10))
+}
@@ -0,0 +1,426 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudprivilege"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/exprutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	compactOptStartTime           = "start_time"
+	compactOptEndTime             = "end_time"
+	compactOptIntoFull            = "into_full"
+	compactOptIncrementalLocation = "incremental_location"
+	compactOptEncPassphrase       = "encryption_passphrase"
+	compactOptKMS                 = "kms"
+	compactOptDetached            = "detached"
+)
+
+var compactBackupOptions = exprutil.KVOptionValidationMap{
+	compactOptStartTime:           exprutil.KVStringOptRequireValue,
+	compactOptEndTime:             exprutil.KVStringOptRequireValue,
+	compactOptIntoFull:            exprutil.KVStringOptRequireNoValue,
+	compactOptIncrementalLocation: exprutil.KVStringOptRequireValue,
+	compactOptEncPassphrase:       exprutil.KVStringOptRequireValue,
+	compactOptKMS:                 exprutil.KVStringOptRequireValue,
+	compactOptDetached:            exprutil.KVStringOptRequireNoValue,
+}
+
+// compactionThreshold is the number of incremental layers at which the chain
+// of a scheduled backup is compacted once the next incremental backup of the
+// schedule completes.
+var compactionThreshold = settings.RegisterIntSetting(
+	settings.ApplicationLevel,
+	"backup.compaction.threshold",
+	"number of incremental layers in the chain of a scheduled backup after which the "+
+		"incremental layers are compacted into one; 0 disables compaction",
+	0,
+	settings.NonNegativeInt,
+)
+
+func compactBackupTypeCheck(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (matched bool, header colinfo.ResultColumns, _ error) {
+	compactStmt, ok := stmt.(*tree.CompactBackup)
+	if !ok {
+		return false, nil, nil
+	}
+	if err := exprutil.TypeCheck(
+		ctx, "COMPACT BACKUP", p.SemaCtx(),
+		exprutil.Strings{compactStmt.Subdir},
+		exprutil.StringArrays{tree.Exprs(compactStmt.To)},
+		exprutil.KVOptions{
+			KVOptions:  compactStmt.Options,
+			Validation: compactBackupOptions,
+		},
+	); err != nil {
+		return false, nil, err
+	}
+	if compactStmt.Options.HasKey(compactOptDetached) {
+		return true, jobs.DetachedJobExecutionResultHeader, nil
+	}
+	return true, jobs.BulkJobExecutionResultHeader, nil
+}
+
+// compactBackupPlanHook implements PlanHookFn.
+func compactBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	compactStmt, ok := stmt.(*tree.CompactBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureBackupEnabled,
+		"COMPACT BACKUP",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	exprEval := p.ExprEvaluator("COMPACT BACKUP")
+	subdir, err := exprEval.String(ctx, compactStmt.Subdir)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	to, err := exprEval.StringArray(ctx, tree.Exprs(compactStmt.To))
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	opts, err := exprEval.KVOptions(ctx, compactStmt.Options, compactBackupOptions)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	_, detached := opts[compactOptDetached]
+
+	header := jobs.BulkJobExecutionResultHeader
+	if detached {
+		header = jobs.DetachedJobExecutionResultHeader
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if !(p.ExtendedEvalContext().TxnIsSingleStmt || detached) {
+			return errors.Errorf("COMPACT BACKUP cannot be used inside a multi-statement transaction without DETACHED option")
+		}
+		if err := requireEnterprise(p.ExecCfg(), "COMPACT BACKUP"); err != nil {
+			return err
+		}
+		if err := cloudprivilege.CheckDestinationPrivileges(ctx, p, to); err != nil {
+			return err
+		}
+
+		details := jobspb.BackupDetails{
+			Destination: jobspb.BackupDetails_Destination{
+				To:     to,
+				Exists: true,
+			},
+			CollectionURI: to[0],
+			Compaction:    &jobspb.BackupDetails_Compaction{},
+		}
+		if inc, ok := opts[compactOptIncrementalLocation]; ok {
+			details.Destination.IncrementalStorage = []string{inc}
+		}
+		if _, ok := opts[compactOptIntoFull]; ok {
+			details.Compaction.IntoFull = true
+		}
+		if s, ok := opts[compactOptStartTime]; ok {
+			if details.StartTime, err = parseLayerBound(s); err != nil {
+				return errors.Wrapf(err, "invalid %s", compactOptStartTime)
+			}
+		}
+		if s, ok := opts[compactOptEndTime]; ok {
+			if details.EndTime, err = parseLayerBound(s); err != nil {
+				return errors.Wrapf(err, "invalid %s", compactOptEndTime)
+			}
+		}
+		if details.Compaction.IntoFull && !details.StartTime.IsEmpty() {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"%s cannot be used with %s", compactOptStartTime, compactOptIntoFull)
+		}
+
+		pw, hasPassphrase := opts[compactOptEncPassphrase]
+		kms, hasKMS := opts[compactOptKMS]
+		switch {
+		case hasPassphrase && hasKMS:
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"%s and %s cannot be used together", compactOptEncPassphrase, compactOptKMS)
+		case hasPassphrase:
+			details.EncryptionOptions = &jobspb.BackupEncryptionOptions{
+				Mode:          jobspb.EncryptionMode_Passphrase,
+				RawPassphrase: pw,
+			}
+		case hasKMS:
+			details.EncryptionOptions = &jobspb.BackupEncryptionOptions{
+				Mode:       jobspb.EncryptionMode_KMS,
+				RawKmsUris: []string{kms},
+			}
+		}
+
+		// Resolve LATEST now, so that the job compacts the chain that was the
+		// latest when it was created.
+		if strings.EqualFold(subdir, backupbase.LatestFileName) {
+			subdir, err = backupdest.ReadLatestFile(ctx, to[0],
+				p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User())
+			if err != nil {
+				return errors.Wrap(err, "read LATEST path")
+			}
+		}
+		details.Destination.Subdir = "/" + strings.TrimPrefix(subdir, "/")
+
+		if err := logAndSanitizeBackupDestinations(ctx, to...); err != nil {
+			return errors.Wrap(err, "logging backup destinations")
+		}
+		description, err := compactBackupJobDescription(compactStmt, details)
+		if err != nil {
+			return err
+		}
+		jr := jobs.Record{
+			Description: description,
+			Details:     details,
+			Progress:    jobspb.BackupProgress{},
+			Username:    p.User(),
+		}
+		jobID := p.ExecCfg().JobRegistry.MakeJobID()
+		telemetry.Count("backup.compaction.started")
+
+		if detached {
+			if _, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+				ctx, jr, jobID, p.InternalSQLTxn()); err != nil {
+				return err
+			}
+			resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+			return nil
+		}
+
+		plannerTxn := p.Txn()
+		var sj *jobs.StartableJob
+		if err := func() (err error) {
+			defer func() {
+				if err == nil || sj == nil {
+					return
+				}
+				if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+					log.Errorf(ctx, "failed to cleanup job: %v", cleanupErr)
+				}
+			}()
+			if err := p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(
+				ctx, &sj, jobID, p.InternalSQLTxn(), jr,
+			); err != nil {
+				return err
+			}
+			return plannerTxn.Commit(ctx)
+		}(); err != nil {
+			return err
+		}
+		p.InternalSQLTxn().Descriptors().ReleaseAll(ctx)
+		if err := sj.Start(ctx); err != nil {
+			return err
+		}
+		if err := sj.AwaitCompletion(ctx); err != nil {
+			return err
+		}
+		return sj.ReportExecutionResults(ctx, resultsCh)
+	}
+	return fn, header, nil, false, nil
+}
+
+// parseLayerBound parses the start or end time of a layer, given either as a
+// timestamp or as a decimal HLC timestamp.
+func parseLayerBound(s string) (hlc.Timestamp, error) {
+	if ts, err := hlc.ParseHLC(s); err == nil {
+		return ts, nil
+	}
+	d, _, err := tree.ParseDTimestampTZ(nil /* ctx */, s, time.Nanosecond)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	return hlc.Timestamp{WallTime: d.UnixNano()}, nil
+}
+
+// compactBackupJobDescription returns the description of a compaction job,
+// with the LATEST subdir resolved and secrets redacted from its options.
+func compactBackupJobDescription(
+	stmt *tree.CompactBackup, details jobspb.BackupDetails,
+) (string, error) {
+	to := make(tree.StringOrPlaceholderOptList, len(details.Destination.To))
+	for i, uri := range details.Destination.To {
+		clean, err := cloud.SanitizeExternalStorageURI(uri, nil /* extraParams */)
+		if err != nil {
+			return "", err
+		}
+		to[i] = tree.NewDString(clean)
+	}
+	compact := &tree.CompactBackup{
+		Subdir: tree.NewDString(details.Destination.Subdir),
+		To:     to,
+	}
+	var opts tree.KVOptions
+	if stmt != nil {
+		opts = stmt.Options
+	} else if len(details.Destination.IncrementalStorage) > 0 {
+		// Compactions triggered by a schedule have no statement; the only option
+		// they need to describe is where the chain's incremental layers live.
+		opts = tree.KVOptions{{Key: compactOptIncrementalLocation}}
+	}
+	for _, opt := range opts {
+		switch opt.Key {
+		case compactOptEncPassphrase:
+			opt.Value = tree.NewDString("redacted")
+		case compactOptKMS:
+			kms := details.EncryptionOptions.RawKmsUris[0]
+			clean, err := cloud.RedactKMSURI(kms)
+			if err != nil {
+				return "", err
+			}
+			opt.Value = tree.NewDString(clean)
+		case compactOptIncrementalLocation:
+			clean, err := cloud.SanitizeExternalStorageURI(
+				details.Destination.IncrementalStorage[0], nil /* extraParams */)
+			if err != nil {
+				return "", err
+			}
+			opt.Value = tree.NewDString(clean)
+		}
+		compact.Options = append(compact.Options, opt)
+	}
+	return tree.AsString(compact), nil
+}
+
+// maybeCompactScheduledBackupChain creates a job that compacts the incremental
+// layers of the chain that a scheduled incremental backup appended to, once at
+// least backup.compaction.threshold of them were added since the chain was
+// last compacted.
+func maybeCompactScheduledBackupChain(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	details jobspb.BackupDetails,
+	user username.SQLUsername,
+) error {
+	threshold := compactionThreshold.Get(&execCfg.Settings.SV)
+	if threshold == 0 || details.ScheduleID == 0 || details.StartTime.IsEmpty() ||
+		details.CollectionURI == "" || len(details.URIsByLocalityKV) > 0 {
+		return nil
+	}
+
+	// The layer was written to <incrementals directory>/<date>/<time>, where the
+	// incrementals directory is the chain's subdir within either the collection's
+	// default incrementals directory or the custom incremental location.
+	incDir, err := url.Parse(details.URI)
+	if err != nil {
+		return err
+	}
+	incDir.Path = path.Dir(path.Dir(incDir.Path))
+	store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, incDir.String(), user)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	layers, err := backupdest.FindPriorBackups(ctx, store, backupdest.OmitManifest)
+	if err != nil {
+		return err
+	}
+	newLayers := layersSinceCompaction(layers)
+	if int64(newLayers) < threshold {
+		return nil
+	}
+
+	// The resolved details of the backup only retain the subdir of its
+	// destination, so recover the incremental location from the layer's URI.
+	collection, err := url.Parse(details.CollectionURI)
+	if err != nil {
+		return err
+	}
+	subdir := details.Destination.Subdir
+	var incStorage []string
+	incRoot := *incDir
+	incRoot.Path = strings.TrimSuffix(path.Clean(incDir.Path), path.Clean("/"+subdir))
+	if incRoot.Host != collection.Host ||
+		path.Clean(incRoot.Path) != path.Join(collection.Path, backupbase.DefaultIncrementalsSubdir) {
+		incStorage = []string{incRoot.String()}
+	}
+
+	compactDetails := jobspb.BackupDetails{
+		Destination: jobspb.BackupDetails_Destination{
+			To:                 []string{details.CollectionURI},
+			Subdir:             subdir,
+			IncrementalStorage: incStorage,
+			Exists:             true,
+		},
+		EndTime:           details.EndTime,
+		CollectionURI:     details.CollectionURI,
+		EncryptionOptions: details.EncryptionOptions,
+		Compaction:        &jobspb.BackupDetails_Compaction{},
+	}
+	description, err := compactBackupJobDescription(nil /* stmt */, compactDetails)
+	if err != nil {
+		return err
+	}
+	jr := jobs.Record{
+		Description: description,
+		Details:     compactDetails,
+		Progress:    jobspb.BackupProgress{},
+		Username:    user,
+	}
+	return execCfg.InternalDB.Txn(ctx, func(ctx context.Context, txn isql.Txn) error {
+		jobID := execCfg.JobRegistry.MakeJobID()
+		if _, err := execCfg.JobRegistry.CreateAdoptableJobWithTxn(ctx, jr, jobID, txn); err != nil {
+			return err
+		}
+		log.Infof(ctx, "created job %d to compact %d new incremental backup layers", jobID, newLayers)
+		telemetry.Count("backup.compaction.scheduled")
+		return nil
+	})
+}
+
+// layersSinceCompaction returns the number of layers, in the sorted paths of
+// the incremental layers of a chain, that end after the last compacted layer.
+// A compacted layer is named after the end time of the last layer it was built
+// from, followed by its start time, so it sorts right after that layer.
+func layersSinceCompaction(layers []string) int {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if strings.Contains(path.Base(layers[i]), compactedLayerMarker) {
+			return len(layers) - 1 - i
+		}
+	}
+	return len(layers)
+}
+
+func init() {
+	sql.AddPlanHook("backupccl.compactBackupPlanHook", compactBackupPlanHook, compactBackupTypeCheck)
+}
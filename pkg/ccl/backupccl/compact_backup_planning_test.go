@@ -0,0 +1,65 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayersSinceCompaction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		layers   []string
+		expected int
+	}{
+		{layers: nil, expected: 0},
+		{layers: []string{"20230101/100000.00", "20230101/110000.00"}, expected: 2},
+		{
+			layers: []string{
+				"20230101/100000.00",
+				"20230101/110000.00",
+				"20230101/110000.00-compacted-20230101-090000.00",
+			},
+			expected: 0,
+		},
+		{
+			layers: []string{
+				"20230101/100000.00",
+				"20230101/110000.00",
+				"20230101/110000.00-compacted-20230101-090000.00",
+				"20230101/120000.00",
+				"20230102/010000.00",
+			},
+			expected: 2,
+		},
+		{
+			// A compaction of the compacted layer and the layers after it.
+			layers: []string{
+				"20230101/100000.00",
+				"20230101/110000.00",
+				"20230101/110000.00-compacted-20230101-090000.00",
+				"20230101/120000.00",
+				"20230101/120000.00-compacted-20230101-090000.00",
+				"20230101/130000.00",
+			},
+			expected: 1,
+		},
+	} {
+		layers := append([]string(nil), tc.layers...)
+		sort.Strings(layers)
+		require.Equal(t, tc.expected, layersSinceCompaction(layers), "%v", tc.layers)
+	}
+}
@@ -729,6 +729,10 @@ func makeScheduledBackupSpec(
 		spec.updatesMetrics = &updatesMetrics
 	}
 
+	if schedule.BackupOptions.ChangeLog != nil {
+		return nil, errors.New("the change_log option is not supported in backup schedules")
+	}
+
 	return spec, nil
 }
 
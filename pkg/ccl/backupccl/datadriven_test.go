@@ -47,6 +47,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/datadriven"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq"
@@ -347,6 +348,10 @@ func (d *datadrivenTestState) getSQLDB(t *testing.T, name string, user string) *
 //   - wait-for-state=<succeeded|paused|failed|cancelled> tag=<tag>: wait for
 //     the job referenced by the tag to reach the specified state.
 //
+//   - wait-for-change-log=<ts-tag> tag=<tag>: wait for the change log written
+//     by the backup job referenced by the tag to cover the cluster timestamp
+//     saved with save-cluster-ts under ts-tag.
+//
 //   - "let" [args]
 //     Assigns the returned value of the SQL query to the provided args as variables.
 //
@@ -810,6 +815,35 @@ func runTestDataDriven(t *testing.T, testFilePathFromWorkspace string) {
 				default:
 					t.Fatalf("unknown state %s", state)
 				}
+			} else if d.HasArg("wait-for-change-log") {
+				var tag, tsTag string
+				d.ScanArgs(t, "tag", &tag)
+				d.ScanArgs(t, "wait-for-change-log", &tsTag)
+				jobID, ok := ds.jobTags[tag]
+				if !ok {
+					t.Fatalf("could not find job with tag %s", tag)
+				}
+				tsStr, ok := ds.clusterTimestamps[tsTag]
+				if !ok {
+					t.Fatalf("could not find cluster timestamp with tag %s", tsTag)
+				}
+				ts, err := hlc.ParseHLC(tsStr)
+				require.NoError(t, err)
+				registry := ds.firstNode[cluster].ApplicationLayer().JobRegistry().(*jobs.Registry)
+				testutils.SucceedsSoon(t, func() error {
+					j, err := registry.LoadJob(ctx, jobID)
+					if err != nil {
+						return err
+					}
+					changeLog := j.Details().(jobspb.BackupDetails).ChangeLog
+					if changeLog == nil {
+						t.Fatalf("job with tag %s does not write a change log", tag)
+					}
+					if changeLog.Frontier.Less(ts) {
+						return errors.Newf("change log frontier %s is before %s", changeLog.Frontier, ts)
+					}
+					return nil
+				})
 			}
 			return ""
 
@@ -9,9 +9,14 @@
 package backupccl
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	io "io"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
@@ -25,6 +30,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	hlc "github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 	gogotypes "github.com/gogo/protobuf/types"
 	"github.com/kr/pretty"
@@ -35,8 +42,23 @@ type sstSinkConf struct {
 	enc      *kvpb.FileEncryptionOptions
 	id       base.SQLInstanceID
 	settings *settings.Values
+
+	// contentAddressed, if set, makes the sink write each file it flushes to
+	// the destination under a name derived from the file's contents, skipping
+	// the write if a file with that name already exists. The destination is
+	// then the chunk pool of a deduplicated backup.
+	contentAddressed bool
+
+	// mon, if set, accounts for the memory of the file that a content
+	// addressed sink buffers.
+	mon *mon.BytesMonitor
 }
 
+// chunkGCCandidatesCacheTTL is how long a content addressed sink relies on the
+// GC-CANDIDATES file that it last read from the chunk pool. It must be much
+// shorter than the chunk GC grace period.
+const chunkGCCandidatesCacheTTL = time.Minute
+
 type fileSSTSink struct {
 	dest cloud.ExternalStorage
 	conf sstSinkConf
@@ -47,6 +69,18 @@ type fileSSTSink struct {
 	out     io.WriteCloser
 	outName string
 
+	// buf holds the contents of the file being written when the sink is
+	// content addressed, as its name is only known once it is complete. Its
+	// memory is accounted for in bufAcc, which is nil if the sink has no
+	// monitor.
+	buf    bytes.Buffer
+	bufAcc *mon.BoundAccount
+
+	// gcCandidates are the chunks that the chunk GC recorded as candidates for
+	// deletion when the sink last read them, at gcCandidatesReadAt.
+	gcCandidates       map[string]bool
+	gcCandidatesReadAt time.Time
+
 	flushedFiles []backuppb.BackupManifest_File
 	flushedSize  int64
 
@@ -66,21 +100,31 @@ type fileSSTSink struct {
 		oooFlushes  int // number of out of order flushes.
 		sizeFlushes int // number of flushes due to file exceeding targetFileSize.
 		spanGrows   int // number of times a span was extended.
+		dedups      int // number of flushed files that were already in the chunk pool.
 	}
 }
 
 func makeFileSSTSink(conf sstSinkConf, dest cloud.ExternalStorage) *fileSSTSink {
-	return &fileSSTSink{conf: conf, dest: dest}
+	s := &fileSSTSink{conf: conf, dest: dest}
+	if conf.mon != nil {
+		acc := conf.mon.MakeBoundAccount()
+		s.bufAcc = &acc
+	}
+	return s
 }
 
 func (s *fileSSTSink) Close() error {
 	if log.V(1) && s.ctx != nil {
-		log.Infof(s.ctx, "backup sst sink recv'd %d files, wrote %d (%d due to size, %d due to re-ordering), %d recv files extended prior span",
-			s.stats.files, s.stats.flushes, s.stats.sizeFlushes, s.stats.oooFlushes, s.stats.spanGrows)
+		log.Infof(s.ctx, "backup sst sink recv'd %d files, wrote %d (%d due to size, %d due to re-ordering, %d already in chunk pool), %d recv files extended prior span",
+			s.stats.files, s.stats.flushes, s.stats.sizeFlushes, s.stats.oooFlushes, s.stats.dedups, s.stats.spanGrows)
 	}
 	if s.cancel != nil {
 		s.cancel()
 	}
+	s.buf = bytes.Buffer{}
+	if s.ctx != nil {
+		s.bufAcc.Close(s.ctx)
+	}
 	if s.out != nil {
 		return s.out.Close()
 	}
@@ -129,8 +173,19 @@ func (s *fileSSTSink) flushFile(ctx context.Context) error {
 	s.outName = ""
 	s.out = nil
 
+	var chunkName string
+	if s.conf.contentAddressed {
+		var err error
+		if chunkName, err = s.writeChunk(ctx); err != nil {
+			return err
+		}
+	}
+
 	for i := range s.flushedFiles {
 		s.flushedFiles[i].BackingFileSize = wroteSize
+		if chunkName != "" {
+			s.flushedFiles[i].Path = chunkName
+		}
 	}
 
 	progDetails := backuppb.BackupManifest_Progress{
@@ -158,11 +213,93 @@ func (s *fileSSTSink) flushFile(ctx context.Context) error {
 	return nil
 }
 
+// writeChunk writes the buffered file to the chunk pool, unless the pool
+// already has a chunk with the same contents, and returns the name of the
+// chunk. The name is the SHA-256 of the plaintext, keyed with the encryption
+// key for encrypted backups so that it reveals nothing about the contents.
+func (s *fileSSTSink) writeChunk(ctx context.Context) (string, error) {
+	data := s.buf.Bytes()
+	var sum []byte
+	if s.conf.enc != nil {
+		mac := hmac.New(sha256.New, s.conf.enc.Key)
+		mac.Write(data)
+		sum = mac.Sum(nil)
+	} else {
+		h := sha256.Sum256(data)
+		sum = h[:]
+	}
+	name := fmt.Sprintf("data/%s.sst", hex.EncodeToString(sum))
+
+	// The chunk GC may delete a chunk that it recorded as a candidate as soon
+	// as the grace period has passed, as it only learns that a backup uses the
+	// chunk when the backup checkpoints. A backup therefore never relies on a
+	// candidate, and writes its contents under a unique name instead. A chunk
+	// that is not a candidate cannot be deleted before it was a candidate for
+	// the grace period, by when the backup will have checkpointed. The
+	// candidates are read before checking if the chunk exists, since the GC
+	// deletes chunks before it records the next candidates.
+	candidate, err := s.isChunkGCCandidate(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if candidate {
+		name = generateUniqueSSTName(s.conf.id)
+	} else if _, err := s.dest.Size(ctx, name); err == nil {
+		s.stats.dedups++
+		return name, nil
+	} else if !errors.Is(err, cloud.ErrFileDoesNotExist) {
+		return "", err
+	}
+	if s.conf.enc != nil {
+		if err := s.bufAcc.Grow(ctx, int64(len(data))); err != nil {
+			return "", err
+		}
+		defer s.bufAcc.Shrink(ctx, int64(len(data)))
+		if data, err = storageccl.EncryptFile(data, s.conf.enc.Key); err != nil {
+			return "", err
+		}
+	}
+	if err := cloud.WriteFile(ctx, s.dest, name, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// isChunkGCCandidate returns true if the chunk with the given name is a
+// candidate for deletion by the chunk GC. The candidates are read from the
+// chunk pool at most every chunkGCCandidatesCacheTTL.
+func (s *fileSSTSink) isChunkGCCandidate(ctx context.Context, name string) (bool, error) {
+	if now := timeutil.Now(); s.gcCandidates == nil || now.Sub(s.gcCandidatesReadAt) > chunkGCCandidatesCacheTTL {
+		_, candidates, err := readChunkGCCandidates(ctx, s.dest)
+		if err != nil {
+			return false, err
+		}
+		s.gcCandidates = make(map[string]bool, len(candidates))
+		for _, c := range candidates {
+			s.gcCandidates[c] = true
+		}
+		s.gcCandidatesReadAt = now
+	}
+	return s.gcCandidates[name], nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
 func (s *fileSSTSink) open(ctx context.Context) error {
 	s.outName = generateUniqueSSTName(s.conf.id)
 	if s.ctx == nil {
 		s.ctx, s.cancel = context.WithCancel(ctx)
 	}
+	if s.conf.contentAddressed {
+		s.buf.Reset()
+		s.out = nopWriteCloser{&s.buf}
+		s.sst = storage.MakeBackupSSTWriter(ctx, s.dest.Settings(), s.out)
+		return nil
+	}
 	w, err := s.dest.Writer(s.ctx, s.outName)
 	if err != nil {
 		return err
@@ -224,6 +361,11 @@ func (s *fileSSTSink) write(ctx context.Context, resp exportedSpan) error {
 	if err := s.copyRangeKeys(resp.dataSST); err != nil {
 		return err
 	}
+	if s.conf.contentAddressed {
+		if err := s.bufAcc.ResizeTo(ctx, int64(s.buf.Cap())); err != nil {
+			return err
+		}
+	}
 
 	// If this span extended the last span added -- that is, picked up where it
 	// ended and has the same time-bounds -- then we can simply extend that span
@@ -251,6 +393,14 @@ func (s *fileSSTSink) write(ctx context.Context, resp exportedSpan) error {
 		if err := s.flushFile(ctx); err != nil {
 			return err
 		}
+	} else if s.conf.contentAddressed && resp.completedSpans > 0 {
+		// A content addressed file only dedups against an earlier backup if it
+		// holds the same data, so cut files at the end of each span rather than
+		// packing unrelated spans together.
+		log.VEventf(ctx, 2, "flushing backup chunk %s at the end of span %s", s.outName, span)
+		if err := s.flushFile(ctx); err != nil {
+			return err
+		}
 	} else {
 		log.VEventf(ctx, 3, "continuing to write to backup file %s of size %d", s.outName, s.flushedSize)
 	}
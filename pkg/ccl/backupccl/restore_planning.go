@@ -1821,11 +1821,20 @@ func doRestorePlan(
 	var localityInfo []jobspb.RestoreDetails_BackupLocalityInfo
 	var memReserved int64
 	if len(from) <= 1 {
+		// A restore from a collection to a time after the end of the backup chain
+		// may be covered by the change log of the collection.
+		var changeLogURI string
+		if subdir != "" && len(from[0]) == 1 && !endTime.IsEmpty() {
+			changeLogURI, err = backupdest.ChangeLogURI(from[0][0])
+			if err != nil {
+				return err
+			}
+		}
 		// Incremental layers are not specified explicitly. They will be searched for automatically.
 		// This could be either INTO-syntax, OR TO-syntax.
 		defaultURIs, mainBackupManifests, localityInfo, memReserved, err = backupdest.ResolveBackupManifests(
 			ctx, &mem, baseStores, incStores, mkStore, fullyResolvedBaseDirectory,
-			fullyResolvedIncrementalsDirectory, endTime, encryption, &kmsEnv, p.User(),
+			fullyResolvedIncrementalsDirectory, changeLogURI, endTime, encryption, &kmsEnv, p.User(),
 		)
 	} else {
 		// Incremental layers are specified explicitly.
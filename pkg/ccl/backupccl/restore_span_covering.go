@@ -211,7 +211,7 @@ func makeSimpleImportSpans(
 						break
 					}
 					f := it.Value()
-					fileSpec := execinfrapb.RestoreFileSpec{Path: f.Path, Dir: backups[layer].Dir}
+					fileSpec := execinfrapb.RestoreFileSpec{Path: f.Path, Dir: backups[layer].DataDir()}
 					if dir, ok := backupLocalityMap[layer][f.LocalityKV]; ok {
 						fileSpec = execinfrapb.RestoreFileSpec{Path: f.Path, Dir: dir}
 					}
@@ -560,7 +560,7 @@ func generateAndSendImportSpans(
 			for _, f := range covFilesByLayer[layer] {
 				fileSpec := execinfrapb.RestoreFileSpec{
 					Path:                  f.Path,
-					Dir:                   backups[layer].Dir,
+					Dir:                   backups[layer].DataDir(),
 					BackupFileEntrySpan:   f.Span,
 					BackupFileEntryCounts: f.EntryCounts,
 					BackingFileSize:       f.BackingFileSize,
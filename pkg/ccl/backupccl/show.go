@@ -478,7 +478,8 @@ you must pass the 'encryption_info_dir' parameter that points to the directory o
 		info.defaultURIs, info.manifests, info.localityInfo, memReserved,
 			err = backupdest.ResolveBackupManifests(
 			ctx, &mem, baseStores, incStores, mkStore, fullyResolvedDest,
-			fullyResolvedIncrementalsDirectory, hlc.Timestamp{}, encryption, &kmsEnv, p.User())
+			fullyResolvedIncrementalsDirectory, "" /* changeLogURI */, hlc.Timestamp{}, encryption,
+			&kmsEnv, p.User())
 		defer func() {
 			mem.Shrink(ctx, memReserved)
 		}()
@@ -636,6 +637,25 @@ func checkBackupFiles(
 			localityStores[locality] = store
 		}
 
+		// The SSTs of a deduplicated backup are in the chunk pool of its
+		// collection.
+		dataStore, dataURI := defaultStore, info.defaultURIs[layer]
+		if chunkPool := info.manifests[layer].ChunkPool; chunkPool != "" {
+			poolURIs, err := backuputils.AppendPaths([]string{dataURI}, chunkPool)
+			if err != nil {
+				return nil, err
+			}
+			dataURI = poolURIs[0]
+			if dataStore, err = execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, dataURI, user); err != nil {
+				return nil, err
+			}
+			defer func(store cloud.ExternalStorage) {
+				if err := store.Close(); err != nil {
+					log.Warningf(ctx, "close export storage failed %v", err)
+				}
+			}(dataStore)
+		}
+
 		// Check all backup SSTs.
 		fileSizes := make([]int64, 0)
 		it, err := info.layerToIterFactory[layer].NewFileIter(ctx)
@@ -651,8 +671,8 @@ func checkBackupFiles(
 			}
 
 			f := it.Value()
-			store := defaultStore
-			uri := info.defaultURIs[layer]
+			store := dataStore
+			uri := dataURI
 			if _, ok := localityStores[f.LocalityKV]; ok {
 				store = localityStores[f.LocalityKV]
 				uri = info.localityInfo[layer].URIsByOriginalLocalityKV[f.LocalityKV]
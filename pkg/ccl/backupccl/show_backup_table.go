@@ -0,0 +1,919 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudprivilege"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/fetchpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exprutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// backupTableBatchSize is the number of KVs handed to the row fetcher at a
+// time when decoding the rows of a table in a backup.
+const backupTableBatchSize = 1024
+
+// backupTable is a table resolved from a backup, along with everything needed
+// to read its rows out of the backup's files.
+type backupTable struct {
+	desc               catalog.TableDescriptor
+	cols               []catalog.Column
+	manifests          []backuppb.BackupManifest
+	layerToIterFactory backupinfo.LayerToBackupManifestFileIterFactory
+	localityInfo       []jobspb.RestoreDetails_BackupLocalityInfo
+	encryption         *jobspb.BackupEncryptionOptions
+	kmsEnv             cloud.KMSEnv
+	// endTime is the time as of which the table is read: either the AS OF
+	// SYSTEM TIME of the statement or the end time of the latest backup layer.
+	endTime hlc.Timestamp
+}
+
+func (t *backupTable) header() colinfo.ResultColumns {
+	header := make(colinfo.ResultColumns, len(t.cols))
+	for i, col := range t.cols {
+		header[i] = colinfo.ResultColumn{Name: col.GetName(), Typ: col.GetType()}
+	}
+	return header
+}
+
+func showBackupTableTypeCheck(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (matched bool, header colinfo.ResultColumns, _ error) {
+	showStmt, ok := stmt.(*tree.ShowBackupTable)
+	if !ok {
+		return false, nil, nil
+	}
+	if err := exprutil.TypeCheck(
+		ctx, "SHOW BACKUP TABLE", p.SemaCtx(),
+		exprutil.Strings{
+			showStmt.Path,
+			showStmt.Options.EncryptionPassphrase,
+		},
+		exprutil.StringArrays{
+			tree.Exprs(showStmt.InCollection),
+			tree.Exprs(showStmt.Options.IncrementalStorage),
+			tree.Exprs(showStmt.Options.DecryptionKMSURI),
+		},
+	); err != nil {
+		return false, nil, err
+	}
+	// The columns returned depend on the schema of the table in the backup, so
+	// the backup has to be read to determine them.
+	mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+	table, err := resolveBackupTable(ctx, p, showStmt, &mem)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, table.header(), nil
+}
+
+// showBackupTablePlanHook implements PlanHookFn.
+func showBackupTablePlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	showStmt, ok := stmt.(*tree.ShowBackupTable)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	// The table is resolved once, when planning, since the header depends on
+	// its schema. Its manifests remain accounted for in mem until the statement
+	// finishes executing.
+	mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+	table, err := resolveBackupTable(ctx, p, showStmt, &mem)
+	if err != nil {
+		mem.Close(ctx)
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		defer mem.Close(ctx)
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if err := readBackupTable(ctx, p, table, showStmt.Where, resultsCh); err != nil {
+			return err
+		}
+		telemetry.Count("show-backup.table")
+		return nil
+	}
+	return fn, table.header(), nil, false, nil
+}
+
+// resolveBackupTable reads the manifests of the backup named by the statement
+// and resolves the requested table in them. The manifests are accounted for
+// in mem, which the caller must keep open for as long as it uses the result.
+func resolveBackupTable(
+	ctx context.Context, p sql.PlanHookState, showStmt *tree.ShowBackupTable, mem *mon.BoundAccount,
+) (*backupTable, error) {
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.resolveBackupTable")
+	defer span.Finish()
+
+	exprEval := p.ExprEvaluator("SHOW BACKUP TABLE")
+	subdir, err := exprEval.String(ctx, showStmt.Path)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := exprEval.StringArray(ctx, tree.Exprs(showStmt.InCollection))
+	if err != nil {
+		return nil, err
+	}
+	var explicitIncPaths []string
+	if showStmt.Options.IncrementalStorage != nil {
+		explicitIncPaths, err = exprEval.StringArray(ctx, tree.Exprs(showStmt.Options.IncrementalStorage))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := checkShowBackupTableOptions(showStmt.Options); err != nil {
+		return nil, err
+	}
+
+	if err := cloudprivilege.CheckDestinationPrivileges(ctx, p, dest); err != nil {
+		return nil, err
+	}
+
+	var endTime hlc.Timestamp
+	if showStmt.AsOf.Expr != nil {
+		asOf, err := p.EvalAsOfTimestamp(ctx, showStmt.AsOf)
+		if err != nil {
+			return nil, err
+		}
+		endTime = asOf.Timestamp
+	}
+
+	if strings.EqualFold(subdir, backupbase.LatestFileName) {
+		subdir, err = backupdest.ReadLatestFile(ctx, dest[0],
+			p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User())
+		if err != nil {
+			return nil, errors.Wrap(err, "read LATEST path")
+		}
+		if !endTime.IsEmpty() {
+			subdir, err = fullBackupCoveringTime(ctx, p, dest[0], subdir, endTime)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	fullyResolvedBaseDirectory, err := backuputils.AppendPaths(dest, subdir)
+	if err != nil {
+		return nil, err
+	}
+	fullyResolvedIncrementalsDirectory, err := backupdest.ResolveIncrementalsBackupLocation(
+		ctx,
+		p.User(),
+		p.ExecCfg(),
+		explicitIncPaths,
+		dest,
+		subdir,
+	)
+	if err != nil {
+		if errors.Is(err, cloud.ErrListingUnsupported) {
+			log.Warningf(ctx, "storage sink %v does not support listing, only reading the base backup", explicitIncPaths)
+		} else {
+			return nil, err
+		}
+	}
+
+	mkStore := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI
+	baseStores, cleanupFn, err := backupdest.MakeBackupDestinationStores(ctx, p.User(), mkStore,
+		fullyResolvedBaseDirectory)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cleanupFn(); err != nil {
+			log.Warningf(ctx, "failed to close base store: %+v", err)
+		}
+	}()
+	incStores, cleanupFn, err := backupdest.MakeBackupDestinationStores(ctx, p.User(), mkStore,
+		fullyResolvedIncrementalsDirectory)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cleanupFn(); err != nil {
+			log.Warningf(ctx, "failed to close incremental store: %+v", err)
+		}
+	}()
+
+	ioConf := baseStores[0].ExternalIOConf()
+	kmsEnv := backupencryption.MakeBackupKMSEnv(
+		p.ExecCfg().Settings, &ioConf, p.ExecCfg().InternalDB, p.User(),
+	)
+
+	var encryption *jobspb.BackupEncryptionOptions
+	if showStmt.Options.EncryptionPassphrase != nil {
+		passphrase, err := exprEval.String(ctx, showStmt.Options.EncryptionPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		opts, err := backupencryption.ReadEncryptionOptions(ctx, baseStores[0])
+		if err != nil {
+			return nil, err
+		}
+		encryption = &jobspb.BackupEncryptionOptions{
+			Mode: jobspb.EncryptionMode_Passphrase,
+			Key:  storageccl.GenerateKey([]byte(passphrase), opts[0].Salt),
+		}
+	} else if showStmt.Options.DecryptionKMSURI != nil {
+		kms, err := exprEval.StringArray(ctx, tree.Exprs(showStmt.Options.DecryptionKMSURI))
+		if err != nil {
+			return nil, err
+		}
+		opts, err := backupencryption.ReadEncryptionOptions(ctx, baseStores[0])
+		if err != nil {
+			return nil, err
+		}
+		var defaultKMSInfo *jobspb.BackupEncryptionOptions_KMSInfo
+		for _, encFile := range opts {
+			defaultKMSInfo, err = backupencryption.ValidateKMSURIsAgainstFullBackup(ctx, kms,
+				backupencryption.NewEncryptedDataKeyMapFromProtoMap(encFile.EncryptedDataKeyByKMSMasterKeyID),
+				&kmsEnv)
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		encryption = &jobspb.BackupEncryptionOptions{
+			Mode:    jobspb.EncryptionMode_KMS,
+			KMSInfo: defaultKMSInfo,
+		}
+	}
+
+	_, manifests, localityInfo, _, err := backupdest.ResolveBackupManifests(
+		ctx, mem, baseStores, incStores, mkStore, fullyResolvedBaseDirectory,
+		fullyResolvedIncrementalsDirectory, "" /* changeLogURI */, endTime, encryption, &kmsEnv,
+		p.User(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if endTime.IsEmpty() {
+		endTime = manifests[len(manifests)-1].EndTime
+	}
+
+	layerToIterFactory, err := backupinfo.GetBackupManifestIterFactories(ctx,
+		p.ExecCfg().DistSQLSrv.ExternalStorage, manifests, encryption, &kmsEnv)
+	if err != nil {
+		return nil, err
+	}
+	if err := maybeUpgradeDescriptorsInBackupManifests(ctx,
+		p.ExecCfg().Settings.Version.ActiveVersion(ctx),
+		manifests,
+		layerToIterFactory,
+		true /* skipFKsWithNoMatchingTable */); err != nil {
+		return nil, err
+	}
+
+	tn := showStmt.Table.ToTableName()
+	descs, _, _, _, err := selectTargets(ctx, p, manifests, layerToIterFactory,
+		tree.BackupTargetList{Tables: tree.TableAttrs{TablePatterns: tree.TablePatterns{&tn}}},
+		tree.RequestedDescriptors, endTime, false /* restoreAllTenants */)
+	if err != nil {
+		return nil, errors.Wrap(err,
+			"failed to resolve table in the backup, use SHOW BACKUP to find correct targets")
+	}
+	table := &backupTable{
+		manifests:          manifests,
+		layerToIterFactory: layerToIterFactory,
+		localityInfo:       localityInfo,
+		encryption:         encryption,
+		kmsEnv:             &kmsEnv,
+		endTime:            endTime,
+	}
+	for _, desc := range descs {
+		if tableDesc, ok := desc.(catalog.TableDescriptor); ok {
+			table.desc = tableDesc
+			break
+		}
+	}
+	if table.desc == nil {
+		return nil, errors.Errorf("table %s not found in backup", tree.ErrString(&tn))
+	}
+	if !table.desc.IsPhysicalTable() {
+		return nil, pgerror.Newf(pgcode.WrongObjectType,
+			"%s is not a table", tree.ErrString(&tn))
+	}
+
+	// Virtual columns are not stored and so cannot be read out of the backup.
+	for _, col := range table.desc.VisibleColumns() {
+		if col.IsVirtual() {
+			continue
+		}
+		if col.GetType().UserDefined() {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"SHOW BACKUP TABLE does not support column %q of user-defined type %s",
+				col.GetName(), col.GetType().SQLString())
+		}
+		table.cols = append(table.cols, col)
+	}
+	return table, nil
+}
+
+// fullBackupCoveringTime returns the subdirectory of the most recent full
+// backup in the collection taken at or before endTime, whose chain is the one
+// covering endTime. latest, the subdirectory of the latest full backup, is
+// returned as is if it was taken at or before endTime, or if its name is not
+// derived from the time it was taken.
+func fullBackupCoveringTime(
+	ctx context.Context, p sql.PlanHookState, collection string, latest string, endTime hlc.Timestamp,
+) (string, error) {
+	latestTime, err := time.Parse(backupbase.DateBasedIntoFolderName, latest)
+	if err != nil || !endTime.GoTime().Before(latestTime) {
+		return latest, nil //nolint:returnerrcheck
+	}
+
+	store, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, collection, p.User())
+	if err != nil {
+		return "", errors.Wrapf(err, "connect to external storage")
+	}
+	defer store.Close()
+	subdirs, err := backupdest.ListFullBackupsInCollection(ctx, store)
+	if err != nil {
+		return "", err
+	}
+	var covering string
+	var coveringTime time.Time
+	for _, subdir := range subdirs {
+		subdirTime, err := time.Parse(backupbase.DateBasedIntoFolderName, subdir)
+		if err != nil || endTime.GoTime().Before(subdirTime) {
+			continue
+		}
+		if covering == "" || coveringTime.Before(subdirTime) {
+			covering, coveringTime = subdir, subdirTime
+		}
+	}
+	if covering == "" {
+		return "", pgerror.Newf(pgcode.InvalidParameterValue,
+			"no full backup in the collection was taken at or before %s", endTime)
+	}
+	return covering, nil
+}
+
+// checkShowBackupTableOptions returns an error if any of the SHOW BACKUP
+// options that only apply to the metadata of a backup is set.
+func checkShowBackupTableOptions(opts tree.ShowBackupOptions) error {
+	if opts.AsJson || opts.CheckFiles || opts.DebugIDs || opts.Privileges || opts.SkipSize ||
+		opts.DebugMetadataSST || opts.EncryptionInfoDir != nil ||
+		opts.CheckConnectionTransferSize != nil || opts.CheckConnectionDuration != nil ||
+		opts.CheckConnectionConcurrency != nil {
+		return pgerror.New(pgcode.InvalidParameterValue,
+			"SHOW BACKUP TABLE only supports the incremental_location, "+
+				"encryption_passphrase and kms options")
+	}
+	return nil
+}
+
+// readBackupTable reads the rows of the primary index of the table as of its
+// end time out of the backup files covering it, and sends the rows matching
+// the WHERE clause, if any, to resultsCh.
+func readBackupTable(
+	ctx context.Context,
+	p sql.PlanHookState,
+	table *backupTable,
+	where *tree.Where,
+	resultsCh chan<- tree.Datums,
+) error {
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.readBackupTable")
+	defer span.Finish()
+
+	backupCodec, err := backupinfo.MakeBackupCodec(table.manifests[0])
+	if err != nil {
+		return err
+	}
+	filter, spans, err := makeBackupTableFilter(
+		ctx, p.SemaCtx(), p.ExtendedEvalContext().Context.Copy(), table, backupCodec, where,
+	)
+	if err != nil {
+		return err
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	if err := checkCoverage(ctx, spans, table.manifests); err != nil {
+		return err
+	}
+
+	var fileEncryption *kvpb.FileEncryptionOptions
+	if table.encryption != nil {
+		key := table.encryption.Key
+		if table.encryption.Mode == jobspb.EncryptionMode_KMS {
+			kms, err := cloud.KMSFromURI(ctx, table.encryption.KMSInfo.Uri, table.kmsEnv)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := kms.Close(); err != nil {
+					log.Infof(ctx, "failed to close KMS: %+v", err)
+				}
+			}()
+			key, err = kms.Decrypt(ctx, table.encryption.KMSInfo.EncryptedDataKey)
+			if err != nil {
+				return errors.Wrap(err, "failed to decrypt data key")
+			}
+		}
+		fileEncryption = &kvpb.FileEncryptionOptions{Key: key}
+	}
+
+	backupLocalityMap, err := makeBackupLocalityMap(table.localityInfo, p.User())
+	if err != nil {
+		return errors.Wrap(err, "resolving locality locations")
+	}
+	introducedSpanFrontier, err := createIntroducedSpanFrontier(table.manifests, table.endTime)
+	if err != nil {
+		return err
+	}
+	coveringFilter, err := makeSpanCoveringFilter(
+		nil, /* checkpointFrontier */
+		nil, /* highWater */
+		introducedSpanFrontier,
+		targetRestoreSpanSize.Get(&p.ExecCfg().Settings.SV),
+		false, /* useFrontierCheckpointing */
+	)
+	if err != nil {
+		return err
+	}
+
+	r, err := makeBackupTableReader(ctx, p, table, fileEncryption, filter, resultsCh)
+	if err != nil {
+		return err
+	}
+	defer r.close(ctx)
+
+	entries := make(chan execinfrapb.RestoreSpanEntry, 16)
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		defer close(entries)
+		return generateAndSendImportSpans(
+			ctx,
+			spans,
+			table.manifests,
+			table.layerToIterFactory,
+			backupLocalityMap,
+			coveringFilter,
+			false, /* useSimpleImportSpans */
+			entries,
+		)
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		for entry := range entries {
+			if err := r.readEntry(ctx, entry); err != nil {
+				return err
+			}
+		}
+		return r.flush(ctx, true /* final */)
+	})
+	return g.Wait()
+}
+
+// backupTableReader decodes the KVs of a table read out of backup files into
+// rows.
+type backupTableReader struct {
+	execCfg        *sql.ExecutorConfig
+	endTime        hlc.Timestamp
+	fileEncryption *kvpb.FileEncryptionOptions
+	kr             *KeyRewriter
+	filter         *backupTableFilter
+	fetcher        row.Fetcher
+	alloc          tree.DatumAlloc
+	resultsCh      chan<- tree.Datums
+
+	// kvs buffers the KVs that have not yet been decoded.
+	kvs []roachpb.KeyValue
+}
+
+func makeBackupTableReader(
+	ctx context.Context,
+	p sql.PlanHookState,
+	table *backupTable,
+	fileEncryption *kvpb.FileEncryptionOptions,
+	filter *backupTableFilter,
+	resultsCh chan<- tree.Datums,
+) (*backupTableReader, error) {
+	codec := p.ExecCfg().Codec
+	// The table is read under its own ID, so the only rewriting performed is of
+	// the tenant prefix of the backup to that of this cluster, which is what the
+	// fetcher expects.
+	kr, err := makeKeyRewriter(codec, map[descpb.ID]catalog.TableDescriptor{
+		table.desc.GetID(): table.desc,
+	}, nil /* tenants */, false /* restoreTenantFromStream */)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &backupTableReader{
+		execCfg:        p.ExecCfg(),
+		endTime:        table.endTime,
+		fileEncryption: fileEncryption,
+		kr:             kr,
+		filter:         filter,
+		resultsCh:      resultsCh,
+	}
+	colIDs := make([]descpb.ColumnID, len(table.cols))
+	for i, col := range table.cols {
+		colIDs[i] = col.GetID()
+	}
+	var spec fetchpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(
+		&spec, codec, table.desc, table.desc.GetPrimaryIndex(), colIDs,
+	); err != nil {
+		return nil, err
+	}
+	if err := r.fetcher.Init(ctx, row.FetcherInitArgs{
+		WillUseKVProvider: true,
+		Alloc:             &r.alloc,
+		Spec:              &spec,
+	}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// readEntry reads the KVs in the span of the entry out of its files.
+func (r *backupTableReader) readEntry(
+	ctx context.Context, entry execinfrapb.RestoreSpanEntry,
+) error {
+	storeFiles := make([]storageccl.StoreFile, 0, len(entry.Files))
+	defer func() {
+		for _, f := range storeFiles {
+			if err := f.Store.Close(); err != nil {
+				log.Warningf(ctx, "close export storage failed %v", err)
+			}
+		}
+	}()
+	for _, file := range entry.Files {
+		dir, err := r.execCfg.DistSQLSrv.ExternalStorage(ctx, file.Dir)
+		if err != nil {
+			return err
+		}
+		storeFiles = append(storeFiles, storageccl.StoreFile{Store: dir, FilePath: file.Path})
+	}
+
+	iterOpts := storage.IterOptions{
+		RangeKeyMaskingBelow: r.endTime,
+		KeyTypes:             storage.IterKeyTypePointsAndRanges,
+		LowerBound:           keys.LocalMax,
+		UpperBound:           keys.MaxKey,
+	}
+	sstIter, err := storageccl.ExternalSSTReader(ctx, storeFiles, r.fileEncryption, iterOpts)
+	if err != nil {
+		return err
+	}
+	iter := storage.NewReadAsOfIterator(sstIter, r.endTime)
+	defer iter.Close()
+
+	startKeyMVCC, endKeyMVCC := storage.MVCCKey{Key: entry.Span.Key},
+		storage.MVCCKey{Key: entry.Span.EndKey}
+	for iter.SeekGE(startKeyMVCC); ; iter.NextKey() {
+		ok, err := iter.Valid()
+		if err != nil {
+			return err
+		}
+		if !ok || !iter.UnsafeKey().Less(endKeyMVCC) {
+			break
+		}
+
+		key := iter.UnsafeKey()
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return err
+		}
+		// The key is copied since the rewriter may modify it in place.
+		rewritten, ok, err := r.kr.RewriteKey(append(roachpb.Key(nil), key.Key...), key.Timestamp.WallTime)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// The key belongs to an import that was in progress at the time of the
+			// backup.
+			continue
+		}
+		r.kvs = append(r.kvs, roachpb.KeyValue{
+			Key:   rewritten,
+			Value: roachpb.Value{RawBytes: append([]byte(nil), v...), Timestamp: key.Timestamp},
+		})
+		if len(r.kvs) >= backupTableBatchSize {
+			if err := r.flush(ctx, false /* final */); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flush decodes the buffered KVs into rows and sends them to the results
+// channel. Unless final is set, the KVs of the last buffered row are kept
+// since the KVs of its remaining column families may not have been read yet.
+func (r *backupTableReader) flush(ctx context.Context, final bool) error {
+	n := len(r.kvs)
+	if n == 0 {
+		return nil
+	}
+	if !final {
+		lastRow, err := keys.EnsureSafeSplitKey(r.kvs[n-1].Key)
+		if err != nil {
+			return err
+		}
+		for n > 0 && bytes.HasPrefix(r.kvs[n-1].Key, lastRow) {
+			n--
+		}
+		if n == 0 {
+			return nil
+		}
+	}
+
+	if err := r.fetcher.ConsumeKVProvider(ctx, &row.KVProvider{KVs: r.kvs[:n]}); err != nil {
+		return err
+	}
+	for {
+		datums, err := r.fetcher.NextRowDecoded(ctx)
+		if err != nil {
+			return err
+		}
+		if datums == nil {
+			break
+		}
+		if r.filter != nil {
+			if ok, err := r.filter.matches(ctx, datums); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r.resultsCh <- append(tree.Datums(nil), datums...):
+		}
+	}
+	r.kvs = append(r.kvs[:0], r.kvs[n:]...)
+	return nil
+}
+
+func (r *backupTableReader) close(ctx context.Context) {
+	r.fetcher.Close(ctx)
+}
+
+// backupTableFilter filters the rows of a table read out of a backup by the
+// WHERE clause of SHOW BACKUP TABLE.
+type backupTableFilter struct {
+	eh  execinfrapb.ExprHelper
+	row rowenc.EncDatumRow
+}
+
+// makeBackupTableFilter type checks the WHERE clause against the columns of
+// the table, and returns a filter for the rows of the table along with the
+// spans of its primary index which may contain matching rows. The spans are
+// narrowed by equality and IN constraints on a prefix of the primary key
+// columns; every other conjunct is only applied to the decoded rows. A nil
+// filter is returned if there is no WHERE clause.
+func makeBackupTableFilter(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *eval.Context,
+	table *backupTable,
+	codec keys.SQLCodec,
+	where *tree.Where,
+) (*backupTableFilter, roachpb.Spans, error) {
+	if where == nil {
+		return nil, roachpb.Spans{table.desc.PrimaryIndexSpan(codec)}, nil
+	}
+
+	// Replace the column references with ordinal references to the columns
+	// returned by the fetcher.
+	expr, err := tree.SimpleVisit(where.Expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+		vBase, ok := expr.(tree.VarName)
+		if !ok {
+			return true, expr, nil
+		}
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return false, nil, err
+		}
+		c, ok := v.(*tree.ColumnItem)
+		if !ok {
+			return true, expr, nil
+		}
+		for i, col := range table.cols {
+			if col.GetName() == string(c.ColumnName) {
+				return false, tree.NewOrdinalReference(i), nil
+			}
+		}
+		return false, nil, pgerror.Newf(pgcode.UndefinedColumn,
+			"column %q does not exist", tree.ErrString(&c.ColumnName))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	typs := make([]*types.T, len(table.cols))
+	for i, col := range table.cols {
+		typs[i] = col.GetType()
+	}
+	f := &backupTableFilter{row: make(rowenc.EncDatumRow, len(typs))}
+	if err := f.eh.Init(
+		ctx, execinfrapb.Expression{Expr: tree.Serialize(expr)}, typs, semaCtx, evalCtx,
+	); err != nil {
+		return nil, nil, err
+	}
+	if typ := f.eh.Expr.ResolvedType(); typ.Family() != types.BoolFamily &&
+		typ.Family() != types.UnknownFamily {
+		return nil, nil, pgerror.Newf(pgcode.DatatypeMismatch,
+			"argument of WHERE must be type bool, not type %s", typ)
+	}
+
+	spans, err := makeBackupTableFilterSpans(ctx, evalCtx, table, codec, f.eh.Expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, spans, nil
+}
+
+// maxBackupTableFilterSpans bounds the number of spans derived from the
+// constraints on the primary key columns of a table in a backup. Beyond it,
+// the constraints on the remaining key columns are only applied to the
+// decoded rows.
+const maxBackupTableFilterSpans = 1024
+
+// makeBackupTableFilterSpans returns the spans of the primary index of the
+// table which may contain rows passing the filter.
+func makeBackupTableFilterSpans(
+	ctx context.Context,
+	evalCtx *eval.Context,
+	table *backupTable,
+	codec keys.SQLCodec,
+	filter tree.TypedExpr,
+) (roachpb.Spans, error) {
+	var conjuncts []tree.TypedExpr
+	var splitConjuncts func(expr tree.TypedExpr)
+	splitConjuncts = func(expr tree.TypedExpr) {
+		if and, ok := expr.(*tree.AndExpr); ok {
+			splitConjuncts(and.TypedLeft())
+			splitConjuncts(and.TypedRight())
+			return
+		}
+		conjuncts = append(conjuncts, expr)
+	}
+	splitConjuncts(filter)
+
+	// Determine the values that each column is constrained to by an equality
+	// or IN conjunct.
+	values := make(map[int]tree.Datums)
+	for _, c := range conjuncts {
+		cmp, ok := c.(*tree.ComparisonExpr)
+		if !ok || (cmp.Operator.Symbol != treecmp.EQ && cmp.Operator.Symbol != treecmp.In) {
+			continue
+		}
+		v, ok := cmp.TypedLeft().(*tree.IndexedVar)
+		other := cmp.TypedRight()
+		if !ok && cmp.Operator.Symbol == treecmp.EQ {
+			v, ok = cmp.TypedRight().(*tree.IndexedVar)
+			other = cmp.TypedLeft()
+		}
+		if !ok || tree.ContainsVars(other) {
+			continue
+		}
+		d, err := eval.Expr(ctx, evalCtx, other)
+		if err != nil {
+			return nil, err
+		}
+		candidates := tree.Datums{d}
+		if cmp.Operator.Symbol == treecmp.In {
+			tuple, ok := d.(*tree.DTuple)
+			if !ok {
+				continue
+			}
+			candidates = tuple.D
+		}
+		colTyp := table.cols[v.Idx].GetType()
+		matching := make(tree.Datums, 0, len(candidates))
+		encodable := true
+		for _, d := range candidates {
+			if d == tree.DNull {
+				// NULL is never equal to any value.
+				continue
+			}
+			if d.ResolvedType().Family() != colTyp.Family() {
+				encodable = false
+				break
+			}
+			matching = append(matching, d)
+		}
+		if !encodable {
+			continue
+		}
+		if prev, ok := values[v.Idx]; !ok || len(matching) < len(prev) {
+			values[v.Idx] = matching
+		}
+	}
+
+	var spec fetchpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(
+		&spec, codec, table.desc, table.desc.GetPrimaryIndex(), nil, /* fetchColumnIDs */
+	); err != nil {
+		return nil, err
+	}
+	// Build the cartesian product of the values of the longest prefix of the
+	// key columns which are all constrained.
+	prefixes := []tree.Datums{nil}
+	var prefixCols []fetchpb.IndexFetchSpec_KeyColumn
+	for _, keyCol := range spec.KeyColumns() {
+		colValues, ok := func() (tree.Datums, bool) {
+			for i, col := range table.cols {
+				if col.GetID() == keyCol.ColumnID {
+					colValues, ok := values[i]
+					return colValues, ok
+				}
+			}
+			return nil, false
+		}()
+		if !ok || len(prefixes)*len(colValues) > maxBackupTableFilterSpans {
+			break
+		}
+		next := make([]tree.Datums, 0, len(prefixes)*len(colValues))
+		for _, prefix := range prefixes {
+			for _, d := range colValues {
+				next = append(next, append(prefix[:len(prefix):len(prefix)], d))
+			}
+		}
+		prefixes = next
+		prefixCols = append(prefixCols, keyCol)
+	}
+	if len(prefixCols) == 0 {
+		return roachpb.Spans{table.desc.PrimaryIndexSpan(codec)}, nil
+	}
+
+	var colMap catalog.TableColMap
+	for i, keyCol := range prefixCols {
+		colMap.Set(keyCol.ColumnID, i)
+	}
+	keyPrefix := rowenc.MakeIndexKeyPrefix(codec, table.desc.GetID(), table.desc.GetPrimaryIndexID())
+	var spans roachpb.SpanGroup
+	for _, prefix := range prefixes {
+		span, _, err := rowenc.EncodePartialIndexSpan(prefixCols, colMap, prefix, keyPrefix)
+		if err != nil {
+			return nil, err
+		}
+		spans.Add(span)
+	}
+	return spans.Slice(), nil
+}
+
+// matches returns whether the row passes the filter.
+func (f *backupTableFilter) matches(ctx context.Context, datums tree.Datums) (bool, error) {
+	for i, d := range datums {
+		f.row[i] = rowenc.DatumToEncDatum(f.eh.Types[i], d)
+	}
+	return f.eh.EvalFilter(ctx, f.row)
+}
+
+func init() {
+	sql.AddPlanHook("backupccl.showBackupTablePlanHook", showBackupTablePlanHook, showBackupTableTypeCheck)
+}
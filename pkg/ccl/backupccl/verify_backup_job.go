@@ -0,0 +1,773 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudpb"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// maxReportedVerifyBackupProblems is the number of problems that the error of
+// a failed verification job lists.
+const maxReportedVerifyBackupProblems = 10
+
+// verifyBackupFileConcurrency is the number of files that a verification job
+// reads at once.
+const verifyBackupFileConcurrency = 4
+
+// fingerprintOptions are the options that crdb_internal.fingerprint uses to
+// fingerprint the latest data of a span.
+var fingerprintOptions = storage.MVCCExportFingerprintOptions{
+	StripTenantPrefix:  true,
+	StripValueChecksum: true,
+}
+
+type verifyBackupResumer struct {
+	job *jobs.Job
+
+	// progress is the progress of the job once it succeeded, which is what
+	// ReportResults returns.
+	progress jobspb.VerifyBackupProgress
+}
+
+var _ jobs.Resumer = &verifyBackupResumer{}
+
+// resolvedBackupChain is what a verification job needs to read the files of
+// a backup chain.
+type resolvedBackupChain struct {
+	manifests          []backuppb.BackupManifest
+	layerToIterFactory backupinfo.LayerToBackupManifestFileIterFactory
+	localityMap        map[int]storeByLocalityKV
+	fileEncryption     *kvpb.FileEncryptionOptions
+}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *verifyBackupResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.verifyBackup")
+	defer span.Finish()
+
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	details := r.job.Details().(jobspb.VerifyBackupDetails)
+
+	kmsEnv := backupencryption.MakeBackupKMSEnv(
+		execCfg.Settings, &execCfg.ExternalIODirConfig, execCfg.InternalDB, p.User(),
+	)
+	mem := execCfg.RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+
+	fullyResolvedBaseDirectory, err := backuputils.AppendPaths(details.CollectionURIs, details.Subdir)
+	if err != nil {
+		return err
+	}
+	fullyResolvedIncrementalsDirectory, err := backupdest.ResolveIncrementalsBackupLocation(
+		ctx, p.User(), execCfg, details.IncrementalStorage, details.CollectionURIs, details.Subdir,
+	)
+	if err != nil {
+		return err
+	}
+
+	mkStore := execCfg.DistSQLSrv.ExternalStorageFromURI
+	baseStores, cleanupBase, err := backupdest.MakeBackupDestinationStores(ctx, p.User(), mkStore,
+		fullyResolvedBaseDirectory)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanupBase(); err != nil {
+			log.Warningf(ctx, "failed to close base store: %+v", err)
+		}
+	}()
+	incStores, cleanupInc, err := backupdest.MakeBackupDestinationStores(ctx, p.User(), mkStore,
+		fullyResolvedIncrementalsDirectory)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanupInc(); err != nil {
+			log.Warningf(ctx, "failed to close incremental store: %+v", err)
+		}
+	}()
+
+	var encryption *jobspb.BackupEncryptionOptions
+	if details.EncryptionOptions != nil {
+		encryption, err = backupencryption.GetEncryptionFromBase(ctx, p.User(), mkStore,
+			fullyResolvedBaseDirectory[0], *details.EncryptionOptions, &kmsEnv)
+		if err != nil {
+			return err
+		}
+	}
+
+	var chain resolvedBackupChain
+	var localityInfo []jobspb.RestoreDetails_BackupLocalityInfo
+	var memSize int64
+	_, chain.manifests, localityInfo, memSize, err = backupdest.ResolveBackupManifests(
+		ctx, &mem, baseStores, incStores, mkStore, fullyResolvedBaseDirectory,
+		fullyResolvedIncrementalsDirectory, "" /* changeLogURI */, hlc.Timestamp{}, encryption,
+		&kmsEnv, p.User(),
+	)
+	if err != nil {
+		return err
+	}
+	defer mem.Shrink(ctx, memSize)
+
+	chain.layerToIterFactory, err = backupinfo.GetBackupManifestIterFactories(ctx,
+		execCfg.DistSQLSrv.ExternalStorage, chain.manifests, encryption, &kmsEnv)
+	if err != nil {
+		return err
+	}
+	chain.localityMap, err = makeBackupLocalityMap(localityInfo, p.User())
+	if err != nil {
+		return err
+	}
+	if encryption != nil {
+		key, err := backupencryption.GetEncryptionKey(ctx, encryption, &kmsEnv)
+		if err != nil {
+			return err
+		}
+		chain.fileEncryption = &kvpb.FileEncryptionOptions{Key: key}
+	}
+
+	files, err := collectBackupFiles(ctx, chain)
+	if err != nil {
+		return err
+	}
+	problems, err := r.verifyBackupFiles(ctx, execCfg, chain, files)
+	if err != nil {
+		return err
+	}
+
+	// Fingerprints of files that cannot be read are meaningless.
+	if details.Fingerprint && len(problems) == 0 {
+		if err := execCfg.JobRegistry.CheckPausepoint("backup.verify.before_fingerprint"); err != nil {
+			return err
+		}
+		r.progress.Tables, err = fingerprintBackupTables(ctx, execCfg, chain)
+		if err != nil {
+			return err
+		}
+		for _, t := range r.progress.Tables {
+			if t.SourceError == "" && t.BackupFingerprint != t.SourceFingerprint {
+				problems = append(problems, fmt.Sprintf(
+					"table %s.%s: fingerprint %d of the backup does not match fingerprint %d of the cluster",
+					t.DatabaseName, t.TableName, t.BackupFingerprint, t.SourceFingerprint))
+			}
+		}
+	}
+
+	if err := r.job.NoTxn().Update(ctx, func(txn isql.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+		md.Progress.Details = jobspb.WrapProgressDetails(r.progress)
+		ju.UpdateProgress(md.Progress)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		telemetry.Count("backup.verify.failed")
+		msg := problems
+		if len(msg) > maxReportedVerifyBackupProblems {
+			msg = append(msg[:maxReportedVerifyBackupProblems:maxReportedVerifyBackupProblems],
+				fmt.Sprintf("and %d more", len(problems)-maxReportedVerifyBackupProblems))
+		}
+		return errors.Newf("backup verification found %d problems:\n\t%s",
+			len(problems), strings.Join(msg, "\n\t"))
+	}
+	telemetry.Count("backup.verify.succeeded")
+	return nil
+}
+
+// backupFile is a file of a backup chain, along with the spans that the
+// manifests of the chain list for it.
+type backupFile struct {
+	dir   cloudpb.ExternalStorage
+	path  string
+	spans roachpb.Spans
+}
+
+// collectBackupFiles returns the files of every layer of the chain. Files that
+// are listed more than once, for several spans, are only returned once.
+func collectBackupFiles(ctx context.Context, chain resolvedBackupChain) ([]backupFile, error) {
+	var files []backupFile
+	for layer := range chain.manifests {
+		byPath := make(map[string]int)
+		it, err := chain.layerToIterFactory[layer].NewFileIter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := func() error {
+			defer it.Close()
+			for ; ; it.Next() {
+				if ok, err := it.Valid(); err != nil {
+					return err
+				} else if !ok {
+					return nil
+				}
+				f := it.Value()
+				dir := chain.manifests[layer].DataDir()
+				if d, ok := chain.localityMap[layer][f.LocalityKV]; ok {
+					dir = d
+				}
+				id := f.LocalityKV + "/" + f.Path
+				i, ok := byPath[id]
+				if !ok {
+					i = len(files)
+					byPath[id] = i
+					files = append(files, backupFile{dir: dir, path: f.Path})
+				}
+				files[i].spans = append(files[i].spans, f.Span)
+			}
+		}(); err != nil {
+			return nil, err
+		}
+	}
+	for i := range files {
+		files[i].spans, _ = roachpb.MergeSpans(&files[i].spans)
+	}
+	return files, nil
+}
+
+// verifyBackupFiles reads every file of the chain, and returns the problems
+// found in the files that could be read but are corrupt or missing.
+func (r *verifyBackupResumer) verifyBackupFiles(
+	ctx context.Context, execCfg *sql.ExecutorConfig, chain resolvedBackupChain, files []backupFile,
+) ([]string, error) {
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.verifyBackupFiles")
+	defer span.Finish()
+
+	var (
+		keyCount  atomic.Int64
+		fileCount atomic.Int64
+		problems  = make([][]string, verifyBackupFileConcurrency)
+	)
+	todo := make(chan backupFile)
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		defer close(todo)
+		for _, f := range files {
+			select {
+			case todo <- f:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	for w := 0; w < verifyBackupFileConcurrency; w++ {
+		w := w
+		g.GoCtx(func(ctx context.Context) error {
+			for f := range todo {
+				n, err := verifyBackupFile(ctx, execCfg, f, chain.fileEncryption)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					problems[w] = append(problems[w], fmt.Sprintf("file %s: %v", f.path, err))
+				}
+				keyCount.Add(n)
+				done := fileCount.Add(1)
+				if done%100 == 0 {
+					if err := r.job.NoTxn().FractionProgressed(ctx,
+						jobs.FractionUpdater(float32(done)/float32(len(files)))); err != nil {
+						log.Warningf(ctx, "failed to update job progress: %v", err)
+					}
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	r.progress.Files = fileCount.Load()
+	r.progress.Keys = keyCount.Load()
+	var res []string
+	for _, p := range problems {
+		res = append(res, p...)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// verifyBackupFile reads every point and range key of a backup file, and
+// returns the number of keys read. Reading the file verifies the checksums of
+// its blocks, and of the file itself if it is encrypted. The keys must be in
+// order and within the spans of the file, and the values must match their
+// checksums.
+func verifyBackupFile(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	f backupFile,
+	fileEncryption *kvpb.FileEncryptionOptions,
+) (int64, error) {
+	store, err := execCfg.DistSQLSrv.ExternalStorage(ctx, f.dir)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.Warningf(ctx, "close export storage failed %v", err)
+		}
+	}()
+	storeFiles := []storageccl.StoreFile{{Store: store, FilePath: f.path}}
+
+	// inSpans returns whether the given span is within one of the spans of the
+	// file, which are sorted and non-overlapping.
+	inSpans := func(sp roachpb.Span) bool {
+		i := sort.Search(len(f.spans), func(i int) bool {
+			return sp.Key.Compare(f.spans[i].EndKey) < 0
+		})
+		return i < len(f.spans) && f.spans[i].Contains(sp)
+	}
+
+	var n int64
+	iter, err := storageccl.ExternalSSTReader(ctx, storeFiles, fileEncryption, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsOnly,
+		LowerBound: keys.MinKey,
+		UpperBound: keys.MaxKey,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+	var prev storage.MVCCKey
+	for iter.SeekGE(storage.MVCCKey{Key: keys.MinKey}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return n, err
+		} else if !ok {
+			break
+		}
+		k := iter.UnsafeKey()
+		if n > 0 && !prev.Less(k) {
+			return n, errors.Newf("key %s is not after the preceding key %s", k, prev)
+		}
+		if !inSpans(roachpb.Span{Key: k.Key}) {
+			return n, errors.Newf("key %s is outside of the spans of the file", k)
+		}
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return n, err
+		}
+		if err := (roachpb.Value{RawBytes: v}).Verify(k.Key); err != nil {
+			return n, err
+		}
+		prev.Key = append(prev.Key[:0], k.Key...)
+		prev.Timestamp = k.Timestamp
+		n++
+	}
+
+	rangeIter, err := storageccl.ExternalSSTReader(ctx, storeFiles, fileEncryption, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypeRangesOnly,
+		LowerBound: keys.MinKey,
+		UpperBound: keys.MaxKey,
+	})
+	if err != nil {
+		return n, err
+	}
+	defer rangeIter.Close()
+	for rangeIter.SeekGE(storage.MVCCKey{Key: keys.MinKey}); ; rangeIter.Next() {
+		if ok, err := rangeIter.Valid(); err != nil {
+			return n, err
+		} else if !ok {
+			break
+		}
+		bounds := rangeIter.RangeBounds()
+		if !inSpans(bounds) {
+			return n, errors.Newf("range key %s is outside of the spans of the file", bounds)
+		}
+		n += int64(rangeIter.RangeKeys().Len())
+	}
+	return n, nil
+}
+
+// tableSpan is the part of a span of a backup that is in a table.
+type tableSpan struct {
+	tableID descpb.ID
+	span    roachpb.Span
+}
+
+// splitSpansByTable splits the spans of a backup at the boundaries of the
+// tables whose data they contain.
+func splitSpansByTable(codec keys.SQLCodec, spans roachpb.Spans) ([]tableSpan, error) {
+	var res []tableSpan
+	for _, sp := range spans {
+		for key := sp.Key; key.Compare(sp.EndKey) < 0; {
+			_, id, err := codec.DecodeTablePrefix(key)
+			if err != nil {
+				return nil, errors.Wrapf(err,
+					"only backups of the tables of this virtual cluster can be fingerprinted")
+			}
+			end := codec.TablePrefix(id + 1)
+			if sp.EndKey.Compare(end) < 0 {
+				end = sp.EndKey
+			}
+			res = append(res, tableSpan{
+				tableID: descpb.ID(id),
+				span:    roachpb.Span{Key: key, EndKey: end},
+			})
+			key = end
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].span.Key.Compare(res[j].span.Key) < 0 })
+	return res, nil
+}
+
+// backupTableFingerprint accumulates the fingerprint of the data of a table in
+// a backup.
+type backupTableFingerprint struct {
+	points storage.PointKeyFingerprinter
+	// rangeKeySSTs hold the range keys of the table, which are fingerprinted
+	// together once all of them have been read, as FingerprintSpan does.
+	rangeKeySSTs [][]byte
+}
+
+// fingerprintBackupTables fingerprints the data of each table in the spans of
+// the last layer of the chain as of its end time, and the data of the table in
+// the same spans of this cluster at that time.
+func fingerprintBackupTables(
+	ctx context.Context, execCfg *sql.ExecutorConfig, chain resolvedBackupChain,
+) ([]jobspb.VerifyBackupProgress_TableFingerprint, error) {
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.fingerprintBackupTables")
+	defer span.Finish()
+
+	last := chain.manifests[len(chain.manifests)-1]
+	requiredSpans := append(roachpb.Spans(nil), last.Spans...)
+	sort.Sort(requiredSpans)
+	tableSpans, err := splitSpansByTable(execCfg.Codec, requiredSpans)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make(map[descpb.ID]*backupTableFingerprint)
+	for _, ts := range tableSpans {
+		if _, ok := fingerprints[ts.tableID]; !ok {
+			fingerprints[ts.tableID] = &backupTableFingerprint{
+				points: storage.MakePointKeyFingerprinter(fingerprintOptions),
+			}
+		}
+	}
+
+	// The data of each span is spread over the layers of the chain, which are
+	// merged the same way as when the chain is restored.
+	introducedSpanFrontier, err := createIntroducedSpanFrontier(chain.manifests, hlc.Timestamp{})
+	if err != nil {
+		return nil, err
+	}
+	filter, err := makeSpanCoveringFilter(
+		nil, /* checkpointFrontier */
+		nil, /* highWater */
+		introducedSpanFrontier,
+		targetRestoreSpanSize.Get(&execCfg.Settings.SV),
+		false, /* useFrontierCheckpointing */
+	)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(chan execinfrapb.RestoreSpanEntry, 16)
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		defer close(entries)
+		return generateAndSendImportSpans(
+			ctx,
+			requiredSpans,
+			chain.manifests,
+			chain.layerToIterFactory,
+			chain.localityMap,
+			filter,
+			false, /* useSimpleImportSpans */
+			entries,
+		)
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		for entry := range entries {
+			i := sort.Search(len(tableSpans), func(i int) bool {
+				return entry.Span.Key.Compare(tableSpans[i].span.EndKey) < 0
+			})
+			for ; i < len(tableSpans) && tableSpans[i].span.Key.Compare(entry.Span.EndKey) < 0; i++ {
+				sp := tableSpans[i].span.Intersect(entry.Span)
+				if !sp.Valid() {
+					continue
+				}
+				if err := fingerprintBackupEntry(ctx, execCfg, entry.Files, sp, last.EndTime,
+					chain.fileEncryption, fingerprints[tableSpans[i].tableID]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	descs, err := collectDescriptors(chain.layerToIterFactory[len(chain.manifests)-1].NewDescIter(ctx))
+	if err != nil {
+		return nil, err
+	}
+	dbNames := make(map[descpb.ID]string)
+	tableNames := make(map[descpb.ID]string)
+	parents := make(map[descpb.ID]descpb.ID)
+	for i := range descs {
+		table, db, _, _, _ := descpb.GetDescriptors(&descs[i])
+		if db != nil {
+			dbNames[db.ID] = db.Name
+		}
+		if table != nil {
+			tableNames[table.ID] = table.Name
+			parents[table.ID] = table.ParentID
+		}
+	}
+
+	// The data of the tables can only be compared with the cluster that it was
+	// backed up from.
+	var sourceErr string
+	if !last.ClusterID.Equal(execCfg.NodeInfo.LogicalClusterID()) {
+		sourceErr = "the backup was taken by another cluster"
+	}
+
+	res := make([]jobspb.VerifyBackupProgress_TableFingerprint, 0, len(fingerprints))
+	for id, fp := range fingerprints {
+		rangeKeyFingerprint, err := storage.FingerprintRangekeys(ctx, execCfg.Settings,
+			fingerprintOptions, fp.rangeKeySSTs)
+		if err != nil {
+			return nil, err
+		}
+		t := jobspb.VerifyBackupProgress_TableFingerprint{
+			TableID:           id,
+			DatabaseName:      dbNames[parents[id]],
+			TableName:         tableNames[id],
+			BackupFingerprint: int64(fp.points.Fingerprint() ^ rangeKeyFingerprint),
+			SourceError:       sourceErr,
+		}
+		if t.TableName == "" {
+			t.TableName = fmt.Sprintf("[%d]", id)
+		}
+		res = append(res, t)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].TableID < res[j].TableID })
+	if sourceErr != "" {
+		return res, nil
+	}
+
+	for i := range res {
+		var fingerprint int64
+		for _, ts := range tableSpans {
+			if ts.tableID != res[i].TableID {
+				continue
+			}
+			row, err := execCfg.InternalDB.Executor().QueryRowEx(ctx, "verify-backup-fingerprint",
+				nil /* txn */, sessiondata.NodeUserSessionDataOverride,
+				fmt.Sprintf(`SELECT crdb_internal.fingerprint(ARRAY[$1::BYTES, $2::BYTES], false) AS OF SYSTEM TIME %s`,
+					last.EndTime.AsOfSystemTime()),
+				[]byte(ts.span.Key), []byte(ts.span.EndKey))
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				res[i].SourceError = err.Error()
+				break
+			}
+			fingerprint ^= int64(tree.MustBeDInt(row[0]))
+		}
+		if res[i].SourceError == "" {
+			res[i].SourceFingerprint = fingerprint
+		}
+	}
+	return res, nil
+}
+
+// fingerprintBackupEntry adds the data of the files of a restore span entry
+// within the span, as of the end time of the chain, to the fingerprint of the
+// table that the span is in.
+func fingerprintBackupEntry(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	files []execinfrapb.RestoreFileSpec,
+	span roachpb.Span,
+	endTime hlc.Timestamp,
+	fileEncryption *kvpb.FileEncryptionOptions,
+	fp *backupTableFingerprint,
+) error {
+	if len(files) == 0 {
+		return nil
+	}
+	storeFiles := make([]storageccl.StoreFile, 0, len(files))
+	defer func() {
+		for _, f := range storeFiles {
+			if err := f.Store.Close(); err != nil {
+				log.Warningf(ctx, "close export storage failed %v", err)
+			}
+		}
+	}()
+	for _, file := range files {
+		dir, err := execCfg.DistSQLSrv.ExternalStorage(ctx, file.Dir)
+		if err != nil {
+			return err
+		}
+		storeFiles = append(storeFiles, storageccl.StoreFile{Store: dir, FilePath: file.Path})
+	}
+
+	// The point keys are the latest live value of each key, which is what an
+	// ExportRequest without revisions exports.
+	sstIter, err := storageccl.ExternalSSTReader(ctx, storeFiles, fileEncryption, storage.IterOptions{
+		RangeKeyMaskingBelow: endTime,
+		KeyTypes:             storage.IterKeyTypePointsAndRanges,
+		LowerBound:           span.Key,
+		UpperBound:           span.EndKey,
+	})
+	if err != nil {
+		return err
+	}
+	iter := storage.NewReadAsOfIterator(sstIter, endTime)
+	defer iter.Close()
+	for iter.SeekGE(storage.MVCCKey{Key: span.Key}); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return err
+		}
+		if err := fp.points.Add(iter.UnsafeKey(), v); err != nil {
+			return err
+		}
+	}
+
+	// Range keys are exported regardless of revisions.
+	rangeIter, err := storageccl.ExternalSSTReader(ctx, storeFiles, fileEncryption, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypeRangesOnly,
+		LowerBound: span.Key,
+		UpperBound: span.EndKey,
+	})
+	if err != nil {
+		return err
+	}
+	defer rangeIter.Close()
+	var buf bytes.Buffer
+	sst := storage.MakeBackupSSTWriter(ctx, execCfg.Settings, &buf)
+	defer sst.Close()
+	for rangeIter.SeekGE(storage.MVCCKey{Key: span.Key}); ; rangeIter.Next() {
+		if ok, err := rangeIter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		stack := rangeIter.RangeKeys()
+		for _, v := range stack.Versions {
+			if endTime.Less(v.Timestamp) {
+				continue
+			}
+			if err := sst.PutRawMVCCRangeKey(stack.AsRangeKey(v), v.Value); err != nil {
+				return err
+			}
+		}
+	}
+	if sst.DataSize > 0 {
+		if err := sst.Finish(); err != nil {
+			return err
+		}
+		fp.rangeKeySSTs = append(fp.rangeKeySSTs, buf.Bytes())
+	}
+	return nil
+}
+
+// ReportResults implements the jobs.JobResultsReporter interface.
+func (r *verifyBackupResumer) ReportResults(
+	ctx context.Context, resultsCh chan<- tree.Datums,
+) error {
+	details := r.job.Details().(jobspb.VerifyBackupDetails)
+	var rows []tree.Datums
+	if details.Fingerprint {
+		for _, t := range r.progress.Tables {
+			source, status := tree.DNull, "ok"
+			if t.SourceError != "" {
+				status = "unavailable: " + t.SourceError
+			} else {
+				source = tree.NewDInt(tree.DInt(t.SourceFingerprint))
+			}
+			rows = append(rows, tree.Datums{
+				tree.NewDInt(tree.DInt(r.job.ID())),
+				tree.NewDString(t.DatabaseName),
+				tree.NewDString(t.TableName),
+				tree.NewDInt(tree.DInt(t.BackupFingerprint)),
+				source,
+				tree.NewDString(status),
+			})
+		}
+	} else {
+		rows = append(rows, tree.Datums{
+			tree.NewDInt(tree.DInt(r.job.ID())),
+			tree.NewDString(string(jobs.StatusSucceeded)),
+			tree.NewDInt(tree.DInt(r.progress.Files)),
+			tree.NewDInt(tree.DInt(r.progress.Keys)),
+		})
+	}
+	for _, row := range rows {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resultsCh <- row:
+		}
+	}
+	return nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. A verification job
+// only reads the backup, so there is nothing to clean up.
+func (r *verifyBackupResumer) OnFailOrCancel(context.Context, interface{}, error) error {
+	return nil
+}
+
+// CollectProfile is part of the jobs.Resumer interface.
+func (r *verifyBackupResumer) CollectProfile(context.Context, interface{}) error {
+	return nil
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeVerifyBackup,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &verifyBackupResumer{job: job}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
@@ -0,0 +1,291 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudprivilege"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/exprutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	verifyOptFingerprint         = "fingerprint"
+	verifyOptIncrementalLocation = "incremental_location"
+	verifyOptEncPassphrase       = "encryption_passphrase"
+	verifyOptKMS                 = "kms"
+	verifyOptDetached            = "detached"
+)
+
+var verifyBackupOptions = exprutil.KVOptionValidationMap{
+	verifyOptFingerprint:         exprutil.KVStringOptRequireNoValue,
+	verifyOptIncrementalLocation: exprutil.KVStringOptRequireValue,
+	verifyOptEncPassphrase:       exprutil.KVStringOptRequireValue,
+	verifyOptKMS:                 exprutil.KVStringOptRequireValue,
+	verifyOptDetached:            exprutil.KVStringOptRequireNoValue,
+}
+
+var verifyBackupHeader = colinfo.ResultColumns{
+	{Name: "job_id", Typ: types.Int},
+	{Name: "status", Typ: types.String},
+	{Name: "files", Typ: types.Int},
+	{Name: "keys", Typ: types.Int},
+}
+
+var verifyBackupFingerprintHeader = colinfo.ResultColumns{
+	{Name: "job_id", Typ: types.Int},
+	{Name: "database_name", Typ: types.String},
+	{Name: "table_name", Typ: types.String},
+	{Name: "backup_fingerprint", Typ: types.Int},
+	{Name: "source_fingerprint", Typ: types.Int},
+	{Name: "status", Typ: types.String},
+}
+
+func verifyBackupTypeCheck(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (matched bool, header colinfo.ResultColumns, _ error) {
+	verifyStmt, ok := stmt.(*tree.VerifyBackup)
+	if !ok {
+		return false, nil, nil
+	}
+	if err := exprutil.TypeCheck(
+		ctx, "VERIFY BACKUP", p.SemaCtx(),
+		exprutil.Strings{verifyStmt.Subdir},
+		exprutil.StringArrays{tree.Exprs(verifyStmt.To)},
+		exprutil.KVOptions{
+			KVOptions:  verifyStmt.Options,
+			Validation: verifyBackupOptions,
+		},
+	); err != nil {
+		return false, nil, err
+	}
+	return true, verifyBackupResultHeader(verifyStmt.Options), nil
+}
+
+func verifyBackupResultHeader(opts tree.KVOptions) colinfo.ResultColumns {
+	switch {
+	case opts.HasKey(verifyOptDetached):
+		return jobs.DetachedJobExecutionResultHeader
+	case opts.HasKey(verifyOptFingerprint):
+		return verifyBackupFingerprintHeader
+	default:
+		return verifyBackupHeader
+	}
+}
+
+// verifyBackupPlanHook implements PlanHookFn.
+func verifyBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	verifyStmt, ok := stmt.(*tree.VerifyBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureBackupEnabled,
+		"VERIFY BACKUP",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	exprEval := p.ExprEvaluator("VERIFY BACKUP")
+	subdir, err := exprEval.String(ctx, verifyStmt.Subdir)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	to, err := exprEval.StringArray(ctx, tree.Exprs(verifyStmt.To))
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	opts, err := exprEval.KVOptions(ctx, verifyStmt.Options, verifyBackupOptions)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	_, detached := opts[verifyOptDetached]
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if !(p.ExtendedEvalContext().TxnIsSingleStmt || detached) {
+			return errors.Errorf("VERIFY BACKUP cannot be used inside a multi-statement transaction without DETACHED option")
+		}
+		if err := requireEnterprise(p.ExecCfg(), "VERIFY BACKUP"); err != nil {
+			return err
+		}
+		if err := cloudprivilege.CheckDestinationPrivileges(ctx, p, to); err != nil {
+			return err
+		}
+
+		details := jobspb.VerifyBackupDetails{CollectionURIs: to}
+		if inc, ok := opts[verifyOptIncrementalLocation]; ok {
+			details.IncrementalStorage = []string{inc}
+		}
+		if _, ok := opts[verifyOptFingerprint]; ok {
+			// The data of the tables in the cluster is fingerprinted with
+			// crdb_internal.fingerprint, which is restricted to admins.
+			hasAdmin, err := p.HasAdminRole(ctx)
+			if err != nil {
+				return err
+			}
+			if !hasAdmin {
+				return pgerror.Newf(pgcode.InsufficientPrivilege,
+					"only users with the admin role are allowed to use the %s option", verifyOptFingerprint)
+			}
+			details.Fingerprint = true
+		}
+
+		pw, hasPassphrase := opts[verifyOptEncPassphrase]
+		kms, hasKMS := opts[verifyOptKMS]
+		switch {
+		case hasPassphrase && hasKMS:
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"%s and %s cannot be used together", verifyOptEncPassphrase, verifyOptKMS)
+		case hasPassphrase:
+			details.EncryptionOptions = &jobspb.BackupEncryptionOptions{
+				Mode:          jobspb.EncryptionMode_Passphrase,
+				RawPassphrase: pw,
+			}
+		case hasKMS:
+			details.EncryptionOptions = &jobspb.BackupEncryptionOptions{
+				Mode:       jobspb.EncryptionMode_KMS,
+				RawKmsUris: []string{kms},
+			}
+		}
+
+		// Resolve LATEST now, so that the job verifies the chain that was the
+		// latest when it was created.
+		if strings.EqualFold(subdir, backupbase.LatestFileName) {
+			subdir, err = backupdest.ReadLatestFile(ctx, to[0],
+				p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User())
+			if err != nil {
+				return errors.Wrap(err, "read LATEST path")
+			}
+		}
+		details.Subdir = "/" + strings.TrimPrefix(subdir, "/")
+
+		if err := logAndSanitizeBackupDestinations(ctx, to...); err != nil {
+			return errors.Wrap(err, "logging backup destinations")
+		}
+		description, err := verifyBackupJobDescription(verifyStmt, details)
+		if err != nil {
+			return err
+		}
+		jr := jobs.Record{
+			Description: description,
+			Details:     details,
+			Progress:    jobspb.VerifyBackupProgress{},
+			Username:    p.User(),
+		}
+		jobID := p.ExecCfg().JobRegistry.MakeJobID()
+		telemetry.Count("backup.verify.started")
+
+		if detached {
+			if _, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+				ctx, jr, jobID, p.InternalSQLTxn()); err != nil {
+				return err
+			}
+			resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+			return nil
+		}
+
+		plannerTxn := p.Txn()
+		var sj *jobs.StartableJob
+		if err := func() (err error) {
+			defer func() {
+				if err == nil || sj == nil {
+					return
+				}
+				if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+					log.Errorf(ctx, "failed to cleanup job: %v", cleanupErr)
+				}
+			}()
+			if err := p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(
+				ctx, &sj, jobID, p.InternalSQLTxn(), jr,
+			); err != nil {
+				return err
+			}
+			return plannerTxn.Commit(ctx)
+		}(); err != nil {
+			return err
+		}
+		p.InternalSQLTxn().Descriptors().ReleaseAll(ctx)
+		if err := sj.Start(ctx); err != nil {
+			return err
+		}
+		if err := sj.AwaitCompletion(ctx); err != nil {
+			return err
+		}
+		return sj.ReportExecutionResults(ctx, resultsCh)
+	}
+	return fn, verifyBackupResultHeader(verifyStmt.Options), nil, false, nil
+}
+
+// verifyBackupJobDescription returns the description of a verification job,
+// with the LATEST subdir resolved and secrets redacted from its options.
+func verifyBackupJobDescription(
+	stmt *tree.VerifyBackup, details jobspb.VerifyBackupDetails,
+) (string, error) {
+	to := make(tree.StringOrPlaceholderOptList, len(details.CollectionURIs))
+	for i, uri := range details.CollectionURIs {
+		clean, err := cloud.SanitizeExternalStorageURI(uri, nil /* extraParams */)
+		if err != nil {
+			return "", err
+		}
+		to[i] = tree.NewDString(clean)
+	}
+	verify := &tree.VerifyBackup{
+		Subdir: tree.NewDString(details.Subdir),
+		To:     to,
+	}
+	for _, opt := range stmt.Options {
+		switch opt.Key {
+		case verifyOptEncPassphrase:
+			opt.Value = tree.NewDString("redacted")
+		case verifyOptKMS:
+			clean, err := cloud.RedactKMSURI(details.EncryptionOptions.RawKmsUris[0])
+			if err != nil {
+				return "", err
+			}
+			opt.Value = tree.NewDString(clean)
+		case verifyOptIncrementalLocation:
+			clean, err := cloud.SanitizeExternalStorageURI(
+				details.IncrementalStorage[0], nil /* extraParams */)
+			if err != nil {
+				return "", err
+			}
+			opt.Value = tree.NewDString(clean)
+		}
+		verify.Options = append(verify.Options, opt)
+	}
+	return tree.AsString(verify), nil
+}
+
+func init() {
+	sql.AddPlanHook("backupccl.verifyBackupPlanHook", verifyBackupPlanHook, verifyBackupTypeCheck)
+}
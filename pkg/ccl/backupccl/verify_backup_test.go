@@ -0,0 +1,73 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyBackup verifies a backup chain, and checks that the verification
+// notices a corrupt file.
+func TestVerifyBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, sqlDB, tempDir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, localFoo)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id >= 90`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, localFoo)
+
+	var status string
+	var files, keys int
+	sqlDB.QueryRow(t, `SELECT status, files, keys FROM [VERIFY BACKUP FROM LATEST IN $1]`,
+		localFoo).Scan(&status, &files, &keys)
+	require.Equal(t, "succeeded", status)
+	require.Less(t, 0, files)
+	// The incremental layer holds the updated and deleted rows.
+	require.LessOrEqual(t, numAccounts+20, keys)
+
+	require.Equal(t, [][]string{{"data", "bank", "true", "ok"}}, sqlDB.QueryStr(t, `
+SELECT database_name, table_name, backup_fingerprint = source_fingerprint, status
+FROM [VERIFY BACKUP FROM LATEST IN $1 WITH fingerprint]`, localFoo))
+
+	sqlDB.ExpectErr(t, "encryption_passphrase and kms cannot be used together",
+		`VERIFY BACKUP FROM LATEST IN $1 WITH encryption_passphrase = 'a', kms = 'b'`, localFoo)
+
+	// Overwrite the middle of every data file of the chain, leaving the
+	// metadata SSTs intact.
+	require.NoError(t, filepath.WalkDir(filepath.Join(tempDir, "foo"),
+		func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Base(filepath.Dir(path)) != "data" ||
+				!strings.HasSuffix(path, ".sst") {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for i := len(data) / 4; i < len(data)/2; i++ {
+				data[i] ^= 0xff
+			}
+			return os.WriteFile(path, data, 0644)
+		}))
+	sqlDB.ExpectErr(t, "backup verification found [0-9]+ problems",
+		`VERIFY BACKUP FROM LATEST IN $1`, localFoo)
+}
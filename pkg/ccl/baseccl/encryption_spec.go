@@ -11,6 +11,7 @@ package baseccl
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"strings"
 	"time"
@@ -35,6 +36,9 @@ type StoreEncryptionSpec struct {
 	KeyPath        string
 	OldKeyPath     string
 	RotationPeriod time.Duration
+	// KMSURI, if set, is the URI of the KMS master key which wraps the key
+	// files at KeyPath and OldKeyPath.
+	KMSURI string
 }
 
 // ToEncryptionOptions convert to a serialized EncryptionOptions protobuf.
@@ -47,15 +51,28 @@ func (es StoreEncryptionSpec) ToEncryptionOptions() ([]byte, error) {
 		},
 		DataKeyRotationPeriod: int64(es.RotationPeriod / time.Second),
 	}
+	if es.KMSURI != "" {
+		opts.KeySource = EncryptionKeySource_KMS
+		opts.KeyFiles = nil
+		opts.KmsKeys = &EncryptionKMSKeys{
+			Uri:        es.KMSURI,
+			CurrentKey: es.KeyPath,
+			OldKey:     es.OldKeyPath,
+		}
+	}
 
 	return protoutil.Marshal(&opts)
 }
 
 // String returns a fully parsable version of the encryption spec.
 func (es StoreEncryptionSpec) String() string {
-	// All fields are set.
-	return fmt.Sprintf("path=%s,key=%s,old-key=%s,rotation-period=%s",
+	// All fields are set, except for the optional KMS URI.
+	s := fmt.Sprintf("path=%s,key=%s,old-key=%s,rotation-period=%s",
 		es.Path, es.KeyPath, es.OldKeyPath, es.RotationPeriod)
+	if es.KMSURI != "" {
+		s += ",kms=" + es.KMSURI
+	}
+	return s
 }
 
 // NewStoreEncryptionSpec parses the string passed in and returns a new
@@ -122,6 +139,17 @@ func NewStoreEncryptionSpec(value string) (StoreEncryptionSpec, error) {
 			if err != nil {
 				return StoreEncryptionSpec{}, errors.Wrapf(err, "could not parse rotation-duration value: %s", value)
 			}
+		case "kms":
+			u, err := url.Parse(value)
+			if err != nil {
+				return StoreEncryptionSpec{}, errors.Wrapf(err, "could not parse kms value")
+			}
+			// External connections are stored in SQL, which is not available
+			// until after the store is opened.
+			if u.Scheme == "" || u.Scheme == "external" {
+				return StoreEncryptionSpec{}, fmt.Errorf("unsupported kms URI: %s", value)
+			}
+			es.KMSURI = value
 		default:
 			return StoreEncryptionSpec{}, fmt.Errorf("%s is not a valid enterprise-encryption field", field)
 		}
@@ -50,6 +50,12 @@ func TestNewStoreEncryptionSpec(t *testing.T) {
 		{"path=/data,key=/new.key,old-key=/old.key,rotation-period=1h", "", StoreEncryptionSpec{Path: "/data", KeyPath: "/new.key", OldKeyPath: "/old.key", RotationPeriod: time.Hour}},
 		{"path=/data,key=plain,old-key=/old.key,rotation-period=1h", "", StoreEncryptionSpec{Path: "/data", KeyPath: "plain", OldKeyPath: "/old.key", RotationPeriod: time.Hour}},
 		{"path=/data,key=/new.key,old-key=plain,rotation-period=1h", "", StoreEncryptionSpec{Path: "/data", KeyPath: "/new.key", OldKeyPath: "plain", RotationPeriod: time.Hour}},
+
+		// KMS wrapped keys.
+		{"path=/data,key=/new.key,old-key=/old.key,kms=", "no value specified for kms", StoreEncryptionSpec{}},
+		{"path=/data,key=/new.key,old-key=/old.key,kms=my-key", "unsupported kms URI: my-key", StoreEncryptionSpec{}},
+		{"path=/data,key=/new.key,old-key=/old.key,kms=external://foo", "unsupported kms URI: external://foo", StoreEncryptionSpec{}},
+		{"path=/data,key=/new.key,old-key=/old.key,kms=aws-kms:///arn?REGION=us-east-1&AUTH=implicit", "", StoreEncryptionSpec{Path: "/data", KeyPath: "/new.key", OldKeyPath: "/old.key", RotationPeriod: DefaultRotationPeriod, KMSURI: "aws-kms:///arn?REGION=us-east-1&AUTH=implicit"}},
 	}
 
 	for i, testCase := range testCases {
@@ -13,11 +13,14 @@ import (
 	"net/url"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupresolver"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdceval"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedvalidators"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobsauth"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -117,7 +120,22 @@ func alterChangefeedPlanHook(
 		}
 
 		if job.Status() != jobs.StatusPaused {
-			return errors.Errorf(`job %d is not paused`, jobID)
+			// A running changefeed's aggregators would keep emitting from the
+			// high watermark, so the re-scan could only take effect once the
+			// job is restarted; require it to be paused instead.
+			for _, cmd := range alterChangefeedStmt.Cmds {
+				if _, ok := cmd.(*tree.AlterChangefeedBackfill); ok {
+					return errors.WithHintf(
+						pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+							`cannot backfill targets of changefeed job %d with status %s`, jobID, job.Status()),
+						`pause the changefeed with PAUSE JOB %d, run the BACKFILL, and resume it afterwards`, jobID,
+					)
+				}
+			}
+			return errors.WithHintf(
+				pgerror.Newf(pgcode.ObjectNotInPrerequisiteState, `job %d is not paused`, jobID),
+				`pause the changefeed with PAUSE JOB %d before altering it, and resume it afterwards`, jobID,
+			)
 		}
 
 		newChangefeedStmt := &tree.CreateChangefeed{}
@@ -198,8 +216,26 @@ func alterChangefeedPlanHook(
 		newDetails := jobRecord.Details.(jobspb.ChangefeedDetails)
 		newDetails.Opts[changefeedbase.OptInitialScan] = ``
 
+		// Tables being re-scanned by a previous BACKFILL command remain so until
+		// the changefeed's initial scan completes.
+		if hw := newProgress.GetHighWater(); hw == nil || hw.IsEmpty() {
+			newDetails.Backfills = prevDetails.Backfills
+		}
+
+		var backfills []jobspb.ChangefeedBackfill
+		*newProgress, newStatementTime, backfills, err = generateBackfillProgress(
+			ctx, p, jobID, alterChangefeedStmt.Cmds, newDetails, job.Progress(), *newProgress, newStatementTime,
+		)
+		if err != nil {
+			return err
+		}
+		if len(backfills) > 0 {
+			newDetails.Backfills = backfills
+		}
+
 		// newStatementTime will either be the StatementTime of the job prior to the
-		// alteration, or it will be the high watermark of the job.
+		// alteration, the high watermark of the job, or the time as of which
+		// backfilled targets are re-scanned.
 		newDetails.StatementTime = newStatementTime
 
 		newPayload := job.Payload()
@@ -798,6 +834,232 @@ func generateNewProgress(
 	return newProgress, prevStatementTime, nil
 }
 
+// generateBackfillProgress updates the progress of a changefeed job so that
+// the tables named by BACKFILL commands are scanned again when the job
+// resumes. The backfill reuses the initial scan: the high watermark is cleared,
+// the statement time becomes the time as of which the tables are re-scanned,
+// and the spans of every other target are checkpointed at the previous high
+// watermark so that they are not scanned. Rows emitted by the scan are filtered
+// by the returned backfills. The changefeed's protected timestamp record is
+// moved back to the scan time so that the re-scanned data is not garbage
+// collected before the job resumes. If there are no BACKFILL commands, the
+// previous progress and statement time are returned unchanged.
+//
+// jobProgress is the progress of the job before the ALTER, while prevProgress
+// reflects the other commands of the statement. An ADD ... WITH initial_scan
+// clears the high watermark and checkpoints the existing targets at it, in
+// which case the backfilled targets are scanned along with the added ones.
+func generateBackfillProgress(
+	ctx context.Context,
+	p sql.PlanHookState,
+	jobID jobspb.JobID,
+	alterCmds tree.AlterChangefeedCmds,
+	details jobspb.ChangefeedDetails,
+	jobProgress jobspb.Progress,
+	prevProgress jobspb.Progress,
+	prevStatementTime hlc.Timestamp,
+) (jobspb.Progress, hlc.Timestamp, []jobspb.ChangefeedBackfill, error) {
+	var backfillCmds []*tree.AlterChangefeedBackfill
+	for _, cmd := range alterCmds {
+		if v, ok := cmd.(*tree.AlterChangefeedBackfill); ok {
+			backfillCmds = append(backfillCmds, v)
+		}
+	}
+	if len(backfillCmds) == 0 {
+		return prevProgress, prevStatementTime, nil, nil
+	}
+
+	prevHighWater := jobProgress.GetHighWater()
+	jobChangefeedProgress := jobProgress.GetChangefeed()
+	if prevHighWater == nil || prevHighWater.IsEmpty() {
+		return prevProgress, prevStatementTime, nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`cannot backfill targets before the changefeed's initial scan completes`)
+	}
+	if jobChangefeedProgress != nil && jobChangefeedProgress.Checkpoint != nil &&
+		len(jobChangefeedProgress.Checkpoint.Spans) != 0 {
+		return prevProgress, prevStatementTime, nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`cannot backfill targets while the checkpoint is non-empty, `+
+				`please unpause the changefeed and wait until the high watermark progresses past the current value %s to backfill these targets.`,
+			eval.TimestampToDecimalDatum(*prevHighWater).Decimal.String(),
+		)
+	}
+
+	// All targets are re-scanned as of the same time, which defaults to the
+	// high watermark.
+	scanTime := *prevHighWater
+	var asOfSet bool
+	for _, cmd := range backfillCmds {
+		if cmd.AsOf.Expr == nil {
+			continue
+		}
+		asOf, err := p.EvalAsOfTimestamp(ctx, cmd.AsOf)
+		if err != nil {
+			return prevProgress, prevStatementTime, nil, err
+		}
+		if asOfSet && asOf.Timestamp != scanTime {
+			return prevProgress, prevStatementTime, nil, pgerror.New(pgcode.InvalidParameterValue,
+				`all BACKFILL commands must use the same AS OF SYSTEM TIME`)
+		}
+		if prevHighWater.Less(asOf.Timestamp) {
+			return prevProgress, prevStatementTime, nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				`cannot backfill targets as of %s, which is after the high watermark %s`,
+				eval.TimestampToDecimalDatum(asOf.Timestamp).Decimal.String(),
+				eval.TimestampToDecimalDatum(*prevHighWater).Decimal.String(),
+			)
+		}
+		scanTime, asOfSet = asOf.Timestamp, true
+	}
+
+	changefeedProgress := prevProgress.GetChangefeed()
+	addedWithInitialScan := prevProgress.GetHighWater() == nil || prevProgress.GetHighWater().IsEmpty()
+	if addedWithInitialScan && scanTime != *prevHighWater {
+		return prevProgress, prevStatementTime, nil, errors.WithHint(
+			pgerror.Newf(pgcode.FeatureNotSupported,
+				`cannot backfill targets as of %s in the same statement as ADD ... WITH initial_scan, `+
+					`which scans the added targets as of the high watermark %s`,
+				eval.TimestampToDecimalDatum(scanTime).Decimal.String(),
+				eval.TimestampToDecimalDatum(*prevHighWater).Decimal.String(),
+			),
+			`omit AS OF SYSTEM TIME, or add the targets in a separate ALTER CHANGEFEED statement `+
+				`and resume the changefeed until its initial scan completes before backfilling`,
+		)
+	}
+
+	allDescs, err := backupresolver.LoadAllDescs(ctx, p.ExecCfg(), scanTime)
+	if err != nil {
+		return prevProgress, prevStatementTime, nil, err
+	}
+	descResolver, err := backupresolver.NewDescriptorResolver(allDescs)
+	if err != nil {
+		return prevProgress, prevStatementTime, nil, err
+	}
+
+	_, splitColFams := details.Opts[changefeedbase.OptSplitColumnFamilies]
+	var backfills []jobspb.ChangefeedBackfill
+	var backfilledIDs []descpb.ID
+	for _, cmd := range backfillCmds {
+		desc, found, err := getTargetDesc(ctx, p, descResolver, cmd.Target.TableName)
+		if err != nil {
+			return prevProgress, prevStatementTime, nil, err
+		}
+		if !found {
+			return prevProgress, prevStatementTime, nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				`target %q cannot be resolved as of the backfill time`, tree.ErrString(&cmd.Target))
+		}
+
+		var spec jobspb.ChangefeedTargetSpecification
+		var watched bool
+		for _, ts := range details.TargetSpecifications {
+			if ts.TableID == desc.GetID() {
+				spec, watched = ts, true
+				break
+			}
+		}
+		if !watched {
+			return prevProgress, prevStatementTime, nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				`target %q is not watched by changefeed`, tree.ErrString(&cmd.Target))
+		}
+		for _, id := range backfilledIDs {
+			if id == desc.GetID() {
+				return prevProgress, prevStatementTime, nil, pgerror.Newf(pgcode.InvalidParameterValue,
+					`target %q is backfilled more than once`, tree.ErrString(&cmd.Target))
+			}
+		}
+
+		backfill := jobspb.ChangefeedBackfill{TableID: desc.GetID()}
+		if cmd.Where != nil {
+			tableDesc := desc.(catalog.TableDescriptor)
+			tbName, err := getQualifiedTableNameObj(ctx, p.ExecCfg(), p.Txn(), tableDesc)
+			if err != nil {
+				return prevProgress, prevStatementTime, nil, err
+			}
+			sc := &tree.SelectClause{
+				Exprs: tree.SelectExprs{tree.StarSelectExpr()},
+				From:  tree.From{Tables: tree.TableExprs{&tbName}},
+				Where: cmd.Where,
+			}
+			norm, _, err := cdceval.NormalizeExpression(
+				ctx, p, tableDesc, scanTime, spec, sc, splitColFams,
+			)
+			if err != nil {
+				return prevProgress, prevStatementTime, nil, err
+			}
+			backfill.Select = cdceval.AsStringUnredacted(norm)
+		}
+		backfills = append(backfills, backfill)
+		backfilledIDs = append(backfilledIDs, desc.GetID())
+	}
+
+	// Checkpoint the spans of every target which is not being backfilled or
+	// added with an initial scan so that the initial scan skips them; they
+	// resume from the high watermark.
+	var checkpointSpans roachpb.SpanGroup
+	if addedWithInitialScan {
+		checkpointSpans.Add(changefeedProgress.Checkpoint.Spans...)
+	} else {
+		var targetIDs []descpb.ID
+		for _, ts := range details.TargetSpecifications {
+			targetIDs = append(targetIDs, ts.TableID)
+		}
+		checkpointSpans.Add(fetchSpansForDescs(p, targetIDs)...)
+	}
+	backfilledSpans := fetchSpansForDescs(p, backfilledIDs)
+	checkpointSpans.Sub(backfilledSpans...)
+
+	// Moving the protected timestamp record back cannot bring back data which
+	// has already been garbage collected, so check that the backfilled tables
+	// can still be read as of the scan time.
+	b := &kv.Batch{}
+	b.Header.Timestamp = scanTime
+	b.Header.MaxSpanRequestKeys = 1
+	for _, sp := range backfilledSpans {
+		b.Scan(sp.Key, sp.EndKey)
+	}
+	if err := p.ExecCfg().DB.Run(ctx, b); err != nil {
+		if errors.HasType(err, (*kvpb.BatchTimestampBeforeGCError)(nil)) {
+			return prevProgress, prevStatementTime, nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+				`cannot backfill targets as of %s, which is before their garbage collection threshold`,
+				eval.TimestampToDecimalDatum(scanTime).Decimal.String())
+		}
+		return prevProgress, prevStatementTime, nil, err
+	}
+
+	// The changeFrontier protects data from the statement time until the
+	// backfill completes, after which it advances the record with the high
+	// watermark again.
+	pts := p.ExecCfg().ProtectedTimestampProvider.WithTxn(p.InternalSQLTxn())
+	ptsRecord := uuid.UUID{}
+	if changefeedProgress != nil {
+		ptsRecord = changefeedProgress.ProtectedTimestampRecord
+	}
+	if ptsRecord == uuid.Nil {
+		ptr := createProtectedTimestampRecord(
+			ctx, p.ExecCfg().Codec, jobID, AllTargets(details), details.LookupTableIDs, scanTime,
+		)
+		if err := pts.Protect(ctx, ptr); err != nil {
+			return prevProgress, prevStatementTime, nil, err
+		}
+		ptsRecord = ptr.ID.GetUUID()
+	} else if err := pts.UpdateTimestamp(ctx, ptsRecord, scanTime); err != nil {
+		return prevProgress, prevStatementTime, nil, err
+	}
+	newProgress := jobspb.Progress{
+		Progress: &jobspb.Progress_HighWater{},
+		Details: &jobspb.Progress_Changefeed{
+			Changefeed: &jobspb.ChangefeedProgress{
+				Checkpoint: &jobspb.ChangefeedProgress_Checkpoint{
+					Spans:     checkpointSpans.Slice(),
+					Timestamp: *prevHighWater,
+				},
+				ProtectedTimestampRecord: ptsRecord,
+			},
+		},
+	}
+
+	telemetry.CountBucketed(telemetryPath+`.backfilled_targets`, int64(len(backfills)))
+	return newProgress, scanTime, backfills, nil
+}
+
 func removeSpansFromProgress(prevProgress jobspb.Progress, spansToRemove []roachpb.Span) {
 	changefeedProgress := prevProgress.GetChangefeed()
 	if changefeedProgress == nil {
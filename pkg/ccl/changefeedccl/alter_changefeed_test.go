@@ -1464,6 +1464,103 @@ func TestAlterChangefeedInitialScan(t *testing.T) {
 	}
 }
 
+func TestAlterChangefeedBackfill(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, s TestServer, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(s.DB)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1), (2), (3)`)
+		sqlDB.Exec(t, `CREATE TABLE bar (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (1)`)
+		sqlDB.Exec(t, `CREATE TABLE baz (a INT PRIMARY KEY)`)
+
+		testFeed := feed(t, f, `CREATE CHANGEFEED FOR foo, bar WITH resolved = '1s'`)
+		defer closeFeed(t, testFeed)
+
+		assertPayloads(t, testFeed, []string{
+			`foo: [1]->{"after": {"a": 1}}`,
+			`foo: [2]->{"after": {"a": 2}}`,
+			`foo: [3]->{"after": {"a": 3}}`,
+			`bar: [1]->{"after": {"a": 1}}`,
+		})
+		expectResolvedTimestamp(t, testFeed)
+
+		feed, ok := testFeed.(cdctest.EnterpriseTestFeed)
+		require.True(t, ok)
+		registry := s.Server.JobRegistry().(*jobs.Registry)
+
+		sqlDB.ExpectErr(t, `cannot backfill targets of changefeed job \d+ with status running`,
+			fmt.Sprintf(`ALTER CHANGEFEED %d BACKFILL foo`, feed.JobID()))
+
+		sqlDB.Exec(t, `PAUSE JOB $1`, feed.JobID())
+		waitForJobStatus(sqlDB, t, feed.JobID(), `paused`)
+
+		sqlDB.ExpectErr(t, `target "baz" is not watched by changefeed`,
+			fmt.Sprintf(`ALTER CHANGEFEED %d BACKFILL baz`, feed.JobID()))
+		sqlDB.ExpectErr(t, `which is after the high watermark`,
+			fmt.Sprintf(`ALTER CHANGEFEED %d BACKFILL foo AS OF SYSTEM TIME '-1us'`, feed.JobID()))
+
+		var highWater, beforeHighWater string
+		sqlDB.QueryRow(t,
+			`SELECT high_water_timestamp::STRING, (high_water_timestamp - 1)::STRING FROM crdb_internal.jobs WHERE job_id = $1`,
+			feed.JobID(),
+		).Scan(&highWater, &beforeHighWater)
+		sqlDB.ExpectErr(t, `in the same statement as ADD ... WITH initial_scan`,
+			fmt.Sprintf(`ALTER CHANGEFEED %d ADD baz WITH initial_scan BACKFILL foo AS OF SYSTEM TIME '%s'`,
+				feed.JobID(), beforeHighWater))
+
+		// Targets added with an initial scan are scanned along with the
+		// backfilled targets.
+		sqlDB.Exec(t, `INSERT INTO baz VALUES (1)`)
+		sqlDB.Exec(t, fmt.Sprintf(`ALTER CHANGEFEED %d ADD baz WITH initial_scan BACKFILL foo WHERE a > 1`, feed.JobID()))
+
+		// The changefeed's protected timestamp record is moved to the scan time.
+		sqlDB.CheckQueryResults(t,
+			`SELECT ts::STRING FROM system.protected_ts_records WHERE meta_type = 'jobs'`,
+			[][]string{{highWater}},
+		)
+
+		job, err := registry.LoadJob(context.Background(), feed.JobID())
+		require.NoError(t, err)
+		require.Len(t, job.Details().(jobspb.ChangefeedDetails).Backfills, 1)
+
+		sqlDB.Exec(t, fmt.Sprintf(`RESUME JOB %d`, feed.JobID()))
+		waitForJobStatus(sqlDB, t, feed.JobID(), `running`)
+
+		// Only the rows of foo matching the predicate are emitted again.
+		assertPayloads(t, testFeed, []string{
+			`foo: [2]->{"after": {"a": 2}}`,
+			`foo: [3]->{"after": {"a": 3}}`,
+			`baz: [1]->{"after": {"a": 1}}`,
+		})
+
+		// The predicate does not apply to changes made after the backfill.
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0)`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (2)`)
+		assertPayloads(t, testFeed, []string{
+			`foo: [0]->{"after": {"a": 0}}`,
+			`bar: [2]->{"after": {"a": 2}}`,
+		})
+
+		// The backfills are dropped from the job details once the scan
+		// completes.
+		testutils.SucceedsSoon(t, func() error {
+			job, err := registry.LoadJob(context.Background(), feed.JobID())
+			if err != nil {
+				return err
+			}
+			if backfills := job.Details().(jobspb.ChangefeedDetails).Backfills; len(backfills) > 0 {
+				return errors.Newf("waiting for backfills to be cleared: %v", backfills)
+			}
+			return nil
+		})
+	}
+
+	cdcTest(t, testFn, feedTestForceSink("kafka"), feedTestNoExternalConnection)
+}
+
 // This test checks that the time used to get table descriptors in alter
 // changefeed is the time from which changefeed will resume (check
 // validateNewTargets for more info on how this time is calculated).
@@ -71,6 +71,10 @@ type batchingSink struct {
 	metrics metricsRecorder
 	knobs   batchingSinkKnobs
 
+	// deadLetters, if set, receives the messages of batches which could not be
+	// flushed after exhausting all retries.
+	deadLetters *deadLetterQueue
+
 	// eventCh is the channel used to send requests from the Sink caller routines
 	// to the batching routine.  Messages can either be a flushReq or a rowEvent.
 	eventCh chan interface{}
@@ -227,6 +231,14 @@ func (s *batchingSink) getConcreteType() sinkType {
 	return s.concreteType
 }
 
+// setDeadLetterQueue implements the deadLetterQueueSetter interface. It must be
+// called before any rows are emitted.
+func (s *batchingSink) setDeadLetterQueue(q *deadLetterQueue) {
+	s.deadLetters = q
+}
+
+var _ deadLetterQueueSetter = (*batchingSink)(nil)
+
 // sinkBatch stores an in-progress/complete batch of messages, along with
 // metadata related to the batch.
 type sinkBatch struct {
@@ -241,6 +253,12 @@ type sinkBatch struct {
 
 	alloc  kvevent.Alloc
 	hasher hash.Hash32
+
+	// messages retains the contents of the batch so that they may be sent to
+	// the dead letter queue if the batch cannot be flushed. It is only
+	// populated if the sink has a dead letter queue.
+	retainMessages bool
+	messages       []rowEvent
 }
 
 // FinalizePayload closes the writer to produce a payload that is ready to be
@@ -278,6 +296,11 @@ func (sb *sinkBatch) Append(e *rowEvent) {
 	sb.buffer.Append(e.key, e.val)
 
 	sb.keys.Add(hashToInt(sb.hasher, e.key))
+	if sb.retainMessages {
+		sb.messages = append(sb.messages, rowEvent{
+			key: e.key, val: e.val, topicDescriptor: e.topicDescriptor, mvcc: e.mvcc,
+		})
+	}
 	sb.numMessages += 1
 	sb.numKVBytes += len(e.key) + len(e.val)
 
@@ -298,9 +321,25 @@ func (s *batchingSink) newBatchBuffer(topic string) *sinkBatch {
 	batch := newSinkBatch()
 	batch.buffer = s.client.MakeBatchBuffer(topic)
 	batch.hasher = s.hasher
+	batch.retainMessages = s.deadLetters != nil
 	return batch
 }
 
+// deadLetterBatch sends the messages of a batch which failed to flush to the
+// dead letter queue. The flush error is returned if there is no dead letter
+// queue.
+func (s *batchingSink) deadLetterBatch(ctx context.Context, batch *sinkBatch, flushErr error) error {
+	if s.deadLetters == nil || ctx.Err() != nil {
+		return flushErr
+	}
+	log.Warningf(ctx, "sending %d messages to dead letter sink: %v", batch.numMessages, flushErr)
+	letters := make([]deadLetter, len(batch.messages))
+	for i := range batch.messages {
+		letters[i] = makeDeliveryDeadLetter(&batch.messages[i], flushErr)
+	}
+	return s.deadLetters.Enqueue(ctx, letters...)
+}
+
 // runBatchingWorker combines 1 or more row events into batches, sending the IO
 // requests out either once the batch is full or a flush request arrives.
 func (s *batchingSink) runBatchingWorker(ctx context.Context) {
@@ -330,8 +369,12 @@ func (s *batchingSink) runBatchingWorker(ctx context.Context) {
 	handleResult := func(result *ioResult) {
 		batch, _ := result.request.(*sinkBatch)
 
-		if result.err != nil {
-			s.handleError(result.err)
+		err := result.err
+		if err != nil {
+			err = s.deadLetterBatch(ctx, batch, err)
+			if err != nil {
+				s.handleError(err)
+			}
 		} else {
 			s.metrics.recordEmittedBatch(
 				batch.bufferTime, batch.numMessages, batch.mvcc, batch.numKVBytes, sinkDoesNotCompress,
@@ -340,7 +383,7 @@ func (s *batchingSink) runBatchingWorker(ctx context.Context) {
 
 		inflight -= batch.numMessages
 
-		if (result.err != nil || inflight == 0) && sinkFlushWaiter != nil {
+		if (err != nil || inflight == 0) && sinkFlushWaiter != nil {
 			close(sinkFlushWaiter)
 			sinkFlushWaiter = nil
 		}
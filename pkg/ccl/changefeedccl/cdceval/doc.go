@@ -70,6 +70,25 @@ consumed, we can determine if the row ought to be filtered.  This step is done t
 ensure that we correctly release resources for each event -- even the ones that
 are filtered out.
 
+The target table may be joined with other (lookup) tables:
+  SELECT o.*, c.region FROM orders AS o [LEFT] JOIN customers AS c ON c.id = o.customer_id
+The join condition must constrain every primary key column of the lookup table
+to a column of the target table.  Lookup joins are not planned by the optimizer.
+Instead, similar to cdc_prev, each lookup table row is exposed to the plan as a
+hidden tuple column (cdc_lookup_<alias>), and references to lookup table columns
+are rewritten to access that tuple:
+  SELECT o.*, (cdc_lookup_c).region FROM orders AS o
+When evaluating an event, CDC fetches the lookup row at the MVCC timestamp of the
+event (see lookupFetcher), so that the result reflects the state of the lookup
+table as of that row version.  Events without matching lookup row are filtered
+out by inner joins.  Lookups are not performed for deletion events.
+Lookup tables are protected from garbage collection by the protected timestamp
+record of the changefeed, along with its target tables.  Lookup rows are cached
+for the timestamps at which they are known not to have changed, so the events of
+a transaction, or events joined with the same lookup row, share a single read.
+Lookup table columns expanded by a star are named <alias>_<column> when the
+target table, or another lookup table, already has a column with that name.
+
 Virtual computed columns can be easily supported but currently are not.
 To support virtual computed columns we must ensure that the expression in that
 column references only the target changefeed column family.
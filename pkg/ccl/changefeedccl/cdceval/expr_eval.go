@@ -139,9 +139,10 @@ func (e *Evaluator) Eval(
 	ctx context.Context, updatedRow cdcevent.Row, prevRow cdcevent.Row,
 ) (projection cdcevent.Row, evalErr error) {
 	defer func() {
-		if evalErr != nil {
-			// If we can't evaluate a row, we are bound to keep failing.
-			// So mark error permanent.
+		// If we can't evaluate a row, we are bound to keep failing.
+		// So mark error permanent. The exception is a failure to fetch
+		// lookup join row, which may succeed when retried.
+		if evalErr != nil && !errors.Is(evalErr, errLookupFailed) {
 			evalErr = changefeedbase.WithTerminalError(evalErr)
 		}
 	}()
@@ -206,6 +207,19 @@ func (e *familyEvaluator) eval(
 		}
 	}
 
+	// Fetch lookup join rows.
+	for _, j := range e.norm.lookups {
+		d, err := j.lookupRow(ctx, updatedRow)
+		if err != nil {
+			return cdcevent.Row{}, err
+		}
+		if d == tree.DNull && !j.outer && !updatedRow.IsDeleted() {
+			// Inner lookup join did not match.
+			return cdcevent.Row{}, nil
+		}
+		encDatums = append(encDatums, rowenc.EncDatum{Datum: d})
+	}
+
 	// Push data into DistSQL.
 	if st := e.input.Push(encDatums, nil); st != execinfra.NeedMoreRows {
 		return cdcevent.Row{}, errors.Newf("familyEvaluator shutting down due to status %s", st)
@@ -296,8 +310,12 @@ func (e *familyEvaluator) preparePlan(
 			evalCtx.Annotations.Set(cdcAnnotationAddr, &e.rowEvalCtx)
 
 			e.norm.desc = e.currDesc
+			opts, err := e.norm.resolveLookupJoins(ctx, execCtx)
+			if err != nil {
+				return err
+			}
+
 			requiresPrev := e.prevDesc != nil
-			var opts []sql.CDCOption
 			if requiresPrev {
 				prevCol, err = newPrevColumnForDesc(e.prevDesc)
 				if err != nil {
@@ -346,7 +364,7 @@ func (e *familyEvaluator) setupProjection(presentation colinfo.ResultColumns) {
 // inputSpecForEventDescriptor returns input specification for the
 // event descriptor.
 func inputSpecForEventDescriptor(
-	ed *cdcevent.EventDescriptor, prevCol catalog.Column,
+	ed *cdcevent.EventDescriptor, prevCol catalog.Column, lookups []*lookupJoin,
 ) ([]*types.T, catalog.TableColMap, error) {
 	numCols := len(ed.ResultColumns()) + len(colinfo.AllSystemColumnDescs)
 	inputTypes := make([]*types.T, 0, numCols)
@@ -371,6 +389,12 @@ func inputSpecForEventDescriptor(
 		inputCols.Set(prevCol.GetID(), inputCols.Len())
 		inputTypes = append(inputTypes, prevCol.GetType())
 	}
+
+	// Setup lookup join columns.
+	for _, j := range lookups {
+		inputCols.Set(j.col.GetID(), inputCols.Len())
+		inputTypes = append(inputTypes, j.col.GetType())
+	}
 	return inputTypes, inputCols, nil
 }
 
@@ -380,7 +404,7 @@ func (e *familyEvaluator) executePlan(
 	ctx context.Context, plan sql.CDCExpressionPlan, prevCol catalog.Column,
 ) (inputReceiver execinfra.RowReceiver, err error) {
 	// Configure input.
-	inputTypes, inputCols, err := inputSpecForEventDescriptor(e.currDesc, prevCol)
+	inputTypes, inputCols, err := inputSpecForEventDescriptor(e.currDesc, prevCol, e.norm.lookups)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +83,9 @@ RETURNS FLOAT VOLATILE LANGUAGE SQL AS $$
   SELECT random()
 $$`)
 
+	sqlDB.Exec(t, `CREATE TABLE lookup (a INT PRIMARY KEY, label STRING)`)
+	sqlDB.Exec(t, `INSERT INTO lookup VALUES (1, 'one'), (3, 'three')`)
+
 	desc := cdctest.GetHydratedTableDescriptor(t, s.ExecutorConfig(), "foo")
 
 	type decodeExpectation struct {
@@ -190,6 +193,86 @@ $$`)
 				},
 			},
 		},
+		{
+			testName:   "main/lookup_join",
+			familyName: "main",
+			actions: []string{
+				"INSERT INTO foo (a, b) VALUES (1, 'lookup')",
+				"INSERT INTO foo (a, b) VALUES (2, 'lookup')",
+			},
+			stmt: "SELECT foo.a, l.label FROM foo JOIN lookup AS l ON l.a = foo.a",
+			expectMainFamily: []decodeExpectation{
+				{
+					keyValues: []string{"lookup", "1"},
+					allValues: map[string]string{"a": "1", "label": "one"},
+				},
+				{
+					expectFiltered: true,
+					keyValues:      []string{"lookup", "2"},
+				},
+			},
+		},
+		{
+			testName:   "main/lookup_join_updated",
+			familyName: "main",
+			actions: []string{
+				"INSERT INTO foo (a, b) VALUES (1, 'lookup updated')",
+				"INSERT INTO foo (a, b) VALUES (1, 'lookup updated again')",
+				"UPDATE lookup SET label = 'uno' WHERE a = 1",
+				"INSERT INTO foo (a, b) VALUES (1, 'lookup updated once more')",
+				"UPDATE lookup SET label = 'one' WHERE a = 1",
+			},
+			stmt: "SELECT foo.a, l.label FROM foo JOIN lookup AS l ON l.a = foo.a",
+			expectMainFamily: []decodeExpectation{
+				{
+					keyValues: []string{"lookup updated", "1"},
+					allValues: map[string]string{"a": "1", "label": "one"},
+				},
+				{
+					keyValues: []string{"lookup updated again", "1"},
+					allValues: map[string]string{"a": "1", "label": "one"},
+				},
+				{
+					keyValues: []string{"lookup updated once more", "1"},
+					allValues: map[string]string{"a": "1", "label": "uno"},
+				},
+			},
+		},
+		{
+			testName:   "main/lookup_join_star",
+			familyName: "main",
+			actions: []string{
+				"INSERT INTO foo (a, b) VALUES (1, 'lookup star')",
+			},
+			stmt: "SELECT * FROM foo JOIN lookup ON lookup.a = foo.a",
+			expectMainFamily: []decodeExpectation{
+				{
+					keyValues: []string{"lookup star", "1"},
+					allValues: map[string]string{
+						"a": "1", "b": "lookup star", "e": "inactive", "lookup_a": "1", "label": "one",
+					},
+				},
+			},
+		},
+		{
+			testName:   "main/left_lookup_join",
+			familyName: "main",
+			actions: []string{
+				"INSERT INTO foo (a, b) VALUES (2, 'left lookup')",
+				"INSERT INTO foo (a, b) VALUES (3, 'left lookup')",
+			},
+			stmt: "SELECT a, lookup.label FROM foo LEFT JOIN lookup ON lookup.a = a",
+			expectMainFamily: []decodeExpectation{
+				{
+					keyValues: []string{"left lookup", "2"},
+					allValues: map[string]string{"a": "2", "label": "NULL"},
+				},
+				{
+					keyValues: []string{"left lookup", "3"},
+					allValues: map[string]string{"a": "3", "label": "three"},
+				},
+			},
+		},
 		{
 			testName:   "main/not_closed",
 			familyName: "main",
@@ -0,0 +1,733 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package cdceval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/fetchpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// lookupJoin describes a lookup join in the changefeed expression:
+//
+//	SELECT ... FROM target [INNER | LEFT] JOIN dim [AS alias]
+//	  ON dim.pk1 = target.col1 [AND dim.pk2 = target.col2 ...]
+//
+// The join condition must constrain every primary key column of the lookup
+// table (dim) to a column of the changefeed target table. Lookup joins are not
+// planned by the optimizer. Instead, the lookup table row is fetched by CDC at
+// the MVCC timestamp of the event, and supplied to the plan as a hidden tuple
+// column named cdc_lookup_<alias>. All references to the lookup table columns
+// are rewritten to access that tuple.
+type lookupJoin struct {
+	alias     tree.Name      // Name used to reference lookup table columns.
+	tableName tree.TableName // Lookup table name.
+	outer     bool           // True if this is a LEFT join.
+
+	// keyCols maps lookup table column name to the target table column name.
+	keyCols map[tree.Name]tree.Name
+
+	// Fields below are set when lookup join is resolved.
+	desc      catalog.TableDescriptor // Lookup table descriptor at schema timestamp.
+	col       catalog.Column          // Tuple column supplying lookup row.
+	targetOrd []int                   // Target row ordinals for each lookup key column.
+	fetcher   *lookupFetcher
+}
+
+// lookupColumnName returns the name of the hidden tuple column
+// containing the lookup table row.
+func (j *lookupJoin) lookupColumnName() tree.Name {
+	return "cdc_lookup_" + j.alias
+}
+
+// extractLookupJoins extracts lookup joins from the changefeed FROM
+// clause. Returns the target table expression along with the list of lookup
+// joins (if any).
+func extractLookupJoins(from tree.TableExpr) (tree.TableExpr, []*lookupJoin, error) {
+	join, ok := from.(*tree.JoinTableExpr)
+	if !ok {
+		return from, nil, nil
+	}
+
+	target, joins, err := extractLookupJoins(join.Left)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	j := &lookupJoin{}
+	switch join.JoinType {
+	case "", tree.AstInner:
+	case tree.AstLeft:
+		j.outer = true
+	default:
+		return nil, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s JOIN not supported by CDC; only INNER and LEFT lookup joins are supported", join.JoinType)
+	}
+	if join.Hint != "" {
+		return nil, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"join hints not supported by CDC")
+	}
+
+	switch t := join.Right.(type) {
+	case *tree.TableName:
+		j.tableName, j.alias = *t, t.ObjectName
+	case *tree.AliasedTableExpr:
+		tn, ok := t.Expr.(*tree.TableName)
+		if !ok || len(t.As.Cols) > 0 || t.IndexFlags != nil {
+			return nil, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"unsupported lookup join table expression %s", tree.AsString(t))
+		}
+		j.tableName, j.alias = *tn, tn.ObjectName
+		if t.As.Alias != "" {
+			j.alias = t.As.Alias
+		}
+	default:
+		return nil, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"unsupported lookup join table expression %s", tree.AsString(t))
+	}
+
+	targetName := tableExprName(target)
+	if j.alias == targetName {
+		return nil, nil, pgerror.Newf(pgcode.DuplicateAlias,
+			"table name %q specified more than once", j.alias)
+	}
+	for _, other := range joins {
+		if other.alias == j.alias {
+			return nil, nil, pgerror.Newf(pgcode.DuplicateAlias,
+				"table name %q specified more than once", j.alias)
+		}
+	}
+
+	cond, ok := join.Cond.(*tree.OnJoinCond)
+	if !ok {
+		return nil, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"lookup join with %s must specify ON condition", j.alias)
+	}
+	if err := j.parseJoinCondition(targetName, cond.Expr); err != nil {
+		return nil, nil, err
+	}
+
+	return target, append(joins, j), nil
+}
+
+// tableExprName returns the name used to reference target table columns.
+func tableExprName(e tree.TableExpr) tree.Name {
+	switch t := e.(type) {
+	case *tree.TableName:
+		return t.ObjectName
+	case *tree.AliasedTableExpr:
+		if t.As.Alias != "" {
+			return t.As.Alias
+		}
+		return tableExprName(t.Expr)
+	default:
+		return ""
+	}
+}
+
+// parseJoinCondition parses ON condition, which must be a conjunction
+// of equalities between lookup table and target table columns.
+func (j *lookupJoin) parseJoinCondition(targetName tree.Name, cond tree.Expr) error {
+	unsupported := func(e tree.Expr) error {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"unsupported lookup join condition %s: only equality between %s columns "+
+				"and target table columns is supported", tree.AsString(e), j.alias)
+	}
+
+	// columnRef returns the table and column name referenced by the expression.
+	columnRef := func(e tree.Expr) (table tree.Name, col tree.Name, ok bool) {
+		n, ok := e.(*tree.UnresolvedName)
+		if !ok || n.Star {
+			return "", "", false
+		}
+		switch n.NumParts {
+		case 1:
+			return "", tree.Name(n.Parts[0]), true
+		case 2:
+			return tree.Name(n.Parts[1]), tree.Name(n.Parts[0]), true
+		default:
+			return "", "", false
+		}
+	}
+
+	j.keyCols = make(map[tree.Name]tree.Name)
+	var visit func(e tree.Expr) error
+	visit = func(e tree.Expr) error {
+		switch t := e.(type) {
+		case *tree.ParenExpr:
+			return visit(t.Expr)
+		case *tree.AndExpr:
+			if err := visit(t.Left); err != nil {
+				return err
+			}
+			return visit(t.Right)
+		case *tree.ComparisonExpr:
+			if t.Operator.Symbol != treecmp.EQ {
+				return unsupported(e)
+			}
+			lt, lc, lok := columnRef(t.Left)
+			rt, rc, rok := columnRef(t.Right)
+			if !lok || !rok {
+				return unsupported(e)
+			}
+			if rt == j.alias {
+				lt, lc, rt, rc = rt, rc, lt, lc
+			}
+			if lt != j.alias || !(rt == "" || rt == targetName) {
+				return unsupported(e)
+			}
+			if _, seen := j.keyCols[lc]; seen {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"lookup join condition references %s.%s more than once", j.alias, lc)
+			}
+			j.keyCols[lc] = rc
+			return nil
+		default:
+			return unsupported(e)
+		}
+	}
+	return visit(cond)
+}
+
+// targetColumns returns the list of target table columns used as lookup keys.
+func (j *lookupJoin) targetColumns() (cols []tree.Name) {
+	for _, c := range j.keyCols {
+		cols = append(cols, c)
+	}
+	return cols
+}
+
+// resolve resolves lookup join table, and prepares the tuple column
+// containing lookup table row. The target event descriptor is used
+// to determine the location of lookup key columns in the event.
+func (j *lookupJoin) resolve(
+	ctx context.Context,
+	execCtx sql.JobExecContext,
+	target *cdcevent.EventDescriptor,
+	colID descpb.ColumnID,
+) error {
+	desc, err := sql.ResolveCDCLookupTable(ctx, execCtx, &j.tableName)
+	if err != nil {
+		return err
+	}
+	if catalog.FindColumnByTreeName(target.TableDescriptor(), j.lookupColumnName()) != nil {
+		return pgerror.Newf(pgcode.DuplicateColumn,
+			"changefeed employs an internal, hidden column called %s, which must be renamed "+
+				"or dropped from the target table %s in order to use lookup join with %s",
+			j.lookupColumnName(), target.TableName, j.alias)
+	}
+
+	// Lookup join must constrain the entire primary key of the lookup table.
+	pk := desc.GetPrimaryIndex()
+	if pk.NumKeyColumns() != len(j.keyCols) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"lookup join condition must constrain all primary key columns of %s (%s)",
+			j.alias, pk.GetName())
+	}
+
+	targetOrd := make([]int, 0, pk.NumKeyColumns())
+	keyColIDs := make([]descpb.ColumnID, 0, pk.NumKeyColumns())
+	for i := 0; i < pk.NumKeyColumns(); i++ {
+		keyCol, err := catalog.MustFindColumnByID(desc, pk.GetKeyColumnID(i))
+		if err != nil {
+			return err
+		}
+		targetColName, ok := j.keyCols[keyCol.ColName()]
+		if !ok {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"lookup join condition must constrain primary key column %s.%s",
+				j.alias, keyCol.GetName())
+		}
+
+		ord := -1
+		for i, c := range target.ResultColumns() {
+			if c.Name == string(targetColName) {
+				ord = i
+				break
+			}
+		}
+		if ord < 0 {
+			return pgerror.Newf(pgcode.UndefinedColumn,
+				"column %q does not exist in target table %s", targetColName, target.TableName)
+		}
+		if typ := target.ResultColumns()[ord].Typ; !typ.Equivalent(keyCol.GetType()) {
+			return pgerror.Newf(pgcode.DatatypeMismatch,
+				"lookup join on %s.%s of type %s requires target column %s of the same type, found %s",
+				j.alias, keyCol.GetName(), keyCol.GetType().SQLString(), targetColName, typ.SQLString())
+		}
+		targetOrd = append(targetOrd, ord)
+		keyColIDs = append(keyColIDs, keyCol.GetID())
+	}
+
+	// The lookup row contains all visible, non-virtual columns.
+	var colIDs []descpb.ColumnID
+	var colTypes []*types.T
+	var colLabels []string
+	for _, c := range desc.VisibleColumns() {
+		if c.IsVirtual() {
+			continue
+		}
+		colIDs = append(colIDs, c.GetID())
+		colTypes = append(colTypes, c.GetType())
+		colLabels = append(colLabels, c.GetName())
+	}
+
+	// Lookup column is a hidden tuple column, just like cdc_prev.
+	j.desc = desc
+	j.targetOrd = targetOrd
+	j.col = &prevCol{
+		name: j.lookupColumnName(),
+		t:    types.MakeLabeledTuple(colTypes, colLabels),
+		id:   colID,
+	}
+	j.fetcher = newLookupFetcher(execCtx.ExecCfg(), desc.GetID(), j.tableName, keyColIDs, colIDs, j.col.GetType())
+	return nil
+}
+
+// lookupRow returns the datum containing lookup table row for the
+// specified event. Returns tree.DNull if the lookup table does not
+// contain matching row.
+func (j *lookupJoin) lookupRow(ctx context.Context, updated cdcevent.Row) (tree.Datum, error) {
+	// Deleted rows only contain primary key columns; we do not
+	// perform lookups for those events.
+	if updated.IsDeleted() {
+		return tree.DNull, nil
+	}
+
+	key := make(tree.Datums, len(j.targetOrd))
+	for i, ord := range j.targetOrd {
+		d, err := updated.DatumAt(ord)
+		if err != nil {
+			return nil, err
+		}
+		if d == tree.DNull {
+			// NULLs never match.
+			return tree.DNull, nil
+		}
+		key[i] = d
+	}
+	return j.fetcher.fetch(ctx, updated.MvccTimestamp, key)
+}
+
+// rewriteLookupReferences rewrites select clause, replacing references to the
+// lookup table columns with the tuple access of the lookup column. The
+// unqualified star is expanded to include lookup table columns, as are the
+// stars qualified by the lookup table alias. Expanded lookup table columns
+// whose name is already used by a target table column, or by a column of
+// another lookup table, are renamed to <alias>_<column>: changefeed encoders
+// key the output by column name, so duplicate names would shadow each other.
+func rewriteLookupReferences(
+	sc *tree.SelectClause, targetCols []cdcevent.ResultColumn, lookups []*lookupJoin,
+) (*tree.SelectClause, error) {
+	if len(lookups) == 0 {
+		return sc, nil
+	}
+
+	lookupColumnRef := func(alias tree.Name) (tree.Expr, bool) {
+		for _, j := range lookups {
+			if j.alias == alias {
+				return &tree.UnresolvedName{NumParts: 1, Parts: tree.NameParts{string(j.lookupColumnName())}}, true
+			}
+		}
+		return nil, false
+	}
+
+	usedNames := make(map[string]struct{}, len(targetCols))
+	for _, c := range targetCols {
+		usedNames[c.Name] = struct{}{}
+	}
+	expandLookup := func(exprs tree.SelectExprs, alias tree.Name) tree.SelectExprs {
+		var j *lookupJoin
+		for _, l := range lookups {
+			if l.alias == alias {
+				j = l
+			}
+		}
+		ref, _ := lookupColumnRef(alias)
+		for _, label := range j.col.GetType().TupleLabels() {
+			name := label
+			if _, used := usedNames[name]; used {
+				name = string(alias) + "_" + label
+			}
+			usedNames[name] = struct{}{}
+			exprs = append(exprs, tree.SelectExpr{
+				Expr: &tree.ColumnAccessExpr{
+					Expr:    &tree.ParenExpr{Expr: ref},
+					ColName: tree.Name(label),
+				},
+				As: tree.UnrestrictedName(name),
+			})
+		}
+		return exprs
+	}
+
+	// Expand stars.
+	expanded := *sc
+	expanded.Exprs = make(tree.SelectExprs, 0, len(sc.Exprs))
+	for _, e := range sc.Exprs {
+		switch t := e.Expr.(type) {
+		case tree.UnqualifiedStar:
+			expanded.Exprs = append(expanded.Exprs, e)
+			for _, j := range lookups {
+				expanded.Exprs = expandLookup(expanded.Exprs, j.alias)
+			}
+			continue
+		case *tree.UnresolvedName:
+			if t.Star && t.NumParts == 2 {
+				if _, ok := lookupColumnRef(tree.Name(t.Parts[1])); ok {
+					expanded.Exprs = expandLookup(expanded.Exprs, tree.Name(t.Parts[1]))
+					continue
+				}
+			}
+		}
+		expanded.Exprs = append(expanded.Exprs, e)
+	}
+
+	stmt, err := tree.SimpleStmtVisit(
+		&expanded,
+		func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+			n, ok := expr.(*tree.UnresolvedName)
+			if !ok || n.NumParts != 2 {
+				return true, expr, nil
+			}
+			ref, ok := lookupColumnRef(tree.Name(n.Parts[1]))
+			if !ok {
+				return true, expr, nil
+			}
+			if n.Star {
+				return false, &tree.TupleStar{Expr: ref}, nil
+			}
+			return false, &tree.ColumnAccessExpr{
+				Expr:    &tree.ParenExpr{Expr: ref},
+				ColName: tree.Name(n.Parts[0]),
+			}, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	rewritten, ok := stmt.(*tree.SelectClause)
+	if !ok {
+		return nil, errors.AssertionFailedf("unexpected result type %T", stmt)
+	}
+	return rewritten, nil
+}
+
+// errLookupFailed marks errors encountered while fetching lookup table
+// rows. Those errors are not necessarily terminal.
+var errLookupFailed = errors.New("lookup join failed")
+
+// lookupRowCacheSize is the number of lookup rows cached by a lookupFetcher.
+const lookupRowCacheSize = 4096
+
+// lookupFetcher fetches lookup table rows at the specified timestamp.
+// Similar to rowFetcherCache in cdcevent, it maintains a cache of
+// row.Fetchers, keyed by lookup table descriptor version. It also caches the
+// descriptor of the lookup table for the interval in which it is valid, and
+// the rows it fetched for the interval in which they are known not to have
+// changed, so that events of the same transaction, or of rows referencing
+// the same lookup row, don't each acquire a lease and read the row.
+type lookupFetcher struct {
+	execCfg   *sql.ExecutorConfig
+	tableID   descpb.ID
+	tableName tree.TableName
+	keyColIDs []descpb.ColumnID
+	colIDs    []descpb.ColumnID
+	tupleType *types.T
+
+	collection *descs.Collection
+	fetchers   *cache.UnorderedCache
+	rows       *cache.UnorderedCache
+	kvProvider row.KVProvider
+	alloc      tree.DatumAlloc
+
+	// desc is the last descriptor returned by descriptorAt, which is valid in
+	// [descValidFrom, descValidUntil).
+	desc           catalog.TableDescriptor
+	descValidFrom  hlc.Timestamp
+	descValidUntil hlc.Timestamp
+}
+
+// lookupRowKey is the key of cached lookup rows.
+type lookupRowKey struct {
+	version descpb.DescriptorVersion
+	key     string
+}
+
+// cachedLookupRow is a lookup row which was fetched at readTS, and is the
+// same at any timestamp in [validFrom, readTS].
+type cachedLookupRow struct {
+	row       tree.Datum
+	validFrom hlc.Timestamp
+	readTS    hlc.Timestamp
+}
+
+type cachedLookupFetcher struct {
+	tableDesc catalog.TableDescriptor
+	fetcher   row.Fetcher
+	keyColMap catalog.TableColMap
+	// tupleOrd maps each fetched column to the ordinal in the lookup tuple.
+	tupleOrd []int
+}
+
+func newLookupFetcher(
+	execCfg *sql.ExecutorConfig,
+	tableID descpb.ID,
+	tableName tree.TableName,
+	keyColIDs []descpb.ColumnID,
+	colIDs []descpb.ColumnID,
+	tupleType *types.T,
+) *lookupFetcher {
+	return &lookupFetcher{
+		execCfg:   execCfg,
+		tableID:   tableID,
+		tableName: tableName,
+		keyColIDs: keyColIDs,
+		colIDs:    colIDs,
+		tupleType: tupleType,
+		fetchers:  cache.NewUnorderedCache(cdcevent.DefaultCacheConfig),
+		rows: cache.NewUnorderedCache(cache.Config{
+			Policy:      cache.CacheLRU,
+			ShouldEvict: func(size int, _, _ interface{}) bool { return size > lookupRowCacheSize },
+		}),
+	}
+}
+
+// fetch returns lookup table row for the specified primary key, as of
+// specified timestamp. The row is read with a non-transactional request at
+// that timestamp, so fetching does not create a transaction per event.
+func (f *lookupFetcher) fetch(
+	ctx context.Context, ts hlc.Timestamp, key tree.Datums,
+) (_ tree.Datum, retErr error) {
+	defer func() {
+		switch {
+		case retErr == nil:
+		case errors.Is(retErr, catalog.ErrDescriptorDropped):
+			retErr = changefeedbase.WithTerminalError(retErr)
+		case errors.HasType(retErr, (*kvpb.BatchTimestampBeforeGCError)(nil)):
+			// The protected timestamp record of the changefeed covers the lookup
+			// tables, but changefeeds created before lookup tables were protected
+			// may still lag behind their GC threshold. Retrying would fail
+			// forever.
+			retErr = changefeedbase.WithTerminalError(errors.Wrapf(retErr,
+				"lookup table %s was garbage collected at the event timestamp %s", &f.tableName, ts))
+		default:
+			retErr = errors.Mark(retErr, errLookupFailed)
+		}
+	}()
+
+	desc, err := f.descriptorAt(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+	cf, err := f.fetcherForDesc(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPrefix := rowenc.MakeIndexKeyPrefix(f.execCfg.Codec, desc.GetID(), desc.GetPrimaryIndexID())
+	rowKey, containsNull, err := rowenc.EncodeIndexKey(desc, desc.GetPrimaryIndex(), cf.keyColMap, key, keyPrefix)
+	if err != nil || containsNull {
+		return tree.DNull, err
+	}
+
+	// Rows of tables with user defined types aren't cached, as the types may
+	// change without a new version of the table.
+	cacheRow := !catalog.MaybeRequiresHydration(desc)
+	cacheKey := lookupRowKey{version: desc.GetVersion(), key: string(rowKey)}
+	if v, ok := f.rows.Get(cacheKey); ok && cacheRow {
+		if r := v.(*cachedLookupRow); r.validFrom.LessEq(ts) && ts.LessEq(r.readTS) {
+			return r.row, nil
+		}
+	}
+	d, validFrom, err := f.fetchRow(ctx, cf, desc, roachpb.Key(rowKey), ts)
+	if err != nil {
+		return nil, err
+	}
+	if cacheRow {
+		f.rows.Add(cacheKey, &cachedLookupRow{row: d, validFrom: validFrom, readTS: ts})
+	}
+	return d, nil
+}
+
+// fetchRow reads the lookup row with the specified key as of the specified
+// timestamp. It also returns the earliest timestamp from which the row is
+// known to be the same.
+func (f *lookupFetcher) fetchRow(
+	ctx context.Context,
+	cf *cachedLookupFetcher,
+	desc catalog.TableDescriptor,
+	rowKey roachpb.Key,
+	ts hlc.Timestamp,
+) (_ tree.Datum, validFrom hlc.Timestamp, _ error) {
+	b := &kv.Batch{}
+	b.Header.Timestamp = ts
+	b.Scan(rowKey, rowKey.PrefixEnd())
+	if err := f.execCfg.DB.Run(ctx, b); err != nil {
+		return nil, hlc.Timestamp{}, err
+	}
+	kvs := b.Results[0].Rows
+	if len(kvs) == 0 {
+		// The row may have been deleted at any timestamp.
+		return tree.DNull, ts, nil
+	}
+	// No version of the row's keys was written between the newest version we
+	// read and the read timestamp. With several column families, a family
+	// which isn't returned may have been deleted in between, though.
+	validFrom = ts
+	if desc.NumFamilies() == 1 {
+		validFrom = kvs[0].Value.Timestamp
+	}
+
+	f.kvProvider.KVs = f.kvProvider.KVs[:0]
+	for _, kv := range kvs {
+		f.kvProvider.KVs = append(f.kvProvider.KVs, roachpb.KeyValue{Key: kv.Key, Value: *kv.Value})
+	}
+	if err := cf.fetcher.ConsumeKVProvider(ctx, &f.kvProvider); err != nil {
+		return nil, hlc.Timestamp{}, err
+	}
+	encDatums, _, err := cf.fetcher.NextRow(ctx)
+	if err != nil {
+		return nil, hlc.Timestamp{}, err
+	}
+	if encDatums == nil {
+		return tree.DNull, validFrom, nil
+	}
+
+	tupleTypes := f.tupleType.TupleContents()
+	datums := make(tree.Datums, len(tupleTypes))
+	for i := range datums {
+		datums[i] = tree.DNull
+	}
+	for i, ord := range cf.tupleOrd {
+		if err := encDatums[i].EnsureDecoded(tupleTypes[ord], &f.alloc); err != nil {
+			return nil, hlc.Timestamp{}, err
+		}
+		datums[ord] = encDatums[i].Datum
+	}
+	return tree.NewDTuple(f.tupleType, datums...), validFrom, nil
+}
+
+// descriptorAt returns the lookup table descriptor as of the specified
+// timestamp. The descriptor is reused for the timestamps at which its lease
+// is valid, so a lease is only acquired when the events move past it.
+func (f *lookupFetcher) descriptorAt(
+	ctx context.Context, ts hlc.Timestamp,
+) (catalog.TableDescriptor, error) {
+	if f.desc != nil && f.descValidFrom.LessEq(ts) && ts.Less(f.descValidUntil) {
+		return f.desc, nil
+	}
+	leased, err := f.execCfg.LeaseManager.Acquire(ctx, ts, f.tableID)
+	if err != nil {
+		return nil, err
+	}
+	desc := leased.Underlying().(catalog.TableDescriptor)
+	validUntil := leased.Expiration()
+	// Immediately release the lease, since we only need it for the exact
+	// timestamp requested.
+	leased.Release(ctx)
+	if catalog.MaybeRequiresHydration(desc) {
+		if desc, err = f.hydratedDescriptorAt(ctx, ts); err != nil {
+			return nil, err
+		}
+		// The types referenced by the table may change without a new version
+		// of the table.
+		validUntil = ts.Next()
+	}
+	f.desc, f.descValidFrom, f.descValidUntil = desc, desc.GetModificationTime(), validUntil
+	return desc, nil
+}
+
+// hydratedDescriptorAt returns the lookup table descriptor as of the
+// specified timestamp, with its user defined types hydrated.
+func (f *lookupFetcher) hydratedDescriptorAt(
+	ctx context.Context, ts hlc.Timestamp,
+) (desc catalog.TableDescriptor, _ error) {
+	// Hydrating user defined types requires a transaction, see refreshUDT in
+	// cdcevent.
+	if f.collection == nil {
+		f.collection = f.execCfg.CollectionFactory.NewCollection(ctx)
+	}
+	defer f.collection.ReleaseAll(ctx)
+	if err := f.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		if err := txn.SetFixedTimestamp(ctx, ts); err != nil {
+			return err
+		}
+		var err error
+		desc, err = f.collection.ByIDWithLeased(txn).WithoutNonPublic().Get().Table(ctx, f.tableID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// fetcherForDesc returns row.Fetcher for the specified descriptor version.
+func (f *lookupFetcher) fetcherForDesc(
+	ctx context.Context, desc catalog.TableDescriptor,
+) (*cachedLookupFetcher, error) {
+	idVer := cdcevent.CacheKey{ID: desc.GetID(), Version: desc.GetVersion()}
+	if v, ok := f.fetchers.Get(idVer); ok {
+		cf := v.(*cachedLookupFetcher)
+		if catalog.UserDefinedTypeColsHaveSameVersion(cf.tableDesc, desc) {
+			return cf, nil
+		}
+	}
+
+	cf := &cachedLookupFetcher{tableDesc: desc}
+	for i, id := range f.keyColIDs {
+		cf.keyColMap.Set(id, i)
+	}
+
+	// Columns may have been dropped from the lookup table since the
+	// expression was planned; such columns are returned as NULLs.
+	var fetchCols []descpb.ColumnID
+	for ord, id := range f.colIDs {
+		if c := catalog.FindColumnByID(desc, id); c != nil && c.Public() {
+			fetchCols = append(fetchCols, id)
+			cf.tupleOrd = append(cf.tupleOrd, ord)
+		}
+	}
+
+	var spec fetchpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(
+		&spec, f.execCfg.Codec, desc, desc.GetPrimaryIndex(), fetchCols,
+	); err != nil {
+		return nil, err
+	}
+	if err := cf.fetcher.Init(ctx, row.FetcherInitArgs{
+		WillUseKVProvider: true,
+		Alloc:             &f.alloc,
+		Spec:              &spec,
+	}); err != nil {
+		return nil, err
+	}
+
+	f.fetchers.Add(idVer, cf)
+	return cf, nil
+}
@@ -81,10 +81,15 @@ func normalizeExpression(
 		return nil, false, err
 	}
 
+	opts, err := norm.resolveLookupJoins(ctx, execCtx)
+	if err != nil {
+		return nil, false, changefeedbase.WithTerminalError(err)
+	}
+	opts = append(opts, sql.WithExtraColumn(prevCol))
+
 	// Plan execution; this steps triggers optimizer, which
 	// performs various validation steps.
-	plan, err := sql.PlanCDCExpression(ctx, execCtx,
-		norm.SelectStatementForFamily(), sql.WithExtraColumn(prevCol))
+	plan, err := sql.PlanCDCExpression(ctx, execCtx, norm.SelectStatementForFamily(), opts...)
 	if err != nil {
 		return nil, false, err
 	}
@@ -132,8 +137,13 @@ func SpansForExpression(
 				return err
 			}
 
-			plan, err = sql.PlanCDCExpression(ctx, execCtx,
-				norm.SelectStatementForFamily(), sql.WithExtraColumn(prevCol))
+			opts, err := norm.resolveLookupJoins(ctx, execCtx)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, sql.WithExtraColumn(prevCol))
+
+			plan, err = sql.PlanCDCExpression(ctx, execCtx, norm.SelectStatementForFamily(), opts...)
 			return err
 
 		},
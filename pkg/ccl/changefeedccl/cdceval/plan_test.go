@@ -335,8 +335,12 @@ func normalizeAndPlan(
 			if err != nil {
 				return err
 			}
-			plan, err = sql.PlanCDCExpression(ctx, execCtx,
-				norm.SelectStatementForFamily(), sql.WithExtraColumn(prevCol))
+			opts, err := norm.resolveLookupJoins(ctx, execCtx)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, sql.WithExtraColumn(prevCol))
+			plan, err = sql.PlanCDCExpression(ctx, execCtx, norm.SelectStatementForFamily(), opts...)
 			return err
 		},
 	); err != nil {
@@ -0,0 +1,165 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package cdceval
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/valueside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/lib/pq/oid"
+)
+
+// RangeFeedPredicateForExpression returns the conditions of the WHERE clause
+// of a changefeed expression that the rangefeeds of the changefeed can push
+// down to the servers. These are the equalities between a column of the table
+// and a constant that the expression is a conjunction of: equalities on a
+// prefix of the primary key columns become a key prefix, and equalities on
+// other stored columns become column values. The changefeed still evaluates
+// the whole expression on the events it receives, so all other conditions are
+// simply left out.
+func RangeFeedPredicateForExpression(
+	ctx context.Context, codec keys.SQLCodec, desc catalog.TableDescriptor, sc *tree.SelectClause,
+) (keyPrefixes []roachpb.Key, columnValues []kvpb.RangeFeedColumnValue, _ error) {
+	if sc.Where == nil {
+		return nil, nil, nil
+	}
+	semaCtx := tree.MakeSemaContext()
+	equalities := make(map[descpb.ColumnID]tree.Datum)
+	for _, e := range conjuncts(sc.Where.Expr) {
+		col, d := columnEquality(ctx, &semaCtx, desc, e)
+		if col == nil {
+			continue
+		}
+		if _, ok := equalities[col.GetID()]; !ok {
+			equalities[col.GetID()] = d
+		}
+	}
+	if len(equalities) == 0 {
+		return nil, nil, nil
+	}
+
+	primaryIndex := desc.GetPrimaryIndex()
+	prefix := rowenc.MakeIndexKeyPrefix(codec, desc.GetID(), primaryIndex.GetID())
+	var numPrefixColumns int
+	for i := 0; i < primaryIndex.NumKeyColumns(); i++ {
+		d, ok := equalities[primaryIndex.GetKeyColumnID(i)]
+		if !ok {
+			break
+		}
+		dir, err := catalogkeys.IndexColumnEncodingDirection(primaryIndex.GetKeyColumnDirection(i))
+		if err != nil {
+			return nil, nil, err
+		}
+		if prefix, err = keyside.Encode(prefix, d, dir); err != nil {
+			return nil, nil, err
+		}
+		numPrefixColumns++
+	}
+	if numPrefixColumns > 0 {
+		keyPrefixes = []roachpb.Key{prefix}
+	}
+
+	keyColumns := primaryIndex.CollectKeyColumnIDs()
+	for id, d := range equalities {
+		// The key columns are not part of the value.
+		if keyColumns.Contains(id) {
+			continue
+		}
+		value, err := valueside.Encode(nil, valueside.NoColumnID, d, nil /* scratch */)
+		if err != nil {
+			return nil, nil, err
+		}
+		columnValues = append(columnValues, kvpb.RangeFeedColumnValue{
+			ColumnID: uint32(id),
+			Value:    value,
+		})
+	}
+	sort.Slice(columnValues, func(i, j int) bool {
+		return columnValues[i].ColumnID < columnValues[j].ColumnID
+	})
+	return keyPrefixes, columnValues, nil
+}
+
+// conjuncts returns the expressions that the given expression is a
+// conjunction of.
+func conjuncts(e tree.Expr) []tree.Expr {
+	switch t := e.(type) {
+	case *tree.AndExpr:
+		return append(conjuncts(t.Left), conjuncts(t.Right)...)
+	case *tree.ParenExpr:
+		return conjuncts(t.Expr)
+	}
+	return []tree.Expr{e}
+}
+
+// columnEquality returns the column and the value of the given expression if
+// it is an equality between a stored column of the table and a constant, and
+// nil otherwise. Only columns of types whose values have a single encoding are
+// considered, as the predicate compares the encoded values.
+func columnEquality(
+	ctx context.Context, semaCtx *tree.SemaContext, desc catalog.TableDescriptor, e tree.Expr,
+) (catalog.Column, tree.Datum) {
+	cmp, ok := e.(*tree.ComparisonExpr)
+	if !ok || cmp.Operator.Symbol != treecmp.EQ {
+		return nil, nil
+	}
+	name, constant := cmp.Left, cmp.Right
+	if _, ok := name.(*tree.UnresolvedName); !ok {
+		name, constant = constant, name
+	}
+	n, ok := name.(*tree.UnresolvedName)
+	if !ok || n.Star || n.NumParts != 1 {
+		return nil, nil
+	}
+	col := catalog.FindColumnByName(desc, n.Parts[0])
+	if col == nil || !col.Public() || col.IsVirtual() || col.IsSystemColumn() {
+		return nil, nil
+	}
+	typ := col.GetType()
+	switch typ.Family() {
+	case types.IntFamily, types.BoolFamily, types.BytesFamily, types.UuidFamily:
+	case types.StringFamily:
+		if typ.Oid() != oid.T_text {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+	var d tree.Datum
+	switch t := constant.(type) {
+	case tree.Constant:
+		resolved, err := t.ResolveAsType(ctx, semaCtx, typ)
+		if err != nil {
+			return nil, nil
+		}
+		if d, ok = resolved.(tree.Datum); !ok {
+			return nil, nil
+		}
+	case *tree.DBool:
+		d = t
+	default:
+		return nil, nil
+	}
+	if d == tree.DNull || !d.ResolvedType().Equivalent(typ) {
+		return nil, nil
+	}
+	return col, d
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package cdceval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdctest"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeFeedPredicateForExpression(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer srv.Stopper().Stop(context.Background())
+	s := srv.ApplicationLayer()
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE TABLE foo (
+a INT,
+b STRING,
+c INT,
+d DECIMAL,
+e INT AS (c + 1) VIRTUAL,
+PRIMARY KEY (a, b)
+)`)
+
+	codec := s.ExecutorConfig().(sql.ExecutorConfig).Codec
+	desc := cdctest.GetHydratedTableDescriptor(t, s.ExecutorConfig(), "foo")
+	indexPrefix := roachpb.Key(rowenc.MakeIndexKeyPrefix(codec, desc.GetID(), desc.GetPrimaryIndexID()))
+	intValue := func(id uint32, v int64) kvpb.RangeFeedColumnValue {
+		return kvpb.RangeFeedColumnValue{
+			ColumnID: id, Value: encoding.EncodeIntValue(nil, encoding.NoColumnID, v),
+		}
+	}
+
+	for _, tc := range []struct {
+		name         string
+		stmt         string
+		keyPrefixes  []roachpb.Key
+		columnValues []kvpb.RangeFeedColumnValue
+	}{
+		{
+			name: "no where",
+			stmt: "SELECT * FROM foo",
+		},
+		{
+			name:         "column value",
+			stmt:         "SELECT * FROM foo WHERE c = 5",
+			columnValues: []kvpb.RangeFeedColumnValue{intValue(3, 5)},
+		},
+		{
+			name: "key prefix",
+			stmt: "SELECT * FROM foo WHERE 1 = a AND (c = 5 AND b = 'x')",
+			keyPrefixes: []roachpb.Key{encoding.EncodeStringAscending(
+				encoding.EncodeVarintAscending(indexPrefix.Clone(), 1), "x")},
+			columnValues: []kvpb.RangeFeedColumnValue{intValue(3, 5)},
+		},
+		{
+			name:        "partial key prefix",
+			stmt:        "SELECT * FROM foo WHERE a = 1 AND c > 5",
+			keyPrefixes: []roachpb.Key{encoding.EncodeVarintAscending(indexPrefix.Clone(), 1)},
+		},
+		{
+			name: "not a key prefix",
+			stmt: "SELECT * FROM foo WHERE b = 'x'",
+		},
+		{
+			name: "disjunction",
+			stmt: "SELECT * FROM foo WHERE a = 1 OR c = 5",
+		},
+		{
+			name: "unsupported columns",
+			stmt: "SELECT * FROM foo WHERE d = 1.0 AND e = 2 AND cdc_is_delete() = false",
+		},
+		{
+			name: "previous row",
+			stmt: "SELECT * FROM foo WHERE (cdc_prev).c = 5",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sc, err := ParseChangefeedExpression(tc.stmt)
+			require.NoError(t, err)
+			keyPrefixes, columnValues, err := RangeFeedPredicateForExpression(
+				context.Background(), codec, desc, sc)
+			require.NoError(t, err)
+			require.Equal(t, tc.keyPrefixes, keyPrefixes)
+			require.Equal(t, tc.columnValues, columnValues)
+		})
+	}
+}
@@ -32,12 +32,61 @@ import (
 type NormalizedSelectClause struct {
 	*tree.SelectClause
 	desc *cdcevent.EventDescriptor
+
+	// lookups is the list of lookup joins, and planned is the select clause
+	// with lookup joins rewritten as accesses to the lookup columns. Both are
+	// set by resolveLookupJoins, and only if the expression has lookup joins.
+	lookups []*lookupJoin
+	planned *tree.SelectClause
+}
+
+// resolveLookupJoins resolves lookup joins used by this expression, and returns
+// the list of options to supply lookup rows to the plan.
+func (n *NormalizedSelectClause) resolveLookupJoins(
+	ctx context.Context, execCtx sql.JobExecContext,
+) ([]sql.CDCOption, error) {
+	target, lookups, err := extractLookupJoins(n.From.Tables[0])
+	if err != nil || len(lookups) == 0 {
+		return nil, err
+	}
+
+	// Column IDs following the ID reserved for cdc_prev column.
+	nextColID := n.desc.TableDescriptor().GetNextColumnID() + 1
+	opts := make([]sql.CDCOption, 0, len(lookups))
+	for i, j := range lookups {
+		if err := j.resolve(ctx, execCtx, n.desc, nextColID+descpb.ColumnID(i)); err != nil {
+			return nil, err
+		}
+		opts = append(opts, sql.WithExtraColumn(j.col))
+	}
+
+	planned, err := rewriteLookupReferences(n.SelectClause, n.desc.ResultColumns(), lookups)
+	if err != nil {
+		return nil, err
+	}
+	planned.From.Tables = tree.TableExprs{target}
+	n.lookups, n.planned = lookups, planned
+	return opts, nil
+}
+
+// LookupTableIDs returns the IDs of the lookup tables joined by this
+// expression. It is only set once lookup joins are resolved.
+func (n *NormalizedSelectClause) LookupTableIDs() (ids descpb.IDs) {
+	for _, j := range n.lookups {
+		ids = append(ids, j.desc.GetID())
+	}
+	return ids
 }
 
 // SelectStatementForFamily returns tree.Select representing this object.
 func (n *NormalizedSelectClause) SelectStatementForFamily() *tree.Select {
+	selectClause := n.SelectClause
+	if n.planned != nil {
+		selectClause = n.planned
+	}
+
 	if !n.desc.HasOtherFamilies {
-		return &tree.Select{Select: n.SelectClause}
+		return &tree.Select{Select: selectClause}
 	}
 
 	// Configure index flags to restrict access to specific column family. To do
@@ -45,10 +94,10 @@ func (n *NormalizedSelectClause) SelectStatementForFamily() *tree.Select {
 	// make sure that when we do that, we do not mutate underlying select clause.
 	// This is done so that the same NormalizedSelectClause can be used to build
 	// expression evaluation for different table column families.
-	sc := *n.SelectClause
-	sc.From.Tables = append(tree.TableExprs(nil), n.SelectClause.From.Tables...)
+	sc := *selectClause
+	sc.From.Tables = append(tree.TableExprs(nil), selectClause.From.Tables...)
 	sc.From.Tables[0] = &tree.AliasedTableExpr{
-		Expr:       n.SelectClause.From.Tables[0],
+		Expr:       selectClause.From.Tables[0],
 		IndexFlags: &tree.IndexFlags{FamilyID: &n.desc.FamilyID},
 	}
 
@@ -91,12 +140,17 @@ func normalizeAndValidateSelectForTarget(
 			target.TableID, desc.GetID())
 	}
 
+	_, lookups, err := extractLookupJoins(sc.From.Tables[0])
+	if err != nil {
+		return nil, err
+	}
+
 	columnVisitor := checkColumnsVisitor{
 		desc:         desc,
 		splitColFams: splitColFams,
+		lookups:      lookups,
 	}
-	err := columnVisitor.FindColumnFamilies(sc)
-	if err != nil {
+	if err := columnVisitor.FindColumnFamilies(sc); err != nil {
 		return nil, err
 	}
 	target, err = getExpressionTargetSpecification(desc, target, &columnVisitor)
@@ -343,11 +397,26 @@ func normalizeSelectClause(
 type checkColumnsVisitor struct {
 	err          error
 	desc         catalog.TableDescriptor
+	lookups      []*lookupJoin
 	columns      []descpb.ColumnID
 	seenStar     bool
 	splitColFams bool
 }
 
+// isLookupReference returns true if the table name refers to the lookup
+// join table.
+func (c *checkColumnsVisitor) isLookupReference(tn *tree.UnresolvedObjectName) bool {
+	if tn == nil {
+		return false
+	}
+	for _, j := range c.lookups {
+		if tn.NumParts == 1 && tree.Name(tn.Object()) == j.alias {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *checkColumnsVisitor) VisitCols(expr tree.Expr) (bool, tree.Expr) {
 	switch e := expr.(type) {
 	case *tree.UnresolvedName:
@@ -359,6 +428,9 @@ func (c *checkColumnsVisitor) VisitCols(expr tree.Expr) (bool, tree.Expr) {
 		return c.VisitCols(vn)
 
 	case *tree.ColumnItem:
+		if c.isLookupReference(e.TableName) {
+			return false, expr
+		}
 		col, err := catalog.MustFindColumnByTreeName(c.desc, e.ColumnName)
 		if err != nil {
 			c.err = err
@@ -366,7 +438,12 @@ func (c *checkColumnsVisitor) VisitCols(expr tree.Expr) (bool, tree.Expr) {
 		}
 
 		c.columns = append(c.columns, col.GetID())
-	case tree.UnqualifiedStar, *tree.AllColumnsSelector:
+	case *tree.AllColumnsSelector:
+		if c.isLookupReference(e.TableName) {
+			return false, expr
+		}
+		c.seenStar = true
+	case tree.UnqualifiedStar:
 		c.seenStar = true
 	}
 	return true, expr
@@ -377,5 +454,19 @@ func (c *checkColumnsVisitor) FindColumnFamilies(sc *tree.SelectClause) error {
 		recurse, newExpr = c.VisitCols(expr)
 		return recurse, newExpr, nil
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Target columns used as lookup keys are referenced by the expression.
+	for _, j := range c.lookups {
+		for _, name := range j.targetColumns() {
+			col, err := catalog.MustFindColumnByTreeName(c.desc, name)
+			if err != nil {
+				return err
+			}
+			c.columns = append(c.columns, col.GetID())
+		}
+	}
+	return nil
 }
@@ -46,11 +46,14 @@ func TestNormalizeAndValidate(t *testing.T) {
 		`CREATE TABLE other.foo (a INT)`,
 		`CREATE TABLE baz (a INT PRIMARY KEY, b INT, c STRING, FAMILY most (a, b), FAMILY only_c (c))`,
 		`CREATE TABLE bop (a INT, b INT, c STRING, FAMILY most (a, b), FAMILY only_c (c), primary key (a, b))`,
+		`CREATE TABLE customers (id INT PRIMARY KEY, region STRING)`,
+		`CREATE TABLE orders (id INT PRIMARY KEY, customer_id INT, total INT)`,
 	)
 
 	fooDesc := cdctest.GetHydratedTableDescriptor(t, s.ExecutorConfig(), "foo")
 	bazDesc := cdctest.GetHydratedTableDescriptor(t, s.ExecutorConfig(), "baz")
 	bopDesc := cdctest.GetHydratedTableDescriptor(t, s.ExecutorConfig(), "bop")
+	ordersDesc := cdctest.GetHydratedTableDescriptor(t, s.ExecutorConfig(), "orders")
 
 	ctx := context.Background()
 	execCfg := s.ExecutorConfig().(sql.ExecutorConfig)
@@ -200,6 +203,44 @@ func TestNormalizeAndValidate(t *testing.T) {
 			expectStmt:   "SELECT pi() FROM baz",
 			splitColFams: false,
 		},
+		{
+			name: "lookup join",
+			desc: ordersDesc,
+			stmt: "SELECT o.id, c.region FROM orders AS o JOIN customers AS c ON c.id = o.customer_id " +
+				"WHERE c.region = 'us'",
+			expectStmt: "SELECT o.id, c.region FROM orders AS o JOIN customers AS c ON c.id = o.customer_id " +
+				"WHERE c.region = 'us'",
+		},
+		{
+			name:       "left lookup join with star",
+			desc:       ordersDesc,
+			stmt:       "SELECT * FROM orders LEFT JOIN customers ON customer_id = customers.id",
+			expectStmt: "SELECT * FROM orders LEFT JOIN customers ON customer_id = customers.id",
+		},
+		{
+			name:      "reject full lookup join",
+			desc:      ordersDesc,
+			stmt:      "SELECT * FROM orders FULL JOIN customers ON customers.id = customer_id",
+			expectErr: "FULL JOIN not supported by CDC",
+		},
+		{
+			name:      "reject non equality lookup join",
+			desc:      ordersDesc,
+			stmt:      "SELECT * FROM orders JOIN customers ON customers.id > customer_id",
+			expectErr: "unsupported lookup join condition",
+		},
+		{
+			name:      "lookup join must constrain primary key",
+			desc:      ordersDesc,
+			stmt:      "SELECT * FROM orders JOIN customers ON customers.region = customer_id",
+			expectErr: "lookup join condition must constrain primary key column customers.id",
+		},
+		{
+			name:      "lookup join constrains non key columns",
+			desc:      ordersDesc,
+			stmt:      "SELECT * FROM orders AS o JOIN customers AS c ON c.id = o.id AND c.region = o.total",
+			expectErr: "must constrain all primary key columns",
+		},
 		{
 			name:      "cdc_prev is not a function",
 			desc:      fooDesc,
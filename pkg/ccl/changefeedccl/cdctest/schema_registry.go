@@ -29,9 +29,10 @@ type SchemaRegistry struct {
 	statusCode int
 	mu         struct {
 		syncutil.Mutex
-		idAlloc  int32
-		schemas  map[int32]string
-		subjects map[string]int32
+		idAlloc     int32
+		schemas     map[int32]string
+		schemaTypes map[int32]string
+		subjects    map[string]int32
 	}
 }
 
@@ -73,6 +74,7 @@ func StartTestSchemaRegistryWithTLS(
 func makeTestSchemaRegistry() *SchemaRegistry {
 	r := &SchemaRegistry{}
 	r.mu.schemas = make(map[int32]string)
+	r.mu.schemaTypes = make(map[int32]string)
 	r.mu.subjects = make(map[string]int32)
 	r.server = httptest.NewUnstartedServer(http.HandlerFunc(r.requestHandler))
 	return r
@@ -105,13 +107,23 @@ func (r *SchemaRegistry) SchemaForSubject(subject string) string {
 	return r.mu.schemas[r.mu.subjects[subject]]
 }
 
-func (r *SchemaRegistry) registerSchema(subject string, schema string) int32 {
+// SchemaTypeForSubject returns the type of the schema registered for the
+// specified subject. Avro schemas are registered without a type, so an empty
+// string is returned for them.
+func (r *SchemaRegistry) SchemaTypeForSubject(subject string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mu.schemaTypes[r.mu.subjects[subject]]
+}
+
+func (r *SchemaRegistry) registerSchema(subject string, schemaType string, schema string) int32 {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	id := r.mu.idAlloc
 	r.mu.idAlloc++
 	r.mu.schemas[id] = schema
+	r.mu.schemaTypes[id] = schemaType
 	r.mu.subjects[subject] = id
 	return id
 }
@@ -157,7 +169,8 @@ func (r *SchemaRegistry) requestHandler(hw http.ResponseWriter, hr *http.Request
 // register is an http handler for the underlying server which registers schemas.
 func (r *SchemaRegistry) register(hw http.ResponseWriter, hr *http.Request) (err error) {
 	type confluentSchemaVersionRequest struct {
-		Schema string `json:"schema"`
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
 	}
 	type confluentSchemaVersionResponse struct {
 		ID int32 `json:"id"`
@@ -173,7 +186,7 @@ func (r *SchemaRegistry) register(hw http.ResponseWriter, hr *http.Request) (err
 	}
 
 	subject := strings.Split(hr.URL.Path, "/")[2]
-	id := r.registerSchema(subject, req.Schema)
+	id := r.registerSchema(subject, req.SchemaType, req.Schema)
 	res, err := json.Marshal(confluentSchemaVersionResponse{ID: id})
 	if err != nil {
 		return err
@@ -192,22 +205,34 @@ func (r *SchemaRegistry) mode(hw http.ResponseWriter, _ *http.Request) error {
 	return err
 }
 
-// EncodedAvroToNative decodes bytes that were previously encoded by
-// confluent avro encoder, into GO native representation.
-func (r *SchemaRegistry) EncodedAvroToNative(b []byte) (interface{}, error) {
+// decodeWireFormat splits bytes in the confluent wire format into the
+// registered schema they were encoded with and the encoded payload.
+func (r *SchemaRegistry) decodeWireFormat(b []byte) (schemaType, schema string, _ []byte, _ error) {
 	if len(b) == 0 || b[0] != changefeedbase.ConfluentAvroWireFormatMagic {
-		return ``, errors.Errorf(`bad magic byte`)
+		return ``, ``, nil, errors.Errorf(`bad magic byte`)
 	}
 	b = b[1:]
 	if len(b) < 4 {
-		return ``, errors.Errorf(`missing registry id`)
+		return ``, ``, nil, errors.Errorf(`missing registry id`)
 	}
 	id := int32(binary.BigEndian.Uint32(b[:4]))
-	b = b[4:]
 
 	r.mu.Lock()
-	jsonSchema := r.mu.schemas[id]
-	r.mu.Unlock()
+	defer r.mu.Unlock()
+	schema, ok := r.mu.schemas[id]
+	if !ok {
+		return ``, ``, nil, errors.Errorf(`unknown registry id %d`, id)
+	}
+	return r.mu.schemaTypes[id], schema, b[4:], nil
+}
+
+// EncodedAvroToNative decodes bytes that were previously encoded by
+// confluent avro encoder, into GO native representation.
+func (r *SchemaRegistry) EncodedAvroToNative(b []byte) (interface{}, error) {
+	_, jsonSchema, b, err := r.decodeWireFormat(b)
+	if err != nil {
+		return ``, err
+	}
 	codec, err := goavro.NewCodec(jsonSchema)
 	if err != nil {
 		return ``, err
@@ -230,3 +255,17 @@ func (r *SchemaRegistry) AvroToJSON(avroBytes []byte) ([]byte, error) {
 	// which sorts its object keys and so is deterministic.
 	return json.Marshal(native)
 }
+
+// EncodedJSONSchemaToJSON strips the confluent wire format header from bytes
+// that were previously encoded by the confluent JSON encoder, returning the
+// JSON payload along with the JSON Schema it was registered with.
+func (r *SchemaRegistry) EncodedJSONSchemaToJSON(b []byte) (payload []byte, schema string, _ error) {
+	schemaType, schema, payload, err := r.decodeWireFormat(b)
+	if err != nil {
+		return nil, ``, err
+	}
+	if schemaType != `JSON` {
+		return nil, ``, errors.Errorf(`expected a JSON schema, found %q`, schemaType)
+	}
+	return payload, schema, nil
+}
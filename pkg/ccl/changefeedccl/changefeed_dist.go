@@ -16,8 +16,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprofiler"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -36,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
 	"github.com/cockroachdb/errors"
 )
 
@@ -166,6 +169,69 @@ func fetchTableDescriptors(
 	return targetDescs, nil
 }
 
+// makeRangeFeedPredicate returns the predicate that the rangefeeds of a
+// changefeed can push down to the servers, or nil if the changefeed emits all
+// of the changes to its tables. Changefeeds that target particular column
+// families of each of their tables receive only the changes to the targeted
+// families, changefeeds with an expression receive only the changes to rows
+// that may satisfy the equalities in its WHERE clause, and changefeeds that
+// omit storage TTL expiries don't receive the deletions of expired keys.
+func makeRangeFeedPredicate(
+	ctx context.Context,
+	codec keys.SQLCodec,
+	targets changefeedbase.Targets,
+	tableDescs []catalog.TableDescriptor,
+	selectClause string,
+	omitExpired bool,
+) (*kvpb.RangeFeedPredicate, error) {
+	byID := make(map[catid.DescID]catalog.TableDescriptor, len(tableDescs))
+	for _, desc := range tableDescs {
+		byID[desc.GetID()] = desc
+	}
+	var familyIDs []uint32
+	if err := targets.EachTarget(func(t changefeedbase.Target) error {
+		desc, ok := byID[t.TableID]
+		if !ok || t.Type != jobspb.ChangefeedTargetSpecification_COLUMN_FAMILY {
+			return iterutil.StopIteration()
+		}
+		families := desc.GetFamilies()
+		for i := range families {
+			if families[i].Name == t.FamilyName {
+				familyIDs = append(familyIDs, uint32(families[i].ID))
+				return nil
+			}
+		}
+		return iterutil.StopIteration()
+	}); err != nil {
+		familyIDs = nil
+	}
+
+	var keyPrefixes []roachpb.Key
+	var columnValues []kvpb.RangeFeedColumnValue
+	// Changefeeds with an expression have a single target table.
+	if selectClause != "" && len(tableDescs) == 1 {
+		sc, err := cdceval.ParseChangefeedExpression(selectClause)
+		if err != nil {
+			return nil, err
+		}
+		keyPrefixes, columnValues, err = cdceval.RangeFeedPredicateForExpression(
+			ctx, codec, tableDescs[0], sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(familyIDs) == 0 && len(keyPrefixes) == 0 && len(columnValues) == 0 && !omitExpired {
+		return nil, nil
+	}
+	return &kvpb.RangeFeedPredicate{
+		KeyPrefixes:  keyPrefixes,
+		FamilyIDs:    familyIDs,
+		ColumnValues: columnValues,
+		OmitExpired:  omitExpired,
+	}, nil
+}
+
 // changefeedResultTypes is the types returned by changefeed stream.
 var changefeedResultTypes = []*types.T{
 	types.Bytes,  // aggregator progress update
@@ -251,8 +317,15 @@ func startDistChangefeed(
 	if progress := localState.progress.GetChangefeed(); progress != nil && progress.Checkpoint != nil {
 		checkpoint = progress.Checkpoint
 	}
+	omitExpired := changefeedbase.MakeStatementOptions(details.Opts).IsSet(
+		changefeedbase.OptOmitStorageTTLExpiries)
+	predicate, err := makeRangeFeedPredicate(ctx, execCtx.ExecCfg().Codec,
+		AllTargets(details), tableDescs, details.Select, omitExpired)
+	if err != nil {
+		return err
+	}
 	p, planCtx, err := makePlan(execCtx, jobID, details, initialHighWater,
-		trackedSpans, checkpoint, localState.drainingNodes)(ctx, dsp)
+		trackedSpans, checkpoint, localState.drainingNodes, predicate)(ctx, dsp)
 	if err != nil {
 		return err
 	}
@@ -332,6 +405,7 @@ func makePlan(
 	trackedSpans []roachpb.Span,
 	checkpoint *jobspb.ChangefeedProgress_Checkpoint,
 	drainingNodes []roachpb.NodeID,
+	rangefeedPredicate *kvpb.RangeFeedPredicate,
 ) func(context.Context, *sql.DistSQLPlanner) (*sql.PhysicalPlan, *sql.PlanningCtx, error) {
 	return func(ctx context.Context, dsp *sql.DistSQLPlanner) (*sql.PhysicalPlan, *sql.PlanningCtx, error) {
 		var blankTxn *kv.Txn
@@ -419,6 +493,8 @@ func makePlan(
 				UserProto:  execCtx.User().EncodeProto(),
 				JobID:      jobID,
 				Select:     execinfrapb.Expression{Expr: details.Select},
+
+				RangefeedPredicate: rangefeedPredicate,
 			}
 		}
 
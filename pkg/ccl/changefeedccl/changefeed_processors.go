@@ -69,6 +69,9 @@ type changeAggregator struct {
 	// sink is the Sink to write rows to. Resolved timestamps are never written
 	// by changeAggregator.
 	sink EventSink
+	// deadLetters, if non-nil, receives rows which could not be encoded or
+	// delivered to the sink.
+	deadLetters *deadLetterQueue
 	// changedRowBuf, if non-nil, contains changed rows to be emitted. Anything
 	// queued in `resolvedSpanBuf` is dependent on these having been emitted, so
 	// this one must be empty before moving on to that one.
@@ -292,6 +295,23 @@ func (ca *changeAggregator) Start(ctx context.Context) {
 		ca.changedRowBuf = &b.buf
 	}
 
+	ca.deadLetters, err = makeDeadLetterQueue(ctx, ca.flowCtx.Cfg, opts,
+		ca.spec.User(), ca.spec.JobID, ca.sliMetrics)
+	if err != nil {
+		err = changefeedbase.MarkRetryableError(err)
+		ca.MoveToDraining(err)
+		ca.cancel()
+		return
+	}
+	if err := setDeadLetterQueue(ca.sink, ca.deadLetters); err != nil {
+		// The sink type was validated when the changefeed was created, but the
+		// sink implementation may have changed since, e.g. if a cluster setting
+		// switched the webhook sink back to its deprecated implementation.
+		ca.MoveToDraining(changefeedbase.WithTerminalError(err))
+		ca.cancel()
+		return
+	}
+
 	// If the initial scan was disabled the highwater would've already been forwarded
 	needsInitialScan := ca.frontier.Frontier().IsEmpty()
 
@@ -319,7 +339,7 @@ func (ca *changeAggregator) Start(ctx context.Context) {
 	ca.sink = &errorWrapperSink{wrapped: ca.sink}
 	ca.eventConsumer, ca.sink, err = newEventConsumer(
 		ctx, ca.flowCtx.Cfg, ca.spec, feed, ca.frontier.SpanFrontier(), kvFeedHighWater,
-		ca.sink, ca.metrics, ca.sliMetrics, ca.knobs)
+		ca.sink, ca.deadLetters, ca.metrics, ca.sliMetrics, ca.knobs)
 	if err != nil {
 		ca.MoveToDraining(err)
 		ca.cancel()
@@ -461,6 +481,7 @@ func (ca *changeAggregator) makeKVFeedCfg(
 		Knobs:               ca.knobs.FeedKnobs,
 		UseMux:              changefeedbase.UseMuxRangeFeed.Get(&cfg.Settings.SV),
 		MonitoringCfg:       monitoringCfg,
+		RangefeedPredicate:  ca.spec.RangefeedPredicate,
 	}, nil
 }
 
@@ -568,6 +589,7 @@ func (ca *changeAggregator) close() {
 		// Best effort: context is often cancel by now, so we expect to see an error
 		_ = ca.sink.Close()
 	}
+	_ = ca.deadLetters.Close()
 
 	ca.closeMetrics()
 
@@ -724,7 +746,12 @@ func (ca *changeAggregator) flushBufferedEvents() error {
 	if err := ca.eventConsumer.Flush(ca.Ctx()); err != nil {
 		return err
 	}
-	return ca.sink.Flush(ca.Ctx())
+	if err := ca.sink.Flush(ca.Ctx()); err != nil {
+		return err
+	}
+	// Messages which failed delivery are handed to the dead letter queue by the
+	// time the sink flush returns, so it must be flushed afterwards.
+	return ca.deadLetters.Flush(ca.Ctx())
 }
 
 // noteResolvedSpan periodically flushes Frontier progress from the current
@@ -896,6 +923,10 @@ type changeFrontier struct {
 	// CHANGEFEED statement was run at. It's used in an assertion that we never
 	// regress the job high-water.
 	highWaterAtStart hlc.Timestamp
+	// backfillsCleared is set once the tables re-scanned by an ALTER CHANGEFEED
+	// ... BACKFILL have been removed from the job details, which happens when
+	// the initial scan completes.
+	backfillsCleared bool
 	// passthroughBuf, in some but not all flows, contains changed row data to
 	// pass through unchanged to the gateway node.
 	passthroughBuf encDatumRowBuffer
@@ -1546,6 +1577,15 @@ func (cf *changeFrontier) checkpointJobProgress(
 
 			ju.UpdateProgress(progress)
 
+			// The backfills only apply to rows emitted by the initial scan, so
+			// they are dropped from the job details once the scan completes.
+			if !frontier.IsEmpty() && !cf.backfillsCleared {
+				if details := md.Payload.GetChangefeed(); details != nil && len(details.Backfills) > 0 {
+					details.Backfills = nil
+					ju.UpdatePayload(md.Payload)
+				}
+			}
+
 			// Reset RunStats.NumRuns to 1 since the changefeed is
 			// now running. By resetting the NumRuns, we avoid
 			// future job system level retries from having large
@@ -1562,6 +1602,9 @@ func (cf *changeFrontier) checkpointJobProgress(
 
 	cf.localState.SetHighwater(frontier)
 	cf.localState.SetCheckpoint(checkpoint.Spans, checkpoint.Timestamp)
+	if !frontier.IsEmpty() {
+		cf.backfillsCleared = true
+	}
 
 	return true, nil
 }
@@ -1589,7 +1632,8 @@ func (cf *changeFrontier) manageProtectedTimestamps(
 	recordID := progress.ProtectedTimestampRecord
 	if recordID == uuid.Nil {
 		ptr := createProtectedTimestampRecord(
-			ctx, cf.flowCtx.Codec(), cf.spec.JobID, AllTargets(cf.spec.Feed), highWater,
+			ctx, cf.flowCtx.Codec(), cf.spec.JobID, AllTargets(cf.spec.Feed),
+			cf.spec.Feed.LookupTableIDs, highWater,
 		)
 		progress.ProtectedTimestampRecord = ptr.ID.GetUUID()
 		if err := pts.Protect(ctx, ptr); err != nil {
@@ -252,6 +252,7 @@ func changefeedPlanHook(
 				codec,
 				jobID,
 				AllTargets(details),
+				details.LookupTableIDs,
 				details.StatementTime,
 			)
 			progress.GetChangefeed().ProtectedTimestampRecord = ptr.ID.GetUUID()
@@ -542,6 +543,7 @@ func createChangefeedJobRecord(
 		// that support it.
 		opts.SetDefaultEnvelope(changefeedbase.OptEnvelopeBare)
 		details.Select = cdceval.AsStringUnredacted(normalized)
+		details.LookupTableIDs = normalized.LookupTableIDs()
 	}
 
 	// TODO(dan): In an attempt to present the most helpful error message to the
@@ -692,6 +694,15 @@ func createChangefeedJobRecord(
 	}
 	details.Opts = opts.AsMap()
 
+	if opts.IsSet(changefeedbase.OptConfluentJSONSchema) &&
+		!p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.V23_2_ChangefeedConfluentJSONSchema) {
+		return nil, pgerror.Newf(
+			pgcode.FeatureNotSupported,
+			"cannot create new changefeed with %s until upgrade to version %s is complete",
+			changefeedbase.OptConfluentJSONSchema, clusterversion.V23_2_ChangefeedConfluentJSONSchema.String(),
+		)
+	}
+
 	if locFilter := details.Opts[changefeedbase.OptExecutionLocality]; locFilter != "" {
 		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.V23_1) {
 			return nil, pgerror.Newf(
@@ -932,6 +943,13 @@ func validateSink(
 	if err := canarySink.Close(); err != nil {
 		return err
 	}
+	if _, ok := opts.GetDeadLetterSinkURI(); ok {
+		if _, ok := canarySink.(deadLetterQueueSetter); !ok {
+			return errors.WithHintf(errDeadLetterSinkUnsupported,
+				"%s is supported by kafka sinks, and by webhook and pubsub sinks when %s and %s are enabled",
+				changefeedbase.OptDeadLetterSink, WebhookV2Enabled.Key(), PubsubV2Enabled.Key())
+		}
+	}
 	// If there's no projection we may need to force some options to ensure messages
 	// have enough information.
 	if details.Select == `` {
@@ -1026,6 +1044,9 @@ func validateDetailsAndOptions(
 	if err := opts.ValidateForCreateChangefeed(details.Select != ""); err != nil {
 		return err
 	}
+	if err := validateDeadLetterSinkURI(opts, details.SinkURI); err != nil {
+		return err
+	}
 	if opts.HasEndTime() {
 		scanType, err := opts.GetInitialScanType()
 		if err != nil {
@@ -5165,6 +5165,11 @@ func TestChangefeedErrors(t *testing.T) {
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH envelope='key_only'`,
 		`experimental-nodelocal://1/bar`,
 	)
+	sqlDB.ExpectErrWithTimeout(
+		t, `dead_letter_sink is not supported by this sink`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH dead_letter_sink=$2`,
+		`experimental-nodelocal://1/bar`, `nodelocal://1/dlq`,
+	)
 
 	// WITH key_in_value requires envelope=wrapped
 	sqlDB.ExpectErrWithTimeout(
@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/cloud"
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -78,6 +79,7 @@ const (
 const (
 	OptAvroSchemaPrefix             = `avro_schema_prefix`
 	OptConfluentSchemaRegistry      = `confluent_schema_registry`
+	OptConfluentJSONSchema          = `confluent_json_schema`
 	OptCursor                       = `cursor`
 	OptCustomKeyColumn              = `key_column`
 	OptEndTime                      = `end_time`
@@ -105,6 +107,8 @@ const (
 	OptExecutionLocality            = `execution_locality`
 	OptLaggingRangesThreshold       = `lagging_ranges_threshold`
 	OptLaggingRangesPollingInterval = `lagging_ranges_polling_interval`
+	OptDeadLetterSink               = `dead_letter_sink`
+	OptOmitStorageTTLExpiries       = `omit_storage_ttl_expiries`
 
 	OptVirtualColumnsOmitted VirtualColumnVisibility = `omitted`
 	OptVirtualColumnsNull    VirtualColumnVisibility = `null`
@@ -187,6 +191,7 @@ const (
 	SinkParamCACert                 = `ca_cert`
 	SinkParamClientCert             = `client_cert`
 	SinkParamClientKey              = `client_key`
+	SinkParamCommitInterval         = `commit_interval`
 	SinkParamFileSize               = `file_size`
 	SinkParamPartitionFormat        = `partition_format`
 	SinkParamSchemaTopic            = `schema_topic`
@@ -206,6 +211,7 @@ const (
 	SinkSchemeWebhookHTTP           = `webhook-http`
 	SinkSchemeWebhookHTTPS          = `webhook-https`
 	SinkSchemeExternalConnection    = `external`
+	SinkSchemeIcebergPrefix         = `iceberg-`
 	SinkParamSASLEnabled            = `sasl_enabled`
 	SinkParamSASLHandshake          = `sasl_handshake`
 	SinkParamSASLUser               = `sasl_user`
@@ -325,6 +331,7 @@ var jsonOption = OptionPermittedValues{Type: OptionTypeJSON}
 var ChangefeedOptionExpectValues = map[string]OptionPermittedValues{
 	OptAvroSchemaPrefix:                   stringOption,
 	OptConfluentSchemaRegistry:            stringOption,
+	OptConfluentJSONSchema:                flagOption,
 	OptCursor:                             timestampOption,
 	OptCustomKeyColumn:                    stringOption,
 	OptEndTime:                            timestampOption,
@@ -359,6 +366,8 @@ var ChangefeedOptionExpectValues = map[string]OptionPermittedValues{
 	OptExecutionLocality:                  stringOption,
 	OptLaggingRangesThreshold:             durationOption,
 	OptLaggingRangesPollingInterval:       durationOption,
+	OptDeadLetterSink:                     stringOption,
+	OptOmitStorageTTLExpiries:             flagOption,
 }
 
 // CommonOptions is options common to all sinks
@@ -372,17 +381,21 @@ var CommonOptions = makeStringSet(OptCursor, OptEndTime, OptEnvelope,
 	OptInitialScan, OptNoInitialScan, OptInitialScanOnly, OptUnordered, OptCustomKeyColumn,
 	OptMinCheckpointFrequency, OptMetricsScope, OptVirtualColumns, Topics, OptExpirePTSAfter,
 	OptExecutionLocality, OptLaggingRangesThreshold, OptLaggingRangesPollingInterval,
+	OptDeadLetterSink, OptOmitStorageTTLExpiries,
 )
 
 // SQLValidOptions is options exclusive to SQL sink
 var SQLValidOptions map[string]struct{} = nil
 
 // KafkaValidOptions is options exclusive to Kafka sink
-var KafkaValidOptions = makeStringSet(OptAvroSchemaPrefix, OptConfluentSchemaRegistry, OptKafkaSinkConfig)
+var KafkaValidOptions = makeStringSet(OptAvroSchemaPrefix, OptConfluentSchemaRegistry, OptConfluentJSONSchema, OptKafkaSinkConfig)
 
 // CloudStorageValidOptions is options exclusive to cloud storage sink
 var CloudStorageValidOptions = makeStringSet(OptCompression)
 
+// IcebergValidOptions is options exclusive to the iceberg sink
+var IcebergValidOptions = makeStringSet(OptCompression)
+
 // WebhookValidOptions is options exclusive to webhook sink
 var WebhookValidOptions = makeStringSet(OptWebhookAuthHeader, OptWebhookClientTimeout, OptWebhookSinkConfig)
 
@@ -395,7 +408,7 @@ var PubsubValidOptions = makeStringSet(OptPubsubSinkConfig)
 // TODO(adityamaru): Some of these options should be supported when creating the
 // external connection rather than when setting up the changefeed. Move them once
 // we support `CREATE EXTERNAL CONNECTION ... WITH <options>`.
-var ExternalConnectionValidOptions = unionStringSets(SQLValidOptions, KafkaValidOptions, CloudStorageValidOptions, WebhookValidOptions, PubsubValidOptions)
+var ExternalConnectionValidOptions = unionStringSets(SQLValidOptions, KafkaValidOptions, CloudStorageValidOptions, IcebergValidOptions, WebhookValidOptions, PubsubValidOptions)
 
 // CaseInsensitiveOpts options which supports case Insensitive value
 var CaseInsensitiveOpts = makeStringSet(OptFormat, OptEnvelope, OptCompression, OptSchemaChangeEvents,
@@ -424,11 +437,22 @@ func RedactUserFromURI(uri string) (string, error) {
 	return u.String(), nil
 }
 
+// redactStorageURI redacts credentials from an external storage URI, as well
+// as the user.
+func redactStorageURI(uri string) (string, error) {
+	sanitized, err := cloud.SanitizeExternalStorageURI(uri, nil /* extraParams */)
+	if err != nil {
+		return "", err
+	}
+	return RedactUserFromURI(sanitized)
+}
+
 // RedactedOptions are options whose values should be replaced with "redacted" in job descriptions and errors.
 var RedactedOptions = map[string]redactionFunc{
 	OptWebhookAuthHeader:       redactSimple,
 	SinkParamClientKey:         redactSimple,
 	OptConfluentSchemaRegistry: RedactUserFromURI,
+	OptDeadLetterSink:          redactStorageURI,
 }
 
 // NoLongerExperimental aliases options prefixed with experimental that no longer need to be
@@ -452,7 +476,7 @@ var InitialScanOnlyUnsupportedOptions OptionsSet = makeStringSet(OptEndTime, Opt
 
 // ParquetFormatUnsupportedOptions is options that are not supported with the
 // parquet format.
-var ParquetFormatUnsupportedOptions OptionsSet = makeStringSet(OptTopicInValue)
+var ParquetFormatUnsupportedOptions OptionsSet = makeStringSet(OptTopicInValue, OptDeadLetterSink)
 
 // AlterChangefeedUnsupportedOptions are changefeed options that we do not allow
 // users to alter.
@@ -768,6 +792,10 @@ type EncodingOptions struct {
 	SchemaRegistryURI string
 	Compression       string
 	CustomKeyColumn   string
+	// ConfluentJSONSchema, when set, makes JSON encoders register a JSON
+	// Schema with the schema registry and prefix each message with the
+	// Confluent wire format header.
+	ConfluentJSONSchema bool
 }
 
 // GetEncodingOptions populates and validates an EncodingOptions.
@@ -809,6 +837,7 @@ func (s StatementOptions) GetEncodingOptions() (EncodingOptions, error) {
 	_, o.UpdatedTimestamps = s.m[OptUpdatedTimestamps]
 	_, o.MVCCTimestamps = s.m[OptMVCCTimestamps]
 	_, o.Diff = s.m[OptDiff]
+	_, o.ConfluentJSONSchema = s.m[OptConfluentJSONSchema]
 
 	o.SchemaRegistryURI = s.m[OptConfluentSchemaRegistry]
 	o.AvroSchemaPrefix = s.m[OptAvroSchemaPrefix]
@@ -826,6 +855,16 @@ func (e EncodingOptions) Validate() error {
 			OptEnvelope, OptEnvelopeRow, OptFormat, OptFormatAvro,
 		)
 	}
+	if e.ConfluentJSONSchema {
+		if e.Format != OptFormatJSON {
+			return errors.Errorf(`%s is only usable with %s=%s`,
+				OptConfluentJSONSchema, OptFormat, OptFormatJSON)
+		}
+		if e.SchemaRegistryURI == `` {
+			return errors.Errorf(`%s requires %s`,
+				OptConfluentJSONSchema, OptConfluentSchemaRegistry)
+		}
+	}
 	if e.Envelope != OptEnvelopeWrapped && e.Format != OptFormatJSON && e.Format != OptFormatParquet {
 		requiresWrap := []struct {
 			k string
@@ -1065,6 +1104,14 @@ func (s StatementOptions) SetDefaultEnvelope(t EnvelopeType) {
 	}
 }
 
+// GetDeadLetterSinkURI returns the URI of the external storage that events
+// which cannot be encoded or delivered should be written to, or false if no
+// dead letter sink was configured.
+func (s StatementOptions) GetDeadLetterSinkURI() (string, bool) {
+	v, ok := s.m[OptDeadLetterSink]
+	return v, ok && v != ``
+}
+
 // GetOnError validates and returns the desired behavior when a non-retriable error is encountered.
 func (s StatementOptions) GetOnError() (OnErrorType, error) {
 	v, err := s.getEnumValue(OptOnError)
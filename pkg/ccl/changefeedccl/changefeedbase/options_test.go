@@ -40,6 +40,7 @@ func TestOptionsValidations(t *testing.T) {
 		{map[string]string{"format": "txt"}, true, "unknown format"},
 		{map[string]string{"initial_scan": "", "no_initial_scan": ""}, true, "cannot specify both"},
 		{map[string]string{"format": "parquet", "topic_in_value": ""}, false, "cannot specify both"},
+		{map[string]string{"format": "parquet", "dead_letter_sink": "nodelocal://1/dlq"}, false, "cannot specify both"},
 		// Verify that the returned error uses the syntax initial_scan='yes' instead of initial_scan_only. See #97008.
 		{map[string]string{"initial_scan_only": "", "resolved": ""}, true, "cannot specify both initial_scan='only'"},
 		{map[string]string{"initial_scan_only": "", "resolved": ""}, true, "cannot specify both initial_scan='only'"},
@@ -58,6 +59,34 @@ func TestOptionsValidations(t *testing.T) {
 	}
 }
 
+func TestConfluentJSONSchemaValidation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tests := []struct {
+		input     map[string]string
+		expectErr string
+	}{
+		{map[string]string{"confluent_schema_registry": "http://registry"}, ""},
+		{map[string]string{"confluent_json_schema": "", "confluent_schema_registry": "http://registry"}, ""},
+		{map[string]string{"confluent_json_schema": ""}, "confluent_json_schema requires confluent_schema_registry"},
+		{map[string]string{"confluent_json_schema": "", "confluent_schema_registry": "http://registry", "format": "avro"},
+			"confluent_json_schema is only usable with format=json"},
+	}
+
+	for _, test := range tests {
+		opts, err := MakeStatementOptions(test.input).GetEncodingOptions()
+		if test.expectErr == "" {
+			require.NoError(t, err)
+			_, set := test.input["confluent_json_schema"]
+			require.Equal(t, set, opts.ConfluentJSONSchema)
+		} else {
+			require.Error(t, err, fmt.Sprintf("%v should not be valid", test.input))
+			require.Contains(t, err.Error(), test.expectErr)
+		}
+	}
+}
+
 func TestLaggingRangesVersionGate(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -0,0 +1,288 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// deadLetterFileSize is the size at which buffered dead letters are written
+// out to external storage without waiting for the next flush.
+const deadLetterFileSize = 16 << 20 // 16MB
+
+// deadLetterReason describes why a message was sent to the dead letter sink.
+type deadLetterReason string
+
+const (
+	// deadLetterEncodeFailed indicates that the row could not be encoded in the
+	// format requested by the changefeed.
+	deadLetterEncodeFailed deadLetterReason = `encode`
+	// deadLetterDeliveryFailed indicates that the encoded message could not be
+	// delivered to the sink after exhausting all retries.
+	deadLetterDeliveryFailed deadLetterReason = `delivery`
+)
+
+// deadLetter is a message which could not be encoded or delivered, along with
+// the error explaining why. Dead letters are written to the dead letter sink as
+// newline delimited JSON.
+type deadLetter struct {
+	Reason deadLetterReason `json:"reason"`
+	Error  string           `json:"error"`
+	Topic  string           `json:"topic,omitempty"`
+	// Key and Value hold the encoded message for delivery failures.
+	Key   []byte `json:"key,omitempty"`
+	Value []byte `json:"value,omitempty"`
+	// Row holds the string representation of each column for encoding
+	// failures, since no encoded representation of the row exists.
+	Row     map[string]string `json:"row,omitempty"`
+	Updated string            `json:"updated,omitempty"`
+	MVCC    string            `json:"mvcc_timestamp,omitempty"`
+}
+
+// deadLetterQueue buffers dead letters and writes them to external storage.
+//
+// Dead letters are only durable once Flush returns, so the queue must be
+// flushed after the sink and before the changefeed checkpoints past the
+// messages it holds. All methods are safe for concurrent use, and are no-ops
+// on a nil queue.
+type deadLetterQueue struct {
+	es      cloud.ExternalStorage
+	prefix  string
+	metrics *sliMetrics
+
+	mu struct {
+		syncutil.Mutex
+		buf         bytes.Buffer
+		numMessages int
+		fileID      int64
+	}
+}
+
+// isDeadLetterSinkScheme returns true if the dead letter sink can be written
+// to the external storage identified by u.
+func isDeadLetterSinkScheme(u *url.URL) bool {
+	return isCloudStorageSink(u) || u.Scheme == changefeedbase.SinkSchemeExternalConnection
+}
+
+// parseDeadLetterSinkURI returns the URI of the dead letter sink, or nil if the
+// dead_letter_sink option was not specified.
+func parseDeadLetterSinkURI(opts changefeedbase.StatementOptions) (*url.URL, error) {
+	dlqURI, ok := opts.GetDeadLetterSinkURI()
+	if !ok {
+		return nil, nil
+	}
+	u, err := url.Parse(dlqURI)
+	if err != nil {
+		return nil, errors.Wrapf(err, `parsing %s`, changefeedbase.OptDeadLetterSink)
+	}
+	if scheme, ok := changefeedbase.NoLongerExperimental[u.Scheme]; ok {
+		u.Scheme = scheme
+	}
+	return u, nil
+}
+
+// validateDeadLetterSinkURI checks that the dead_letter_sink option, if set,
+// refers to external storage.
+func validateDeadLetterSinkURI(opts changefeedbase.StatementOptions, sinkURI string) error {
+	u, err := parseDeadLetterSinkURI(opts)
+	if err != nil || u == nil {
+		return err
+	}
+	if sinkURI == `` {
+		return errors.Errorf(`%s is not supported for sinkless changefeeds`,
+			changefeedbase.OptDeadLetterSink)
+	}
+	if !isDeadLetterSinkScheme(u) {
+		return errors.Errorf(`%s must be a cloud storage URI or an external connection, found scheme %q`,
+			changefeedbase.OptDeadLetterSink, u.Scheme)
+	}
+	return nil
+}
+
+// makeDeadLetterQueue returns the dead letter queue configured for the
+// changefeed, or nil if the dead_letter_sink option was not specified.
+func makeDeadLetterQueue(
+	ctx context.Context,
+	serverCfg *execinfra.ServerConfig,
+	opts changefeedbase.StatementOptions,
+	user username.SQLUsername,
+	jobID jobspb.JobID,
+	metrics *sliMetrics,
+) (*deadLetterQueue, error) {
+	u, err := parseDeadLetterSinkURI(opts)
+	if err != nil || u == nil {
+		return nil, err
+	}
+	es, err := serverCfg.ExternalStorageFromURI(ctx, u.String(), user)
+	if err != nil {
+		return nil, errors.Wrapf(err, `opening %s`, changefeedbase.OptDeadLetterSink)
+	}
+
+	var instanceID base.SQLInstanceID
+	if serverCfg.NodeID != nil {
+		instanceID = serverCfg.NodeID.SQLInstanceID()
+	}
+	return &deadLetterQueue{
+		es:      es,
+		prefix:  fmt.Sprintf(`%d-%d`, jobID, instanceID),
+		metrics: metrics,
+	}, nil
+}
+
+// makeEncodeDeadLetter returns a dead letter for a row which the encoder failed
+// to encode.
+func makeEncodeDeadLetter(
+	topic TopicDescriptor, row cdcevent.Row, updated hlc.Timestamp, cause error,
+) deadLetter {
+	d := deadLetter{
+		Reason:  deadLetterEncodeFailed,
+		Error:   cause.Error(),
+		Topic:   deadLetterTopic(topic),
+		Row:     make(map[string]string),
+		Updated: updated.AsOfSystemTime(),
+		MVCC:    row.MvccTimestamp.AsOfSystemTime(),
+	}
+	// The datums themselves are what failed to encode, so fall back to their
+	// SQL string representation. Errors here are ignored: the dead letter is
+	// still useful with whichever columns could be rendered.
+	_ = row.ForEachColumn().Datum(func(datum tree.Datum, col cdcevent.ResultColumn) error {
+		d.Row[col.Name] = tree.AsStringWithFlags(datum, tree.FmtExport)
+		return nil
+	})
+	return d
+}
+
+// makeDeliveryDeadLetter returns a dead letter for an encoded message which
+// could not be delivered to the sink.
+func makeDeliveryDeadLetter(e *rowEvent, cause error) deadLetter {
+	return deadLetter{
+		Reason: deadLetterDeliveryFailed,
+		Error:  cause.Error(),
+		Topic:  deadLetterTopic(e.topicDescriptor),
+		Key:    e.key,
+		Value:  e.val,
+		MVCC:   e.mvcc.AsOfSystemTime(),
+	}
+}
+
+func deadLetterTopic(topic TopicDescriptor) string {
+	if topic == nil {
+		return ``
+	}
+	name, components := topic.GetNameComponents()
+	return strings.Join(append([]string{string(name)}, components...), `.`)
+}
+
+// Enqueue buffers the dead letters, writing them to external storage if the
+// buffer has grown large enough.
+func (q *deadLetterQueue) Enqueue(ctx context.Context, letters ...deadLetter) error {
+	if q == nil {
+		return errors.AssertionFailedf("no dead letter sink configured")
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	before := q.mu.buf.Len()
+	for i := range letters {
+		encoded, err := json.Marshal(&letters[i])
+		if err != nil {
+			return err
+		}
+		q.mu.buf.Write(encoded)
+		q.mu.buf.WriteByte('\n')
+	}
+	q.mu.numMessages += len(letters)
+	q.metrics.recordDeadLetters(len(letters), q.mu.buf.Len()-before)
+
+	if q.mu.buf.Len() >= deadLetterFileSize {
+		return q.flushLocked(ctx)
+	}
+	return nil
+}
+
+// Flush writes all buffered dead letters to external storage.
+func (q *deadLetterQueue) Flush(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.flushLocked(ctx)
+}
+
+func (q *deadLetterQueue) flushLocked(ctx context.Context) error {
+	if q.mu.numMessages == 0 {
+		return nil
+	}
+	// Files are named by the time they were written so that listing the dead
+	// letter sink returns them in roughly the order the failures occurred.
+	now := hlc.Timestamp{WallTime: timeutil.Now().UnixNano()}
+	name := fmt.Sprintf(`%s-%s-%d.ndjson`, cloudStorageFormatTime(now), q.prefix, q.mu.fileID)
+	if err := cloud.WriteFile(ctx, q.es, name, bytes.NewReader(q.mu.buf.Bytes())); err != nil {
+		return errors.Wrapf(err, `writing to %s`, changefeedbase.OptDeadLetterSink)
+	}
+	q.mu.fileID++
+	q.mu.numMessages = 0
+	q.mu.buf.Reset()
+	return nil
+}
+
+// Close releases the external storage. Buffered dead letters which were not
+// flushed are discarded; they will be re-emitted when the changefeed resumes
+// from its last checkpoint.
+func (q *deadLetterQueue) Close() error {
+	if q == nil {
+		return nil
+	}
+	return q.es.Close()
+}
+
+// deadLetterQueueSetter is implemented by sinks which can send messages that
+// failed delivery to the dead letter queue.
+type deadLetterQueueSetter interface {
+	setDeadLetterQueue(q *deadLetterQueue)
+}
+
+// errDeadLetterSinkUnsupported is returned when the dead_letter_sink option is
+// used with a sink which cannot route its delivery failures to the dead letter
+// queue. Rather than silently failing the changefeed on such failures, the
+// option is rejected.
+var errDeadLetterSinkUnsupported = errors.Newf(
+	`%s is not supported by this sink`, changefeedbase.OptDeadLetterSink)
+
+// setDeadLetterQueue configures the sink to send messages that fail delivery to
+// q. Returns errDeadLetterSinkUnsupported if the sink does not support it.
+func setDeadLetterQueue(s EventSink, q *deadLetterQueue) error {
+	if q == nil {
+		return nil
+	}
+	setter, ok := s.(deadLetterQueueSetter)
+	if !ok {
+		return errDeadLetterSinkUnsupported
+	}
+	setter.setDeadLetterQueue(q)
+	return nil
+}
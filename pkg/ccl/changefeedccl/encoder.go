@@ -50,7 +50,11 @@ func getEncoder(
 ) (Encoder, error) {
 	switch opts.Format {
 	case changefeedbase.OptFormatJSON:
-		return makeJSONEncoder(jsonEncoderOptions{EncodingOptions: opts, encodeForQuery: encodeForQuery})
+		jsonOpts := jsonEncoderOptions{EncodingOptions: opts, encodeForQuery: encodeForQuery}
+		if opts.ConfluentJSONSchema {
+			return newConfluentJSONEncoder(jsonOpts, targets, p, sliMetrics)
+		}
+		return makeJSONEncoder(jsonOpts)
 	case changefeedbase.OptFormatAvro, changefeedbase.DeprecatedOptFormatAvro:
 		return newConfluentAvroEncoder(opts, targets, p, sliMetrics)
 	case changefeedbase.OptFormatCSV:
@@ -11,6 +11,7 @@ package changefeedccl
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	gojson "encoding/json"
 	"strings"
 	"time"
@@ -410,6 +411,248 @@ func (e *jsonEncoder) EncodeResolvedTimestamp(
 	return gojson.Marshal(jsonEntries)
 }
 
+// confluentJSONEncoder encodes changefeed entries as JSON exactly as the
+// jsonEncoder does, and registers a JSON Schema describing every key and value
+// with the Confluent schema registry. Each message is prefixed with the
+// Confluent wire format header identifying the schema it conforms to. It is
+// only used when the confluent_json_schema option is set, so that json feeds
+// which merely configure a registry keep emitting plain JSON.
+//
+// As with Avro, a new schema is registered whenever a table's descriptor
+// changes, which leaves compatibility checks to the schema registry.
+type confluentJSONEncoder struct {
+	*jsonEncoder
+	schemaRegistry schemaRegistry
+	targets        changefeedbase.Targets
+
+	keyCache   *cache.UnorderedCache // [tableIDAndVersion]int32
+	valueCache *cache.UnorderedCache // [tableIDAndVersionPair]int32
+
+	// resolvedCache doesn't need to be bounded like the other caches because the
+	// number of topics is fixed per changefeed.
+	resolvedCache map[string]int32
+
+	wireBuf []byte
+}
+
+var _ Encoder = &confluentJSONEncoder{}
+
+func newConfluentJSONEncoder(
+	opts jsonEncoderOptions,
+	targets changefeedbase.Targets,
+	p externalConnectionProvider,
+	sliMetrics *sliMetrics,
+) (*confluentJSONEncoder, error) {
+	je, err := makeJSONEncoder(opts)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := newConfluentSchemaRegistry(opts.SchemaRegistryURI, p, sliMetrics)
+	if err != nil {
+		return nil, err
+	}
+	return &confluentJSONEncoder{
+		jsonEncoder:    je,
+		schemaRegistry: reg,
+		targets:        targets,
+		keyCache:       cache.NewUnorderedCache(encoderCacheConfig),
+		valueCache:     cache.NewUnorderedCache(encoderCacheConfig),
+		resolvedCache:  make(map[string]int32),
+	}, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *confluentJSONEncoder) EncodeKey(ctx context.Context, row cdcevent.Row) ([]byte, error) {
+	// No familyID in the cache key for keys because it's the same schema for all families
+	cacheKey := tableIDAndVersion{tableID: row.TableID, version: row.Version}
+
+	var registryID int32
+	if v, ok := e.keyCache.Get(cacheKey); ok {
+		registryID = v.(int32)
+	} else {
+		keys := row.ForEachKeyColumn()
+		if e.customKeyColumn != "" {
+			var err error
+			keys, err = row.DatumNamed(e.customKeyColumn)
+			if err != nil {
+				return nil, err
+			}
+		}
+		schema, err := keyToJSONSchema(keys)
+		if err != nil {
+			return nil, err
+		}
+		name, err := targetTableName(e.targets, "" /* prefix */, row.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		registryID, err = e.register(ctx, name, confluentSubjectSuffixKey, schema)
+		if err != nil {
+			return nil, err
+		}
+		e.keyCache.Add(cacheKey, registryID)
+	}
+
+	key, err := e.jsonEncoder.EncodeKey(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	return e.withWireHeader(registryID, key), nil
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *confluentJSONEncoder) EncodeValue(
+	ctx context.Context, evCtx eventContext, updatedRow cdcevent.Row, prevRow cdcevent.Row,
+) ([]byte, error) {
+	value, err := e.jsonEncoder.EncodeValue(ctx, evCtx, updatedRow, prevRow)
+	if err != nil || value == nil {
+		// Tombstones are sent without a header, as they have no schema.
+		return nil, err
+	}
+
+	var cacheKey tableIDAndVersionPair
+	if e.beforeField && prevRow.IsInitialized() {
+		cacheKey[0] = tableIDAndVersion{
+			tableID: prevRow.TableID, version: prevRow.Version, familyID: prevRow.FamilyID,
+		}
+	}
+	cacheKey[1] = tableIDAndVersion{
+		tableID: updatedRow.TableID, version: updatedRow.Version, familyID: updatedRow.FamilyID,
+	}
+
+	var registryID int32
+	if v, ok := e.valueCache.Get(cacheKey); ok {
+		registryID = v.(int32)
+	} else {
+		schema, err := e.valueSchema(updatedRow, prevRow)
+		if err != nil {
+			return nil, err
+		}
+		name, err := targetTableName(e.targets, "" /* prefix */, updatedRow.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		registryID, err = e.register(ctx, name, confluentSubjectSuffixValue, schema)
+		if err != nil {
+			return nil, err
+		}
+		e.valueCache.Add(cacheKey, registryID)
+	}
+	return e.withWireHeader(registryID, value), nil
+}
+
+// valueSchema returns the schema of the values that the jsonEncoder produces
+// for rows with the given descriptors.
+func (e *confluentJSONEncoder) valueSchema(updated, prev cdcevent.Row) (jsonSchema, error) {
+	row, err := rowToJSONSchema(updated.ForEachColumn())
+	if err != nil {
+		return nil, err
+	}
+	if !canJSONEncodeMetadata(e.envelopeType) {
+		return row, nil
+	}
+
+	metaProperties := make(jsonSchema)
+	var metaKeys []string
+	addMeta := func(key string, s jsonSchema) {
+		metaProperties[key] = s
+		metaKeys = append(metaKeys, key)
+	}
+
+	if e.envelopeType == changefeedbase.OptEnvelopeWrapped {
+		// Deleted rows are emitted with a null "after".
+		addMeta(`after`, nullableJSONSchema(row))
+		if e.beforeField {
+			before := row
+			if prev.IsInitialized() {
+				if before, err = rowToJSONSchema(prev.ForEachColumn()); err != nil {
+					return nil, err
+				}
+			}
+			addMeta(`before`, nullableJSONSchema(before))
+		}
+	}
+	if e.keyInValue {
+		key, err := keyToJSONSchema(updated.ForEachKeyColumn())
+		if err != nil {
+			return nil, err
+		}
+		addMeta(`key`, key)
+	}
+	if e.topicInValue {
+		addMeta(`topic`, jsonSchema{`type`: `string`})
+	}
+	if e.updatedField {
+		addMeta(`updated`, jsonSchema{`type`: `string`})
+	}
+	if e.mvccTimestampField {
+		addMeta(`mvcc_timestamp`, jsonSchema{`type`: `string`})
+	}
+
+	if e.envelopeType == changefeedbase.OptEnvelopeWrapped {
+		return closedJSONSchemaObject(metaProperties, metaKeys), nil
+	}
+	if len(metaKeys) > 0 {
+		// Bare envelopes nest metadata under the __crdb__ key, alongside the
+		// columns.
+		row[`properties`].(jsonSchema)[metaSentinel] = closedJSONSchemaObject(metaProperties, metaKeys)
+	}
+	return row, nil
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *confluentJSONEncoder) EncodeResolvedTimestamp(
+	ctx context.Context, topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	registryID, ok := e.resolvedCache[topic]
+	if !ok {
+		schema := closedJSONSchemaObject(jsonSchema{`resolved`: jsonSchema{`type`: `string`}}, []string{`resolved`})
+		if e.envelopeType != changefeedbase.OptEnvelopeWrapped {
+			schema = closedJSONSchemaObject(jsonSchema{metaSentinel: schema}, []string{metaSentinel})
+		}
+		var err error
+		registryID, err = e.register(ctx, topic, confluentSubjectSuffixValue, schema)
+		if err != nil {
+			return nil, err
+		}
+		e.resolvedCache[topic] = registryID
+	}
+
+	value, err := e.jsonEncoder.EncodeResolvedTimestamp(ctx, topic, resolved)
+	if err != nil {
+		return nil, err
+	}
+	return e.withWireHeader(registryID, value), nil
+}
+
+func (e *confluentJSONEncoder) register(
+	ctx context.Context, name string, subjectSuffix string, schema jsonSchema,
+) (int32, error) {
+	doc, err := jsonSchemaDocument(name, schema)
+	if err != nil {
+		return 0, err
+	}
+	// NB: This uses the kafka name escaper because it has to match the name
+	// of the kafka topic.
+	subject := SQLNameToKafkaName(name) + subjectSuffix
+	return e.schemaRegistry.RegisterSchemaForSubject(ctx, subject, confluentSchemaTypeJSON, doc)
+}
+
+// withWireHeader returns the encoded message prefixed with the header
+// identifying its registered schema. The returned slice is only valid until
+// the next call to the encoder.
+//
+// https://docs.confluent.io/current/schema-registry/docs/serializer-formatter.html#wire-format
+func (e *confluentJSONEncoder) withWireHeader(registryID int32, encoded []byte) []byte {
+	e.wireBuf = append(e.wireBuf[:0],
+		changefeedbase.ConfluentAvroWireFormatMagic,
+		0, 0, 0, 0, // Placeholder for the ID.
+	)
+	binary.BigEndian.PutUint32(e.wireBuf[1:5], uint32(registryID))
+	e.wireBuf = append(e.wireBuf, encoded...)
+	return e.wireBuf
+}
+
 var placeholderCtx = eventContext{topic: "topic"}
 
 // EncodeAsJSONChangefeedWithFlags implements the crdb_internal.to_json_as_changefeed_with_flags
@@ -12,6 +12,7 @@ import (
 	"context"
 	gosql "database/sql"
 	"encoding/base64"
+	gojson "encoding/json"
 	"fmt"
 	"math/rand"
 	"net/url"
@@ -25,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/randgen"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -439,6 +441,122 @@ func TestAvroEncoderWithTLS(t *testing.T) {
 	}
 }
 
+func TestJSONEncoderWithSchemaRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	reg := cdctest.StartTestSchemaRegistry()
+	defer reg.Close()
+
+	tableDesc, err := parseTableDesc(`CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	require.NoError(t, err)
+	targets := changefeedbase.Targets{}
+	targets.Add(changefeedbase.Target{
+		Type:              jobspb.ChangefeedTargetSpecification_PRIMARY_FAMILY_ONLY,
+		TableID:           tableDesc.GetID(),
+		StatementTimeName: changefeedbase.StatementTimeName(tableDesc.GetName()),
+	})
+	opts := changefeedbase.EncodingOptions{
+		Format:            changefeedbase.OptFormatJSON,
+		Envelope:          changefeedbase.OptEnvelopeWrapped,
+		UpdatedTimestamps: true,
+		Diff:              true,
+		SchemaRegistryURI: reg.URL(),
+	}
+	// Without confluent_json_schema, a registry does not change the JSON wire
+	// format, so existing json feeds with a registry keep emitting plain JSON.
+	e, err := getEncoder(opts, targets, false, nil, nil)
+	require.NoError(t, err)
+	require.IsType(t, &jsonEncoder{}, e)
+	require.Equal(t, 0, reg.RegistrationCount())
+
+	opts.ConfluentJSONSchema = true
+	e, err = getEncoder(opts, targets, false, nil, nil)
+	require.NoError(t, err)
+
+	ts := hlc.Timestamp{WallTime: 1, Logical: 2}
+	evCtx := eventContext{updated: ts}
+	decode := func(subject string, b []byte) string {
+		payload, schema, err := reg.EncodedJSONSchemaToJSON(b)
+		require.NoError(t, err)
+		require.Equal(t, reg.SchemaForSubject(subject), schema)
+		return string(payload)
+	}
+	schemaFor := func(subject string) map[string]interface{} {
+		require.Equal(t, `JSON`, reg.SchemaTypeForSubject(subject))
+		var schema map[string]interface{}
+		require.NoError(t, gojson.Unmarshal([]byte(reg.SchemaForSubject(subject)), &schema))
+		return schema
+	}
+
+	row := rowenc.EncDatumRow{
+		rowenc.EncDatum{Datum: tree.NewDInt(1)},
+		rowenc.EncDatum{Datum: tree.NewDString(`bar`)},
+	}
+	rowInsert := cdcevent.TestingMakeEventRow(tableDesc, 0, row, false)
+	key, err := e.EncodeKey(ctx, rowInsert)
+	require.NoError(t, err)
+	require.Equal(t, `[1]`, decode(`foo-key`, key))
+	value, err := e.EncodeValue(ctx, evCtx, rowInsert, cdcevent.Row{})
+	require.NoError(t, err)
+	require.Equal(t, `{"after": {"a": 1, "b": "bar"}, "before": null, "updated": "1.0000000002"}`,
+		decode(`foo-value`, value))
+	assertRegisteredSubjects(t, reg, []string{`foo-key`, `foo-value`})
+
+	require.Equal(t, map[string]interface{}{
+		`$schema`: jsonSchemaDraft,
+		`title`:   `foo`,
+		`type`:    `array`,
+		`items`: []interface{}{
+			map[string]interface{}{`anyOf`: []interface{}{
+				map[string]interface{}{`type`: `null`},
+				map[string]interface{}{`type`: `integer`},
+			}},
+		},
+		`additionalItems`: false,
+		`minItems`:        float64(1),
+	}, schemaFor(`foo-key`))
+	valueSchema := schemaFor(`foo-value`)
+	require.Equal(t, []interface{}{`after`, `before`, `updated`}, valueSchema[`required`])
+	require.Equal(t, false, valueSchema[`additionalProperties`])
+
+	// Deletes reuse the registered schemas.
+	rowDelete := cdcevent.TestingMakeEventRow(tableDesc, 0, row, true)
+	value, err = e.EncodeValue(ctx, evCtx, rowDelete, rowInsert)
+	require.NoError(t, err)
+	require.Equal(t, `{"after": null, "before": {"a": 1, "b": "bar"}, "updated": "1.0000000002"}`,
+		decode(`foo-value`, value))
+	require.Equal(t, 2, reg.RegistrationCount())
+
+	// A new version of the table registers a new value schema, which the
+	// registry is responsible for checking for compatibility.
+	newDesc, err := parseTableDesc(`CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+	require.NoError(t, err)
+	newDesc.(*tabledesc.Mutable).Version = tableDesc.GetVersion() + 1
+	rowUpdate := cdcevent.TestingMakeEventRow(newDesc, 0, rowenc.EncDatumRow{
+		rowenc.EncDatum{Datum: tree.NewDInt(1)},
+		rowenc.EncDatum{Datum: tree.NewDString(`baz`)},
+		rowenc.EncDatum{Datum: tree.NewDInt(2)},
+	}, false)
+	key, err = e.EncodeKey(ctx, rowUpdate)
+	require.NoError(t, err)
+	require.Equal(t, `[1]`, decode(`foo-key`, key))
+	value, err = e.EncodeValue(ctx, evCtx, rowUpdate, rowInsert)
+	require.NoError(t, err)
+	require.Equal(t, `{"after": {"a": 1, "b": "baz", "c": 2}, "before": {"a": 1, "b": "bar"}, "updated": "1.0000000002"}`,
+		decode(`foo-value`, value))
+	// The key schema is unchanged, so only the value schema is registered.
+	require.Equal(t, 3, reg.RegistrationCount())
+	after := schemaFor(`foo-value`)[`properties`].(map[string]interface{})[`after`]
+	afterColumns := after.(map[string]interface{})[`anyOf`].([]interface{})[1].(map[string]interface{})[`properties`]
+	require.Contains(t, afterColumns, `c`)
+
+	resolved, err := e.EncodeResolvedTimestamp(ctx, `foo`, ts)
+	require.NoError(t, err)
+	require.Equal(t, `{"resolved":"1.0000000002"}`, decode(`foo-value`, resolved))
+}
+
 func TestAvroArray(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
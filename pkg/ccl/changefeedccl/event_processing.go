@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -68,6 +69,15 @@ type kvEventToRowConsumer struct {
 	evaluator    *cdceval.Evaluator
 	encodingOpts changefeedbase.EncodingOptions
 
+	// backfillEvaluators filter the rows emitted by the initial scan of tables
+	// backfilled with a WHERE clause, keyed by table ID.
+	backfillEvaluators map[descpb.ID]*cdceval.Evaluator
+	// backfillTS is the time as of which backfilled tables are scanned.
+	backfillTS hlc.Timestamp
+
+	// deadLetters, if set, receives rows which could not be encoded.
+	deadLetters *deadLetterQueue
+
 	topicDescriptorCache map[TopicIdentifier]TopicDescriptor
 	topicNamer           *TopicNamer
 
@@ -93,6 +103,7 @@ func newEventConsumer(
 	spanFrontier *span.Frontier,
 	cursor hlc.Timestamp,
 	sink EventSink,
+	deadLetters *deadLetterQueue,
 	metrics *Metrics,
 	sliMetrics *sliMetrics,
 	knobs TestingKnobs,
@@ -143,7 +154,7 @@ func newEventConsumer(
 
 		execCfg := cfg.ExecutorConfig.(*sql.ExecutorConfig)
 		return newKVEventToRowConsumer(ctx, execCfg, frontier, cursor, s,
-			encoder, feed, spec, knobs, topicNamer, sliMetrics, pacer)
+			encoder, feed, spec, knobs, topicNamer, sliMetrics, pacer, deadLetters)
 	}
 
 	numWorkers := changefeedbase.EventConsumerWorkers.Get(&cfg.Settings.SV)
@@ -224,6 +235,7 @@ func newKVEventToRowConsumer(
 	topicNamer *TopicNamer,
 	metrics *sliMetrics,
 	pacer *admission.Pacer,
+	deadLetters *deadLetterQueue,
 ) (_ *kvEventToRowConsumer, err error) {
 	includeVirtual := details.Opts.IncludeVirtual()
 	keyOnly := details.Opts.KeyOnly()
@@ -234,7 +246,22 @@ func newKVEventToRowConsumer(
 
 	var evaluator *cdceval.Evaluator
 	if spec.Select.Expr != "" {
-		evaluator, err = newEvaluator(ctx, cfg, spec, details.Opts.GetFilters().WithDiff)
+		evaluator, err = newEvaluator(ctx, cfg, spec, spec.Select.Expr, details.Opts.GetFilters().WithDiff)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var backfillEvaluators map[descpb.ID]*cdceval.Evaluator
+	for _, backfill := range spec.Feed.Backfills {
+		if backfill.Select == "" {
+			continue
+		}
+		if backfillEvaluators == nil {
+			backfillEvaluators = make(map[descpb.ID]*cdceval.Evaluator, len(spec.Feed.Backfills))
+		}
+		backfillEvaluators[backfill.TableID], err = newEvaluator(
+			ctx, cfg, spec, backfill.Select, false /* withDiff */)
 		if err != nil {
 			return nil, err
 		}
@@ -257,8 +284,11 @@ func newKVEventToRowConsumer(
 		topicNamer:           topicNamer,
 		evaluator:            evaluator,
 		encodingOpts:         encodingOpts,
+		backfillEvaluators:   backfillEvaluators,
+		backfillTS:           spec.Feed.StatementTime,
 		metrics:              metrics,
 		pacer:                pacer,
+		deadLetters:          deadLetters,
 	}, nil
 }
 
@@ -266,9 +296,10 @@ func newEvaluator(
 	ctx context.Context,
 	cfg *sql.ExecutorConfig,
 	spec execinfrapb.ChangeAggregatorSpec,
+	expr string,
 	withDiff bool,
 ) (*cdceval.Evaluator, error) {
-	sc, err := cdceval.ParseChangefeedExpression(spec.Select.Expr)
+	sc, err := cdceval.ParseChangefeedExpression(expr)
 	if err != nil {
 		return nil, err
 	}
@@ -368,6 +399,22 @@ func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, ev kvevent.Even
 		return err
 	}
 
+	// Rows emitted by the scan of a table backfilled with a WHERE clause must
+	// match it. Only the initial scan happens at the statement time; schema
+	// change backfills, which re-emit every row, happen after it.
+	if e, ok := c.backfillEvaluators[updatedRow.TableID]; ok && ev.BackfillTimestamp() == c.backfillTS {
+		matched, err := e.Eval(ctx, updatedRow, cdcevent.Row{})
+		if err != nil {
+			return err
+		}
+		if !matched.IsInitialized() {
+			c.metrics.FilteredMessages.Inc(1)
+			a := ev.DetachAlloc()
+			a.Release(ctx)
+			return nil
+		}
+	}
+
 	if c.evaluator != nil {
 		updatedRow, err = c.evaluator.Eval(ctx, updatedRow, prevRow)
 		if err != nil {
@@ -438,14 +485,14 @@ func (c *kvEventToRowConsumer) encodeAndEmit(
 	var keyCopy, valueCopy []byte
 	encodedKey, err := c.encoder.EncodeKey(ctx, updatedRow)
 	if err != nil {
-		return err
+		return c.maybeDeadLetter(ctx, err, topic, updatedRow, schemaTS, alloc)
 	}
 	c.scratch, keyCopy = c.scratch.Copy(encodedKey, 0 /* extraCap */)
 	// TODO(yevgeniy): Some refactoring is needed in the encoder: namely, prevRow
 	// might not be available at all when working with changefeed expressions.
 	encodedValue, err := c.encoder.EncodeValue(ctx, evCtx, updatedRow, prevRow)
 	if err != nil {
-		return err
+		return c.maybeDeadLetter(ctx, err, topic, updatedRow, schemaTS, alloc)
 	}
 	c.scratch, valueCopy = c.scratch.Copy(encodedValue, 0 /* extraCap */)
 
@@ -464,12 +511,34 @@ func (c *kvEventToRowConsumer) encodeAndEmit(
 	return nil
 }
 
+// maybeDeadLetter sends a row which failed to encode to the dead letter queue,
+// if one is configured, so that the changefeed may continue. Otherwise, the
+// encoding error is returned.
+func (c *kvEventToRowConsumer) maybeDeadLetter(
+	ctx context.Context,
+	encodeErr error,
+	topic TopicDescriptor,
+	updatedRow cdcevent.Row,
+	schemaTS hlc.Timestamp,
+	alloc kvevent.Alloc,
+) error {
+	if c.deadLetters == nil || ctx.Err() != nil {
+		return encodeErr
+	}
+	log.VEventf(ctx, 2, "sending row %s to dead letter sink: %v", updatedRow.DebugString(), encodeErr)
+	defer alloc.Release(ctx)
+	return c.deadLetters.Enqueue(ctx, makeEncodeDeadLetter(topic, updatedRow, schemaTS, encodeErr))
+}
+
 // Close closes this consumer.
 func (c *kvEventToRowConsumer) Close() error {
 	c.pacer.Close()
 	if c.evaluator != nil {
 		c.evaluator.Close()
 	}
+	for _, e := range c.backfillEvaluators {
+		e.Close()
+	}
 	return nil
 }
 
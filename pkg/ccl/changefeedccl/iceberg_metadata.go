@@ -0,0 +1,1074 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ioctx"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+	"github.com/linkedin/goavro/v2"
+)
+
+// This file contains just enough of the Apache Iceberg table format
+// (https://iceberg.apache.org/spec/) to maintain an unpartitioned, format
+// version 2 table whose data files are written by the changefeed: the table
+// metadata JSON, the Avro manifest list and manifest files, and the mapping
+// from SQL types onto Iceberg types. It is not a general purpose Iceberg
+// library.
+//
+// Tables written by the changefeed never carry Iceberg field IDs inside their
+// parquet files. Instead, the table's default name mapping is set so that
+// readers resolve parquet columns to fields by name.
+//
+// Since the changefeed is the only writer of its tables, it also performs the
+// maintenance that Iceberg otherwise leaves to external procedures: it expires
+// old snapshots and deletes the files which only they referenced, trims the
+// metadata log, and merges manifests once a snapshot references too many of
+// them. These are controlled by the standard Iceberg table properties, which
+// are recorded in the metadata of every table the changefeed creates.
+
+const (
+	icebergFormatVersion = 2
+
+	icebergMetadataDir     = `metadata`
+	icebergDataDir         = `data`
+	icebergVersionHintFile = `version-hint.text`
+
+	// icebergResolvedSummaryKey is recorded in the summary of every snapshot
+	// committed by the changefeed. It holds the resolved timestamp at which the
+	// snapshot was committed.
+	icebergResolvedSummaryKey = `crdb.resolved-timestamp`
+)
+
+// Values of the content field of Iceberg manifests and data files.
+const (
+	icebergContentData           = 0
+	icebergContentDeletes        = 1
+	icebergContentEqualityDelete = 2
+)
+
+// Values of the status field of manifest entries. An entry is added or deleted
+// if the snapshot which wrote the manifest added or removed its file, and
+// existing otherwise.
+const (
+	icebergStatusExisting = 0
+	icebergStatusAdded    = 1
+	icebergStatusDeleted  = 2
+)
+
+// Table properties which control the maintenance of the table.
+const (
+	// icebergMaxSnapshotAgeProperty is the age in milliseconds after which
+	// snapshots are expired.
+	icebergMaxSnapshotAgeProperty = `history.expire.max-snapshot-age-ms`
+	// icebergMinSnapshotsToKeepProperty is the number of snapshots which are
+	// retained regardless of their age.
+	icebergMinSnapshotsToKeepProperty = `history.expire.min-snapshots-to-keep`
+	// icebergPreviousVersionsMaxProperty is the number of previous metadata
+	// files recorded in the metadata log.
+	icebergPreviousVersionsMaxProperty = `write.metadata.previous-versions-max`
+	// icebergDeleteAfterCommitProperty controls whether metadata files which are
+	// removed from the metadata log are deleted.
+	icebergDeleteAfterCommitProperty = `write.metadata.delete-after-commit.enabled`
+	// icebergMinMergeCountProperty is the number of manifests a snapshot may
+	// reference before they are merged into one manifest per content type.
+	icebergMinMergeCountProperty = `commit.manifest.min-count-to-merge`
+)
+
+// icebergDefaultProperties are the properties of tables created by the
+// changefeed, and the values assumed for any of them missing from a table.
+var icebergDefaultProperties = map[string]string{
+	`write.format.default`:             `parquet`,
+	icebergMaxSnapshotAgeProperty:      `3600000`, // 1h
+	icebergMinSnapshotsToKeepProperty:  `1`,
+	icebergPreviousVersionsMaxProperty: `10`,
+	icebergDeleteAfterCommitProperty:   `true`,
+	icebergMinMergeCountProperty:       `100`,
+}
+
+type icebergTableMetadata struct {
+	FormatVersion      int                    `json:"format-version"`
+	TableUUID          string                 `json:"table-uuid"`
+	Location           string                 `json:"location"`
+	LastSequenceNumber int64                  `json:"last-sequence-number"`
+	LastUpdatedMs      int64                  `json:"last-updated-ms"`
+	LastColumnID       int                    `json:"last-column-id"`
+	CurrentSchemaID    int                    `json:"current-schema-id"`
+	Schemas            []icebergSchema        `json:"schemas"`
+	DefaultSpecID      int                    `json:"default-spec-id"`
+	PartitionSpecs     []icebergPartitionSpec `json:"partition-specs"`
+	LastPartitionID    int                    `json:"last-partition-id"`
+	DefaultSortOrderID int                    `json:"default-sort-order-id"`
+	SortOrders         []icebergSortOrder     `json:"sort-orders"`
+	Properties         map[string]string      `json:"properties"`
+	CurrentSnapshotID  *int64                 `json:"current-snapshot-id,omitempty"`
+	Snapshots          []icebergSnapshot      `json:"snapshots"`
+	SnapshotLog        []icebergSnapshotLog   `json:"snapshot-log"`
+	MetadataLog        []icebergMetadataLog   `json:"metadata-log"`
+	Refs               map[string]icebergRef  `json:"refs"`
+}
+
+type icebergSchema struct {
+	Type     string         `json:"type"`
+	SchemaID int            `json:"schema-id"`
+	Fields   []icebergField `json:"fields"`
+}
+
+type icebergField struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Type     string `json:"type"`
+}
+
+// icebergPartitionSpec and icebergSortOrder are only ever empty, since the
+// changefeed writes unpartitioned, unsorted tables.
+type icebergPartitionSpec struct {
+	SpecID int        `json:"spec-id"`
+	Fields []struct{} `json:"fields"`
+}
+
+type icebergSortOrder struct {
+	OrderID int        `json:"order-id"`
+	Fields  []struct{} `json:"fields"`
+}
+
+type icebergSnapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID *int64            `json:"parent-snapshot-id,omitempty"`
+	SequenceNumber   int64             `json:"sequence-number"`
+	TimestampMs      int64             `json:"timestamp-ms"`
+	ManifestList     string            `json:"manifest-list"`
+	Summary          map[string]string `json:"summary"`
+	SchemaID         int               `json:"schema-id"`
+}
+
+type icebergSnapshotLog struct {
+	SnapshotID  int64 `json:"snapshot-id"`
+	TimestampMs int64 `json:"timestamp-ms"`
+}
+
+type icebergMetadataLog struct {
+	MetadataFile string `json:"metadata-file"`
+	TimestampMs  int64  `json:"timestamp-ms"`
+}
+
+type icebergRef struct {
+	SnapshotID int64  `json:"snapshot-id"`
+	Type       string `json:"type"`
+}
+
+type icebergNameMapping struct {
+	FieldID int      `json:"field-id"`
+	Names   []string `json:"names"`
+}
+
+// icebergManifestEntrySchema is the Avro schema of the entries in a manifest
+// file for an unpartitioned table. The field IDs are fixed by the Iceberg
+// spec. The changefeed always records the sequence numbers of the entries it
+// writes, but entries written by other writers may leave them null, in which
+// case they are inherited from the manifest list.
+const icebergManifestEntrySchema = `{
+  "type": "record",
+  "name": "manifest_entry",
+  "fields": [
+    {"name": "status", "type": "int", "field-id": 0},
+    {"name": "snapshot_id", "type": ["null", "long"], "default": null, "field-id": 1},
+    {"name": "sequence_number", "type": ["null", "long"], "default": null, "field-id": 3},
+    {"name": "file_sequence_number", "type": ["null", "long"], "default": null, "field-id": 4},
+    {"name": "data_file", "field-id": 2, "type": {
+      "type": "record",
+      "name": "r2",
+      "fields": [
+        {"name": "content", "type": "int", "field-id": 134},
+        {"name": "file_path", "type": "string", "field-id": 100},
+        {"name": "file_format", "type": "string", "field-id": 101},
+        {"name": "partition", "field-id": 102, "type": {"type": "record", "name": "r102", "fields": []}},
+        {"name": "record_count", "type": "long", "field-id": 103},
+        {"name": "file_size_in_bytes", "type": "long", "field-id": 104},
+        {"name": "equality_ids", "default": null, "field-id": 135,
+         "type": ["null", {"type": "array", "items": "int", "element-id": 136}]}
+      ]
+    }}
+  ]
+}`
+
+// icebergManifestFileSchema is the Avro schema of the entries in a manifest
+// list.
+const icebergManifestFileSchema = `{
+  "type": "record",
+  "name": "manifest_file",
+  "fields": [
+    {"name": "manifest_path", "type": "string", "field-id": 500},
+    {"name": "manifest_length", "type": "long", "field-id": 501},
+    {"name": "partition_spec_id", "type": "int", "field-id": 502},
+    {"name": "content", "type": "int", "field-id": 517},
+    {"name": "sequence_number", "type": "long", "field-id": 515},
+    {"name": "min_sequence_number", "type": "long", "field-id": 516},
+    {"name": "added_snapshot_id", "type": "long", "field-id": 503},
+    {"name": "added_files_count", "type": "int", "field-id": 504},
+    {"name": "existing_files_count", "type": "int", "field-id": 505},
+    {"name": "deleted_files_count", "type": "int", "field-id": 506},
+    {"name": "added_rows_count", "type": "long", "field-id": 512},
+    {"name": "existing_rows_count", "type": "long", "field-id": 513},
+    {"name": "deleted_rows_count", "type": "long", "field-id": 514}
+  ]
+}`
+
+// icebergColumnType returns the Iceberg type of a column with the given SQL
+// type, along with the SQL type that must be used when writing the column to
+// parquet so that the physical representation matches what Iceberg readers
+// expect for that type.
+func icebergColumnType(typ *types.T) (icebergType string, parquetType *types.T, _ error) {
+	switch typ.Family() {
+	case types.BoolFamily:
+		return `boolean`, typ, nil
+	case types.IntFamily:
+		if typ.Oid() == oid.T_int8 {
+			return `long`, typ, nil
+		}
+		return `int`, typ, nil
+	case types.PGLSNFamily:
+		return `long`, typ, nil
+	case types.OidFamily:
+		return `int`, typ, nil
+	case types.FloatFamily:
+		if typ.Oid() == oid.T_float4 {
+			return `float`, typ, nil
+		}
+		return `double`, typ, nil
+	case types.UuidFamily:
+		return `uuid`, typ, nil
+	case types.BytesFamily, types.BitFamily:
+		return `binary`, typ, nil
+	case types.TimeFamily:
+		return `time`, typ, nil
+	case types.DecimalFamily:
+		// The parquet writer stores decimals as their string representation, which
+		// Iceberg readers would misinterpret as an unscaled two's complement
+		// integer, so decimals are written as strings.
+		return `string`, types.String, nil
+	case types.StringFamily, types.CollatedStringFamily, types.EnumFamily,
+		types.INetFamily, types.JsonFamily, types.DateFamily, types.TimestampFamily,
+		types.TimestampTZFamily, types.IntervalFamily, types.TimeTZFamily,
+		types.Box2DFamily:
+		// All of these are written to parquet as strings.
+		return `string`, typ, nil
+	default:
+		return ``, nil, errors.Errorf(`iceberg sink does not support columns of type %s`, typ.SQLString())
+	}
+}
+
+// icebergColumn is a column of an Iceberg table written by the changefeed.
+type icebergColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// icebergTable is an Iceberg table stored under dir in external storage.
+//
+// An icebergTable is not safe for concurrent use, and assumes that it is the
+// only writer of the table's metadata.
+type icebergTable struct {
+	es  cloud.ExternalStorage
+	dir string
+	// location is the absolute URI of the table, which prefixes every path
+	// recorded in the table's metadata.
+	location string
+
+	metadata icebergTableMetadata
+	// version is the version of the metadata file from which metadata was
+	// loaded, or 0 if the table does not exist yet.
+	version int
+	// manifests holds the manifest list entries of the current snapshot, in
+	// their goavro native representation.
+	manifests []interface{}
+	// files holds the live files of the current snapshot.
+	files []icebergDataFile
+}
+
+// icebergDataFile is a live data or equality delete file of the table.
+type icebergDataFile struct {
+	// content is either icebergContentData or icebergContentEqualityDelete.
+	content int
+	// path is the absolute path of the file.
+	path        string
+	recordCount int64
+	fileSize    int64
+	// equalityIDs holds the field IDs of the columns of an equality delete
+	// file, in their goavro native representation.
+	equalityIDs []interface{}
+	// snapshotID is the ID of the snapshot which added the file.
+	snapshotID int64
+	// seq is the data sequence number of the file, which determines the data
+	// files to which an equality delete file applies, and fileSeq is the
+	// sequence number of the snapshot which added the file. The two differ for
+	// files written by compaction.
+	seq, fileSeq int64
+}
+
+// loadIcebergTable loads the current state of the table stored under dir, or
+// initializes the state of a new table if none exists.
+func loadIcebergTable(
+	ctx context.Context, es cloud.ExternalStorage, dir, location string,
+) (*icebergTable, error) {
+	t := &icebergTable{es: es, dir: dir, location: location}
+
+	hint, err := t.readFile(ctx, path.Join(icebergMetadataDir, icebergVersionHintFile))
+	if errors.Is(err, cloud.ErrFileDoesNotExist) {
+		t.metadata = newIcebergTableMetadata(location)
+		return t, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if t.version, err = strconv.Atoi(strings.TrimSpace(string(hint))); err != nil {
+		return nil, errors.Wrapf(err, `parsing iceberg version hint for %s`, location)
+	}
+
+	raw, err := t.readFile(ctx, t.metadataFile(t.version))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &t.metadata); err != nil {
+		return nil, errors.Wrapf(err, `parsing iceberg metadata for %s`, location)
+	}
+
+	current := t.currentSnapshot()
+	if current == nil {
+		return t, nil
+	}
+	if t.manifests, err = t.readOCF(ctx, current.ManifestList); err != nil {
+		return nil, err
+	}
+	for _, manifest := range t.manifests {
+		manifest := manifest.(map[string]interface{})
+		entries, err := t.readOCF(ctx, manifest[`manifest_path`].(string))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			f, status := icebergDataFileFromEntry(manifest, entry.(map[string]interface{}))
+			if status != icebergStatusDeleted {
+				t.files = append(t.files, f)
+			}
+		}
+	}
+	return t, nil
+}
+
+// icebergDataFileFromEntry decodes an entry of the given manifest, applying
+// the inheritance rules of the Iceberg spec to its snapshot ID and sequence
+// numbers, and returns it along with its status.
+func icebergDataFileFromEntry(
+	manifest, entry map[string]interface{},
+) (icebergDataFile, int32) {
+	inherit := func(v interface{}, inherited int64) int64 {
+		if v, ok := v.(map[string]interface{}); ok {
+			return v[`long`].(int64)
+		}
+		return inherited
+	}
+	manifestSeq := manifest[`sequence_number`].(int64)
+	dataFile := entry[`data_file`].(map[string]interface{})
+	f := icebergDataFile{
+		content:     int(dataFile[`content`].(int32)),
+		path:        dataFile[`file_path`].(string),
+		recordCount: dataFile[`record_count`].(int64),
+		fileSize:    dataFile[`file_size_in_bytes`].(int64),
+		snapshotID:  inherit(entry[`snapshot_id`], manifest[`added_snapshot_id`].(int64)),
+		seq:         inherit(entry[`sequence_number`], manifestSeq),
+		fileSeq:     inherit(entry[`file_sequence_number`], manifestSeq),
+	}
+	if ids, ok := dataFile[`equality_ids`].(map[string]interface{}); ok {
+		f.equalityIDs = ids[`array`].([]interface{})
+	}
+	return f, entry[`status`].(int32)
+}
+
+func newIcebergTableMetadata(location string) icebergTableMetadata {
+	properties := make(map[string]string, len(icebergDefaultProperties))
+	for k, v := range icebergDefaultProperties {
+		properties[k] = v
+	}
+	return icebergTableMetadata{
+		FormatVersion:  icebergFormatVersion,
+		TableUUID:      uuid.MakeV4().String(),
+		Location:       location,
+		LastUpdatedMs:  timeutil.Now().UnixMilli(),
+		Schemas:        []icebergSchema{{Type: `struct`, Fields: []icebergField{}}},
+		PartitionSpecs: []icebergPartitionSpec{{Fields: []struct{}{}}},
+		// Partition field IDs start at 1000, so an unpartitioned table records
+		// the ID just below that as the last one assigned.
+		LastPartitionID: 999,
+		SortOrders:      []icebergSortOrder{{Fields: []struct{}{}}},
+		Properties:      properties,
+		Snapshots:       []icebergSnapshot{},
+		SnapshotLog:     []icebergSnapshotLog{},
+		MetadataLog:     []icebergMetadataLog{},
+		Refs:            map[string]icebergRef{},
+	}
+}
+
+// intProperty returns the value of an integer table property, or its default
+// value if the table does not set it.
+func (t *icebergTable) intProperty(name string) int64 {
+	if v, err := strconv.ParseInt(t.metadata.Properties[name], 10, 64); err == nil {
+		return v
+	}
+	v, err := strconv.ParseInt(icebergDefaultProperties[name], 10, 64)
+	if err != nil {
+		panic(errors.NewAssertionErrorWithWrappedErrf(err, `iceberg property %s`, name))
+	}
+	return v
+}
+
+func (t *icebergTable) readFile(ctx context.Context, name string) ([]byte, error) {
+	r, _, err := t.es.ReadFile(ctx, path.Join(t.dir, name), cloud.ReadOptions{NoFileSize: true})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close(ctx)
+	return ioctx.ReadAll(ctx, r)
+}
+
+func (t *icebergTable) writeFile(ctx context.Context, name string, data []byte) error {
+	return cloud.WriteFile(ctx, t.es, path.Join(t.dir, name), bytes.NewReader(data))
+}
+
+// readOCF reads the records of the Avro object container file with the given
+// absolute path.
+func (t *icebergTable) readOCF(ctx context.Context, abs string) ([]interface{}, error) {
+	rel, err := t.relativePath(abs)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := t.readFile(ctx, rel)
+	if err != nil {
+		return nil, err
+	}
+	ocf, err := goavro.NewOCFReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.Wrapf(err, `reading iceberg file %s`, abs)
+	}
+	var records []interface{}
+	for ocf.Scan() {
+		record, err := ocf.Read()
+		if err != nil {
+			return nil, errors.Wrapf(err, `reading iceberg file %s`, abs)
+		}
+		records = append(records, record)
+	}
+	return records, errors.Wrapf(ocf.Err(), `reading iceberg file %s`, abs)
+}
+
+func (t *icebergTable) metadataFile(version int) string {
+	return path.Join(icebergMetadataDir, fmt.Sprintf(`v%d.metadata.json`, version))
+}
+
+// absolutePath returns the absolute path of name, which is relative to the
+// table's directory, as recorded in the table's metadata.
+func (t *icebergTable) absolutePath(name string) string {
+	return t.location + `/` + name
+}
+
+// relativePath is the inverse of absolutePath.
+func (t *icebergTable) relativePath(abs string) (string, error) {
+	rel := strings.TrimPrefix(abs, t.location+`/`)
+	if rel == abs {
+		return ``, errors.Errorf(`iceberg file %s is not within the table location %s`, abs, t.location)
+	}
+	return rel, nil
+}
+
+func (t *icebergTable) currentSnapshot() *icebergSnapshot {
+	if t.metadata.CurrentSnapshotID == nil {
+		return nil
+	}
+	for i := range t.metadata.Snapshots {
+		if t.metadata.Snapshots[i].SnapshotID == *t.metadata.CurrentSnapshotID {
+			return &t.metadata.Snapshots[i]
+		}
+	}
+	return nil
+}
+
+func (t *icebergTable) currentSchema() icebergSchema {
+	for _, s := range t.metadata.Schemas {
+		if s.SchemaID == t.metadata.CurrentSchemaID {
+			return s
+		}
+	}
+	return t.metadata.Schemas[len(t.metadata.Schemas)-1]
+}
+
+// ensureSchema makes the table's current schema contain the given columns,
+// adding a new schema if any of them are new, and returns the field ID of
+// each column. Fields are matched to columns by name. Columns which have been
+// dropped remain in the table's schema, and are null in newer data files.
+func (t *icebergTable) ensureSchema(columns []icebergColumn) (map[string]int, error) {
+	m := &t.metadata
+	current := t.currentSchema()
+
+	fields := append([]icebergField(nil), current.Fields...)
+	byName := make(map[string]icebergField, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	ids := make(map[string]int, len(columns))
+	for _, col := range columns {
+		f, ok := byName[col.Name]
+		if !ok {
+			// Every field is optional, since deleted rows and dropped columns are
+			// written as nulls.
+			m.LastColumnID++
+			f = icebergField{ID: m.LastColumnID, Name: col.Name, Type: col.Type}
+			fields = append(fields, f)
+		} else if f.Type != col.Type {
+			return nil, errors.Errorf(
+				`iceberg sink cannot change the type of column %s from %s to %s`, col.Name, f.Type, col.Type)
+		}
+		ids[col.Name] = f.ID
+	}
+	if len(fields) == len(current.Fields) {
+		return ids, nil
+	}
+
+	if len(current.Fields) == 0 {
+		// The table was just created, so its initial empty schema is replaced
+		// rather than evolved.
+		m.Schemas = []icebergSchema{{Type: `struct`, SchemaID: current.SchemaID, Fields: fields}}
+	} else {
+		next := icebergSchema{Type: `struct`, Fields: fields}
+		for _, s := range m.Schemas {
+			if s.SchemaID >= next.SchemaID {
+				next.SchemaID = s.SchemaID + 1
+			}
+		}
+		m.Schemas = append(m.Schemas, next)
+		m.CurrentSchemaID = next.SchemaID
+	}
+
+	mapping := make([]icebergNameMapping, len(fields))
+	for i, f := range fields {
+		mapping[i] = icebergNameMapping{FieldID: f.ID, Names: []string{f.Name}}
+	}
+	encoded, err := json.Marshal(mapping)
+	if err != nil {
+		return nil, err
+	}
+	m.Properties[`schema.name-mapping.default`] = string(encoded)
+	return ids, nil
+}
+
+// icebergFile is a data or delete file to be added to the table.
+type icebergFile struct {
+	Path        string `json:"path"`
+	RecordCount int64  `json:"record_count"`
+	FileSize    int64  `json:"file_size"`
+}
+
+// icebergCommit describes the files added to the table by a single snapshot.
+type icebergCommit struct {
+	Columns    []icebergColumn `json:"columns"`
+	KeyColumns []string        `json:"key_columns"`
+	// DataFile, if set, contains the rows upserted by the commit.
+	DataFile *icebergFile `json:"data_file,omitempty"`
+	// DeleteFile, if set, is an equality delete file on KeyColumns which removes
+	// every prior version of the rows touched by the commit.
+	DeleteFile *icebergFile `json:"delete_file,omitempty"`
+}
+
+// addSnapshot adds a snapshot to the table which adds the files described by
+// c, and makes it the table's current snapshot. The snapshot is not visible
+// to readers until writeMetadata is called.
+func (t *icebergTable) addSnapshot(
+	ctx context.Context, c icebergCommit, summary map[string]string,
+) error {
+	ids, err := t.ensureSchema(c.Columns)
+	if err != nil {
+		return err
+	}
+	equalityIDs := make([]interface{}, len(c.KeyColumns))
+	for i, name := range c.KeyColumns {
+		id, ok := ids[name]
+		if !ok {
+			return errors.AssertionFailedf(`iceberg key column %s missing from schema`, name)
+		}
+		equalityIDs[i] = int32(id)
+	}
+
+	var added []icebergDataFile
+	operation := `append`
+	if c.DeleteFile != nil {
+		f := t.newDataFile(icebergContentEqualityDelete, *c.DeleteFile)
+		f.equalityIDs = equalityIDs
+		added = append(added, f)
+		operation = `overwrite`
+	}
+	if c.DataFile != nil {
+		added = append(added, t.newDataFile(icebergContentData, *c.DataFile))
+	}
+	return t.commitSnapshot(ctx, operation, added, nil /* removed */, summary)
+}
+
+// newDataFile returns the description of a file written to the table's
+// directory, which has yet to be added to the table.
+func (t *icebergTable) newDataFile(content int, f icebergFile) icebergDataFile {
+	return icebergDataFile{
+		content:     content,
+		path:        t.absolutePath(f.Path),
+		recordCount: f.RecordCount,
+		fileSize:    f.FileSize,
+	}
+}
+
+// icebergManifestEntry is an entry of a manifest file.
+type icebergManifestEntry struct {
+	status int
+	file   icebergDataFile
+}
+
+// commitSnapshot adds a snapshot to the table which adds and removes the given
+// files, and makes it the table's current snapshot. Added files are assigned
+// the snapshot's sequence number as their data sequence number, unless they
+// already have one. The snapshot is not visible to readers until
+// writeMetadata is called.
+func (t *icebergTable) commitSnapshot(
+	ctx context.Context,
+	operation string,
+	added, removed []icebergDataFile,
+	summary map[string]string,
+) error {
+	m := &t.metadata
+	snapshotID := newIcebergSnapshotID()
+	seq := m.LastSequenceNumber + 1
+	nowMs := timeutil.Now().UnixMilli()
+
+	added = append([]icebergDataFile(nil), added...)
+	for i := range added {
+		added[i].snapshotID, added[i].fileSeq = snapshotID, seq
+		if added[i].seq == 0 {
+			added[i].seq = seq
+		}
+	}
+	isRemoved := make(map[string]bool, len(removed))
+	for _, f := range removed {
+		isRemoved[f.path] = true
+	}
+	var existing []icebergDataFile
+	for _, f := range t.files {
+		if !isRemoved[f.path] {
+			existing = append(existing, f)
+		}
+	}
+
+	// A manifest which lists a removed file as live cannot be referenced by the
+	// snapshot, so removing files rewrites every manifest, as does referencing
+	// more manifests than the table allows.
+	var manifests []interface{}
+	if len(removed) > 0 || int64(len(t.manifests)+2) > t.intProperty(icebergMinMergeCountProperty) {
+		var deletes, data []icebergManifestEntry
+		addEntry := func(status int, f icebergDataFile) {
+			if f.content == icebergContentData {
+				data = append(data, icebergManifestEntry{status: status, file: f})
+			} else {
+				deletes = append(deletes, icebergManifestEntry{status: status, file: f})
+			}
+		}
+		for _, f := range existing {
+			addEntry(icebergStatusExisting, f)
+		}
+		for _, f := range added {
+			addEntry(icebergStatusAdded, f)
+		}
+		for _, f := range removed {
+			// The snapshot ID of a deleted entry is that of the snapshot which
+			// removed the file.
+			f.snapshotID = snapshotID
+			addEntry(icebergStatusDeleted, f)
+		}
+		for _, entries := range [][]icebergManifestEntry{deletes, data} {
+			if len(entries) == 0 {
+				continue
+			}
+			manifest, err := t.writeManifest(ctx, snapshotID, seq, entries)
+			if err != nil {
+				return err
+			}
+			manifests = append(manifests, manifest)
+		}
+	} else {
+		for _, f := range added {
+			manifest, err := t.writeManifest(ctx, snapshotID, seq,
+				[]icebergManifestEntry{{status: icebergStatusAdded, file: f}})
+			if err != nil {
+				return err
+			}
+			manifests = append(manifests, manifest)
+		}
+		for _, manifest := range t.manifests {
+			// Manifests which only record the removal of files are not carried
+			// over into later snapshots.
+			manifest := manifest.(map[string]interface{})
+			if manifest[`added_files_count`].(int32)+manifest[`existing_files_count`].(int32) > 0 {
+				manifests = append(manifests, manifest)
+			}
+		}
+	}
+
+	listName := path.Join(icebergMetadataDir, fmt.Sprintf(`snap-%d-%s.avro`, snapshotID, uuid.MakeV4()))
+	list, err := writeIcebergOCF(icebergManifestFileSchema, map[string][]byte{
+		`snapshot-id`:        []byte(strconv.FormatInt(snapshotID, 10)),
+		`parent-snapshot-id`: []byte(t.parentSnapshotIDString()),
+		`sequence-number`:    []byte(strconv.FormatInt(seq, 10)),
+		`format-version`:     []byte(strconv.Itoa(icebergFormatVersion)),
+	}, manifests...)
+	if err != nil {
+		return err
+	}
+	if err := t.writeFile(ctx, listName, list); err != nil {
+		return err
+	}
+
+	var addedRows, addedDeletes, deletedRows, removedDeletes int64
+	for _, f := range added {
+		if f.content == icebergContentData {
+			addedRows += f.recordCount
+		} else {
+			addedDeletes += f.recordCount
+		}
+	}
+	for _, f := range removed {
+		if f.content == icebergContentData {
+			deletedRows += f.recordCount
+		} else {
+			removedDeletes += f.recordCount
+		}
+	}
+	fullSummary := map[string]string{
+		`operation`:              operation,
+		`added-records`:          strconv.FormatInt(addedRows, 10),
+		`added-equality-deletes`: strconv.FormatInt(addedDeletes, 10),
+	}
+	if len(removed) > 0 {
+		fullSummary[`deleted-records`] = strconv.FormatInt(deletedRows, 10)
+		fullSummary[`removed-equality-deletes`] = strconv.FormatInt(removedDeletes, 10)
+	}
+	for k, v := range summary {
+		fullSummary[k] = v
+	}
+
+	m.Snapshots = append(m.Snapshots, icebergSnapshot{
+		SnapshotID:       snapshotID,
+		ParentSnapshotID: m.CurrentSnapshotID,
+		SequenceNumber:   seq,
+		TimestampMs:      nowMs,
+		ManifestList:     t.absolutePath(listName),
+		Summary:          fullSummary,
+		SchemaID:         m.CurrentSchemaID,
+	})
+	m.SnapshotLog = append(m.SnapshotLog, icebergSnapshotLog{SnapshotID: snapshotID, TimestampMs: nowMs})
+	m.Refs[`main`] = icebergRef{SnapshotID: snapshotID, Type: `branch`}
+	m.CurrentSnapshotID = &snapshotID
+	m.LastSequenceNumber = seq
+	m.LastUpdatedMs = nowMs
+	t.manifests = manifests
+	t.files = append(existing, added...)
+	return nil
+}
+
+func (t *icebergTable) parentSnapshotIDString() string {
+	if t.metadata.CurrentSnapshotID == nil {
+		return `null`
+	}
+	return strconv.FormatInt(*t.metadata.CurrentSnapshotID, 10)
+}
+
+func (t *icebergTable) manifestEntry(e icebergManifestEntry) map[string]interface{} {
+	var equalityIDs interface{}
+	if e.file.equalityIDs != nil {
+		equalityIDs = goavro.Union(`array`, e.file.equalityIDs)
+	}
+	return map[string]interface{}{
+		`status`:               e.status,
+		`snapshot_id`:          goavro.Union(`long`, e.file.snapshotID),
+		`sequence_number`:      goavro.Union(`long`, e.file.seq),
+		`file_sequence_number`: goavro.Union(`long`, e.file.fileSeq),
+		`data_file`: map[string]interface{}{
+			`content`:            e.file.content,
+			`file_path`:          e.file.path,
+			`file_format`:        `PARQUET`,
+			`partition`:          map[string]interface{}{},
+			`record_count`:       e.file.recordCount,
+			`file_size_in_bytes`: e.file.fileSize,
+			`equality_ids`:       equalityIDs,
+		},
+	}
+}
+
+// writeManifest writes a manifest containing the given entries, which must all
+// be data files or all be delete files, and returns its entry in the manifest
+// list.
+func (t *icebergTable) writeManifest(
+	ctx context.Context, snapshotID, seq int64, entries []icebergManifestEntry,
+) (map[string]interface{}, error) {
+	schema, err := json.Marshal(t.currentSchema())
+	if err != nil {
+		return nil, err
+	}
+	content, contentName := icebergContentData, `data`
+	if entries[0].file.content != icebergContentData {
+		content, contentName = icebergContentDeletes, `deletes`
+	}
+	records := make([]interface{}, len(entries))
+	var files [3]int32
+	var rows [3]int64
+	minSeq := seq
+	for i, e := range entries {
+		records[i] = t.manifestEntry(e)
+		files[e.status]++
+		rows[e.status] += e.file.recordCount
+		if e.status != icebergStatusDeleted && e.file.seq < minSeq {
+			minSeq = e.file.seq
+		}
+	}
+	encoded, err := writeIcebergOCF(icebergManifestEntrySchema, map[string][]byte{
+		`schema`:            schema,
+		`schema-id`:         []byte(strconv.Itoa(t.metadata.CurrentSchemaID)),
+		`partition-spec`:    []byte(`[]`),
+		`partition-spec-id`: []byte(`0`),
+		`format-version`:    []byte(strconv.Itoa(icebergFormatVersion)),
+		`content`:           []byte(contentName),
+	}, records...)
+	if err != nil {
+		return nil, err
+	}
+	name := path.Join(icebergMetadataDir, fmt.Sprintf(`%s-m0.avro`, uuid.MakeV4()))
+	if err := t.writeFile(ctx, name, encoded); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		`manifest_path`:        t.absolutePath(name),
+		`manifest_length`:      int64(len(encoded)),
+		`partition_spec_id`:    0,
+		`content`:              content,
+		`sequence_number`:      seq,
+		`min_sequence_number`:  minSeq,
+		`added_snapshot_id`:    snapshotID,
+		`added_files_count`:    files[icebergStatusAdded],
+		`existing_files_count`: files[icebergStatusExisting],
+		`deleted_files_count`:  files[icebergStatusDeleted],
+		`added_rows_count`:     rows[icebergStatusAdded],
+		`existing_rows_count`:  rows[icebergStatusExisting],
+		`deleted_rows_count`:   rows[icebergStatusDeleted],
+	}, nil
+}
+
+// expireSnapshots removes the snapshots which are older than the table's
+// maximum snapshot age from its metadata, retaining at least the table's
+// minimum number of snapshots, and returns them. The files which only the
+// expired snapshots reference should be deleted with deleteExpired once the
+// metadata has been written.
+func (t *icebergTable) expireSnapshots(now time.Time) []icebergSnapshot {
+	m := &t.metadata
+	cutoff := now.UnixMilli() - t.intProperty(icebergMaxSnapshotAgeProperty)
+	minKeep := int(t.intProperty(icebergMinSnapshotsToKeepProperty))
+	if minKeep < 1 {
+		// The current snapshot is never expired.
+		minKeep = 1
+	}
+	// Snapshots are recorded in the order in which they were committed.
+	n := 0
+	for n < len(m.Snapshots)-minKeep && m.Snapshots[n].TimestampMs < cutoff {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	expired := append([]icebergSnapshot(nil), m.Snapshots[:n]...)
+	m.Snapshots = append([]icebergSnapshot(nil), m.Snapshots[n:]...)
+	retained := make(map[int64]bool, len(m.Snapshots))
+	for _, s := range m.Snapshots {
+		retained[s.SnapshotID] = true
+	}
+	var snapshotLog []icebergSnapshotLog
+	for _, l := range m.SnapshotLog {
+		if retained[l.SnapshotID] {
+			snapshotLog = append(snapshotLog, l)
+		}
+	}
+	m.SnapshotLog = append([]icebergSnapshotLog{}, snapshotLog...)
+	return expired
+}
+
+// deleteExpired deletes the files which are no longer referenced by the table
+// once the given snapshots, which were returned by expireSnapshots, and
+// metadata files, which were returned by writeMetadata, are no longer part of
+// its metadata. Failures are logged rather than returned, since they only
+// leave behind files which are no longer part of the table.
+func (t *icebergTable) deleteExpired(
+	ctx context.Context, expired []icebergSnapshot, obsoleteMetadata []string,
+) {
+	live := make(map[string]bool, len(t.files))
+	for _, f := range t.files {
+		live[f.path] = true
+	}
+	var obsolete []string
+	for i, s := range expired {
+		child := t.metadata.Snapshots[0]
+		if i+1 < len(expired) {
+			child = expired[i+1]
+		}
+		files, err := t.unreferencedFiles(ctx, s, child)
+		if err != nil {
+			log.Warningf(ctx, "failed to find the files of expired iceberg snapshot %d: %v", s.SnapshotID, err)
+			continue
+		}
+		for _, f := range files {
+			// A file which is live again was removed by compaction and then added
+			// once more by a repeated commit of the same pending files.
+			if !live[f] {
+				obsolete = append(obsolete, f)
+			}
+		}
+	}
+	obsolete = append(obsolete, obsoleteMetadata...)
+	for _, abs := range obsolete {
+		rel, err := t.relativePath(abs)
+		if err == nil {
+			err = t.es.Delete(ctx, path.Join(t.dir, rel))
+		}
+		if err != nil {
+			log.Warningf(ctx, "failed to delete expired iceberg file %s: %v", abs, err)
+		}
+	}
+}
+
+// unreferencedFiles returns the files which are referenced by an expired
+// snapshot but not by its child, and so by no later snapshot: the expired
+// snapshot's manifest list, the manifests which its child no longer references
+// and the files which its child removed.
+func (t *icebergTable) unreferencedFiles(
+	ctx context.Context, expired, child icebergSnapshot,
+) ([]string, error) {
+	expiredManifests, err := t.readOCF(ctx, expired.ManifestList)
+	if err != nil {
+		return nil, err
+	}
+	childManifests, err := t.readOCF(ctx, child.ManifestList)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{expired.ManifestList}
+	referenced := make(map[string]bool, len(childManifests))
+	for _, manifest := range childManifests {
+		manifest := manifest.(map[string]interface{})
+		manifestPath := manifest[`manifest_path`].(string)
+		referenced[manifestPath] = true
+		if manifest[`added_snapshot_id`].(int64) != child.SnapshotID ||
+			manifest[`deleted_files_count`].(int32) == 0 {
+			continue
+		}
+		entries, err := t.readOCF(ctx, manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			f, status := icebergDataFileFromEntry(manifest, entry.(map[string]interface{}))
+			if status == icebergStatusDeleted {
+				files = append(files, f.path)
+			}
+		}
+	}
+	for _, manifest := range expiredManifests {
+		manifestPath := manifest.(map[string]interface{})[`manifest_path`].(string)
+		if !referenced[manifestPath] {
+			files = append(files, manifestPath)
+		}
+	}
+	return files, nil
+}
+
+// writeMetadata writes a new version of the table's metadata file and points
+// the version hint at it, making every snapshot added since the table was
+// loaded visible to readers. It returns the metadata files which were trimmed
+// from the table's metadata log and should be deleted.
+func (t *icebergTable) writeMetadata(ctx context.Context) (obsolete []string, _ error) {
+	m := &t.metadata
+	if t.version > 0 {
+		m.MetadataLog = append(m.MetadataLog, icebergMetadataLog{
+			MetadataFile: t.absolutePath(t.metadataFile(t.version)),
+			TimestampMs:  m.LastUpdatedMs,
+		})
+	}
+	if keep := int(t.intProperty(icebergPreviousVersionsMaxProperty)); keep >= 0 && len(m.MetadataLog) > keep {
+		trimmed := len(m.MetadataLog) - keep
+		if m.Properties[icebergDeleteAfterCommitProperty] != `false` {
+			for _, l := range m.MetadataLog[:trimmed] {
+				obsolete = append(obsolete, l.MetadataFile)
+			}
+		}
+		m.MetadataLog = append([]icebergMetadataLog{}, m.MetadataLog[trimmed:]...)
+	}
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	next := t.version + 1
+	if err := t.writeFile(ctx, t.metadataFile(next), encoded); err != nil {
+		return nil, err
+	}
+	// The version hint is the commit point: readers which locate the table's
+	// metadata through the hint do not see the new version until it is written.
+	if err := t.writeFile(ctx, path.Join(icebergMetadataDir, icebergVersionHintFile),
+		[]byte(strconv.Itoa(next))); err != nil {
+		return nil, err
+	}
+	t.version = next
+	return obsolete, nil
+}
+
+// writeIcebergOCF encodes the records as an Avro object container file with
+// the given schema and file metadata.
+func writeIcebergOCF(
+	schema string, metadata map[string][]byte, records ...interface{},
+) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Schema: schema, MetaData: metadata})
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Append(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newIcebergSnapshotID returns a random, positive snapshot ID.
+func newIcebergSnapshotID() int64 {
+	id := uuid.MakeV4()
+	return int64(binary.BigEndian.Uint64(id.GetBytes()[:8]) &^ (1 << 63))
+}
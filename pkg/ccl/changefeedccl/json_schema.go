@@ -0,0 +1,143 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// jsonSchemaDraft identifies the JSON Schema draft which derived schemas
+// conform to. Draft-07 is supported by all versions of the Confluent schema
+// registry which accept JSON Schemas.
+const jsonSchemaDraft = `http://json-schema.org/draft-07/schema#`
+
+// jsonSchema is a JSON Schema, or a subschema within one. Schemas are built as
+// maps because encoding/json sorts map keys, which keeps the registered schema
+// for a given table version byte-for-byte stable across encoders and nodes.
+type jsonSchema map[string]interface{}
+
+// columnToJSONSchema returns the schema of the JSON that the JSON encoder
+// produces for non-NULL datums of the given type. See tree.AsJSON.
+func columnToJSONSchema(typ *types.T) jsonSchema {
+	switch typ.Family() {
+	case types.BoolFamily:
+		return jsonSchema{`type`: `boolean`}
+	case types.IntFamily:
+		return jsonSchema{`type`: `integer`}
+	case types.FloatFamily, types.DecimalFamily:
+		return jsonSchema{`type`: `number`}
+	case types.StringFamily, types.CollatedStringFamily, types.EnumFamily,
+		types.TimestampFamily, types.TimestampTZFamily, types.DateFamily,
+		types.TimeFamily, types.TimeTZFamily, types.IntervalFamily,
+		types.UuidFamily, types.OidFamily, types.BytesFamily, types.INetFamily,
+		types.BitFamily, types.Box2DFamily, types.TSQueryFamily,
+		types.TSVectorFamily, types.PGLSNFamily, types.VoidFamily:
+		return jsonSchema{`type`: `string`}
+	case types.ArrayFamily:
+		return jsonSchema{
+			`type`:  `array`,
+			`items`: nullableJSONSchema(columnToJSONSchema(typ.ArrayContents())),
+		}
+	case types.TupleFamily:
+		labels := typ.TupleLabels()
+		properties := make(jsonSchema, len(typ.TupleContents()))
+		for i, elem := range typ.TupleContents() {
+			key := fmt.Sprintf("f%d", i+1)
+			if i < len(labels) {
+				key = labels[i]
+			}
+			properties[key] = nullableJSONSchema(columnToJSONSchema(elem))
+		}
+		return closedJSONSchemaObject(properties, nil /* required */)
+	case types.GeometryFamily, types.GeographyFamily:
+		// Spatial types are encoded as GeoJSON objects.
+		return jsonSchema{`type`: `object`}
+	default:
+		// JSON columns, along with any type we don't know how to describe more
+		// precisely, may hold any JSON value.
+		return jsonSchema{}
+	}
+}
+
+// nullableJSONSchema returns a schema which accepts null in addition to
+// whatever s accepts.
+func nullableJSONSchema(s jsonSchema) jsonSchema {
+	if len(s) == 0 {
+		// The empty schema already accepts everything, null included.
+		return s
+	}
+	return jsonSchema{`anyOf`: []jsonSchema{{`type`: `null`}, s}}
+}
+
+// closedJSONSchemaObject returns the schema of an object with the given
+// properties and no others. Describing the closed content model, rather than
+// permitting additional properties, is what allows columns to be added to a
+// table while remaining backward compatible in the schema registry.
+func closedJSONSchemaObject(properties jsonSchema, required []string) jsonSchema {
+	s := jsonSchema{
+		`type`:                 `object`,
+		`properties`:           properties,
+		`additionalProperties`: false,
+	}
+	if len(required) > 0 {
+		s[`required`] = required
+	}
+	return s
+}
+
+// rowToJSONSchema returns the schema of the JSON object that the JSON encoder
+// produces for the columns iterated by it. All columns may be NULL, and none
+// are required so that dropping a column remains compatible.
+func rowToJSONSchema(it cdcevent.Iterator) (jsonSchema, error) {
+	properties := make(jsonSchema)
+	if err := it.Col(func(col cdcevent.ResultColumn) error {
+		properties[col.Name] = nullableJSONSchema(columnToJSONSchema(col.Typ))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return closedJSONSchemaObject(properties, nil /* required */), nil
+}
+
+// keyToJSONSchema returns the schema of the JSON array that the JSON encoder
+// produces for the key columns iterated by it.
+func keyToJSONSchema(it cdcevent.Iterator) (jsonSchema, error) {
+	var items []jsonSchema
+	if err := it.Col(func(col cdcevent.ResultColumn) error {
+		items = append(items, nullableJSONSchema(columnToJSONSchema(col.Typ)))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return jsonSchema{
+		`type`:            `array`,
+		`items`:           items,
+		`additionalItems`: false,
+		`minItems`:        len(items),
+	}, nil
+}
+
+// jsonSchemaDocument returns the serialized top-level schema document for s.
+func jsonSchemaDocument(title string, s jsonSchema) (string, error) {
+	doc := make(jsonSchema, len(s)+2)
+	for k, v := range s {
+		doc[k] = v
+	}
+	doc[`$schema`] = jsonSchemaDraft
+	doc[`title`] = title
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return ``, err
+	}
+	return string(b), nil
+}
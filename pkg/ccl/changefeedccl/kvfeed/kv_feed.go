@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
@@ -91,6 +92,10 @@ type Config struct {
 
 	// UseMux enables MuxRangeFeed rpc
 	UseMux bool
+
+	// RangefeedPredicate, if set, is pushed down to the rangefeeds of the feed.
+	// The feed may still receive changes that do not satisfy it.
+	RangefeedPredicate *kvpb.RangeFeedPredicate
 }
 
 // Run will run the kvfeed. The feed runs synchronously and returns an
@@ -126,6 +131,7 @@ func Run(ctx context.Context, cfg Config) error {
 		cfg.SchemaFeed,
 		sc, pff, bf, cfg.UseMux, cfg.Targets, cfg.Knobs)
 	f.onBackfillCallback = cfg.MonitoringCfg.OnBackfillCallback
+	f.rangefeedPredicate = cfg.RangefeedPredicate
 	f.rangeObserver = startLaggingRangesObserver(g, cfg.MonitoringCfg.LaggingRangesCallback,
 		cfg.MonitoringCfg.LaggingRangesPollingInterval, cfg.MonitoringCfg.LaggingRangesThreshold)
 
@@ -249,6 +255,7 @@ type kvFeed struct {
 
 	onBackfillCallback func() func()
 	rangeObserver      func(fn kvcoord.ForEachRangeFn)
+	rangefeedPredicate *kvpb.RangeFeedPredicate
 	schemaChangeEvents changefeedbase.SchemaChangeEventClass
 	schemaChangePolicy changefeedbase.SchemaChangePolicy
 
@@ -562,6 +569,7 @@ func (f *kvFeed) runUntilTableEvent(
 		Spans:         stps,
 		Frontier:      resumeFrontier.Frontier(),
 		WithDiff:      f.withDiff,
+		Predicate:     f.rangefeedPredicate,
 		Knobs:         f.knobs,
 		UseMux:        f.useMux,
 		RangeObserver: f.rangeObserver,
@@ -72,6 +72,8 @@ type AggMetrics struct {
 	CheckpointProgress        *aggmetric.AggGauge
 	LaggingRanges             *aggmetric.AggGauge
 	CloudstorageBufferedBytes *aggmetric.AggGauge
+	DeadLetterMessages        *aggmetric.AggCounter
+	DeadLetterBytes           *aggmetric.AggCounter
 
 	// There is always at least 1 sliMetrics created for defaultSLI scope.
 	mu struct {
@@ -137,6 +139,8 @@ type sliMetrics struct {
 	CheckpointProgress        *aggmetric.Gauge
 	LaggingRanges             *aggmetric.Gauge
 	CloudstorageBufferedBytes *aggmetric.Gauge
+	DeadLetterMessages        *aggmetric.Counter
+	DeadLetterBytes           *aggmetric.Counter
 
 	mu struct {
 		syncutil.Mutex
@@ -329,6 +333,14 @@ func (m *sliMetrics) recordSinkIOInflightChange(delta int64) {
 	m.SinkIOInflight.Inc(delta)
 }
 
+func (m *sliMetrics) recordDeadLetters(numMessages int, bytes int) {
+	if m == nil {
+		return
+	}
+	m.DeadLetterMessages.Inc(int64(numMessages))
+	m.DeadLetterBytes.Inc(int64(bytes))
+}
+
 type wrappingCostController struct {
 	ctx      context.Context
 	inner    metricsRecorder
@@ -636,6 +648,18 @@ func newAggregateMetrics(histogramWindow time.Duration) *AggMetrics {
 		Measurement: "Bytes",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaChangefeedDeadLetterMessages := metric.Metadata{
+		Name:        "changefeed.dead_letter_messages",
+		Help:        "Messages which could not be encoded or delivered and were written to the dead letter sink by all feeds",
+		Measurement: "Messages",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaChangefeedDeadLetterBytes := metric.Metadata{
+		Name:        "changefeed.dead_letter_bytes",
+		Help:        "Bytes written to the dead letter sink by all feeds",
+		Measurement: "Bytes",
+		Unit:        metric.Unit_BYTES,
+	}
 
 	functionalGaugeMinFn := func(childValues []int64) int64 {
 		var min int64
@@ -713,6 +737,8 @@ func newAggregateMetrics(histogramWindow time.Duration) *AggMetrics {
 		CheckpointProgress:        b.FunctionalGauge(metaCheckpointProgress, functionalGaugeMinFn),
 		LaggingRanges:             b.Gauge(metaLaggingRangePercentage),
 		CloudstorageBufferedBytes: b.Gauge(metaCloudstorageBufferedBytes),
+		DeadLetterMessages:        b.Counter(metaChangefeedDeadLetterMessages),
+		DeadLetterBytes:           b.Counter(metaChangefeedDeadLetterBytes),
 	}
 	a.mu.sliMetrics = make(map[string]*sliMetrics)
 	_, err := a.getOrCreateScope(defaultSLIScope)
@@ -774,6 +800,8 @@ func (a *AggMetrics) getOrCreateScope(scope string) (*sliMetrics, error) {
 		SchemaRegistrations:       a.SchemaRegistrations.AddChild(scope),
 		LaggingRanges:             a.LaggingRanges.AddChild(scope),
 		CloudstorageBufferedBytes: a.CloudstorageBufferedBytes.AddChild(scope),
+		DeadLetterMessages:        a.DeadLetterMessages.AddChild(scope),
+		DeadLetterBytes:           a.DeadLetterBytes.AddChild(scope),
 	}
 	sm.mu.resolved = make(map[int64]hlc.Timestamp)
 	sm.mu.checkpoint = make(map[int64]hlc.Timestamp)
@@ -24,17 +24,20 @@ import (
 )
 
 // createProtectedTimestampRecord will create a record to protect the spans for
-// this changefeed at the resolved timestamp.
+// this changefeed at the resolved timestamp. The lookup tables joined by the
+// changefeed expression are protected along with the targets, since their
+// rows are read at the timestamps of the events.
 func createProtectedTimestampRecord(
 	ctx context.Context,
 	codec keys.SQLCodec,
 	jobID jobspb.JobID,
 	targets changefeedbase.Targets,
+	lookupTableIDs descpb.IDs,
 	resolved hlc.Timestamp,
 ) *ptpb.Record {
 	ptsID := uuid.MakeV4()
-	deprecatedSpansToProtect := makeSpansToProtect(codec, targets)
-	targetToProtect := makeTargetToProtect(targets)
+	deprecatedSpansToProtect := makeSpansToProtect(codec, targets, lookupTableIDs)
+	targetToProtect := makeTargetToProtect(targets, lookupTableIDs)
 
 	log.VEventf(ctx, 2, "creating protected timestamp %v at %v", ptsID, resolved)
 	return jobsprotectedts.MakeRecord(
@@ -42,24 +45,29 @@ func createProtectedTimestampRecord(
 		jobsprotectedts.Jobs, targetToProtect)
 }
 
-func makeTargetToProtect(targets changefeedbase.Targets) *ptpb.Target {
+func makeTargetToProtect(
+	targets changefeedbase.Targets, lookupTableIDs descpb.IDs,
+) *ptpb.Target {
 	// NB: We add 1 because we're also going to protect system.descriptors.
 	// We protect system.descriptors because a changefeed needs all of the history
 	// of table descriptors to version data.
-	tablesToProtect := make(descpb.IDs, 0, targets.NumUniqueTables()+1)
+	tablesToProtect := make(descpb.IDs, 0, targets.NumUniqueTables()+len(lookupTableIDs)+1)
 	_ = targets.EachTableID(func(id descpb.ID) error {
 		tablesToProtect = append(tablesToProtect, id)
 		return nil
 	})
+	tablesToProtect = append(tablesToProtect, lookupTableIDs...)
 	tablesToProtect = append(tablesToProtect, keys.DescriptorTableID)
 	return ptpb.MakeSchemaObjectsTarget(tablesToProtect)
 }
 
-func makeSpansToProtect(codec keys.SQLCodec, targets changefeedbase.Targets) []roachpb.Span {
+func makeSpansToProtect(
+	codec keys.SQLCodec, targets changefeedbase.Targets, lookupTableIDs descpb.IDs,
+) []roachpb.Span {
 	// NB: We add 1 because we're also going to protect system.descriptors.
 	// We protect system.descriptors because a changefeed needs all of the history
 	// of table descriptors to version data.
-	spansToProtect := make([]roachpb.Span, 0, targets.NumUniqueTables()+1)
+	spansToProtect := make([]roachpb.Span, 0, targets.NumUniqueTables()+len(lookupTableIDs)+1)
 	addTablePrefix := func(id uint32) {
 		tablePrefix := codec.TablePrefix(id)
 		spansToProtect = append(spansToProtect, roachpb.Span{
@@ -71,6 +79,9 @@ func makeSpansToProtect(codec keys.SQLCodec, targets changefeedbase.Targets) []r
 		addTablePrefix(uint32(id))
 		return nil
 	})
+	for _, id := range lookupTableIDs {
+		addTablePrefix(uint32(id))
+	}
 	addTablePrefix(keys.DescriptorTableID)
 	return spansToProtect
 }
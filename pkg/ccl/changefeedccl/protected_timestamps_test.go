@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/spanconfig/spanconfigptsreader"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/desctestutils"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsql"
 	"github.com/cockroachdb/cockroach/pkg/sql/isql"
@@ -457,7 +458,7 @@ func TestPTSRecordProtectsTargetsAndDescriptorTable(t *testing.T) {
 	})
 
 	// Lay protected timestamp record.
-	ptr := createProtectedTimestampRecord(ctx, s.Codec(), 42, targets, ts)
+	ptr := createProtectedTimestampRecord(ctx, s.Codec(), 42, targets, nil /* lookupTableIDs */, ts)
 	require.NoError(t, execCfg.InternalDB.Txn(ctx, func(ctx context.Context, txn isql.Txn) error {
 		return execCfg.ProtectedTimestampProvider.WithTxn(txn).Protect(ctx, ptr)
 	}))
@@ -472,3 +473,23 @@ func TestPTSRecordProtectsTargetsAndDescriptorTable(t *testing.T) {
 	_, err := fetchTableDescriptors(ctx, &execCfg, targets, asOf)
 	require.NoError(t, err)
 }
+
+// TestProtectedTimestampRecordIncludesLookupTables verifies that the lookup
+// tables of a changefeed are protected along with its targets.
+func TestProtectedTimestampRecordIncludesLookupTables(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	var targets changefeedbase.Targets
+	targets.Add(changefeedbase.Target{TableID: 104})
+	ptr := createProtectedTimestampRecord(context.Background(), keys.SystemSQLCodec, 42, targets,
+		descpb.IDs{105, 106}, hlc.Timestamp{WallTime: 1})
+	require.Equal(t, descpb.IDs{104, 105, 106, keys.DescriptorTableID},
+		ptr.Target.GetSchemaObjects().IDs)
+	var spans []roachpb.Span
+	for _, id := range []uint32{104, 105, 106, keys.DescriptorTableID} {
+		prefix := keys.SystemSQLCodec.TablePrefix(id)
+		spans = append(spans, roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+	}
+	require.Equal(t, spans, ptr.DeprecatedSpans)
+}
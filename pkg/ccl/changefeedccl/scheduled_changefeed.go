@@ -295,7 +295,8 @@ func makeScheduledChangefeedSpec(
 		// tree.TableExpr (from Select clause) into tree.ChangefeedTarget. If that
 		// typecasting was successful, it is guaranteed that the reverse should work
 		// without any errors.
-		tableExprs[0] = qualifiedTablePatterns[0].(tree.TableExpr)
+		tableExprs[0] = replaceChangefeedTargetExpr(
+			schedule.Select.From.Tables[0], qualifiedTablePatterns[0].(tree.TableExpr))
 		schedule.Select.From.Tables = tableExprs
 	}
 
@@ -356,6 +357,25 @@ func makeScheduledChangefeedSpec(
 	return spec, nil
 }
 
+// replaceChangefeedTargetExpr replaces changefeed target table in the FROM
+// clause of the changefeed expression with the specified (qualified) table
+// expression. The target is the left-most table in the case of lookup joins.
+// Table alias, if any, is preserved.
+func replaceChangefeedTargetExpr(from tree.TableExpr, target tree.TableExpr) tree.TableExpr {
+	switch t := from.(type) {
+	case *tree.JoinTableExpr:
+		join := *t
+		join.Left = replaceChangefeedTargetExpr(t.Left, target)
+		return &join
+	case *tree.AliasedTableExpr:
+		aliased := *t
+		aliased.Expr = target
+		return &aliased
+	default:
+		return target
+	}
+}
+
 func makeChangefeedSchedule(
 	env scheduledjobs.JobSchedulerEnv,
 	owner username.SQLUsername,
@@ -59,6 +59,7 @@ const (
 	sinkTypePubsub
 	sinkTypeCloudstorage
 	sinkTypeSQL
+	sinkTypeIceberg
 )
 
 // externalResource is the interface common to both EventSink and
@@ -280,6 +281,24 @@ func getSink(
 					timestampOracle, serverCfg.ExternalStorageFromURI, user, metricsBuilder, testingKnobs,
 				)
 			})
+		case isIcebergSink(u):
+			return validateOptionsAndMakeSink(changefeedbase.IcebergValidOptions, func() (Sink, error) {
+				// Snapshots of the iceberg table are only committed when resolved
+				// timestamps are emitted.
+				if _, emitResolved, err := opts.GetResolvedTimestampInterval(); err != nil {
+					return nil, err
+				} else if !emitResolved {
+					return nil, errors.Errorf(`this sink requires the %s option`, changefeedbase.OptResolvedTimestamps)
+				}
+				var nodeID base.SQLInstanceID
+				if serverCfg.NodeID != nil {
+					nodeID = serverCfg.NodeID.SQLInstanceID()
+				}
+				return makeIcebergSink(
+					ctx, sinkURL{URL: u}, nodeID, encodingOpts, AllTargets(feedCfg),
+					serverCfg.ExternalStorageFromURI, user, metricsBuilder,
+				)
+			})
 		case u.Scheme == changefeedbase.SinkSchemeExperimentalSQL:
 			return validateOptionsAndMakeSink(changefeedbase.SQLValidOptions, func() (Sink, error) {
 				return makeSQLSink(sinkURL{URL: u}, sqlSinkTableName, AllTargets(feedCfg), metricsBuilder)
@@ -0,0 +1,946 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/kvevent"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/parquet"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// The iceberg sink writes each topic to an Apache Iceberg table stored in
+// cloud storage, so that the table can be queried directly by engines which
+// read Iceberg. It is configured with a cloud storage URI whose scheme is
+// prefixed with `iceberg-`, for example `iceberg-s3://bucket/path`, and writes
+// the table for each topic to `<path>/<topic>`.
+//
+// Writing happens in two phases, since the rows of a table are emitted by
+// every change aggregator, but a table's metadata must be updated by a single
+// writer:
+//
+//  1. When a change aggregator flushes, it writes the rows it has buffered for
+//     each topic as a parquet data file, along with an equality delete file
+//     containing the primary key of every row it touched. It then records the
+//     two files in a pending commit file under `<topic>/crdb_pending`.
+//  2. When the change frontier emits a resolved timestamp, and at least the
+//     commit interval (the `commit_interval` sink parameter) has passed since
+//     the table was last committed, it commits each pending commit file as a
+//     new snapshot of its table, in order, and then removes the pending commit
+//     files. Committing less often than resolved timestamps are emitted bounds
+//     the rate at which snapshots and metadata files are written.
+//
+// Because the equality deletes of a snapshot apply to every row written by
+// earlier snapshots, the table always holds the latest version of each row,
+// and updates and deletes made before the resolved timestamp are visible once
+// the snapshot is committed. The snapshot committed at a resolved timestamp
+// may also contain some rows updated after that timestamp.
+//
+// Since every commit adds an equality delete file, which readers must apply
+// to all older data files, the frontier also compacts the files of
+// consecutive commits once enough of them have accumulated. See maybeCompact.
+// Files which are no longer part of the table are deleted once the snapshots
+// which reference them expire.
+//
+// Committing a pending commit file more than once, which happens if the
+// frontier fails between writing the table's metadata and removing the pending
+// commit files, is harmless: the repeated equality deletes remove the rows
+// added by the first commit, which are then added back.
+
+const icebergPendingDir = `crdb_pending`
+
+// icebergDefaultCommitInterval is the minimum interval between commits to a
+// table, unless overridden with the `commit_interval` sink parameter.
+const icebergDefaultCommitInterval = time.Minute
+
+const (
+	// icebergCompactionFanIn is the number of consecutive commits whose files
+	// are compacted together.
+	icebergCompactionFanIn = 4
+	// icebergCompactionMaxBytes bounds the total size of the files which are
+	// compacted together, since they are read into memory.
+	icebergCompactionMaxBytes = 64 << 20 // 64MB
+)
+
+// isIcebergSink returns true if u identifies an iceberg table in a supported
+// cloud storage provider.
+func isIcebergSink(u *url.URL) bool {
+	if !strings.HasPrefix(u.Scheme, changefeedbase.SinkSchemeIcebergPrefix) {
+		return false
+	}
+	storage := *u
+	storage.Scheme = strings.TrimPrefix(u.Scheme, changefeedbase.SinkSchemeIcebergPrefix)
+	return isCloudStorageSink(&storage)
+}
+
+// icebergBuffer holds the rows emitted for a topic since the last flush, with
+// at most one row per primary key.
+type icebergBuffer struct {
+	topic   string
+	version descpb.DescriptorVersion
+
+	columns      []icebergColumn
+	parquetTypes []*types.T
+	// keyIdx holds the index in columns of each primary key column.
+	keyIdx []int
+
+	// rows holds the latest version of each row, indexed by the encoded primary
+	// key in keys.
+	keys  map[string]int
+	rows  []icebergBufferedRow
+	alloc kvevent.Alloc
+}
+
+type icebergBufferedRow struct {
+	key tree.Datums
+	// datums holds every column of the row, and is nil if the row was deleted.
+	datums tree.Datums
+}
+
+type icebergSink struct {
+	es cloud.ExternalStorage
+	// location is the absolute URI under which tables are written, without any
+	// credentials.
+	location          string
+	srcID             base.SQLInstanceID
+	sessionID         string
+	topicNamer        *TopicNamer
+	compression       parquet.CompressionCodec
+	targetMaxFileSize int64
+	commitInterval    time.Duration
+	metrics           metricsRecorder
+
+	// buffers holds the rows buffered for each topic by a change aggregator.
+	buffers map[string]*icebergBuffer
+	// commitSeq orders the pending commit files written by the sink.
+	commitSeq int64
+	scratch   []byte
+
+	// tables caches the state of each table committed by the change frontier.
+	tables map[string]*icebergTable
+}
+
+var _ SinkWithEncoder = (*icebergSink)(nil)
+
+func makeIcebergSink(
+	ctx context.Context,
+	u sinkURL,
+	srcID base.SQLInstanceID,
+	encodingOpts changefeedbase.EncodingOptions,
+	targets changefeedbase.Targets,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+	user username.SQLUsername,
+	mb metricsRecorderBuilder,
+) (Sink, error) {
+	if encodingOpts.Format != changefeedbase.OptFormatParquet {
+		return nil, errors.Errorf(`this sink requires %s=%s`,
+			changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+	}
+	if encodingOpts.Diff || encodingOpts.UpdatedTimestamps || encodingOpts.MVCCTimestamps {
+		return nil, errors.Errorf(`this sink is incompatible with %s, %s and %s`,
+			changefeedbase.OptDiff, changefeedbase.OptUpdatedTimestamps, changefeedbase.OptMVCCTimestamps)
+	}
+	if err := targets.EachTarget(func(t changefeedbase.Target) error {
+		if t.Type != jobspb.ChangefeedTargetSpecification_PRIMARY_FAMILY_ONLY {
+			return errors.Errorf(`this sink does not support tables with multiple column families`)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var targetMaxFileSize int64 = 16 << 20 // 16MB
+	if fileSizeParam := u.consumeParam(changefeedbase.SinkParamFileSize); fileSizeParam != `` {
+		var err error
+		if targetMaxFileSize, err = humanizeutil.ParseBytes(fileSizeParam); err != nil {
+			return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, fileSizeParam)
+		}
+	}
+	commitInterval := icebergDefaultCommitInterval
+	if commitIntervalParam := u.consumeParam(changefeedbase.SinkParamCommitInterval); commitIntervalParam != `` {
+		var err error
+		if commitInterval, err = time.ParseDuration(commitIntervalParam); err != nil {
+			return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, commitIntervalParam)
+		}
+	}
+	u.Scheme = strings.TrimPrefix(u.Scheme, changefeedbase.SinkSchemeIcebergPrefix)
+	u.Scheme = strings.TrimPrefix(u.Scheme, `file-`)
+
+	sessID, err := generateChangefeedSessionID()
+	if err != nil {
+		return nil, err
+	}
+	tn, err := MakeTopicNamer(targets, WithJoinByte('+'))
+	if err != nil {
+		return nil, err
+	}
+
+	location := *u.URL
+	location.User = nil
+	location.RawQuery = ``
+	s := &icebergSink{
+		location:          strings.TrimSuffix(location.String(), `/`),
+		srcID:             srcID,
+		sessionID:         sessID,
+		topicNamer:        tn,
+		compression:       parquet.CompressionNone,
+		targetMaxFileSize: targetMaxFileSize,
+		commitInterval:    commitInterval,
+		buffers:           make(map[string]*icebergBuffer),
+		tables:            make(map[string]*icebergTable),
+	}
+
+	if codec := encodingOpts.Compression; codec != `` {
+		algo, _, err := compressionFromString(codec)
+		if err != nil {
+			return nil, err
+		}
+		switch algo {
+		case sinkCompressionGzip:
+			s.compression = parquet.CompressionGZIP
+		case sinkCompressionZstd:
+			s.compression = parquet.CompressionZSTD
+		default:
+			return nil, errors.AssertionFailedf("unexpected compression codec %s", algo)
+		}
+	}
+
+	// We make the external storage with a nil IOAccountingInterceptor since we
+	// record usage metrics via s.metrics.
+	if s.es, err = makeExternalStorageFromURI(ctx, u.String(), user, cloud.WithIOAccountingInterceptor(nil)); err != nil {
+		return nil, err
+	}
+	if mb != nil {
+		s.metrics = mb(s.es.RequiresExternalIOAccounting())
+	} else {
+		s.metrics = (*sliMetrics)(nil)
+	}
+	return s, nil
+}
+
+// getConcreteType implements the Sink interface.
+func (s *icebergSink) getConcreteType() sinkType {
+	return sinkTypeIceberg
+}
+
+// Dial implements the Sink interface.
+func (s *icebergSink) Dial() error {
+	return nil
+}
+
+// EmitRow does not do anything. It must not be called. It is present so that
+// icebergSink implements the Sink interface.
+func (s *icebergSink) EmitRow(
+	ctx context.Context,
+	topic TopicDescriptor,
+	key, value []byte,
+	updated, mvcc hlc.Timestamp,
+	alloc kvevent.Alloc,
+) error {
+	return errors.AssertionFailedf("EmitRow unimplemented by the iceberg sink")
+}
+
+// EncodeAndEmitRow buffers the row until the next flush, replacing any version
+// of the row buffered earlier. Implements the SinkWithEncoder interface.
+func (s *icebergSink) EncodeAndEmitRow(
+	ctx context.Context,
+	updatedRow cdcevent.Row,
+	prevRow cdcevent.Row,
+	topic TopicDescriptor,
+	updated, mvcc hlc.Timestamp,
+	encodingOpts changefeedbase.EncodingOptions,
+	alloc kvevent.Alloc,
+) error {
+	if s.buffers == nil {
+		return errors.New(`cannot EmitRow on a closed sink`)
+	}
+	name, err := s.topicNamer.Name(topic)
+	if err != nil {
+		return err
+	}
+
+	// The rows of a topic are buffered with the schema of the descriptor version
+	// which produced them, so rows buffered under an earlier version are flushed
+	// before the first row of the next one.
+	buf := s.buffers[name]
+	if buf != nil && buf.version != topic.GetVersion() {
+		if err := s.flushBuffer(ctx, buf); err != nil {
+			return err
+		}
+		buf = nil
+	}
+	if buf == nil {
+		if buf, err = newIcebergBuffer(name, topic.GetVersion(), updatedRow); err != nil {
+			return err
+		}
+		s.buffers[name] = buf
+	}
+
+	r := icebergBufferedRow{key: make(tree.Datums, 0, len(buf.keyIdx))}
+	s.scratch = s.scratch[:0]
+	if err := updatedRow.ForEachKeyColumn().Datum(func(d tree.Datum, _ cdcevent.ResultColumn) error {
+		r.key = append(r.key, d)
+		s.scratch, err = keyside.Encode(s.scratch, d, encoding.Ascending)
+		return err
+	}); err != nil {
+		return err
+	}
+	if !updatedRow.IsDeleted() {
+		r.datums = make(tree.Datums, 0, len(buf.columns))
+		if err := updatedRow.ForAllColumns().Datum(func(d tree.Datum, _ cdcevent.ResultColumn) error {
+			r.datums = append(r.datums, d)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if i, ok := buf.keys[string(s.scratch)]; ok {
+		buf.rows[i] = r
+	} else {
+		buf.keys[string(s.scratch)] = len(buf.rows)
+		buf.rows = append(buf.rows, r)
+	}
+	s.metrics.recordMessageSize(alloc.Bytes())
+	buf.alloc.Merge(&alloc)
+
+	if buf.alloc.Bytes() > s.targetMaxFileSize {
+		s.metrics.recordSizeBasedFlush()
+		return s.flushBuffer(ctx, buf)
+	}
+	return nil
+}
+
+func newIcebergBuffer(
+	topic string, version descpb.DescriptorVersion, row cdcevent.Row,
+) (*icebergBuffer, error) {
+	buf := &icebergBuffer{topic: topic, version: version, keys: make(map[string]int)}
+	if err := row.ForAllColumns().Col(func(col cdcevent.ResultColumn) error {
+		icebergType, parquetType, err := icebergColumnType(col.Typ)
+		if err != nil {
+			return errors.Wrapf(err, `column %s`, col.Name)
+		}
+		buf.columns = append(buf.columns, icebergColumn{Name: col.Name, Type: icebergType})
+		buf.parquetTypes = append(buf.parquetTypes, parquetType)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := row.ForEachKeyColumn().Col(func(col cdcevent.ResultColumn) error {
+		for i := range buf.columns {
+			if buf.columns[i].Name == col.Name {
+				buf.keyIdx = append(buf.keyIdx, i)
+				return nil
+			}
+		}
+		return errors.AssertionFailedf(`primary key column %s not found`, col.Name)
+	}); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Flush implements the Sink interface.
+func (s *icebergSink) Flush(ctx context.Context) error {
+	defer s.metrics.recordFlushRequestCallback()()
+	names := make([]string, 0, len(s.buffers))
+	for name := range s.buffers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := s.flushBuffer(ctx, s.buffers[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushBuffer writes the buffered rows to data and delete files, and records
+// them in a pending commit file for the change frontier to commit.
+func (s *icebergSink) flushBuffer(ctx context.Context, buf *icebergBuffer) error {
+	defer func() {
+		buf.alloc.Release(ctx)
+		delete(s.buffers, buf.topic)
+	}()
+	if len(buf.rows) == 0 {
+		return nil
+	}
+
+	start := timeutil.Now()
+	prefix := fmt.Sprintf(`%s-%s-%d-%08d`,
+		cloudStorageFormatTime(hlc.Timestamp{WallTime: start.UnixNano()}), s.sessionID, s.srcID, s.commitSeq)
+	commit := icebergCommit{Columns: buf.columns}
+
+	// Every primary key touched since the last flush is deleted, inserted rows
+	// included, so that committing the same files twice does not duplicate
+	// rows.
+	keyTypes := make([]*types.T, len(buf.keyIdx))
+	keyNames := make([]string, len(buf.keyIdx))
+	for i, j := range buf.keyIdx {
+		keyNames[i], keyTypes[i] = buf.columns[j].Name, buf.parquetTypes[j]
+	}
+	commit.KeyColumns = keyNames
+	var err error
+	commit.DeleteFile, err = s.writeParquet(ctx, buf.topic, prefix+`-deletes`, keyNames, keyTypes,
+		func(add func(tree.Datums) error) error {
+			for _, r := range buf.rows {
+				if err := add(icebergParquetRow(r.key, keyTypes)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(buf.columns))
+	for i, col := range buf.columns {
+		names[i] = col.Name
+	}
+	commit.DataFile, err = s.writeParquet(ctx, buf.topic, prefix+`-data`, names, buf.parquetTypes,
+		func(add func(tree.Datums) error) error {
+			for _, r := range buf.rows {
+				if r.datums == nil {
+					continue
+				}
+				if err := add(icebergParquetRow(r.datums, buf.parquetTypes)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(commit)
+	if err != nil {
+		return err
+	}
+	pendingName := path.Join(buf.topic, icebergPendingDir, prefix+`.json`)
+	if log.V(1) {
+		log.Infof(ctx, "writing iceberg pending commit %s with %d rows", pendingName, len(buf.rows))
+	}
+	if err := cloud.WriteFile(ctx, s.es, pendingName, bytes.NewReader(encoded)); err != nil {
+		return err
+	}
+	s.commitSeq++
+
+	fileSize := commit.DeleteFile.FileSize
+	if commit.DataFile != nil {
+		fileSize += commit.DataFile.FileSize
+	}
+	s.metrics.recordEmittedBatch(start, len(buf.rows), hlc.Timestamp{}, int(buf.alloc.Bytes()), int(fileSize))
+	return nil
+}
+
+// icebergParquetRow converts the datums of a row to the types they are written
+// to parquet as. See icebergColumnType.
+func icebergParquetRow(row tree.Datums, parquetTypes []*types.T) tree.Datums {
+	converted := make(tree.Datums, len(row))
+	for i, d := range row {
+		converted[i] = d
+		if d != tree.DNull && d.ResolvedType().Family() != parquetTypes[i].Family() {
+			converted[i] = tree.NewDString(tree.AsStringWithFlags(d, tree.FmtBareStrings))
+		}
+	}
+	return converted
+}
+
+// writeParquet writes the rows produced by fn to a parquet file named
+// `<topic>/data/<name>.parquet`. No file is written if fn produces no rows.
+func (s *icebergSink) writeParquet(
+	ctx context.Context,
+	topic, name string,
+	colNames []string,
+	colTypes []*types.T,
+	fn func(add func(tree.Datums) error) error,
+) (*icebergFile, error) {
+	sch, err := parquet.NewSchema(colNames, colTypes)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	// The reader metadata allows the file to be read back when it is compacted.
+	w, err := parquet.NewWriter(sch, &out,
+		parquet.WithCompressionCodec(s.compression), parquet.WithReaderMetadata())
+	if err != nil {
+		return nil, err
+	}
+	var numRows int64
+	if err := fn(func(row tree.Datums) error {
+		numRows++
+		return w.AddRow(row)
+	}); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if numRows == 0 {
+		return nil, nil
+	}
+
+	f := &icebergFile{
+		Path:        path.Join(icebergDataDir, name+`.parquet`),
+		RecordCount: numRows,
+		FileSize:    int64(out.Len()),
+	}
+	if err := cloud.WriteFile(ctx, s.es, path.Join(topic, f.Path), &out); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// EmitResolvedTimestamp commits every pending commit file as a snapshot of
+// its table. Implements the Sink interface.
+func (s *icebergSink) EmitResolvedTimestamp(
+	ctx context.Context, _ Encoder, resolved hlc.Timestamp,
+) error {
+	if s.tables == nil {
+		return errors.New(`cannot EmitResolvedTimestamp on a closed sink`)
+	}
+	defer s.metrics.recordResolvedCallback()()
+	return s.topicNamer.Each(func(topic string) error {
+		return s.commitPending(ctx, topic, resolved)
+	})
+}
+
+// commitPending commits the pending commit files of a topic, in the order in
+// which they were written, as snapshots of the topic's table, unless the
+// table was committed less than the commit interval ago.
+func (s *icebergSink) commitPending(
+	ctx context.Context, topic string, resolved hlc.Timestamp,
+) error {
+	table, ok := s.tables[topic]
+	if !ok {
+		var err error
+		if table, err = loadIcebergTable(ctx, s.es, topic, s.location+`/`+topic); err != nil {
+			return errors.Wrapf(err, `loading iceberg table for %s`, topic)
+		}
+		s.tables[topic] = table
+	}
+	now := timeutil.Now()
+	if table.metadata.CurrentSnapshotID != nil &&
+		now.Sub(time.UnixMilli(table.metadata.LastUpdatedMs)) < s.commitInterval {
+		return nil
+	}
+
+	pendingDir := path.Join(topic, icebergPendingDir)
+	var pending []string
+	if err := s.es.List(ctx, pendingDir, ``, func(name string) error {
+		pending = append(pending, strings.TrimPrefix(name, `/`))
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	// Pending commit files are named by the time they were written, followed by
+	// a sequence number, so sorting them yields the order in which they were
+	// written by each aggregator.
+	sort.Strings(pending)
+
+	summary := map[string]string{icebergResolvedSummaryKey: resolved.AsOfSystemTime()}
+	for _, name := range pending {
+		raw, err := table.readFile(ctx, path.Join(icebergPendingDir, name))
+		if err != nil {
+			return err
+		}
+		var c icebergCommit
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return errors.Wrapf(err, `parsing iceberg pending commit %s`, name)
+		}
+		if err := table.addSnapshot(ctx, c, summary); err != nil {
+			// The in-memory state of the table may be partially updated, so it is
+			// reloaded on the next attempt.
+			delete(s.tables, topic)
+			return err
+		}
+	}
+	if err := s.maybeCompact(ctx, topic, table, summary); err != nil {
+		delete(s.tables, topic)
+		return err
+	}
+	expired := table.expireSnapshots(now)
+	obsolete, err := table.writeMetadata(ctx)
+	if err != nil {
+		delete(s.tables, topic)
+		return err
+	}
+	if log.V(1) {
+		log.Infof(ctx, "committed %d iceberg snapshots to %s at %s, expiring %d",
+			len(pending), topic, resolved, len(expired))
+	}
+
+	for _, name := range pending {
+		if err := s.es.Delete(ctx, path.Join(pendingDir, name)); err != nil {
+			return err
+		}
+	}
+	// Files are only deleted once the pending commit files are, since a
+	// repeated commit of a pending commit file adds back its files.
+	table.deleteExpired(ctx, expired, obsolete)
+	return nil
+}
+
+// icebergFileGroup holds the live files of a table with the same data
+// sequence number: the files added by one commit, or written by the
+// compaction of several.
+type icebergFileGroup struct {
+	seq   int64
+	files []icebergDataFile
+	size  int64
+}
+
+func makeIcebergFileGroups(files []icebergDataFile) []icebergFileGroup {
+	sorted := append([]icebergDataFile(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].seq < sorted[j].seq })
+	var groups []icebergFileGroup
+	for _, f := range sorted {
+		if len(groups) == 0 || groups[len(groups)-1].seq != f.seq {
+			groups = append(groups, icebergFileGroup{seq: f.seq})
+		}
+		g := &groups[len(groups)-1]
+		g.files = append(g.files, f)
+		g.size += f.fileSize
+	}
+	return groups
+}
+
+// maybeCompact compacts the files of the newest icebergCompactionFanIn groups
+// of the table's files into one group, for as long as the oldest of them is no
+// larger than the others combined and they are small enough to read into
+// memory. Groups are therefore compacted into exponentially larger ones, so
+// that the table holds a logarithmic number of equality delete files, and
+// each row is rewritten a logarithmic number of times.
+func (s *icebergSink) maybeCompact(
+	ctx context.Context, topic string, table *icebergTable, summary map[string]string,
+) error {
+	for {
+		groups := makeIcebergFileGroups(table.files)
+		if len(groups) < icebergCompactionFanIn {
+			return nil
+		}
+		window := groups[len(groups)-icebergCompactionFanIn:]
+		var newer int64
+		for _, g := range window[1:] {
+			newer += g.size
+		}
+		if window[0].size > newer || window[0].size+newer > icebergCompactionMaxBytes {
+			return nil
+		}
+		// The delete files of every group must be on the same columns, which
+		// only differ across a change of the primary key.
+		var equalityIDs []interface{}
+		for _, g := range window {
+			for _, f := range g.files {
+				if f.content == icebergContentData {
+					continue
+				}
+				if equalityIDs == nil {
+					equalityIDs = f.equalityIDs
+				} else if fmt.Sprint(equalityIDs) != fmt.Sprint(f.equalityIDs) {
+					return nil
+				}
+			}
+		}
+		oldest := len(groups) == len(window)
+		if err := s.compact(ctx, topic, table, window, oldest, equalityIDs, summary); err != nil {
+			return errors.Wrapf(err, `compacting iceberg table for %s`, topic)
+		}
+	}
+}
+
+// compact replaces the files of consecutive groups with a data file holding
+// the rows which are live at the newest of them, and an equality delete file
+// holding every key which they delete, both with the data sequence number of
+// the newest group. The delete file is omitted if the groups are the oldest in
+// the table, since there are no older files to which it would apply.
+func (s *icebergSink) compact(
+	ctx context.Context,
+	topic string,
+	table *icebergTable,
+	window []icebergFileGroup,
+	oldest bool,
+	equalityIDs []interface{},
+	summary map[string]string,
+) error {
+	fields := table.currentSchema().Fields
+	fieldNames := make(map[int]string, len(fields))
+	for _, f := range fields {
+		fieldNames[f.ID] = f.Name
+	}
+	keyNames := make([]string, len(equalityIDs))
+	for i, id := range equalityIDs {
+		keyNames[i] = fieldNames[int(id.(int32))]
+	}
+
+	type dataRows struct {
+		names []string
+		rows  [][]tree.Datum
+	}
+	var data []dataRows
+	colTypes := make(map[string]*types.T)
+	keyTypes := make([]*types.T, len(keyNames))
+	deletedKeys := make(map[string]struct{})
+	var deletes []tree.Datums
+	var removed []icebergDataFile
+	var scratch []byte
+
+	// Groups are read from newest to oldest, so that a row is only kept if no
+	// newer group deletes its key.
+	for i := len(window) - 1; i >= 0; i-- {
+		var deleteFiles []icebergDataFile
+		for _, f := range window[i].files {
+			removed = append(removed, f)
+			if f.content != icebergContentData {
+				deleteFiles = append(deleteFiles, f)
+				continue
+			}
+			meta, rows, err := table.readParquet(ctx, f.path)
+			if err != nil {
+				return err
+			}
+			keyIdx, err := icebergColumnIndexes(f.path, meta.ColumnNames, keyNames)
+			if err != nil {
+				return err
+			}
+			for j, name := range meta.ColumnNames {
+				colTypes[name] = icebergCompactionType(colTypes[name], meta.ColumnTypes[j])
+			}
+			live := rows[:0]
+			for _, row := range rows {
+				scratch = icebergCompactionKey(scratch[:0], row, keyIdx)
+				if _, ok := deletedKeys[string(scratch)]; !ok {
+					live = append(live, row)
+				}
+			}
+			data = append(data, dataRows{names: meta.ColumnNames, rows: live})
+		}
+		for _, f := range deleteFiles {
+			meta, rows, err := table.readParquet(ctx, f.path)
+			if err != nil {
+				return err
+			}
+			keyIdx, err := icebergColumnIndexes(f.path, meta.ColumnNames, keyNames)
+			if err != nil {
+				return err
+			}
+			for j, idx := range keyIdx {
+				keyTypes[j] = icebergCompactionType(keyTypes[j], meta.ColumnTypes[idx])
+			}
+			for _, row := range rows {
+				scratch = icebergCompactionKey(scratch[:0], row, keyIdx)
+				if _, ok := deletedKeys[string(scratch)]; ok {
+					continue
+				}
+				deletedKeys[string(scratch)] = struct{}{}
+				key := make(tree.Datums, len(keyIdx))
+				for j, idx := range keyIdx {
+					key[j] = row[idx]
+				}
+				deletes = append(deletes, key)
+			}
+		}
+	}
+
+	seq := window[len(window)-1].seq
+	prefix := fmt.Sprintf(`%s-%s-compacted`,
+		cloudStorageFormatTime(hlc.Timestamp{WallTime: timeutil.Now().UnixNano()}), uuid.MakeV4())
+	var added []icebergDataFile
+	if !oldest && len(deletes) > 0 {
+		f, err := s.writeParquet(ctx, topic, prefix+`-deletes`, keyNames, keyTypes,
+			func(add func(tree.Datums) error) error {
+				for _, key := range deletes {
+					if err := add(icebergCompactionRow(key, keyTypes)); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+		deleteFile := table.newDataFile(icebergContentEqualityDelete, *f)
+		deleteFile.equalityIDs, deleteFile.seq = equalityIDs, seq
+		added = append(added, deleteFile)
+	}
+
+	// The compacted data file has every column of the compacted data files, in
+	// the order of the table's schema.
+	var names []string
+	var typs []*types.T
+	for _, f := range fields {
+		if typ, ok := colTypes[f.Name]; ok {
+			names, typs = append(names, f.Name), append(typs, typ)
+		}
+	}
+	var numRows int
+	for _, d := range data {
+		numRows += len(d.rows)
+	}
+	var f *icebergFile
+	if numRows > 0 {
+		var err error
+		f, err = s.writeParquet(ctx, topic, prefix+`-data`, names, typs,
+			func(add func(tree.Datums) error) error {
+				for _, d := range data {
+					colIdx, err := icebergColumnIndexes(``, names, d.names)
+					if err != nil {
+						return err
+					}
+					for _, row := range d.rows {
+						out := make(tree.Datums, len(names))
+						for j := range out {
+							out[j] = tree.DNull
+						}
+						for j, idx := range colIdx {
+							out[idx] = row[j]
+						}
+						if err := add(icebergCompactionRow(out, typs)); err != nil {
+							return err
+						}
+					}
+				}
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+	}
+	if f != nil {
+		dataFile := table.newDataFile(icebergContentData, *f)
+		dataFile.seq = seq
+		added = append(added, dataFile)
+	}
+
+	if log.V(1) {
+		log.Infof(ctx, "compacting %d iceberg files of %s into %d", len(removed), topic, len(added))
+	}
+	return table.commitSnapshot(ctx, `replace`, added, removed, summary)
+}
+
+// readParquet reads the parquet file with the given absolute path.
+func (t *icebergTable) readParquet(
+	ctx context.Context, abs string,
+) (parquet.ReadDatumsMetadata, [][]tree.Datum, error) {
+	rel, err := t.relativePath(abs)
+	if err != nil {
+		return parquet.ReadDatumsMetadata{}, nil, err
+	}
+	raw, err := t.readFile(ctx, rel)
+	if err != nil {
+		return parquet.ReadDatumsMetadata{}, nil, err
+	}
+	meta, rows, err := parquet.Read(bytes.NewReader(raw))
+	if err != nil {
+		return parquet.ReadDatumsMetadata{}, nil, errors.Wrapf(err, `reading %s`, abs)
+	}
+	if meta.ColumnNames == nil && len(rows) > 0 {
+		return parquet.ReadDatumsMetadata{}, nil, errors.AssertionFailedf(
+			`iceberg file %s has columns which cannot be compacted`, abs)
+	}
+	return meta, rows, nil
+}
+
+// icebergColumnIndexes returns the index in columns of each of names.
+func icebergColumnIndexes(file string, columns, names []string) ([]int, error) {
+	idx := make([]int, len(names))
+	for i, name := range names {
+		idx[i] = -1
+		for j, col := range columns {
+			if col == name {
+				idx[i] = j
+				break
+			}
+		}
+		if idx[i] < 0 {
+			return nil, errors.AssertionFailedf(`iceberg file %s is missing column %s`, file, name)
+		}
+	}
+	return idx, nil
+}
+
+// icebergCompactionType returns the type with which a column is written by
+// compaction, given the type it was written with in a compacted file and the
+// type chosen for it by the files compacted so far. A column whose values
+// were written as different types in different files is written as a string,
+// which is how every type that shares an Iceberg type with another is written
+// to parquet.
+func icebergCompactionType(chosen, typ *types.T) *types.T {
+	if chosen == nil || chosen.Family() == typ.Family() {
+		return typ
+	}
+	return types.String
+}
+
+// icebergCompactionRow converts the datums of a row read back from parquet to
+// the types with which they are written by compaction. See
+// icebergCompactionType.
+func icebergCompactionRow(row tree.Datums, typs []*types.T) tree.Datums {
+	for i, d := range row {
+		if d == tree.DNull || typs[i].Family() != types.StringFamily {
+			continue
+		}
+		if _, ok := tree.AsDString(d); !ok {
+			row[i] = tree.NewDString(tree.AsStringWithFlags(d, tree.FmtBareStrings))
+		}
+	}
+	return row
+}
+
+// icebergCompactionKey appends an encoding of the key columns of the row to
+// buf. Keys are compared by their textual representation, since the datums
+// read back from parquet are not hydrated.
+func icebergCompactionKey(buf []byte, row tree.Datums, keyIdx []int) []byte {
+	for _, idx := range keyIdx {
+		buf = encoding.EncodeStringAscending(buf, tree.AsStringWithFlags(row[idx], tree.FmtBareStrings))
+	}
+	return buf
+}
+
+// Close implements the Sink interface.
+func (s *icebergSink) Close() error {
+	for _, buf := range s.buffers {
+		buf.alloc.Release(context.Background())
+	}
+	s.buffers = nil
+	s.tables = nil
+	return s.es.Close()
+}
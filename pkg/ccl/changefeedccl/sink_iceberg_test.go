@@ -0,0 +1,346 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/blobs"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/parquet"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIcebergSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	externalIODir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	settings := cluster.MakeTestingClusterSettings()
+	settings.ExternalIODir = externalIODir
+	clientFactory := blobs.TestBlobServiceClient(settings.ExternalIODir)
+	externalStorageFromURI := func(ctx context.Context, uri string, user username.SQLUsername, opts ...cloud.ExternalStorageOption) (cloud.ExternalStorage,
+		error) {
+		return cloud.ExternalStorageFromURI(ctx, uri, base.ExternalIODirConfig{}, settings,
+			clientFactory,
+			user,
+			nil, /* db */
+			nil, /* limiters */
+			cloud.NilMetrics,
+			opts...)
+	}
+
+	opts := changefeedbase.EncodingOptions{
+		Format:   changefeedbase.OptFormatParquet,
+		Envelope: changefeedbase.OptEnvelopeWrapped,
+	}
+	t1 := makeTopic(`t1`)
+	var targets changefeedbase.Targets
+	targets.Add(t1.GetTargetSpecification())
+
+	// Sinks commit at every resolved timestamp unless a commit interval is
+	// given.
+	makeSink := func(
+		t *testing.T, opts changefeedbase.EncodingOptions, commitInterval string,
+	) (*icebergSink, error) {
+		if commitInterval == `` {
+			commitInterval = `0s`
+		}
+		u, err := url.Parse(`iceberg-nodelocal://1/iceberg?commit_interval=` + commitInterval)
+		require.NoError(t, err)
+		s, err := makeIcebergSink(ctx, sinkURL{URL: u}, 1, opts, targets,
+			externalStorageFromURI, username.RootUserName(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return s.(*icebergSink), nil
+	}
+
+	colTypes := []*types.T{types.Int, types.String}
+	row := func(k int, v string) cdcevent.Row {
+		return cdcevent.TestingMakeEventRowFromEncDatums(rowenc.EncDatumRow{
+			rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(k))),
+			rowenc.DatumToEncDatum(types.String, tree.NewDString(v)),
+		}, colTypes, 1 /* numKeyCols */, false /* deleted */)
+	}
+	deleted := func(k int) cdcevent.Row {
+		return cdcevent.TestingMakeEventRowFromEncDatums(rowenc.EncDatumRow{
+			rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(k))),
+			rowenc.DatumToEncDatum(types.String, tree.DNull),
+		}, colTypes, 1 /* numKeyCols */, true /* deleted */)
+	}
+	ts := func(i int64) hlc.Timestamp { return hlc.Timestamp{WallTime: i} }
+	emit := func(t *testing.T, s *icebergSink, r cdcevent.Row) {
+		require.NoError(t, s.EncodeAndEmitRow(
+			ctx, r, cdcevent.Row{}, t1, ts(1), ts(1), opts, zeroAlloc))
+	}
+
+	tableDir := filepath.Join(externalIODir, `iceberg`, `t1`)
+	readMetadata := func(t *testing.T) icebergTableMetadata {
+		hint, err := os.ReadFile(filepath.Join(tableDir, `metadata`, `version-hint.text`))
+		require.NoError(t, err)
+		raw, err := os.ReadFile(filepath.Join(tableDir, `metadata`, `v`+string(hint)+`.metadata.json`))
+		require.NoError(t, err)
+		var m icebergTableMetadata
+		require.NoError(t, json.Unmarshal(raw, &m))
+		return m
+	}
+	localPath := func(t *testing.T, abs string) string {
+		rel, err := (&icebergTable{location: `nodelocal://1/iceberg/t1`}).relativePath(abs)
+		require.NoError(t, err)
+		return filepath.Join(tableDir, rel)
+	}
+	readManifests := func(t *testing.T, m icebergTableMetadata) []map[string]interface{} {
+		var current icebergSnapshot
+		for _, s := range m.Snapshots {
+			if s.SnapshotID == *m.CurrentSnapshotID {
+				current = s
+			}
+		}
+		raw, err := os.ReadFile(localPath(t, current.ManifestList))
+		require.NoError(t, err)
+		ocf, err := goavro.NewOCFReader(bytes.NewReader(raw))
+		require.NoError(t, err)
+		var manifests []map[string]interface{}
+		for ocf.Scan() {
+			manifest, err := ocf.Read()
+			require.NoError(t, err)
+			manifests = append(manifests, manifest.(map[string]interface{}))
+		}
+		require.NoError(t, ocf.Err())
+		return manifests
+	}
+
+	t.Run(`requires parquet`, func(t *testing.T) {
+		_, err := makeSink(t, changefeedbase.EncodingOptions{
+			Format:   changefeedbase.OptFormatJSON,
+			Envelope: changefeedbase.OptEnvelopeWrapped,
+		}, ``)
+		require.Regexp(t, `this sink requires format=parquet`, err)
+	})
+
+	t.Run(`commit`, func(t *testing.T) {
+		aggregator, err := makeSink(t, opts, ``)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, aggregator.Close()) }()
+		frontier, err := makeSink(t, opts, ``)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, frontier.Close()) }()
+
+		// Nothing is committed before the first flush.
+		require.NoError(t, frontier.EmitResolvedTimestamp(ctx, nil, ts(1)))
+		_, err = os.Stat(filepath.Join(tableDir, `metadata`))
+		require.True(t, os.IsNotExist(err))
+
+		emit(t, aggregator, row(1, `a`))
+		emit(t, aggregator, row(2, `b`))
+		emit(t, aggregator, row(1, `c`))
+		require.NoError(t, aggregator.Flush(ctx))
+		emit(t, aggregator, deleted(2))
+		require.NoError(t, aggregator.Flush(ctx))
+
+		require.NoError(t, frontier.EmitResolvedTimestamp(ctx, nil, ts(5)))
+
+		m := readMetadata(t)
+		require.Equal(t, 2, len(m.Snapshots))
+		require.Equal(t, int64(2), m.LastSequenceNumber)
+		require.Equal(t, []icebergField{
+			{ID: 1, Name: `col_int`, Type: `long`},
+			{ID: 2, Name: `col_string`, Type: `string`},
+		}, m.Schemas[0].Fields)
+		require.Equal(t, `5.0000000000`, m.Snapshots[1].Summary[icebergResolvedSummaryKey])
+		require.Equal(t, `overwrite`, m.Snapshots[1].Summary[`operation`])
+
+		// The first flush added a data and a delete manifest, and the second only
+		// a delete manifest.
+		manifests := readManifests(t, m)
+		require.Equal(t, 3, len(manifests))
+		var contents []int32
+		for _, manifest := range manifests {
+			contents = append(contents, manifest[`content`].(int32))
+		}
+		require.Equal(t, []int32{icebergContentDeletes, icebergContentDeletes, icebergContentData}, contents)
+
+		// The data file holds only the latest version of each row.
+		raw, err := os.ReadFile(localPath(t, manifests[2][`manifest_path`].(string)))
+		require.NoError(t, err)
+		ocf, err := goavro.NewOCFReader(bytes.NewReader(raw))
+		require.NoError(t, err)
+		require.True(t, ocf.Scan())
+		entry, err := ocf.Read()
+		require.NoError(t, err)
+		dataFile := entry.(map[string]interface{})[`data_file`].(map[string]interface{})
+		_, datums, err := parquet.ReadFile(localPath(t, dataFile[`file_path`].(string)))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(datums))
+		parquet.ValidateDatum(t, tree.NewDInt(1), datums[0][0])
+		parquet.ValidateDatum(t, tree.NewDString(`c`), datums[0][1])
+		parquet.ValidateDatum(t, tree.NewDInt(2), datums[1][0])
+
+		// All pending commits were removed once committed.
+		pending, err := os.ReadDir(filepath.Join(tableDir, icebergPendingDir))
+		require.NoError(t, err)
+		require.Empty(t, pending)
+
+		// A new frontier picks up the table where the last one left off.
+		restarted, err := makeSink(t, opts, ``)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, restarted.Close()) }()
+		emit(t, aggregator, row(3, `d`))
+		require.NoError(t, aggregator.Flush(ctx))
+		require.NoError(t, restarted.EmitResolvedTimestamp(ctx, nil, ts(10)))
+
+		m = readMetadata(t)
+		require.Equal(t, 3, len(m.Snapshots))
+		require.Equal(t, 1, len(m.MetadataLog))
+		require.Equal(t, 5, len(readManifests(t, m)))
+	})
+	// liveFiles returns the live entries of the manifests of the current
+	// snapshot.
+	liveFiles := func(t *testing.T, m icebergTableMetadata) []map[string]interface{} {
+		var live []map[string]interface{}
+		for _, manifest := range readManifests(t, m) {
+			raw, err := os.ReadFile(localPath(t, manifest[`manifest_path`].(string)))
+			require.NoError(t, err)
+			ocf, err := goavro.NewOCFReader(bytes.NewReader(raw))
+			require.NoError(t, err)
+			for ocf.Scan() {
+				entry, err := ocf.Read()
+				require.NoError(t, err)
+				if entry.(map[string]interface{})[`status`].(int32) != icebergStatusDeleted {
+					live = append(live, entry.(map[string]interface{}))
+				}
+			}
+			require.NoError(t, ocf.Err())
+		}
+		return live
+	}
+
+	t.Run(`commit interval`, func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(tableDir))
+		aggregator, err := makeSink(t, opts, ``)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, aggregator.Close()) }()
+		frontier, err := makeSink(t, opts, `1h`)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, frontier.Close()) }()
+
+		// The first commit to a table is not delayed.
+		emit(t, aggregator, row(1, `a`))
+		require.NoError(t, aggregator.Flush(ctx))
+		require.NoError(t, frontier.EmitResolvedTimestamp(ctx, nil, ts(1)))
+		require.Equal(t, 1, len(readMetadata(t).Snapshots))
+
+		// Later ones wait for the commit interval, leaving the files pending.
+		emit(t, aggregator, row(2, `b`))
+		require.NoError(t, aggregator.Flush(ctx))
+		require.NoError(t, frontier.EmitResolvedTimestamp(ctx, nil, ts(2)))
+		require.Equal(t, 1, len(readMetadata(t).Snapshots))
+		pending, err := os.ReadDir(filepath.Join(tableDir, icebergPendingDir))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(pending))
+	})
+
+	t.Run(`maintenance`, func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(tableDir))
+		aggregator, err := makeSink(t, opts, ``)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, aggregator.Close()) }()
+		frontier, err := makeSink(t, opts, ``)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, frontier.Close()) }()
+
+		emit(t, aggregator, row(1, `a`))
+		require.NoError(t, aggregator.Flush(ctx))
+		require.NoError(t, frontier.EmitResolvedTimestamp(ctx, nil, ts(1)))
+
+		// A negative maximum age expires every snapshot but the current one, and
+		// no previous metadata files are retained.
+		props := frontier.tables[`t1`].metadata.Properties
+		props[icebergMaxSnapshotAgeProperty] = `-3600000`
+		props[icebergPreviousVersionsMaxProperty] = `0`
+
+		// The next three commits make four groups of files, which are compacted
+		// into a single data file. Since they are the oldest files of the table,
+		// no delete file is needed.
+		emit(t, aggregator, row(2, `b`))
+		require.NoError(t, aggregator.Flush(ctx))
+		emit(t, aggregator, row(1, `c`))
+		require.NoError(t, aggregator.Flush(ctx))
+		emit(t, aggregator, deleted(2))
+		require.NoError(t, aggregator.Flush(ctx))
+		require.NoError(t, frontier.EmitResolvedTimestamp(ctx, nil, ts(5)))
+
+		m := readMetadata(t)
+		require.Equal(t, 1, len(m.Snapshots))
+		require.Equal(t, 1, len(m.SnapshotLog))
+		require.Equal(t, 0, len(m.MetadataLog))
+		require.Equal(t, int64(5), m.LastSequenceNumber)
+		require.Equal(t, `replace`, m.Snapshots[0].Summary[`operation`])
+		_, err = os.Stat(filepath.Join(tableDir, `metadata`, `v1.metadata.json`))
+		require.True(t, os.IsNotExist(err))
+
+		live := liveFiles(t, m)
+		require.Equal(t, 1, len(live))
+		require.Equal(t, goavro.Union(`long`, int64(4)), live[0][`sequence_number`])
+		dataFile := live[0][`data_file`].(map[string]interface{})
+		require.Equal(t, int32(icebergContentData), dataFile[`content`])
+		_, datums, err := parquet.ReadFile(localPath(t, dataFile[`file_path`].(string)))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(datums))
+		parquet.ValidateDatum(t, tree.NewDInt(1), datums[0][0])
+		parquet.ValidateDatum(t, tree.NewDString(`c`), datums[0][1])
+
+		// The files of the expired snapshots were deleted.
+		data, err := os.ReadDir(filepath.Join(tableDir, icebergDataDir))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(data))
+
+		// A new frontier loads the compacted file along with its sequence number,
+		// and the delete files of later commits apply to it.
+		restarted, err := makeSink(t, opts, ``)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, restarted.Close()) }()
+		emit(t, aggregator, deleted(1))
+		require.NoError(t, aggregator.Flush(ctx))
+		require.NoError(t, restarted.EmitResolvedTimestamp(ctx, nil, ts(10)))
+		files := restarted.tables[`t1`].files
+		require.Equal(t, 2, len(files))
+		for _, f := range files {
+			if f.content == icebergContentData {
+				require.Equal(t, int64(4), f.seq)
+				require.Equal(t, int64(5), f.fileSeq)
+			} else {
+				require.Equal(t, int64(6), f.seq)
+			}
+		}
+	})
+}
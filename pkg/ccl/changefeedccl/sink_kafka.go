@@ -143,11 +143,31 @@ type kafkaSink struct {
 		inflight int64
 		flushErr error
 		flushCh  chan struct{}
+
+		// deadLetters are the rejected row messages which the worker has yet to
+		// send to the dead letter queue, and writingDeadLetters is the number of
+		// those it is sending. The dead letter queue may write to external
+		// storage, so the worker sends them without holding the lock. Flushes
+		// wait for them like for inflight messages.
+		deadLetters        []deadLetter
+		writingDeadLetters int
 	}
 
 	disableInternalRetry bool
+
+	// deadLetters, if set, receives the row messages which kafka did not
+	// accept once sarama exhausted its retries.
+	deadLetters *deadLetterQueue
+}
+
+// setDeadLetterQueue implements the deadLetterQueueSetter interface. It must be
+// called before any rows are emitted.
+func (s *kafkaSink) setDeadLetterQueue(q *deadLetterQueue) {
+	s.deadLetters = q
 }
 
+var _ deadLetterQueueSetter = (*kafkaSink)(nil)
+
 func (s *kafkaSink) getConcreteType() sinkType {
 	return sinkTypeKafka
 }
@@ -430,7 +450,7 @@ func (s *kafkaSink) Flush(ctx context.Context) error {
 		inflight = s.mu.inflight
 		flushErr = s.mu.flushErr
 		s.mu.flushErr = nil
-		immediateFlush = inflight == 0 || flushErr != nil
+		immediateFlush = (inflight == 0 && !s.hasPendingDeadLettersLocked()) || flushErr != nil
 		if !immediateFlush {
 			s.mu.flushCh = flushCh
 		}
@@ -587,19 +607,55 @@ func (s *kafkaSink) workerLoop() {
 
 		// If we're in a retry inflight can be 0 but messages in retryBuf are yet to
 		// be resent.
-		if !isRetrying() && s.mu.inflight == 0 && s.mu.flushCh != nil {
-			s.mu.flushCh <- struct{}{}
-			s.mu.flushCh = nil
+		if !isRetrying() {
+			s.maybeSignalFlushLocked()
 		}
 
 		// If we're in a retry we keep hold of the lock to stop all other operations
 		// until the retry has completed.
 		if !isRetrying() {
+			letters := s.mu.deadLetters
+			s.mu.deadLetters = nil
+			s.mu.writingDeadLetters += len(letters)
 			muLocker.Unlock()
+			s.writeDeadLetters(letters)
 		}
 	}
 }
 
+// hasPendingDeadLettersLocked returns true if rejected row messages have yet to
+// be sent to the dead letter queue.
+func (s *kafkaSink) hasPendingDeadLettersLocked() bool {
+	s.mu.AssertHeld()
+	return len(s.mu.deadLetters) > 0 || s.mu.writingDeadLetters > 0
+}
+
+// maybeSignalFlushLocked signals a waiting flush once no messages are in
+// flight and all rejected messages were sent to the dead letter queue.
+func (s *kafkaSink) maybeSignalFlushLocked() {
+	s.mu.AssertHeld()
+	if s.mu.inflight == 0 && !s.hasPendingDeadLettersLocked() && s.mu.flushCh != nil {
+		s.mu.flushCh <- struct{}{}
+		s.mu.flushCh = nil
+	}
+}
+
+// writeDeadLetters sends the letters, which were taken from s.mu.deadLetters,
+// to the dead letter queue. It must be called without holding s.mu.
+func (s *kafkaSink) writeDeadLetters(letters []deadLetter) {
+	if len(letters) == 0 {
+		return
+	}
+	err := s.deadLetters.Enqueue(s.ctx, letters...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.writingDeadLetters -= len(letters)
+	if err != nil && s.mu.flushErr == nil {
+		s.mu.flushErr = errors.Wrap(err, "sending rejected messages to the dead letter queue")
+	}
+	s.maybeSignalFlushLocked()
+}
+
 func (s *kafkaSink) finishProducerMessage(ackMsg *sarama.ProducerMessage, ackError error) {
 	s.mu.AssertHeld()
 	if m, ok := ackMsg.Metadata.(messageMetadata); ok {
@@ -607,6 +663,8 @@ func (s *kafkaSink) finishProducerMessage(ackMsg *sarama.ProducerMessage, ackErr
 			sz := ackMsg.Key.Length() + ackMsg.Value.Length()
 			s.stats.finishMessage(int64(sz))
 			m.updateMetrics(m.mvcc, sz, sinkDoesNotCompress)
+		} else {
+			ackError = s.maybeDeadLetter(ackMsg, m, ackError)
 		}
 		m.alloc.Release(s.ctx)
 	}
@@ -615,6 +673,34 @@ func (s *kafkaSink) finishProducerMessage(ackMsg *sarama.ProducerMessage, ackErr
 	}
 }
 
+// maybeDeadLetter collects a row message which kafka did not accept, to be
+// sent to the dead letter queue by the worker once it releases s.mu, if a
+// dead letter queue is configured. Returns nil if the message is
+// dead-lettered, and the delivery error otherwise. Resolved timestamp messages
+// carry no metadata and never reach this point.
+func (s *kafkaSink) maybeDeadLetter(
+	msg *sarama.ProducerMessage, m messageMetadata, ackError error,
+) error {
+	s.mu.AssertHeld()
+	if s.deadLetters == nil || s.ctx.Err() != nil {
+		return ackError
+	}
+	letter := deadLetter{
+		Reason: deadLetterDeliveryFailed,
+		Error:  ackError.Error(),
+		Topic:  msg.Topic,
+		MVCC:   m.mvcc.AsOfSystemTime(),
+	}
+	if msg.Key != nil {
+		letter.Key, _ = msg.Key.Encode()
+	}
+	if msg.Value != nil {
+		letter.Value, _ = msg.Value.Encode()
+	}
+	s.mu.deadLetters = append(s.mu.deadLetters, letter)
+	return nil
+}
+
 func (s *kafkaSink) handleBufferedRetries(msgs []*sarama.ProducerMessage, retryErr error) error {
 	lastSendErr := retryErr
 	activeConfig := s.kafkaCfg
@@ -362,6 +362,39 @@ func TestKafkaSink(t *testing.T) {
 	require.EqualValues(t, 0, pool.used())
 }
 
+// TestKafkaSinkDeadLetterQueue verifies that row messages which kafka does not
+// accept are written to the dead letter sink instead of failing the changefeed.
+func TestKafkaSinkDeadLetterQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	p := newAsyncProducerMock(1)
+	sink, cleanup := makeTestKafkaSink(t, noTopicPrefix, defaultTopicName, p, "t")
+	defer cleanup()
+	dlq, readDeadLetters := makeTestDeadLetterQueue(t, changefeedbase.MakeStatementOptions(
+		map[string]string{changefeedbase.OptDeadLetterSink: "nodelocal://1/dlq"}))
+	require.NoError(t, setDeadLetterQueue(sink, dlq))
+
+	var pool testAllocPool
+	require.NoError(t, sink.EmitRow(ctx, topic(`t`), []byte(`1`), []byte(`poison`), zeroTS, zeroTS, pool.alloc()))
+	m1 := <-p.inputCh
+	require.NoError(t, sink.EmitRow(ctx, topic(`t`), []byte(`2`), []byte(`good`), zeroTS, zeroTS, pool.alloc()))
+	m2 := <-p.inputCh
+	go func() { p.errorsCh <- &sarama.ProducerError{Msg: m1, Err: errors.New("rejected")} }()
+	go func() { p.successesCh <- m2 }()
+	require.NoError(t, sink.Flush(ctx))
+	require.EqualValues(t, 0, pool.used())
+
+	letters := readDeadLetters()
+	require.Len(t, letters, 1)
+	require.Equal(t, deadLetterDeliveryFailed, letters[0].Reason)
+	require.Equal(t, m1.Topic, letters[0].Topic)
+	require.Equal(t, []byte(`1`), letters[0].Key)
+	require.Equal(t, []byte(`poison`), letters[0].Value)
+	require.Contains(t, letters[0].Error, "rejected")
+}
+
 func TestKafkaSinkEscaping(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -9,19 +9,29 @@
 package changefeedccl
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/blobs"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdctest"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -695,6 +705,108 @@ func TestWebhookSinkShutsDownOnError(t *testing.T) {
 	}
 }
 
+// TestWebhookSinkDeadLetterQueue verifies that messages which cannot be
+// delivered after exhausting all retries are written to the dead letter sink
+// instead of failing the changefeed.
+func TestWebhookSinkDeadLetterQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	cert, certEncoded, err := cdctest.NewCACertBase64Encoded()
+	require.NoError(t, err)
+	sinkDest, err := cdctest.StartMockWebhookSink(cert)
+	require.NoError(t, err)
+	defer sinkDest.Close()
+
+	opts := getGenericWebhookSinkOptions(struct {
+		key   string
+		value string
+	}{key: changefeedbase.OptDeadLetterSink, value: "nodelocal://1/dlq"})
+
+	// Fail every attempt to deliver the first message, then accept the second.
+	sinkDest.SetStatusCodes(append(repeatStatusCode(http.StatusInternalServerError,
+		defaultRetryConfig().MaxRetries+1), http.StatusOK))
+	sinkDestHost, err := url.Parse(sinkDest.URL())
+	require.NoError(t, err)
+	params := sinkDestHost.Query()
+	params.Set(changefeedbase.SinkParamCACert, certEncoded)
+	sinkDestHost.RawQuery = params.Encode()
+
+	details := jobspb.ChangefeedDetails{
+		SinkURI: fmt.Sprintf("webhook-%s", sinkDestHost.String()),
+		Opts:    opts.AsMap(),
+	}
+	sinkSrc, err := setupWebhookSinkWithDetails(ctx, details, 1 /* parallelism */, timeutil.DefaultTimeSource{})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sinkSrc.Close()) }()
+
+	dlq, readDeadLetters := makeTestDeadLetterQueue(t, opts)
+	require.NoError(t, setDeadLetterQueue(sinkSrc, dlq))
+
+	poison := []byte(`{"after":{"col1":"poison","rowid":1000},"key":[1000],"topic:":"foo"}`)
+	require.NoError(t, sinkSrc.EmitRow(ctx, nil, []byte("[1000]"), poison, zeroTS, zeroTS, zeroAlloc))
+	require.NoError(t, sinkSrc.Flush(ctx))
+
+	good := []byte(`{"after":{"col1":"val1","rowid":1001},"key":[1001],"topic:":"foo"}`)
+	require.NoError(t, sinkSrc.EmitRow(ctx, nil, []byte("[1001]"), good, zeroTS, zeroTS, zeroAlloc))
+	require.NoError(t, sinkSrc.Flush(ctx))
+	require.Equal(t, `{"payload":[`+string(good)+`],"length":1}`, sinkDest.Pop())
+
+	letters := readDeadLetters()
+	require.Len(t, letters, 1)
+	letter := letters[0]
+	require.Equal(t, deadLetterDeliveryFailed, letter.Reason)
+	require.Equal(t, []byte("[1000]"), letter.Key)
+	require.Equal(t, poison, letter.Value)
+	require.Contains(t, letter.Error, "500 Internal Server Error")
+}
+
+// makeTestDeadLetterQueue returns a dead letter queue writing to the
+// dead_letter_sink of opts, which must be a nodelocal URI, along with a
+// function which flushes the queue and returns all the dead letters written so
+// far. The queue is closed when the test ends.
+func makeTestDeadLetterQueue(
+	t *testing.T, opts changefeedbase.StatementOptions,
+) (_ *deadLetterQueue, readDeadLetters func() []deadLetter) {
+	ctx := context.Background()
+	externalIODir, dirCleanupFn := testutils.TempDir(t)
+	t.Cleanup(dirCleanupFn)
+
+	settings := cluster.MakeTestingClusterSettings()
+	settings.ExternalIODir = externalIODir
+	clientFactory := blobs.TestBlobServiceClient(settings.ExternalIODir)
+	serverCfg := &execinfra.ServerConfig{
+		ExternalStorageFromURI: func(ctx context.Context, uri string, user username.SQLUsername,
+			opts ...cloud.ExternalStorageOption) (cloud.ExternalStorage, error) {
+			return cloud.ExternalStorageFromURI(ctx, uri, base.ExternalIODirConfig{}, settings,
+				clientFactory, user, nil /* db */, nil /* limiters */, cloud.NilMetrics, opts...)
+		},
+	}
+
+	dlq, err := makeDeadLetterQueue(ctx, serverCfg, opts, username.RootUserName(), 42, nil /* metrics */)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, dlq.Close()) })
+
+	u, err := parseDeadLetterSinkURI(opts)
+	require.NoError(t, err)
+	return dlq, func() []deadLetter {
+		require.NoError(t, dlq.Flush(ctx))
+		files, err := filepath.Glob(filepath.Join(externalIODir, u.Path, "*.ndjson"))
+		require.NoError(t, err)
+		var letters []deadLetter
+		for _, f := range files {
+			contents, err := os.ReadFile(f)
+			require.NoError(t, err)
+			for _, line := range bytes.Split(bytes.TrimSpace(contents), []byte("\n")) {
+				var letter deadLetter
+				require.NoError(t, json.Unmarshal(line, &letter))
+				letters = append(letters, letter)
+			}
+		}
+		return letters
+	}
+}
+
 // Regression test for https://github.com/cockroachdb/cockroach/issues/102467.
 // Ensure that we do not use the default retry config which is capped at
 // 4000ms.
@@ -34,6 +34,9 @@ Valid fields:
 * key     (required): path to the current key file, or "plain"
 * old-key (required): path to the previous key file, or "plain"
 * rotation-period   : amount of time after which data keys should be rotated
+* kms               : URI of a KMS master key; if set, the key files hold key
+                      material wrapped by the KMS, which is unwrapped when the
+                      store is opened
 
 </PRE>
 example:
@@ -14,10 +14,12 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/pebble/vfs"
 )
 
@@ -40,10 +42,12 @@ import (
 //   about encryption settings used for the file, including the key id.
 // - The StoreKeyManager uses the base-FS to read the user-specified store keys at startup.
 //   These are in two key files: the active key file and the old key file, which contain the
-//   key id and the key.
+//   key id and the key. The key files may instead contain the key id and key wrapped by an
+//   external KMS, in which case they are unwrapped through the KMS when they are read.
 // - The store-FS is used only for storing the key file for the generated keys. It is used by
 //   the DataKeyManager. These keys are rotated periodically in a simple manner -- a new
-//   active key is generated for future file writes. Existing files are not affected.
+//   active key is generated for future file writes. Existing files are not affected, until
+//   they are rewritten by re-encryption (see reencrypt.go).
 //
 // The data-FS and store-FS both use a common implementation. They consume:
 // - the FS they are wrapping: it is always the base-FS in our case, but it does not matter.
@@ -109,10 +113,18 @@ type encryptedFS struct {
 	vfs.FS
 	fileRegistry  *storage.PebbleFileRegistry
 	streamCreator *FileCipherStreamCreator
+
+	// mu is held exclusively by reEncryptFile while it replaces a file, and
+	// for reading by the operations which look up or modify files by name,
+	// so that they don't observe a file whose registry entry doesn't match
+	// its contents.
+	mu syncutil.RWMutex
 }
 
 // Create implements vfs.FS.Create.
 func (fs *encryptedFS) Create(name string) (vfs.File, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
 	f, err := fs.FS.Create(name)
 	if err != nil {
 		return f, err
@@ -148,6 +160,12 @@ func (fs *encryptedFS) Create(name string) (vfs.File, error) {
 
 // Link implements vfs.FS.Link.
 func (fs *encryptedFS) Link(oldname, newname string) error {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.linkLocked(oldname, newname)
+}
+
+func (fs *encryptedFS) linkLocked(oldname, newname string) error {
 	if err := fs.FS.Link(oldname, newname); err != nil {
 		return err
 	}
@@ -156,6 +174,8 @@ func (fs *encryptedFS) Link(oldname, newname string) error {
 
 // Open implements vfs.FS.Open.
 func (fs *encryptedFS) Open(name string, opts ...vfs.OpenOption) (vfs.File, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
 	f, err := fs.FS.Open(name, opts...)
 	if err != nil {
 		return f, err
@@ -184,6 +204,12 @@ func (fs *encryptedFS) Open(name string, opts ...vfs.OpenOption) (vfs.File, erro
 
 // Remove implements vfs.FS.Remove.
 func (fs *encryptedFS) Remove(name string) error {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.removeLocked(name)
+}
+
+func (fs *encryptedFS) removeLocked(name string) error {
 	if err := fs.FS.Remove(name); err != nil {
 		return err
 	}
@@ -206,6 +232,8 @@ func (fs *encryptedFS) Remove(name string) error {
 // will contain a dangling entry for the old path. The dangling entry
 // will be elided when the file registry is loaded again.
 func (fs *encryptedFS) Rename(oldname, newname string) error {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
 	// First copy the metadata from the old name to the new name. If a
 	// file exists at newname, this copy action will make the file at
 	// newname unlegible, because the encryption-at-rest metadata will
@@ -308,14 +336,24 @@ func newEncryptedEnv(
 	if err := protoutil.Unmarshal(optionBytes, options); err != nil {
 		return nil, err
 	}
-	if options.KeySource != baseccl.EncryptionKeySource_KeyFiles {
+	storeKeyManager := &StoreKeyManager{fs: fs}
+	switch options.KeySource {
+	case baseccl.EncryptionKeySource_KeyFiles:
+		storeKeyManager.activeKeyFilename = options.KeyFiles.CurrentKey
+		storeKeyManager.oldKeyFilename = options.KeyFiles.OldKey
+	case baseccl.EncryptionKeySource_KMS:
+		kms, err := cloud.KMSFromURI(context.TODO(), options.KmsKeys.Uri, makeStoreKMSEnv())
+		if err != nil {
+			return nil, errors.Wrap(err, "opening KMS for store keys")
+		}
+		// The KMS is only needed to unwrap the store keys when they're loaded.
+		defer kms.Close()
+		storeKeyManager.activeKeyFilename = options.KmsKeys.CurrentKey
+		storeKeyManager.oldKeyFilename = options.KmsKeys.OldKey
+		storeKeyManager.kms = kms
+	default:
 		return nil, fmt.Errorf("unknown encryption key source: %d", options.KeySource)
 	}
-	storeKeyManager := &StoreKeyManager{
-		fs:                fs,
-		activeKeyFilename: options.KeyFiles.CurrentKey,
-		oldKeyFilename:    options.KeyFiles.OldKey,
-	}
 	if err := storeKeyManager.Load(context.TODO()); err != nil {
 		return nil, err
 	}
@@ -353,6 +391,9 @@ func newEncryptedEnv(
 		if err := dataKeyManager.SetActiveStoreKeyInfo(context.TODO(), key.Info); err != nil {
 			return nil, err
 		}
+		if err := dataFS.recoverReEncryption(dbDir); err != nil {
+			return nil, errors.Wrap(err, "recovering interrupted re-encryption")
+		}
 	}
 
 	return &storage.EncryptionEnv{
@@ -362,6 +403,10 @@ func newEncryptedEnv(
 			storeKM: storeKeyManager,
 			dataKM:  dataKeyManager,
 		},
+		ReEncrypter: &reEncrypter{
+			fs:     dataFS,
+			dataKM: dataKeyManager,
+		},
 	}, nil
 }
 
@@ -421,6 +421,155 @@ func TestPebbleEncryption2(t *testing.T) {
 	addKeyAndValidate("d", "d", "plain", "16v2.key")
 }
 
+func TestPebbleReEncryption(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	writeToFile(t, memFS, "16v1.key", []byte("111111111111111111111111111111111234567890123456"))
+	writeToFile(t, memFS, "16v2.key", []byte("111111111111111111111111111111198765432198765432"))
+
+	open := func(encKeyFile, oldEncKeyFile string) *storage.Pebble {
+		encOptions := baseccl.EncryptionOptions{
+			KeySource: baseccl.EncryptionKeySource_KeyFiles,
+			KeyFiles: &baseccl.EncryptionKeyFiles{
+				CurrentKey: encKeyFile,
+				OldKey:     oldEncKeyFile,
+			},
+			DataKeyRotationPeriod: 1000,
+		}
+		encOptionsBytes, err := protoutil.Marshal(&encOptions)
+		require.NoError(t, err)
+		opts := storage.DefaultPebbleOptions()
+		opts.FS = memFS
+		opts.Cache = pebble.NewCache(1 << 20)
+		defer opts.Cache.Unref()
+		db, err := storage.NewPebble(
+			context.Background(),
+			storage.PebbleConfig{
+				StorageConfig: base.StorageConfig{
+					Settings:          cluster.MakeTestingClusterSettings(),
+					MaxSize:           512 << 20,
+					UseFileRegistry:   true,
+					EncryptionOptions: encOptionsBytes,
+				},
+				Opts: opts,
+			})
+		require.NoError(t, err)
+		return db
+	}
+	reEncrypt := func(db *storage.Pebble) storage.ReEncryptionProgress {
+		var last storage.ReEncryptionProgress
+		require.NoError(t, db.ReEncrypt(context.Background(), func(p storage.ReEncryptionProgress) {
+			require.LessOrEqual(t, last.RewrittenFiles, p.RewrittenFiles)
+			last = p
+		}))
+		require.Equal(t, last.TotalFiles, last.RewrittenFiles)
+		require.Equal(t, last.TotalBytes, last.RewrittenBytes)
+		return last
+	}
+
+	db := open("16v1.key", "plain")
+	require.NoError(t, db.PutUnversioned(roachpb.Key("a"), []byte("a")))
+	require.NoError(t, db.Flush())
+	// None of the files are encrypted under a retired key.
+	require.Zero(t, reEncrypt(db).TotalFiles)
+	db.Close()
+
+	// Rotating the store key retires the data keys of the files written so
+	// far, which are rewritten under the active data key.
+	db = open("16v2.key", "16v1.key")
+	require.NoError(t, db.PutUnversioned(roachpb.Key("b"), []byte("b")))
+	require.NotZero(t, reEncrypt(db).TotalFiles)
+	require.Zero(t, reEncrypt(db).TotalFiles)
+	require.Equal(t, []byte("a"), storageutils.MVCCGetRaw(t, db, storageutils.PointKey("a", 0)))
+	require.Equal(t, []byte("b"), storageutils.MVCCGetRaw(t, db, storageutils.PointKey("b", 0)))
+	r, err := db.GetEncryptionRegistries()
+	require.NoError(t, err)
+	var keyRegistry enginepbccl.DataKeysRegistry
+	require.NoError(t, protoutil.Unmarshal(r.KeyRegistry, &keyRegistry))
+	var fileRegistry enginepb.FileRegistry
+	require.NoError(t, protoutil.Unmarshal(r.FileRegistry, &fileRegistry))
+	for name, entry := range fileRegistry.Files {
+		require.False(t, isReEncryptFile(name), name)
+		if entry.EnvType != enginepb.EnvType_Data {
+			continue
+		}
+		var settings enginepbccl.EncryptionSettings
+		require.NoError(t, protoutil.Unmarshal(entry.EncryptionSettings, &settings))
+		require.Equal(t, keyRegistry.ActiveStoreKeyId, keyRegistry.DataKeys[settings.KeyId].Info.ParentKeyId, name)
+	}
+	db.Close()
+
+	db = open("16v2.key", "plain")
+	require.Equal(t, []byte("a"), storageutils.MVCCGetRaw(t, db, storageutils.PointKey("a", 0)))
+	db.Close()
+}
+
+// TestReEncryptRecovery verifies that the replacement of a file by
+// re-encryption is reverted or completed after a crash.
+func TestReEncryptRecovery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	require.NoError(t, memFS.MkdirAll("/foo", os.ModeDir))
+	writeToFile(t, memFS, "keyfile", []byte("111111111111111111111111111111111234567890123456"))
+	fileRegistry := &storage.PebbleFileRegistry{FS: memFS, DBDir: "/foo"}
+	require.NoError(t, fileRegistry.Load(context.Background()))
+	keyManager := &StoreKeyManager{fs: memFS, activeKeyFilename: "keyfile", oldKeyFilename: "plain"}
+	require.NoError(t, keyManager.Load(context.Background()))
+	fs := &encryptedFS{
+		FS:            memFS,
+		fileRegistry:  fileRegistry,
+		streamCreator: &FileCipherStreamCreator{keyManager: keyManager, envType: enginepb.EnvType_Store},
+	}
+
+	write := func(name, contents string) {
+		f, err := fs.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(contents))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+	read := func(name string) string {
+		f, err := fs.Open(name)
+		require.NoError(t, err)
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		require.NoError(t, err)
+		return string(b)
+	}
+	// crash leaves the state of reEncryptFile after the registry entry of name
+	// is replaced, optionally renaming the temporary file.
+	crash := func(name string, rename bool) {
+		write(name+reEncryptTmpSuffix, "contents")
+		require.NoError(t, fs.Link(name, name+reEncryptPrevSuffix))
+		require.NoError(t, fileRegistry.MaybeCopyEntry(name+reEncryptTmpSuffix, name))
+		if rename {
+			require.NoError(t, fs.FS.Rename(name+reEncryptTmpSuffix, name))
+		}
+	}
+
+	write("/foo/a", "contents")
+	crash("/foo/a", false /* rename */)
+	write("/foo/b", "contents")
+	crash("/foo/b", true /* rename */)
+	write("/foo/c", "contents")
+	write("/foo/c"+reEncryptTmpSuffix, "contents")
+
+	require.NoError(t, fs.recoverReEncryption("/foo"))
+	for _, name := range []string{"/foo/a", "/foo/b", "/foo/c"} {
+		require.Equal(t, "contents", read(name))
+	}
+	names, err := memFS.List("/foo")
+	require.NoError(t, err)
+	for _, name := range names {
+		require.False(t, isReEncryptFile(name), name)
+	}
+	for name := range fileRegistry.List() {
+		require.False(t, isReEncryptFile(name), name)
+	}
+}
+
 func TestCanRegistryElide(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
@@ -77,6 +78,9 @@ type StoreKeyManager struct {
 	fs                vfs.FS
 	activeKeyFilename string
 	oldKeyFilename    string
+	// kms, if set, is used to unwrap the contents of the key files, which
+	// then have the same format as plain key files.
+	kms cloud.KMS
 
 	// Implementation. Both are not nil after a successful call to Load().
 	activeKey *enginepbccl.SecretKey
@@ -86,11 +90,11 @@ type StoreKeyManager struct {
 // Load must be called before calling other functions.
 func (m *StoreKeyManager) Load(ctx context.Context) error {
 	var err error
-	m.activeKey, err = loadKeyFromFile(m.fs, m.activeKeyFilename)
+	m.activeKey, err = loadKeyFromFile(ctx, m.fs, m.activeKeyFilename, m.kms)
 	if err != nil {
 		return err
 	}
-	m.oldKey, err = loadKeyFromFile(m.fs, m.oldKeyFilename)
+	m.oldKey, err = loadKeyFromFile(ctx, m.fs, m.oldKeyFilename, m.kms)
 	if err != nil {
 		return err
 	}
@@ -115,7 +119,9 @@ func (m *StoreKeyManager) GetKey(id string) (*enginepbccl.SecretKey, error) {
 	return nil, fmt.Errorf("store key ID %s was not found", id)
 }
 
-func loadKeyFromFile(fs vfs.FS, filename string) (*enginepbccl.SecretKey, error) {
+func loadKeyFromFile(
+	ctx context.Context, fs vfs.FS, filename string, kms cloud.KMS,
+) (*enginepbccl.SecretKey, error) {
 	now := kmTimeNow().Unix()
 	key := &enginepbccl.SecretKey{}
 	key.Info = &enginepbccl.KeyInfo{}
@@ -136,6 +142,11 @@ func loadKeyFromFile(fs vfs.FS, filename string) (*enginepbccl.SecretKey, error)
 	if err != nil {
 		return nil, err
 	}
+	if kms != nil {
+		if b, err = kms.Decrypt(ctx, b); err != nil {
+			return nil, errors.Wrapf(err, "unwrapping store key %s with KMS key %s", filename, kms.MasterKeyID())
+		}
+	}
 	// keyIDLength bytes for the ID, followed by the key.
 	keyLength := len(b) - keyIDLength
 	switch keyLength {
@@ -334,6 +345,21 @@ func (m *DataKeyManager) SetActiveStoreKeyInfo(
 	return nil
 }
 
+// retiredKeyIDs returns the IDs of the data keys which were generated under a
+// store key other than the active one. Once no file is encrypted under these
+// keys, the store keys they descend from are no longer needed.
+func (m *DataKeyManager) retiredKeyIDs() map[string]struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	retired := make(map[string]struct{})
+	for id, key := range m.mu.keyRegistry.DataKeys {
+		if key.Info.ParentKeyId != m.mu.keyRegistry.ActiveStoreKeyId {
+			retired[id] = struct{}{}
+		}
+	}
+	return retired
+}
+
 func (m *DataKeyManager) getScrubbedRegistry() *enginepbccl.DataKeysRegistry {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
 	"github.com/cockroachdb/cockroach/pkg/testutils/datapathutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -118,6 +119,47 @@ func TestStoreKeyManagerLoadErrors(t *testing.T) {
 	}
 }
 
+// xorKMS is a cloud.KMS which wraps data by XORing it with a single byte.
+type xorKMS byte
+
+var _ cloud.KMS = xorKMS(0)
+
+func (k xorKMS) MasterKeyID() string { return "xor" }
+
+func (k xorKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	res := make([]byte, len(data))
+	for i := range data {
+		res[i] = data[i] ^ byte(k)
+	}
+	return res, nil
+}
+
+func (k xorKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	return k.Encrypt(ctx, data)
+}
+
+func (k xorKMS) Close() error { return nil }
+
+func TestStoreKeyManagerKMS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	kms := xorKMS(0x5a)
+	wrapped, err := kms.Encrypt(context.Background(), []byte(keyFile128))
+	require.NoError(t, err)
+	writeToFile(t, memFS, "16.key.wrapped", wrapped)
+
+	skm := &StoreKeyManager{
+		fs: memFS, activeKeyFilename: "16.key.wrapped", oldKeyFilename: "plain", kms: kms,
+	}
+	require.NoError(t, skm.Load(context.Background()))
+	key, err := skm.ActiveKey(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, enginepbccl.EncryptionType_AES128_CTR, key.Info.EncryptionType)
+	require.Equal(t, keyID128, key.Info.KeyId)
+	require.Equal(t, key128, string(key.Key))
+}
+
 func TestStoreKeyManager(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
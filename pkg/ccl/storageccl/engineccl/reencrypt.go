@@ -0,0 +1,275 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/cockroachdb/pebble/vfs"
+)
+
+// Rotating the store key only changes the key under which new files are
+// encrypted: files written earlier remain encrypted under data keys generated
+// under the previous store keys. Re-encryption retires those store keys by
+// rewriting each such file under the active data key. The store key can only
+// change when the store is opened, so the files encrypted under a retired key
+// were all written by a previous process and are no longer written to.
+//
+// A file is rewritten by copying its contents to a temporary file, which is
+// encrypted under the active data key, and renaming the temporary file over
+// the original one. The rename and the update of the file's registry entry
+// can't be done atomically, so a hard link to the original file preserves its
+// contents and registry entry until the temporary file has durably replaced
+// it. If the process crashes in between, recoverReEncryption uses the
+// presence of the temporary file to determine whether the original entry must
+// be restored.
+
+const (
+	// reEncryptTmpSuffix is appended to the name of a file to form the name of
+	// the temporary file holding its rewritten contents.
+	reEncryptTmpSuffix = ".reencrypt-tmp"
+	// reEncryptPrevSuffix is appended to the name of a file to form the name of
+	// the hard link preserving its previous contents.
+	reEncryptPrevSuffix = ".reencrypt-prev"
+)
+
+// reEncrypter implements storage.EncryptionReEncrypter for the data-FS.
+type reEncrypter struct {
+	fs     *encryptedFS
+	dataKM *DataKeyManager
+}
+
+var _ storage.EncryptionReEncrypter = &reEncrypter{}
+
+// ReEncrypt implements storage.EncryptionReEncrypter.
+func (r *reEncrypter) ReEncrypt(
+	ctx context.Context, progress func(storage.ReEncryptionProgress),
+) error {
+	type file struct {
+		path string
+		size uint64
+	}
+	var files []file
+	var p storage.ReEncryptionProgress
+	retired := r.dataKM.retiredKeyIDs()
+	for name, entry := range r.fs.fileRegistry.List() {
+		if entry.EnvType != enginepb.EnvType_Data || isReEncryptFile(name) {
+			continue
+		}
+		var settings enginepbccl.EncryptionSettings
+		if err := protoutil.Unmarshal(entry.EncryptionSettings, &settings); err != nil {
+			return err
+		}
+		if _, ok := retired[settings.KeyId]; !ok {
+			continue
+		}
+		path := r.fs.registryPath(name)
+		info, err := r.fs.FS.Stat(path)
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				// The file was removed since the registry was listed.
+				continue
+			}
+			return err
+		}
+		files = append(files, file{path: path, size: uint64(info.Size())})
+		p.TotalFiles++
+		p.TotalBytes += uint64(info.Size())
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	log.Infof(ctx, "re-encrypting %d files (%s) under the active data key",
+		p.TotalFiles, humanizeutil.IBytes(int64(p.TotalBytes)))
+	progress(p)
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := r.fs.reEncryptFile(f.path); err != nil {
+			return err
+		}
+		p.RewrittenFiles++
+		p.RewrittenBytes += f.size
+		progress(p)
+	}
+	log.Infof(ctx, "re-encrypted %d files (%s)",
+		p.RewrittenFiles, humanizeutil.IBytes(int64(p.RewrittenBytes)))
+	return nil
+}
+
+// reEncryptFile rewrites the file with the given name, which must no longer be
+// written to, under the active key. Readers which opened the file before it
+// was replaced continue to read its previous contents. It returns false if the
+// file was removed before it could be replaced.
+func (fs *encryptedFS) reEncryptFile(name string) (bool, error) {
+	tmpName := name + reEncryptTmpSuffix
+	if err := fs.copyFile(name, tmpName); err != nil {
+		if _, statErr := fs.FS.Stat(tmpName); statErr == nil {
+			_ = fs.Remove(tmpName)
+		}
+		if oserror.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, err := fs.FS.Stat(name); err != nil {
+		if oserror.IsNotExist(err) {
+			// The file was removed while it was being copied.
+			return false, fs.removeLocked(tmpName)
+		}
+		return false, err
+	}
+	dir := fs.PathDir(name)
+	prevName := name + reEncryptPrevSuffix
+	if err := fs.linkLocked(name, prevName); err != nil {
+		return false, err
+	}
+	if err := syncDir(fs.FS, dir); err != nil {
+		return false, err
+	}
+	// From here until the rename is durable, the file's registry entry
+	// doesn't match its contents. Readers are excluded by fs.mu, and a crash
+	// is recovered from by recoverReEncryption.
+	if err := fs.fileRegistry.MaybeCopyEntry(tmpName, name); err != nil {
+		return false, err
+	}
+	if err := fs.FS.Rename(tmpName, name); err != nil {
+		return false, err
+	}
+	if err := syncDir(fs.FS, dir); err != nil {
+		return false, err
+	}
+	if err := fs.fileRegistry.MaybeDeleteEntry(tmpName); err != nil {
+		return false, err
+	}
+	return true, fs.removeLocked(prevName)
+}
+
+// copyFile copies the contents of src to a new file dst, encrypted under the
+// active key.
+func (fs *encryptedFS) copyFile(src, dst string) error {
+	in, err := fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := fs.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// recoverReEncryption completes, or reverts, the replacement of the files
+// which were being rewritten when the process crashed. It must be called
+// before the FS is used.
+func (fs *encryptedFS) recoverReEncryption(dbDir string) error {
+	// The hard links preserving the previous contents of files always have a
+	// registry entry, since only encrypted files are rewritten, which locates
+	// the directories to recover. A temporary file outside of dbDir which has
+	// no registry entry, because the active key is plaintext, and no hard link
+	// beside it is not found, but is also never read.
+	dirs := map[string]struct{}{dbDir: {}}
+	for name := range fs.fileRegistry.List() {
+		if isReEncryptFile(name) {
+			dirs[fs.PathDir(fs.registryPath(name))] = struct{}{}
+		}
+	}
+	for dir := range dirs {
+		names, err := fs.FS.List(dir)
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, name := range names {
+			if !strings.HasSuffix(name, reEncryptPrevSuffix) {
+				continue
+			}
+			prevName := fs.PathJoin(dir, name)
+			origName := strings.TrimSuffix(prevName, reEncryptPrevSuffix)
+			if _, err := fs.FS.Stat(origName + reEncryptTmpSuffix); err == nil {
+				// The temporary file wasn't renamed, so the original file still
+				// has its previous contents.
+				if err := fs.fileRegistry.MaybeCopyEntry(prevName, origName); err != nil {
+					return err
+				}
+			} else if oserror.IsNotExist(err) {
+				// The temporary file was renamed, but its registry entry may not
+				// have been removed.
+				if err := fs.fileRegistry.MaybeDeleteEntry(origName + reEncryptTmpSuffix); err != nil {
+					return err
+				}
+			} else {
+				return err
+			}
+			if err := fs.Remove(prevName); err != nil {
+				return err
+			}
+		}
+		for _, name := range names {
+			if !strings.HasSuffix(name, reEncryptTmpSuffix) {
+				continue
+			}
+			if err := fs.Remove(fs.PathJoin(dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isReEncryptFile(name string) bool {
+	return strings.HasSuffix(name, reEncryptTmpSuffix) || strings.HasSuffix(name, reEncryptPrevSuffix)
+}
+
+// registryPath returns the path of the file with the given name in the file
+// registry, which is relative to the registry's directory unless the file is
+// outside of it.
+func (fs *encryptedFS) registryPath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return fs.PathJoin(fs.fileRegistry.DBDir, name)
+}
+
+func syncDir(fs vfs.FS, dir string) error {
+	d, err := fs.OpenDir(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		_ = d.Close()
+		return err
+	}
+	return d.Close()
+}
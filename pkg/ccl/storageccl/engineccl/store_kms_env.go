@@ -0,0 +1,54 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+)
+
+// storeKMSEnv is the environment of the KMS which wraps the store keys. The
+// store keys are unwrapped when the store is opened, before the node has
+// joined the cluster, so the KMS is configured with default cluster settings
+// and without access to SQL.
+type storeKMSEnv struct {
+	settings *cluster.Settings
+	conf     base.ExternalIODirConfig
+}
+
+var _ cloud.KMSEnv = &storeKMSEnv{}
+
+func makeStoreKMSEnv() *storeKMSEnv {
+	return &storeKMSEnv{settings: cluster.MakeClusterSettings()}
+}
+
+// ClusterSettings implements the cloud.KMSEnv interface.
+func (e *storeKMSEnv) ClusterSettings() *cluster.Settings {
+	return e.settings
+}
+
+// KMSConfig implements the cloud.KMSEnv interface.
+func (e *storeKMSEnv) KMSConfig() *base.ExternalIODirConfig {
+	return &e.conf
+}
+
+// DBHandle implements the cloud.KMSEnv interface. External connections, which
+// are resolved through SQL, are rejected when the encryption options are
+// parsed.
+func (e *storeKMSEnv) DBHandle() isql.DB {
+	return nil
+}
+
+// User implements the cloud.KMSEnv interface.
+func (e *storeKMSEnv) User() username.SQLUsername {
+	return username.NodeUserName()
+}
@@ -0,0 +1,216 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package logical
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/repstream/streampb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/exprutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// optCursor is the option that makes the stream replicate the changes made
+	// after the given timestamp instead of starting with an initial scan of
+	// the source table.
+	optCursor = "cursor"
+)
+
+var streamCreationOptions = exprutil.KVOptionValidationMap{
+	optCursor: exprutil.KVStringOptRequireValue,
+}
+
+var createLogicalReplicationStreamHeader = colinfo.ResultColumns{
+	{Name: "job_id", Typ: types.Int},
+}
+
+func createLogicalReplicationStreamTypeCheck(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (matched bool, _ colinfo.ResultColumns, _ error) {
+	createStmt, ok := stmt.(*tree.CreateLogicalReplicationStream)
+	if !ok {
+		return false, nil, nil
+	}
+	if err := exprutil.TypeCheck(ctx, "LOGICAL REPLICATION STREAM", p.SemaCtx(),
+		exprutil.Strings{createStmt.PGURL},
+		exprutil.KVOptions{KVOptions: createStmt.Options, Validation: streamCreationOptions},
+	); err != nil {
+		return false, nil, err
+	}
+	return true, createLogicalReplicationStreamHeader, nil
+}
+
+func createLogicalReplicationStreamPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	createStmt, ok := stmt.(*tree.CreateLogicalReplicationStream)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if !streamingccl.CrossClusterReplicationEnabled.Get(&p.ExecCfg().Settings.SV) {
+		return nil, nil, nil, false, errors.WithTelemetry(
+			pgerror.WithCandidateCode(
+				errors.WithHint(
+					errors.Newf("cross cluster replication is disabled"),
+					"You can enable cross cluster replication by running `SET CLUSTER SETTING cross_cluster_replication.enabled = true`.",
+				),
+				pgcode.ExperimentalFeature,
+			),
+			"cross_cluster_replication.enabled",
+		)
+	}
+
+	exprEval := p.ExprEvaluator("LOGICAL REPLICATION STREAM")
+	from, err := exprEval.String(ctx, createStmt.PGURL)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	options, err := exprEval.KVOptions(ctx, createStmt.Options, streamCreationOptions)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	var cursor hlc.Timestamp
+	if c, ok := options[optCursor]; ok {
+		cursor, err = hlc.ParseHLC(c)
+		if err != nil {
+			return nil, nil, nil, false, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+				"invalid %s", optCursor)
+		}
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().NodeInfo.LogicalClusterID(),
+			"CREATE LOGICAL REPLICATION STREAM",
+		); err != nil {
+			return err
+		}
+
+		_, dst, err := p.ResolveMutableTableDescriptor(ctx, &createStmt.Into, true, tree.ResolveRequireTableDesc)
+		if err != nil {
+			return err
+		}
+		for _, kind := range []privilege.Kind{privilege.INSERT, privilege.UPDATE, privilege.DELETE} {
+			if err := p.CheckPrivilege(ctx, dst, kind); err != nil {
+				return err
+			}
+		}
+		// Check the destination table before creating the stream, so that a
+		// producer job is not started for nothing.
+		if err := checkOriginTimestampColumn(dst); err != nil {
+			return err
+		}
+
+		streamAddress := streamingccl.StreamAddress(from)
+		streamURL, err := streamAddress.URL()
+		if err != nil {
+			return err
+		}
+		streamAddress = streamingccl.StreamAddress(streamURL.String())
+
+		client, err := streamclient.NewStreamClient(ctx, streamAddress, p.ExecCfg().InternalDB)
+		if err != nil {
+			return err
+		}
+		defer closeAndLog(ctx, client)
+
+		spec, err := client.CreateForTables(ctx, streampb.ReplicationProducerRequest{
+			TableNames: []string{createStmt.From.String()},
+		})
+		if err != nil {
+			return err
+		}
+		if len(spec.TableDescriptors) != 1 {
+			return errors.AssertionFailedf("expected 1 table descriptor, got %d", len(spec.TableDescriptors))
+		}
+		src := tabledesc.NewBuilder(&spec.TableDescriptors[0]).BuildImmutableTable()
+		if _, _, err := replicatedColumns(src, dst); err != nil {
+			if completeErr := client.Complete(ctx, spec.StreamID, false /* successfulIngestion */); completeErr != nil {
+				log.Warningf(ctx, "could not complete producer job %d: %v", spec.StreamID, completeErr)
+			}
+			return err
+		}
+
+		jobDescription, err := logicalReplicationJobDescription(p, from, createStmt)
+		if err != nil {
+			return err
+		}
+		jr := jobs.Record{
+			JobID:       p.ExecCfg().JobRegistry.MakeJobID(),
+			Description: jobDescription,
+			Username:    p.User(),
+			Details: jobspb.LogicalReplicationDetails{
+				SourceClusterConnStr: string(streamAddress),
+				StreamID:             uint64(spec.StreamID),
+				TableID:              dst.GetID(),
+				SourceTable:          spec.TableDescriptors[0],
+				SourceTenantID:       spec.SourceTenantID,
+				ReplicationStartTime: spec.ReplicationStartTime,
+			},
+			// A cursor makes the job start as if it had already replicated the
+			// changes made up to it.
+			Progress: jobspb.LogicalReplicationProgress{
+				ReplicatedTime: cursor,
+			},
+		}
+		if _, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+			ctx, jr, jr.JobID, p.InternalSQLTxn(),
+		); err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jr.JobID))}
+		return nil
+	}
+
+	return fn, createLogicalReplicationStreamHeader, nil, false, nil
+}
+
+func logicalReplicationJobDescription(
+	p sql.PlanHookState, sourceAddr string, createStmt *tree.CreateLogicalReplicationStream,
+) (string, error) {
+	redactedSourceAddr, err := cloud.SanitizeExternalStorageURI(sourceAddr, streamclient.RedactableURLParameters)
+	if err != nil {
+		return "", err
+	}
+	redactedStmt := &tree.CreateLogicalReplicationStream{
+		From:    createStmt.From,
+		PGURL:   tree.NewDString(redactedSourceAddr),
+		Into:    createStmt.Into,
+		Options: createStmt.Options,
+	}
+	ann := p.ExtendedEvalContext().Annotations
+	return tree.AsStringWithFQNames(redactedStmt, ann), nil
+}
+
+func init() {
+	sql.AddPlanHook("create logical replication stream",
+		createLogicalReplicationStreamPlanHook, createLogicalReplicationStreamTypeCheck)
+}
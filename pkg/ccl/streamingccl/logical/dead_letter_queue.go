@@ -0,0 +1,132 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package logical
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/replicationutils"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// deadLetterSchemaName is the name of the schema, in the database of the
+// destination table, that dead letter tables are created in.
+const deadLetterSchemaName = replicationutils.ReplicationSchemaName
+
+const deadLetterTableSchema = `(
+	id               INT8 NOT NULL DEFAULT unique_rowid(),
+	ingestion_job_id INT8 NOT NULL,
+	table_id         INT8 NOT NULL,
+	origin_timestamp DECIMAL NOT NULL,
+	mutation_type    STRING NOT NULL,
+	key_value_bytes  BYTES NOT NULL,
+	incoming_row     JSONB,
+	reason           STRING NOT NULL,
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (ingestion_job_id, id)
+)`
+
+// deadLetterTableName returns the name of the dead letter table of the
+// destination table with the given ID. The table is shared by all the
+// streams into the destination table.
+func deadLetterTableName(dbName string, tableID descpb.ID) string {
+	return fmt.Sprintf("%s.%s.dlq_%d", tree.NameString(dbName), deadLetterSchemaName, tableID)
+}
+
+// createDeadLetterTable creates the dead letter table of the destination
+// table, if it does not exist yet, and returns its ID.
+func createDeadLetterTable(
+	ctx context.Context,
+	ie isql.Executor,
+	override sessiondata.InternalExecutorOverride,
+	dbName string,
+	tableID descpb.ID,
+) (descpb.ID, error) {
+	tableName := deadLetterTableName(dbName, tableID)
+	if _, err := ie.ExecEx(ctx, "create-dlq-schema", nil /* txn */, override,
+		fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s.%s", tree.NameString(dbName), deadLetterSchemaName),
+	); err != nil {
+		return 0, errors.Wrap(err, "creating dead letter schema")
+	}
+	if _, err := ie.ExecEx(ctx, "create-dlq-table", nil /* txn */, override,
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s %s", tableName, deadLetterTableSchema),
+	); err != nil {
+		return 0, errors.Wrap(err, "creating dead letter table")
+	}
+	row, err := ie.QueryRowEx(ctx, "resolve-dlq-table", nil /* txn */, override,
+		"SELECT $1::REGCLASS::INT8", tableName)
+	if err != nil {
+		return 0, err
+	}
+	if row == nil {
+		return 0, errors.AssertionFailedf("dead letter table %s not found", tableName)
+	}
+	return descpb.ID(tree.MustBeDInt(row[0])), nil
+}
+
+// deadLetterQueue records the row changes that a stream could not apply to
+// the destination table, so that they may be inspected and applied manually.
+type deadLetterQueue struct {
+	ie       isql.Executor
+	override sessiondata.InternalExecutorOverride
+	jobID    jobspb.JobID
+	tableID  descpb.ID
+	// dlqTableID is the ID of the dead letter table.
+	dlqTableID descpb.ID
+}
+
+// Log records the row change that failed to be applied with the given error.
+func (q *deadLetterQueue) Log(
+	ctx context.Context,
+	kv roachpb.KeyValue,
+	cols []replicatedColumn,
+	datums tree.Datums,
+	deleted bool,
+	originTimestamp *tree.DDecimal,
+	applyErr error,
+) error {
+	kvBytes, err := protoutil.Marshal(&kv)
+	if err != nil {
+		return err
+	}
+	mutationType := "upsert"
+	incomingRow := tree.DNull
+	if deleted {
+		mutationType = "delete"
+	} else {
+		b := json.NewObjectBuilder(len(cols))
+		for i, col := range cols {
+			j, err := tree.AsJSON(datums[i], sessiondatapb.DataConversionConfig{}, time.UTC)
+			if err != nil {
+				return err
+			}
+			b.Add(col.name, j)
+		}
+		incomingRow = tree.NewDJSON(b.Build())
+	}
+
+	_, err = q.ie.ExecEx(ctx, "log-dlq-row", nil /* txn */, q.override,
+		fmt.Sprintf(`INSERT INTO [%d AS dlq] (
+	ingestion_job_id, table_id, origin_timestamp, mutation_type, key_value_bytes, incoming_row, reason
+) VALUES ($1, $2, $3, $4, $5, $6, $7)`, q.dlqTableID),
+		q.jobID, q.tableID, originTimestamp, mutationType, kvBytes, incomingRow, applyErr.Error(),
+	)
+	return errors.Wrap(err, "writing to dead letter table")
+}
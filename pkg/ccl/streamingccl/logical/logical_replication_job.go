@@ -0,0 +1,501 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package logical
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/replicationutils"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/repstream/streampb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var applyBatchSize = settings.RegisterIntSetting(
+	settings.ApplicationLevel,
+	"logical_replication.consumer.batch_size",
+	"the maximum number of row changes that a logical replication job applies in a single transaction",
+	64,
+	settings.PositiveInt,
+)
+
+// logicalReplicationResumer implements jobs.Resumer for a job that applies
+// the row changes of a table in another cluster to a table in this cluster.
+//
+// Unlike physical replication, the job runs entirely on the node that
+// resumed it: it subscribes to every partition of the stream and applies the
+// rows it receives as SQL writes, so that the destination table may be
+// written to, and replicated elsewhere, while the stream runs.
+type logicalReplicationResumer struct {
+	job *jobs.Job
+
+	mu struct {
+		syncutil.Mutex
+		// frontier tracks the time up to which the changes of each span of the
+		// source table have been applied.
+		frontier *span.Frontier
+	}
+}
+
+var _ jobs.Resumer = &logicalReplicationResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *logicalReplicationResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	jobExecCtx := execCtx.(sql.JobExecContext)
+	if err := r.initFrontier(); err != nil {
+		return err
+	}
+
+	ro := retry.Options{
+		InitialBackoff: time.Second,
+		Multiplier:     2,
+		MaxBackoff:     time.Minute,
+		MaxRetries:     10,
+	}
+	var err error
+	lastReplicatedTime := r.replicatedTime()
+	for rt := retry.Start(ro); rt.Next(); {
+		err = r.ingest(ctx, jobExecCtx)
+		if err == nil || jobs.IsPermanentJobError(err) || errors.Is(err, context.Canceled) {
+			break
+		}
+		log.Warningf(ctx, "logical replication job %d encountered retryable error: %v", r.job.ID(), err)
+		if replicatedTime := r.replicatedTime(); lastReplicatedTime.Less(replicatedTime) {
+			rt.Reset()
+			lastReplicatedTime = replicatedTime
+		}
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		// Pause rather than fail the job so that the changes applied so far are
+		// not lost: a resumed job continues from its last checkpoint, whereas a
+		// new stream would have to scan the source table again.
+		return jobs.MarkPauseRequestError(err)
+	}
+	return err
+}
+
+// initFrontier initializes the frontier of the job from its persisted
+// progress.
+func (r *logicalReplicationResumer) initFrontier() error {
+	details := r.job.Details().(jobspb.LogicalReplicationDetails)
+	progress := r.job.Progress().GetLogicalReplication()
+	srcCodec := keys.MakeSQLCodec(details.SourceTenantID)
+	src := tabledesc.NewBuilder(&details.SourceTable).BuildImmutableTable()
+
+	var replicatedTime hlc.Timestamp
+	var resolvedSpans []jobspb.ResolvedSpan
+	if progress != nil {
+		replicatedTime = progress.ReplicatedTime
+		resolvedSpans = progress.Checkpoint.ResolvedSpans
+	}
+	frontier, err := span.MakeFrontierAt(replicatedTime, src.PrimaryIndexSpan(srcCodec))
+	if err != nil {
+		return err
+	}
+	for _, resolvedSpan := range resolvedSpans {
+		if _, err := frontier.Forward(resolvedSpan.Span, resolvedSpan.Timestamp); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.frontier = frontier
+	return nil
+}
+
+func (r *logicalReplicationResumer) replicatedTime() hlc.Timestamp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mu.frontier.Frontier()
+}
+
+// ingest subscribes to every partition of the stream and applies the rows it
+// receives until the stream or the job is stopped.
+func (r *logicalReplicationResumer) ingest(ctx context.Context, execCtx sql.JobExecContext) error {
+	execCfg := execCtx.ExecCfg()
+	details := r.job.Details().(jobspb.LogicalReplicationDetails)
+	streamID := streampb.StreamID(details.StreamID)
+	srcCodec := keys.MakeSQLCodec(details.SourceTenantID)
+	src := tabledesc.NewBuilder(&details.SourceTable).BuildImmutableTable()
+	user := r.job.Payload().UsernameProto.Decode()
+	override := sessiondata.InternalExecutorOverride{User: user}
+	metrics := execCfg.JobRegistry.MetricsStruct().JobSpecificMetrics[jobspb.TypeLogicalReplication].(*Metrics)
+
+	var dst catalog.TableDescriptor
+	var dbName string
+	if err := execCfg.InternalDB.DescsTxn(ctx, func(ctx context.Context, txn descs.Txn) error {
+		var err error
+		dst, err = txn.Descriptors().ByID(txn.KV()).WithoutNonPublic().Get().Table(ctx, details.TableID)
+		if err != nil {
+			return err
+		}
+		db, err := txn.Descriptors().ByID(txn.KV()).WithoutNonPublic().Get().Database(ctx, dst.GetParentID())
+		if err != nil {
+			return err
+		}
+		dbName = db.GetName()
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	dlq, tombstoneTableID, err := r.createTables(ctx, execCfg.InternalDB, dbName, dst)
+	if err != nil {
+		return err
+	}
+
+	client, err := streamclient.NewStreamClient(ctx,
+		streamingccl.StreamAddress(details.SourceClusterConnStr), execCfg.InternalDB,
+		streamclient.WithStreamID(streamID))
+	if err != nil {
+		return err
+	}
+	defer closeAndLog(ctx, client)
+
+	topology, err := client.Plan(ctx, streamID)
+	if err != nil {
+		return err
+	}
+
+	type partitionSubscription struct {
+		sub streamclient.Subscription
+		rp  *lwwRowProcessor
+	}
+	subs := make([]partitionSubscription, 0, len(topology.Partitions))
+	for _, partition := range topology.Partitions {
+		// The schema of the destination table is validated again whenever the
+		// job resumes, since it may have changed since the job was created.
+		rp, err := newLWWRowProcessor(ctx, srcCodec, src, dst, tombstoneTableID, override, metrics, dlq)
+		if err != nil {
+			return jobs.MarkAsPermanentJobError(err)
+		}
+		partitionClient, err := streamclient.NewStreamClient(ctx,
+			streamingccl.StreamAddress(partition.SrcAddr), execCfg.InternalDB,
+			streamclient.WithStreamID(streamID))
+		if err != nil {
+			return errors.Wrapf(err, "creating client for partition %s", partition.ID)
+		}
+		defer closeAndLog(ctx, partitionClient)
+
+		r.mu.Lock()
+		previousReplicatedTime := frontierForSpans(r.mu.frontier, partition.Spans...)
+		r.mu.Unlock()
+		sub, err := partitionClient.Subscribe(ctx, streamID, partition.SubscriptionToken,
+			details.ReplicationStartTime, previousReplicatedTime)
+		if err != nil {
+			return errors.Wrapf(err, "subscribing to partition %s", partition.ID)
+		}
+		subs = append(subs, partitionSubscription{sub: sub, rp: rp})
+	}
+
+	g := ctxgroup.WithContext(ctx)
+	for i := range subs {
+		ps := subs[i]
+		g.GoCtx(ps.sub.Subscribe)
+		g.GoCtx(func(ctx context.Context) error {
+			return r.consumePartition(ctx, execCfg, ps.sub, ps.rp)
+		})
+	}
+	g.GoCtx(func(ctx context.Context) error {
+		return r.checkpointLoop(ctx, execCfg, client, streamID)
+	})
+	return g.Wait()
+}
+
+// createTables returns the queue that rows which cannot be applied to the
+// destination table are written to, and the ID of the tombstone table of the
+// destination table, creating their tables when the job first runs. Both are
+// created and written to as the node user, since the tombstone table may also
+// be created by the producer of a stream of the destination table.
+func (r *logicalReplicationResumer) createTables(
+	ctx context.Context, db isql.DB, dbName string, dst catalog.TableDescriptor,
+) (_ *deadLetterQueue, tombstoneTableID descpb.ID, _ error) {
+	override := sessiondata.NodeUserSessionDataOverride
+	details := r.job.Details().(jobspb.LogicalReplicationDetails)
+	dlqTableID, tombstoneTableID := details.DeadLetterTableID, details.TombstoneTableID
+	if dlqTableID == 0 || tombstoneTableID == 0 {
+		var err error
+		dlqTableID, err = createDeadLetterTable(ctx, db.Executor(), override, dbName, dst.GetID())
+		if err != nil {
+			return nil, 0, err
+		}
+		tombstoneTableID, err = replicationutils.CreateTombstoneTable(ctx, db.Executor(), nil, /* txn */
+			dbName, dst.GetID())
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := r.job.NoTxn().Update(ctx, func(
+			txn isql.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
+		) error {
+			details := md.Payload.GetLogicalReplicationDetails()
+			details.DeadLetterTableID = dlqTableID
+			details.TombstoneTableID = tombstoneTableID
+			ju.UpdatePayload(md.Payload)
+			return nil
+		}); err != nil {
+			return nil, 0, err
+		}
+	}
+	return &deadLetterQueue{
+		ie:         db.Executor(),
+		override:   override,
+		jobID:      r.job.ID(),
+		tableID:    dst.GetID(),
+		dlqTableID: dlqTableID,
+	}, tombstoneTableID, nil
+}
+
+// consumePartition applies the rows received from a partition of the stream
+// and forwards the frontier of the job as the partition is checkpointed. The
+// rows are applied in batches, each in a single transaction, and the pending
+// batch is applied before the frontier is forwarded, so that the frontier
+// only covers rows that have been applied.
+func (r *logicalReplicationResumer) consumePartition(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	sub streamclient.Subscription,
+	rp *lwwRowProcessor,
+) error {
+	var batch []roachpb.KeyValue
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := rp.ApplyBatch(ctx, execCfg.InternalDB, batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return sub.Err()
+			}
+			switch event.Type() {
+			case streamingccl.KVEvent:
+				batch = append(batch, *event.GetKV())
+				if len(batch) >= int(applyBatchSize.Get(&execCfg.Settings.SV)) {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			case streamingccl.CheckpointEvent:
+				if err := flush(); err != nil {
+					return err
+				}
+				if err := r.forwardFrontier(event.GetResolvedSpans()); err != nil {
+					return err
+				}
+			case streamingccl.SSTableEvent, streamingccl.DeleteRangeEvent:
+				// These events are emitted for bulk writes such as IMPORT INTO or
+				// for dropped indexes, neither of which can be applied row by row.
+				return jobs.MarkAsPermanentJobError(errors.Newf(
+					"logical replication does not support event type %v", event.Type()))
+			case streamingccl.SpanConfigEvent:
+				// Span configs are only replicated for entire tenants.
+			default:
+				return errors.AssertionFailedf("unexpected event type %v", event.Type())
+			}
+		}
+	}
+}
+
+func (r *logicalReplicationResumer) forwardFrontier(resolvedSpans []jobspb.ResolvedSpan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, resolvedSpan := range resolvedSpans {
+		if _, err := r.mu.frontier.Forward(resolvedSpan.Span, resolvedSpan.Timestamp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkpointLoop periodically persists the progress of the job and
+// heartbeats the producer job, which allows it to release the protected
+// timestamp on the source table up to the replicated time.
+func (r *logicalReplicationResumer) checkpointLoop(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	client streamclient.Client,
+	streamID streampb.StreamID,
+) error {
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	for {
+		// Progress is not persisted when checkpointing is disabled, but the
+		// producer job still needs to be heartbeated to keep it alive.
+		freq := streamingccl.JobCheckpointFrequency.Get(&execCfg.Settings.SV)
+		persist := freq > 0
+		if !persist {
+			freq = streamingccl.StreamReplicationConsumerHeartbeatFrequency.Get(&execCfg.Settings.SV)
+		}
+		timer.Reset(freq)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			timer.Read = true
+		}
+
+		replicatedTime, err := r.checkpoint(ctx, persist)
+		if err != nil {
+			return err
+		}
+		status, err := client.Heartbeat(ctx, streamID, replicatedTime)
+		if err != nil {
+			log.Warningf(ctx, "could not heartbeat producer job %d: %v", streamID, err)
+			continue
+		}
+		if status.StreamStatus != streampb.StreamReplicationStatus_STREAM_ACTIVE {
+			return jobs.MarkAsPermanentJobError(errors.Newf(
+				"replication stream %d is not running, status is %s", streamID, status.StreamStatus))
+		}
+	}
+}
+
+// checkpoint returns the replicated time of the job, persisting its frontier
+// if requested.
+func (r *logicalReplicationResumer) checkpoint(
+	ctx context.Context, persist bool,
+) (hlc.Timestamp, error) {
+	var resolvedSpans []jobspb.ResolvedSpan
+	r.mu.Lock()
+	r.mu.frontier.Entries(func(sp roachpb.Span, ts hlc.Timestamp) span.OpResult {
+		resolvedSpans = append(resolvedSpans, jobspb.ResolvedSpan{Span: sp, Timestamp: ts})
+		return span.ContinueMatch
+	})
+	replicatedTime := r.mu.frontier.Frontier()
+	r.mu.Unlock()
+	if !persist {
+		return replicatedTime, nil
+	}
+
+	log.VInfof(ctx, 2, "persisting replicated time of %s", replicatedTime)
+	if err := r.job.NoTxn().Update(ctx, func(
+		txn isql.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
+	) error {
+		if err := md.CheckRunningOrReverting(); err != nil {
+			return err
+		}
+		progress := md.Progress.GetLogicalReplication()
+		progress.Checkpoint.ResolvedSpans = resolvedSpans
+		if progress.ReplicatedTime.Less(replicatedTime) {
+			progress.ReplicatedTime = replicatedTime
+			// The HighWater is for informational purposes only.
+			md.Progress.Progress = &jobspb.Progress_HighWater{
+				HighWater: &replicatedTime,
+			}
+		}
+		ju.UpdateProgress(md.Progress)
+		return nil
+	}); err != nil {
+		return hlc.Timestamp{}, err
+	}
+	return replicatedTime, nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. It completes the
+// producer job on a best effort basis, which releases the protected
+// timestamp on the source table. The dead letter table is kept.
+func (r *logicalReplicationResumer) OnFailOrCancel(
+	ctx context.Context, execCtx interface{}, _ error,
+) error {
+	execCfg := execCtx.(sql.JobExecContext).ExecCfg()
+	details := r.job.Details().(jobspb.LogicalReplicationDetails)
+	streamID := streampb.StreamID(details.StreamID)
+	if err := timeutil.RunWithTimeout(ctx, "complete producer job", 30*time.Second,
+		func(ctx context.Context) error {
+			client, err := streamclient.NewStreamClient(ctx,
+				streamingccl.StreamAddress(details.SourceClusterConnStr), execCfg.InternalDB,
+				streamclient.WithStreamID(streamID))
+			if err != nil {
+				return err
+			}
+			defer closeAndLog(ctx, client)
+			return client.Complete(ctx, streamID, false /* successfulIngestion */)
+		},
+	); err != nil {
+		log.Warningf(ctx, "encountered error when completing the source cluster producer job %d: %s",
+			streamID, err.Error())
+	}
+	return nil
+}
+
+// CollectProfile is part of the jobs.Resumer interface.
+func (r *logicalReplicationResumer) CollectProfile(context.Context, interface{}) error {
+	return nil
+}
+
+// frontierForSpans returns the minimum timestamp of the given spans in the
+// frontier, or an empty timestamp if any part of them has not been resolved.
+func frontierForSpans(f *span.Frontier, spans ...roachpb.Span) hlc.Timestamp {
+	var (
+		minTimestamp hlc.Timestamp
+		sawEmptyTS   bool
+	)
+	for _, spanToCheck := range spans {
+		f.SpanEntries(spanToCheck, func(frontierSpan roachpb.Span, ts hlc.Timestamp) span.OpResult {
+			if ts.IsEmpty() {
+				sawEmptyTS = true
+			}
+			if minTimestamp.IsEmpty() || ts.Less(minTimestamp) {
+				minTimestamp = ts
+			}
+			return span.ContinueMatch
+		})
+	}
+	if sawEmptyTS {
+		return hlc.Timestamp{}
+	}
+	return minTimestamp
+}
+
+func closeAndLog(ctx context.Context, d streamclient.Dialer) {
+	if err := d.Close(ctx); err != nil {
+		log.Warningf(ctx, "error closing stream client: %s", err.Error())
+	}
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeLogicalReplication,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &logicalReplicationResumer{job: job}
+		},
+		jobs.UsesTenantCostControl,
+		jobs.WithJobMetrics(makeMetrics()),
+	)
+}
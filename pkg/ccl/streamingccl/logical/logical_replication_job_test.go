@@ -0,0 +1,125 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package logical_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// TestBidirectionalLogicalReplication replicates two tables of the same
+// cluster into each other, which is enough to exercise the streams in both
+// directions.
+func TestBidirectionalLogicalReplication(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		DefaultTestTenant: base.TestControlsTenantsExplicitly,
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.ExecMultiple(t,
+		`SET CLUSTER SETTING kv.rangefeed.enabled = true`,
+		`SET CLUSTER SETTING cross_cluster_replication.enabled = true`,
+		`SET CLUSTER SETTING physical_replication.producer.min_checkpoint_frequency = '100ms'`,
+		`SET CLUSTER SETTING physical_replication.consumer.job_checkpoint_frequency = '100ms'`,
+		`CREATE DATABASE a`,
+		`CREATE DATABASE b`,
+		`CREATE TABLE a.tab (
+			pk INT PRIMARY KEY,
+			payload STRING,
+			crdb_replication_origin_timestamp DECIMAL NULL ON UPDATE NULL
+		)`,
+		// The destination of the stream from a does not accept some of its rows,
+		// which end up in the dead letter table.
+		`CREATE TABLE b.tab (
+			pk INT PRIMARY KEY,
+			payload STRING CHECK (payload != 'rejected'),
+			crdb_replication_origin_timestamp DECIMAL NULL ON UPDATE NULL
+		)`,
+		`CREATE TABLE b.no_origin (pk INT PRIMARY KEY, payload STRING)`,
+		`INSERT INTO a.tab (pk, payload) VALUES (1, 'hello')`,
+		`INSERT INTO b.tab (pk, payload) VALUES (2, 'world')`,
+	)
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.AdvSQLAddr(), t.Name(), url.User(username.RootUser))
+	defer cleanup()
+
+	sqlDB.ExpectErr(t, `table "no_origin" does not have a crdb_replication_origin_timestamp column`,
+		`CREATE LOGICAL REPLICATION STREAM FROM TABLE a.tab ON $1 INTO TABLE b.no_origin`, pgURL.String())
+
+	var jobAToB, jobBToA jobspb.JobID
+	sqlDB.QueryRow(t, `CREATE LOGICAL REPLICATION STREAM FROM TABLE a.tab ON $1 INTO TABLE b.tab`,
+		pgURL.String()).Scan(&jobAToB)
+	sqlDB.QueryRow(t, `CREATE LOGICAL REPLICATION STREAM FROM TABLE b.tab ON $1 INTO TABLE a.tab`,
+		pgURL.String()).Scan(&jobBToA)
+
+	expectRows := func(rows [][]string) {
+		t.Helper()
+		for _, tab := range []string{"a.tab", "b.tab"} {
+			sqlDB.CheckQueryResultsRetry(t, fmt.Sprintf(`SELECT pk, payload FROM %s ORDER BY pk`, tab), rows)
+		}
+	}
+	expectRows([][]string{{"1", "hello"}, {"2", "world"}})
+
+	// A local update of a replicated row wins over the older replicated
+	// version, and is replicated back to where the row came from.
+	sqlDB.Exec(t, `UPDATE b.tab SET payload = 'goodbye' WHERE pk = 1`)
+	expectRows([][]string{{"1", "goodbye"}, {"2", "world"}})
+
+	sqlDB.Exec(t, `DELETE FROM a.tab WHERE pk = 2`)
+	expectRows([][]string{{"1", "goodbye"}})
+
+	// The replicated deletion leaves a tombstone behind, which a newer row
+	// supersedes. The deletion is not replicated back, where it would delete
+	// the newer row again.
+	var tableID int
+	sqlDB.QueryRow(t, `SELECT 'b.tab'::REGCLASS::INT`).Scan(&tableID)
+	tombstones := fmt.Sprintf(`SELECT count(*) FROM b.crdb_replication.tombstones_%d`, tableID)
+	sqlDB.CheckQueryResults(t, tombstones, [][]string{{"1"}})
+	sqlDB.Exec(t, `INSERT INTO a.tab (pk, payload) VALUES (2, 'again')`)
+	expectRows([][]string{{"1", "goodbye"}, {"2", "again"}})
+	sqlDB.CheckQueryResultsRetry(t, tombstones, [][]string{{"0"}})
+	sqlDB.Exec(t, `DELETE FROM a.tab WHERE pk = 2`)
+	expectRows([][]string{{"1", "goodbye"}})
+
+	// Rows written by replication are not replicated back.
+	sqlDB.CheckQueryResults(t,
+		`SELECT crdb_replication_origin_timestamp IS NULL FROM a.tab WHERE pk = 1`, [][]string{{"false"}})
+	sqlDB.CheckQueryResults(t,
+		`SELECT crdb_replication_origin_timestamp IS NULL FROM b.tab WHERE pk = 1`, [][]string{{"true"}})
+
+	sqlDB.Exec(t, `INSERT INTO a.tab (pk, payload) VALUES (3, 'rejected')`)
+	sqlDB.CheckQueryResultsRetry(t, fmt.Sprintf(
+		`SELECT ingestion_job_id, mutation_type, incoming_row->>'payload' FROM b.crdb_replication.dlq_%d`, tableID),
+		[][]string{{fmt.Sprint(jobAToB), "upsert", "rejected"}})
+
+	for _, jobID := range []jobspb.JobID{jobAToB, jobBToA} {
+		sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+		jobutils.WaitForJobToCancel(t, sqlDB, jobID)
+	}
+}
@@ -0,0 +1,501 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package logical
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/fetchpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// originTimestampColumnName is the name of the column, which both the source
+// and the destination table must have, that records the time at which a
+// replicated row was written in the cluster it was replicated from. It is
+// NULL for rows that were written locally, which its ON UPDATE NULL
+// expression ensures for local updates of replicated rows.
+//
+// The column serves two purposes. When a replicated row conflicts with a
+// local one, the row that was written last wins, and the write time of a
+// replicated local row is its origin timestamp rather than the time it was
+// applied. And since the rows that a stream applies have a non-NULL origin
+// timestamp, a stream in the opposite direction can tell them apart and
+// avoid replicating them back to the cluster they came from.
+const originTimestampColumnName = "crdb_replication_origin_timestamp"
+
+// replicatedColumn is a column that is written by a logical replication
+// stream.
+type replicatedColumn struct {
+	name string
+	// srcID and dstID are the IDs of the column in the source and the
+	// destination table.
+	srcID, dstID descpb.ColumnID
+	typ          *types.T
+}
+
+// replicatedColumns returns the columns of the destination table that are
+// written with the values of the columns of the same name in the source
+// table, along with the ordinals of the primary key columns among them. It
+// returns an error if the rows of the source table cannot be applied to the
+// destination table.
+func replicatedColumns(
+	src, dst catalog.TableDescriptor,
+) (cols []replicatedColumn, keyOrdinals []int, _ error) {
+	for _, desc := range []catalog.TableDescriptor{src, dst} {
+		if desc.NumFamilies() != 1 {
+			return nil, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"table %q has more than one column family", desc.GetName())
+		}
+		if err := checkOriginTimestampColumn(desc); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	srcCols := make(map[string]catalog.Column)
+	for _, col := range src.PublicColumns() {
+		srcCols[col.GetName()] = col
+	}
+	ordinals := make(map[string]int)
+	for _, col := range dst.PublicColumns() {
+		name := col.GetName()
+		srcCol, ok := srcCols[name]
+		if !ok {
+			return nil, nil, pgerror.Newf(pgcode.InvalidTableDefinition,
+				"column %q of table %q does not exist in table %q", name, dst.GetName(), src.GetName())
+		}
+		delete(srcCols, name)
+		if !srcCol.GetType().Identical(col.GetType()) {
+			return nil, nil, pgerror.Newf(pgcode.DatatypeMismatch,
+				"column %q is of type %s in table %q but of type %s in table %q",
+				name, srcCol.GetType().SQLString(), src.GetName(), col.GetType().SQLString(), dst.GetName())
+		}
+		if col.GetType().UserDefined() {
+			return nil, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"column %q has a user-defined type", name)
+		}
+		if srcCol.IsComputed() != col.IsComputed() {
+			return nil, nil, pgerror.Newf(pgcode.InvalidTableDefinition,
+				"column %q must either be computed in both tables or in neither", name)
+		}
+		if col.IsComputed() || name == originTimestampColumnName {
+			continue
+		}
+		ordinals[name] = len(cols)
+		cols = append(cols, replicatedColumn{
+			name: name, srcID: srcCol.GetID(), dstID: col.GetID(), typ: col.GetType(),
+		})
+	}
+	for name := range srcCols {
+		return nil, nil, pgerror.Newf(pgcode.InvalidTableDefinition,
+			"column %q of table %q does not exist in table %q", name, src.GetName(), dst.GetName())
+	}
+
+	srcKey, dstKey := src.GetPrimaryIndex(), dst.GetPrimaryIndex()
+	if srcKey.NumKeyColumns() != dstKey.NumKeyColumns() {
+		return nil, nil, pgerror.Newf(pgcode.InvalidTableDefinition,
+			"tables %q and %q have different primary keys", src.GetName(), dst.GetName())
+	}
+	for i := 0; i < dstKey.NumKeyColumns(); i++ {
+		name := dstKey.GetKeyColumnName(i)
+		ord, ok := ordinals[name]
+		if !ok || srcKey.GetKeyColumnName(i) != name {
+			return nil, nil, pgerror.Newf(pgcode.InvalidTableDefinition,
+				"tables %q and %q have different primary keys", src.GetName(), dst.GetName())
+		}
+		keyOrdinals = append(keyOrdinals, ord)
+	}
+	return cols, keyOrdinals, nil
+}
+
+// checkOriginTimestampColumn returns an error if the table does not have an
+// origin timestamp column as expected by logical replication.
+func checkOriginTimestampColumn(desc catalog.TableDescriptor) error {
+	col := catalog.FindColumnByName(desc, originTimestampColumnName)
+	if col == nil || !col.Public() {
+		return errors.WithHintf(
+			pgerror.Newf(pgcode.InvalidTableDefinition,
+				"table %q does not have a %s column", desc.GetName(), originTimestampColumnName),
+			"Add the column by running `ALTER TABLE %s ADD COLUMN %s DECIMAL NULL ON UPDATE NULL`.",
+			tree.NameString(desc.GetName()), originTimestampColumnName,
+		)
+	}
+	if col.GetType().Family() != types.DecimalFamily || !col.IsNullable() || col.IsComputed() ||
+		!col.HasOnUpdate() || col.GetOnUpdateExpr() != "NULL" {
+		return pgerror.Newf(pgcode.InvalidTableDefinition,
+			"column %s of table %q must be a nullable DECIMAL column with ON UPDATE NULL",
+			originTimestampColumnName, desc.GetName())
+	}
+	return nil
+}
+
+// lwwRowProcessor applies the rows of the source table that it receives from
+// a stream to the destination table, resolving conflicts with local rows by
+// keeping whichever was written last.
+//
+// The deletions it applies are recorded in the tombstone table of the
+// destination table (see replicationutils.CreateTombstoneTable), which the
+// updates it applies later are compared against, and which keeps the
+// producer of a stream in the opposite direction from replicating them back.
+type lwwRowProcessor struct {
+	override sessiondata.InternalExecutorOverride
+	metrics  *Metrics
+	dlq      *deadLetterQueue
+
+	cols        []replicatedColumn
+	keyOrdinals []int
+	upsertStmt  string
+	deleteStmt  string
+
+	dst       catalog.TableDescriptor
+	dstColMap catalog.TableColMap
+	// The statements on the tombstone table, which belongs to the node user,
+	// are run as the node user.
+	readTombstoneStmt   string
+	writeTombstoneStmt  string
+	clearTombstonesStmt string
+
+	fetcher    row.Fetcher
+	kvProvider row.KVProvider
+	alloc      tree.DatumAlloc
+}
+
+// rowOutcome is the outcome of applying a row change.
+type rowOutcome int
+
+const (
+	rowApplied rowOutcome = iota
+	// rowSkipped is the outcome of a row that had itself been replicated into
+	// the source table.
+	rowSkipped
+	// rowRejected is the outcome of an update that lost to a more recent
+	// local row or deletion.
+	rowRejected
+)
+
+func newLWWRowProcessor(
+	ctx context.Context,
+	srcCodec keys.SQLCodec,
+	src, dst catalog.TableDescriptor,
+	tombstoneTableID descpb.ID,
+	override sessiondata.InternalExecutorOverride,
+	metrics *Metrics,
+	dlq *deadLetterQueue,
+) (*lwwRowProcessor, error) {
+	cols, keyOrdinals, err := replicatedColumns(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	rp := &lwwRowProcessor{
+		override:    override,
+		metrics:     metrics,
+		dlq:         dlq,
+		cols:        cols,
+		keyOrdinals: keyOrdinals,
+		upsertStmt:  makeUpsertStatement(dst.GetID(), cols, keyOrdinals),
+		deleteStmt:  makeDeleteStatement(dst.GetID(), cols, keyOrdinals),
+		dst:         dst,
+		readTombstoneStmt: fmt.Sprintf(
+			"SELECT 1 FROM [%d AS ts] WHERE key = $1 AND deleted_at > $2", tombstoneTableID),
+		writeTombstoneStmt: fmt.Sprintf(
+			"INSERT INTO [%d AS ts] (key, deleted_at) VALUES ($1, $2) "+
+				"ON CONFLICT (key) DO UPDATE SET deleted_at = greatest(ts.deleted_at, excluded.deleted_at)",
+			tombstoneTableID),
+		clearTombstonesStmt: fmt.Sprintf(
+			"DELETE FROM [%d AS ts] WHERE key = $1 AND deleted_at <= $2", tombstoneTableID),
+	}
+	for i, col := range cols {
+		rp.dstColMap.Set(col.dstID, i)
+	}
+
+	// The origin timestamp column of the source table is fetched after the
+	// replicated columns.
+	fetchedCols := make([]descpb.ColumnID, 0, len(cols)+1)
+	for _, col := range cols {
+		fetchedCols = append(fetchedCols, col.srcID)
+	}
+	fetchedCols = append(fetchedCols, catalog.FindColumnByName(src, originTimestampColumnName).GetID())
+	var spec fetchpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(
+		&spec, srcCodec, src, src.GetPrimaryIndex(), fetchedCols,
+	); err != nil {
+		return nil, err
+	}
+	if err := rp.fetcher.Init(
+		ctx,
+		row.FetcherInitArgs{
+			WillUseKVProvider: true,
+			Alloc:             &rp.alloc,
+			Spec:              &spec,
+		},
+	); err != nil {
+		return nil, err
+	}
+	return rp, nil
+}
+
+// lwwPredicate is true if the row t was written before the row that is
+// being applied, whose origin timestamp is the given expression.
+func lwwPredicate(originTimestamp string) string {
+	return fmt.Sprintf(
+		"((t.%[1]s IS NULL AND t.crdb_internal_mvcc_timestamp <= %[2]s) OR "+
+			"(t.%[1]s IS NOT NULL AND t.%[1]s <= %[2]s))",
+		originTimestampColumnName, originTimestamp)
+}
+
+// makeUpsertStatement returns the statement that applies an updated row. Its
+// placeholders are the values of the replicated columns followed by the
+// origin timestamp of the row.
+func makeUpsertStatement(tableID descpb.ID, cols []replicatedColumn, keyOrdinals []int) string {
+	isKey := make(map[int]bool, len(keyOrdinals))
+	for _, ord := range keyOrdinals {
+		isKey[ord] = true
+	}
+	var names, placeholders, keyNames, updates []string
+	for i, col := range cols {
+		name := tree.NameString(col.name)
+		names = append(names, name)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+		if !isKey[i] {
+			updates = append(updates, fmt.Sprintf("%[1]s = excluded.%[1]s", name))
+		}
+	}
+	for _, ord := range keyOrdinals {
+		keyNames = append(keyNames, tree.NameString(cols[ord].name))
+	}
+	names = append(names, originTimestampColumnName)
+	placeholders = append(placeholders, fmt.Sprintf("$%d", len(cols)+1))
+	updates = append(updates, fmt.Sprintf("%[1]s = excluded.%[1]s", originTimestampColumnName))
+
+	return fmt.Sprintf(
+		"INSERT INTO [%d AS t] (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s WHERE %s",
+		tableID,
+		strings.Join(names, ", "),
+		strings.Join(placeholders, ", "),
+		strings.Join(keyNames, ", "),
+		strings.Join(updates, ", "),
+		lwwPredicate("excluded."+originTimestampColumnName),
+	)
+}
+
+// makeDeleteStatement returns the statement that applies a deleted row. Its
+// placeholders are the values of the primary key columns followed by the
+// origin timestamp of the deletion.
+func makeDeleteStatement(tableID descpb.ID, cols []replicatedColumn, keyOrdinals []int) string {
+	conds := make([]string, 0, len(keyOrdinals))
+	for i, ord := range keyOrdinals {
+		conds = append(conds, fmt.Sprintf("%s = $%d", tree.NameString(cols[ord].name), i+1))
+	}
+	return fmt.Sprintf(
+		"DELETE FROM [%d AS t] WHERE %s AND %s",
+		tableID,
+		strings.Join(conds, " AND "),
+		lwwPredicate(fmt.Sprintf("$%d", len(keyOrdinals)+1)),
+	)
+}
+
+// ApplyBatch applies the row changes described by the given KVs of the
+// source table in a single transaction. If some of them cannot be applied,
+// the changes are applied one at a time instead, so that only those end up in
+// the dead letter table.
+func (rp *lwwRowProcessor) ApplyBatch(
+	ctx context.Context, db isql.DB, kvs []roachpb.KeyValue,
+) error {
+	outcomes := make([]rowOutcome, len(kvs))
+	err := db.Txn(ctx, func(ctx context.Context, txn isql.Txn) error {
+		for i := range kvs {
+			var err error
+			if outcomes[i], err = rp.processRow(ctx, txn, kvs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for _, outcome := range outcomes {
+			rp.recordOutcome(outcome)
+		}
+		return nil
+	}
+	if !isUnappliableRowError(err) {
+		return err
+	}
+
+	for i := range kvs {
+		var outcome rowOutcome
+		err := db.Txn(ctx, func(ctx context.Context, txn isql.Txn) error {
+			var err error
+			outcome, err = rp.processRow(ctx, txn, kvs[i])
+			return err
+		})
+		if err != nil {
+			if !isUnappliableRowError(err) {
+				return err
+			}
+			if err := rp.deadLetter(ctx, kvs[i], err); err != nil {
+				return err
+			}
+			continue
+		}
+		rp.recordOutcome(outcome)
+	}
+	return nil
+}
+
+func (rp *lwwRowProcessor) recordOutcome(outcome rowOutcome) {
+	switch outcome {
+	case rowSkipped:
+		rp.metrics.SkippedReplicatedRows.Inc(1)
+	case rowRejected:
+		rp.metrics.LWWRejections.Inc(1)
+	default:
+		rp.metrics.AppliedRowUpdates.Inc(1)
+	}
+}
+
+// processRow applies the row change described by the given KV of the source
+// table in the given transaction.
+func (rp *lwwRowProcessor) processRow(
+	ctx context.Context, txn isql.Txn, kv roachpb.KeyValue,
+) (rowOutcome, error) {
+	datums, deleted, err := rp.decodeRow(ctx, kv)
+	if err != nil {
+		return 0, err
+	}
+	// Rows that were replicated into the source table are not replicated back
+	// to the cluster they came from. The same goes for deletions, but those
+	// are filtered out by the producer of the stream, since they do not carry
+	// an origin timestamp.
+	if !deleted && datums[len(rp.cols)] != tree.DNull {
+		return rowSkipped, nil
+	}
+	key, _, err := rowenc.EncodeIndexKey(rp.dst, rp.dst.GetPrimaryIndex(), rp.dstColMap, datums, nil /* keyPrefix */)
+	if err != nil {
+		return 0, err
+	}
+	tombstoneKey := tree.NewDBytes(tree.DBytes(key))
+	originTimestamp := eval.TimestampToDecimalDatum(kv.Value.Timestamp)
+
+	if deleted {
+		args := make([]interface{}, 0, len(rp.keyOrdinals)+1)
+		for _, ord := range rp.keyOrdinals {
+			args = append(args, datums[ord])
+		}
+		args = append(args, originTimestamp)
+		if _, err := txn.ExecEx(ctx, "logical-replication-delete", txn.KV(), rp.override,
+			rp.deleteStmt, args...); err != nil {
+			return 0, err
+		}
+		// The tombstone is written even if there was no row to delete, so
+		// that older updates that arrive later lose to the deletion.
+		if _, err := txn.ExecEx(ctx, "logical-replication-write-tombstone", txn.KV(),
+			sessiondata.NodeUserSessionDataOverride, rp.writeTombstoneStmt, tombstoneKey, originTimestamp,
+		); err != nil {
+			return 0, err
+		}
+		// A deletion affects no rows both when the local row is newer and
+		// when there is no local row, so only rejected updates are counted.
+		return rowApplied, nil
+	}
+
+	tombstone, err := txn.QueryRowEx(ctx, "logical-replication-read-tombstone", txn.KV(),
+		sessiondata.NodeUserSessionDataOverride, rp.readTombstoneStmt, tombstoneKey, originTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	if tombstone != nil {
+		return rowRejected, nil
+	}
+	args := make([]interface{}, 0, len(rp.cols)+1)
+	for i := range rp.cols {
+		args = append(args, datums[i])
+	}
+	args = append(args, originTimestamp)
+	rowsAffected, err := txn.ExecEx(ctx, "logical-replication-upsert", txn.KV(), rp.override,
+		rp.upsertStmt, args...)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return rowRejected, nil
+	}
+	// Tombstones of older deletions are superseded by the row.
+	if _, err := txn.ExecEx(ctx, "logical-replication-clear-tombstones", txn.KV(),
+		sessiondata.NodeUserSessionDataOverride, rp.clearTombstonesStmt, tombstoneKey, originTimestamp,
+	); err != nil {
+		return 0, err
+	}
+	return rowApplied, nil
+}
+
+// deadLetter writes the row change that could not be applied with the given
+// error to the dead letter table.
+func (rp *lwwRowProcessor) deadLetter(
+	ctx context.Context, kv roachpb.KeyValue, applyErr error,
+) error {
+	datums, deleted, err := rp.decodeRow(ctx, kv)
+	if err != nil {
+		return err
+	}
+	rp.metrics.DeadLetteredRows.Inc(1)
+	return rp.dlq.Log(ctx, kv, rp.cols, datums[:len(rp.cols)], deleted,
+		eval.TimestampToDecimalDatum(kv.Value.Timestamp), applyErr)
+}
+
+// decodeRow decodes the row of the source table that the KV belongs to. The
+// returned datums are the values of the replicated columns followed by the
+// origin timestamp of the row in the source table. Only the primary key
+// columns are set if the row was deleted.
+func (rp *lwwRowProcessor) decodeRow(
+	ctx context.Context, kv roachpb.KeyValue,
+) (_ tree.Datums, deleted bool, _ error) {
+	rp.kvProvider.KVs = append(rp.kvProvider.KVs[:0], kv)
+	if err := rp.fetcher.ConsumeKVProvider(ctx, &rp.kvProvider); err != nil {
+		return nil, false, err
+	}
+	datums, err := rp.fetcher.NextRowDecoded(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if datums == nil {
+		return nil, false, errors.AssertionFailedf("no row decoded from key %s", kv.Key)
+	}
+	// The decoded datums are only valid until the next call to the fetcher.
+	datums = append(tree.Datums(nil), datums...)
+	deleted = rp.fetcher.RowIsDeleted()
+	if next, err := rp.fetcher.NextRowDecoded(ctx); err != nil {
+		return nil, false, err
+	} else if next != nil {
+		return nil, false, errors.AssertionFailedf("more than one row decoded from key %s", kv.Key)
+	}
+	return datums, deleted, nil
+}
+
+// isUnappliableRowError returns true if the error indicates that a row
+// cannot be applied to the destination table, such as when it violates a
+// constraint, rather than a problem that retrying could overcome.
+func isUnappliableRowError(err error) bool {
+	code := pgerror.GetPGCode(err).String()
+	// Class 22 is data exceptions and class 23 integrity constraint violations.
+	return strings.HasPrefix(code, "22") || strings.HasPrefix(code, "23")
+}
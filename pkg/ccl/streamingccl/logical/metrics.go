@@ -0,0 +1,58 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package logical
+
+import "github.com/cockroachdb/cockroach/pkg/util/metric"
+
+var (
+	metaAppliedRowUpdates = metric.Metadata{
+		Name:        "logical_replication.events_applied",
+		Help:        "Row updates and deletes applied by all logical replication jobs",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaSkippedReplicatedRows = metric.Metadata{
+		Name:        "logical_replication.events_skipped",
+		Help:        "Row updates that were not applied because they had themselves been replicated into the source table",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaLWWRejections = metric.Metadata{
+		Name:        "logical_replication.lww_rejections",
+		Help:        "Row updates and deletes that were not applied because the local row was written more recently",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaDeadLetteredRows = metric.Metadata{
+		Name:        "logical_replication.dead_lettered_rows",
+		Help:        "Row updates and deletes that could not be applied and were written to a dead letter table",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
+)
+
+// Metrics are the metrics of logical replication jobs.
+type Metrics struct {
+	AppliedRowUpdates     *metric.Counter
+	SkippedReplicatedRows *metric.Counter
+	LWWRejections         *metric.Counter
+	DeadLetteredRows      *metric.Counter
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (*Metrics) MetricStruct() {}
+
+func makeMetrics() *Metrics {
+	return &Metrics{
+		AppliedRowUpdates:     metric.NewCounter(metaAppliedRowUpdates),
+		SkippedReplicatedRows: metric.NewCounter(metaSkippedReplicatedRows),
+		LWWRejections:         metric.NewCounter(metaLWWRejections),
+		DeadLetteredRows:      metric.NewCounter(metaDeadLetteredRows),
+	}
+}
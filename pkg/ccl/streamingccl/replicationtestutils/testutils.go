@@ -15,6 +15,7 @@ import (
 	"math/rand"
 	"net/url"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -69,6 +70,7 @@ type TenantStreamingClustersArgs struct {
 	DestClusterSettings            map[string]string
 	DestClusterTestRegions         []string
 	RetentionTTLSeconds            int
+	EnableReaderTenant             bool
 	TestingKnobs                   *sql.StreamingTestingKnobs
 	TenantCapabilitiesTestingKnobs *tenantcapabilities.TestingKnobs
 
@@ -116,6 +118,7 @@ type TenantStreamingClusters struct {
 	DestSysSQL     *sqlutils.SQLRunner
 	DestTenantConn *gosql.DB
 	DestTenantSQL  *sqlutils.SQLRunner
+	DestURL        url.URL
 
 	Rng *rand.Rand
 }
@@ -271,8 +274,15 @@ func (c *TenantStreamingClusters) BuildCreateTenantQuery(externalConnection stri
 		c.Args.DestTenantName,
 		c.Args.SrcTenantName,
 		sourceURI)
+	var options []string
 	if c.Args.RetentionTTLSeconds > 0 {
-		streamReplStmt = fmt.Sprintf("%s WITH RETENTION = '%ds'", streamReplStmt, c.Args.RetentionTTLSeconds)
+		options = append(options, fmt.Sprintf("RETENTION = '%ds'", c.Args.RetentionTTLSeconds))
+	}
+	if c.Args.EnableReaderTenant {
+		options = append(options, "READ VIRTUAL CLUSTER")
+	}
+	if len(options) > 0 {
+		streamReplStmt = fmt.Sprintf("%s WITH %s", streamReplStmt, strings.Join(options, ", "))
 	}
 	return streamReplStmt
 }
@@ -388,10 +398,11 @@ func CreateTenantStreamingClusters(
 	})
 
 	var destCluster *testcluster.TestCluster
+	var destURL url.URL
 	var destCleanup func()
 	g.GoCtx(func(ctx context.Context) error {
 		// Start the destination cluster.
-		destCluster, _, destCleanup = startC2CTestCluster(ctx, t, serverArgs, args.DestNumNodes, args.DestClusterTestRegions)
+		destCluster, destURL, destCleanup = startC2CTestCluster(ctx, t, serverArgs, args.DestNumNodes, args.DestClusterTestRegions)
 		return nil
 	})
 
@@ -409,6 +420,7 @@ func CreateTenantStreamingClusters(
 		DestCluster:   destCluster,
 		DestSysSQL:    sqlutils.MakeSQLRunner(destCluster.ServerConn(0)),
 		DestSysServer: destCluster.Server(0).SystemLayer(),
+		DestURL:       destURL,
 		Rng:           rng,
 	}
 	tsc.setupSrcTenant()
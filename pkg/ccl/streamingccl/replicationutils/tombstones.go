@@ -0,0 +1,126 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package replicationutils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// ReplicationSchemaName is the name of the schema, in the database of a table
+// replicated by logical replication, that the tables which logical
+// replication maintains for it are created in.
+const ReplicationSchemaName = "crdb_replication"
+
+// The tombstone table of a table records the rows of the table that were
+// deleted by logical replication streams, keyed by the encoding of their
+// primary key, along with the origin timestamp of the deletion. Rows that are
+// deleted leave no trace that a later, but older, update could be compared
+// against, so the last-writer-wins comparison of updates also takes the
+// tombstones into account. Since a tombstone is written by the same
+// transaction as the deletion, it also tells the deletions applied by a
+// stream apart from local ones, so that a stream in the opposite direction
+// does not replicate them back.
+const tombstoneTableSchema = `(
+	key        BYTES NOT NULL PRIMARY KEY,
+	deleted_at DECIMAL NOT NULL
+)`
+
+// TombstoneTableName returns the name of the tombstone table of the table
+// with the given ID.
+func TombstoneTableName(dbName string, tableID descpb.ID) string {
+	return fmt.Sprintf("%s.%s.tombstones_%d", tree.NameString(dbName), ReplicationSchemaName, tableID)
+}
+
+// CreateTombstoneTable creates the tombstone table of the table with the
+// given ID, if it does not exist yet, and returns its ID. It is created by
+// both the logical replication job that applies rows to the table and the
+// producer of a stream of the table, whichever comes first, so it is owned
+// by the node user.
+func CreateTombstoneTable(
+	ctx context.Context, ie isql.Executor, txn *kv.Txn, dbName string, tableID descpb.ID,
+) (descpb.ID, error) {
+	override := sessiondata.NodeUserSessionDataOverride
+	tableName := TombstoneTableName(dbName, tableID)
+	if _, err := ie.ExecEx(ctx, "create-replication-schema", txn, override,
+		fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s.%s", tree.NameString(dbName), ReplicationSchemaName),
+	); err != nil {
+		return 0, errors.Wrap(err, "creating replication schema")
+	}
+	if _, err := ie.ExecEx(ctx, "create-tombstone-table", txn, override,
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s %s", tableName, tombstoneTableSchema),
+	); err != nil {
+		return 0, errors.Wrap(err, "creating tombstone table")
+	}
+	row, err := ie.QueryRowEx(ctx, "resolve-tombstone-table", txn, override,
+		"SELECT $1::REGCLASS::INT8", tableName)
+	if err != nil {
+		return 0, err
+	}
+	if row == nil {
+		return 0, errors.AssertionFailedf("tombstone table %s not found", tableName)
+	}
+	return descpb.ID(tree.MustBeDInt(row[0])), nil
+}
+
+// EncodedPrimaryKey returns the encoding of the primary key of the row that
+// the given key of the primary index with the given prefix belongs to. It is
+// the key of the row's tombstone.
+func EncodedPrimaryKey(key roachpb.Key, indexPrefix roachpb.Key) ([]byte, error) {
+	rowKey, err := keys.EnsureSafeSplitKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(rowKey, indexPrefix) {
+		return nil, errors.AssertionFailedf("key %s is not in index %s", key, indexPrefix)
+	}
+	return rowKey[len(indexPrefix):], nil
+}
+
+// IsReplicatedDeletion returns true if the deletion of the row with the given
+// encoded primary key at the given timestamp was applied by a logical
+// replication stream, which is the case if the row's tombstone was written by
+// the same transaction.
+func IsReplicatedDeletion(
+	ctx context.Context,
+	db *kv.DB,
+	codec keys.SQLCodec,
+	tombstoneTableID descpb.ID,
+	primaryKey []byte,
+	ts hlc.Timestamp,
+) (bool, error) {
+	key := codec.IndexPrefix(uint32(tombstoneTableID), 1 /* primary index */)
+	key = encoding.EncodeBytesAscending(key, primaryKey)
+	key = keys.MakeFamilyKey(key, 0)
+	var replicated bool
+	err := db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		if err := txn.SetFixedTimestamp(ctx, ts); err != nil {
+			return err
+		}
+		tombstone, err := txn.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		replicated = tombstone.Value != nil && tombstone.Value.Timestamp == ts
+		return nil
+	})
+	return replicated, err
+}
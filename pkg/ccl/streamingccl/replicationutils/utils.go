@@ -16,6 +16,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/repstream/streampb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -32,6 +34,38 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// CheckReadableAt returns a BatchTimestampBeforeGCError if the garbage
+// collection threshold of any range in the span is at or after ts, i.e. if the
+// history needed to read or revert the span as of ts may be gone. A protected
+// timestamp record written afterwards can not bring it back, so this is checked
+// before a replication stream starts at a time in the past.
+func CheckReadableAt(ctx context.Context, db *kv.DB, span roachpb.Span, ts hlc.Timestamp) error {
+	ranges, err := kvclient.ScanMetaKVs(ctx, db.NewTxn(ctx, "check-readable-at"), span)
+	if err != nil {
+		return err
+	}
+	for _, r := range ranges {
+		var desc roachpb.RangeDescriptor
+		if err := r.ValueProto(&desc); err != nil {
+			return err
+		}
+		rangeSpan := span.Intersect(desc.RSpan().AsRawSpanWithNoLocals())
+		if !rangeSpan.Valid() {
+			continue
+		}
+		// The threshold is checked before a request is evaluated, so a request
+		// for a single key is enough to check that of each range.
+		b := &kv.Batch{}
+		b.Header.Timestamp = ts
+		b.Header.MaxSpanRequestKeys = 1
+		b.Scan(rangeSpan.Key, rangeSpan.EndKey)
+		if err := db.Run(ctx, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ScanSST scans the SSTable in the given RangeFeedSSTable within
 // 'scanWithin' boundaries and execute given operations on each
 // emitted MVCCKeyValue and MVCCRangeKeyValue.
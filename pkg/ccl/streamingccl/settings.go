@@ -80,6 +80,17 @@ var JobCheckpointFrequency = settings.RegisterDurationSetting(
 	settings.WithName("physical_replication.consumer.job_checkpoint_frequency"),
 )
 
+// ReaderCatalogUpdateFrequency controls how often the catalog of the reader
+// tenant of a replication stream is advanced to the stream's replicated time.
+var ReaderCatalogUpdateFrequency = settings.RegisterDurationSetting(
+	settings.SystemOnly,
+	"physical_replication.consumer.reader_catalog_update_frequency",
+	"controls how often the catalog of the reader virtual cluster of a replication stream "+
+		"is advanced to the replicated time; if 0, disabled",
+	30*time.Second,
+	settings.NonNegativeDuration,
+)
+
 var ReplanThreshold = settings.RegisterFloatSetting(
 	settings.ApplicationLevel,
 	"stream_replication.replan_flow_threshold",
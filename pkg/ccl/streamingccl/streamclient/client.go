@@ -59,8 +59,19 @@ type Client interface {
 
 	// Create initializes a stream with the source, potentially reserving any
 	// required resources, such as protected timestamps, and returns an ID which
-	// can be used to interact with this stream in the future.
-	Create(ctx context.Context, tenant roachpb.TenantName) (streampb.ReplicationProducerSpec, error)
+	// can be used to interact with this stream in the future. The request is
+	// only set when the caller is failing back to a tenant it was previously
+	// replicated to.
+	Create(
+		ctx context.Context, tenant roachpb.TenantName, req streampb.ReplicationProducerRequest,
+	) (streampb.ReplicationProducerSpec, error)
+
+	// CreateForTables initializes a stream of the changes to the tables named
+	// in the request, which belong to the tenant the client is connected to.
+	// The returned spec includes the descriptors of the tables.
+	CreateForTables(
+		ctx context.Context, req streampb.ReplicationProducerRequest,
+	) (streampb.ReplicationProducerSpec, error)
 
 	// Destroy informs the source of the stream that it may terminate production
 	// and release resources such as protected timestamps.
@@ -12,6 +12,7 @@ import (
 	"context"
 	"net"
 	"net/url"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
 	"github.com/cockroachdb/cockroach/pkg/repstream/streampb"
@@ -21,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgx/v4"
 )
@@ -63,14 +65,25 @@ var _ Client = &partitionedStreamClient{}
 
 // Create implements Client interface.
 func (p *partitionedStreamClient) Create(
-	ctx context.Context, tenantName roachpb.TenantName,
+	ctx context.Context, tenantName roachpb.TenantName, req streampb.ReplicationProducerRequest,
 ) (streampb.ReplicationProducerSpec, error) {
 	ctx, sp := tracing.ChildSpan(ctx, "streamclient.Client.Create")
 	defer sp.Finish()
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	var row pgx.Row
+	if req.ClusterID.Equal(uuid.Nil) {
+		// Use the single argument form so that sources that predate failback
+		// continue to work.
+		row = p.mu.srcConn.QueryRow(ctx, `SELECT crdb_internal.start_replication_stream($1)`, tenantName)
+	} else {
+		rawReq, err := protoutil.Marshal(&req)
+		if err != nil {
+			return streampb.ReplicationProducerSpec{}, err
+		}
+		row = p.mu.srcConn.QueryRow(ctx, `SELECT crdb_internal.start_replication_stream($1, $2)`, tenantName, rawReq)
+	}
 	var rawReplicationProducerSpec []byte
-	row := p.mu.srcConn.QueryRow(ctx, `SELECT crdb_internal.start_replication_stream($1)`, tenantName)
 	err := row.Scan(&rawReplicationProducerSpec)
 	if err != nil {
 		return streampb.ReplicationProducerSpec{}, errors.Wrapf(err, "error creating replication stream for tenant %s", tenantName)
@@ -83,6 +96,31 @@ func (p *partitionedStreamClient) Create(
 	return replicationProducerSpec, err
 }
 
+// CreateForTables implements Client interface.
+func (p *partitionedStreamClient) CreateForTables(
+	ctx context.Context, req streampb.ReplicationProducerRequest,
+) (streampb.ReplicationProducerSpec, error) {
+	ctx, sp := tracing.ChildSpan(ctx, "streamclient.Client.CreateForTables")
+	defer sp.Finish()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rawReq, err := protoutil.Marshal(&req)
+	if err != nil {
+		return streampb.ReplicationProducerSpec{}, err
+	}
+	row := p.mu.srcConn.QueryRow(ctx, `SELECT crdb_internal.start_replication_stream_for_tables($1)`, rawReq)
+	var rawReplicationProducerSpec []byte
+	if err := row.Scan(&rawReplicationProducerSpec); err != nil {
+		return streampb.ReplicationProducerSpec{}, errors.Wrapf(err,
+			"error creating replication stream for tables %s", strings.Join(req.TableNames, ", "))
+	}
+	var replicationProducerSpec streampb.ReplicationProducerSpec
+	if err := protoutil.Unmarshal(rawReplicationProducerSpec, &replicationProducerSpec); err != nil {
+		return streampb.ReplicationProducerSpec{}, err
+	}
+	return replicationProducerSpec, nil
+}
+
 // Dial implements Client interface.
 func (p *partitionedStreamClient) Dial(ctx context.Context) error {
 	p.mu.Lock()
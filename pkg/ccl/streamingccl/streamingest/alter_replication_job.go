@@ -14,11 +14,16 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/replicationutils"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/multitenant/mtinfopb"
+	"github.com/cockroachdb/cockroach/pkg/repstream/streampb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/exprutil"
@@ -42,7 +47,8 @@ var alterReplicationCutoverHeader = colinfo.ResultColumns{
 // ResolvedTenantReplicationOptions represents options from an
 // evaluated CREATE VIRTUAL CLUSTER FROM REPLICATION command.
 type resolvedTenantReplicationOptions struct {
-	retention *int32
+	retention          *int32
+	enableReaderTenant bool
 }
 
 func evalTenantReplicationOptions(
@@ -65,6 +71,7 @@ func evalTenantReplicationOptions(
 		retSeconds := int32(retSeconds64)
 		r.retention = &retSeconds
 	}
+	r.enableReaderTenant = options.EnableReaderTenant
 	return r, nil
 }
 
@@ -75,6 +82,10 @@ func (r *resolvedTenantReplicationOptions) GetRetention() (int32, bool) {
 	return *r.retention, true
 }
 
+func (r *resolvedTenantReplicationOptions) ReaderTenantEnabled() bool {
+	return r != nil && r.enableReaderTenant
+}
+
 func alterReplicationJobTypeCheck(
 	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
 ) (matched bool, header colinfo.ResultColumns, _ error) {
@@ -82,10 +93,18 @@ func alterReplicationJobTypeCheck(
 	if !ok {
 		return false, nil, nil
 	}
-	if err := exprutil.TypeCheck(
-		ctx, alterReplicationJobOp, p.SemaCtx(),
+	toTypeCheck := []exprutil.ToTypeCheck{
 		exprutil.TenantSpec{TenantSpec: alterStmt.TenantSpec},
 		exprutil.Strings{alterStmt.Options.Retention},
+	}
+	if alterStmt.ReplicationSourceAddress != nil {
+		toTypeCheck = append(toTypeCheck,
+			exprutil.TenantSpec{TenantSpec: alterStmt.ReplicationSourceTenantName},
+			exprutil.Strings{alterStmt.ReplicationSourceAddress},
+		)
+	}
+	if err := exprutil.TypeCheck(
+		ctx, alterReplicationJobOp, p.SemaCtx(), toTypeCheck...,
 	); err != nil {
 		return false, nil, err
 	}
@@ -151,6 +170,22 @@ func alterReplicationJobHook(
 	if err != nil {
 		return nil, nil, nil, false, err
 	}
+	if options.ReaderTenantEnabled() {
+		return nil, nil, nil, false, errors.Newf(
+			"the READ VIRTUAL CLUSTER option can only be specified with CREATE VIRTUAL CLUSTER FROM REPLICATION")
+	}
+
+	var srcAddr, srcTenant string
+	if alterTenantStmt.ReplicationSourceAddress != nil {
+		srcAddr, err = exprEval.String(ctx, alterTenantStmt.ReplicationSourceAddress)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+		_, _, srcTenant, err = exprEval.TenantSpec(ctx, alterTenantStmt.ReplicationSourceTenantName)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
 
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		if err := utilccl.CheckEnterpriseEnabled(
@@ -168,6 +203,9 @@ func alterReplicationJobHook(
 		if err != nil {
 			return err
 		}
+		if alterTenantStmt.ReplicationSourceAddress != nil {
+			return alterTenantRestartReplication(ctx, p, tenInfo, srcAddr, srcTenant, options, alterTenantStmt)
+		}
 		if tenInfo.TenantReplicationJobID == 0 {
 			return errors.Newf("tenant %q (%d) does not have an active replication job",
 				tenInfo.Name, tenInfo.ID)
@@ -272,6 +310,128 @@ func alterTenantJobCutover(
 	return cutoverTime, nil
 }
 
+// alterTenantRestartReplication starts replicating into an existing tenant
+// from the tenant that was created by replicating from it, e.g. to fail back
+// to the original source after a failover. The source only accepts the stream
+// if it was cut over from this tenant, in which case it starts the stream at
+// its cutover time; the ingestion job reverts this tenant to that time before
+// ingesting, so no initial scan is needed.
+func alterTenantRestartReplication(
+	ctx context.Context,
+	p sql.PlanHookState,
+	tenInfo *mtinfopb.TenantInfo,
+	srcAddr string,
+	srcTenant string,
+	options *resolvedTenantReplicationOptions,
+	alterTenantStmt *tree.AlterTenantReplication,
+) error {
+	if tenInfo.TenantReplicationJobID != 0 {
+		return errors.Newf("tenant %q (%d) already has a replication job",
+			tenInfo.Name, tenInfo.ID)
+	}
+	if tenInfo.DataState != mtinfopb.DataStateReady {
+		return errors.Newf("tenant %q (%d) is not ready (data state: %s)",
+			tenInfo.Name, tenInfo.ID, tenInfo.DataState)
+	}
+	if tenInfo.ServiceMode != mtinfopb.ServiceModeNone {
+		return errors.WithHint(
+			errors.Newf("tenant %q (%d) must be stopped before replication into it can start (service mode: %s)",
+				tenInfo.Name, tenInfo.ID, tenInfo.ServiceMode),
+			"Use ALTER VIRTUAL CLUSTER ... STOP SERVICE to stop the virtual cluster.")
+	}
+	if roachpb.IsSystemTenantName(roachpb.TenantName(srcTenant)) {
+		return errors.Newf("the source tenant %q cannot be the system tenant", srcTenant)
+	}
+
+	streamAddress := streamingccl.StreamAddress(srcAddr)
+	streamURL, err := streamAddress.URL()
+	if err != nil {
+		return err
+	}
+	streamAddress = streamingccl.StreamAddress(streamURL.String())
+
+	client, err := streamclient.NewStreamClient(ctx, streamAddress, p.ExecCfg().InternalDB)
+	if err != nil {
+		return err
+	}
+	tenantID := roachpb.MustMakeTenantID(tenInfo.ID)
+	replicationProducerSpec, err := client.Create(ctx, roachpb.TenantName(srcTenant),
+		streampb.ReplicationProducerRequest{
+			ClusterID: p.ExecCfg().NodeInfo.LogicalClusterID(),
+			TenantID:  tenantID,
+		})
+	if err != nil {
+		return err
+	}
+	// The ingestion job reverts this tenant to the start time before ingesting,
+	// which needs its history since then.
+	if err := replicationutils.CheckReadableAt(ctx, p.ExecCfg().DB, keys.MakeTenantSpan(tenantID),
+		replicationProducerSpec.ReplicationStartTime); err != nil {
+		if errors.HasType(err, (*kvpb.BatchTimestampBeforeGCError)(nil)) {
+			err = errors.WithHint(pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+				"cannot revert virtual cluster %q to the cutover time %s of %q, which is before its garbage collection threshold",
+				tenInfo.Name, replicationProducerSpec.ReplicationStartTime, srcTenant),
+				"Replicate into a new virtual cluster with CREATE VIRTUAL CLUSTER ... FROM REPLICATION OF instead.")
+		}
+		// Release the producer job, which would otherwise protect the source
+		// tenant until it times out.
+		err = errors.CombineErrors(err, client.Complete(ctx, replicationProducerSpec.StreamID,
+			false /* successfulIngestion */))
+		return errors.CombineErrors(err, client.Close(ctx))
+	}
+	if err := client.Close(ctx); err != nil {
+		return err
+	}
+
+	retentionTTLSeconds := defaultRetentionTTLSeconds
+	if ret, ok := options.GetRetention(); ok {
+		retentionTTLSeconds = ret
+	}
+	streamIngestionDetails := jobspb.StreamIngestionDetails{
+		StreamAddress:         string(streamAddress),
+		StreamID:              uint64(replicationProducerSpec.StreamID),
+		Span:                  keys.MakeTenantSpan(tenantID),
+		DestinationTenantID:   tenantID,
+		SourceTenantName:      roachpb.TenantName(srcTenant),
+		DestinationTenantName: tenInfo.Name,
+		ReplicationTTLSeconds: retentionTTLSeconds,
+		ReplicationStartTime:  replicationProducerSpec.ReplicationStartTime,
+		SourceClusterID:       replicationProducerSpec.SourceClusterID,
+		SourceTenantID:        replicationProducerSpec.SourceTenantID,
+	}
+	// The tenant already holds the source's data as of the start time, so the
+	// job starts from there rather than with an initial scan.
+	streamIngestionProgress := jobspb.StreamIngestionProgress{
+		ReplicatedTime:        replicationProducerSpec.ReplicationStartTime,
+		InitialSplitComplete:  true,
+		InitialRevertRequired: true,
+	}
+
+	redactedAddr, err := redactSourceURI(srcAddr)
+	if err != nil {
+		return err
+	}
+	redactedStmt := *alterTenantStmt
+	redactedStmt.ReplicationSourceAddress = tree.NewDString(redactedAddr)
+	jobDescription := tree.AsStringWithFQNames(&redactedStmt, p.ExtendedEvalContext().Annotations)
+
+	jobID := p.ExecCfg().JobRegistry.MakeJobID()
+	tenInfo.DataState = mtinfopb.DataStateAdd
+	tenInfo.TenantReplicationJobID = jobID
+	if err := sql.UpdateTenantRecord(ctx, p.ExecCfg().Settings, p.InternalSQLTxn(), tenInfo); err != nil {
+		return err
+	}
+
+	jr := jobs.Record{
+		Description: jobDescription,
+		Username:    p.User(),
+		Progress:    streamIngestionProgress,
+		Details:     streamIngestionDetails,
+	}
+	_, err = p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(ctx, jr, jobID, p.InternalSQLTxn())
+	return err
+}
+
 func alterTenantOptions(
 	ctx context.Context,
 	txn isql.Txn,
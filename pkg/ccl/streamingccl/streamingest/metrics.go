@@ -117,6 +117,12 @@ var (
 		Measurement: "Job Updates",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaReaderCatalogUpdates = metric.Metadata{
+		Name:        "physical_replication.reader_catalog_updates",
+		Help:        "Total number of updates to the catalog of the reader virtual cluster",
+		Measurement: "Catalog Updates",
+		Unit:        metric.Unit_COUNT,
+	}
 	// This metric would be 0 until cutover begins, and then it will be updated to
 	// the total number of ranges that need to be reverted, and then gradually go
 	// down to 0 again. NB: that the number of ranges is the total number of
@@ -143,6 +149,7 @@ type Metrics struct {
 	IngestedSSTBytes            *metric.Counter
 	Flushes                     *metric.Counter
 	JobProgressUpdates          *metric.Counter
+	ReaderCatalogUpdates        *metric.Counter
 	ResolvedEvents              *metric.Counter
 	ReplanCount                 *metric.Counter
 	FlushHistNanos              metric.IHistogram
@@ -169,6 +176,7 @@ func MakeMetrics(histogramWindow time.Duration) metric.Struct {
 		Flushes:              metric.NewCounter(metaReplicationFlushes),
 		ResolvedEvents:       metric.NewCounter(metaReplicationResolvedEventsIngested),
 		JobProgressUpdates:   metric.NewCounter(metaJobProgressUpdates),
+		ReaderCatalogUpdates: metric.NewCounter(metaReaderCatalogUpdates),
 		ReplanCount:          metric.NewCounter(metaDistSQLReplanCount),
 		FlushHistNanos: metric.NewHistogram(metric.HistogramOptions{
 			Metadata:     metaReplicationFlushHistNanos,
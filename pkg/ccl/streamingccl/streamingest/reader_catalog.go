@@ -0,0 +1,233 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamingest
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catconstants"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// updateReaderTenantCatalog advances the catalog of the reader tenant to the
+// catalog of the standby tenant as of asOf.
+//
+// The descriptors and namespace entries of the standby tenant, other than
+// those of the system database, are copied into the reader tenant's keyspace.
+// The copied tables are marked as having external row data, so that queries
+// in the reader tenant read their rows from the standby tenant's keyspace as
+// of asOf. Descriptors and namespace entries of the reader tenant which no
+// longer exist in the standby tenant are removed.
+//
+// The reader tenant picks up the changes through its regular descriptor
+// leasing, and because the copied descriptors are versioned in the reader
+// tenant's keyspace, AS OF SYSTEM TIME queries in the reader tenant see the
+// replicated data as of the replicated time at that time.
+func updateReaderTenantCatalog(
+	ctx context.Context, db *kv.DB, standbyID, readerID roachpb.TenantID, asOf hlc.Timestamp,
+) error {
+	standbyCodec := keys.MakeSQLCodec(standbyID)
+	readerCodec := keys.MakeSQLCodec(readerID)
+
+	var standbyDescs, standbyNames []roachpb.KeyValue
+	if err := db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		if err := txn.SetFixedTimestamp(ctx, asOf); err != nil {
+			return err
+		}
+		var err error
+		if standbyDescs, err = scanCatalogTable(ctx, txn, standbyCodec, keys.DescriptorTableID); err != nil {
+			return err
+		}
+		standbyNames, err = scanCatalogTable(ctx, txn, standbyCodec, keys.NamespaceTableID)
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, "reading catalog of tenant %s as of %s", standbyID, asOf)
+	}
+
+	return db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		readerDescs, err := scanCatalogTable(ctx, txn, readerCodec, keys.DescriptorTableID)
+		if err != nil {
+			return err
+		}
+		readerNames, err := scanCatalogTable(ctx, txn, readerCodec, keys.NamespaceTableID)
+		if err != nil {
+			return err
+		}
+		b := txn.NewBatch()
+
+		// Collect the versions of the descriptors currently in the reader
+		// tenant, since the copied descriptors must have higher versions for
+		// the reader tenant's leases to move on to them.
+		readerVersions := make(map[descpb.ID]descpb.DescriptorVersion, len(readerDescs))
+		for i := range readerDescs {
+			desc, err := decodeDescriptor(readerDescs[i].Value)
+			if err != nil {
+				return err
+			}
+			if isSystemDatabaseDescriptor(desc) {
+				continue
+			}
+			id, version, _, _, err := descpb.GetDescriptorMetadata(desc)
+			if err != nil {
+				return err
+			}
+			readerVersions[id] = version
+		}
+
+		for i := range standbyDescs {
+			desc, err := decodeDescriptor(standbyDescs[i].Value)
+			if err != nil {
+				return err
+			}
+			if isSystemDatabaseDescriptor(desc) {
+				continue
+			}
+			id, version, _, _, err := descpb.GetDescriptorMetadata(desc)
+			if err != nil {
+				return err
+			}
+			if readerVersion, ok := readerVersions[id]; ok && version <= readerVersion {
+				version = readerVersion + 1
+			}
+			delete(readerVersions, id)
+			if err := prepareReaderDescriptor(desc, version, standbyID, asOf); err != nil {
+				return err
+			}
+			b.Put(catalogkeys.MakeDescMetadataKey(readerCodec, id), desc)
+		}
+		// Whatever remains no longer exists in the standby tenant.
+		for id := range readerVersions {
+			b.Del(catalogkeys.MakeDescMetadataKey(readerCodec, id))
+		}
+
+		standbyNameSet := make(map[descpb.NameInfo]struct{}, len(standbyNames))
+		for i := range standbyNames {
+			nameKey, err := catalogkeys.DecodeNameMetadataKey(standbyCodec, standbyNames[i].Key)
+			if err != nil {
+				return err
+			}
+			id, err := standbyNames[i].Value.GetInt()
+			if err != nil {
+				return err
+			}
+			if nameKey.ParentID == keys.SystemDatabaseID || id == keys.SystemDatabaseID {
+				continue
+			}
+			standbyNameSet[nameKey] = struct{}{}
+			b.Put(catalogkeys.EncodeNameKey(readerCodec, &nameKey), id)
+		}
+		for i := range readerNames {
+			nameKey, err := catalogkeys.DecodeNameMetadataKey(readerCodec, readerNames[i].Key)
+			if err != nil {
+				return err
+			}
+			id, err := readerNames[i].Value.GetInt()
+			if err != nil {
+				return err
+			}
+			if nameKey.ParentID == keys.SystemDatabaseID || id == keys.SystemDatabaseID {
+				continue
+			}
+			if _, ok := standbyNameSet[nameKey]; !ok {
+				b.Del(readerNames[i].Key)
+			}
+		}
+
+		log.VInfof(ctx, 1, "advancing catalog of reader tenant %s to %s", readerID, asOf)
+		return txn.Run(ctx, b)
+	})
+}
+
+// scanCatalogTable returns all rows of the primary index of the given system
+// table of the tenant with the given codec.
+func scanCatalogTable(
+	ctx context.Context, txn *kv.Txn, codec keys.SQLCodec, tableID descpb.ID,
+) ([]roachpb.KeyValue, error) {
+	primaryIndexID := uint32(1)
+	if tableID == keys.NamespaceTableID {
+		primaryIndexID = catconstants.NamespaceTablePrimaryIndexID
+	}
+	prefix := codec.IndexPrefix(uint32(tableID), primaryIndexID)
+	return txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0 /* maxRows */)
+}
+
+func decodeDescriptor(value *roachpb.Value) (*descpb.Descriptor, error) {
+	var desc descpb.Descriptor
+	if err := value.GetProto(&desc); err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
+
+// isSystemDatabaseDescriptor returns true if the descriptor is the system
+// database or one of its objects. These are maintained by each tenant and are
+// never copied between tenants.
+func isSystemDatabaseDescriptor(desc *descpb.Descriptor) bool {
+	table, database, typ, schema, function := descpb.GetDescriptors(desc)
+	switch {
+	case table != nil:
+		return table.ParentID == keys.SystemDatabaseID
+	case database != nil:
+		return database.ID == keys.SystemDatabaseID
+	case typ != nil:
+		return typ.ParentID == keys.SystemDatabaseID
+	case schema != nil:
+		return schema.ParentID == keys.SystemDatabaseID
+	case function != nil:
+		return function.ParentID == keys.SystemDatabaseID
+	}
+	return false
+}
+
+// prepareReaderDescriptor modifies a descriptor read from the standby tenant
+// in place so that it can be written into the reader tenant.
+func prepareReaderDescriptor(
+	desc *descpb.Descriptor,
+	version descpb.DescriptorVersion,
+	standbyID roachpb.TenantID,
+	asOf hlc.Timestamp,
+) error {
+	table, database, typ, schema, function := descpb.GetDescriptors(desc)
+	switch {
+	case table != nil:
+		table.Version, table.ModificationTime = version, hlc.Timestamp{}
+		if table.IsTable() {
+			table.External = &descpb.ExternalRowData{
+				AsOf:     asOf,
+				TenantID: standbyID,
+				TableID:  table.ID,
+			}
+			// Statistics cannot be collected on external row data.
+			disabled := false
+			if table.AutoStatsSettings == nil {
+				table.AutoStatsSettings = &catpb.AutoStatsSettings{}
+			}
+			table.AutoStatsSettings.Enabled = &disabled
+		}
+	case database != nil:
+		database.Version, database.ModificationTime = version, hlc.Timestamp{}
+	case typ != nil:
+		typ.Version, typ.ModificationTime = version, hlc.Timestamp{}
+	case schema != nil:
+		schema.Version, schema.ModificationTime = version, hlc.Timestamp{}
+	case function != nil:
+		function.Version, function.ModificationTime = version, hlc.Timestamp{}
+	default:
+		return errors.AssertionFailedf("unknown descriptor type %T", desc.Union)
+	}
+	return nil
+}
@@ -21,8 +21,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/mtinfopb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
@@ -593,6 +596,166 @@ func TestTenantStreamingCutoverOnSourceFailure(t *testing.T) {
 	jobutils.WaitForJobToSucceed(t, c.DestSysSQL, jobspb.JobID(ingestionJobID))
 }
 
+// TestTenantStreamingFailback tests that after cutting over from the source to
+// the destination, the original source tenant can be caught up from the
+// destination without an initial scan, discarding writes it received after
+// the cutover.
+func TestTenantStreamingFailback(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	args := replicationtestutils.DefaultTenantStreamingClustersArgs
+	c, cleanup := replicationtestutils.CreateTenantStreamingClusters(ctx, t, args)
+	defer cleanup()
+
+	producerJobID, ingestionJobID := c.StartStreamReplication(ctx)
+	jobutils.WaitForJobToRun(c.T, c.SrcSysSQL, jobspb.JobID(producerJobID))
+	jobutils.WaitForJobToRun(c.T, c.DestSysSQL, jobspb.JobID(ingestionJobID))
+
+	c.SrcTenantSQL.Exec(t, "INSERT INTO d.t2 VALUES (3);")
+	c.WaitUntilStartTimeReached(jobspb.JobID(ingestionJobID))
+	c.Cutover(producerJobID, ingestionJobID, time.Time{}, false)
+	cutoverTime := jobutils.GetJobProgress(t, c.DestSysSQL, jobspb.JobID(ingestionJobID)).
+		GetStreamIngest().CutoverTime
+
+	// Write to both tenants after the cutover. The write to the original source
+	// must not survive the failback.
+	cleanupDestTenant := c.StartDestTenant(ctx)
+	defer func() { require.NoError(t, cleanupDestTenant()) }()
+	c.DestTenantSQL.Exec(t, "INSERT INTO d.t2 VALUES (100);")
+	c.SrcTenantSQL.Exec(t, "INSERT INTO d.t2 VALUES (200);")
+
+	// The roles of the clusters are now reversed.
+	c.SrcSysSQL.ExecMultiple(t, replicationtestutils.ConfigureClusterSettings(c.Args.DestClusterSettings)...)
+	c.SrcSysSQL.Exec(t, `SET CLUSTER SETTING cross_cluster_replication.enabled = true`)
+	c.DestSysSQL.ExecMultiple(t, replicationtestutils.ConfigureClusterSettings(c.Args.SrcClusterSettings)...)
+
+	startReplication := `ALTER VIRTUAL CLUSTER $1 START REPLICATION OF $2 ON $3`
+	c.SrcSysSQL.ExpectErr(t, "must be stopped", startReplication,
+		c.Args.SrcTenantName, c.Args.DestTenantName, c.DestURL.String())
+	c.SrcSysSQL.Exec(t, `ALTER VIRTUAL CLUSTER $1 STOP SERVICE`, c.Args.SrcTenantName)
+
+	// Only the tenant that was replicated from can fail back.
+	c.SrcSysSQL.Exec(t, `CREATE VIRTUAL CLUSTER other`)
+	c.SrcSysSQL.ExpectErr(t, "was last replicated from tenant", startReplication,
+		"other", c.Args.DestTenantName, c.DestURL.String())
+
+	c.SrcSysSQL.Exec(t, startReplication,
+		c.Args.SrcTenantName, c.Args.DestTenantName, c.DestURL.String())
+	failbackProducerJobID, failbackIngestionJobID := replicationtestutils.GetStreamJobIds(
+		t, ctx, c.SrcSysSQL, c.Args.SrcTenantName)
+	jobutils.WaitForJobToRun(t, c.DestSysSQL, jobspb.JobID(failbackProducerJobID))
+	jobutils.WaitForJobToRun(t, c.SrcSysSQL, jobspb.JobID(failbackIngestionJobID))
+
+	// The failback stream starts at the original cutover time.
+	payload := jobutils.GetJobPayload(t, c.SrcSysSQL, jobspb.JobID(failbackIngestionJobID))
+	require.Equal(t, cutoverTime, payload.GetStreamIngestion().ReplicationStartTime)
+
+	c.DestTenantSQL.Exec(t, "INSERT INTO d.t2 VALUES (101);")
+	now := c.DestSysServer.Clock().Now()
+	replicationtestutils.WaitUntilReplicatedTime(t, now, c.SrcSysSQL, jobspb.JobID(failbackIngestionJobID))
+
+	expected := replicationtestutils.FingerprintTenantAtTimestampNoHistory(
+		t, c.DestSysSQL, c.Args.DestTenantID.ToUint64(), now.AsOfSystemTime())
+	actual := replicationtestutils.FingerprintTenantAtTimestampNoHistory(
+		t, c.SrcSysSQL, c.Args.SrcTenantID.ToUint64(), now.AsOfSystemTime())
+	require.Equal(t, expected, actual)
+}
+
+// TestTenantStreamingReaderTenant tests that the reader virtual cluster of a
+// replication stream serves read-only queries over the replicated data.
+func TestTenantStreamingReaderTenant(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	skip.UnderRace(t, "slow test")
+
+	ctx := context.Background()
+	args := replicationtestutils.DefaultTenantStreamingClustersArgs
+	args.EnableReaderTenant = true
+	c, cleanup := replicationtestutils.CreateTenantStreamingClusters(ctx, t, args)
+	defer cleanup()
+
+	c.DestSysSQL.Exec(t, `SET CLUSTER SETTING physical_replication.consumer.reader_catalog_update_frequency = '100ms'`)
+	producerJobID, ingestionJobID := c.StartStreamReplication(ctx)
+	jobutils.WaitForJobToRun(c.T, c.SrcSysSQL, jobspb.JobID(producerJobID))
+	jobutils.WaitForJobToRun(c.T, c.DestSysSQL, jobspb.JobID(ingestionJobID))
+
+	c.SrcTenantSQL.Exec(t, "INSERT INTO d.t2 VALUES (3);")
+	c.WaitUntilReplicatedTime(c.SrcCluster.Server(0).Clock().Now(), jobspb.JobID(ingestionJobID))
+
+	readerConn := c.DestCluster.Server(0).SystemLayer().SQLConn(t,
+		"cluster:"+string(c.Args.DestTenantName)+"-readonly/defaultdb")
+	readerSQL := sqlutils.MakeSQLRunner(readerConn)
+	testutils.SucceedsSoon(t, func() error {
+		var count int
+		if err := readerConn.QueryRow(`SELECT count(*) FROM d.t2`).Scan(&count); err != nil {
+			return err
+		}
+		if count != 2 {
+			return errors.Newf("expected 2 rows, found %d", count)
+		}
+		return nil
+	})
+	readerSQL.CheckQueryResults(t, `SELECT i, b FROM d.t1`, [][]string{{"42", "world"}})
+
+	readerSQL.ExpectErr(t, "is read-only because its data is read from another virtual cluster",
+		`INSERT INTO d.t2 VALUES (4)`)
+	readerSQL.ExpectErr(t, "is read-only because its data is read from another virtual cluster",
+		`ALTER TABLE d.t2 ADD COLUMN j INT`)
+
+	// The reader virtual cluster is dropped on cutover, since its data is no
+	// longer kept up to date nor protected from garbage collection.
+	readerName := string(c.Args.DestTenantName) + "-readonly"
+	var readerID int
+	c.DestSysSQL.QueryRow(t, `SELECT id FROM system.tenants WHERE name = $1`, readerName).Scan(&readerID)
+	c.Cutover(producerJobID, ingestionJobID, time.Time{}, false)
+	c.DestSysSQL.CheckQueryResults(t,
+		fmt.Sprintf(`SELECT name IS NULL, data_state FROM system.tenants WHERE id = %d`, readerID),
+		[][]string{{"true", fmt.Sprint(int(mtinfopb.DataStateDrop))}})
+}
+
+// TestTenantStreamingFailbackAfterGC tests that failing back is rejected up
+// front if the history since the cutover has been garbage collected on the
+// tenant that was cut over to.
+func TestTenantStreamingFailbackAfterGC(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	args := replicationtestutils.DefaultTenantStreamingClustersArgs
+	c, cleanup := replicationtestutils.CreateTenantStreamingClusters(ctx, t, args)
+	defer cleanup()
+
+	producerJobID, ingestionJobID := c.StartStreamReplication(ctx)
+	jobutils.WaitForJobToRun(c.T, c.SrcSysSQL, jobspb.JobID(producerJobID))
+	jobutils.WaitForJobToRun(c.T, c.DestSysSQL, jobspb.JobID(ingestionJobID))
+
+	c.WaitUntilStartTimeReached(jobspb.JobID(ingestionJobID))
+	c.Cutover(producerJobID, ingestionJobID, time.Time{}, false)
+
+	// Advance the GC threshold of the destination tenant past the cutover.
+	gcr := &kvpb.GCRequest{
+		RequestHeader: kvpb.RequestHeader{
+			Key:    keys.MakeTenantPrefix(c.Args.DestTenantID),
+			EndKey: keys.MakeTenantPrefix(c.Args.DestTenantID).PrefixEnd(),
+		},
+		Threshold: c.DestSysServer.Clock().Now(),
+	}
+	_, pErr := kv.SendWrapped(ctx, c.DestSysServer.DB().NonTransactionalSender(), gcr)
+	require.NoError(t, pErr.GoError())
+
+	c.SrcSysSQL.ExecMultiple(t, replicationtestutils.ConfigureClusterSettings(c.Args.DestClusterSettings)...)
+	c.SrcSysSQL.Exec(t, `SET CLUSTER SETTING cross_cluster_replication.enabled = true`)
+	c.DestSysSQL.ExecMultiple(t, replicationtestutils.ConfigureClusterSettings(c.Args.SrcClusterSettings)...)
+	c.SrcSysSQL.Exec(t, `ALTER VIRTUAL CLUSTER $1 STOP SERVICE`, c.Args.SrcTenantName)
+
+	c.SrcSysSQL.ExpectErr(t, "which is before its garbage collection threshold",
+		`ALTER VIRTUAL CLUSTER $1 START REPLICATION OF $2 ON $3`,
+		c.Args.SrcTenantName, c.Args.DestTenantName, c.DestURL.String())
+}
+
 func TestTenantStreamingDeleteRange(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -73,9 +73,10 @@ type streamIngestionFrontier struct {
 	// progress that is persisted in the job record.
 	persistedReplicatedTime hlc.Timestamp
 
-	lastPartitionUpdate time.Time
-	lastFrontierDump    time.Time
-	partitionProgress   map[string]jobspb.StreamIngestionProgress_PartitionProgress
+	lastPartitionUpdate     time.Time
+	lastFrontierDump        time.Time
+	lastReaderCatalogUpdate time.Time
+	partitionProgress       map[string]jobspb.StreamIngestionProgress_PartitionProgress
 }
 
 var _ execinfra.Processor = &streamIngestionFrontier{}
@@ -414,6 +415,7 @@ func (sf *streamIngestionFrontier) maybeUpdateProgress() error {
 
 	sf.lastPartitionUpdate = timeutil.Now()
 	log.VInfof(ctx, 2, "persisting replicated time of %s", replicatedTime)
+	var standbyTenantID, readerTenantID roachpb.TenantID
 	if err := registry.UpdateJobWithTxn(ctx, jobID, nil, false, func(
 		txn isql.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
 	) error {
@@ -451,6 +453,7 @@ func (sf *streamIngestionFrontier) maybeUpdateProgress() error {
 		// timestamp less than replicatedTime - ReplicationTTLSeconds eligible for
 		// garbage collection.
 		replicationDetails := md.Payload.GetStreamIngestion()
+		standbyTenantID, readerTenantID = replicationDetails.DestinationTenantID, replicationDetails.ReadTenantID
 		if replicationDetails.ProtectedTimestampRecordID == nil {
 			return errors.AssertionFailedf("expected replication job to have a protected timestamp " +
 				"record over the destination tenant's keyspan")
@@ -481,9 +484,35 @@ func (sf *streamIngestionFrontier) maybeUpdateProgress() error {
 	sf.persistedReplicatedTime = f.Frontier()
 	sf.metrics.FrontierCheckpointSpanCount.Update(int64(len(frontierResolvedSpans)))
 	sf.updateLagMetric()
+
+	if readerTenantID.IsSet() && sf.replicatedTimeAtStart.Less(replicatedTime) {
+		sf.maybeUpdateReaderTenantCatalog(ctx, standbyTenantID, readerTenantID, replicatedTime)
+	}
 	return nil
 }
 
+// maybeUpdateReaderTenantCatalog periodically advances the catalog of the
+// reader tenant to the given replicated time, so that its queries observe
+// more recently replicated data.
+func (sf *streamIngestionFrontier) maybeUpdateReaderTenantCatalog(
+	ctx context.Context, standbyTenantID, readerTenantID roachpb.TenantID, replicatedTime hlc.Timestamp,
+) {
+	updateFreq := streamingccl.ReaderCatalogUpdateFrequency.Get(&sf.flowCtx.Cfg.Settings.SV)
+	if updateFreq == 0 || timeutil.Since(sf.lastReaderCatalogUpdate) < updateFreq {
+		return
+	}
+	sf.lastReaderCatalogUpdate = timeutil.Now()
+	if err := updateReaderTenantCatalog(
+		ctx, sf.flowCtx.Cfg.DB.KV(), standbyTenantID, readerTenantID, replicatedTime,
+	); err != nil {
+		// A failed update only leaves the reader tenant's data more stale, so it
+		// does not fail the replication stream.
+		log.Warningf(ctx, "failed to update catalog of reader tenant %s: %v", readerTenantID, err)
+		return
+	}
+	sf.metrics.ReaderCatalogUpdates.Inc(1)
+}
+
 func (sf *streamIngestionFrontier) updateLagMetric() {
 	if !sf.persistedReplicatedTime.IsEmpty() {
 		// Only update the frontier lag if the replicated time has been updated,
@@ -105,8 +105,29 @@ func completeIngestion(
 	ctx context.Context, execCtx sql.JobExecContext, ingestionJob *jobs.Job,
 ) error {
 	details := ingestionJob.Details().(jobspb.StreamIngestionDetails)
+
+	// Record where the tenant was replicated from so that the source tenant can
+	// later fail back to it without a full copy. Jobs created before the source
+	// was recorded in the details leave this unset.
+	var previousSource *mtinfopb.PreviousSourceTenant
+	if !details.SourceClusterID.Equal(uuid.Nil) {
+		progress, err := replicationutils.LoadIngestionProgress(ctx, execCtx.ExecCfg().InternalDB, ingestionJob.ID())
+		if err != nil {
+			return err
+		}
+		if progress == nil {
+			return errors.AssertionFailedf("no progress recorded for stream ingestion job %d", ingestionJob.ID())
+		}
+		previousSource = &mtinfopb.PreviousSourceTenant{
+			ClusterID:        details.SourceClusterID,
+			TenantID:         details.SourceTenantID,
+			CutoverTimestamp: progress.CutoverTime,
+		}
+	}
+
 	log.Infof(ctx, "activating destination tenant %d", details.DestinationTenantID)
-	if err := activateTenant(ctx, execCtx, details.DestinationTenantID); err != nil {
+	if err := activateTenant(ctx, execCtx, details.DestinationTenantID, details.ReadTenantID,
+		previousSource); err != nil {
 		return err
 	}
 
@@ -169,6 +190,9 @@ func ingest(
 		log.Infof(ctx, "job completed cutover on resume")
 		return completeIngestion(ctx, execCtx, ingestionJob)
 	}
+	if err := maybeRevertToReplicationStartTime(ctx, execCtx, ingestionJob); err != nil {
+		return err
+	}
 	if knobs := execCtx.ExecCfg().StreamingTestingKnobs; knobs != nil && knobs.BeforeIngestionStart != nil {
 		if err := knobs.BeforeIngestionStart(ctx); err != nil {
 			return err
@@ -466,7 +490,67 @@ func maybeRevertToCutoverTimestamp(
 	return true, nil
 }
 
-func activateTenant(ctx context.Context, execCtx interface{}, newTenantID roachpb.TenantID) error {
+// maybeRevertToReplicationStartTime reverts the destination tenant to the
+// replication start time if it already contained data when the job was
+// created, which is the case when failing back into a tenant that the source
+// tenant was itself replicated from. Any writes made to the tenant after the
+// source cut over are discarded, leaving the two tenants identical as of the
+// start time, so ingestion can proceed without an initial scan.
+func maybeRevertToReplicationStartTime(
+	ctx context.Context, p sql.JobExecContext, ingestionJob *jobs.Job,
+) error {
+	progress, err := replicationutils.LoadIngestionProgress(ctx, p.ExecCfg().InternalDB, ingestionJob.ID())
+	if err != nil {
+		return err
+	}
+	if progress == nil || !progress.InitialRevertRequired {
+		return nil
+	}
+
+	ctx, span := tracing.ChildSpan(ctx, "streamingest.revertToReplicationStartTime")
+	defer span.Finish()
+
+	details := ingestionJob.Details().(jobspb.StreamIngestionDetails)
+	log.Infof(ctx, "reverting destination tenant to replication start time %s", details.ReplicationStartTime)
+
+	batchSize := int64(sql.RevertTableDefaultBatchSize)
+	if p.ExecCfg().StreamingTestingKnobs != nil && p.ExecCfg().StreamingTestingKnobs.OverrideRevertRangeBatchSize != 0 {
+		batchSize = p.ExecCfg().StreamingTestingKnobs.OverrideRevertRangeBatchSize
+	}
+	if err := sql.RevertSpansFanout(ctx,
+		p.ExecCfg().DB,
+		p,
+		roachpb.Spans{details.Span},
+		details.ReplicationStartTime,
+		false, /* ignoreGCThreshold */
+		batchSize,
+		nil /* onCompletedCallback */); err != nil {
+		return err
+	}
+
+	return ingestionJob.NoTxn().Update(ctx,
+		func(txn isql.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+			streamIngestionProgress := md.Progress.GetStreamIngest()
+			if streamIngestionProgress == nil {
+				return errors.AssertionFailedf("unknown progress %v in stream ingestion job %d",
+					md.Progress, ingestionJob.ID())
+			}
+			streamIngestionProgress.InitialRevertRequired = false
+			ju.UpdateProgress(md.Progress)
+			return nil
+		})
+}
+
+// activateTenant marks the destination tenant as ready and drops its reader
+// tenant, if any, whose queries are served by the destination tenant itself
+// once it is activated.
+func activateTenant(
+	ctx context.Context,
+	execCtx interface{},
+	newTenantID roachpb.TenantID,
+	readerTenantID roachpb.TenantID,
+	previousSource *mtinfopb.PreviousSourceTenant,
+) error {
 	p := execCtx.(sql.JobExecContext)
 	execCfg := p.ExecCfg()
 	return execCfg.InternalDB.Txn(ctx, func(
@@ -479,7 +563,14 @@ func activateTenant(ctx context.Context, execCtx interface{}, newTenantID roachp
 
 		info.DataState = mtinfopb.DataStateReady
 		info.TenantReplicationJobID = 0
-		return sql.UpdateTenantRecord(ctx, p.ExecCfg().Settings, txn, info)
+		info.PreviousSourceTenant = previousSource
+		if err := sql.UpdateTenantRecord(ctx, p.ExecCfg().Settings, txn, info); err != nil {
+			return err
+		}
+		if readerTenantID.IsSet() {
+			return sql.DropReaderTenant(ctx, execCfg, txn, p.User(), readerTenantID)
+		}
+		return nil
 	})
 }
 
@@ -513,6 +604,13 @@ func (s *streamIngestionResumer) OnFailOrCancel(
 			return errors.Wrap(err, "update tenant record")
 		}
 
+		if details.ReadTenantID.IsSet() {
+			if err := sql.DropReaderTenant(ctx, execCfg, txn, jobExecCtx.User(),
+				details.ReadTenantID); err != nil {
+				return errors.Wrap(err, "drop reader tenant")
+			}
+		}
+
 		if details.ProtectedTimestampRecordID != nil {
 			ptp := execCfg.ProtectedTimestampProvider.WithTxn(txn)
 			if err := releaseDestinationTenantProtectedTimestamp(
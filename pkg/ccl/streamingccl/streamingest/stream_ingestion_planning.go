@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/multitenant/mtinfopb"
+	"github.com/cockroachdb/cockroach/pkg/repstream/streampb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
@@ -53,6 +54,12 @@ func streamIngestionJobDescription(
 	return tree.AsStringWithFQNames(redactedCreateStmt, ann), nil
 }
 
+// readerTenantName returns the name of the reader tenant of the given
+// destination tenant.
+func readerTenantName(dstTenantName roachpb.TenantName) roachpb.TenantName {
+	return dstTenantName + "-readonly"
+}
+
 func redactSourceURI(addr string) (string, error) {
 	return cloud.SanitizeExternalStorageURI(addr, streamclient.RedactableURLParameters)
 }
@@ -210,6 +217,15 @@ func ingestionPlanHook(
 			return nil
 		}
 
+		var readerTenantID roachpb.TenantID
+		if options.ReaderTenantEnabled() {
+			readerTenantID, err = p.CreateReaderTenant(ctx,
+				readerTenantName(roachpb.TenantName(dstTenantName)), destinationTenantID)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Create a new stream with stream client.
 		client, err := streamclient.NewStreamClient(ctx, streamAddress, p.ExecCfg().InternalDB)
 		if err != nil {
@@ -218,7 +234,8 @@ func ingestionPlanHook(
 		// Create the producer job first for the purpose of observability, user is
 		// able to know the producer job id immediately after executing
 		// CREATE VIRTUAL CLUSTER ... FROM REPLICATION.
-		replicationProducerSpec, err := client.Create(ctx, roachpb.TenantName(sourceTenant))
+		replicationProducerSpec, err := client.Create(ctx, roachpb.TenantName(sourceTenant),
+			streampb.ReplicationProducerRequest{})
 		if err != nil {
 			return err
 		}
@@ -235,6 +252,9 @@ func ingestionPlanHook(
 			DestinationTenantName: roachpb.TenantName(dstTenantName),
 			ReplicationTTLSeconds: retentionTTLSeconds,
 			ReplicationStartTime:  replicationProducerSpec.ReplicationStartTime,
+			SourceClusterID:       replicationProducerSpec.SourceClusterID,
+			SourceTenantID:        replicationProducerSpec.SourceTenantID,
+			ReadTenantID:          readerTenantID,
 		}
 
 		jobDescription, err := streamIngestionJobDescription(p, from, ingestionStmt)
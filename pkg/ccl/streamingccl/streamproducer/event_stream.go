@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/repstream/streampb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
@@ -61,6 +62,17 @@ type eventStream struct {
 	streamCh    chan tree.Datums              // Channel signaled to forward datums to consumer.
 	sp          *tracing.Span                 // Span representing the lifetime of the eventStream.
 	acc         mon.BoundAccount
+
+	// tombstoneTables are the tombstone tables of the replicated tables when
+	// the stream replicates tables rather than a tenant.
+	tombstoneTables []tombstoneTable
+}
+
+// tombstoneTable is the tombstone table of a replicated table.
+type tombstoneTable struct {
+	// span is the span of the primary index of the replicated table.
+	span roachpb.Span
+	id   descpb.ID
 }
 
 var _ eval.ValueGenerator = (*eventStream)(nil)
@@ -85,10 +97,17 @@ func (s *eventStream) Start(ctx context.Context, txn *kv.Txn) error {
 		return errors.AssertionFailedf("expected to be started once")
 	}
 
-	sourceTenantID, err := s.validateProducerJobAndSpec(ctx)
+	details, err := s.validateProducerJobAndSpec(ctx)
 	if err != nil {
 		return err
 	}
+	sourceTenantID := details.TenantID
+	for i, id := range details.TombstoneTableIDs {
+		s.tombstoneTables = append(s.tombstoneTables, tombstoneTable{
+			span: details.Spans[i],
+			id:   id,
+		})
+	}
 
 	log.Infof(ctx, "starting physical replication event stream: tenant=%s initial_scan_timestamp=%s previous_replicated_time=%s",
 		sourceTenantID, s.spec.InitialScanTimestamp, s.spec.PreviousReplicatedTimestamp)
@@ -266,6 +285,31 @@ func (s *eventStream) onValue(ctx context.Context, value *kvpb.RangeFeedValue) {
 	}
 }
 
+// isReplicatedDeletion returns true if the value is the deletion of a row of
+// a replicated table that was applied by a logical replication stream into
+// the table. Such deletions are not streamed, just like the rows written by
+// such a stream are not applied by the consumer, so that they are not
+// replicated back to the cluster they came from.
+func (s *eventStream) isReplicatedDeletion(
+	ctx context.Context, value *kvpb.RangeFeedValue,
+) (bool, error) {
+	if value.Value.IsPresent() {
+		return false, nil
+	}
+	for _, t := range s.tombstoneTables {
+		if !t.span.ContainsKey(value.Key) {
+			continue
+		}
+		primaryKey, err := replicationutils.EncodedPrimaryKey(value.Key, t.span.Key)
+		if err != nil {
+			return false, err
+		}
+		return replicationutils.IsReplicatedDeletion(ctx, s.execCfg.DB, s.execCfg.Codec, t.id,
+			primaryKey, value.Value.Timestamp)
+	}
+	return false, nil
+}
+
 func (s *eventStream) onCheckpoint(ctx context.Context, checkpoint *kvpb.RangeFeedCheckpoint) {
 	select {
 	case <-ctx.Done():
@@ -458,6 +502,11 @@ func (s *eventStream) streamLoop(ctx context.Context, frontier *span.Frontier) e
 		case ev := <-s.eventsCh:
 			switch {
 			case ev.Val != nil:
+				if replicated, err := s.isReplicatedDeletion(ctx, ev.Val); err != nil {
+					return err
+				} else if replicated {
+					continue
+				}
 				seb.addKV(&roachpb.KeyValue{
 					Key:   ev.Val.Key,
 					Value: ev.Val.Value,
@@ -500,22 +549,24 @@ func (s *eventStream) streamLoop(ctx context.Context, frontier *span.Frontier) e
 	}
 }
 
-func (s *eventStream) validateProducerJobAndSpec(ctx context.Context) (roachpb.TenantID, error) {
+func (s *eventStream) validateProducerJobAndSpec(
+	ctx context.Context,
+) (*jobspb.StreamReplicationDetails, error) {
 	producerJobID := jobspb.JobID(s.streamID)
 	job, err := s.execCfg.JobRegistry.LoadJob(ctx, producerJobID)
 	if err != nil {
-		return roachpb.TenantID{}, err
+		return nil, err
 	}
 	payload := job.Payload()
 	sp, ok := payload.GetDetails().(*jobspb.Payload_StreamReplication)
 	if !ok {
-		return roachpb.TenantID{}, notAReplicationJobError(producerJobID)
+		return nil, notAReplicationJobError(producerJobID)
 	}
 	if sp.StreamReplication == nil {
-		return roachpb.TenantID{}, errors.AssertionFailedf("unexpected nil StreamReplication in producer job %d payload", producerJobID)
+		return nil, errors.AssertionFailedf("unexpected nil StreamReplication in producer job %d payload", producerJobID)
 	}
 	if job.Status() != jobs.StatusRunning {
-		return roachpb.TenantID{}, jobIsNotRunningError(producerJobID, job.Status(), "stream events")
+		return nil, jobIsNotRunningError(producerJobID, job.Status(), "stream events")
 	}
 
 	// Validate that the requested spans are a subset of the
@@ -527,10 +578,10 @@ func (s *eventStream) validateProducerJobAndSpec(ctx context.Context) (roachpb.T
 			err := pgerror.Newf(pgcode.InvalidParameterValue, "requested span %s is not contained within the keyspace of source tenant %d",
 				sp,
 				sourceTenantID)
-			return roachpb.TenantID{}, err
+			return nil, err
 		}
 	}
-	return sourceTenantID, nil
+	return sp.StreamReplication, nil
 }
 
 const defaultBatchSize = 1 << 20
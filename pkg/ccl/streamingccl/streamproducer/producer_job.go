@@ -12,6 +12,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
@@ -24,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/isql"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -60,6 +62,34 @@ func makeProducerJobRecord(
 	}
 }
 
+// makeProducerJobRecordForTables returns the record of a producer job that
+// streams the given spans of the named tables of a tenant.
+func makeProducerJobRecordForTables(
+	registry *jobs.Registry,
+	tenantID roachpb.TenantID,
+	tableNames []string,
+	spans roachpb.Spans,
+	tombstoneTableIDs descpb.IDs,
+	timeout time.Duration,
+	user username.SQLUsername,
+	ptsID uuid.UUID,
+) jobs.Record {
+	return jobs.Record{
+		JobID:       registry.MakeJobID(),
+		Description: fmt.Sprintf("Logical replication stream producer for %s", strings.Join(tableNames, ", ")),
+		Username:    user,
+		Details: jobspb.StreamReplicationDetails{
+			ProtectedTimestampRecordID: ptsID,
+			Spans:                      spans,
+			TenantID:                   tenantID,
+			TombstoneTableIDs:          tombstoneTableIDs,
+		},
+		Progress: jobspb.StreamReplicationProgress{
+			Expiration: timeutil.Now().Add(timeout),
+		},
+	}
+}
+
 type producerJobResumer struct {
 	job *jobs.Job
 
@@ -13,12 +13,15 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/replicationutils"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprotectedts"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/mtinfopb"
 	"github.com/cockroachdb/cockroach/pkg/repstream/streampb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -26,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
 	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
@@ -56,8 +60,16 @@ func jobIsNotRunningError(id jobspb.JobID, status jobs.Status, op string) error
 //
 // 1. Tracks the liveness of the replication stream consumption.
 // 2. Updates the protected timestamp for spans being replicated.
+//
+// If the request identifies the tenant this tenant was itself replicated
+// from, the stream starts at the cutover timestamp of that replication so
+// that the former source can catch up without an initial scan.
 func startReplicationProducerJob(
-	ctx context.Context, evalCtx *eval.Context, txn isql.Txn, tenantName roachpb.TenantName,
+	ctx context.Context,
+	evalCtx *eval.Context,
+	txn isql.Txn,
+	tenantName roachpb.TenantName,
+	req streampb.ReplicationProducerRequest,
 ) (streampb.ReplicationProducerSpec, error) {
 	execConfig := evalCtx.Planner.ExecutorConfig().(*sql.ExecutorConfig)
 
@@ -71,6 +83,30 @@ func startReplicationProducerJob(
 	}
 	tenantID := tenantRecord.ID
 
+	statementTime := hlc.Timestamp{
+		WallTime: evalCtx.GetStmtTimestamp().UnixNano(),
+	}
+	startTime := statementTime
+	if !req.ClusterID.Equal(uuid.Nil) {
+		startTime, err = failbackStartTime(tenantRecord, req)
+		if err != nil {
+			return streampb.ReplicationProducerSpec{}, err
+		}
+		// The protected timestamp record written below only protects the
+		// history since the cutover if it has not been garbage collected yet.
+		if err := replicationutils.CheckReadableAt(ctx, execConfig.DB,
+			makeTenantSpan(tenantID), startTime); err != nil {
+			if errors.HasType(err, (*kvpb.BatchTimestampBeforeGCError)(nil)) {
+				return streampb.ReplicationProducerSpec{}, errors.WithHint(
+					pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+						"cannot replicate virtual cluster %q from its cutover time %s, which is before its garbage collection threshold",
+						tenantRecord.Name, startTime),
+					"Replicate it into a new virtual cluster with CREATE VIRTUAL CLUSTER ... FROM REPLICATION OF instead.")
+			}
+			return streampb.ReplicationProducerSpec{}, err
+		}
+	}
+
 	registry := execConfig.JobRegistry
 	timeout := streamingccl.StreamReplicationJobLivenessTimeout.Get(&evalCtx.Settings.SV)
 	ptsID := uuid.MakeV4()
@@ -81,12 +117,9 @@ func startReplicationProducerJob(
 	}
 
 	ptp := execConfig.ProtectedTimestampProvider.WithTxn(txn)
-	statementTime := hlc.Timestamp{
-		WallTime: evalCtx.GetStmtTimestamp().UnixNano(),
-	}
 	deprecatedSpansToProtect := roachpb.Spans{makeTenantSpan(tenantID)}
 	targetToProtect := ptpb.MakeTenantsTarget([]roachpb.TenantID{roachpb.MustMakeTenantID(tenantID)})
-	pts := jobsprotectedts.MakeRecord(ptsID, int64(jr.JobID), statementTime,
+	pts := jobsprotectedts.MakeRecord(ptsID, int64(jr.JobID), startTime,
 		deprecatedSpansToProtect, jobsprotectedts.Jobs, targetToProtect)
 
 	if err := ptp.Protect(ctx, pts); err != nil {
@@ -94,10 +127,124 @@ func startReplicationProducerJob(
 	}
 	return streampb.ReplicationProducerSpec{
 		StreamID:             streampb.StreamID(jr.JobID),
-		ReplicationStartTime: statementTime,
+		ReplicationStartTime: startTime,
+		SourceClusterID:      execConfig.NodeInfo.LogicalClusterID(),
+		SourceTenantID:       roachpb.MustMakeTenantID(tenantID),
+	}, nil
+}
+
+// startReplicationProducerJobForTables initializes a replication stream
+// producer job that, like startReplicationProducerJob, tracks the liveness of
+// the stream and protects the replicated spans, but whose spans are the
+// primary indexes of the tables in the current tenant named in the request.
+// The returned spec includes the descriptors of the tables, which the
+// consumer needs to decode the streamed rows.
+func startReplicationProducerJobForTables(
+	ctx context.Context,
+	evalCtx *eval.Context,
+	txn isql.Txn,
+	req streampb.ReplicationProducerRequest,
+) (streampb.ReplicationProducerSpec, error) {
+	execConfig := evalCtx.Planner.ExecutorConfig().(*sql.ExecutorConfig)
+
+	if !kvserver.RangefeedEnabled.Get(&evalCtx.Settings.SV) {
+		return streampb.ReplicationProducerSpec{}, errors.Errorf("kv.rangefeed.enabled must be true to start a replication job")
+	}
+	if len(req.TableNames) == 0 {
+		return streampb.ReplicationProducerSpec{}, pgerror.Newf(pgcode.InvalidParameterValue,
+			"no tables to replicate")
+	}
+
+	var tableIDs, tombstoneTableIDs descpb.IDs
+	var spans roachpb.Spans
+	tableDescs := make([]descpb.TableDescriptor, 0, len(req.TableNames))
+	for _, name := range req.TableNames {
+		tn, err := parser.ParseQualifiedTableName(name)
+		if err != nil {
+			return streampb.ReplicationProducerSpec{}, err
+		}
+		id, err := evalCtx.Planner.ResolveTableName(ctx, tn)
+		if err != nil {
+			return streampb.ReplicationProducerSpec{}, err
+		}
+		desc, err := descs.FromTxn(txn).ByID(txn.KV()).WithoutNonPublic().Get().Table(ctx, descpb.ID(id))
+		if err != nil {
+			return streampb.ReplicationProducerSpec{}, err
+		}
+		if !desc.IsPhysicalTable() || desc.IsSequence() {
+			return streampb.ReplicationProducerSpec{}, pgerror.Newf(pgcode.WrongObjectType,
+				"%q is not a table", name)
+		}
+		db, err := descs.FromTxn(txn).ByID(txn.KV()).WithoutNonPublic().Get().Database(ctx, desc.GetParentID())
+		if err != nil {
+			return streampb.ReplicationProducerSpec{}, err
+		}
+		// The tombstone table is created here rather than left to a logical
+		// replication job into the table, so that the deletions such a job
+		// applies are filtered out of this stream no matter which of the two
+		// was created first.
+		tombstoneTableID, err := replicationutils.CreateTombstoneTable(ctx, txn, txn.KV(),
+			db.GetName(), desc.GetID())
+		if err != nil {
+			return streampb.ReplicationProducerSpec{}, err
+		}
+		tableIDs = append(tableIDs, desc.GetID())
+		tombstoneTableIDs = append(tombstoneTableIDs, tombstoneTableID)
+		spans = append(spans, desc.PrimaryIndexSpan(evalCtx.Codec))
+		tableDescs = append(tableDescs, *desc.TableDesc())
+	}
+
+	startTime := hlc.Timestamp{
+		WallTime: evalCtx.GetStmtTimestamp().UnixNano(),
+	}
+	registry := execConfig.JobRegistry
+	timeout := streamingccl.StreamReplicationJobLivenessTimeout.Get(&evalCtx.Settings.SV)
+	ptsID := uuid.MakeV4()
+
+	jr := makeProducerJobRecordForTables(registry, evalCtx.Codec.TenantID, req.TableNames, spans,
+		tombstoneTableIDs, timeout, evalCtx.SessionData().User(), ptsID)
+	if _, err := registry.CreateAdoptableJobWithTxn(ctx, jr, jr.JobID, txn); err != nil {
+		return streampb.ReplicationProducerSpec{}, err
+	}
+
+	ptp := execConfig.ProtectedTimestampProvider.WithTxn(txn)
+	// The tombstone tables are protected as well, since they are read at the
+	// time of the streamed deletions.
+	pts := jobsprotectedts.MakeRecord(ptsID, int64(jr.JobID), startTime,
+		spans, jobsprotectedts.Jobs, ptpb.MakeSchemaObjectsTarget(append(tableIDs, tombstoneTableIDs...)))
+	if err := ptp.Protect(ctx, pts); err != nil {
+		return streampb.ReplicationProducerSpec{}, err
+	}
+	return streampb.ReplicationProducerSpec{
+		StreamID:             streampb.StreamID(jr.JobID),
+		ReplicationStartTime: startTime,
+		SourceClusterID:      execConfig.NodeInfo.LogicalClusterID(),
+		SourceTenantID:       evalCtx.Codec.TenantID,
+		TableDescriptors:     tableDescs,
 	}, nil
 }
 
+// failbackStartTime returns the time a failback stream to the tenant that
+// made the request should start at. The requesting tenant must be the one
+// the given tenant was last replicated from, since only then are the two
+// known to be identical as of the cutover timestamp.
+func failbackStartTime(
+	tenantRecord *mtinfopb.TenantInfo, req streampb.ReplicationProducerRequest,
+) (hlc.Timestamp, error) {
+	prev := tenantRecord.PreviousSourceTenant
+	if prev == nil {
+		return hlc.Timestamp{}, pgerror.Newf(pgcode.InvalidParameterValue,
+			"virtual cluster %q was not created by cutting over a replication stream",
+			tenantRecord.Name)
+	}
+	if !prev.ClusterID.Equal(req.ClusterID) || prev.TenantID != req.TenantID {
+		return hlc.Timestamp{}, pgerror.Newf(pgcode.InvalidParameterValue,
+			"virtual cluster %q was last replicated from tenant %s of cluster %s, not tenant %s of cluster %s",
+			tenantRecord.Name, prev.TenantID, prev.ClusterID, req.TenantID, req.ClusterID)
+	}
+	return prev.CutoverTimestamp, nil
+}
+
 // Convert the producer job's status into corresponding replication
 // stream status.
 func convertProducerJobStatusToStreamStatus(
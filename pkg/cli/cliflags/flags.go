@@ -1006,6 +1006,18 @@ For example:
   --store=provisioned-rate=disk-name=nvme1n1
   --store=provisioned-rate=disk-name=sdb:bandwidth=250MiB/s
 
+</PRE>
+The "raft-log-dir" field places the raft log of the store in a separate
+storage engine, with its own write-ahead log, in the given directory. This
+keeps raft log writes and truncations from competing with the compactions of
+the store, in particular when the directory is on a different device. An
+existing store moves its raft log into the directory the first time it is
+started with the field; a store whose raft log was moved can no longer be
+started without it. For example:
+<PRE>
+
+  --store=path=/mnt/hda1,raft-log-dir=/mnt/nvme1/raft-log
+
 </PRE>
 Commas are forbidden in all values, since they are used to separate fields.
 Also, if you use equal signs in the file path to a store, you must use the
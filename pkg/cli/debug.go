@@ -39,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/gc"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvstorage"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -463,6 +464,11 @@ func runDebugRangeData(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	// The raft state of stores with a separate raft log engine is not in the
+	// store's engine.
+	if err := kvstorage.CheckRaftLogEngine(context.Background(), db, db); err != nil {
+		return err
+	}
 
 	rangeID, err := parseRangeID(args[1])
 	if err != nil {
@@ -749,6 +755,11 @@ func runDebugRaftLog(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	// The raft state of stores with a separate raft log engine is not in the
+	// store's engine.
+	if err := kvstorage.CheckRaftLogEngine(context.Background(), db, db); err != nil {
+		return err
+	}
 
 	rangeID, err := parseRangeID(args[1])
 	if err != nil {
@@ -1348,6 +1359,7 @@ var debugCmds = []*cobra.Command{
 	debugMergeLogsCmd,
 	debugListFilesCmd,
 	debugResetQuorumCmd,
+	debugEncryptionReEncryptCmd,
 	debugSendKVBatchCmd,
 	debugRecoverCmd,
 }
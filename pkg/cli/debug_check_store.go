@@ -226,6 +226,9 @@ func checkStoreRaftState(
 	if err != nil {
 		return err
 	}
+	if err := kvstorage.CheckRaftLogEngine(ctx, db, db); err != nil {
+		return err
+	}
 
 	// MVCCIterate over the entire range-id-local space.
 	start := roachpb.Key(keys.LocalRangeIDPrefix)
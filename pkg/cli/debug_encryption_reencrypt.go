@@ -0,0 +1,100 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/clierrorplus"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/spf13/cobra"
+)
+
+var debugEncryptionReEncryptCmd = &cobra.Command{
+	Use:   "encryption-reencrypt [store ID]",
+	Short: "rewrite the files of a store which are encrypted under a retired key",
+	Long: `
+Rewrite the files of the given store on the target node which are still
+encrypted under a data key generated under a previous store key, so that
+they are encrypted under the active store key. The node must be running
+with encryption-at-rest enabled on the store.
+
+The store key is rotated by restarting the node with a new key passed to
+--enterprise-encryption. Once this command completes, no data in the store
+depends on the previous store key any more, and old-key=plain may be used
+on the next restart.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: clierrorplus.MaybeDecorateError(runDebugEncryptionReEncrypt),
+}
+
+func runDebugEncryptionReEncrypt(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storeID, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil {
+		return err
+	}
+
+	cc, finish, err := getClientGRPCConn(ctx, serverCfg)
+	if err != nil {
+		log.Errorf(ctx, "connection to server failed: %v", err)
+		return err
+	}
+	defer finish()
+
+	stream, err := kvserver.NewPerStoreClient(cc).ReEncryptEngine(ctx, &kvserver.ReEncryptEngineRequest{
+		StoreRequestHeader: kvserver.StoreRequestHeader{StoreID: roachpb.StoreID(storeID)},
+	})
+	if err != nil {
+		return err
+	}
+
+	// A response is streamed for every rewritten file, so the progress is
+	// printed at most once a second.
+	var last *kvserver.ReEncryptEngineResponse
+	var lastPrinted time.Time
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		last = resp
+		if now := timeutil.Now(); now.Sub(lastPrinted) >= time.Second {
+			lastPrinted = now
+			printReEncryptionProgress(resp)
+		}
+	}
+	if last == nil {
+		return fmt.Errorf("store %d did not report any progress", storeID)
+	}
+	printReEncryptionProgress(last)
+	fmt.Println("ok")
+	return nil
+}
+
+func printReEncryptionProgress(resp *kvserver.ReEncryptEngineResponse) {
+	fmt.Printf("rewritten %d/%d files (%s/%s)\n",
+		resp.RewrittenFiles, resp.TotalFiles,
+		humanizeutil.IBytes(int64(resp.RewrittenBytes)), humanizeutil.IBytes(int64(resp.TotalBytes)))
+}
@@ -322,7 +322,7 @@ func runDebugDeadReplicaCollect(cmd *cobra.Command, args []string) error {
 				"Check cluster health and retry the operation.")
 		}
 	} else {
-		var stores []storage.Engine
+		var stores, raftLogEngines []storage.Engine
 		for _, storeSpec := range debugRecoverCollectInfoOpts.Stores.Specs {
 			db, err := OpenEngine(storeSpec.Path, stopper, storage.MustExist, storage.ReadOnly)
 			if err != nil {
@@ -331,9 +331,18 @@ func runDebugDeadReplicaCollect(cmd *cobra.Command, args []string) error {
 					"Ensure that store path is correct and that it is not used by another process.")
 			}
 			stores = append(stores, db)
+			logEng := db
+			if storeSpec.RaftLogPath != "" {
+				if logEng, err = OpenEngine(storeSpec.RaftLogPath, stopper, storage.MustExist, storage.ReadOnly); err != nil {
+					return errors.WithHint(errors.Wrapf(err,
+						"failed to open raft log engine at path %q", storeSpec.RaftLogPath),
+						"Ensure that raft log path is correct and that it is not used by another process.")
+				}
+			}
+			raftLogEngines = append(raftLogEngines, logEng)
 		}
 		var err error
-		replicaInfo, stats, err = loqrecovery.CollectStoresReplicaInfo(ctx, stores)
+		replicaInfo, stats, err = loqrecovery.CollectStoresReplicaInfo(ctx, stores, raftLogEngines)
 		if err != nil {
 			return errors.Wrapf(err, "failed to collect replica info from local stores")
 		}
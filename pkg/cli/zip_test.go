@@ -72,6 +72,7 @@ table_name NOT IN (
 	'cluster_contended_keys',
 	'cluster_contended_indexes',
 	'cluster_contended_tables',
+	'cluster_hot_keys',
 	'cluster_inflight_traces',
 	'cross_db_references',
 	'databases',
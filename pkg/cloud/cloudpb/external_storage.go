@@ -52,6 +52,9 @@ func (m *ExternalStorage) AccessIsWithExplicitAuth() bool {
 		// Arbitrary network endpoints may be accessible only via the node and thus
 		// make use of its implicit access to them.
 		return false
+	case ExternalStorageProvider_sftp:
+		// Like HTTP, the server may be accessible only via the node's network.
+		return false
 	case ExternalStorageProvider_nodelocal:
 		// The node's local filesystem is obviously accessed implicitly as the node.
 		return false
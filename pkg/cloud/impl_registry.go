@@ -126,6 +126,15 @@ func RegisterExternalStorageProvider(
 	}
 }
 
+// RegisterRedactedParams registers query parameters that should be redacted
+// from URIs whenever they are displayed to a user. It is used by providers that
+// do not register an ExternalStorage implementation, such as KMS-only ones.
+func RegisterRedactedParams(redactedParams map[string]struct{}) {
+	for param := range redactedParams {
+		redactedQueryParams[param] = struct{}{}
+	}
+}
+
 // ExternalStorageConfFromURI generates an ExternalStorage config from a URI string.
 func ExternalStorageConfFromURI(
 	path string, user username.SQLUsername,
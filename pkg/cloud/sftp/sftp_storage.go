@@ -0,0 +1,529 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/ioctx"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+	sftpclient "github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	scheme = "sftp"
+
+	// PrivateKeyParam is the query parameter for the base64-encoded PEM private
+	// key used to authenticate with the server.
+	PrivateKeyParam = "SFTP_PRIVATE_KEY"
+	// KnownHostsParam is the query parameter for the base64-encoded contents of
+	// a known_hosts file that lists the host keys the server may present.
+	KnownHostsParam = "SFTP_KNOWN_HOSTS"
+
+	defaultPort = "22"
+
+	// partialFileMarker is part of the name of files that are still being
+	// written, which are renamed to their final name once complete.
+	partialFileMarker = ".partial-"
+)
+
+func parseSFTPURL(_ cloud.ExternalStorageURIContext, uri *url.URL) (cloudpb.ExternalStorage, error) {
+	sftpURL := cloud.ConsumeURL{URL: uri}
+	if uri.Host == "" {
+		return cloudpb.ExternalStorage{}, errors.Newf("host component of sftp URI must be set: %s",
+			uri.Redacted())
+	}
+	if uri.User == nil || uri.User.Username() == "" {
+		return cloudpb.ExternalStorage{}, errors.Newf("user component of sftp URI must be set: %s",
+			uri.Redacted())
+	}
+	password, _ := uri.User.Password()
+
+	conf := cloudpb.ExternalStorage{}
+	conf.Provider = cloudpb.ExternalStorageProvider_sftp
+	conf.SFTPConfig = &cloudpb.ExternalStorage_SFTP{
+		Host:       uri.Host,
+		User:       uri.User.Username(),
+		Path:       uri.Path,
+		Password:   password,
+		PrivateKey: sftpURL.ConsumeParam(PrivateKeyParam),
+		KnownHosts: sftpURL.ConsumeParam(KnownHostsParam),
+	}
+
+	// Validate that all the passed in parameters are supported.
+	if unknownParams := sftpURL.RemainingQueryParams(); len(unknownParams) > 0 {
+		return cloudpb.ExternalStorage{}, errors.Errorf(
+			`unknown SFTP query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+	return conf, nil
+}
+
+type sftpStorage struct {
+	conf     *cloudpb.ExternalStorage_SFTP
+	ioConf   base.ExternalIODirConfig
+	settings *cluster.Settings
+
+	ssh    *ssh.Client
+	client *sftpclient.Client
+}
+
+var _ cloud.ExternalStorage = &sftpStorage{}
+
+func makeSFTPStorage(
+	ctx context.Context, args cloud.ExternalStorageContext, dest cloudpb.ExternalStorage,
+) (cloud.ExternalStorage, error) {
+	telemetry.Count("external-io.sftp")
+	conf := dest.SFTPConfig
+	if conf == nil {
+		return nil, errors.Errorf("sftp storage requested but info missing")
+	}
+
+	// The host key of the server is always verified, since the credentials
+	// would otherwise be sent to whoever answers at its address.
+	if conf.KnownHosts == "" {
+		return nil, errors.Errorf("%s must be set to verify the host key of the sftp server",
+			KnownHostsParam)
+	}
+	knownHosts, err := base64.StdEncoding.DecodeString(conf.KnownHosts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", KnownHostsParam)
+	}
+	hostKeyCallback, err := makeHostKeyCallback(knownHosts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", KnownHostsParam)
+	}
+
+	var auth []ssh.AuthMethod
+	if conf.PrivateKey != "" {
+		key, err := base64.StdEncoding.DecodeString(conf.PrivateKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding %s", PrivateKeyParam)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", PrivateKeyParam)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if conf.Password != "" {
+		auth = append(auth, ssh.Password(conf.Password))
+	}
+	if len(auth) == 0 {
+		return nil, errors.Errorf("a password or %s must be set to authenticate with the sftp server",
+			PrivateKeyParam)
+	}
+
+	addr := conf.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultPort)
+	}
+	timeout := cloud.Timeout.Get(&args.Settings.SV)
+	sshConf := &ssh.ClientConfig{
+		User:            conf.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}
+	d := net.Dialer{Timeout: timeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to sftp server %s", addr)
+	}
+	// ssh.ClientConfig.Timeout only applies to ssh.Dial, so the handshake and
+	// the start of the sftp session are bounded by a deadline on the
+	// connection instead, which is cleared once the session is established.
+	if err := conn.SetDeadline(timeutil.Now().Add(timeout)); err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrapf(err, "setting deadline on connection to %s", addr)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, sshConf)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrapf(err, "establishing ssh connection to %s", addr)
+	}
+	sshClient := ssh.NewClient(c, chans, reqs)
+	client, err := sftpclient.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, errors.Wrap(err, "starting sftp session")
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = client.Close()
+		_ = sshClient.Close()
+		return nil, errors.Wrapf(err, "clearing deadline on connection to %s", addr)
+	}
+
+	return &sftpStorage{
+		conf:     conf,
+		ioConf:   args.IOConf,
+		settings: args.Settings,
+		ssh:      sshClient,
+		client:   client,
+	}, nil
+}
+
+// makeHostKeyCallback returns a callback that accepts the host keys that the
+// given known_hosts file lists for the host. Host patterns may be plain or
+// hashed, but wildcards are not supported.
+func makeHostKeyCallback(knownHosts []byte) (ssh.HostKeyCallback, error) {
+	type entry struct {
+		revoked bool
+		hosts   []string
+		key     ssh.PublicKey
+	}
+	var entries []entry
+	for rest := knownHosts; len(bytes.TrimSpace(rest)) > 0; {
+		marker, hosts, key, _, next, err := ssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		rest = next
+		switch marker {
+		case "":
+			entries = append(entries, entry{hosts: hosts, key: key})
+		case "revoked":
+			entries = append(entries, entry{revoked: true, hosts: hosts, key: key})
+		default:
+			return nil, errors.Newf("unsupported known_hosts marker @%s", marker)
+		}
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		host := knownhosts.Normalize(hostname)
+		var found bool
+		for _, e := range entries {
+			if !hostMatches(e.hosts, host) || !bytes.Equal(e.key.Marshal(), key.Marshal()) {
+				continue
+			}
+			if e.revoked {
+				return errors.Newf("host key of sftp server %s is revoked", hostname)
+			}
+			found = true
+		}
+		if !found {
+			return errors.Newf("host key %s of sftp server %s is not in %s",
+				ssh.FingerprintSHA256(key), hostname, KnownHostsParam)
+		}
+		return nil
+	}, nil
+}
+
+// hostMatches returns true if one of the host patterns of a known_hosts entry
+// matches the normalized host.
+func hostMatches(patterns []string, host string) bool {
+	for _, p := range patterns {
+		if p == host {
+			return true
+		}
+		// Hashed patterns are |1|<base64 salt>|<base64 HMAC-SHA1 of the host>.
+		if parts := strings.Split(p, "|"); len(parts) == 4 && parts[0] == "" && parts[1] == "1" {
+			salt, err := base64.StdEncoding.DecodeString(parts[2])
+			if err != nil {
+				continue
+			}
+			want, err := base64.StdEncoding.DecodeString(parts[3])
+			if err != nil {
+				continue
+			}
+			mac := hmac.New(sha1.New, salt)
+			_, _ = mac.Write([]byte(host))
+			if hmac.Equal(mac.Sum(nil), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *sftpStorage) Conf() cloudpb.ExternalStorage {
+	return cloudpb.ExternalStorage{
+		Provider:   cloudpb.ExternalStorageProvider_sftp,
+		SFTPConfig: s.conf,
+	}
+}
+
+func (s *sftpStorage) ExternalIOConf() base.ExternalIODirConfig {
+	return s.ioConf
+}
+
+func (s *sftpStorage) RequiresExternalIOAccounting() bool { return true }
+
+func (s *sftpStorage) Settings() *cluster.Settings {
+	return s.settings
+}
+
+func (s *sftpStorage) path(basename string) string {
+	return path.Join(s.conf.Path, basename)
+}
+
+func (s *sftpStorage) ReadFile(
+	ctx context.Context, basename string, opts cloud.ReadOptions,
+) (_ ioctx.ReadCloserCtx, fileSize int64, _ error) {
+	// The sftp client does not take a context, so it is checked between the
+	// requests to the server instead.
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	p := s.path(basename)
+	f, err := s.client.Open(p)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			// nolint:errwrap
+			err = errors.Wrapf(
+				errors.Wrapf(cloud.ErrFileDoesNotExist, "sftp file %q does not exist", p),
+				"%v",
+				err.Error(),
+			)
+		}
+		return nil, 0, err
+	}
+	if !opts.NoFileSize {
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, 0, err
+		}
+		fileSize = info.Size()
+	}
+	if opts.Offset != 0 {
+		if _, err := f.Seek(opts.Offset, io.SeekStart); err != nil {
+			_ = f.Close()
+			return nil, 0, err
+		}
+	}
+	return &sftpReader{f: f}, fileSize, nil
+}
+
+// sftpReader reads a file from the sftp server, checking the context of each
+// read before reading.
+type sftpReader struct {
+	f *sftpclient.File
+}
+
+var _ ioctx.ReadCloserCtx = &sftpReader{}
+
+func (r *sftpReader) Read(ctx context.Context, p []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.f.Read(p)
+}
+
+func (r *sftpReader) Close(ctx context.Context) error {
+	return r.f.Close()
+}
+
+// Writer returns a writer that writes to a partial file next to the requested
+// one, which is renamed to the requested name when the writer is closed, so
+// that a file that is only partially written, e.g. because the writing job
+// failed, is never read.
+func (s *sftpStorage) Writer(ctx context.Context, basename string) (io.WriteCloser, error) {
+	p := s.path(basename)
+	if err := s.client.MkdirAll(path.Dir(p)); err != nil {
+		return nil, errors.Wrapf(err, "creating directory of %q", p)
+	}
+	partial := p + partialFileMarker + uuid.MakeV4().Short()
+	f, err := s.client.Create(partial)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating %q", partial)
+	}
+	return &sftpWriter{ctx: ctx, client: s.client, f: f, partial: partial, dest: p}, nil
+}
+
+type sftpWriter struct {
+	ctx     context.Context
+	client  *sftpclient.Client
+	f       *sftpclient.File
+	partial string
+	dest    string
+	err     error
+}
+
+func (w *sftpWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if err := w.ctx.Err(); err != nil {
+		w.err = err
+		return 0, err
+	}
+	n, err := w.f.Write(p)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (w *sftpWriter) Close() error {
+	err := w.f.Close()
+	if err == nil {
+		err = w.err
+	}
+	if err == nil {
+		err = w.ctx.Err()
+	}
+	if err == nil {
+		// PosixRename replaces an existing file, where a plain rename fails.
+		err = w.client.PosixRename(w.partial, w.dest)
+	}
+	if err != nil {
+		_ = w.client.Remove(w.partial)
+		return errors.Wrapf(err, "writing %q", w.dest)
+	}
+	return nil
+}
+
+func (s *sftpStorage) List(ctx context.Context, prefix, delim string, fn cloud.ListingFn) error {
+	dest := cloud.JoinPathPreservingTrailingSlash(s.conf.Path, prefix)
+
+	// Walk the directory of the prefix unless the prefix itself is one.
+	root := dest
+	if info, err := s.client.Stat(dest); err != nil || !info.IsDir() {
+		root = path.Dir(dest)
+	}
+
+	var res []string
+	walker := s.client.Walk(root)
+	for walker.Step() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := walker.Err(); err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return errors.Wrap(err, "unable to list files in sftp directory")
+		}
+		p := walker.Path()
+		if walker.Stat().IsDir() {
+			if p == root {
+				continue
+			}
+			// Only directories under the prefix can contain matching files.
+			if !strings.HasPrefix(p, dest) {
+				walker.SkipDir()
+				continue
+			}
+			// All files in a directory whose path relative to the prefix contains
+			// the delimiter are grouped into the same result, so the directory
+			// is only searched for a single file rather than walked.
+			if delim == "" {
+				continue
+			}
+			rel := strings.TrimPrefix(p, dest) + "/"
+			if i := strings.Index(rel, delim); i >= 0 {
+				walker.SkipDir()
+				ok, err := s.hasFile(ctx, p)
+				if err != nil {
+					return err
+				}
+				if ok {
+					res = append(res, rel[:i+len(delim)])
+				}
+			}
+			continue
+		}
+		if !strings.HasPrefix(p, dest) || strings.Contains(path.Base(p), partialFileMarker) {
+			continue
+		}
+		res = append(res, strings.TrimPrefix(p, dest))
+	}
+
+	// Sort results so that we can group as we go.
+	sort.Strings(res)
+	var prevPrefix string
+	for _, f := range res {
+		if delim != "" {
+			if i := strings.Index(f, delim); i >= 0 {
+				f = f[:i+len(delim)]
+			}
+			if f == prevPrefix {
+				continue
+			}
+			prevPrefix = f
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasFile returns true if the directory contains a complete file, at any
+// depth. It stops at the first one it finds.
+func (s *sftpStorage) hasFile(ctx context.Context, dir string) (bool, error) {
+	walker := s.client.Walk(dir)
+	for walker.Step() {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if err := walker.Err(); err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return false, errors.Wrap(err, "unable to list files in sftp directory")
+		}
+		if !walker.Stat().IsDir() && !strings.Contains(path.Base(walker.Path()), partialFileMarker) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *sftpStorage) Delete(ctx context.Context, basename string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.client.Remove(s.path(basename))
+}
+
+func (s *sftpStorage) Size(ctx context.Context, basename string) (int64, error) {
+	p := s.path(basename)
+	info, err := s.client.Stat(p)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return 0, errors.Wrapf(cloud.ErrFileDoesNotExist, "sftp file %q does not exist", p)
+		}
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (s *sftpStorage) Close() error {
+	return errors.CombineErrors(s.client.Close(), s.ssh.Close())
+}
+
+func init() {
+	cloud.RegisterExternalStorageProvider(cloudpb.ExternalStorageProvider_sftp,
+		parseSFTPURL, makeSFTPStorage, cloud.RedactedParams(PrivateKeyParam), scheme)
+}
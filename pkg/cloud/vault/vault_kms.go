@@ -0,0 +1,374 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	kmsScheme = "vault-transit"
+
+	// VaultTokenParam is the query parameter for a Vault token that is allowed
+	// to use the transit key.
+	VaultTokenParam = "VAULT_TOKEN"
+	// VaultRoleIDParam and VaultSecretIDParam are the query parameters for the
+	// credentials used to log in with the AppRole auth method.
+	VaultRoleIDParam   = "VAULT_ROLE_ID"
+	VaultSecretIDParam = "VAULT_SECRET_ID"
+	// VaultAppRoleMountParam is the query parameter for the path at which the
+	// AppRole auth method is mounted. Defaults to "approle".
+	VaultAppRoleMountParam = "VAULT_APPROLE_MOUNT"
+	// VaultMountParam is the query parameter for the path at which the transit
+	// secrets engine is mounted. Defaults to "transit".
+	VaultMountParam = "VAULT_MOUNT"
+	// VaultNamespaceParam is the query parameter for the Vault Enterprise
+	// namespace the mounts live in.
+	VaultNamespaceParam = "VAULT_NAMESPACE"
+	// VaultKeyVersionParam is the query parameter for the version of the
+	// transit key used to encrypt. Defaults to the latest version of the key.
+	// Decryption always uses the version recorded in the ciphertext.
+	VaultKeyVersionParam = "VAULT_KEY_VERSION"
+
+	defaultTransitMount = "transit"
+	defaultAppRoleMount = "approle"
+
+	// vaultTokenEnvVar is the environment variable consulted for the token
+	// when implicit auth is requested, matching the Vault CLI.
+	vaultTokenEnvVar = "VAULT_TOKEN"
+)
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeVaultTransitKMS, kmsScheme)
+	cloud.RegisterRedactedParams(cloud.RedactedParams(VaultTokenParam, VaultSecretIDParam))
+}
+
+type kmsURIParams struct {
+	auth         string
+	token        string
+	roleID       string
+	secretID     string
+	appRoleMount string
+	mount        string
+	namespace    string
+	keyVersion   int
+}
+
+// resolveKMSURIParams parses the `kmsURI` for all the supported KMS parameters.
+func resolveKMSURIParams(kmsURI cloud.ConsumeURL) (kmsURIParams, error) {
+	params := kmsURIParams{
+		auth:         kmsURI.ConsumeParam(cloud.AuthParam),
+		token:        kmsURI.ConsumeParam(VaultTokenParam),
+		roleID:       kmsURI.ConsumeParam(VaultRoleIDParam),
+		secretID:     kmsURI.ConsumeParam(VaultSecretIDParam),
+		appRoleMount: kmsURI.ConsumeParam(VaultAppRoleMountParam),
+		mount:        kmsURI.ConsumeParam(VaultMountParam),
+		namespace:    kmsURI.ConsumeParam(VaultNamespaceParam),
+	}
+	if v := kmsURI.ConsumeParam(VaultKeyVersionParam); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil || version < 1 {
+			return kmsURIParams{}, errors.Errorf(
+				"%s must be a positive integer, got %q", VaultKeyVersionParam, v)
+		}
+		params.keyVersion = version
+	}
+	if params.mount == "" {
+		params.mount = defaultTransitMount
+	}
+	if params.appRoleMount == "" {
+		params.appRoleMount = defaultAppRoleMount
+	}
+
+	// Validate that all the passed in parameters are supported.
+	if unknownParams := kmsURI.RemainingQueryParams(); len(unknownParams) > 0 {
+		return kmsURIParams{}, errors.Errorf(
+			`unknown KMS query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+
+	return params, nil
+}
+
+type vaultTransitKMS struct {
+	client    *http.Client
+	addr      *url.URL
+	mount     string
+	keyName   string
+	namespace string
+	// keyVersion is the version of the key used to encrypt, or 0 for the
+	// latest version.
+	keyVersion int
+
+	// roleID and secretID are set if the token is obtained, and renewed, by
+	// logging in with AppRole.
+	roleID, secretID string
+	appRoleMount     string
+
+	mu struct {
+		syncutil.Mutex
+		token string
+	}
+}
+
+var _ cloud.KMS = &vaultTransitKMS{}
+
+// MakeVaultTransitKMS is the factory method which returns a configured,
+// ready-to-use KMS backed by the transit secrets engine of a HashiCorp Vault
+// server. The URI is of the form
+// vault-transit://<host>:<port>/<key-name>?VAULT_TOKEN=<token>.
+func MakeVaultTransitKMS(ctx context.Context, uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	telemetry.Count("external-io.kms.vault-transit")
+	if env.KMSConfig().DisableOutbound {
+		return nil, errors.New("external IO must be enabled to use Vault KMS")
+	}
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if kmsURI.Host == "" {
+		return nil, errors.New("host component of the Vault KMS URI must be set")
+	}
+	keyName := strings.TrimPrefix(kmsURI.Path, "/")
+	if keyName == "" {
+		return nil, errors.Newf("path component of the KMS cannot be empty; must contain the transit key name")
+	}
+	if strings.Contains(keyName, "/") {
+		return nil, errors.Newf("transit key name %q must not contain '/'; use %s to set the mount path",
+			keyName, VaultMountParam)
+	}
+
+	kmsURIParams, err := resolveKMSURIParams(cloud.ConsumeURL{URL: kmsURI})
+	if err != nil {
+		return nil, err
+	}
+
+	k := &vaultTransitKMS{
+		addr:         &url.URL{Scheme: "https", Host: kmsURI.Host},
+		mount:        strings.Trim(kmsURIParams.mount, "/"),
+		keyName:      keyName,
+		namespace:    kmsURIParams.namespace,
+		keyVersion:   kmsURIParams.keyVersion,
+		appRoleMount: strings.Trim(kmsURIParams.appRoleMount, "/"),
+	}
+
+	switch kmsURIParams.auth {
+	case "", cloud.AuthParamSpecified:
+		switch {
+		case kmsURIParams.token != "" && (kmsURIParams.roleID != "" || kmsURIParams.secretID != ""):
+			return nil, errors.Errorf("only one of %s or %s and %s may be set",
+				VaultTokenParam, VaultRoleIDParam, VaultSecretIDParam)
+		case kmsURIParams.token != "":
+			k.mu.token = kmsURIParams.token
+		case kmsURIParams.roleID != "" && kmsURIParams.secretID != "":
+			k.roleID, k.secretID = kmsURIParams.roleID, kmsURIParams.secretID
+		default:
+			return nil, errors.Errorf(
+				"%s, or %s and %s, must be set if %q is %q",
+				VaultTokenParam,
+				VaultRoleIDParam,
+				VaultSecretIDParam,
+				cloud.AuthParam,
+				cloud.AuthParamSpecified,
+			)
+		}
+	case cloud.AuthParamImplicit:
+		if env.KMSConfig().DisableImplicitCredentials {
+			return nil, errors.New(
+				"implicit credentials disallowed for vault due to --external-io-disable-implicit-credentials flag")
+		}
+		k.mu.token = os.Getenv(vaultTokenEnvVar)
+		if k.mu.token == "" {
+			return nil, errors.Errorf("implicit auth requires the %s environment variable to be set",
+				vaultTokenEnvVar)
+		}
+	default:
+		return nil, errors.Errorf("unsupported value %s for %s", kmsURIParams.auth, cloud.AuthParam)
+	}
+
+	k.client, err = cloud.MakeHTTPClient(env.ClusterSettings())
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// MasterKeyID returns the mount path and name of the transit key. It
+// deliberately excludes the key version: the version used to encrypt is
+// recorded in the ciphertext, so data encrypted before a key rotation is still
+// found, and decrypted, under the same ID.
+func (k *vaultTransitKMS) MasterKeyID() string {
+	return path.Join(k.mount, k.keyName)
+}
+
+// Encrypt implements the cloud.KMS interface.
+func (k *vaultTransitKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	req := struct {
+		Plaintext  string `json:"plaintext"`
+		KeyVersion int    `json:"key_version,omitempty"`
+	}{
+		Plaintext:  base64.StdEncoding.EncodeToString(data),
+		KeyVersion: k.keyVersion,
+	}
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := k.transit(ctx, "encrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	// The ciphertext is of the form vault:v<version>:<base64>, which is what
+	// Decrypt must hand back to Vault.
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// Decrypt implements the cloud.KMS interface.
+func (k *vaultTransitKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	req := struct {
+		Ciphertext string `json:"ciphertext"`
+	}{
+		Ciphertext: string(data),
+	}
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := k.transit(ctx, "decrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding plaintext returned by vault")
+	}
+	return plaintext, nil
+}
+
+// Close implements the cloud.KMS interface.
+func (k *vaultTransitKMS) Close() error {
+	k.client.CloseIdleConnections()
+	return nil
+}
+
+// transit performs the given transit operation against the key, logging in
+// with AppRole first if needed. A token obtained with AppRole may expire, so a
+// permission error is retried once with a fresh token.
+func (k *vaultTransitKMS) transit(ctx context.Context, op string, req, resp interface{}) error {
+	opPath := path.Join(k.mount, op, k.keyName)
+	for attempt := 0; ; attempt++ {
+		token, err := k.token(ctx, attempt > 0 /* refresh */)
+		if err != nil {
+			return cloud.KMSInaccessible(err)
+		}
+		status, err := k.do(ctx, opPath, token, req, resp)
+		if err == nil {
+			return nil
+		}
+		if status == http.StatusForbidden && k.roleID != "" && attempt == 0 {
+			continue
+		}
+		return cloud.KMSInaccessible(errors.Wrapf(err, "vault transit %s with key %s", op, k.MasterKeyID()))
+	}
+}
+
+// token returns the token used to authenticate to Vault. If the KMS uses
+// AppRole, a new token is obtained if there is none or refresh is set.
+func (k *vaultTransitKMS) token(ctx context.Context, refresh bool) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.roleID == "" || (k.mu.token != "" && !refresh) {
+		return k.mu.token, nil
+	}
+
+	req := struct {
+		RoleID   string `json:"role_id"`
+		SecretID string `json:"secret_id"`
+	}{
+		RoleID:   k.roleID,
+		SecretID: k.secretID,
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if _, err := k.do(ctx, path.Join("auth", k.appRoleMount, "login"), "", req, &resp); err != nil {
+		return "", errors.Wrap(err, "vault approle login")
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("vault approle login did not return a token")
+	}
+	k.mu.token = resp.Auth.ClientToken
+	return k.mu.token, nil
+}
+
+// do sends a request to the given Vault API path and decodes the response into
+// resp. It returns the HTTP status code of the response, if one was received.
+func (k *vaultTransitKMS) do(
+	ctx context.Context, apiPath, token string, req, resp interface{},
+) (int, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return 0, err
+	}
+	u := *k.addr
+	u.Path = "/v1/" + apiPath
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("X-Vault-Token", token)
+	}
+	if k.namespace != "" {
+		httpReq.Header.Set("X-Vault-Namespace", k.namespace)
+	}
+
+	httpResp, err := k.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return httpResp.StatusCode, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		msg := strings.TrimSpace(string(respBody))
+		if json.Unmarshal(respBody, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			msg = strings.Join(vaultErr.Errors, "; ")
+		}
+		return httpResp.StatusCode, errors.Newf("%s: %s", httpResp.Status, msg)
+	}
+	if err := json.Unmarshal(respBody, resp); err != nil {
+		return httpResp.StatusCode, errors.Wrap(err, "decoding vault response")
+	}
+	return httpResp.StatusCode, nil
+}
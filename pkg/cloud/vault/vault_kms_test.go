@@ -0,0 +1,428 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/externalconn"
+	"github.com/cockroachdb/cockroach/pkg/cloud/externalconn/connectionpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRoleID   = "test-role"
+	testSecretID = "test-secret"
+	testKey      = "backups"
+)
+
+// fakeVault is a stand-in for a dev-mode Vault server that implements the
+// subset of the transit secrets engine and AppRole auth method used by the
+// KMS. Ciphertexts use the same vault:v<version>:<base64> format as Vault.
+type fakeVault struct {
+	*httptest.Server
+
+	mu struct {
+		syncutil.Mutex
+		// keys maps a transit key name to its versions; version N is at index
+		// N-1.
+		keys map[string][][]byte
+		// tokens is the set of valid tokens.
+		tokens     map[string]bool
+		loginCount int
+		rootToken  string
+	}
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	v := &fakeVault{}
+	v.mu.keys = map[string][][]byte{}
+	v.mu.tokens = map[string]bool{}
+	v.mu.rootToken = "root"
+	v.mu.tokens[v.mu.rootToken] = true
+	v.rotate(t, testKey)
+	v.Server = httptest.NewTLSServer(http.HandlerFunc(v.handle))
+	return v
+}
+
+// rotate adds a new version to the given transit key.
+func (v *fakeVault) rotate(t *testing.T, name string) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mu.keys[name] = append(v.mu.keys[name], key)
+}
+
+// revokeLoginTokens revokes all tokens obtained by logging in, as if they had
+// expired.
+func (v *fakeVault) revokeLoginTokens() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for token := range v.mu.tokens {
+		if token != v.mu.rootToken {
+			delete(v.mu.tokens, token)
+		}
+	}
+}
+
+func (v *fakeVault) logins() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.mu.loginCount
+}
+
+func (v *fakeVault) handle(w http.ResponseWriter, r *http.Request) {
+	writeErr := func(code int, msg string) {
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {msg}})
+	}
+	var req map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(http.StatusBadRequest, err.Error())
+		return
+	}
+	str := func(k string) string { s, _ := req[k].(string); return s }
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		if str("role_id") != testRoleID || str("secret_id") != testSecretID {
+			writeErr(http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		v.mu.loginCount++
+		token := fmt.Sprintf("approle-%d", v.mu.loginCount)
+		v.mu.tokens[token] = true
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]string{"client_token": token},
+		})
+		return
+	}
+
+	if !v.mu.tokens[r.Header.Get("X-Vault-Token")] {
+		writeErr(http.StatusForbidden, "permission denied")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/transit/"), "/")
+	if len(parts) != 2 {
+		writeErr(http.StatusNotFound, "unsupported path")
+		return
+	}
+	versions, ok := v.mu.keys[parts[1]]
+	if !ok {
+		writeErr(http.StatusBadRequest, "encryption key not found")
+		return
+	}
+	aead := func(version int) cipher.AEAD {
+		block, err := aes.NewCipher(versions[version-1])
+		if err != nil {
+			panic(err)
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(err)
+		}
+		return gcm
+	}
+
+	switch parts[0] {
+	case "encrypt":
+		version := len(versions)
+		if kv, ok := req["key_version"].(float64); ok {
+			version = int(kv)
+		}
+		if version < 1 || version > len(versions) {
+			writeErr(http.StatusBadRequest, "invalid key version")
+			return
+		}
+		plaintext, err := base64.StdEncoding.DecodeString(str("plaintext"))
+		if err != nil {
+			writeErr(http.StatusBadRequest, err.Error())
+			return
+		}
+		gcm := aead(version)
+		nonce := make([]byte, gcm.NonceSize())
+		_, _ = rand.Read(nonce)
+		sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{
+				"ciphertext": fmt.Sprintf("vault:v%d:%s", version, base64.StdEncoding.EncodeToString(sealed)),
+			},
+		})
+	case "decrypt":
+		fields := strings.SplitN(str("ciphertext"), ":", 3)
+		if len(fields) != 3 || fields[0] != "vault" || !strings.HasPrefix(fields[1], "v") {
+			writeErr(http.StatusBadRequest, "invalid ciphertext")
+			return
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(fields[1], "v"))
+		if err != nil || version < 1 || version > len(versions) {
+			writeErr(http.StatusBadRequest, "invalid key version")
+			return
+		}
+		sealed, err := base64.StdEncoding.DecodeString(fields[2])
+		gcm := aead(version)
+		if err != nil || len(sealed) < gcm.NonceSize() {
+			writeErr(http.StatusBadRequest, "invalid ciphertext")
+			return
+		}
+		plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+		if err != nil {
+			writeErr(http.StatusBadRequest, "cipher: message authentication failed")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)},
+		})
+	default:
+		writeErr(http.StatusNotFound, "unsupported path")
+	}
+}
+
+// kmsEnv returns a KMSEnv whose cluster settings trust the fake server's
+// certificate.
+func (v *fakeVault) kmsEnv(t *testing.T) *cloud.TestKMSEnv {
+	st := cluster.MakeTestingClusterSettings()
+	u := st.MakeUpdater()
+	require.NoError(t, u.Set(context.Background(), "cloudstorage.http.custom_ca", settings.EncodedValue{
+		Value: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: v.Certificate().Raw})),
+		Type:  "s",
+	}))
+	return &cloud.TestKMSEnv{Settings: st, ExternalIOConfig: &base.ExternalIODirConfig{}}
+}
+
+func (v *fakeVault) uri(key string, params url.Values) string {
+	u := url.URL{
+		Scheme:   kmsScheme,
+		Host:     strings.TrimPrefix(v.URL, "https://"),
+		Path:     "/" + key,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+func TestEncryptDecryptVault(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	v := newFakeVault(t)
+	defer v.Close()
+	env := v.kmsEnv(t)
+
+	t.Run("token auth", func(t *testing.T) {
+		uri := v.uri(testKey, url.Values{VaultTokenParam: []string{"root"}})
+		cloud.KMSEncryptDecrypt(t, uri, env)
+	})
+
+	t.Run("approle auth", func(t *testing.T) {
+		uri := v.uri(testKey, url.Values{
+			VaultRoleIDParam:   []string{testRoleID},
+			VaultSecretIDParam: []string{testSecretID},
+		})
+		cloud.KMSEncryptDecrypt(t, uri, env)
+	})
+}
+
+func TestVaultKeyRotation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	v := newFakeVault(t)
+	defer v.Close()
+	env := v.kmsEnv(t)
+
+	open := func(params url.Values) cloud.KMS {
+		params.Set(VaultTokenParam, "root")
+		k, err := cloud.KMSFromURI(ctx, v.uri(testKey, params), env)
+		require.NoError(t, err)
+		return k
+	}
+	k := open(url.Values{})
+	defer func() { require.NoError(t, k.Close()) }()
+
+	before, err := k.Encrypt(ctx, []byte("before"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(before), "vault:v1:"))
+	id := k.MasterKeyID()
+
+	v.rotate(t, testKey)
+
+	// New data is encrypted with the latest version, and data encrypted with
+	// the previous version can still be decrypted under the same master key ID.
+	after, err := k.Encrypt(ctx, []byte("after"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(after), "vault:v2:"))
+	require.Equal(t, id, k.MasterKeyID())
+	for ciphertext, plaintext := range map[string]string{string(before): "before", string(after): "after"} {
+		decrypted, err := k.Decrypt(ctx, []byte(ciphertext))
+		require.NoError(t, err)
+		require.Equal(t, plaintext, string(decrypted))
+	}
+
+	// The version used to encrypt can be pinned.
+	pinned := open(url.Values{VaultKeyVersionParam: []string{"1"}})
+	defer func() { require.NoError(t, pinned.Close()) }()
+	ciphertext, err := pinned.Encrypt(ctx, []byte("pinned"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(ciphertext), "vault:v1:"))
+	require.Equal(t, id, pinned.MasterKeyID())
+}
+
+func TestVaultAppRoleRelogin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	v := newFakeVault(t)
+	defer v.Close()
+
+	uri := v.uri(testKey, url.Values{
+		VaultRoleIDParam:   []string{testRoleID},
+		VaultSecretIDParam: []string{testSecretID},
+	})
+	k, err := cloud.KMSFromURI(ctx, uri, v.kmsEnv(t))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, k.Close()) }()
+
+	// Logging in is deferred until the KMS is used.
+	require.Equal(t, 0, v.logins())
+	ciphertext, err := k.Encrypt(ctx, []byte("hello"))
+	require.NoError(t, err)
+	require.Equal(t, 1, v.logins())
+
+	// An expired token is replaced transparently.
+	v.revokeLoginTokens()
+	plaintext, err := k.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(plaintext))
+	require.Equal(t, 2, v.logins())
+}
+
+func TestVaultKMSInaccessibleError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	v := newFakeVault(t)
+	defer v.Close()
+	env := v.kmsEnv(t)
+
+	for _, tc := range []struct {
+		name   string
+		key    string
+		params url.Values
+		errRE  string
+	}{
+		{
+			name:   "incorrect-token",
+			key:    testKey,
+			params: url.Values{VaultTokenParam: []string{"garbage"}},
+			errRE:  "permission denied",
+		},
+		{
+			name: "incorrect-approle",
+			key:  testKey,
+			params: url.Values{
+				VaultRoleIDParam:   []string{testRoleID},
+				VaultSecretIDParam: []string{"garbage"},
+			},
+			errRE: "invalid role or secret ID",
+		},
+		{
+			name:   "incorrect-key",
+			key:    testKey + "-non-existent",
+			params: url.Values{VaultTokenParam: []string{"root"}},
+			errRE:  "encryption key not found",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := cloud.KMSFromURI(ctx, v.uri(tc.key, tc.params), env)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, k.Close()) }()
+
+			_, err = k.Encrypt(ctx, []byte("test123"))
+			require.True(t, cloud.IsKMSInaccessible(err), "error not kms inaccessible: %v", err)
+			require.Regexp(t, tc.errRE, err)
+
+			_, err = k.Decrypt(ctx, []byte("vault:v1:dGVzdDEyMw=="))
+			require.True(t, cloud.IsKMSInaccessible(err), "error not kms inaccessible: %v", err)
+			require.Regexp(t, tc.errRE, err)
+		})
+	}
+}
+
+func TestVaultKMSURIParams(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	env := &cloud.TestKMSEnv{
+		Settings:         cluster.MakeTestingClusterSettings(),
+		ExternalIOConfig: &base.ExternalIODirConfig{},
+	}
+
+	for _, tc := range []struct {
+		uri   string
+		errRE string
+	}{
+		{uri: "vault-transit://vault:8200/?VAULT_TOKEN=t", errRE: "path component of the KMS cannot be empty"},
+		{uri: "vault-transit://vault:8200/a/b?VAULT_TOKEN=t", errRE: "must not contain '/'"},
+		{uri: "vault-transit://vault:8200/k", errRE: "VAULT_TOKEN, or VAULT_ROLE_ID and VAULT_SECRET_ID, must be set"},
+		{uri: "vault-transit://vault:8200/k?VAULT_ROLE_ID=r", errRE: "VAULT_TOKEN, or VAULT_ROLE_ID and VAULT_SECRET_ID, must be set"},
+		{uri: "vault-transit://vault:8200/k?VAULT_TOKEN=t&VAULT_ROLE_ID=r", errRE: "only one of"},
+		{uri: "vault-transit://vault:8200/k?VAULT_TOKEN=t&VAULT_KEY_VERSION=0", errRE: "must be a positive integer"},
+		{uri: "vault-transit://vault:8200/k?VAULT_TOKEN=t&FOO=bar", errRE: "unknown KMS query parameters: FOO"},
+	} {
+		t.Run(tc.uri, func(t *testing.T) {
+			_, err := cloud.KMSFromURI(ctx, tc.uri, env)
+			require.Regexp(t, tc.errRE, err)
+		})
+	}
+
+	t.Run("implicit auth disallowed", func(t *testing.T) {
+		_, err := cloud.KMSFromURI(ctx, "vault-transit://vault:8200/k?AUTH=implicit", &cloud.TestKMSEnv{
+			Settings:         cluster.MakeTestingClusterSettings(),
+			ExternalIOConfig: &base.ExternalIODirConfig{DisableImplicitCredentials: true},
+		})
+		require.Regexp(t, "implicit credentials disallowed", err)
+	})
+
+	t.Run("redaction", func(t *testing.T) {
+		redacted, err := cloud.RedactKMSURI(
+			"vault-transit://vault:8200/k?VAULT_ROLE_ID=r&VAULT_SECRET_ID=s&VAULT_TOKEN=t")
+		require.NoError(t, err)
+		require.Equal(t,
+			"vault-transit://vault:8200/redacted?VAULT_ROLE_ID=r&VAULT_SECRET_ID=redacted&VAULT_TOKEN=redacted",
+			redacted)
+	})
+}
+
+func TestVaultKMSConnection(t *testing.T) {
+	require.Equal(t, connectionpb.ConnectionProvider_vault_transit_kms,
+		externalconn.ProviderForURI("vault-transit://vault:8200/k"))
+}
@@ -499,6 +499,24 @@ const (
 	// role for all existing functions.
 	V23_2_GrantExecuteToPublic
 
+	// V23_2_ReadIndexFollowerReads is the version from which all nodes can
+	// evaluate ReadIndexRequests, which followers send to the leaseholder to
+	// serve reads above the closed timestamp.
+	V23_2_ReadIndexFollowerReads
+
+	// V23_2_WitnessReplicas is the version from which zone configurations may
+	// set num_witnesses and ranges may carry WITNESS replicas.
+	V23_2_WitnessReplicas
+
+	// V23_2_StorageTTL is the version from which zone configurations may set
+	// storage_ttl_seconds, and GC requests may expire live keys.
+	V23_2_StorageTTL
+
+	// V23_2_ChangefeedConfluentJSONSchema is the version from which changefeeds
+	// may set confluent_json_schema to register JSON Schemas with the schema
+	// registry.
+	V23_2_ChangefeedConfluentJSONSchema
+
 	// *************************************************
 	// Step (1) Add new versions here.
 	// Do not add new versions to a patch release.
@@ -832,6 +850,22 @@ var rawVersionsSingleton = keyedVersions{
 		Key:     V23_2_GrantExecuteToPublic,
 		Version: roachpb.Version{Major: 23, Minor: 1, Internal: 26},
 	},
+	{
+		Key:     V23_2_ReadIndexFollowerReads,
+		Version: roachpb.Version{Major: 23, Minor: 1, Internal: 28},
+	},
+	{
+		Key:     V23_2_WitnessReplicas,
+		Version: roachpb.Version{Major: 23, Minor: 1, Internal: 30},
+	},
+	{
+		Key:     V23_2_StorageTTL,
+		Version: roachpb.Version{Major: 23, Minor: 1, Internal: 32},
+	},
+	{
+		Key:     V23_2_ChangefeedConfluentJSONSchema,
+		Version: roachpb.Version{Major: 23, Minor: 1, Internal: 34},
+	},
 
 	// *************************************************
 	// Step (2): Add new versions here.
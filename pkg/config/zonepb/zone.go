@@ -361,6 +361,10 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	// Witnesses vote alongside the voting replicas, so two voting replicas
+	// plus a witness form a valid (three-member) quorum.
+	hasWitnesses := z.NumWitnesses != nil && *z.NumWitnesses > 0
+
 	if z.NumReplicas != nil {
 		switch {
 		case *z.NumReplicas < 0:
@@ -373,7 +377,7 @@ func (z *ZoneConfig) Validate() error {
 			}
 			return fmt.Errorf("at least one replica is required")
 		case *z.NumReplicas == 2:
-			if !(z.NumVoters != nil && *z.NumVoters > 0) {
+			if !(z.NumVoters != nil && *z.NumVoters > 0) && !hasWitnesses {
 				return fmt.Errorf("at least 3 replicas are required for multi-replica configurations")
 			}
 		}
@@ -385,7 +389,7 @@ func (z *ZoneConfig) Validate() error {
 		switch {
 		case *z.NumVoters <= 0:
 			return fmt.Errorf("at least one voting replica is required")
-		case *z.NumVoters == 2:
+		case *z.NumVoters == 2 && !hasWitnesses:
 			return fmt.Errorf("at least 3 voting replicas are required for multi-replica configurations")
 		}
 		if z.NumReplicas != nil && *z.NumVoters > *z.NumReplicas {
@@ -393,6 +397,21 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	if z.NumWitnesses != nil && *z.NumWitnesses != 0 {
+		if *z.NumWitnesses < 0 {
+			return fmt.Errorf("num_witnesses cannot be negative")
+		}
+		// Witnesses must be a minority of the voting members of the range, or
+		// a quorum could be formed without any replica that applies user writes.
+		numVoters := z.NumVoters
+		if numVoters == nil {
+			numVoters = z.NumReplicas
+		}
+		if numVoters != nil && *numVoters > 0 && *z.NumWitnesses >= *numVoters {
+			return fmt.Errorf("num_witnesses must be less than the number of voting replicas")
+		}
+	}
+
 	if z.RangeMaxBytes != nil && *z.RangeMaxBytes < minRangeMaxBytes {
 		return fmt.Errorf("RangeMaxBytes %d less than minimum allowed %d",
 			*z.RangeMaxBytes, minRangeMaxBytes)
@@ -411,6 +430,9 @@ func (z *ZoneConfig) Validate() error {
 	if z.GC != nil && z.GC.TTLSeconds < 1 {
 		return fmt.Errorf("GC.TTLSeconds %d less than minimum allowed 1", z.GC.TTLSeconds)
 	}
+	if z.StorageTTLSeconds != nil && *z.StorageTTLSeconds < 0 {
+		return fmt.Errorf("storage_ttl_seconds cannot be negative")
+	}
 
 	for _, constraints := range z.Constraints {
 		for _, constraint := range constraints.Constraints {
@@ -559,11 +581,21 @@ func (z *ZoneConfig) InheritFromParent(parent *ZoneConfig) {
 			z.NumVoters = proto.Int32(*parent.NumVoters)
 		}
 	}
+	if z.NumWitnesses == nil {
+		if parent.NumWitnesses != nil {
+			z.NumWitnesses = proto.Int32(*parent.NumWitnesses)
+		}
+	}
 	if z.GlobalReads == nil {
 		if parent.GlobalReads != nil {
 			z.GlobalReads = proto.Bool(*parent.GlobalReads)
 		}
 	}
+	if z.StorageTTLSeconds == nil {
+		if parent.StorageTTLSeconds != nil {
+			z.StorageTTLSeconds = proto.Int32(*parent.StorageTTLSeconds)
+		}
+	}
 	if z.RangeMinBytes == nil {
 		if parent.RangeMinBytes != nil {
 			z.RangeMinBytes = proto.Int64(*parent.RangeMinBytes)
@@ -608,6 +640,11 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 			if other.NumVoters != nil {
 				z.NumVoters = proto.Int32(*other.NumVoters)
 			}
+		case "num_witnesses":
+			z.NumWitnesses = nil
+			if other.NumWitnesses != nil {
+				z.NumWitnesses = proto.Int32(*other.NumWitnesses)
+			}
 		case "range_min_bytes":
 			z.RangeMinBytes = nil
 			if other.RangeMinBytes != nil {
@@ -629,6 +666,11 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 				tempGC := *other.GC
 				z.GC = &tempGC
 			}
+		case "storage_ttl_seconds":
+			z.StorageTTLSeconds = nil
+			if other.StorageTTLSeconds != nil {
+				z.StorageTTLSeconds = proto.Int32(*other.StorageTTLSeconds)
+			}
 		case "constraints":
 			z.Constraints = other.Constraints
 			z.InheritedConstraints = other.InheritedConstraints
@@ -697,6 +739,16 @@ func (z *ZoneConfig) DiffWithZone(
 					Field: "num_voters",
 				}, nil
 			}
+		case "num_witnesses":
+			if other.NumWitnesses == nil && z.NumWitnesses == nil {
+				continue
+			}
+			if z.NumWitnesses == nil || other.NumWitnesses == nil ||
+				*z.NumWitnesses != *other.NumWitnesses {
+				return false, DiffWithZoneMismatch{
+					Field: "num_witnesses",
+				}, nil
+			}
 		case "range_min_bytes":
 			if other.RangeMinBytes == nil && z.RangeMinBytes == nil {
 				continue
@@ -736,6 +788,16 @@ func (z *ZoneConfig) DiffWithZone(
 					Field: "gc.ttlseconds",
 				}, nil
 			}
+		case "storage_ttl_seconds":
+			if other.StorageTTLSeconds == nil && z.StorageTTLSeconds == nil {
+				continue
+			}
+			if z.StorageTTLSeconds == nil || other.StorageTTLSeconds == nil ||
+				*z.StorageTTLSeconds != *other.StorageTTLSeconds {
+				return false, DiffWithZoneMismatch{
+					Field: "storage_ttl_seconds",
+				}, nil
+			}
 		case "constraints":
 			if other.Constraints == nil && z.Constraints == nil {
 				continue
@@ -1129,6 +1191,9 @@ func (z *ZoneConfig) toSpanConfig() (roachpb.SpanConfig, error) {
 	sc.RangeMinBytes = *z.RangeMinBytes
 	sc.RangeMaxBytes = *z.RangeMaxBytes
 	sc.GCPolicy.TTLSeconds = z.GC.TTLSeconds
+	if z.StorageTTLSeconds != nil {
+		sc.GCPolicy.StorageTTLSeconds = *z.StorageTTLSeconds
+	}
 
 	// GlobalReads is false by default.
 	if z.GlobalReads != nil {
@@ -1138,6 +1203,9 @@ func (z *ZoneConfig) toSpanConfig() (roachpb.SpanConfig, error) {
 	if z.NumVoters != nil {
 		sc.NumVoters = *z.NumVoters
 	}
+	if z.NumWitnesses != nil {
+		sc.NumWitnesses = *z.NumWitnesses
+	}
 
 	toSpanConfigConstraints := func(src []Constraint) ([]roachpb.Constraint, error) {
 		spanConfigConstraints := make([]roachpb.Constraint, len(src))
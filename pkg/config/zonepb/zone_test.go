@@ -85,6 +85,53 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"",
 		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(3),
+				NumWitnesses:  proto.Int32(-1),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+			},
+			"num_witnesses cannot be negative",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(3),
+				NumVoters:     proto.Int32(2),
+				NumWitnesses:  proto.Int32(2),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+			},
+			"num_witnesses must be less than the number of voting replicas",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:       proto.Int32(3),
+				RangeMaxBytes:     DefaultZoneConfig().RangeMaxBytes,
+				GC:                &GCPolicy{TTLSeconds: 1},
+				StorageTTLSeconds: proto.Int32(-1),
+			},
+			"storage_ttl_seconds cannot be negative",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(3),
+				NumVoters:     proto.Int32(2),
+				NumWitnesses:  proto.Int32(1),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+			},
+			"",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(2),
+				NumWitnesses:  proto.Int32(1),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+			},
+			"",
+		},
 		{
 			ZoneConfig{
 				NumReplicas:   proto.Int32(1),
@@ -202,9 +202,11 @@ type marshalableZoneConfig struct {
 	RangeMinBytes                *int64            `json:"range_min_bytes" yaml:"range_min_bytes"`
 	RangeMaxBytes                *int64            `json:"range_max_bytes" yaml:"range_max_bytes"`
 	GC                           *GCPolicy         `json:"gc"`
+	StorageTTLSeconds            *int32            `json:"storage_ttl_seconds,omitempty" yaml:"storage_ttl_seconds,omitempty"`
 	GlobalReads                  *bool             `json:"global_reads" yaml:"global_reads"`
 	NumReplicas                  *int32            `json:"num_replicas" yaml:"num_replicas"`
 	NumVoters                    *int32            `json:"num_voters" yaml:"num_voters"`
+	NumWitnesses                 *int32            `json:"num_witnesses,omitempty" yaml:"num_witnesses,omitempty"`
 	Constraints                  ConstraintsList   `json:"constraints" yaml:"constraints,flow"`
 	VoterConstraints             ConstraintsList   `json:"voter_constraints" yaml:"voter_constraints,flow"`
 	LeasePreferences             []LeasePreference `json:"lease_preferences" yaml:"lease_preferences,flow"`
@@ -225,6 +227,9 @@ func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
 		tempGC := *c.GC
 		m.GC = &tempGC
 	}
+	if c.StorageTTLSeconds != nil && *c.StorageTTLSeconds != 0 {
+		m.StorageTTLSeconds = proto.Int32(*c.StorageTTLSeconds)
+	}
 	if c.GlobalReads != nil {
 		m.GlobalReads = proto.Bool(*c.GlobalReads)
 	}
@@ -235,6 +240,9 @@ func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
 	if c.NumVoters != nil && *c.NumVoters != 0 {
 		m.NumVoters = proto.Int32(*c.NumVoters)
 	}
+	if c.NumWitnesses != nil && *c.NumWitnesses != 0 {
+		m.NumWitnesses = proto.Int32(*c.NumWitnesses)
+	}
 	// NB: In order to preserve round-trippability, we're directly using
 	// `NullVoterConstraintsIsEmpty` as opposed to calling
 	// `c.InheritedVoterConstraints()`. This is copacetic as long as the value is
@@ -264,6 +272,9 @@ func zoneConfigFromMarshalable(m marshalableZoneConfig, c ZoneConfig) ZoneConfig
 		tempGC := *m.GC
 		c.GC = &tempGC
 	}
+	if m.StorageTTLSeconds != nil {
+		c.StorageTTLSeconds = proto.Int32(*m.StorageTTLSeconds)
+	}
 	if m.GlobalReads != nil {
 		c.GlobalReads = proto.Bool(*m.GlobalReads)
 	}
@@ -275,6 +286,9 @@ func zoneConfigFromMarshalable(m marshalableZoneConfig, c ZoneConfig) ZoneConfig
 	if m.NumVoters != nil {
 		c.NumVoters = proto.Int32(*m.NumVoters)
 	}
+	if m.NumWitnesses != nil {
+		c.NumWitnesses = proto.Int32(*m.NumWitnesses)
+	}
 	c.VoterConstraints = m.VoterConstraints.Constraints
 	c.NullVoterConstraintsIsEmpty = !m.VoterConstraints.Inherited
 	if m.LeasePreferences != nil {
@@ -81,6 +81,12 @@ func StoreHLCUpperBoundKey() roachpb.Key {
 	return MakeStoreKey(localStoreHLCUpperBoundSuffix, nil)
 }
 
+// StoreRaftLogEngineKey returns the store-local key that marks a store whose
+// raft logs are kept in a separate raft log engine.
+func StoreRaftLogEngineKey() roachpb.Key {
+	return MakeStoreKey(localStoreRaftLogEngineSuffix, nil)
+}
+
 // StoreNodeTombstoneKey returns the key for storing a node tombstone for nodeID.
 func StoreNodeTombstoneKey(nodeID roachpb.NodeID) roachpb.Key {
 	return MakeStoreKey(localStoreNodeTombstoneSuffix, encoding.EncodeUint32Ascending(nil, uint32(nodeID)))
@@ -87,6 +87,7 @@ type rangeFeedConfig struct {
 	useMuxRangeFeed bool
 	overSystemTable bool
 	withDiff        bool
+	predicate       *kvpb.RangeFeedPredicate
 	rangeObserver   func(ForEachRangeFn)
 
 	knobs struct {
@@ -136,6 +137,14 @@ func WithDiff() RangeFeedOption {
 	})
 }
 
+// WithPredicate asks the servers to only send the value events that satisfy
+// the predicate. Servers may still send other events.
+func WithPredicate(predicate *kvpb.RangeFeedPredicate) RangeFeedOption {
+	return optionFunc(func(c *rangeFeedConfig) {
+		c.predicate = predicate
+	})
+}
+
 // WithRangeObserver is called when the rangefeed starts with a function that
 // can be used to iterate over all the ranges.
 func WithRangeObserver(observer func(ForEachRangeFn)) RangeFeedOption {
@@ -805,6 +814,7 @@ func (ds *DistSender) singleRangeFeed(
 	}()
 
 	args := makeRangeFeedRequest(span, desc.RangeID, cfg.overSystemTable, startAfter, cfg.withDiff)
+	args.Predicate = cfg.predicate
 	transport, err := newTransportForRange(ctx, desc, ds)
 	if err != nil {
 		return args.Timestamp, err
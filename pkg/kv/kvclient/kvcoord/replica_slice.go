@@ -41,7 +41,7 @@ const (
 	// VOTER_FULL).
 	OnlyPotentialLeaseholders ReplicaSliceFilter = iota
 	// AllExtantReplicas prescribes that the ReplicaSlice should include all
-	// replicas that are not LEARNERs, VOTER_OUTGOING, or
+	// replicas that are not LEARNERs, WITNESSes, VOTER_OUTGOING, or
 	// VOTER_DEMOTING_{LEARNER/NON_VOTER}.
 	AllExtantReplicas
 	// AllReplicas prescribes that the ReplicaSlice should include all replicas.
@@ -90,8 +90,8 @@ func NewReplicaSlice(
 		return true
 	}
 
-	// Learner replicas won't serve reads/writes, so we'll send only to the voters
-	// and non-voting replicas. This is just an optimization to save a network
+	// Learner and witness replicas won't serve reads/writes, so we'll send only
+	// to the voters and non-voting replicas. This is just an optimization to save a network
 	// hop, everything would still work if we had `All` here.
 	var replicas []roachpb.ReplicaDescriptor
 	switch filter {
@@ -39,6 +39,7 @@ type config struct {
 	useRowTimestampInInitialScan bool
 
 	withDiff             bool
+	predicate            *kvpb.RangeFeedPredicate
 	onUnrecoverableError OnUnrecoverableError
 	onCheckpoint         OnCheckpoint
 	onFrontierAdvance    OnFrontierAdvance
@@ -144,6 +145,16 @@ func WithDiff(withDiff bool) Option {
 	})
 }
 
+// WithPredicate makes an option to ask the servers to only send the value
+// events that satisfy the predicate. The predicate does not apply to the
+// initial scan, and servers may still send other events, so the OnValue
+// callback must check the events it receives itself.
+func WithPredicate(predicate *kvpb.RangeFeedPredicate) Option {
+	return optionFunc(func(c *config) {
+		c.predicate = predicate
+	})
+}
+
 // WithRetry configures the retry options for the rangefeed.
 func WithRetry(options retry.Options) Option {
 	return optionFunc(func(c *config) {
@@ -297,6 +297,9 @@ func (f *RangeFeed) run(ctx context.Context, frontier *span.Frontier) {
 	if f.withDiff {
 		rangefeedOpts = append(rangefeedOpts, kvcoord.WithDiff())
 	}
+	if f.predicate != nil {
+		rangefeedOpts = append(rangefeedOpts, kvcoord.WithPredicate(f.predicate))
+	}
 
 	for i := 0; r.Next(); i++ {
 		ts := frontier.Frontier()
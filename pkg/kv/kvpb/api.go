@@ -901,6 +901,9 @@ func (*BarrierRequest) Method() Method { return Barrier }
 // Method implements the Request interface.
 func (*IsSpanEmptyRequest) Method() Method { return IsSpanEmpty }
 
+// Method implements the Request interface.
+func (*ReadIndexRequest) Method() Method { return ReadIndex }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -1189,6 +1192,12 @@ func (r *IsSpanEmptyRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *ReadIndexRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewLockingGet returns a Request initialized to get the value at key. A lock
 // corresponding to the supplied lock strength and durability is acquired on the
 // key, if it exists.
@@ -1600,6 +1609,12 @@ func (gcr *GCRequest) flags() flag {
 	if gcr.ClearRange != nil {
 		flags |= isAlone
 	}
+	// Expiring live keys is published on rangefeeds at the request's
+	// timestamp, so the request must be evaluated above the closed timestamp
+	// and any reads that have observed the keys.
+	if len(gcr.ExpiredKeys) > 0 || len(gcr.ExpiredSpans) > 0 {
+		flags |= appliesTSCache
+	}
 	return flags
 }
 
@@ -1690,6 +1705,12 @@ func (*QueryResolvedTimestampRequest) flags() flag {
 func (*BarrierRequest) flags() flag     { return isWrite | isRange }
 func (*IsSpanEmptyRequest) flags() flag { return isRead | isRange }
 
+// ReadIndexRequest is unsplittable because the lease applied index that it
+// returns is only meaningful for a single range.
+func (*ReadIndexRequest) flags() flag {
+	return isRead | isRange | isAlone | isUnsplittable | updatesTSCache
+}
+
 // IsParallelCommit returns whether the EndTxn request is attempting to perform
 // a parallel commit. See txn_interceptor_committer.go for a discussion about
 // parallel commits.
@@ -1834,6 +1855,18 @@ func (rc ReplicationChanges) NonVoterRemovals() []roachpb.ReplicationTarget {
 	return rc.byType(roachpb.REMOVE_NON_VOTER)
 }
 
+// WitnessAdditions returns a slice of all contained replication changes that
+// add witnesses.
+func (rc ReplicationChanges) WitnessAdditions() []roachpb.ReplicationTarget {
+	return rc.byType(roachpb.ADD_WITNESS)
+}
+
+// WitnessRemovals returns a slice of all contained replication changes that
+// remove witnesses.
+func (rc ReplicationChanges) WitnessRemovals() []roachpb.ReplicationTarget {
+	return rc.byType(roachpb.REMOVE_WITNESS)
+}
+
 // Changes returns the changes requested by this AdminChangeReplicasRequest, taking
 // the deprecated method of doing so into account.
 func (acrr *AdminChangeReplicasRequest) Changes() []ReplicationChange {
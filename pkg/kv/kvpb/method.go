@@ -174,6 +174,10 @@ const (
 	// IsSpanEmpty is a non-transaction read request used to determine whether
 	// a span contains any keys whatsoever (garbage or otherwise).
 	IsSpanEmpty
+	// ReadIndex waits for the writes that a read on a follower must observe to
+	// apply on the leaseholder, and returns the lease applied index that the
+	// follower must reach before serving the read.
+	ReadIndex
 	// MaxMethod is the maximum method.
 	MaxMethod Method = iota - 1
 	// NumMethods represents the total number of API methods.
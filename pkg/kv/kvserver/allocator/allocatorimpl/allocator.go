@@ -140,11 +140,15 @@ const (
 	AllocatorConsiderRebalance
 	AllocatorRangeUnavailable
 	AllocatorFinalizeAtomicReplicationChange
+	AllocatorAddWitness
+	AllocatorRemoveWitness
+	AllocatorRemoveDeadWitness
+	AllocatorRemoveDecommissioningWitness
 )
 
 // Add indicates an action adding a replica.
 func (a AllocatorAction) Add() bool {
-	return a == AllocatorAddVoter || a == AllocatorAddNonVoter
+	return a == AllocatorAddVoter || a == AllocatorAddNonVoter || a == AllocatorAddWitness
 }
 
 // Replace indicates an action replacing a dead or decommissioning replica.
@@ -162,10 +166,14 @@ func (a AllocatorAction) Remove() bool {
 		a == AllocatorRemoveDeadVoter ||
 		a == AllocatorRemoveDeadNonVoter ||
 		a == AllocatorRemoveDecommissioningVoter ||
-		a == AllocatorRemoveDecommissioningNonVoter
+		a == AllocatorRemoveDecommissioningNonVoter ||
+		a == AllocatorRemoveWitness ||
+		a == AllocatorRemoveDeadWitness ||
+		a == AllocatorRemoveDecommissioningWitness
 }
 
-// TargetReplicaType returns that the action is for a voter or non-voter replica.
+// TargetReplicaType returns that the action is for a voter, non-voter or
+// witness replica.
 func (a AllocatorAction) TargetReplicaType() TargetReplicaType {
 	var t TargetReplicaType
 	if a == AllocatorRemoveVoter ||
@@ -182,6 +190,11 @@ func (a AllocatorAction) TargetReplicaType() TargetReplicaType {
 		a == AllocatorReplaceDecommissioningNonVoter ||
 		a == AllocatorRemoveDecommissioningNonVoter {
 		t = NonVoterTarget
+	} else if a == AllocatorAddWitness ||
+		a == AllocatorRemoveWitness ||
+		a == AllocatorRemoveDeadWitness ||
+		a == AllocatorRemoveDecommissioningWitness {
+		t = WitnessTarget
 	}
 	return t
 }
@@ -193,17 +206,21 @@ func (a AllocatorAction) ReplicaStatus() ReplicaStatus {
 	if a == AllocatorRemoveVoter ||
 		a == AllocatorRemoveNonVoter ||
 		a == AllocatorAddVoter ||
-		a == AllocatorAddNonVoter {
+		a == AllocatorAddNonVoter ||
+		a == AllocatorAddWitness ||
+		a == AllocatorRemoveWitness {
 		s = Alive
 	} else if a == AllocatorReplaceDeadVoter ||
 		a == AllocatorReplaceDeadNonVoter ||
 		a == AllocatorRemoveDeadVoter ||
-		a == AllocatorRemoveDeadNonVoter {
+		a == AllocatorRemoveDeadNonVoter ||
+		a == AllocatorRemoveDeadWitness {
 		s = Dead
 	} else if a == AllocatorReplaceDecommissioningVoter ||
 		a == AllocatorReplaceDecommissioningNonVoter ||
 		a == AllocatorRemoveDecommissioningVoter ||
-		a == AllocatorRemoveDecommissioningNonVoter {
+		a == AllocatorRemoveDecommissioningNonVoter ||
+		a == AllocatorRemoveDecommissioningWitness {
 		s = Decommissioning
 	}
 	return s
@@ -227,6 +244,10 @@ var allocatorActionNames = map[AllocatorAction]string{
 	AllocatorConsiderRebalance:               "consider rebalance",
 	AllocatorRangeUnavailable:                "range unavailable",
 	AllocatorFinalizeAtomicReplicationChange: "finalize conf change",
+	AllocatorAddWitness:                      "add witness",
+	AllocatorRemoveWitness:                   "remove witness",
+	AllocatorRemoveDeadWitness:               "remove dead witness",
+	AllocatorRemoveDecommissioningWitness:    "remove decommissioning witness",
 }
 
 func (a AllocatorAction) String() string {
@@ -259,6 +280,14 @@ func (a AllocatorAction) Priority() float64 {
 		return 900
 	case AllocatorRemoveVoter:
 		return 800
+	case AllocatorAddWitness:
+		return 780
+	case AllocatorRemoveDeadWitness:
+		return 760
+	case AllocatorRemoveDecommissioningWitness:
+		return 740
+	case AllocatorRemoveWitness:
+		return 720
 	case AllocatorReplaceDeadNonVoter:
 		return 700
 	case AllocatorAddNonVoter:
@@ -278,8 +307,8 @@ func (a AllocatorAction) Priority() float64 {
 	}
 }
 
-// TargetReplicaType indicates whether the target replica is a voter or
-// non-voter.
+// TargetReplicaType indicates whether the target replica is a voter,
+// non-voter or witness.
 type TargetReplicaType int
 
 const (
@@ -288,6 +317,8 @@ const (
 	VoterTarget
 	// NonVoterTarget represents a non-voting target replica.
 	NonVoterTarget
+	// WitnessTarget represents a witness target replica.
+	WitnessTarget
 )
 
 // ReplicaStatus represents whether a replica is currently alive,
@@ -315,6 +346,8 @@ func (t TargetReplicaType) AddChangeType() roachpb.ReplicaChangeType {
 		return roachpb.ADD_VOTER
 	case NonVoterTarget:
 		return roachpb.ADD_NON_VOTER
+	case WitnessTarget:
+		return roachpb.ADD_WITNESS
 	default:
 		panic(fmt.Sprintf("unknown targetReplicaType %d", t))
 	}
@@ -328,6 +361,8 @@ func (t TargetReplicaType) RemoveChangeType() roachpb.ReplicaChangeType {
 		return roachpb.REMOVE_VOTER
 	case NonVoterTarget:
 		return roachpb.REMOVE_NON_VOTER
+	case WitnessTarget:
+		return roachpb.REMOVE_WITNESS
 	default:
 		panic(fmt.Sprintf("unknown targetReplicaType %d", t))
 	}
@@ -339,6 +374,8 @@ func (t TargetReplicaType) String() string {
 		return "voter"
 	case NonVoterTarget:
 		return "non-voter"
+	case WitnessTarget:
+		return "witness"
 	default:
 		panic(fmt.Sprintf("unknown targetReplicaType %d", t))
 	}
@@ -685,6 +722,21 @@ func GetNeededNonVoters(numVoters, zoneConfigNonVoterCount, clusterNodes int) in
 	return need
 }
 
+// GetNeededWitnesses calculates the number of witnesses a range should have
+// given the number of voting replicas the range has and the number of nodes
+// available for up-replication. Like non-voters, witnesses are only placed on
+// nodes that do not already hold a voting replica.
+func GetNeededWitnesses(numVoters, zoneConfigWitnessCount, clusterNodes int) int {
+	need := zoneConfigWitnessCount
+	if clusterNodes-numVoters < need {
+		need = clusterNodes - numVoters
+	}
+	if need < 0 {
+		need = 0 // Must be non-negative.
+	}
+	return need
+}
+
 // WillHaveFragileQuorum determines, based on the number of existing voters,
 // incoming voters, and needed voters, if we will be upreplicating to a state
 // in which we don't have enough needed voters and yet will have a fragile quorum
@@ -762,6 +814,10 @@ func DetermineReplicaToReplaceAndFilter(
 	case NonVoterTarget:
 		existing = nonVoters
 		deadReplicas = deadNonVoterReplicas
+	case WitnessTarget:
+		// Witnesses are only ever added on their own; dead or decommissioning
+		// witnesses are removed by a separate action once a replacement exists.
+		return
 	default:
 		panic(fmt.Sprintf("unknown targetReplicaType: %s", replicaType))
 	}
@@ -853,6 +909,11 @@ func FilterReplicasForAction(
 	if removeIdx >= 0 {
 		replacing = &existing[removeIdx]
 	}
+	if action.TargetReplicaType() == WitnessTarget {
+		// Witnesses are diversified against the range's other quorum
+		// participants; see getReplicasForDiversityCalc.
+		filteredVoters = append(filteredVoters, desc.Replicas().WitnessDescriptors()...)
+	}
 
 	return filteredVoters, filteredNonVoters, replacing, nothingToDo, err
 }
@@ -925,7 +986,7 @@ func (a *Allocator) ComputeAction(
 	}
 
 	return a.computeAction(ctx, storePool, conf, desc.Replicas().VoterDescriptors(),
-		desc.Replicas().NonVoterDescriptors())
+		desc.Replicas().NonVoterDescriptors(), desc.Replicas().WitnessDescriptors())
 }
 
 func (a *Allocator) computeAction(
@@ -934,6 +995,7 @@ func (a *Allocator) computeAction(
 	conf *roachpb.SpanConfig,
 	voterReplicas []roachpb.ReplicaDescriptor,
 	nonVoterReplicas []roachpb.ReplicaDescriptor,
+	witnessReplicas []roachpb.ReplicaDescriptor,
 ) (action AllocatorAction, adjustedPriority float64) {
 	// NB: The ordering of the checks in this method is intentional. The order in
 	// which these actions are returned by this method determines the relative
@@ -942,7 +1004,8 @@ func (a *Allocator) computeAction(
 	// (which influence the replicateQueue's decision of which range it'll pick to
 	// repair/rebalance before the others).
 	//
-	// In broad strokes, we first handle all voting replica-based actions and then
+	// In broad strokes, we first handle all voting replica-based actions, then
+	// the actions pertaining to witnesses (which take part in quorum) and finally
 	// the actions pertaining to non-voting replicas. Within each replica set, we
 	// first handle operations that correspond to repairing/recovering the range.
 	// After that we handle rebalancing related actions, followed by removal
@@ -955,7 +1018,10 @@ func (a *Allocator) computeAction(
 	clusterNodes := storePool.ClusterNodeCount()
 	neededVoters := GetNeededVoters(conf.GetNumVoters(), clusterNodes)
 	desiredQuorum := computeQuorum(neededVoters)
-	quorum := computeQuorum(haveVoters)
+	// Witnesses vote in raft, so they count towards the range's quorum even
+	// though they are never considered for the voter counts above.
+	haveWitnesses := len(witnessReplicas)
+	quorum := computeQuorum(haveVoters + haveWitnesses)
 
 	// TODO(aayush): When haveVoters < neededVoters but we don't have quorum to
 	// actually execute the addition of a new replica, we should be returning a
@@ -979,15 +1045,16 @@ func (a *Allocator) computeAction(
 	// elsewhere (for a regular rebalance or for decommissioning).
 	const includeSuspectAndDrainingStores = true
 	liveVoters, deadVoters := storePool.LiveAndDeadReplicas(voterReplicas, includeSuspectAndDrainingStores)
+	liveWitnesses, deadWitnesses := storePool.LiveAndDeadReplicas(witnessReplicas, includeSuspectAndDrainingStores)
 
-	if len(liveVoters) < quorum {
+	if len(liveVoters)+len(liveWitnesses) < quorum {
 		// Do not take any replacement/removal action if we do not have a quorum of
 		// live voters. If we're correctly assessing the unavailable state of the
 		// range, we also won't be able to add replicas as we try above, but hope
 		// springs eternal.
 		action = AllocatorRangeUnavailable
-		log.KvDistribution.VEventf(ctx, 1, "unable to take action - live voters %v don't meet quorum of %d",
-			liveVoters, quorum)
+		log.KvDistribution.VEventf(ctx, 1, "unable to take action - live voters %v and witnesses %v don't meet quorum of %d",
+			liveVoters, liveWitnesses, quorum)
 		return action, action.Priority()
 	}
 
@@ -1018,7 +1085,7 @@ func (a *Allocator) computeAction(
 	if len(deadVoters) > 0 {
 		// The range has dead replicas, which should be removed immediately.
 		action = AllocatorRemoveDeadVoter
-		adjustedPriority = action.Priority() + float64(quorum-len(liveVoters))
+		adjustedPriority = action.Priority() + float64(quorum-len(liveVoters)-len(liveWitnesses))
 		log.KvDistribution.VEventf(ctx, 3, "%s - dead=%d, live=%d, quorum=%d, priority=%.2f",
 			action, len(deadVoters), len(liveVoters), quorum, adjustedPriority)
 		return action, adjustedPriority
@@ -1045,6 +1112,43 @@ func (a *Allocator) computeAction(
 		return action, adjustedPriority
 	}
 
+	// Witness replica actions follow.
+	//
+	// Witness changes are never combined with other changes, so dead and
+	// decommissioning witnesses are replaced by first adding a new witness and
+	// then removing the old one once the range is over-replicated.
+	neededWitnesses := GetNeededWitnesses(haveVoters, int(conf.NumWitnesses), clusterNodes)
+	decommissioningWitnesses := storePool.DecommissioningReplicas(witnessReplicas)
+	healthyWitnesses := haveWitnesses - len(decommissioningWitnesses) - len(deadWitnesses)
+	if healthyWitnesses < neededWitnesses && haveWitnesses < clusterNodes-haveVoters {
+		action = AllocatorAddWitness
+		log.KvDistribution.VEventf(ctx, 3, "%s - missing witness need=%d, have=%d, priority=%.2f",
+			action, neededWitnesses, healthyWitnesses, action.Priority())
+		return action, action.Priority()
+	}
+
+	if len(deadWitnesses) > 0 {
+		action = AllocatorRemoveDeadWitness
+		log.KvDistribution.VEventf(ctx, 3, "%s - dead=%d, live=%d, priority=%.2f",
+			action, len(deadWitnesses), len(liveWitnesses), action.Priority())
+		return action, action.Priority()
+	}
+
+	if len(decommissioningWitnesses) > 0 {
+		action = AllocatorRemoveDecommissioningWitness
+		log.KvDistribution.VEventf(ctx, 3,
+			"%s - need=%d, have=%d, num_decommissioning=%d, priority=%.2f",
+			action, neededWitnesses, haveWitnesses, len(decommissioningWitnesses), action.Priority())
+		return action, action.Priority()
+	}
+
+	if haveWitnesses > neededWitnesses {
+		action = AllocatorRemoveWitness
+		log.KvDistribution.VEventf(ctx, 3, "%s - need=%d, have=%d, priority=%.2f", action,
+			neededWitnesses, haveWitnesses, action.Priority())
+		return action, action.Priority()
+	}
+
 	// Non-voting replica actions follow.
 	//
 	// Non-voting replica addition / replacement.
@@ -1143,6 +1247,11 @@ func getReplicasForDiversityCalc(
 		return existingVoters
 	case NonVoterTarget:
 		return allExistingReplicas
+	case WitnessTarget:
+		// Witnesses exist to keep quorum through the loss of a locality, so they
+		// are spread out relative to the other quorum participants. Callers
+		// include existing witnesses in `existingVoters`.
+		return existingVoters
 	default:
 		panic(fmt.Sprintf("unsupported targetReplicaType: %v", t))
 	}
@@ -1339,6 +1448,20 @@ func (a *Allocator) AllocateNonVoter(
 	return a.AllocateTarget(ctx, storePool, conf, existingVoters, existingNonVoters, replacing, replicaStatus, NonVoterTarget)
 }
 
+// AllocateWitness returns a suitable store for a new allocation of a witness
+// replica. Nodes already accommodating _any_ existing replicas are ruled out as
+// targets, and the witness is placed to maximize diversity with respect to the
+// range's voters and existing witnesses.
+func (a *Allocator) AllocateWitness(
+	ctx context.Context,
+	storePool storepool.AllocatorStorePool,
+	conf *roachpb.SpanConfig,
+	existingVoters, existingNonVoters, existingWitnesses []roachpb.ReplicaDescriptor,
+) (roachpb.ReplicationTarget, string, error) {
+	quorumReplicas := append(append([]roachpb.ReplicaDescriptor(nil), existingVoters...), existingWitnesses...)
+	return a.AllocateTarget(ctx, storePool, conf, quorumReplicas, existingNonVoters, nil /* replacing */, Alive, WitnessTarget)
+}
+
 // AllocateTargetFromList returns a suitable store for a new allocation of a
 // replica of the given type from the set of candidate stores, with the given
 // existing set of voters and non-voters..
@@ -1420,6 +1543,8 @@ func (a *Allocator) allocateTargetFromList(
 		} else {
 			constraintsChecker = nonVoterConstraintsCheckerForAllocation(analyzedOverallConstraints)
 		}
+	case WitnessTarget:
+		constraintsChecker = witnessConstraintsChecker()
 	default:
 		log.KvDistribution.Fatalf(ctx, "unsupported targetReplicaType: %v", t)
 	}
@@ -1561,6 +1686,8 @@ func (a Allocator) RemoveTarget(
 		)
 	case NonVoterTarget:
 		constraintsChecker = nonVoterConstraintsCheckerForRemoval(analyzedOverallConstraints)
+	case WitnessTarget:
+		constraintsChecker = witnessConstraintsChecker()
 	default:
 		log.KvDistribution.Fatalf(ctx, "unsupported targetReplicaType: %v", t)
 	}
@@ -1659,6 +1786,38 @@ func (a Allocator) RemoveNonVoter(
 	)
 }
 
+// RemoveWitness returns a suitable witness to remove from the provided
+// candidates, preferring the one whose removal least reduces the diversity of
+// the range's quorum.
+func (a Allocator) RemoveWitness(
+	ctx context.Context,
+	storePool storepool.AllocatorStorePool,
+	conf *roachpb.SpanConfig,
+	witnessCandidates []roachpb.ReplicaDescriptor,
+	existingVoters []roachpb.ReplicaDescriptor,
+	existingNonVoters []roachpb.ReplicaDescriptor,
+	existingWitnesses []roachpb.ReplicaDescriptor,
+	options ScorerOptions,
+) (roachpb.ReplicationTarget, string, error) {
+	candidateStoreIDs := make(roachpb.StoreIDSlice, len(witnessCandidates))
+	for i, exist := range witnessCandidates {
+		candidateStoreIDs[i] = exist.StoreID
+	}
+	candidateStoreList, _, _ := storePool.GetStoreListFromIDs(candidateStoreIDs, storepool.StoreFilterNone)
+
+	quorumReplicas := append(append([]roachpb.ReplicaDescriptor(nil), existingVoters...), existingWitnesses...)
+	return a.RemoveTarget(
+		ctx,
+		storePool,
+		conf,
+		candidateStoreList,
+		quorumReplicas,
+		existingNonVoters,
+		WitnessTarget,
+		options,
+	)
+}
+
 // RebalanceTarget returns a suitable store for a rebalance target (of the given
 // type) with required attributes.
 func (a Allocator) RebalanceTarget(
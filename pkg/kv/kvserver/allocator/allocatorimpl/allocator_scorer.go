@@ -1879,6 +1879,15 @@ func nonVoterConstraintsCheckerForAllocation(
 	}
 }
 
+// witnessConstraintsChecker returns a constraintsCheckFn for witness replicas.
+// Witnesses hold no user data, so neither `constraints` nor `voter_constraints`
+// apply to them and every store is a valid (but never necessary) candidate.
+func witnessConstraintsChecker() constraintsCheckFn {
+	return func(s roachpb.StoreDescriptor) (valid, necessary bool) {
+		return true, false
+	}
+}
+
 // voterConstraintsCheckerForRemoval returns a constraintsCheckFn that
 // determines whether an existing voting replica is valid and/or necessary with
 // respect to the `constraints` and `voter_constraints` on the range.
@@ -6933,6 +6933,87 @@ func TestAllocatorComputeActionSuspect(t *testing.T) {
 	}
 }
 
+func TestAllocatorComputeActionWitness(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// Two voters (one per datacenter) plus a witness in a third location.
+	conf := roachpb.SpanConfig{NumReplicas: 2, NumVoters: 2, NumWitnesses: 1}
+	voter := func(id int) roachpb.ReplicaDescriptor {
+		return roachpb.ReplicaDescriptor{
+			StoreID: roachpb.StoreID(id), NodeID: roachpb.NodeID(id), ReplicaID: roachpb.ReplicaID(id),
+		}
+	}
+	witness := func(id int) roachpb.ReplicaDescriptor {
+		r := voter(id)
+		r.Type = roachpb.WITNESS
+		return r
+	}
+
+	testCases := []struct {
+		replicas       []roachpb.ReplicaDescriptor
+		live           []roachpb.StoreID
+		dead           []roachpb.StoreID
+		expectedAction AllocatorAction
+	}{
+		{
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), witness(3)},
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+			expectedAction: AllocatorConsiderRebalance,
+		},
+		{
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2)},
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+			expectedAction: AllocatorAddWitness,
+		},
+		{
+			// A dead witness is replaced before it is removed.
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), witness(3)},
+			live:           []roachpb.StoreID{1, 2, 4},
+			dead:           []roachpb.StoreID{3},
+			expectedAction: AllocatorAddWitness,
+		},
+		{
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), witness(3), witness(4)},
+			live:           []roachpb.StoreID{1, 2, 4},
+			dead:           []roachpb.StoreID{3},
+			expectedAction: AllocatorRemoveDeadWitness,
+		},
+		{
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), witness(3), witness(4)},
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+			expectedAction: AllocatorRemoveWitness,
+		},
+		{
+			// Losing a datacenter leaves the surviving voter and the witness with
+			// a quorum, so the dead voter can be replaced.
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), witness(3)},
+			live:           []roachpb.StoreID{1, 3, 4},
+			dead:           []roachpb.StoreID{2},
+			expectedAction: AllocatorReplaceDeadVoter,
+		},
+		{
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), witness(3)},
+			live:           []roachpb.StoreID{1, 4},
+			dead:           []roachpb.StoreID{2, 3},
+			expectedAction: AllocatorRangeUnavailable,
+		},
+	}
+
+	ctx := context.Background()
+	stopper, _, sp, a, _ := CreateTestAllocator(ctx, 10, false /* deterministic */)
+	defer stopper.Stop(ctx)
+
+	for i, tcase := range testCases {
+		mockStorePool(sp, tcase.live, nil, tcase.dead, nil, nil, nil)
+		desc := roachpb.RangeDescriptor{InternalReplicas: tcase.replicas}
+		action, _ := a.ComputeAction(ctx, sp, &conf, &desc)
+		if tcase.expectedAction != action {
+			t.Errorf("Test case %d expected action %s, got action %s", i, tcase.expectedAction, action)
+		}
+	}
+}
+
 func TestAllocatorComputeActionDecommission(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
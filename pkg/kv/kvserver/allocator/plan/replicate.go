@@ -335,6 +335,23 @@ func (rp ReplicaPlanner) PlanOneChange(
 		op, stats, err = rp.removeDead(ctx, repl, deadVoterReplicas, allocatorimpl.VoterTarget)
 	case allocatorimpl.AllocatorRemoveDeadNonVoter:
 		op, stats, err = rp.removeDead(ctx, repl, deadNonVoterReplicas, allocatorimpl.NonVoterTarget)
+
+	// Witness changes.
+	//
+	// NB: witnesses are never swapped atomically with another replica, so dead
+	// and decommissioning witnesses are only removed after a replacement witness
+	// has been added by AllocatorAddWitness.
+	case allocatorimpl.AllocatorAddWitness:
+		op, stats, err = rp.addWitness(ctx, repl, desc, conf, allocatorPrio)
+	case allocatorimpl.AllocatorRemoveWitness:
+		op, stats, err = rp.removeWitness(ctx, repl, desc, conf)
+	case allocatorimpl.AllocatorRemoveDecommissioningWitness:
+		op, stats, err = rp.removeDecommissioning(ctx, repl, desc, conf, allocatorimpl.WitnessTarget)
+	case allocatorimpl.AllocatorRemoveDeadWitness:
+		_, deadWitnessReplicas := rp.storePool.LiveAndDeadReplicas(
+			desc.Replicas().WitnessDescriptors(), false, /* includeSuspectAndDrainingStores */
+		)
+		op, stats, err = rp.removeDead(ctx, repl, deadWitnessReplicas, allocatorimpl.WitnessTarget)
 	// Rebalance replicas.
 	//
 	// NB: Rebalacing attempts to balance replica counts among stores of
@@ -705,6 +722,76 @@ func (rp ReplicaPlanner) removeNonVoter(
 	return op, stats, nil
 }
 
+// addWitness adds a witness replica to `repl`s range.
+func (rp ReplicaPlanner) addWitness(
+	ctx context.Context,
+	repl AllocatorReplica,
+	desc *roachpb.RangeDescriptor,
+	conf *roachpb.SpanConfig,
+	allocatorPrio float64,
+) (op AllocationOp, stats ReplicateStats, _ error) {
+	existingWitnesses := desc.Replicas().WitnessDescriptors()
+	newWitness, details, err := rp.allocator.AllocateWitness(
+		ctx, rp.storePool, conf,
+		desc.Replicas().VoterDescriptors(),
+		desc.Replicas().NonVoterDescriptors(),
+		existingWitnesses,
+	)
+	if err != nil {
+		return nil, stats, err
+	}
+	stats = stats.trackAddReplicaCount(allocatorimpl.WitnessTarget)
+
+	log.KvDistribution.Infof(ctx, "adding witness %+v: %s",
+		newWitness, rangeRaftProgress(repl.RaftStatus(), existingWitnesses))
+	op = AllocationChangeReplicasOp{
+		lhStore:           repl.StoreID(),
+		Usage:             repl.RangeUsageInfo(),
+		Chgs:              kvpb.MakeReplicationChanges(roachpb.ADD_WITNESS, newWitness),
+		Priority:          kvserverpb.SnapshotRequest_RECOVERY,
+		AllocatorPriority: allocatorPrio,
+		Reason:            kvserverpb.ReasonRangeUnderReplicated,
+		Details:           details,
+	}
+	return op, stats, nil
+}
+
+func (rp ReplicaPlanner) removeWitness(
+	ctx context.Context,
+	repl AllocatorReplica,
+	desc *roachpb.RangeDescriptor,
+	conf *roachpb.SpanConfig,
+) (op AllocationOp, stats ReplicateStats, _ error) {
+	existingWitnesses := desc.Replicas().WitnessDescriptors()
+	removeWitness, details, err := rp.allocator.RemoveWitness(
+		ctx,
+		rp.storePool,
+		conf,
+		existingWitnesses,
+		desc.Replicas().VoterDescriptors(),
+		desc.Replicas().NonVoterDescriptors(),
+		existingWitnesses,
+		rp.allocator.ScorerOptions(ctx),
+	)
+	if err != nil {
+		return nil, stats, err
+	}
+	stats = stats.trackRemoveMetric(allocatorimpl.WitnessTarget, allocatorimpl.Alive)
+
+	log.KvDistribution.Infof(ctx, "removing witness %+v due to over-replication: %s",
+		removeWitness, rangeRaftProgress(repl.RaftStatus(), existingWitnesses))
+	op = AllocationChangeReplicasOp{
+		lhStore:           repl.StoreID(),
+		Usage:             repl.RangeUsageInfo(),
+		Chgs:              kvpb.MakeReplicationChanges(roachpb.REMOVE_WITNESS, removeWitness),
+		Priority:          kvserverpb.SnapshotRequest_UNKNOWN, // unused
+		AllocatorPriority: 0.0,                                // unused
+		Reason:            kvserverpb.ReasonRangeOverReplicated,
+		Details:           details,
+	}
+	return op, stats, nil
+}
+
 func (rp ReplicaPlanner) removeDecommissioning(
 	ctx context.Context,
 	repl AllocatorReplica,
@@ -722,6 +809,10 @@ func (rp ReplicaPlanner) removeDecommissioning(
 		decommissioningReplicas = rp.storePool.DecommissioningReplicas(
 			desc.Replicas().NonVoterDescriptors(),
 		)
+	case allocatorimpl.WitnessTarget:
+		decommissioningReplicas = rp.storePool.DecommissioningReplicas(
+			desc.Replicas().WitnessDescriptors(),
+		)
 	default:
 		panic(fmt.Sprintf("unknown targetReplicaType: %s", targetType))
 	}
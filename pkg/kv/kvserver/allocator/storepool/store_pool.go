@@ -513,6 +513,11 @@ func (sp *StorePool) UpdateLocalStoreAfterRebalance(
 				detail.Desc.Capacity.CPUPerSecond -= rangeUsageInfo.RaftCPUNanosPerSecond
 			}
 		}
+	case roachpb.ADD_WITNESS:
+		// Witnesses don't apply user writes, so they only add to the range count.
+		detail.Desc.Capacity.RangeCount++
+	case roachpb.REMOVE_WITNESS:
+		detail.Desc.Capacity.RangeCount--
 	default:
 		return
 	}
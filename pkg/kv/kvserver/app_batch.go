@@ -13,6 +13,7 @@ package kvserver
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
@@ -22,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble"
 	"golang.org/x/time/rate"
 )
 
@@ -74,6 +76,10 @@ func (s *appBatchStats) merge(ss appBatchStats) {
 // [^1]: https://github.com/cockroachdb/cockroach/issues/75729
 type appBatch struct {
 	appBatchStats
+	// witness is set if the batch is applied to a witness replica, which only
+	// applies the non-user keys of the commands and skips AddSSTable
+	// ingestions.
+	witness bool
 	// TODO(tbg): this will absorb the following fields from replicaAppBatch:
 	//
 	// - batch
@@ -143,12 +149,69 @@ func (b *appBatch) addWriteBatch(
 	} else {
 		b.numMutations += mutations
 	}
+	if b.witness {
+		if err := applyWitnessWriteBatch(batch, wb.Data); err != nil {
+			return errors.Wrapf(err, "unable to apply WriteBatch")
+		}
+		return nil
+	}
 	if err := batch.ApplyBatchRepr(wb.Data, false); err != nil {
 		return errors.Wrapf(err, "unable to apply WriteBatch")
 	}
 	return nil
 }
 
+// applyWitnessWriteBatch applies the part of a command's WriteBatch that a
+// witness keeps, i.e. the writes to the local keyspace. This covers the
+// range-local and RangeID-local keys of the replica as well as the lock table,
+// which is all a witness needs to take part in raft, splits and replication
+// changes. Writes to global keys are dropped.
+func applyWitnessWriteBatch(w storage.Writer, repr []byte) error {
+	r, err := storage.NewBatchReader(repr)
+	if err != nil {
+		return err
+	}
+	for r.Next() {
+		key, err := r.EngineKey()
+		if err != nil {
+			return err
+		}
+		if !keys.IsLocal(key.Key) {
+			continue
+		}
+		switch r.KeyKind() {
+		case pebble.InternalKeyKindSet, pebble.InternalKeyKindSetWithDelete:
+			err = w.PutEngineKey(key, r.Value())
+		case pebble.InternalKeyKindDelete, pebble.InternalKeyKindDeleteSized:
+			err = w.ClearEngineKey(key, storage.ClearOptions{})
+		case pebble.InternalKeyKindSingleDelete:
+			err = w.SingleClearEngineKey(key)
+		case pebble.InternalKeyKindMerge:
+			var mvccKey storage.MVCCKey
+			if mvccKey, err = r.MVCCKey(); err == nil {
+				err = w.Merge(mvccKey, r.Value())
+			}
+		case pebble.InternalKeyKindRangeDelete:
+			var end storage.EngineKey
+			if end, err = r.EngineEndKey(); err == nil {
+				endKey := end.Key
+				if !keys.IsLocal(endKey) {
+					endKey = keys.LocalMax
+				}
+				err = w.ClearRawRange(key.Key, endKey, true /* pointKeys */, false /* rangeKeys */)
+			}
+		default:
+			// Range keys are only written to the global keyspace.
+			err = errors.AssertionFailedf("unexpected batch entry kind %d for local key %s",
+				r.KeyKind(), key.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return r.Error()
+}
+
 type postAddEnv struct {
 	st          *cluster.Settings
 	eng         storage.Engine
@@ -174,7 +237,7 @@ func (b *appBatch) runPostAddTriggers(
 	// NB: any command which has an AddSSTable is non-trivial and will be
 	// applied in its own batch so it's not possible that any other commands
 	// which precede this command can shadow writes from this SSTable.
-	if res.AddSSTable != nil {
+	if res.AddSSTable != nil && !b.witness {
 		copied := addSSTablePreApply(
 			ctx,
 			env,
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/history"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/metrics"
@@ -242,6 +243,102 @@ func (ba BalanceAssertion) String() string {
 		ba.Stat, ba.Threshold, ba.Ticks)
 }
 
+// ProductionSeries provides the per-store values of a stat that were observed
+// in a production cluster, for comparison against a simulation replaying that
+// cluster's load.
+type ProductionSeries interface {
+	// StoreValues returns the production value of the stat for each store at
+	// time t. False is returned if the stat wasn't recorded in production.
+	StoreValues(stat string, t time.Time) (values []float64, ok bool)
+}
+
+// ProductionAssertion implements the SimulationAssertion interface. The
+// ProductionAssertion declares an assertion which compares the balance of the
+// simulated cluster against the balance the production cluster had at the same
+// point in time. For each of the last Ticks, the max/mean of the Stat across
+// stores is computed for both the simulation and production, the difference
+// (simulated - production) must meet the Threshold. A common use case is to
+// specify an upper_bound threshold, asserting that the simulated allocator
+// converges to a distribution no worse than what was seen in production.
+type ProductionAssertion struct {
+	Ticks      int
+	Stat       string
+	Production ProductionSeries
+	Threshold  Threshold
+}
+
+// Assert looks at a simulation run history and returns true if the difference
+// between the simulated and production max/mean of the declared Stat meets the
+// Threshold constraint at each assertion tick. If violated, holds is returned
+// as false along with the reason.
+func (pa ProductionAssertion) Assert(
+	ctx context.Context, h history.History,
+) (holds bool, reason string) {
+	m := h.Recorded
+	ticks := len(m)
+	if pa.Ticks > ticks {
+		log.VInfof(ctx, 2,
+			"The history to run assertions against (%d) is shorter than "+
+				"the assertion duration (%d)", ticks, pa.Ticks)
+		return true, ""
+	}
+
+	ts := metrics.MakeTS(m)
+	statTs := metrics.Transpose(ts[pa.Stat])
+
+	holds = true
+	buf := strings.Builder{}
+
+	for tick := 0; tick < pa.Ticks && tick < ticks; tick++ {
+		idx := ticks - tick - 1
+		if len(m[idx]) == 0 {
+			continue
+		}
+		prodStats, ok := pa.Production.StoreValues(pa.Stat, m[idx][0].Tick)
+		if !ok {
+			log.VInfof(ctx, 2,
+				"Production assertion: stat=%s not recorded at %s, skipping",
+				pa.Stat, m[idx][0].Tick)
+			continue
+		}
+		simMaxMean := maxMeanRatio(statTs[idx])
+		prodMaxMean := maxMeanRatio(prodStats)
+		diff := simMaxMean - prodMaxMean
+
+		log.VInfof(ctx, 2,
+			"Production assertion: stat=%s, sim max/mean=%.2f, prod max/mean=%.2f, threshold=%+v",
+			pa.Stat, simMaxMean, prodMaxMean, pa.Threshold)
+		if pa.Threshold.isViolated(diff) {
+			if holds {
+				fmt.Fprintf(&buf, "  %s\n", pa)
+				holds = false
+			}
+			fmt.Fprintf(&buf,
+				"\tsim max/mean=%.2f prod max/mean=%.2f tick=%d\n",
+				simMaxMean, prodMaxMean, tick)
+		}
+	}
+	return holds, buf.String()
+}
+
+// String returns the string representation of the assertion.
+func (pa ProductionAssertion) String() string {
+	return fmt.Sprintf(
+		"production stat=%s threshold=%v ticks=%d",
+		pa.Stat, pa.Threshold, pa.Ticks)
+}
+
+// maxMeanRatio returns the max/mean of the values, or zero if the values are
+// empty or sum to zero.
+func maxMeanRatio(values []float64) float64 {
+	mean, _ := stats.Mean(values)
+	if mean == 0 {
+		return 0
+	}
+	max, _ := stats.Max(values)
+	return max / mean
+}
+
 // StoreStatAssertion implements the SimulationAssertion interface. The
 // StoreStatAssertion declares an assertion. A common use case is to specify an
 // exact_bound for the type=stat threshold. With this configuration, the given
@@ -0,0 +1,277 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package replay
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+)
+
+// StoreInfo describes a store in the snapshot.
+type StoreInfo struct {
+	StoreID  roachpb.StoreID
+	NodeID   roachpb.NodeID
+	Locality roachpb.Locality
+	// Capacity is the disk capacity of the store in bytes, it is zero when
+	// unknown.
+	Capacity int64
+}
+
+// RangeSnapshot describes the placement, size and load of a range in the
+// snapshot.
+type RangeSnapshot struct {
+	Descriptor   roachpb.RangeDescriptor
+	Leaseholder  roachpb.StoreID
+	LogicalBytes int64
+	// Stats is the load on the range, as reported by the leaseholder.
+	Stats serverpb.RangeStatistics
+}
+
+type spanConfigEntry struct {
+	span   roachpb.Span
+	config roachpb.SpanConfig
+}
+
+// Snapshot is the state of a cluster, recovered from a `cockroach debug zip`.
+type Snapshot struct {
+	// Stores is ordered by StoreID.
+	Stores []StoreInfo
+	// Ranges is ordered by start key.
+	Ranges []RangeSnapshot
+	// spanConfigs is ordered by start key.
+	spanConfigs []spanConfigEntry
+}
+
+// ReadDebugZip reads the cluster topology, ranges and span configurations
+// from the root directory of an extracted debug zip. The following files are
+// used:
+//
+//	nodes/*/status.json                the node and store descriptors.
+//	nodes/*/ranges.json                the range descriptors, leases and load.
+//	system.span_configurations.txt     the span configurations.
+//
+// A range is reported by every node holding a replica of it, the report from
+// the leaseholder is preferred as it's the only replica that has accurate
+// load statistics.
+func ReadDebugZip(dir string) (*Snapshot, error) {
+	snap := &Snapshot{}
+	stores := make(map[roachpb.StoreID]StoreInfo)
+
+	statusFiles, err := filepath.Glob(filepath.Join(dir, "nodes", "*", "status.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range statusFiles {
+		var ns statuspb.NodeStatus
+		if err := readJSON(path, &ns); err != nil {
+			return nil, err
+		}
+		for _, ss := range ns.StoreStatuses {
+			stores[ss.Desc.StoreID] = StoreInfo{
+				StoreID:  ss.Desc.StoreID,
+				NodeID:   ns.Desc.NodeID,
+				Locality: ns.Desc.Locality,
+				Capacity: ss.Desc.Capacity.Capacity,
+			}
+		}
+	}
+
+	rangeFiles, err := filepath.Glob(filepath.Join(dir, "nodes", "*", "ranges.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(rangeFiles) == 0 {
+		return nil, errors.Newf("no ranges found in debug zip %s", dir)
+	}
+	ranges := make(map[roachpb.RangeID]serverpb.RangeInfo)
+	for _, path := range rangeFiles {
+		var infos []serverpb.RangeInfo
+		if err := readJSON(path, &infos); err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if info.State.Desc == nil {
+				continue
+			}
+			if _, ok := stores[info.SourceStoreID]; !ok {
+				store := StoreInfo{StoreID: info.SourceStoreID, NodeID: info.SourceNodeID}
+				if info.Locality != nil {
+					for _, tier := range info.Locality.Tiers {
+						store.Locality.Tiers = append(store.Locality.Tiers,
+							roachpb.Tier{Key: tier.Key, Value: tier.Value})
+					}
+				}
+				stores[info.SourceStoreID] = store
+			}
+			rangeID := info.State.Desc.RangeID
+			if existing, ok := ranges[rangeID]; !ok || (info.IsLeaseholder && !existing.IsLeaseholder) {
+				ranges[rangeID] = info
+			}
+		}
+	}
+
+	// A store holding replicas may not have been reported by any node, e.g.
+	// when its node was down while the debug zip was collected. Such a store
+	// is added without a locality or capacity, so that its replicas can still
+	// be placed.
+	for _, info := range ranges {
+		for _, repl := range info.State.Desc.InternalReplicas {
+			if _, ok := stores[repl.StoreID]; !ok {
+				stores[repl.StoreID] = StoreInfo{StoreID: repl.StoreID, NodeID: repl.NodeID}
+			}
+		}
+	}
+
+	for _, store := range stores {
+		snap.Stores = append(snap.Stores, store)
+	}
+	sort.Slice(snap.Stores, func(i, j int) bool {
+		return snap.Stores[i].StoreID < snap.Stores[j].StoreID
+	})
+
+	for _, info := range ranges {
+		rs := RangeSnapshot{
+			Descriptor: *info.State.Desc,
+			Stats:      info.Stats,
+		}
+		if info.State.Lease != nil {
+			rs.Leaseholder = info.State.Lease.Replica.StoreID
+		}
+		if info.State.Stats != nil {
+			rs.LogicalBytes = info.State.Stats.Total()
+		}
+		snap.Ranges = append(snap.Ranges, rs)
+	}
+	sort.Slice(snap.Ranges, func(i, j int) bool {
+		return snap.Ranges[i].Descriptor.StartKey.Less(snap.Ranges[j].Descriptor.StartKey)
+	})
+
+	snap.spanConfigs, err = readSpanConfigs(filepath.Join(dir, "system.span_configurations.txt"))
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// SpanConfigFor returns the span config which applies to the key. False is
+// returned if no span config applies to the key.
+func (s *Snapshot) SpanConfigFor(key roachpb.RKey) (roachpb.SpanConfig, bool) {
+	idx := sort.Search(len(s.spanConfigs), func(i int) bool {
+		return key.AsRawKey().Compare(s.spanConfigs[i].span.Key) < 0
+	})
+	if idx == 0 {
+		return roachpb.SpanConfig{}, false
+	}
+	entry := s.spanConfigs[idx-1]
+	if !entry.span.ContainsKey(key.AsRawKey()) {
+		return roachpb.SpanConfig{}, false
+	}
+	return entry.config, true
+}
+
+func readJSON(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return errors.Wrapf(err, "decoding %s", path)
+	}
+	return nil
+}
+
+// readSpanConfigs parses the span configurations table dump. The dump is tab
+// separated with a header row, the keys and config are hex encoded bytes. A
+// missing file isn't an error, all ranges will then use the default span
+// config.
+func readSpanConfigs(path string) ([]spanConfigEntry, error) {
+	f, err := os.Open(path)
+	if oserror.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comma = '\t'
+	r.LazyQuotes = true
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	cols := make(map[string]int, len(header))
+	for i, col := range header {
+		cols[col] = i
+	}
+	for _, col := range []string{"start_key", "end_key", "config"} {
+		if _, ok := cols[col]; !ok {
+			return nil, errors.Newf("%s: missing column %s", path, col)
+		}
+	}
+
+	var entries []spanConfigEntry
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", path)
+		}
+		var entry spanConfigEntry
+		if entry.span.Key, err = decodeBytes(record[cols["start_key"]]); err != nil {
+			return nil, errors.Wrapf(err, "%s: decoding start_key", path)
+		}
+		if entry.span.EndKey, err = decodeBytes(record[cols["end_key"]]); err != nil {
+			return nil, errors.Wrapf(err, "%s: decoding end_key", path)
+		}
+		config, err := decodeBytes(record[cols["config"]])
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: decoding config", path)
+		}
+		if err := protoutil.Unmarshal(config, &entry.config); err != nil {
+			return nil, errors.Wrapf(err, "%s: unmarshaling config", path)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].span.Key, entries[j].span.Key) < 0
+	})
+	return entries, nil
+}
+
+// decodeBytes decodes a BYTES datum as formatted in a debug zip table dump.
+func decodeBytes(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, `\x`) {
+		return []byte(s), nil
+	}
+	return hex.DecodeString(s[2:])
+}
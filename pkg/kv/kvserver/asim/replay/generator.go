@@ -0,0 +1,146 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package replay
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+)
+
+// rangeLoad is the snapshot load of a range, which is scaled over time by the
+// load recorded for the range's leaseholder store in production.
+type rangeLoad struct {
+	startKey    int64
+	leaseholder roachpb.StoreID
+	qps         float64
+	writeRatio  float64
+	writeBytes  float64
+	readBytes   float64
+	cpu         float64
+	// carry is the fractional number of queries that were not emitted in the
+	// previous tick.
+	carry float64
+}
+
+// storeLoad is the total snapshot load of the ranges a store was the
+// leaseholder for.
+type storeLoad struct {
+	qps, writeBytes, cpu float64
+}
+
+// generator implements the workload.Generator interface. It emits, every
+// tick, a load event for each range containing the range's reconstructed
+// production load since the last tick.
+type generator struct {
+	dump    *TSDump
+	spacing int64
+	rand    *rand.Rand
+	lastRun time.Time
+	ranges  []rangeLoad
+	stores  map[roachpb.StoreID]storeLoad
+}
+
+func newGenerator(r *Replay, start time.Time, seed int64) *generator {
+	g := &generator{
+		dump:    r.dump,
+		spacing: r.spacing,
+		rand:    rand.New(rand.NewSource(seed)),
+		lastRun: start,
+		ranges:  make([]rangeLoad, len(r.snapshot.Ranges)),
+		stores:  make(map[roachpb.StoreID]storeLoad),
+	}
+	for i, rng := range r.snapshot.Ranges {
+		rl := rangeLoad{
+			startKey:    int64(i) * r.spacing,
+			leaseholder: rng.Leaseholder,
+			qps:         rng.Stats.QueriesPerSecond,
+			writeBytes:  rng.Stats.WriteBytesPerSecond,
+			readBytes:   rng.Stats.ReadBytesPerSecond,
+			cpu:         rng.Stats.CPUTimePerSecond,
+		}
+		if keys := rng.Stats.WritesPerSecond + rng.Stats.ReadsPerSecond; keys > 0 {
+			rl.writeRatio = rng.Stats.WritesPerSecond / keys
+		}
+		g.ranges[i] = rl
+
+		sl := g.stores[rl.leaseholder]
+		sl.qps += rl.qps
+		sl.writeBytes += rl.writeBytes
+		sl.cpu += rl.cpu
+		g.stores[rl.leaseholder] = sl
+	}
+	return g
+}
+
+// scale returns the ratio of the store's production load at time t, to the
+// snapshot load of the ranges it held leases for. When the store has no
+// timeseries for the metric, the snapshot load is replayed unscaled.
+func (g *generator) scale(
+	metric string, storeID roachpb.StoreID, snapshot float64, t time.Time,
+) float64 {
+	s, ok := g.dump.Series(metric, storeID)
+	if !ok || snapshot == 0 {
+		return 1
+	}
+	return s.ValueAt(t) / snapshot
+}
+
+// Tick returns the load events up till time tick, from the last time the
+// workload generator was called.
+func (g *generator) Tick(tick time.Time) workload.LoadBatch {
+	elapsed := tick.Sub(g.lastRun).Seconds()
+	if elapsed <= 0 {
+		return workload.LoadBatch{}
+	}
+	g.lastRun = tick
+
+	type scales struct{ qps, writeBytes, cpu float64 }
+	storeScales := make(map[roachpb.StoreID]scales, len(g.stores))
+	for storeID, sl := range g.stores {
+		storeScales[storeID] = scales{
+			qps:        g.scale(MetricQPS, storeID, sl.qps, tick),
+			writeBytes: g.scale(MetricWriteBytes, storeID, sl.writeBytes, tick),
+			cpu:        g.scale(MetricCPU, storeID, sl.cpu, tick),
+		}
+	}
+
+	// The ranges are ordered by key and each event falls within its range, so
+	// the batch is sorted.
+	batch := make(workload.LoadBatch, 0, len(g.ranges))
+	for i := range g.ranges {
+		rl := &g.ranges[i]
+		sc := storeScales[rl.leaseholder]
+
+		queries := rl.qps*sc.qps*elapsed + rl.carry
+		count := math.Floor(queries)
+		rl.carry = queries - count
+		writes := int64(math.Round(count * rl.writeRatio))
+
+		le := workload.LoadEvent{
+			Key:        rl.startKey + g.rand.Int63n(g.spacing),
+			Writes:     writes,
+			Reads:      int64(count) - writes,
+			WriteSize:  int64(rl.writeBytes * sc.writeBytes * elapsed),
+			ReadSize:   int64(rl.readBytes * sc.qps * elapsed),
+			RequestCPU: int64(rl.cpu * sc.cpu * elapsed),
+		}
+		if le.Writes == 0 && le.Reads == 0 && le.WriteSize == 0 &&
+			le.ReadSize == 0 && le.RequestCPU == 0 {
+			continue
+		}
+		batch = append(batch, le)
+	}
+	return batch
+}
@@ -0,0 +1,256 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package replay builds allocation simulations from the state and load of a
+// production cluster. The initial state (stores, localities, ranges, span
+// configs and leases) is taken from a `cockroach debug zip` and the load over
+// time is taken from a `cockroach debug tsdump` of the same cluster.
+//
+// Only store level timeseries are retained by the timeseries database, so the
+// load on each range over time is reconstructed: each range's load in the
+// debug zip snapshot is scaled by the ratio of its leaseholder store's
+// timeseries value to the total snapshot load of the ranges that store held
+// leases for. Once attributed, load follows the range regardless of where the
+// simulated allocator moves its lease.
+package replay
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/config"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+)
+
+// Replay builds the initial state and workload of a simulation which replays
+// a production cluster.
+type Replay struct {
+	snapshot *Snapshot
+	dump     *TSDump
+	// storeIDs maps production store IDs to simulated store IDs. Simulated
+	// stores and nodes are assigned sequential IDs, in order of the production
+	// store IDs.
+	storeIDs map[roachpb.StoreID]state.StoreID
+	// spacing is the distance between the start keys of adjacent ranges in the
+	// simulated keyspace.
+	spacing int64
+}
+
+// New returns a Replay of the production cluster snapshot and timeseries.
+func New(snapshot *Snapshot, dump *TSDump) *Replay {
+	r := &Replay{
+		snapshot: snapshot,
+		dump:     dump,
+		storeIDs: make(map[roachpb.StoreID]state.StoreID, len(snapshot.Stores)),
+		spacing:  int64(state.MaxKey) / int64(len(snapshot.Ranges)+1),
+	}
+	for i, store := range snapshot.Stores {
+		r.storeIDs[store.StoreID] = state.StoreID(i + 1)
+	}
+	return r
+}
+
+// Settings returns the default simulation settings, with the start time set
+// to the beginning of the timeseries dump.
+func (r *Replay) Settings() *config.SimulationSettings {
+	settings := config.DefaultSimulationSettings()
+	if !r.dump.Start.IsZero() {
+		settings.StartTime = r.dump.Start
+	}
+	return settings
+}
+
+// Duration returns the duration of production load that is replayed.
+func (r *Replay) Duration() time.Duration {
+	return r.dump.Duration()
+}
+
+// Cluster returns a generator for the simulated cluster's nodes and stores.
+func (r *Replay) Cluster() Cluster {
+	return Cluster{r}
+}
+
+// Ranges returns a generator for the simulated cluster's ranges.
+func (r *Replay) Ranges() Ranges {
+	return Ranges{r}
+}
+
+// Load returns a generator for the replayed load.
+func (r *Replay) Load() Load {
+	return Load{r}
+}
+
+// statMetrics maps the simulator's store stats to the production timeseries
+// that they are compared against.
+var statMetrics = map[string]string{
+	"qps":      MetricQPS,
+	"replicas": MetricReplicas,
+	"leases":   MetricLeases,
+}
+
+// StoreValues returns the production value of the stat for each store at time
+// t. It implements the assertion.ProductionSeries interface.
+func (r *Replay) StoreValues(stat string, t time.Time) ([]float64, bool) {
+	metric, ok := statMetrics[stat]
+	if !ok {
+		return nil, false
+	}
+	var values []float64
+	for _, store := range r.snapshot.Stores {
+		if s, ok := r.dump.Series(metric, store.StoreID); ok {
+			values = append(values, s.ValueAt(t))
+		}
+	}
+	return values, len(values) > 0
+}
+
+// Cluster implements the gen.ClusterGen interface.
+type Cluster struct {
+	r *Replay
+}
+
+// Generate returns a new State containing a node and store for every node and
+// store in the production cluster, with the same localities.
+func (c Cluster) Generate(seed int64, settings *config.SimulationSettings) state.State {
+	s := state.NewState(settings)
+	var node state.Node
+	for i, store := range c.r.snapshot.Stores {
+		if i == 0 || store.NodeID != c.r.snapshot.Stores[i-1].NodeID {
+			node = s.AddNode()
+			s.SetNodeLocality(node.NodeID(), store.Locality)
+		}
+		simStore, ok := s.AddStore(node.NodeID())
+		if !ok {
+			panic(fmt.Sprintf("unable to add store s%d to n%d", store.StoreID, node.NodeID()))
+		}
+		if store.Capacity > 0 {
+			s.SetStoreCapacity(simStore.StoreID(), store.Capacity)
+		}
+	}
+	return s
+}
+
+// String returns the string representation of the cluster generator.
+func (c Cluster) String() string {
+	return fmt.Sprintf("replay cluster stores=%d", len(c.r.snapshot.Stores))
+}
+
+// Regions returns the regions and zones of the production cluster, derived
+// from the region and zone locality tiers of its stores.
+func (c Cluster) Regions() []state.Region {
+	type zoneKey struct{ region, zone string }
+	nodes := make(map[zoneKey]map[roachpb.NodeID]int)
+	for _, store := range c.r.snapshot.Stores {
+		region, _ := store.Locality.Find("region")
+		zone, _ := store.Locality.Find("zone")
+		key := zoneKey{region, zone}
+		if nodes[key] == nil {
+			nodes[key] = make(map[roachpb.NodeID]int)
+		}
+		nodes[key][store.NodeID]++
+	}
+
+	keys := make([]zoneKey, 0, len(nodes))
+	for key := range nodes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].region != keys[j].region {
+			return keys[i].region < keys[j].region
+		}
+		return keys[i].zone < keys[j].zone
+	})
+
+	var regions []state.Region
+	for _, key := range keys {
+		zone := state.Zone{Name: key.zone, NodeCount: len(nodes[key])}
+		for _, stores := range nodes[key] {
+			if stores > zone.StoresPerNode {
+				zone.StoresPerNode = stores
+			}
+		}
+		if len(regions) == 0 || regions[len(regions)-1].Name != key.region {
+			regions = append(regions, state.Region{Name: key.region})
+		}
+		regions[len(regions)-1].Zones = append(regions[len(regions)-1].Zones, zone)
+	}
+	return regions
+}
+
+// Ranges implements the gen.RangeGen interface.
+type Ranges struct {
+	r *Replay
+}
+
+// Generate returns an updated state, containing a range for every range in the
+// production cluster. The production ranges keep their order, size, span
+// config, replica placement and leaseholder, however their keys are mapped
+// onto evenly spaced keys in the simulated keyspace.
+func (rg Ranges) Generate(
+	seed int64, settings *config.SimulationSettings, s state.State,
+) state.State {
+	r := rg.r
+	rangeInfos := make(state.RangesInfo, 0, len(r.snapshot.Ranges))
+	for i, rng := range r.snapshot.Ranges {
+		desc := roachpb.RangeDescriptor{
+			StartKey: state.Key(int64(i) * r.spacing).ToRKey(),
+		}
+		var leaseholder state.StoreID
+		for _, repl := range rng.Descriptor.InternalReplicas {
+			storeID, ok := r.storeIDs[repl.StoreID]
+			if !ok {
+				panic(fmt.Sprintf("r%d has a replica on unknown store s%d",
+					rng.Descriptor.RangeID, repl.StoreID))
+			}
+			desc.InternalReplicas = append(desc.InternalReplicas, roachpb.ReplicaDescriptor{
+				StoreID: roachpb.StoreID(storeID),
+				Type:    repl.Type,
+			})
+			if repl.StoreID == rng.Leaseholder || leaseholder == 0 {
+				leaseholder = storeID
+			}
+		}
+		info := state.RangeInfo{
+			Descriptor:  desc,
+			Size:        rng.LogicalBytes,
+			Leaseholder: leaseholder,
+		}
+		if conf, ok := r.snapshot.SpanConfigFor(rng.Descriptor.StartKey); ok {
+			info.Config = &conf
+		}
+		rangeInfos = append(rangeInfos, info)
+	}
+	state.LoadRangeInfo(s, rangeInfos...)
+	return s
+}
+
+// String returns the string representation of the range generator.
+func (rg Ranges) String() string {
+	return fmt.Sprintf("replay ranges=%d", len(rg.r.snapshot.Ranges))
+}
+
+// Load implements the gen.LoadGen interface.
+type Load struct {
+	r *Replay
+}
+
+// Generate returns a workload generator which replays the production load.
+func (l Load) Generate(seed int64, settings *config.SimulationSettings) []workload.Generator {
+	return []workload.Generator{newGenerator(l.r, settings.StartTime, seed)}
+}
+
+// String returns the string representation of the load generator.
+func (l Load) String() string {
+	return fmt.Sprintf("replay load start=%s duration=%s",
+		l.r.dump.Start.Format(time.RFC3339), l.r.dump.Duration())
+}
@@ -0,0 +1,211 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package replay
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testingSeed = 42
+
+var testingStart = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func writeJSON(t *testing.T, path string, v interface{}) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, b, 0644))
+}
+
+// makeDebugZip writes a debug zip containing two single store nodes, in
+// different regions, and two ranges which have a replica on both stores. The
+// first range has its lease on s1 and the second on s2.
+func makeDebugZip(t *testing.T) string {
+	dir := t.TempDir()
+	for _, id := range []int{1, 2} {
+		locality := roachpb.Locality{Tiers: []roachpb.Tier{
+			{Key: "region", Value: fmt.Sprintf("region-%d", id)},
+			{Key: "zone", Value: fmt.Sprintf("zone-%d", id)},
+		}}
+		writeJSON(t, filepath.Join(dir, "nodes", fmt.Sprint(id), "status.json"), statuspb.NodeStatus{
+			Desc: roachpb.NodeDescriptor{NodeID: roachpb.NodeID(id), Locality: locality},
+			StoreStatuses: []statuspb.StoreStatus{{Desc: roachpb.StoreDescriptor{
+				StoreID:  roachpb.StoreID(id),
+				Capacity: roachpb.StoreCapacity{Capacity: 1 << 40},
+			}}},
+		})
+	}
+
+	replicas := []roachpb.ReplicaDescriptor{
+		{NodeID: 1, StoreID: 1, ReplicaID: 1},
+		{NodeID: 2, StoreID: 2, ReplicaID: 2},
+	}
+	makeRange := func(
+		rangeID roachpb.RangeID, start, end string, lh roachpb.StoreID, qps float64, source roachpb.StoreID,
+	) serverpb.RangeInfo {
+		return serverpb.RangeInfo{
+			State: kvserverpb.RangeInfo{ReplicaState: kvserverpb.ReplicaState{
+				Desc: &roachpb.RangeDescriptor{
+					RangeID:          rangeID,
+					StartKey:         roachpb.RKey(start),
+					EndKey:           roachpb.RKey(end),
+					InternalReplicas: replicas,
+				},
+				Lease: &roachpb.Lease{Replica: replicas[lh-1]},
+				Stats: &enginepb.MVCCStats{KeyBytes: 100, ValBytes: 900},
+			}},
+			SourceNodeID:  roachpb.NodeID(source),
+			SourceStoreID: source,
+			IsLeaseholder: lh == source,
+			Stats: serverpb.RangeStatistics{
+				QueriesPerSecond: qps,
+				WritesPerSecond:  qps / 2,
+				ReadsPerSecond:   qps / 2,
+			},
+		}
+	}
+	// Each node reports both ranges, only the leaseholder reports load.
+	writeJSON(t, filepath.Join(dir, "nodes", "1", "ranges.json"), []serverpb.RangeInfo{
+		makeRange(1, "a", "m", 1, 100, 1),
+		makeRange(2, "m", "z", 2, 0, 1),
+	})
+	writeJSON(t, filepath.Join(dir, "nodes", "2", "ranges.json"), []serverpb.RangeInfo{
+		makeRange(1, "a", "m", 1, 0, 2),
+		makeRange(2, "m", "z", 2, 50, 2),
+	})
+
+	conf := roachpb.SpanConfig{NumReplicas: 2, NumVoters: 2}
+	b, err := protoutil.Marshal(&conf)
+	require.NoError(t, err)
+	spanConfigs := fmt.Sprintf("start_key\tend_key\tconfig\n\\x%s\t\\x%s\t\\x%s\n",
+		hex.EncodeToString([]byte("a")), hex.EncodeToString([]byte("m")), hex.EncodeToString(b))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "system.span_configurations.txt"), []byte(spanConfigs), 0644))
+	return dir
+}
+
+// makeTSDump returns a tsdump where the QPS on s1 doubles after 10 seconds
+// and the QPS on s2 is constant.
+func makeTSDump() string {
+	var buf strings.Builder
+	for i, qps := range []float64{100, 200, 200} {
+		ts := testingStart.Add(time.Duration(i) * 10 * time.Second).Format(time.RFC3339)
+		fmt.Fprintf(&buf, "%s,%s,1,%v\n", MetricQPS, ts, qps)
+		fmt.Fprintf(&buf, "%s,%s,2,%v\n", MetricQPS, ts, 50)
+		fmt.Fprintf(&buf, "%s,%s,1,%v\n", MetricLeases, ts, 1)
+		fmt.Fprintf(&buf, "%s,%s,2,%v\n", MetricLeases, ts, 1)
+		// Node level metrics are ignored.
+		fmt.Fprintf(&buf, "cr.node.sql.conns,%s,1,%v\n", ts, 5)
+	}
+	return buf.String()
+}
+
+func TestReplay(t *testing.T) {
+	snapshot, err := ReadDebugZip(makeDebugZip(t))
+	require.NoError(t, err)
+	require.Len(t, snapshot.Stores, 2)
+	require.Len(t, snapshot.Ranges, 2)
+	require.Equal(t, 100.0, snapshot.Ranges[0].Stats.QueriesPerSecond)
+	require.Equal(t, 50.0, snapshot.Ranges[1].Stats.QueriesPerSecond)
+
+	dump, err := ReadTSDump(strings.NewReader(makeTSDump()))
+	require.NoError(t, err)
+	require.Equal(t, testingStart, dump.Start)
+	require.Equal(t, 20*time.Second, dump.Duration())
+	_, ok := dump.Series("cr.node.sql.conns", 1)
+	require.False(t, ok)
+
+	r := New(snapshot, dump)
+	settings := r.Settings()
+	require.Equal(t, testingStart, settings.StartTime)
+
+	s := r.Cluster().Generate(testingSeed, settings)
+	s = r.Ranges().Generate(testingSeed, settings, s)
+	require.Len(t, s.Stores(), 2)
+	require.Len(t, r.Cluster().Regions(), 2)
+
+	first := s.RangeFor(state.MinKey)
+	second := s.RangeFor(state.Key(r.spacing))
+	require.NotEqual(t, first.RangeID(), second.RangeID())
+	require.Equal(t, int32(2), first.SpanConfig().NumReplicas)
+	require.Equal(t, int64(1000), first.Size())
+	lh, _ := s.LeaseholderStore(first.RangeID())
+	require.Equal(t, state.StoreID(1), lh.StoreID())
+	lh, _ = s.LeaseholderStore(second.RangeID())
+	require.Equal(t, state.StoreID(2), lh.StoreID())
+
+	// After 10 seconds, s1's QPS has doubled, which is attributed entirely to
+	// the first range.
+	g := r.Load().Generate(testingSeed, settings)[0]
+	batch := g.Tick(testingStart.Add(10 * time.Second))
+	require.Len(t, batch, 2)
+	require.Equal(t, int64(2000), batch[0].Reads+batch[0].Writes)
+	require.Equal(t, int64(500), batch[1].Reads+batch[1].Writes)
+	require.Equal(t, s.RangeFor(state.Key(batch[0].Key)).RangeID(), first.RangeID())
+	require.Equal(t, s.RangeFor(state.Key(batch[1].Key)).RangeID(), second.RangeID())
+
+	values, ok := r.StoreValues("qps", testingStart.Add(5*time.Second))
+	require.True(t, ok)
+	require.Equal(t, []float64{150, 50}, values)
+	_, ok = r.StoreValues("replicas", testingStart)
+	require.False(t, ok)
+}
+
+// TestReplayUnreportedStore verifies that a store which holds replicas, but
+// isn't reported by any node in the debug zip, is still simulated.
+func TestReplayUnreportedStore(t *testing.T) {
+	dir := makeDebugZip(t)
+	// Add a range with a replica on s3, whose node didn't report its status.
+	writeJSON(t, filepath.Join(dir, "nodes", "1", "ranges.json"), []serverpb.RangeInfo{{
+		State: kvserverpb.RangeInfo{ReplicaState: kvserverpb.ReplicaState{
+			Desc: &roachpb.RangeDescriptor{
+				RangeID:  3,
+				StartKey: roachpb.RKey("a"),
+				EndKey:   roachpb.RKey("z"),
+				InternalReplicas: []roachpb.ReplicaDescriptor{
+					{NodeID: 1, StoreID: 1, ReplicaID: 1},
+					{NodeID: 3, StoreID: 3, ReplicaID: 2},
+				},
+			},
+		}},
+		SourceNodeID:  1,
+		SourceStoreID: 1,
+	}})
+
+	snapshot, err := ReadDebugZip(dir)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Stores, 3)
+	require.Equal(t, StoreInfo{StoreID: 3, NodeID: 3}, snapshot.Stores[2])
+
+	dump, err := ReadTSDump(strings.NewReader(makeTSDump()))
+	require.NoError(t, err)
+	r := New(snapshot, dump)
+	settings := r.Settings()
+	s := r.Cluster().Generate(testingSeed, settings)
+	s = r.Ranges().Generate(testingSeed, settings, s)
+	require.Len(t, s.Stores(), 3)
+}
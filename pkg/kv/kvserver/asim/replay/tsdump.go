@@ -0,0 +1,165 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package replay
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/ts/tsutil"
+	"github.com/cockroachdb/errors"
+)
+
+// The store level timeseries which are used when replaying production load.
+// The rebalancing metrics are the leaseholder load that the store rebalancer
+// acts upon, the others are used to compare the simulated replica and lease
+// distribution against production.
+const (
+	MetricQPS        = "cr.store.rebalancing.queriespersecond"
+	MetricWriteBytes = "cr.store.rebalancing.writebytespersecond"
+	MetricCPU        = "cr.store.rebalancing.cpunanospersecond"
+	MetricReplicas   = "cr.store.replicas"
+	MetricLeases     = "cr.store.replicas.leaseholders"
+)
+
+var replayMetrics = map[string]struct{}{
+	MetricQPS:        {},
+	MetricWriteBytes: {},
+	MetricCPU:        {},
+	MetricReplicas:   {},
+	MetricLeases:     {},
+}
+
+// Datapoint is a single timeseries sample.
+type Datapoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a sequence of datapoints, ordered by time.
+type Series []Datapoint
+
+// ValueAt returns the value of the series at time t, linearly interpolating
+// between the datapoints either side of t. Times before the first, or after
+// the last datapoint are clamped to the value of that datapoint.
+func (s Series) ValueAt(t time.Time) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	idx := sort.Search(len(s), func(i int) bool { return !s[i].Time.Before(t) })
+	if idx == 0 {
+		return s[0].Value
+	}
+	if idx == len(s) {
+		return s[len(s)-1].Value
+	}
+	prev, next := s[idx-1], s[idx]
+	frac := float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time))
+	return prev.Value + frac*(next.Value-prev.Value)
+}
+
+// TSDump contains the store level timeseries of a cluster, as output by
+// `cockroach debug tsdump`.
+type TSDump struct {
+	// Start and End are the timestamps of the earliest and latest datapoint
+	// in the dump.
+	Start, End time.Time
+	series     map[string]map[roachpb.StoreID]Series
+}
+
+// ReadTSDump parses the csv or tsv output of `cockroach debug tsdump`. Each
+// record is of the form: name, timestamp (RFC3339), source, value. Records
+// for metrics which aren't used for replay, or that belong to a secondary
+// tenant are ignored.
+func ReadTSDump(r io.Reader) (*TSDump, error) {
+	br := bufio.NewReader(r)
+	// Peek at the first line to determine whether the dump is comma or tab
+	// separated.
+	first, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	cr := csv.NewReader(io.MultiReader(strings.NewReader(first), br))
+	if strings.Contains(first, "\t") {
+		cr.Comma = '\t'
+	}
+	cr.FieldsPerRecord = 4
+	cr.ReuseRecord = true
+
+	d := &TSDump{series: make(map[string]map[roachpb.StoreID]Series)}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading tsdump")
+		}
+		name := record[0]
+		if _, ok := replayMetrics[name]; !ok {
+			continue
+		}
+		source, tenant := tsutil.DecodeSource(record[2])
+		if tenant != "" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, record[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing timestamp for %s", name)
+		}
+		storeID, err := strconv.ParseInt(source, 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing source for %s", name)
+		}
+		value, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing value for %s", name)
+		}
+
+		stores, ok := d.series[name]
+		if !ok {
+			stores = make(map[roachpb.StoreID]Series)
+			d.series[name] = stores
+		}
+		stores[roachpb.StoreID(storeID)] = append(
+			stores[roachpb.StoreID(storeID)], Datapoint{Time: ts, Value: value})
+		if d.Start.IsZero() || ts.Before(d.Start) {
+			d.Start = ts
+		}
+		if ts.After(d.End) {
+			d.End = ts
+		}
+	}
+
+	for _, stores := range d.series {
+		for _, s := range stores {
+			sort.Slice(s, func(i, j int) bool { return s[i].Time.Before(s[j].Time) })
+		}
+	}
+	return d, nil
+}
+
+// Series returns the timeseries recorded for the metric on the store.
+func (d *TSDump) Series(metric string, storeID roachpb.StoreID) (Series, bool) {
+	s, ok := d.series[metric][storeID]
+	return s, ok
+}
+
+// Duration returns the duration covered by the dump.
+func (d *TSDump) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
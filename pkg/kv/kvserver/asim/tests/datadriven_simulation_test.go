@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -25,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/gen"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/history"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/metrics"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/replay"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
 	"github.com/cockroachdb/cockroach/pkg/spanconfig/spanconfigtestutils"
@@ -61,6 +63,13 @@ import (
 //     regions having 3 zones. complex: 28 nodes, 3 regions with a skewed
 //     number of nodes per region.
 //
+//   - "load_replay" debug_zip=<path> tsdump=<path>
+//     Replay a production cluster: the cluster, ranges and load are generated
+//     from the extracted debug zip and the tsdump (in CSV form) found at the
+//     given paths, relative to testdata. The simulation starts at the
+//     beginning of the tsdump and, unless given, eval runs for its duration.
+//     Since it sets the start time, it should precede any delayed events.
+//
 //   - "gen_ranges" [ranges=<int>] [placement_skew=<bool>] [repl_factor=<int>]
 //     [keyspace=<int>] [range_bytes=<int>]
 //     Initialize the range generator parameters. On the next call to eval, the
@@ -95,7 +104,7 @@ import (
 //     Add an assertion to the list of assertions that run against each sample
 //     on subsequent calls to eval. When every assertion holds during eval, OK
 //     is printed, otherwise the reason the assertion(s) failed is printed.
-//     type=balance,steady,stat assertions look at the last 'ticks' duration of
+//     type=balance,steady,stat,production assertions look at the last 'ticks' duration of
 //     the simulation run. type=conformance assertions look at the end of the
 //     evaluation.
 //
@@ -116,6 +125,13 @@ import (
 //     provided (e.g. exact=0), the assertion fails. This applies for specified
 //     stores which must be provided with stores=(storeID,...).
 //
+//     For type=production assertions, which require load_replay, the max/mean
+//     of the stat (e.g. stat=qps) across stores is compared with the max/mean
+//     the production cluster had at the same time. If the difference
+//     (simulated - production) violates the threshold constraint provided
+//     (e.g. upper_bound=0.1) during any of the last ticks, the assertion
+//     fails.
+//
 //     For type=conformance assertions, you may assert on the number of
 //     replicas that you expect to be under-replicated (under),
 //     over-replicated(over), unavailable(unavailable) and violating
@@ -158,7 +174,7 @@ func TestDataDriven(t *testing.T) {
 	dir := datapathutils.TestDataPath(t, "non_rand")
 	datadriven.Walk(t, dir, func(t *testing.T, path string) {
 		const defaultKeyspace = 10000
-		loadGen := gen.BasicLoad{}
+		var loadGen gen.LoadGen = gen.BasicLoad{}
 		var clusterGen gen.ClusterGen
 		var rangeGen gen.RangeGen = gen.BasicRanges{
 			BaseRanges: gen.BaseRanges{
@@ -169,6 +185,7 @@ func TestDataDriven(t *testing.T) {
 		}
 		settingsGen := gen.StaticSettings{Settings: config.DefaultSimulationSettings()}
 		eventGen := gen.NewStaticEventsWithNoEvents()
+		var rep *replay.Replay
 		assertions := []assertion.SimulationAssertion{}
 		runs := []history.History{}
 		datadriven.RunTest(t, path, func(t *testing.T, d *datadriven.TestData) string {
@@ -187,13 +204,15 @@ func TestDataDriven(t *testing.T) {
 				scanIfExists(t, d, "min_key", &minKey)
 				scanIfExists(t, d, "max_key", &maxKey)
 
-				loadGen.SkewedAccess = accessSkew
-				loadGen.MinKey = minKey
-				loadGen.MaxKey = maxKey
-				loadGen.RWRatio = rwRatio
-				loadGen.Rate = rate
-				loadGen.MaxBlockSize = maxBlock
-				loadGen.MinBlockSize = minBlock
+				loadGen = gen.BasicLoad{
+					SkewedAccess: accessSkew,
+					MinKey:       minKey,
+					MaxKey:       maxKey,
+					RWRatio:      rwRatio,
+					Rate:         rate,
+					MaxBlockSize: maxBlock,
+					MinBlockSize: minBlock,
+				}
 				return ""
 			case "gen_ranges":
 				var ranges, replFactor, keyspace = 1, 3, defaultKeyspace
@@ -242,6 +261,21 @@ func TestDataDriven(t *testing.T) {
 				scanArg(t, d, "config", &config)
 				clusterGen = loadClusterInfo(config)
 				return ""
+			case "load_replay":
+				var debugZip, tsDump string
+				scanArg(t, d, "debug_zip", &debugZip)
+				scanArg(t, d, "tsdump", &tsDump)
+				snapshot, err := replay.ReadDebugZip(datapathutils.TestDataPath(t, debugZip))
+				require.NoError(t, err)
+				f, err := os.Open(datapathutils.TestDataPath(t, tsDump))
+				require.NoError(t, err)
+				defer f.Close()
+				dump, err := replay.ReadTSDump(f)
+				require.NoError(t, err)
+				rep = replay.New(snapshot, dump)
+				clusterGen, rangeGen, loadGen = rep.Cluster(), rep.Ranges(), rep.Load()
+				settingsGen.Settings = rep.Settings()
+				return ""
 			case "add_node":
 				var delay time.Duration
 				var numStores = 1
@@ -313,6 +347,9 @@ func TestDataDriven(t *testing.T) {
 				samples := 1
 				seed := rand.Int63()
 				duration := 30 * time.Minute
+				if rep != nil {
+					duration = rep.Duration()
+				}
 				failureExists := false
 
 				scanIfExists(t, d, "duration", &duration)
@@ -393,6 +430,16 @@ func TestDataDriven(t *testing.T) {
 						Threshold: scanThreshold(t, d),
 						Stores:    stores,
 					})
+				case "production":
+					require.NotNil(t, rep, "production assertions require load_replay")
+					scanArg(t, d, "stat", &stat)
+					scanArg(t, d, "ticks", &ticks)
+					assertions = append(assertions, assertion.ProductionAssertion{
+						Ticks:      ticks,
+						Stat:       stat,
+						Production: rep,
+						Threshold:  scanThreshold(t, d),
+					})
 				case "conformance":
 					var under, over, unavailable, violating int
 					under = assertion.ConformanceAssertionSentinel
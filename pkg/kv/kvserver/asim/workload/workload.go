@@ -26,6 +26,10 @@ type LoadEvent struct {
 	WriteSize int64
 	Reads     int64
 	ReadSize  int64
+	// RequestCPU is the request CPU time, in nanoseconds, attributed to the
+	// load event. It is zero for synthetic workloads which only model key
+	// accesses.
+	RequestCPU int64
 }
 
 // LoadBatch is a sorted list of load events.
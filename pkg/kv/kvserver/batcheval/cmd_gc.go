@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/lockspanset"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
@@ -82,6 +83,19 @@ func declareKeysGC(
 				hlc.MaxTimestamp)
 		}
 	}
+	// Unlike the versions removed by Keys, expired keys may be live and above
+	// the GC threshold, so the reasoning below doesn't apply to them. We
+	// declare exclusive access over each expired key at all timestamps, to
+	// serialize with concurrent writers and readers of the key.
+	for _, k := range gcr.ExpiredKeys {
+		latchSpans.AddMVCC(spanset.SpanReadWrite, roachpb.Span{Key: k.Key}, hlc.MaxTimestamp)
+	}
+	// Expired spans are cleared wholesale, so we also need to prevent keys
+	// from being added to them.
+	for _, sp := range gcr.ExpiredSpans {
+		latchSpans.AddMVCC(spanset.SpanReadWrite, roachpb.Span{Key: sp.StartKey, EndKey: sp.EndKey},
+			hlc.MaxTimestamp)
+	}
 	// The RangeGCThresholdKey is only written to if the
 	// req.(*GCRequest).Threshold is set. However, we always declare an exclusive
 	// access over this key in order to serialize with other GC requests.
@@ -166,7 +180,8 @@ func GC(
 	//    GC request's effect from the raft log. Latches held on the leaseholder
 	//    would have no impact on a follower read.
 	if !args.Threshold.IsEmpty() &&
-		(len(args.Keys) != 0 || len(args.RangeKeys) != 0 || args.ClearRange != nil) &&
+		(len(args.Keys) != 0 || len(args.RangeKeys) != 0 || args.ClearRange != nil ||
+			len(args.ExpiredKeys) != 0 || len(args.ExpiredSpans) != 0) &&
 		!cArgs.EvalCtx.EvalKnobs().AllowGCWithNewThresholdAndKeys {
 		return result.Result{}, errors.AssertionFailedf(
 			"GC request can set threshold or it can GC keys, but it is unsafe for it to do both")
@@ -210,6 +225,43 @@ func GC(
 
 	desc := cArgs.EvalCtx.Desc()
 
+	// Expire the specified keys and spans under the storage TTL. Only global
+	// keys are subject to a storage TTL.
+	if len(args.ExpiredKeys) != 0 || len(args.ExpiredSpans) != 0 {
+		expiredKeys := make([]kvpb.GCRequest_GCKey, 0, len(args.ExpiredKeys))
+		for _, k := range args.ExpiredKeys {
+			if cArgs.EvalCtx.ContainsKey(k.Key) && !keys.IsLocal(k.Key) {
+				expiredKeys = append(expiredKeys, k)
+			}
+		}
+		// The expiries are published on rangefeeds at this timestamp, which
+		// must be above the range's resolved timestamp. The request's
+		// timestamp was forwarded above the closed timestamp before it was
+		// evaluated, since the request applies the timestamp cache, but we
+		// forward it here too: an expiry at or below the closed timestamp,
+		// e.g. on a range whose closed timestamp leads present time, would
+		// violate an assertion of the rangefeed processor.
+		expiredAt := h.Timestamp
+		expiredAt.Forward(cArgs.EvalCtx.GetCurrentClosedTimestamp(ctx).Next())
+		numExpired, err := storage.MVCCExpire(
+			ctx, readWriter, cArgs.Stats, expiredKeys, expiredAt,
+		)
+		if err != nil {
+			return result.Result{}, err
+		}
+		for _, sp := range args.ExpiredSpans {
+			if keys.IsLocal(sp.StartKey) || !kvserverbase.ContainsKeyRange(desc, sp.StartKey, sp.EndKey) {
+				continue
+			}
+			n, err := storage.MVCCExpireSpan(ctx, readWriter, cArgs.Stats, sp, expiredAt)
+			if err != nil {
+				return result.Result{}, err
+			}
+			numExpired += n
+		}
+		resp.(*kvpb.GCResponse).NumKeysExpired = numExpired
+	}
+
 	if cr := args.ClearRange; cr != nil {
 		// Check if we are performing a fast path operation to try to remove all user
 		// key data from the range. All data must be deleted by a range tombstone for
@@ -0,0 +1,60 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/lockspanset"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+)
+
+func init() {
+	RegisterReadOnlyCommand(kvpb.ReadIndex, declareKeysReadIndex, ReadIndex)
+}
+
+func declareKeysReadIndex(
+	_ ImmutableRangeState,
+	header *kvpb.Header,
+	req kvpb.Request,
+	latchSpans *spanset.SpanSet,
+	_ *lockspanset.LockSpanSet,
+	_ time.Duration,
+) error {
+	// Acquire read latches up to the uncertainty limit of the follower read,
+	// like the read would if it was served by the leaseholder. This waits for
+	// the writes that the read may observe to apply before the lease applied
+	// index is returned. No lock spans are declared: the follower handles the
+	// intents that it encounters itself.
+	args := req.(*kvpb.ReadIndexRequest)
+	ts := header.Timestamp
+	ts.Forward(args.UncertaintyLimit)
+	latchSpans.AddMVCC(spanset.SpanReadOnly, req.Header().Span(), ts)
+	return nil
+}
+
+// ReadIndex returns the lease applied index of the range. Through the latches
+// declared by the request, it includes every write that a read on a follower
+// at the request's timestamp and uncertainty limit must observe. The timestamp
+// cache is updated over the span once the request has evaluated, so no later
+// write can change what the read observes.
+func ReadIndex(
+	_ context.Context, _ storage.Reader, cArgs CommandArgs, resp kvpb.Response,
+) (result.Result, error) {
+	reply := resp.(*kvpb.ReadIndexResponse)
+	reply.RangeID = cArgs.EvalCtx.GetRangeID()
+	reply.LeaseAppliedIndex = cArgs.EvalCtx.GetLeaseAppliedIndex()
+	return result.Result{}, nil
+}
@@ -488,6 +488,96 @@ func TestClosedTimestampCantServeBasedOnUncertaintyLimit(t *testing.T) {
 	verifyNotLeaseHolderErrors(t, baRead, repls, 2)
 }
 
+// TestFollowerReadsWithReadIndex verifies that followers serve reads above
+// their closed timestamp that opt into a read index, and that these reads
+// observe the writes that precede them.
+func TestFollowerReadsWithReadIndex(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	// Limiting how long transactions can run does not work
+	// well with race unless we're extremely lenient, which
+	// drives up the test duration.
+	skip.UnderRace(t)
+
+	ctx := context.Background()
+	// Use a target duration long enough for followers to never be able to serve
+	// present time reads under the closed timestamp.
+	cArgs := aggressiveResolvedTimestampManuallyReplicatedClusterArgs
+	tc, db0, desc := setupClusterForClosedTSTesting(ctx, t, time.Hour, testingSideTransportInterval, cArgs, "cttest", "kv")
+	defer tc.Stopper().Stop(ctx)
+	repls := replsForRange(ctx, t, tc, desc)
+
+	var baRead *kvpb.BatchRequest
+	for i := 1; i <= 2; i++ {
+		_, err := db0.Exec(`INSERT INTO cttest.kv VALUES($1, 'foo')`, i)
+		require.NoError(t, err)
+
+		baRead = makeTxnReadBatchForDesc(desc, tc.Server(0).Clock().Now())
+		// Only the leaseholder can serve the read without a read index.
+		verifyNotLeaseHolderErrors(t, baRead, repls, 2)
+		baRead.AllowReadIndex = true
+		require.NoError(t, verifyCanReadFromAllRepls(ctx, t, baRead, repls, expectRows(i)))
+	}
+	var readIndexReads int64
+	for _, repl := range repls {
+		readIndexReads += repl.Store().Metrics().FollowerReadIndexCount.Count()
+	}
+	require.LessOrEqual(t, int64(4), readIndexReads)
+
+	_, err := db0.Exec(`SET CLUSTER SETTING kv.follower_reads.read_index.enabled = false`)
+	require.NoError(t, err)
+	testutils.SucceedsSoon(t, func() error {
+		if n, err := countNotLeaseHolderErrors(baRead, repls); err != nil {
+			return err
+		} else if n != 2 {
+			return errors.Errorf("expected 2 NotLeaseHolderErrors, found %d", n)
+		}
+		return nil
+	})
+}
+
+// TestFollowerReadsWithReadIndexTimeout verifies that a follower that can't
+// confirm a read index in time, because the leaseholder does not respond,
+// redirects the read to the leaseholder.
+func TestFollowerReadsWithReadIndexTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	skip.UnderRace(t)
+
+	ctx := context.Background()
+	var blockReadIndex atomic.Bool
+	unblock := make(chan struct{})
+	knobs := aggressiveResolvedTimestampPushKnobs()
+	knobs.TestingRequestFilter = func(ctx context.Context, ba *kvpb.BatchRequest) *kvpb.Error {
+		if _, ok := ba.GetArg(kvpb.ReadIndex); ok && blockReadIndex.Load() {
+			select {
+			case <-unblock:
+			case <-ctx.Done():
+			}
+		}
+		return nil
+	}
+	cArgs := aggressiveResolvedTimestampManuallyReplicatedClusterArgs
+	cArgs.ServerArgs.Knobs.Store = knobs
+	tc, db0, desc := setupClusterForClosedTSTesting(ctx, t, time.Hour, testingSideTransportInterval, cArgs, "cttest", "kv")
+	defer tc.Stopper().Stop(ctx)
+	defer close(unblock)
+	repls := replsForRange(ctx, t, tc, desc)
+
+	_, err := db0.Exec(`INSERT INTO cttest.kv VALUES(1, 'foo')`)
+	require.NoError(t, err)
+
+	blockReadIndex.Store(true)
+	baRead := makeTxnReadBatchForDesc(desc, tc.Server(0).Clock().Now())
+	baRead.AllowReadIndex = true
+	verifyNotLeaseHolderErrors(t, baRead, repls, 2)
+	var readIndexErrors int64
+	for _, repl := range repls {
+		readIndexErrors += repl.Store().Metrics().FollowerReadIndexErrorCount.Count()
+	}
+	require.Equal(t, int64(2), readIndexErrors)
+}
+
 func TestClosedTimestampCanServeForWritingTransaction(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -443,6 +443,12 @@ type Guard struct {
 	ltg lockTableGuard
 	// The latest RequestEvalKind passed to SequenceReq.
 	EvalKind RequestEvalKind
+	// LatchWait is the total time the request spent waiting to acquire
+	// latches while being sequenced.
+	LatchWait time.Duration
+	// LockWait is the total time the request spent waiting in lock
+	// wait-queues while being sequenced.
+	LockWait time.Duration
 }
 
 // Response is a slice of responses to requests in a batch. This type is used
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
@@ -288,7 +289,9 @@ func (m *managerImpl) sequenceReqWithGuard(
 				// Acquire latches for the request. This synchronizes the request
 				// with all conflicting in-flight requests.
 				log.Event(ctx, "acquiring latches")
+				start := timeutil.Now()
 				g.lg, err = m.lm.Acquire(ctx, g.Req)
+				g.LatchWait += timeutil.Since(start)
 				if err != nil {
 					return nil, err
 				}
@@ -305,7 +308,9 @@ func (m *managerImpl) sequenceReqWithGuard(
 					g.EvalKind, g.HoldingLatches(), branch, firstIteration, string(debug.Stack()))))
 			}
 			log.Event(ctx, "optimistic failed, so waiting for latches")
+			start := timeutil.Now()
 			g.lg, err = m.lm.WaitUntilAcquired(ctx, g.lg)
+			g.LatchWait += timeutil.Since(start)
 			if err != nil {
 				return nil, err
 			}
@@ -345,7 +350,10 @@ func (m *managerImpl) sequenceReqWithGuard(
 			m.lm.Release(g.moveLatchGuard())
 
 			log.Event(ctx, "waiting in lock wait-queues")
-			if err := m.ltw.WaitOn(ctx, g.Req, g.ltg); err != nil {
+			start := timeutil.Now()
+			err = m.ltw.WaitOn(ctx, g.Req, g.ltg)
+			g.LockWait += timeutil.Since(start)
+			if err != nil {
 				return nil, err
 			}
 			continue
@@ -46,6 +46,15 @@ var consistencyCheckRate = settings.RegisterByteSizeSetting(
 	settings.PositiveInt,
 	settings.WithPublic)
 
+var consistencyCheckRepairEnabled = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"server.consistency_check.repair.enabled",
+	"if enabled, replicas which diverge from a quorum of their peers are removed "+
+		"from the range and replaced via snapshot, instead of terminating their node; "+
+		"storage checkpoints of the range are kept for investigation either way",
+	false,
+	settings.WithPublic)
+
 // consistencyCheckRateBurstFactor we use this to set the burst parameter on the
 // quotapool.RateLimiter. It seems overkill to provide a user setting for this,
 // so we use a factor to scale the burst setting based on the rate defined above.
@@ -180,10 +180,18 @@ type PureGCer interface {
 	) error
 }
 
+// Expirer is part of the GCer interface. It removes keys and spans of keys
+// that expired under a storage TTL, see kvpb.GCRequest.ExpiredKeys and
+// kvpb.GCRequest.ExpiredSpans, and returns the number of keys it expired.
+type Expirer interface {
+	Expire(context.Context, []kvpb.GCRequest_GCKey, []kvpb.GCRequest_GCRangeKey) (int, error)
+}
+
 // A GCer is an abstraction used by the MVCC GC queue to carry out chunked deletions.
 type GCer interface {
 	Thresholder
 	PureGCer
+	Expirer
 }
 
 // NoopGCer implements GCer by doing nothing.
@@ -204,6 +212,13 @@ func (NoopGCer) GC(
 	return nil
 }
 
+// Expire implements storage.GCer.
+func (NoopGCer) Expire(
+	context.Context, []kvpb.GCRequest_GCKey, []kvpb.GCRequest_GCRangeKey,
+) (int, error) {
+	return 0, nil
+}
+
 // Threshold holds the key and txn span GC thresholds, respectively.
 type Threshold struct {
 	Key hlc.Timestamp
@@ -266,6 +281,9 @@ type Info struct {
 	ClearRangeSpanOperations int
 	// ClearRangeSpanFailures number of ClearRange requests GC failed to perform.
 	ClearRangeSpanFailures int
+	// NumKeysExpired is the number of live keys removed because they expired
+	// under the storage TTL.
+	NumKeysExpired int
 }
 
 // RunOptions contains collection of limits that GC run applies when performing operations
@@ -305,6 +323,9 @@ type RunOptions struct {
 	// to issuing point delete requests for the oldest batch to free up memory
 	// before resuming further iteration.
 	MaxPendingKeysSize int64
+	// StorageTTL is the age after which live keys expire and are removed. 0
+	// means keys never expire.
+	StorageTTL time.Duration
 }
 
 // CleanupIntentsFunc synchronously resolves the supplied intents
@@ -380,6 +401,21 @@ func Run(
 		}
 		return Info{}, err
 	}
+	// If the fast path cleared the user key span, there is nothing left to
+	// expire.
+	if options.StorageTTL > 0 && !fastPath {
+		// Keys must not expire below a protected timestamp.
+		expiration := CalculateThreshold(now, options.StorageTTL)
+		expiration.Backward(newThreshold)
+		err = processExpiredKeys(ctx, desc, snap, expiration, options.MaxKeyVersionChunkBytes,
+			options.ClearRangeMinKeys, gcer, &info)
+		if err != nil {
+			if errors.Is(err, pebble.ErrSnapshotExcised) {
+				err = benignerror.NewStoreBenign(err)
+			}
+			return Info{}, err
+		}
+	}
 
 	// From now on, all keys processed are range-local and inline (zero timestamp).
 
@@ -1380,6 +1416,139 @@ func (b *rangeKeyBatcher) flushPendingFragments(ctx context.Context) error {
 	return nil
 }
 
+// processExpiredKeys identifies live keys whose newest version is at or below
+// the expiration timestamp, and sends requests to expire them. Keys which are
+// deleted, or have an intent, are left to the rest of the GC process, unless
+// they are cleared along with a span of expired keys. Keys covered by a range
+// tombstone are deleted, and are left too.
+//
+// Ideally, expired keys would be dropped by Pebble compactions, without
+// writing anything, but Pebble offers no compaction filter to do so. Instead,
+// runs of at least clearRangeMinKeys consecutive keys that are all expired or
+// deleted at or below the expiration are sent as spans, which are cleared
+// with a single Pebble range tombstone that compactions drop wholesale, and
+// the other expired keys are sent individually and removed with point
+// tombstones. A run is cut when its keys reach batchBytes, to bound the work
+// of the request that clears it.
+func processExpiredKeys(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	snap storage.Reader,
+	expiration hlc.Timestamp,
+	batchBytes int64,
+	clearRangeMinKeys int64,
+	gcer Expirer,
+	info *Info,
+) error {
+	if batchBytes == 0 {
+		batchBytes = KeyVersionChunkBytes
+	}
+	span := desc.KeySpan().AsRawSpanWithNoLocals()
+	iter, err := snap.NewMVCCIterator(storage.MVCCKeyAndIntentsIterKind, storage.IterOptions{
+		LowerBound: span.Key,
+		UpperBound: span.EndKey,
+		KeyTypes:   storage.IterKeyTypePointsAndRanges,
+	})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	var batchKeys []kvpb.GCRequest_GCKey
+	var batchSpans []kvpb.GCRequest_GCRangeKey
+	var size int64
+	flush := func() error {
+		if len(batchKeys) == 0 && len(batchSpans) == 0 {
+			return nil
+		}
+		expired, err := gcer.Expire(ctx, batchKeys, batchSpans)
+		batchKeys, batchSpans, size = nil, nil, 0
+		info.NumKeysExpired += expired
+		return err
+	}
+
+	// The current run of consecutive keys which are expired or deleted at or
+	// below the expiration, the last key of the run, and the live keys in it.
+	var runStart, runEnd roachpb.Key
+	var runKeys, runSize int64
+	var runLive []kvpb.GCRequest_GCKey
+	endRun := func() error {
+		if runKeys == 0 {
+			return nil
+		}
+		if clearRangeMinKeys > 0 && runKeys >= clearRangeMinKeys {
+			batchSpans = append(batchSpans, kvpb.GCRequest_GCRangeKey{
+				StartKey:  runStart,
+				EndKey:    runEnd.Next(),
+				Timestamp: expiration,
+			})
+			size += int64(len(runStart)+len(runEnd)+1) + hlcTimestampSize
+		} else {
+			for _, k := range runLive {
+				batchKeys = append(batchKeys, k)
+				size += int64(len(k.Key)) + hlcTimestampSize
+			}
+		}
+		runStart, runEnd, runKeys, runSize, runLive = nil, nil, 0, 0, nil
+		if size >= batchBytes {
+			return flush()
+		}
+		return nil
+	}
+
+	for iter.SeekGE(storage.MakeMVCCMetadataKey(span.Key)); ; {
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		hasPoint, hasRange := iter.HasPointAndRange()
+		if !hasPoint {
+			iter.Next()
+			continue
+		}
+		key := iter.UnsafeKey()
+		if hasRange || !key.IsValue() || expiration.Less(key.Timestamp) {
+			// The key breaks the run.
+			if err := endRun(); err != nil {
+				return err
+			}
+			iter.NextKey()
+			continue
+		}
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return err
+		}
+		isTombstone, err := storage.EncodedMVCCValueIsTombstone(v)
+		if err != nil {
+			return err
+		}
+		runEnd = key.Key.Clone()
+		if runKeys == 0 {
+			runStart = runEnd
+		}
+		runKeys++
+		runSize += int64(len(runEnd)) + hlcTimestampSize
+		if !isTombstone {
+			runLive = append(runLive, kvpb.GCRequest_GCKey{
+				Key:       runEnd,
+				Timestamp: key.Timestamp,
+			})
+		}
+		if runSize >= batchBytes {
+			if err := endRun(); err != nil {
+				return err
+			}
+		}
+		iter.NextKey()
+	}
+	if err := endRun(); err != nil {
+		return err
+	}
+	return flush()
+}
+
 func processReplicatedRangeTombstones(
 	ctx context.Context,
 	desc *roachpb.RangeDescriptor,
@@ -820,6 +820,8 @@ type fakeGCer struct {
 	// non-overlapping.
 	gcRangeKeyBatches [][]kvpb.GCRequest_GCRangeKey
 	gcClearRanges     []kvpb.GCRequest_GCClearRange
+	expiredKeys       []kvpb.GCRequest_GCKey
+	expiredSpans      []kvpb.GCRequest_GCRangeKey
 	threshold         Threshold
 	intents           []roachpb.Intent
 	batches           [][]roachpb.Intent
@@ -864,6 +866,16 @@ func (f *fakeGCer) GC(
 	return nil
 }
 
+// Expire records the expired keys and spans. Only the keys are counted as
+// expired, as the fake doesn't know which keys the spans contain.
+func (f *fakeGCer) Expire(
+	ctx context.Context, keys []kvpb.GCRequest_GCKey, spans []kvpb.GCRequest_GCRangeKey,
+) (int, error) {
+	f.expiredKeys = append(f.expiredKeys, keys...)
+	f.expiredSpans = append(f.expiredSpans, spans...)
+	return len(keys), nil
+}
+
 func (f *fakeGCer) resolveIntentsAsync(_ context.Context, txn *roachpb.Transaction) error {
 	f.txnIntents = append(f.txnIntents, txnIntents{txn: txn, intents: txn.LocksAsLockUpdates()})
 	return nil
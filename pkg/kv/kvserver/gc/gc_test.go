@@ -180,6 +180,107 @@ func TestIntentAgeThresholdSetting(t *testing.T) {
 	require.Equal(t, 2, len(gcer.intents))
 }
 
+// TestStorageTTL verifies that GC expires live keys whose newest version is
+// older than the storage TTL, and leaves deleted keys, keys with intents and
+// newer keys alone.
+func TestStorageTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	eng := storage.NewDefaultInMemForTesting()
+	defer eng.Close()
+
+	ts := func(d time.Duration) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: d.Nanoseconds()}
+	}
+	value := roachpb.MakeValueFromString("v")
+	put := func(key string, at time.Duration) {
+		require.NoError(t, storage.MVCCPut(ctx, eng, roachpb.Key(key), ts(at), value, storage.MVCCWriteOptions{}))
+	}
+	// a is live and expired, and has an older version.
+	put("a", time.Minute)
+	put("a", 2*time.Minute)
+	// b is live, but was written after the expiration.
+	put("b", 50*time.Minute)
+	// c is deleted, which is left to regular GC.
+	put("c", time.Minute)
+	_, err := storage.MVCCDelete(ctx, eng, roachpb.Key("c"), ts(2*time.Minute), storage.MVCCWriteOptions{})
+	require.NoError(t, err)
+	// d has an intent on top of an expired version.
+	put("d", time.Minute)
+	txn := roachpb.MakeTransaction("txn", roachpb.Key("d"), isolation.Serializable,
+		roachpb.NormalUserPriority, ts(3*time.Minute), 1000, 0, 0)
+	require.NoError(t, storage.MVCCPut(ctx, eng, roachpb.Key("d"), ts(3*time.Minute), value,
+		storage.MVCCWriteOptions{Txn: &txn}))
+	// e is live and expired.
+	put("e", 10*time.Minute)
+
+	desc := roachpb.RangeDescriptor{
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("z"),
+	}
+	snap := eng.NewSnapshot()
+	defer snap.Close()
+	now := ts(time.Hour)
+	gcer := makeFakeGCer()
+	info, err := Run(ctx, &desc, snap, now, CalculateThreshold(now, 30*time.Minute),
+		RunOptions{
+			IntentAgeThreshold:  time.Hour,
+			TxnCleanupThreshold: txnCleanupThreshold,
+			StorageTTL:          30 * time.Minute,
+		}, 30*time.Minute, &gcer, gcer.resolveIntents, gcer.resolveIntentsAsync)
+	require.NoError(t, err)
+	require.Equal(t, 2, info.NumKeysExpired)
+	require.Equal(t, []kvpb.GCRequest_GCKey{
+		{Key: roachpb.Key("a"), Timestamp: ts(2 * time.Minute)},
+		{Key: roachpb.Key("e"), Timestamp: ts(10 * time.Minute)},
+	}, gcer.expiredKeys)
+
+	// Keys don't expire above a protected timestamp, which holds back the GC
+	// threshold.
+	gcer = makeFakeGCer()
+	info, err = Run(ctx, &desc, snap, now, ts(5*time.Minute),
+		RunOptions{
+			IntentAgeThreshold:  time.Hour,
+			TxnCleanupThreshold: txnCleanupThreshold,
+			StorageTTL:          30 * time.Minute,
+		}, 30*time.Minute, &gcer, gcer.resolveIntents, gcer.resolveIntentsAsync)
+	require.NoError(t, err)
+	require.Equal(t, 1, info.NumKeysExpired)
+	require.Equal(t, []kvpb.GCRequest_GCKey{
+		{Key: roachpb.Key("a"), Timestamp: ts(2 * time.Minute)},
+	}, gcer.expiredKeys)
+
+	// Runs of consecutive expired or deleted keys are expired as spans, if
+	// they have enough keys. f-h extend the run of e, which also covers the
+	// deleted key g, while the run of c has too few keys.
+	put("f", 5*time.Minute)
+	put("g", time.Minute)
+	_, err = storage.MVCCDelete(ctx, eng, roachpb.Key("g"), ts(3*time.Minute), storage.MVCCWriteOptions{})
+	require.NoError(t, err)
+	put("h", 20*time.Minute)
+	snap = eng.NewSnapshot()
+	defer snap.Close()
+	gcer = makeFakeGCer()
+	info, err = Run(ctx, &desc, snap, now, CalculateThreshold(now, 30*time.Minute),
+		RunOptions{
+			IntentAgeThreshold:  time.Hour,
+			TxnCleanupThreshold: txnCleanupThreshold,
+			StorageTTL:          30 * time.Minute,
+			ClearRangeMinKeys:   3,
+		}, 30*time.Minute, &gcer, gcer.resolveIntents, gcer.resolveIntentsAsync)
+	require.NoError(t, err)
+	// The fake GCer only counts the keys which aren't part of a span.
+	require.Equal(t, 1, info.NumKeysExpired)
+	require.Equal(t, []kvpb.GCRequest_GCKey{
+		{Key: roachpb.Key("a"), Timestamp: ts(2 * time.Minute)},
+	}, gcer.expiredKeys)
+	expiration := CalculateThreshold(now, 30*time.Minute)
+	require.Equal(t, []kvpb.GCRequest_GCRangeKey{
+		{StartKey: roachpb.Key("e"), EndKey: roachpb.Key("h").Next(), Timestamp: expiration},
+	}, gcer.expiredSpans)
+}
+
 func TestIntentCleanupBatching(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -166,7 +166,7 @@ func TestDataDriven(t *testing.T) {
 					fmt.Fprintln(&buf, desc)
 				}
 			case "load-and-reconcile":
-				replicas, err := LoadAndReconcileReplicas(ctx, e.eng)
+				replicas, err := LoadAndReconcileReplicas(ctx, e.eng, e.eng)
 				if err != nil {
 					fmt.Fprintln(&buf, err)
 					break
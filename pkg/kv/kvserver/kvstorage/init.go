@@ -20,7 +20,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/logstore"
-	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -337,20 +336,16 @@ func (r Replica) ID() storage.FullReplicaID {
 	}
 }
 
-// Load loads the state necessary to instantiate a replica in memory.
+// Load loads the state necessary to instantiate a replica in memory. The raft
+// state is loaded from logReader, see LoadReplicaState.
 func (r Replica) Load(
-	ctx context.Context, eng storage.Reader, storeID roachpb.StoreID,
+	ctx context.Context, eng, logReader storage.Reader, storeID roachpb.StoreID,
 ) (LoadedReplicaState, error) {
 	ls := LoadedReplicaState{
 		ReplicaID: r.ReplicaID,
 		hardState: r.hardState,
 	}
-	sl := stateloader.Make(r.Desc.RangeID)
-	var err error
-	if ls.LastIndex, err = sl.LoadLastIndex(ctx, eng); err != nil {
-		return LoadedReplicaState{}, err
-	}
-	if ls.ReplState, err = sl.Load(ctx, eng, r.Desc); err != nil {
+	if err := ls.load(ctx, eng, logReader, r.Desc); err != nil {
 		return LoadedReplicaState{}, err
 	}
 
@@ -391,7 +386,7 @@ func (m replicaMap) setDesc(rangeID roachpb.RangeID, desc roachpb.RangeDescripto
 	return nil
 }
 
-func loadReplicas(ctx context.Context, eng storage.Engine) ([]Replica, error) {
+func loadReplicas(ctx context.Context, eng, logEng storage.Engine) ([]Replica, error) {
 	s := replicaMap{}
 
 	// INVARIANT: the latest visible committed version of the RangeDescriptor
@@ -444,7 +439,7 @@ func loadReplicas(ctx context.Context, eng storage.Engine) ([]Replica, error) {
 		}
 
 		var hs raftpb.HardState
-		if err := IterateIDPrefixKeys(ctx, eng, func(rangeID roachpb.RangeID) roachpb.Key {
+		if err := IterateIDPrefixKeys(ctx, logEng, func(rangeID roachpb.RangeID) roachpb.Key {
 			return keys.RaftHardStateKey(rangeID)
 		}, &hs, func(rangeID roachpb.RangeID) error {
 			s.setHardState(rangeID, hs)
@@ -467,14 +462,18 @@ func loadReplicas(ctx context.Context, eng storage.Engine) ([]Replica, error) {
 // store. It reconciles inconsistent state and runs validation checks.
 // The returned slice is sorted by ReplicaID.
 //
+// The raft state of the replicas is read from logEng, which is the same
+// engine as eng unless the store keeps its raft logs in a separate engine. In
+// that case, ReconcileRaftLogEngine must have been called first.
+//
 // TODO(sep-raft-log): consider a callback-visitor pattern here.
-func LoadAndReconcileReplicas(ctx context.Context, eng storage.Engine) ([]Replica, error) {
+func LoadAndReconcileReplicas(ctx context.Context, eng, logEng storage.Engine) ([]Replica, error) {
 	ident, err := ReadStoreIdent(ctx, eng)
 	if err != nil {
 		return nil, err
 	}
 
-	sl, err := loadReplicas(ctx, eng)
+	sl, err := loadReplicas(ctx, eng, logEng)
 	if err != nil {
 		return nil, err
 	}
@@ -528,7 +527,7 @@ func LoadAndReconcileReplicas(ctx context.Context, eng storage.Engine) ([]Replic
 			// TODO(tbg): if clearRangeData were in this package we could destroy more
 			// effectively even if for some reason we had in the past written state
 			// other than the HardState here (not supposed to happen, but still).
-			if err := eng.ClearUnversioned(logstore.NewStateLoader(repl.RangeID).RaftHardStateKey(), storage.ClearOptions{}); err != nil {
+			if err := logEng.ClearUnversioned(logstore.NewStateLoader(repl.RangeID).RaftHardStateKey(), storage.ClearOptions{}); err != nil {
 				return nil, errors.Wrapf(err, "removing HardState for r%d", repl.RangeID)
 			}
 			log.Eventf(ctx, "removed legacy uninitialized replica for r%s", repl.RangeID)
@@ -0,0 +1,474 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvstorage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/raftlog"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/stateloader"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// A store can keep its raft logs in a raft log engine that is separate from
+// the state engine holding the rest of its data. The raft log engine then holds
+// the HardState, the RaftTruncatedState and the raft log entries of each
+// replica (see RaftStateSpans). Everything else, including the RaftReplicaID,
+// the RangeTombstone and the RangeAppliedState, stays in the state engine.
+//
+// Writes to the two engines are not atomic, so the code writing them observes
+// the following rules, which ReconcileRaftLogEngine relies upon to restore a
+// consistent state after a crash:
+//
+//   - raft log entries are durable in the log engine before they are applied
+//     to the state engine.
+//   - raft log entries are truncated from the log engine only once the
+//     RangeAppliedState reflecting their application is durable in the state
+//     engine.
+//   - raft state that must be written atomically with the applied state, such
+//     as when a snapshot is applied or a split creates a replica, is written to
+//     the state engine and then moved to the log engine by MoveRaftState.
+//   - when a replica is destroyed, its raft state is removed from the log
+//     engine after its RaftReplicaID has durably been removed from the state
+//     engine.
+
+// moveRaftStateBatchSize is the size of the log engine batch beyond which
+// MoveRaftState commits the raft state that it has copied so far, before
+// moving on to the remaining ranges.
+const moveRaftStateBatchSize = 32 << 20 // 32 MiB
+
+// RaftStateSpans returns the spans of the raft state of the given range that
+// are kept in the raft log engine, if the store has one.
+func RaftStateSpans(rangeID roachpb.RangeID) []roachpb.Span {
+	hardState := keys.RaftHardStateKey(rangeID)
+	logPrefix := keys.RaftLogPrefix(rangeID)
+	truncatedState := keys.RaftTruncatedStateKey(rangeID)
+	return []roachpb.Span{
+		{Key: hardState, EndKey: hardState.Next()},
+		{Key: logPrefix, EndKey: logPrefix.PrefixEnd()},
+		{Key: truncatedState, EndKey: truncatedState.Next()},
+	}
+}
+
+// MoveRaftState moves the raft state of the given ranges from the state engine
+// to the log engine. For each range that has raft state in the state engine,
+// that state replaces the raft state that the log engine holds for the range.
+// Ranges without raft state in the state engine are left alone.
+//
+// The raft state is durably written to the log engine before it is removed
+// from the state engine, so a move that is interrupted by a crash is completed
+// by moving again.
+func MoveRaftState(
+	ctx context.Context, stateEng, logEng storage.Engine, rangeIDs ...roachpb.RangeID,
+) error {
+	for len(rangeIDs) > 0 {
+		n, err := moveRaftStateBatch(ctx, stateEng, logEng, rangeIDs)
+		if err != nil {
+			return err
+		}
+		rangeIDs = rangeIDs[n:]
+	}
+	return nil
+}
+
+// moveRaftStateBatch moves the raft state of a prefix of the given ranges, and
+// returns the length of that prefix.
+func moveRaftStateBatch(
+	ctx context.Context, stateEng, logEng storage.Engine, rangeIDs []roachpb.RangeID,
+) (int, error) {
+	logBatch := logEng.NewUnindexedBatch()
+	defer logBatch.Close()
+	var moved []roachpb.RangeID
+	var n int
+	for n < len(rangeIDs) && logBatch.Len() < moveRaftStateBatchSize {
+		rangeID := rangeIDs[n]
+		n++
+		found, err := hasRaftState(stateEng, rangeID)
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			continue
+		}
+		for _, span := range RaftStateSpans(rangeID) {
+			if err := storage.ClearRangeWithHeuristic(
+				logEng, logBatch, span.Key, span.EndKey,
+				ClearRangeThresholdPointKeys, ClearRangeThresholdRangeKeys,
+			); err != nil {
+				return 0, err
+			}
+			if err := copySpan(stateEng, logBatch, span); err != nil {
+				return 0, err
+			}
+		}
+		moved = append(moved, rangeID)
+	}
+	if len(moved) == 0 {
+		return n, nil
+	}
+	if err := logBatch.Commit(true /* sync */); err != nil {
+		return 0, errors.Wrap(err, "writing raft state to raft log engine")
+	}
+
+	stateBatch := stateEng.NewUnindexedBatch()
+	defer stateBatch.Close()
+	for _, rangeID := range moved {
+		if err := clearRaftState(stateEng, stateBatch, rangeID); err != nil {
+			return 0, err
+		}
+	}
+	if err := stateBatch.Commit(true /* sync */); err != nil {
+		return 0, errors.Wrap(err, "removing raft state from state engine")
+	}
+	log.VEventf(ctx, 2, "moved raft state of %d ranges to raft log engine", len(moved))
+	return n, nil
+}
+
+// ClearRaftState removes the raft state of the given range from the log
+// engine. It is used once a replica is destroyed and its RaftReplicaID has
+// durably been removed from the state engine.
+func ClearRaftState(ctx context.Context, logEng storage.Engine, rangeID roachpb.RangeID) error {
+	batch := logEng.NewUnindexedBatch()
+	defer batch.Close()
+	if err := clearRaftState(logEng, batch, rangeID); err != nil {
+		return err
+	}
+	// Sync, so that the raft state of the destroyed replica does not reappear
+	// after a crash and get attributed to a newer replica of the range.
+	return batch.Commit(true /* sync */)
+}
+
+func clearRaftState(reader storage.Reader, writer storage.Writer, rangeID roachpb.RangeID) error {
+	for _, span := range RaftStateSpans(rangeID) {
+		if err := storage.ClearRangeWithHeuristic(
+			reader, writer, span.Key, span.EndKey,
+			ClearRangeThresholdPointKeys, ClearRangeThresholdRangeKeys,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasRaftState returns whether the reader contains any raft state of the
+// given range.
+func hasRaftState(reader storage.Reader, rangeID roachpb.RangeID) (bool, error) {
+	for _, span := range RaftStateSpans(rangeID) {
+		iter, err := reader.NewEngineIterator(storage.IterOptions{
+			LowerBound: span.Key,
+			UpperBound: span.EndKey,
+		})
+		if err != nil {
+			return false, err
+		}
+		valid, err := iter.SeekEngineKeyGE(storage.EngineKey{Key: span.Key})
+		iter.Close()
+		if err != nil || valid {
+			return valid, err
+		}
+	}
+	return false, nil
+}
+
+// copySpan copies the point keys in the given span from the reader to the
+// writer.
+func copySpan(reader storage.Reader, writer storage.Writer, span roachpb.Span) error {
+	iter, err := reader.NewEngineIterator(storage.IterOptions{
+		LowerBound: span.Key,
+		UpperBound: span.EndKey,
+	})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	valid, err := iter.SeekEngineKeyGE(storage.EngineKey{Key: span.Key})
+	for ; valid; valid, err = iter.NextEngineKey() {
+		key, err := iter.UnsafeEngineKey()
+		if err != nil {
+			return err
+		}
+		value, err := iter.UnsafeValue()
+		if err != nil {
+			return err
+		}
+		if err := writer.PutEngineKey(key, value); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// ReconcileRaftLogEngine brings the raft log engine of a store in line with
+// its state engine. It must be called when the store starts, before its
+// replicas are loaded. If the log engine is the state engine, it only verifies
+// that the store has never kept its raft logs in a separate engine; moving the
+// raft logs back into the state engine is not supported.
+//
+// Otherwise, it
+//   - binds the log engine to the store, and verifies that it was not
+//     replaced by a different or an empty one after the store started to use
+//     it,
+//   - moves all the raft state that the state engine holds to the log engine,
+//     which migrates a store that is started with a separate raft log engine
+//     for the first time, and completes moves that were interrupted by a
+//     crash,
+//   - removes the raft state of destroyed replicas, and reconciles the raft
+//     state of each replica with its RangeAppliedState.
+func ReconcileRaftLogEngine(ctx context.Context, stateEng, logEng storage.Engine) error {
+	separated, err := loadRaftLogEngineMarker(ctx, stateEng)
+	if err != nil {
+		return err
+	}
+	if logEng == stateEng {
+		if separated {
+			return errors.Errorf(
+				"store %s keeps its raft logs in a separate engine and must be started with its raft log directory",
+				stateEng)
+		}
+		return nil
+	}
+
+	ident, err := ReadStoreIdent(ctx, stateEng)
+	if err != nil {
+		return err
+	}
+	if logIdent, err := ReadStoreIdent(ctx, logEng); err == nil {
+		if logIdent != ident {
+			return errors.Errorf("raft log engine %s belongs to store %s, not to %s", logEng, logIdent, ident)
+		}
+	} else if !errors.HasType(err, (*NotBootstrappedError)(nil)) {
+		return err
+	} else if separated {
+		return errors.Errorf(
+			"raft log engine %s is empty, but store %s keeps its raft logs in a separate engine", logEng, ident)
+	} else if err := InitEngine(ctx, logEng, ident); err != nil {
+		return errors.Wrap(err, "initializing raft log engine")
+	}
+
+	rangeIDs, err := raftStateRangeIDs(ctx, stateEng)
+	if err != nil {
+		return err
+	}
+	if len(rangeIDs) > 0 {
+		log.Infof(ctx, "moving raft state of %d ranges to raft log engine %s", len(rangeIDs), logEng)
+		if err := MoveRaftState(ctx, stateEng, logEng, rangeIDs...); err != nil {
+			return err
+		}
+	}
+	if !separated {
+		if err := setRaftLogEngineMarker(ctx, stateEng); err != nil {
+			return err
+		}
+	}
+
+	rangeIDs, err = raftStateRangeIDs(ctx, logEng)
+	if err != nil {
+		return err
+	}
+	for _, rangeID := range rangeIDs {
+		if err := reconcileRaftState(ctx, stateEng, logEng, rangeID); err != nil {
+			return errors.Wrapf(err, "reconciling raft state of r%d", rangeID)
+		}
+	}
+	return nil
+}
+
+// reconcileRaftState makes the raft state of the given range in the log engine
+// consistent with the state of the range in the state engine.
+func reconcileRaftState(
+	ctx context.Context, stateEng, logEng storage.Engine, rangeID roachpb.RangeID,
+) error {
+	sl := stateloader.Make(rangeID)
+	var replicaID kvserverpb.RaftReplicaID
+	hasReplicaID, err := storage.MVCCGetProto(ctx, stateEng, sl.RaftReplicaIDKey(),
+		hlc.Timestamp{}, &replicaID, storage.MVCCGetOptions{})
+	if err != nil {
+		return err
+	}
+	as, err := sl.LoadRangeAppliedState(ctx, stateEng)
+	if err != nil {
+		return err
+	}
+	if !hasReplicaID && as.RaftAppliedIndex == 0 {
+		// The replica was destroyed, but its raft state was not removed from the
+		// log engine before a crash.
+		log.Eventf(ctx, "removing raft state of destroyed replica r%d", rangeID)
+		return ClearRaftState(ctx, logEng, rangeID)
+	}
+	if as.RaftAppliedIndex == 0 {
+		// An uninitialized replica has no log, and any HardState is valid for it.
+		return nil
+	}
+
+	hs, err := sl.LoadHardState(ctx, logEng)
+	if err != nil {
+		return err
+	}
+	ts, err := sl.LoadRaftTruncatedState(ctx, logEng)
+	if err != nil {
+		return err
+	}
+	applied, appliedTerm := as.RaftAppliedIndex, as.RaftAppliedIndexTerm
+	if applied < ts.Index {
+		return errors.AssertionFailedf(
+			"applied index %d is below the truncated index %d", applied, ts.Index)
+	}
+
+	batch := logEng.NewUnindexedBatch()
+	defer batch.Close()
+	term, found, err := termAt(logEng, rangeID, ts, applied)
+	if err != nil {
+		return err
+	}
+	if appliedTerm != 0 && (!found || term != appliedTerm) {
+		// The log does not contain the applied entry. Discard the log, and
+		// let raft catch up the replica from the leader.
+		log.Warningf(ctx, "r%d: raft log does not match applied index %d at term %d, discarding it",
+			rangeID, applied, appliedTerm)
+		prefix := keys.RaftLogPrefix(rangeID)
+		if err := storage.ClearRangeWithHeuristic(
+			logEng, batch, prefix, prefix.PrefixEnd(),
+			ClearRangeThresholdPointKeys, ClearRangeThresholdRangeKeys,
+		); err != nil {
+			return err
+		}
+		ts = kvserverpb.RaftTruncatedState{Index: applied, Term: appliedTerm}
+		if err := sl.SetRaftTruncatedState(ctx, batch, &ts); err != nil {
+			return err
+		}
+		hs.Commit = uint64(applied)
+	}
+	// The applied state may have become durable before the HardState that
+	// committed the applied entries.
+	newHS := hs
+	if newHS.Commit < uint64(applied) {
+		newHS.Commit = uint64(applied)
+	}
+	if newHS.Term < uint64(ts.Term) {
+		newHS.Term = uint64(ts.Term)
+	}
+	if newHS != hs || !batch.Empty() {
+		if err := sl.SetHardState(ctx, batch, newHS); err != nil {
+			return err
+		}
+		return batch.Commit(true /* sync */)
+	}
+	return nil
+}
+
+// termAt returns the term of the raft log entry at the given index, or the
+// term of the truncated state if the index is the truncated index.
+func termAt(
+	reader storage.Reader,
+	rangeID roachpb.RangeID,
+	ts kvserverpb.RaftTruncatedState,
+	index kvpb.RaftIndex,
+) (_ kvpb.RaftTerm, found bool, _ error) {
+	if index == ts.Index {
+		return ts.Term, true, nil
+	}
+	var term kvpb.RaftTerm
+	if err := raftlog.Visit(reader, rangeID, index, index+1, func(ent raftpb.Entry) error {
+		if kvpb.RaftIndex(ent.Index) == index {
+			term, found = kvpb.RaftTerm(ent.Term), true
+		}
+		return nil
+	}); err != nil {
+		return 0, false, err
+	}
+	return term, found, nil
+}
+
+// raftStateRangeIDs returns the IDs of the ranges that have a HardState or a
+// RaftTruncatedState in the given engine, in ascending order.
+func raftStateRangeIDs(ctx context.Context, reader storage.Reader) ([]roachpb.RangeID, error) {
+	seen := map[roachpb.RangeID]struct{}{}
+	var hs raftpb.HardState
+	if err := IterateIDPrefixKeys(ctx, reader, keys.RaftHardStateKey, &hs,
+		func(rangeID roachpb.RangeID) error {
+			seen[rangeID] = struct{}{}
+			return nil
+		}); err != nil {
+		return nil, err
+	}
+	var ts kvserverpb.RaftTruncatedState
+	if err := IterateIDPrefixKeys(ctx, reader, keys.RaftTruncatedStateKey, &ts,
+		func(rangeID roachpb.RangeID) error {
+			seen[rangeID] = struct{}{}
+			return nil
+		}); err != nil {
+		return nil, err
+	}
+	rangeIDs := make([]roachpb.RangeID, 0, len(seen))
+	for rangeID := range seen {
+		rangeIDs = append(rangeIDs, rangeID)
+	}
+	sort.Slice(rangeIDs, func(i, j int) bool {
+		return rangeIDs[i] < rangeIDs[j]
+	})
+	return rangeIDs, nil
+}
+
+// CheckRaftLogEngine returns an error if the store with the given state engine
+// keeps its raft logs in a separate raft log engine, but logEng is the state
+// engine. Tools that open the engines of a store on their own, such as
+// loss-of-quorum recovery and the debug commands, use it before reading the
+// raft state of the store's replicas.
+func CheckRaftLogEngine(ctx context.Context, stateEng, logEng storage.Reader) error {
+	if logEng != stateEng {
+		return nil
+	}
+	separated, err := loadRaftLogEngineMarker(ctx, stateEng)
+	if err != nil {
+		return err
+	}
+	if separated {
+		return errors.WithHint(
+			errors.Errorf("store %s keeps its raft logs in a separate engine", stateEng),
+			"Specify the raft log directory of the store.")
+	}
+	return nil
+}
+
+// loadRaftLogEngineMarker returns whether the store has moved its raft logs to
+// a separate raft log engine.
+func loadRaftLogEngineMarker(ctx context.Context, stateEng storage.Reader) (bool, error) {
+	res, err := storage.MVCCGet(ctx, stateEng, keys.StoreRaftLogEngineKey(), hlc.Timestamp{},
+		storage.MVCCGetOptions{})
+	if err != nil || res.Value == nil {
+		return false, err
+	}
+	return res.Value.GetBool()
+}
+
+// setRaftLogEngineMarker durably records that the store has moved its raft
+// logs to a separate raft log engine. From then on, the store can't be
+// started without that engine.
+func setRaftLogEngineMarker(ctx context.Context, stateEng storage.Engine) error {
+	batch := stateEng.NewBatch()
+	defer batch.Close()
+	var v roachpb.Value
+	v.SetBool(true)
+	if err := storage.MVCCBlindPut(ctx, batch, keys.StoreRaftLogEngineKey(), hlc.Timestamp{}, v,
+		storage.MVCCWriteOptions{}); err != nil {
+		return err
+	}
+	return batch.Commit(true /* sync */)
+}
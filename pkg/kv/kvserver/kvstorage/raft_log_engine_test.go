@@ -0,0 +1,133 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvstorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/stateloader"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// TestReconcileRaftLogEngine checks that ReconcileRaftLogEngine migrates the
+// raft state of a store to a separate raft log engine, and repairs the raft
+// state that a crash can leave behind in it.
+func TestReconcileRaftLogEngine(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	stateEng := storage.NewDefaultInMemForTesting()
+	defer stateEng.Close()
+	logEng := storage.NewDefaultInMemForTesting()
+	defer logEng.Close()
+
+	ident := roachpb.StoreIdent{ClusterID: uuid.MakeV4(), NodeID: 1, StoreID: 1}
+	require.NoError(t, InitEngine(ctx, stateEng, ident))
+
+	putEntries := func(eng storage.Engine, rangeID roachpb.RangeID, lo, hi, term uint64) {
+		for i := lo; i < hi; i++ {
+			ent := raftpb.Entry{Index: i, Term: term}
+			require.NoError(t, storage.MVCCBlindPutProto(ctx, eng, keys.RaftLogKey(rangeID, kvpb.RaftIndex(i)),
+				hlc.Timestamp{}, &ent, storage.MVCCWriteOptions{}))
+		}
+	}
+	putReplica := func(rangeID roachpb.RangeID, applied, appliedTerm uint64) {
+		sl := stateloader.Make(rangeID)
+		require.NoError(t, sl.SetRaftReplicaID(ctx, stateEng, 1))
+		require.NoError(t, storage.MVCCBlindPutProto(ctx, stateEng, keys.RangeAppliedStateKey(rangeID),
+			hlc.Timestamp{}, &kvserverpb.RangeAppliedState{
+				RaftAppliedIndex:     kvpb.RaftIndex(applied),
+				RaftAppliedIndexTerm: kvpb.RaftTerm(appliedTerm),
+			}, storage.MVCCWriteOptions{}))
+	}
+	putRaftState := func(
+		eng storage.Engine, rangeID roachpb.RangeID, hs raftpb.HardState, ts kvserverpb.RaftTruncatedState,
+	) {
+		sl := stateloader.Make(rangeID)
+		require.NoError(t, sl.SetHardState(ctx, eng, hs))
+		require.NoError(t, sl.SetRaftTruncatedState(ctx, eng, &ts))
+	}
+
+	// r1 keeps its raft state in the state engine, as before the migration.
+	putReplica(1, 8, 5)
+	putRaftState(stateEng, 1, raftpb.HardState{Term: 5, Commit: 10}, kvserverpb.RaftTruncatedState{Index: 5, Term: 5})
+	putEntries(stateEng, 1, 6, 11, 5)
+	// r2 was destroyed, but its raft state was left in the log engine.
+	putRaftState(logEng, 2, raftpb.HardState{Term: 3, Commit: 7}, kvserverpb.RaftTruncatedState{Index: 5, Term: 3})
+	putEntries(logEng, 2, 6, 8, 3)
+	// r3 received a snapshot whose raft state did not make it to the log engine.
+	putReplica(3, 20, 4)
+	putRaftState(logEng, 3, raftpb.HardState{Term: 3, Commit: 10}, kvserverpb.RaftTruncatedState{Index: 8, Term: 3})
+	putEntries(logEng, 3, 9, 11, 3)
+
+	require.NoError(t, ReconcileRaftLogEngine(ctx, stateEng, logEng))
+	// Reconciling is idempotent.
+	require.NoError(t, ReconcileRaftLogEngine(ctx, stateEng, logEng))
+
+	logIdent, err := ReadStoreIdent(ctx, logEng)
+	require.NoError(t, err)
+	require.Equal(t, ident, logIdent)
+	separated, err := loadRaftLogEngineMarker(ctx, stateEng)
+	require.NoError(t, err)
+	require.True(t, separated)
+
+	for _, rangeID := range []roachpb.RangeID{1, 2, 3} {
+		found, err := hasRaftState(stateEng, rangeID)
+		require.NoError(t, err)
+		require.False(t, found, "r%d", rangeID)
+	}
+	found, err := hasRaftState(logEng, 2)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	for _, tc := range []struct {
+		rangeID   roachpb.RangeID
+		hs        raftpb.HardState
+		ts        kvserverpb.RaftTruncatedState
+		lastIndex kvpb.RaftIndex
+	}{
+		{1, raftpb.HardState{Term: 5, Commit: 10}, kvserverpb.RaftTruncatedState{Index: 5, Term: 5}, 10},
+		{3, raftpb.HardState{Term: 4, Commit: 20}, kvserverpb.RaftTruncatedState{Index: 20, Term: 4}, 20},
+	} {
+		sl := stateloader.Make(tc.rangeID)
+		hs, err := sl.LoadHardState(ctx, logEng)
+		require.NoError(t, err)
+		require.Equal(t, tc.hs, hs, "r%d", tc.rangeID)
+		ts, err := sl.LoadRaftTruncatedState(ctx, logEng)
+		require.NoError(t, err)
+		require.Equal(t, tc.ts, ts, "r%d", tc.rangeID)
+		lastIndex, err := sl.LoadLastIndex(ctx, logEng)
+		require.NoError(t, err)
+		require.Equal(t, tc.lastIndex, lastIndex, "r%d", tc.rangeID)
+	}
+
+	// The store can no longer be started without its raft log engine.
+	require.ErrorContains(t, ReconcileRaftLogEngine(ctx, stateEng, stateEng),
+		"must be started with its raft log directory")
+	require.ErrorContains(t, CheckRaftLogEngine(ctx, stateEng, stateEng),
+		"keeps its raft logs in a separate engine")
+	require.NoError(t, CheckRaftLogEngine(ctx, stateEng, logEng))
+	emptyEng := storage.NewDefaultInMemForTesting()
+	defer emptyEng.Close()
+	require.ErrorContains(t, ReconcileRaftLogEngine(ctx, stateEng, emptyEng), "is empty")
+}
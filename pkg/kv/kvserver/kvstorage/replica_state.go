@@ -42,6 +42,7 @@ type LoadedReplicaState struct {
 func LoadReplicaState(
 	ctx context.Context,
 	eng storage.Reader,
+	logReader storage.Reader,
 	storeID roachpb.StoreID,
 	desc *roachpb.RangeDescriptor,
 	replicaID roachpb.ReplicaID,
@@ -57,13 +58,10 @@ func LoadReplicaState(
 	}
 
 	ls := LoadedReplicaState{ReplicaID: replicaID}
-	if ls.hardState, err = sl.LoadHardState(ctx, eng); err != nil {
+	if ls.hardState, err = sl.LoadHardState(ctx, logReader); err != nil {
 		return LoadedReplicaState{}, err
 	}
-	if ls.LastIndex, err = sl.LoadLastIndex(ctx, eng); err != nil {
-		return LoadedReplicaState{}, err
-	}
-	if ls.ReplState, err = sl.Load(ctx, eng, desc); err != nil {
+	if err := ls.load(ctx, eng, logReader, desc); err != nil {
 		return LoadedReplicaState{}, err
 	}
 
@@ -73,6 +71,30 @@ func LoadReplicaState(
 	return ls, nil
 }
 
+// load loads the LastIndex and the ReplState of the replica. The raft state is
+// loaded from logReader, and the rest of the state from eng. The two are the
+// same unless the store keeps its raft logs in a separate engine.
+func (r *LoadedReplicaState) load(
+	ctx context.Context, eng, logReader storage.Reader, desc *roachpb.RangeDescriptor,
+) error {
+	sl := stateloader.Make(desc.RangeID)
+	var err error
+	if r.LastIndex, err = sl.LoadLastIndex(ctx, logReader); err != nil {
+		return err
+	}
+	if r.ReplState, err = sl.Load(ctx, eng, desc); err != nil {
+		return err
+	}
+	// The RaftTruncatedState is part of the raft state, so it is not
+	// necessarily in eng.
+	truncState, err := sl.LoadRaftTruncatedState(ctx, logReader)
+	if err != nil {
+		return err
+	}
+	r.ReplState.TruncatedState = &truncState
+	return nil
+}
+
 // check makes sure that the replica invariants hold for the loaded state.
 func (r LoadedReplicaState) check(storeID roachpb.StoreID) error {
 	desc := r.ReplState.Desc
@@ -109,6 +131,7 @@ func (r LoadedReplicaState) check(storeID roachpb.StoreID) error {
 func CreateUninitializedReplica(
 	ctx context.Context,
 	eng storage.Engine,
+	logReader storage.Reader,
 	storeID roachpb.StoreID,
 	rangeID roachpb.RangeID,
 	replicaID roachpb.ReplicaID,
@@ -158,6 +181,6 @@ func CreateUninitializedReplica(
 
 	// Make sure that storage invariants for this uninitialized replica hold.
 	uninitDesc := roachpb.RangeDescriptor{RangeID: rangeID}
-	_, err := LoadReplicaState(ctx, eng, storeID, &uninitDesc, replicaID)
+	_, err := LoadReplicaState(ctx, eng, logReader, storeID, &uninitDesc, replicaID)
 	return err
 }
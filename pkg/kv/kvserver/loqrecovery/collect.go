@@ -104,12 +104,19 @@ func CollectRemoteReplicaInfo(
 }
 
 // CollectStoresReplicaInfo captures states of all replicas in all stores for the sake of quorum recovery.
+// raftLogEngines holds the engine that keeps the raft logs of each of the
+// stores, which is the store's engine itself unless the store has a separate
+// raft log engine.
 func CollectStoresReplicaInfo(
-	ctx context.Context, stores []storage.Engine,
+	ctx context.Context, stores []storage.Engine, raftLogEngines []storage.Engine,
 ) (loqrecoverypb.ClusterReplicaInfo, CollectionStats, error) {
 	if len(stores) == 0 {
 		return loqrecoverypb.ClusterReplicaInfo{}, CollectionStats{}, errors.New("no stores were provided for info collection")
 	}
+	if len(raftLogEngines) != len(stores) {
+		return loqrecoverypb.ClusterReplicaInfo{}, CollectionStats{}, errors.AssertionFailedf(
+			"%d raft log engines were provided for %d stores", len(raftLogEngines), len(stores))
+	}
 
 	// Synthesizing version from engine ensures that binary is compatible with
 	// the store, so we don't need to do any extra checks.
@@ -138,7 +145,10 @@ func CollectStoresReplicaInfo(
 			return loqrecoverypb.ClusterReplicaInfo{}, CollectionStats{}, errors.New("can't collect info from stored that belong to different clusters")
 		}
 		nodes[ident.NodeID] = struct{}{}
-		if err := visitStoreReplicas(ctx, reader, ident.StoreID, ident.NodeID, version,
+		if err := kvstorage.CheckRaftLogEngine(ctx, reader, raftLogEngines[i]); err != nil {
+			return loqrecoverypb.ClusterReplicaInfo{}, CollectionStats{}, err
+		}
+		if err := visitStoreReplicas(ctx, reader, raftLogEngines[i], ident.StoreID, ident.NodeID, version,
 			func(info loqrecoverypb.ReplicaInfo) error {
 				replicas = append(replicas, info)
 				return nil
@@ -156,9 +166,13 @@ func CollectStoresReplicaInfo(
 		}, nil
 }
 
+// visitStoreReplicas sends the info of each replica of a store. The raft state
+// of the replicas is read from raftReader, which is the same as reader unless
+// the store has a separate raft log engine.
 func visitStoreReplicas(
 	ctx context.Context,
 	reader storage.Reader,
+	raftReader storage.Reader,
 	storeID roachpb.StoreID,
 	nodeID roachpb.NodeID,
 	targetVersion clusterversion.ClusterVersion,
@@ -170,7 +184,7 @@ func visitStoreReplicas(
 		if err != nil {
 			return err
 		}
-		hstate, err := rsl.LoadHardState(ctx, reader)
+		hstate, err := rsl.LoadHardState(ctx, raftReader)
 		if err != nil {
 			return err
 		}
@@ -180,7 +194,7 @@ func visitStoreReplicas(
 		// outcome, and they will become committed as soon as the replica is
 		// designated as a survivor.
 		rangeUpdates, err := GetDescriptorChangesFromRaftLog(desc.RangeID,
-			rstate.RaftAppliedIndex+1, math.MaxInt64, reader)
+			rstate.RaftAppliedIndex+1, math.MaxInt64, raftReader)
 		if err != nil {
 			return err
 		}
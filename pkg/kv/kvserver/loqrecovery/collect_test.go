@@ -39,7 +39,7 @@ func TestCollectChecksStoreInfo(t *testing.T) {
 		t.Fatalf("failed to populate test store cluster version: %v", err)
 	}
 
-	_, _, err = CollectStoresReplicaInfo(ctx, []storage.Engine{eng})
+	_, _, err = CollectStoresReplicaInfo(ctx, []storage.Engine{eng}, []storage.Engine{eng})
 	require.ErrorContains(t, err, "is too old for running version",
 		"engine version check not triggered")
 }
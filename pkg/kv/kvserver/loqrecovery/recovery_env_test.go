@@ -646,7 +646,7 @@ func (e *quorumRecoveryEnv) handleCollectReplicas(
 	// save collected results into environment
 	e.replicas = loqrecoverypb.ClusterReplicaInfo{}
 	for _, nodeStores := range nodes {
-		info, _, err := CollectStoresReplicaInfo(ctx, nodeStores)
+		info, _, err := CollectStoresReplicaInfo(ctx, nodeStores, nodeStores)
 		if err != nil {
 			return "", err
 		}
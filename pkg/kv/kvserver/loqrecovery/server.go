@@ -122,9 +122,14 @@ func (s Server) ServeLocalReplicas(
 ) error {
 	v := s.settings.Version.ActiveVersion(ctx)
 	return s.stores.VisitStores(func(s *kvserver.Store) error {
-		reader := s.TODOEngine().NewSnapshot()
+		reader := s.StateEngine().NewSnapshot()
 		defer reader.Close()
-		return visitStoreReplicas(ctx, reader, s.StoreID(), s.NodeID(), v,
+		raftReader := reader
+		if s.LogEngine() != s.StateEngine() {
+			raftReader = s.LogEngine().NewSnapshot()
+			defer raftReader.Close()
+		}
+		return visitStoreReplicas(ctx, reader, raftReader, s.StoreID(), s.NodeID(), v,
 			func(info loqrecoverypb.ReplicaInfo) error {
 				return stream.Send(&serverpb.RecoveryCollectLocalReplicaInfoResponse{ReplicaInfo: &info})
 			})
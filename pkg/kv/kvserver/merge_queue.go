@@ -265,6 +265,11 @@ func (mq *mergeQueue) process(
 		return false, nil
 	}
 
+	if len(lhsDesc.Replicas().WitnessDescriptors())+len(rhsDesc.Replicas().WitnessDescriptors()) > 0 {
+		log.VEventf(ctx, 2, "skipping merge: ranges with witnesses cannot be merged")
+		return false, nil
+	}
+
 	mergedDesc := &roachpb.RangeDescriptor{
 		StartKey: lhsDesc.StartKey,
 		EndKey:   rhsDesc.EndKey,
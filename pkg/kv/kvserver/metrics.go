@@ -427,6 +427,18 @@ var (
 		Measurement: "Read Ops",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaFollowerReadIndexCount = metric.Metadata{
+		Name:        "follower_reads.read_index.success_count",
+		Help:        "Number of follower reads above the closed timestamp served after a read index round trip to the leaseholder",
+		Measurement: "Read Ops",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaFollowerReadIndexErrorCount = metric.Metadata{
+		Name:        "follower_reads.read_index.error_count",
+		Help:        "Number of read index round trips to the leaseholder that failed, causing the read to be redirected to the leaseholder",
+		Measurement: "Read Ops",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// Server-side transaction metrics.
 	metaCommitWaitBeforeCommitTriggerCount = metric.Metadata{
@@ -1883,6 +1895,12 @@ The messages are dropped to help these replicas to recover from I/O overload.`,
 		Measurement: "Keys",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaGCNumKeysExpired = metric.Metadata{
+		Name:        "queue.gc.info.numkeysexpired",
+		Help:        "Number of keys expired by a storage TTL",
+		Measurement: "Keys",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaGCNumRangeKeysAffected = metric.Metadata{
 		Name:        "queue.gc.info.numrangekeysaffected",
 		Help:        "Number of range keys GC'able",
@@ -2341,7 +2359,9 @@ type StoreMetrics struct {
 	}
 
 	// Follower read metrics.
-	FollowerReadsCount *metric.Counter
+	FollowerReadsCount          *metric.Counter
+	FollowerReadIndexCount      *metric.Counter
+	FollowerReadIndexErrorCount *metric.Counter
 
 	// Server-side transaction metrics.
 	CommitWaitsBeforeCommitTrigger                           *metric.Counter
@@ -2575,6 +2595,7 @@ type StoreMetrics struct {
 
 	// GCInfo cumulative totals.
 	GCNumKeysAffected            *metric.Counter
+	GCNumKeysExpired             *metric.Counter
 	GCNumRangeKeysAffected       *metric.Counter
 	GCIntentsConsidered          *metric.Counter
 	GCIntentTxns                 *metric.Counter
@@ -3018,7 +3039,9 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		),
 
 		// Follower reads metrics.
-		FollowerReadsCount: metric.NewCounter(metaFollowerReadsCount),
+		FollowerReadsCount:          metric.NewCounter(metaFollowerReadsCount),
+		FollowerReadIndexCount:      metric.NewCounter(metaFollowerReadIndexCount),
+		FollowerReadIndexErrorCount: metric.NewCounter(metaFollowerReadIndexErrorCount),
 
 		// Server-side transaction metrics.
 		CommitWaitsBeforeCommitTrigger:                           metric.NewCounter(metaCommitWaitBeforeCommitTriggerCount),
@@ -3288,6 +3311,7 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 
 		// GCInfo cumulative totals.
 		GCNumKeysAffected:            metric.NewCounter(metaGCNumKeysAffected),
+		GCNumKeysExpired:             metric.NewCounter(metaGCNumKeysExpired),
 		GCNumRangeKeysAffected:       metric.NewCounter(metaGCNumRangeKeysAffected),
 		GCIntentsConsidered:          metric.NewCounter(metaGCIntentsConsidered),
 		GCIntentTxns:                 metric.NewCounter(metaGCIntentTxns),
@@ -70,6 +70,10 @@ const (
 	// prevent continually spinning on intents that belong to active transactions,
 	// which can't be cleaned up.
 	mvccGCQueueIntentCooldownDuration = 2 * time.Hour
+	// mvccGCStorageTTLMaxInterval is the maximum duration between MVCC GC runs
+	// of a range with live data and a storage TTL, so that keys are expired
+	// within this duration of reaching the storage TTL.
+	mvccGCStorageTTLMaxInterval = 24 * time.Hour
 	// intentAgeNormalization is the average age of outstanding intents
 	// which amount to a score of "1" added to total replica priority.
 	intentAgeNormalization = 8 * time.Hour
@@ -220,6 +224,7 @@ func newMVCCGCQueue(store *Store) *mvccGCQueue {
 // are documented in makeMVCCGCQueueScoreImpl.
 type mvccGCQueueScore struct {
 	TTL                 time.Duration
+	StorageTTL          time.Duration
 	LastGC              time.Duration
 	DeadFraction        float64
 	ValuesScalableScore float64
@@ -253,6 +258,9 @@ func (r mvccGCQueueScore) String() string {
 	if !r.Hint.IsEmpty() {
 		s += fmt.Sprintf("\nhint: %s", r.Hint)
 	}
+	if r.StorageTTL > 0 {
+		s += fmt.Sprintf("\nstorage ttl: %s", r.StorageTTL)
+	}
 	return s
 }
 
@@ -284,7 +292,8 @@ func (mgcq *mvccGCQueue) shouldQueue(
 		return false, 0
 	}
 
-	r := makeMVCCGCQueueScore(ctx, repl, gcTimestamp, lastGC, conf.TTL(), canAdvanceGCThreshold)
+	r := makeMVCCGCQueueScore(
+		ctx, repl, gcTimestamp, lastGC, conf.TTL(), conf.StorageTTL(), canAdvanceGCThreshold)
 	log.VEventf(ctx, 2, "shouldQueue=%t: %s", r.ShouldQueue, r)
 	return r.ShouldQueue, r.FinalScore
 }
@@ -295,6 +304,7 @@ func makeMVCCGCQueueScore(
 	now hlc.Timestamp,
 	lastGC hlc.Timestamp,
 	gcTTL time.Duration,
+	storageTTL time.Duration,
 	canAdvanceGCThreshold bool,
 ) mvccGCQueueScore {
 	repl.mu.RLock()
@@ -313,6 +323,25 @@ func makeMVCCGCQueueScore(
 		ctx, int64(repl.RangeID), now, ms, gcTTL, lastGC, canAdvanceGCThreshold,
 		hint, gc.TxnCleanupThreshold.Get(&repl.ClusterSettings().SV),
 	)
+
+	// Live keys expire under a storage TTL without contributing to GCByteAge,
+	// so a range with live data is queued periodically instead. The interval
+	// is the storage TTL, capped so that long TTLs are enforced in a timely
+	// manner.
+	if storageTTL > 0 {
+		r.StorageTTL = storageTTL
+		interval := storageTTL
+		if interval > mvccGCStorageTTLMaxInterval {
+			interval = mvccGCStorageTTLMaxInterval
+		}
+		if !r.ShouldQueue && canAdvanceGCThreshold && ms.LiveCount > 0 &&
+			(r.LastGC == 0 || r.LastGC >= interval) {
+			r.ShouldQueue = true
+			if r.FinalScore < mvccGCKeyScoreThreshold {
+				r.FinalScore = mvccGCKeyScoreThreshold
+			}
+		}
+	}
 	return r
 }
 
@@ -594,7 +623,7 @@ func (r *replicaGCer) template() kvpb.GCRequest {
 	return template
 }
 
-func (r *replicaGCer) send(ctx context.Context, req kvpb.GCRequest) error {
+func (r *replicaGCer) send(ctx context.Context, req kvpb.GCRequest) (*kvpb.GCResponse, error) {
 	n := atomic.AddInt32(&r.count, 1)
 	log.Eventf(ctx, "sending batch %d (%d keys)", n, len(req.Keys))
 
@@ -612,25 +641,26 @@ func (r *replicaGCer) send(ctx context.Context, req kvpb.GCRequest) error {
 		var err error
 		admissionHandle, err = r.admissionController.AdmitKVWork(ctx, roachpb.SystemTenantID, ba)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	_, writeBytes, pErr := r.repl.SendWithWriteBytes(ctx, ba)
+	br, writeBytes, pErr := r.repl.SendWithWriteBytes(ctx, ba)
 	defer writeBytes.Release()
 	if r.admissionController != nil {
 		r.admissionController.AdmittedKVWorkDone(admissionHandle, writeBytes)
 	}
 	if pErr != nil {
 		log.VErrEventf(ctx, 2, "%v", pErr.String())
-		return pErr.GoError()
+		return nil, pErr.GoError()
 	}
-	return nil
+	return br.Responses[0].GetInner().(*kvpb.GCResponse), nil
 }
 
 func (r *replicaGCer) SetGCThreshold(ctx context.Context, thresh gc.Threshold) error {
 	req := r.template()
 	req.Threshold = thresh.Key
-	return r.send(ctx, req)
+	_, err := r.send(ctx, req)
+	return err
 }
 
 func (r *replicaGCer) GC(
@@ -646,7 +676,24 @@ func (r *replicaGCer) GC(
 	req.Keys = keys
 	req.RangeKeys = rangeKeys
 	req.ClearRange = clearRange
-	return r.send(ctx, req)
+	_, err := r.send(ctx, req)
+	return err
+}
+
+func (r *replicaGCer) Expire(
+	ctx context.Context, keys []kvpb.GCRequest_GCKey, spans []kvpb.GCRequest_GCRangeKey,
+) (int, error) {
+	if len(keys) == 0 && len(spans) == 0 {
+		return 0, nil
+	}
+	req := r.template()
+	req.ExpiredKeys = keys
+	req.ExpiredSpans = spans
+	resp, err := r.send(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.NumKeysExpired), nil
 }
 
 // process first determines whether the replica can run MVCC GC given its view
@@ -706,7 +753,8 @@ func (mgcq *mvccGCQueue) process(
 		lastGC = hlc.Timestamp{}
 		log.VErrEventf(ctx, 2, "failed to fetch last processed time: %v", err)
 	}
-	r := makeMVCCGCQueueScore(ctx, repl, gcTimestamp, lastGC, conf.TTL(), canAdvanceGCThreshold)
+	r := makeMVCCGCQueueScore(
+		ctx, repl, gcTimestamp, lastGC, conf.TTL(), conf.StorageTTL(), canAdvanceGCThreshold)
 	log.VEventf(ctx, 2, "processing replica %s with score %s", repl.String(), r)
 	// Synchronize the new GC threshold decision with concurrent
 	// AdminVerifyProtectedTimestamp requests.
@@ -752,6 +800,7 @@ func (mgcq *mvccGCQueue) process(
 			MaxTxnsPerIntentCleanupBatch:           intentresolver.MaxTxnsPerIntentCleanupBatch,
 			IntentCleanupBatchTimeout:              mvccGCQueueIntentBatchTimeout,
 			ClearRangeMinKeys:                      clearRangeMinKeys,
+			StorageTTL:                             conf.StorageTTL(),
 		},
 		conf.TTL(),
 		&replicaGCer{
@@ -793,8 +842,10 @@ func (mgcq *mvccGCQueue) process(
 		return false, err
 	}
 
+	// The storage TTL criterion is time based, and doesn't indicate stats that
+	// are off, so it is left out here.
 	scoreAfter := makeMVCCGCQueueScore(
-		ctx, repl, repl.store.Clock().Now(), lastGC, conf.TTL(), canAdvanceGCThreshold)
+		ctx, repl, repl.store.Clock().Now(), lastGC, conf.TTL(), 0 /* storageTTL */, canAdvanceGCThreshold)
 	log.VEventf(ctx, 2, "MVCC stats after GC: %+v", repl.GetMVCCStats())
 	log.VEventf(ctx, 2, "GC score after GC: %s", scoreAfter)
 	updateStoreMetricsWithGCInfo(mgcq.store.metrics, info)
@@ -831,6 +882,7 @@ func (mgcq *mvccGCQueue) process(
 
 func updateStoreMetricsWithGCInfo(metrics *StoreMetrics, info gc.Info) {
 	metrics.GCNumKeysAffected.Inc(int64(info.NumKeysAffected))
+	metrics.GCNumKeysExpired.Inc(int64(info.NumKeysExpired))
 	metrics.GCNumRangeKeysAffected.Inc(int64(info.NumRangeKeysAffected))
 	metrics.GCIntentsConsidered.Inc(int64(info.IntentsConsidered))
 	metrics.GCIntentTxns.Inc(int64(info.IntentTxns))
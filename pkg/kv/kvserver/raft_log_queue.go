@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -309,8 +310,26 @@ func newTruncateDecision(ctx context.Context, r *Replica) (truncateDecision, err
 		},
 	)
 	log.Eventf(ctx, "raft status after lastUpdateTimes check: %+v", raftStatus.Progress)
+	replicas := r.descRLocked().Replicas()
 	r.mu.RUnlock()
 
+	// If the range has witnesses, find its live full voters, which we don't
+	// cut off (see computeTruncateDecision).
+	var liveFullVoters []roachpb.ReplicaID
+	if len(replicas.WitnessDescriptors()) > 0 {
+		for _, replDesc := range replicas.Descriptors() {
+			if replDesc.Type != roachpb.VOTER_FULL {
+				continue
+			}
+			// Some tests run without a NodeLiveness configured.
+			if nl := r.store.cfg.NodeLiveness; nl != nil &&
+				!nl.GetNodeVitalityFromCache(replDesc.NodeID).IsLive(livenesspb.ReplicaProgress) {
+				continue
+			}
+			liveFullVoters = append(liveFullVoters, replDesc.ReplicaID)
+		}
+	}
+
 	input := truncateDecisionInput{
 		RaftStatus:           *raftStatus,
 		LogSize:              raftLogSize,
@@ -319,6 +338,7 @@ func newTruncateDecision(ctx context.Context, r *Replica) (truncateDecision, err
 		FirstIndex:           firstIndex,
 		LastIndex:            lastIndex,
 		PendingSnapshotIndex: pendingSnapshotIndex,
+		LiveFullVoters:       liveFullVoters,
 	}
 
 	decision := computeTruncateDecision(input)
@@ -353,6 +373,7 @@ func updateRaftProgressFromActivity(
 const (
 	truncatableIndexChosenViaCommitIndex     = "commit"
 	truncatableIndexChosenViaFollowers       = "followers"
+	truncatableIndexChosenViaFullVoter       = "full voter"
 	truncatableIndexChosenViaProbingFollower = "probing follower"
 	truncatableIndexChosenViaPendingSnap     = "pending snapshot"
 	truncatableIndexChosenViaFirstIndex      = "first index"
@@ -376,6 +397,9 @@ type truncateDecisionInput struct {
 	LogSizeTrusted        bool // false when LogSize might be off
 	FirstIndex, LastIndex kvpb.RaftIndex
 	PendingSnapshotIndex  kvpb.RaftIndex
+	// LiveFullVoters is only populated if the range has witnesses, and holds
+	// the full voters that live on live nodes.
+	LiveFullVoters []roachpb.ReplicaID
 }
 
 func (input truncateDecisionInput) LogTooLarge() bool {
@@ -561,6 +585,24 @@ func computeTruncateDecision(input truncateDecisionInput) truncateDecision {
 		// Otherwise, we let it truncate to the committed index.
 	}
 
+	// Witnesses take part in the commit quorum but don't hold the range's
+	// data, so the log can be committed without some of the full voters, and
+	// a full voter that is cut off can only be caught up by a snapshot from
+	// another full voter, which might be the only other replica that holds
+	// the data. So we never truncate a live full voter off, no matter how
+	// large the log gets.
+	for _, replicaID := range input.LiveFullVoters {
+		progress, ok := input.RaftStatus.Progress[uint64(replicaID)]
+		if !ok {
+			continue
+		}
+		if progress.State == tracker.StateProbe {
+			decision.ProtectIndex(input.FirstIndex, truncatableIndexChosenViaProbingFollower)
+		} else {
+			decision.ProtectIndex(kvpb.RaftIndex(progress.Match), truncatableIndexChosenViaFullVoter)
+		}
+	}
+
 	// The pending snapshot index acts as a placeholder for a replica that is
 	// about to be added to the range (or is in Raft recovery). We don't want to
 	// truncate the log in a way that will require that new replica to be caught
@@ -692,7 +734,7 @@ func (rlq *raftLogQueue) process(
 		// make sure concurrent Raft activity doesn't foul up our update to the
 		// cached in-memory values.
 		r.raftMu.Lock()
-		n, err := ComputeRaftLogSize(ctx, r.RangeID, r.store.TODOEngine(), r.raftMu.sideloaded)
+		n, err := ComputeRaftLogSize(ctx, r.RangeID, r.store.LogEngine(), r.raftMu.sideloaded)
 		if err == nil {
 			r.mu.Lock()
 			r.mu.raftLogSize = n
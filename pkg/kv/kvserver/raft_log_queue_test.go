@@ -294,6 +294,52 @@ func TestComputeTruncateDecisionProgressStatusProbe(t *testing.T) {
 	})
 }
 
+// TestComputeTruncateDecisionWitnesses verifies that when a range has
+// witnesses, we don't truncate the log out from under a live full voter, even
+// if it is not recently active and the log is too large.
+func TestComputeTruncateDecisionWitnesses(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	status := raft.Status{
+		Progress: map[uint64]tracker.Progress{
+			// The leader, a full voter.
+			1: {Match: 500, Next: 501, RecentActive: true, State: tracker.StateReplicate},
+			// A witness, which lets the log be committed without replica 3.
+			2: {Match: 500, Next: 501, RecentActive: true, State: tracker.StateReplicate},
+			// A full voter which has fallen behind.
+			3: {Match: 100, Next: 101, RecentActive: false, State: tracker.StateReplicate},
+		},
+	}
+	status.Commit = 500
+
+	input := truncateDecisionInput{
+		RaftStatus:     status,
+		LogSize:        2048,
+		MaxLogSize:     1024,
+		FirstIndex:     10,
+		LastIndex:      600,
+		LogSizeTrusted: true,
+	}
+	decision := computeTruncateDecision(input)
+	require.Equal(t, kvpb.RaftIndex(500), decision.NewFirstIndex)
+	require.Equal(t, truncatableIndexChosenViaCommitIndex, decision.ChosenVia)
+
+	// If replica 3 is live, it isn't cut off.
+	input.LiveFullVoters = []roachpb.ReplicaID{1, 3}
+	decision = computeTruncateDecision(input)
+	require.Equal(t, kvpb.RaftIndex(100), decision.NewFirstIndex)
+	require.Equal(t, truncatableIndexChosenViaFullVoter, decision.ChosenVia)
+
+	// If it is being probed, nothing is truncated.
+	pr := input.RaftStatus.Progress[3]
+	pr.State = tracker.StateProbe
+	input.RaftStatus.Progress[3] = pr
+	decision = computeTruncateDecision(input)
+	require.Equal(t, kvpb.RaftIndex(10), decision.NewFirstIndex)
+	require.Equal(t, truncatableIndexChosenViaProbingFollower, decision.ChosenVia)
+}
+
 func TestTruncateDecisionZeroValue(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
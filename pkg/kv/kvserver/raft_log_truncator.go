@@ -228,8 +228,12 @@ type storeForTruncator interface {
 	acquireReplicaForTruncator(rangeID roachpb.RangeID) replicaForTruncator
 	// releaseReplicaForTruncator releases the replica.
 	releaseReplicaForTruncator(r replicaForTruncator)
-	// Engine accessor.
-	getEngine() storage.Engine
+	// getStateEngine returns the engine that holds the RangeAppliedState.
+	getStateEngine() storage.Engine
+	// getLogEngine returns the engine that holds the raft log. It is the same
+	// engine as the state engine unless the store keeps its raft log in a
+	// separate engine.
+	getLogEngine() storage.Engine
 }
 
 // replicaForTruncator abstracts the interface of Replica needed by the
@@ -451,7 +455,7 @@ func (t *raftLogTruncator) durabilityAdvanced(ctx context.Context) {
 	// Sort it for deterministic testing output.
 	sort.Sort(rangesByRangeID(ranges))
 	// Create an engine Reader to provide a safe lower bound on what is durable.
-	reader := t.store.getEngine().NewReadOnly(storage.GuaranteedDurability)
+	reader := t.store.getStateEngine().NewReadOnly(storage.GuaranteedDurability)
 	defer reader.Close()
 	shouldQuiesce := t.stopper.ShouldQuiesce()
 	quiesced := false
@@ -555,7 +559,7 @@ func (t *raftLogTruncator) tryEnactTruncations(
 	}
 	// Do the truncation of persistent raft entries, specified by enactIndex
 	// (this subsumes all the preceding queued truncations).
-	batch := t.store.getEngine().NewUnindexedBatch()
+	batch := t.store.getLogEngine().NewUnindexedBatch()
 	defer batch.Close()
 	apply, err := handleTruncatedStateBelowRaftPreApply(ctx, &truncState,
 		&pendingTruncs.mu.truncs[enactIndex].RaftTruncatedState, stateLoader, batch)
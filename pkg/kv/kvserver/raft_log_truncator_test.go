@@ -258,7 +258,11 @@ func makeStoreTT(eng storage.Engine, buf *strings.Builder) *storeTruncatorTest {
 	}
 }
 
-func (s *storeTruncatorTest) getEngine() storage.Engine {
+func (s *storeTruncatorTest) getStateEngine() storage.Engine {
+	return s.eng
+}
+
+func (s *storeTruncatorTest) getLogEngine() storage.Engine {
 	return s.eng
 }
 
@@ -11,7 +11,9 @@
 package rangefeed
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/interval"
 )
 
@@ -22,12 +24,22 @@ import (
 type Filter struct {
 	needPrevVals interval.RangeGroup
 	needVals     interval.RangeGroup
+	// needAllVals are the spans of the registrations without a predicate, and
+	// predicates are the predicates of the other registrations.
+	needAllVals interval.RangeGroup
+	predicates  []spanPredicate
+}
+
+type spanPredicate struct {
+	span      roachpb.Span
+	predicate *Predicate
 }
 
 func newFilterFromRegistry(reg *registry) *Filter {
 	f := &Filter{
 		needPrevVals: interval.NewRangeList(),
 		needVals:     interval.NewRangeList(),
+		needAllVals:  interval.NewRangeList(),
 	}
 	reg.tree.Do(func(i interval.Interface) (done bool) {
 		r := i.(*registration)
@@ -35,6 +47,11 @@ func newFilterFromRegistry(reg *registry) *Filter {
 			f.needPrevVals.Add(r.Range())
 		}
 		f.needVals.Add(r.Range())
+		if r.predicate == nil {
+			f.needAllVals.Add(r.Range())
+		} else {
+			f.predicates = append(f.predicates, spanPredicate{span: r.span, predicate: r.predicate})
+		}
 		return false
 	})
 	return f
@@ -53,3 +70,42 @@ func (r *Filter) NeedPrevVal(s roachpb.Span) bool {
 func (r *Filter) NeedVal(s roachpb.Span) bool {
 	return r.needVals.Overlaps(s.AsRange())
 }
+
+// NeedValueOp returns whether the Processor requires the given operation. It
+// does not require MVCCWriteValueOp and MVCCExpireValueOp operations that no
+// registration over their key is interested in, given the registrations'
+// predicates, which lets the producer drop them before they take up the
+// Processor's memory budget. All other operations are required, as they also
+// inform the Processor of intents. The operation's values must have been
+// populated as required by NeedVal and NeedPrevVal.
+func (r *Filter) NeedValueOp(op enginepb.MVCCLogicalOp) bool {
+	var value kvpb.RangeFeedValue
+	switch t := op.GetValue().(type) {
+	case *enginepb.MVCCWriteValueOp:
+		value = kvpb.RangeFeedValue{
+			Key:   t.Key,
+			Value: roachpb.Value{RawBytes: t.Value, Timestamp: t.Timestamp},
+		}
+		value.PrevValue.RawBytes = t.PrevValue
+	case *enginepb.MVCCExpireValueOp:
+		value = kvpb.RangeFeedValue{
+			Key:     t.Key,
+			Value:   roachpb.Value{Timestamp: t.Timestamp},
+			Expired: true,
+		}
+		value.PrevValue.RawBytes = t.PrevValue
+	default:
+		return true
+	}
+	if r.needAllVals.Overlaps(roachpb.Span{Key: value.Key}.AsRange()) {
+		return true
+	}
+	var event kvpb.RangeFeedEvent
+	event.MustSetValue(&value)
+	for _, p := range r.predicates {
+		if p.span.ContainsKey(value.Key) && p.predicate.Matches(&event) {
+			return true
+		}
+	}
+	return false
+}
@@ -37,6 +37,12 @@ var (
 		Measurement: "Events",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRangeFeedEventsFiltered = metric.Metadata{
+		Name:        "kv.rangefeed.events_filtered",
+		Help:        "Number of RangeFeed value events that were not sent because they did not satisfy the predicate of the registration",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaRangeFeedRegistrations = metric.Metadata{
 		Name:        "kv.rangefeed.registrations",
 		Help:        "Number of active RangeFeed registrations",
@@ -74,6 +80,7 @@ type Metrics struct {
 	RangeFeedCatchUpScanNanos        *metric.Counter
 	RangeFeedBudgetExhausted         *metric.Counter
 	RangeFeedBudgetBlocked           *metric.Counter
+	RangeFeedEventsFiltered          *metric.Counter
 	RangeFeedRegistrations           *metric.Gauge
 	RangeFeedSlowClosedTimestampLogN log.EveryN
 	// RangeFeedSlowClosedTimestampNudgeSem bounds the amount of work that can be
@@ -97,6 +104,7 @@ func NewMetrics() *Metrics {
 		RangeFeedCatchUpScanNanos:            metric.NewCounter(metaRangeFeedCatchUpScanNanos),
 		RangeFeedBudgetExhausted:             metric.NewCounter(metaRangeFeedExhausted),
 		RangeFeedBudgetBlocked:               metric.NewCounter(metaRangeFeedBudgetBlocked),
+		RangeFeedEventsFiltered:              metric.NewCounter(metaRangeFeedEventsFiltered),
 		RangeFeedRegistrations:               metric.NewGauge(metaRangeFeedRegistrations),
 		RangeFeedSlowClosedTimestampLogN:     log.Every(5 * time.Second),
 		RangeFeedSlowClosedTimestampNudgeSem: make(chan struct{}, 1024),
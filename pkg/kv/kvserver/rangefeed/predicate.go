@@ -0,0 +1,172 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rangefeed
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// Predicate is the server side form of a kvpb.RangeFeedPredicate. It is
+// evaluated by the Processor before an event is buffered for a registration,
+// so that events that the registration is not interested in take up neither
+// its buffer nor the network. A nil Predicate matches all events.
+//
+// A Predicate is conservative: an event that it cannot evaluate, such as a
+// value that does not decode, matches.
+type Predicate struct {
+	keyPrefixes []roachpb.Key
+	familyIDs   map[uint32]struct{}
+	columns     []columnCondition
+	omitExpired bool
+}
+
+// columnCondition is a condition on the value of a column, kept in decoded
+// form so that it can be compared to the columns of a value without
+// re-encoding either.
+type columnCondition struct {
+	columnID uint32
+	typ      encoding.Type
+	// data is the encoded datum, without its value tag.
+	data []byte
+}
+
+// NewPredicate returns the Predicate for the given kvpb.RangeFeedPredicate,
+// or nil if p is nil or sets no conditions.
+func NewPredicate(p *kvpb.RangeFeedPredicate) (*Predicate, error) {
+	if p == nil || (len(p.KeyPrefixes) == 0 && len(p.FamilyIDs) == 0 &&
+		len(p.ColumnValues) == 0 && !p.OmitExpired) {
+		return nil, nil
+	}
+	pred := &Predicate{keyPrefixes: p.KeyPrefixes, omitExpired: p.OmitExpired}
+	if len(p.FamilyIDs) > 0 {
+		pred.familyIDs = make(map[uint32]struct{}, len(p.FamilyIDs))
+		for _, id := range p.FamilyIDs {
+			pred.familyIDs[id] = struct{}{}
+		}
+	}
+	for _, c := range p.ColumnValues {
+		_, dataOffset, colID, typ, err := encoding.DecodeValueTag(c.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding value of column %d", c.ColumnID)
+		}
+		if colID != encoding.NoColumnID {
+			return nil, errors.Newf("value of column %d must not be encoded with a column ID", c.ColumnID)
+		}
+		length, err := encoding.PeekValueLengthWithOffsetsAndType(c.Value, dataOffset, typ)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding value of column %d", c.ColumnID)
+		}
+		pred.columns = append(pred.columns, columnCondition{
+			columnID: c.ColumnID,
+			typ:      typ,
+			data:     c.Value[dataOffset:length],
+		})
+	}
+	sort.Slice(pred.columns, func(i, j int) bool {
+		return pred.columns[i].columnID < pred.columns[j].columnID
+	})
+	for i := 1; i < len(pred.columns); i++ {
+		if pred.columns[i].columnID == pred.columns[i-1].columnID {
+			return nil, errors.Newf("column %d has more than one value", pred.columns[i].columnID)
+		}
+	}
+	return pred, nil
+}
+
+// Matches returns whether an event satisfies the predicate. Only value events
+// are subject to the predicate.
+func (p *Predicate) Matches(event *kvpb.RangeFeedEvent) bool {
+	if p == nil {
+		return true
+	}
+	v, ok := event.GetValue().(*kvpb.RangeFeedValue)
+	if !ok {
+		return true
+	}
+	if v.Expired && p.omitExpired {
+		return false
+	}
+	return p.matchesKey(v.Key) && p.matchesValue(v.Value, v.PrevValue)
+}
+
+func (p *Predicate) matchesKey(key roachpb.Key) bool {
+	if len(p.keyPrefixes) > 0 {
+		var found bool
+		for _, prefix := range p.keyPrefixes {
+			if bytes.HasPrefix(key, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if p.familyIDs != nil {
+		// Keys that are not SQL row keys are not subject to the condition.
+		if id, err := keys.DecodeFamilyKey(key); err == nil {
+			if _, ok := p.familyIDs[id]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesValue returns whether the value, or the previous value, satisfies the
+// column conditions. The previous value lets a consumer that asked for diffs
+// observe a row that stops satisfying the predicate. Deletions always match,
+// as the predicate cannot tell which rows they delete.
+func (p *Predicate) matchesValue(value, prevValue roachpb.Value) bool {
+	if len(p.columns) == 0 || !value.IsPresent() {
+		return true
+	}
+	return p.matchesColumns(value) || (prevValue.IsPresent() && p.matchesColumns(prevValue))
+}
+
+func (p *Predicate) matchesColumns(value roachpb.Value) bool {
+	if value.GetTag() != roachpb.ValueType_TUPLE {
+		return true
+	}
+	b, err := value.GetTuple()
+	if err != nil {
+		return true
+	}
+	// The columns of a tuple are ordered by ID. Each is encoded with the delta
+	// from the ID of the previous column. NULL columns are omitted, and so are
+	// the columns of other families, so a missing column cannot be evaluated.
+	var colID uint32
+	for _, c := range p.columns {
+		for colID < c.columnID && len(b) > 0 {
+			_, dataOffset, delta, typ, err := encoding.DecodeValueTag(b)
+			if err != nil {
+				return true
+			}
+			length, err := encoding.PeekValueLengthWithOffsetsAndType(b, dataOffset, typ)
+			if err != nil {
+				return true
+			}
+			colID += delta
+			if colID == c.columnID && (typ != c.typ || !bytes.Equal(b[dataOffset:length], c.data)) {
+				return false
+			}
+			b = b[length:]
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rangefeed
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredicate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rowKey := func(table uint32, family uint32) roachpb.Key {
+		k := keys.SystemSQLCodec.IndexPrefix(table, 1)
+		k = encoding.EncodeVarintAscending(k, 1)
+		return keys.MakeFamilyKey(k, family)
+	}
+	// tuple returns a value with columns 1 and 3 set to a and b.
+	tuple := func(a, b int64) roachpb.Value {
+		var v roachpb.Value
+		data := encoding.EncodeIntValue(nil, 1, a)
+		data = encoding.EncodeIntValue(data, 2, b)
+		v.SetTuple(data)
+		return v
+	}
+	column := func(id uint32, v int64) kvpb.RangeFeedColumnValue {
+		return kvpb.RangeFeedColumnValue{
+			ColumnID: id, Value: encoding.EncodeIntValue(nil, encoding.NoColumnID, v),
+		}
+	}
+	event := func(key roachpb.Key, value, prevValue roachpb.Value) *kvpb.RangeFeedEvent {
+		var ev kvpb.RangeFeedEvent
+		ev.MustSetValue(&kvpb.RangeFeedValue{Key: key, Value: value, PrevValue: prevValue})
+		return &ev
+	}
+	var nonTuple roachpb.Value
+	nonTuple.SetInt(7)
+
+	p, err := NewPredicate(nil)
+	require.NoError(t, err)
+	require.Nil(t, p)
+	p, err = NewPredicate(&kvpb.RangeFeedPredicate{})
+	require.NoError(t, err)
+	require.Nil(t, p)
+	require.True(t, p.Matches(event(rowKey(100, 0), tuple(1, 2), roachpb.Value{})))
+
+	_, err = NewPredicate(&kvpb.RangeFeedPredicate{
+		ColumnValues: []kvpb.RangeFeedColumnValue{column(1, 1), column(1, 2)},
+	})
+	require.ErrorContains(t, err, "column 1 has more than one value")
+	_, err = NewPredicate(&kvpb.RangeFeedPredicate{
+		ColumnValues: []kvpb.RangeFeedColumnValue{
+			{ColumnID: 1, Value: encoding.EncodeIntValue(nil, 1, 1)},
+		},
+	})
+	require.ErrorContains(t, err, "must not be encoded with a column ID")
+
+	p, err = NewPredicate(&kvpb.RangeFeedPredicate{
+		KeyPrefixes:  []roachpb.Key{keys.SystemSQLCodec.TablePrefix(100)},
+		FamilyIDs:    []uint32{0},
+		ColumnValues: []kvpb.RangeFeedColumnValue{column(3, 2), column(1, 1)},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		ev      *kvpb.RangeFeedEvent
+		matches bool
+	}{
+		{"match", event(rowKey(100, 0), tuple(1, 2), roachpb.Value{}), true},
+		{"other table", event(rowKey(101, 0), tuple(1, 2), roachpb.Value{}), false},
+		{"other family", event(rowKey(100, 1), tuple(1, 2), roachpb.Value{}), false},
+		{"first column differs", event(rowKey(100, 0), tuple(2, 2), roachpb.Value{}), false},
+		{"second column differs", event(rowKey(100, 0), tuple(1, 3), roachpb.Value{}), false},
+		{"previous value matches", event(rowKey(100, 0), tuple(2, 2), tuple(1, 2)), true},
+		{"delete", event(rowKey(100, 0), roachpb.Value{}, tuple(2, 2)), true},
+		{"not a tuple", event(rowKey(100, 0), nonTuple, roachpb.Value{}), true},
+		{"checkpoint", func() *kvpb.RangeFeedEvent {
+			var ev kvpb.RangeFeedEvent
+			ev.MustSetValue(&kvpb.RangeFeedCheckpoint{Span: spAB})
+			return &ev
+		}(), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.matches, p.Matches(tc.ev))
+		})
+	}
+	// Expiries are omitted only if asked to.
+	expiry := event(rowKey(100, 0), roachpb.Value{}, tuple(1, 2))
+	expiry.Val.Expired = true
+	require.True(t, p.Matches(expiry))
+	p, err = NewPredicate(&kvpb.RangeFeedPredicate{OmitExpired: true})
+	require.NoError(t, err)
+	require.False(t, p.Matches(expiry))
+	require.True(t, p.Matches(event(rowKey(100, 0), roachpb.Value{}, tuple(1, 2))))
+}
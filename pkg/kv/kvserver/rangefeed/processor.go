@@ -168,6 +168,9 @@ type Processor interface {
 	// subsequently close it. If method fails, iterator must be kept intact and
 	// would be closed by caller.
 	//
+	// The optionally provided predicate restricts the value events, both live
+	// and from the catch-up scan, that are sent to the registration.
+	//
 	// If the method returns false, the processor will have been stopped, so calling
 	// Stop is not necessary. If the method returns true, it will also return an
 	// updated operation filter that includes the operations required by the new
@@ -179,6 +182,7 @@ type Processor interface {
 		startTS hlc.Timestamp, // exclusive
 		catchUpIter *CatchUpIterator,
 		withDiff bool,
+		predicate *Predicate,
 		stream Stream,
 		disconnectFn func(),
 		done *future.ErrorFuture,
@@ -556,6 +560,7 @@ func (p *LegacyProcessor) Register(
 	startTS hlc.Timestamp,
 	catchUpIter *CatchUpIterator,
 	withDiff bool,
+	predicate *Predicate,
 	stream Stream,
 	disconnectFn func(),
 	done *future.ErrorFuture,
@@ -567,7 +572,7 @@ func (p *LegacyProcessor) Register(
 
 	blockWhenFull := p.Config.EventChanTimeout == 0 // for testing
 	r := newRegistration(
-		span.AsRawSpanWithNoLocals(), startTS, catchUpIter, withDiff,
+		span.AsRawSpanWithNoLocals(), startTS, catchUpIter, withDiff, predicate,
 		p.Config.EventChanCap, blockWhenFull, p.Metrics, stream, disconnectFn, done,
 	)
 	select {
@@ -795,6 +800,10 @@ func (p *LegacyProcessor) consumeLogicalOps(
 			// Publish the range deletion directly.
 			p.publishDeleteRange(ctx, t.StartKey, t.EndKey, t.Timestamp, alloc)
 
+		case *enginepb.MVCCExpireValueOp:
+			// Publish the expiry as a deletion.
+			p.publishExpiry(ctx, t.Key, t.Timestamp, t.PrevValue, alloc)
+
 		case *enginepb.MVCCWriteIntentOp:
 			// No updates to publish.
 
@@ -872,6 +881,31 @@ func (p *LegacyProcessor) publishValue(
 	p.reg.PublishToOverlapping(ctx, roachpb.Span{Key: key}, &event, alloc)
 }
 
+func (p *LegacyProcessor) publishExpiry(
+	ctx context.Context,
+	key roachpb.Key,
+	timestamp hlc.Timestamp,
+	prevValue []byte,
+	alloc *SharedBudgetAllocation,
+) {
+	if !p.Span.ContainsKey(roachpb.RKey(key)) {
+		log.Fatalf(ctx, "key %v not in Processor's key range %v", key, p.Span)
+	}
+
+	var prevVal roachpb.Value
+	if prevValue != nil {
+		prevVal.RawBytes = prevValue
+	}
+	var event kvpb.RangeFeedEvent
+	event.MustSetValue(&kvpb.RangeFeedValue{
+		Key:       key,
+		Value:     roachpb.Value{Timestamp: timestamp},
+		PrevValue: prevVal,
+		Expired:   true,
+	})
+	p.reg.PublishToOverlapping(ctx, roachpb.Span{Key: key}, &event, alloc)
+}
+
 func (p *LegacyProcessor) publishDeleteRange(
 	ctx context.Context,
 	startKey, endKey roachpb.Key,
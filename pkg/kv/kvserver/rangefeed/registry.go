@@ -79,6 +79,7 @@ type registration struct {
 	span             roachpb.Span
 	catchUpTimestamp hlc.Timestamp // exclusive
 	withDiff         bool
+	predicate        *Predicate
 	metrics          *Metrics
 
 	// Output.
@@ -117,6 +118,7 @@ func newRegistration(
 	startTS hlc.Timestamp,
 	catchUpIter *CatchUpIterator,
 	withDiff bool,
+	predicate *Predicate,
 	bufferSz int,
 	blockWhenFull bool,
 	metrics *Metrics,
@@ -128,6 +130,7 @@ func newRegistration(
 		span:             span,
 		catchUpTimestamp: startTS,
 		withDiff:         withDiff,
+		predicate:        predicate,
 		metrics:          metrics,
 		stream:           stream,
 		done:             done,
@@ -397,7 +400,17 @@ func (r *registration) maybeRunCatchUpScan(ctx context.Context) error {
 		r.metrics.RangeFeedCatchUpScanNanos.Inc(timeutil.Since(start).Nanoseconds())
 	}()
 
-	return catchUpIter.CatchUpScan(ctx, r.stream.Send, r.withDiff)
+	outputFn := r.stream.Send
+	if r.predicate != nil {
+		outputFn = func(event *kvpb.RangeFeedEvent) error {
+			if !r.predicate.Matches(event) {
+				r.metrics.RangeFeedEventsFiltered.Inc(1)
+				return nil
+			}
+			return r.stream.Send(event)
+		}
+	}
+	return catchUpIter.CatchUpScan(ctx, outputFn, r.withDiff)
 }
 
 // ID implements interval.Interface.
@@ -484,6 +497,12 @@ func (reg *registry) PublishToOverlapping(
 		// Don't publish events if they are equal to or less
 		// than the registration's starting timestamp.
 		if r.catchUpTimestamp.Less(minTS) {
+			// Nor events that the registration filtered out, which is checked
+			// before the event takes up room in its buffer.
+			if !r.predicate.Matches(event) {
+				r.metrics.RangeFeedEventsFiltered.Inc(1)
+				return false, nil
+			}
 			r.publish(ctx, event, alloc)
 		}
 		return false, nil
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/future"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -122,6 +123,7 @@ func newTestRegistration(
 		ts,
 		makeCatchUpIterator(catchup, span, ts),
 		withDiff,
+		nil, /* predicate */
 		5,
 		false, /* blockWhenFull */
 		NewMetrics(),
@@ -608,3 +610,37 @@ func TestRegistryShutdownMetrics(t *testing.T) {
 	<-regDoneC
 	require.Zero(t, reg.metrics.RangeFeedRegistrations.Value(), "metric is not zero on stop")
 }
+
+func TestRegistryFilterNeedValueOp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	register := func(reg *registry, span roachpb.Span, p *kvpb.RangeFeedPredicate) {
+		r := newTestRegistration(span, hlc.Timestamp{}, nil, false /* withDiff */)
+		pred, err := NewPredicate(p)
+		require.NoError(t, err)
+		r.predicate = pred
+		reg.Register(&r.registration)
+	}
+	reg := makeRegistry(NewMetrics())
+	register(&reg, spAC, &kvpb.RangeFeedPredicate{KeyPrefixes: []roachpb.Key{keyB}})
+	register(&reg, spCD, &kvpb.RangeFeedPredicate{OmitExpired: true})
+	register(&reg, spXY, nil)
+	f := reg.NewFilter()
+
+	ts := hlc.Timestamp{WallTime: 1}
+	expireValueOp := func(key roachpb.Key) enginepb.MVCCLogicalOp {
+		return makeLogicalOp(&enginepb.MVCCExpireValueOp{Key: key, Timestamp: ts})
+	}
+	// Only the predicate of rAC applies to keyA, and rejects it.
+	require.False(t, f.NeedValueOp(writeValueOpWithKV(keyA, ts, []byte("val"))))
+	require.True(t, f.NeedValueOp(writeValueOpWithKV(keyB, ts, []byte("val"))))
+	// The predicate of rCD rejects expiries only.
+	require.True(t, f.NeedValueOp(writeValueOpWithKV(keyC, ts, []byte("val"))))
+	require.False(t, f.NeedValueOp(expireValueOp(keyC)))
+	// rXY has no predicate.
+	require.True(t, f.NeedValueOp(expireValueOp(keyX)))
+	// No registration covers keyY.
+	require.False(t, f.NeedValueOp(writeValueOpWithKV(keyY, ts, []byte("val"))))
+	// Operations on intents are always needed.
+	require.True(t, f.NeedValueOp(writeIntentOp(uuid.MakeV4(), ts)))
+}
@@ -294,6 +294,7 @@ func (p *ScheduledProcessor) Register(
 	startTS hlc.Timestamp,
 	catchUpIter *CatchUpIterator,
 	withDiff bool,
+	predicate *Predicate,
 	stream Stream,
 	disconnectFn func(),
 	done *future.ErrorFuture,
@@ -305,7 +306,7 @@ func (p *ScheduledProcessor) Register(
 
 	blockWhenFull := p.Config.EventChanTimeout == 0 // for testing
 	r := newRegistration(
-		span.AsRawSpanWithNoLocals(), startTS, catchUpIter, withDiff,
+		span.AsRawSpanWithNoLocals(), startTS, catchUpIter, withDiff, predicate,
 		p.Config.EventChanCap, blockWhenFull, p.Metrics, stream, disconnectFn, done,
 	)
 
@@ -639,6 +640,10 @@ func (p *ScheduledProcessor) consumeLogicalOps(
 			// Publish the range deletion directly.
 			p.publishDeleteRange(ctx, t.StartKey, t.EndKey, t.Timestamp, alloc)
 
+		case *enginepb.MVCCExpireValueOp:
+			// Publish the expiry as a deletion.
+			p.publishExpiry(ctx, t.Key, t.Timestamp, t.PrevValue, alloc)
+
 		case *enginepb.MVCCWriteIntentOp:
 			// No updates to publish.
 
@@ -716,6 +721,31 @@ func (p *ScheduledProcessor) publishValue(
 	p.reg.PublishToOverlapping(ctx, roachpb.Span{Key: key}, &event, alloc)
 }
 
+func (p *ScheduledProcessor) publishExpiry(
+	ctx context.Context,
+	key roachpb.Key,
+	timestamp hlc.Timestamp,
+	prevValue []byte,
+	alloc *SharedBudgetAllocation,
+) {
+	if !p.Span.ContainsKey(roachpb.RKey(key)) {
+		log.Fatalf(ctx, "key %v not in Processor's key range %v", key, p.Span)
+	}
+
+	var prevVal roachpb.Value
+	if prevValue != nil {
+		prevVal.RawBytes = prevValue
+	}
+	var event kvpb.RangeFeedEvent
+	event.MustSetValue(&kvpb.RangeFeedValue{
+		Key:       key,
+		Value:     roachpb.Value{Timestamp: timestamp},
+		PrevValue: prevVal,
+		Expired:   true,
+	})
+	p.reg.PublishToOverlapping(ctx, roachpb.Span{Key: key}, &event, alloc)
+}
+
 func (p *ScheduledProcessor) publishDeleteRange(
 	ctx context.Context,
 	startKey, endKey roachpb.Key,
@@ -887,6 +887,10 @@ type Replica struct {
 	// loadBasedSplitter keeps information about load-based splitting.
 	loadBasedSplitter split.Decider
 
+	// hotKeys tracks the most frequently accessed keys of the replica, which
+	// are reported alongside hot ranges.
+	hotKeys split.HotKeys
+
 	// unreachablesMu contains a set of remote ReplicaIDs that are to be reported
 	// as unreachable on the next raft tick.
 	unreachablesMu struct {
@@ -1620,6 +1624,14 @@ func (r *Replica) assertStateRaftMuLockedReplicaMuRLocked(
 	if err != nil {
 		log.Fatalf(ctx, "%v", err)
 	}
+	if r.store.separateRaftLogEngine() {
+		// The RaftTruncatedState is kept in the log engine, not in reader.
+		truncState, err := r.mu.stateLoader.LoadRaftTruncatedState(ctx, r.store.LogEngine())
+		if err != nil {
+			log.Fatalf(ctx, "%v", err)
+		}
+		diskState.TruncatedState = &truncState
+	}
 
 	// We don't care about this field; see comment on
 	// DeprecatedUsingAppliedStateKey for more details. This can be removed once
@@ -59,6 +59,9 @@ type replicaAppBatch struct {
 	// changeRemovesReplica tracks whether the command in the batch (there must
 	// be only one) removes this replica from the range.
 	changeRemovesReplica bool
+	// mergeDestroysReplica tracks whether the command in the batch is a merge,
+	// which destroys the subsumed replica.
+	mergeDestroysReplica bool
 
 	start                   time.Time // time at NewBatch()
 	followerStoreWriteBytes kvadmission.FollowerStoreWriteBytes
@@ -346,6 +349,7 @@ func (b *replicaAppBatch) runPostAddTriggersReplicaOnly(
 		}); err != nil {
 			return errors.Wrapf(err, "unable to destroy replica before merge")
 		}
+		b.mergeDestroysReplica = true
 
 		// Shut down rangefeed processors on either side of the merge.
 		//
@@ -413,6 +417,12 @@ func (b *replicaAppBatch) runPostAddTriggersReplicaOnly(
 		// will recompute the size. This has no correctness impact, so we are not
 		// going to bother with a long-running migration.
 		apply := !looselyCoupledTruncation || res.RaftExpectedFirstIndex == 0
+		if b.r.store.separateRaftLogEngine() {
+			// The raft log can't be truncated in this batch, since it is not in the
+			// engine this batch commits to. The raft log truncator truncates it once
+			// the applied state of this batch is durable.
+			looselyCoupledTruncation, apply = true, false
+		}
 		if apply {
 			if apply, err = handleTruncatedStateBelowRaftPreApply(
 				ctx, b.state.TruncatedState, res.State.TruncatedState, b.r.raftMu.stateLoader, b.batch,
@@ -561,8 +571,11 @@ func (b *replicaAppBatch) ApplyToStateMachine(ctx context.Context) error {
 	// applied again upon startup. However, if we're removing the replica's data
 	// then we sync this batch as it is not safe to call postDestroyRaftMuLocked
 	// before ensuring that the replica's data has been synchronously removed.
-	// See handleChangeReplicasResult().
-	sync := b.changeRemovesReplica
+	// See handleChangeReplicasResult(). With a separate raft log engine, the
+	// same holds for the replica subsumed by a merge, whose raft state
+	// postDestroyRaftMuLocked removes from the log engine.
+	sync := b.changeRemovesReplica ||
+		(b.mergeDestroysReplica && b.r.store.separateRaftLogEngine())
 	if err := b.batch.Commit(sync); err != nil {
 		return errors.Wrapf(err, "unable to commit Raft entry batch")
 	}
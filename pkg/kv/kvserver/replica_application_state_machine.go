@@ -144,6 +144,9 @@ func (sm *replicaStateMachine) NewBatch() apply.Batch {
 	b.state.Stats = &sm.stats
 	*b.state.Stats = *r.mu.state.Stats
 	b.closedTimestampSetter = r.mu.closedTimestampSetter
+	if rDesc, ok := r.mu.state.Desc.GetReplicaDescriptorByID(r.replicaID); ok {
+		b.ab.witness = rDesc.IsWitness()
+	}
 	r.mu.RUnlock()
 	b.start = timeutil.Now()
 	return b
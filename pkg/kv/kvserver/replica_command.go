@@ -674,6 +674,12 @@ func (r *Replica) AdminMerge(
 			return errors.Errorf("cannot merge ranges when rhs is in a joint state or has learners: %s",
 				rReplicas)
 		}
+		if len(lReplicas.WitnessDescriptors())+len(rReplicas.WitnessDescriptors()) > 0 {
+			// A witness on one side could be collocated with a full replica on
+			// the other side, which would end up without the data of the witness'
+			// side after the merge.
+			return errors.Errorf("cannot merge ranges with witnesses: %s, %s", lReplicas, rReplicas)
+		}
 		if !replicasCollocated(lReplicas.Descriptors(), rReplicas.Descriptors()) {
 			return errors.Errorf("ranges not collocated; %s != %s", lReplicas, rReplicas)
 		}
@@ -1044,6 +1050,8 @@ func (r *Replica) changeReplicasImpl(
 	// 3. Voter removals
 	// 4. Non-voter additions
 	// 5. Non-voter removals
+	// 6. Witness additions
+	// 7. Witness removals
 	//
 	// This order is meant to be symmetric with how the allocator prioritizes
 	// these actions. Broadly speaking, we first want to add a missing voter (and
@@ -1155,6 +1163,36 @@ func (r *Replica) changeReplicasImpl(
 		}
 	}
 
+	if adds := targets.WitnessAdditions; len(adds) > 0 {
+		// Witnesses are added as voters right away rather than going through a
+		// LEARNER first: their initial snapshot only contains the range-local
+		// state of the replica, so they catch up quickly.
+		desc, err = r.initializeRaftLearners(
+			ctx, desc, priority, senderName, senderQueuePriority, reason, details, adds, roachpb.WITNESS,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if removals := targets.WitnessRemovals; len(removals) > 0 {
+		for _, rem := range removals {
+			iChgs := []internalReplicationChange{{target: rem, typ: internalChangeTypeRemoveWitness}}
+			var err error
+			desc, err = execChangeReplicasTxn(ctx, r.store.cfg.Tracer(), desc, reason, details, iChgs,
+				changeReplicasTxnArgs{
+					db:                                   r.store.DB(),
+					liveAndDeadReplicas:                  r.store.cfg.StorePool.LiveAndDeadReplicas,
+					logChange:                            r.store.logChange,
+					testForceJointConfig:                 r.store.TestingKnobs().ReplicationAlwaysUseJointConfig,
+					testAllowDangerousReplicationChanges: r.store.TestingKnobs().AllowDangerousReplicationChanges,
+				})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if len(targets.VoterDemotions) > 0 {
 		// If we demoted or swapped any voters with non-voters, we likely are in a
 		// joint config or have learners on the range. Let's exit the joint config
@@ -1171,6 +1209,7 @@ type TargetsForReplicationChanges struct {
 	VoterDemotions, NonVoterPromotions  []roachpb.ReplicationTarget
 	VoterAdditions, VoterRemovals       []roachpb.ReplicationTarget
 	NonVoterAdditions, NonVoterRemovals []roachpb.ReplicationTarget
+	WitnessAdditions, WitnessRemovals   []roachpb.ReplicationTarget
 }
 
 // SynthesizeTargetsByChangeType groups replication changes in the
@@ -1197,6 +1236,10 @@ func SynthesizeTargetsByChangeType(
 	result.NonVoterAdditions = subtractTargets(chgs.NonVoterAdditions(), chgs.VoterRemovals())
 	result.NonVoterRemovals = subtractTargets(chgs.NonVoterRemovals(), chgs.VoterAdditions())
 
+	// Witnesses are never promoted or demoted, see validateWitnessChanges.
+	result.WitnessAdditions = chgs.WitnessAdditions()
+	result.WitnessRemovals = chgs.WitnessRemovals()
+
 	return result
 }
 
@@ -1455,6 +1498,9 @@ func validateAdditionsPerStore(
 					return errors.AssertionFailedf(
 						"trying to add a non-voter to a store that already has a %s", t)
 				}
+			case roachpb.WITNESS:
+				return errors.AssertionFailedf(
+					"trying to add(%+v) to a store that already has a %s", chg, t)
 			default:
 				return errors.AssertionFailedf("store(%d) being added to already contains a"+
 					" replica of an unexpected type: %s", storeID, t)
@@ -1489,6 +1535,11 @@ func validateRemovals(desc *roachpb.RangeDescriptor, chgsByStoreID changesByStor
 					return errors.AssertionFailedf("type of replica being removed (%s) does not match"+
 						" expectation for change: %+v", t, chg)
 				}
+			case roachpb.WITNESS:
+				if chg.ChangeType != roachpb.REMOVE_WITNESS {
+					return errors.AssertionFailedf("type of replica being removed (%s) does not match"+
+						" expectation for change: %+v", t, chg)
+				}
 			default:
 				return errors.AssertionFailedf("unexpected replica type for removal %+v: %s", chg, t)
 			}
@@ -1497,6 +1548,16 @@ func validateRemovals(desc *roachpb.RangeDescriptor, chgsByStoreID changesByStor
 	return nil
 }
 
+// validateWitnessChanges ensures that witnesses are added or removed on their
+// own. Witnesses change the quorum of the range without going through a joint
+// config, so etcd/raft only accepts them as a single change.
+func validateWitnessChanges(chgs kvpb.ReplicationChanges) error {
+	if len(chgs.WitnessAdditions())+len(chgs.WitnessRemovals()) > 0 && len(chgs) > 1 {
+		return errors.Errorf("witnesses must be added or removed in a change of their own: %+v", chgs)
+	}
+	return nil
+}
+
 // validatePromotionsAndDemotions ensures the following:
 // 1. All additions of voters to stores that already have a non-voter are
 // accompanied by a removal of that non-voter (which is interpreted as a
@@ -1638,6 +1699,9 @@ func validateReplicationChanges(desc *roachpb.RangeDescriptor, chgs kvpb.Replica
 	chgsByStoreID := getChangesByStoreID(chgs)
 	chgsByNodeID := getChangesByNodeID(chgs)
 
+	if err := validateWitnessChanges(chgs); err != nil {
+		return err
+	}
 	if err := validateAdditionsPerStore(desc, chgsByStoreID); err != nil {
 		return err
 	}
@@ -1686,7 +1750,8 @@ func getChangesByNodeID(chgs kvpb.ReplicationChanges) changesByNodeID {
 
 // initializeRaftLearners adds etcd LearnerNodes (LEARNERs or NON_VOTERs in
 // Cockroach-land) to the given replication targets and synchronously sends them
-// an initial snapshot to upreplicate. Once this successfully returns, the
+// an initial snapshot to upreplicate. It is also used to add WITNESSes, which
+// are voters in etcd/raft but are initialized the same way. Once this successfully returns, the
 // callers can assume that the learners were added and have been initialized via
 // that snapshot. Otherwise, if we get any errors trying to add or upreplicate
 // any of these learners, this function will clean up after itself by rolling all
@@ -1708,6 +1773,8 @@ func (r *Replica) initializeRaftLearners(
 		iChangeType = internalChangeTypeAddLearner
 	case roachpb.NON_VOTER:
 		iChangeType = internalChangeTypeAddNonVoter
+	case roachpb.WITNESS:
+		iChangeType = internalChangeTypeAddWitness
 	default:
 		log.Fatalf(ctx, "unexpected replicaType %s", replicaType)
 	}
@@ -1966,6 +2033,8 @@ func (r *Replica) tryRollbackRaftLearner(
 		removeChgType = internalChangeTypeRemoveNonVoter
 	case roachpb.LEARNER:
 		removeChgType = internalChangeTypeRemoveLearner
+	case roachpb.WITNESS:
+		removeChgType = internalChangeTypeRemoveWitness
 	default:
 		log.Event(ctx, "replica to rollback is no longer a learner; skipping")
 		return
@@ -2016,6 +2085,9 @@ const (
 	_ internalChangeType = iota + 1
 	internalChangeTypeAddLearner
 	internalChangeTypeAddNonVoter
+	// internalChangeTypeAddWitness adds a witness, which changes the quorum of
+	// the range. It is only ever used as a simple (non-joint) change.
+	internalChangeTypeAddWitness
 	// NB: internalChangeTypePromote{Learner,Voter} are quite similar to each
 	// other. We only chose to differentiate them in order to be able to assert on
 	// the type of replica being promoted. See `prepareChangeReplicasTrigger`.
@@ -2036,6 +2108,7 @@ const (
 	// https://github.com/cockroachdb/cockroach/pull/40268
 	internalChangeTypeRemoveLearner
 	internalChangeTypeRemoveNonVoter
+	internalChangeTypeRemoveWitness
 )
 
 // internalReplicationChange is a replication target together with an internal
@@ -2089,6 +2162,9 @@ func prepareChangeReplicasTrigger(
 			case internalChangeTypeAddNonVoter:
 				added = append(added,
 					updatedDesc.AddReplica(chg.target.NodeID, chg.target.StoreID, roachpb.NON_VOTER))
+			case internalChangeTypeAddWitness:
+				added = append(added,
+					updatedDesc.AddReplica(chg.target.NodeID, chg.target.StoreID, roachpb.WITNESS))
 			case internalChangeTypePromoteLearner:
 				typ := roachpb.VOTER_FULL
 				if useJoint {
@@ -2111,14 +2187,17 @@ func prepareChangeReplicasTrigger(
 						chg.target)
 				}
 				added = append(added, rDesc)
-			case internalChangeTypeRemoveLearner, internalChangeTypeRemoveNonVoter:
+			case internalChangeTypeRemoveLearner, internalChangeTypeRemoveNonVoter,
+				internalChangeTypeRemoveWitness:
 				rDesc, ok := updatedDesc.GetReplicaDescriptor(chg.target.StoreID)
 				if !ok {
 					return nil, errors.Errorf("target %s not found", chg.target)
 				}
 				prevTyp := rDesc.Type
 				isRaftLearner := prevTyp == roachpb.LEARNER || prevTyp == roachpb.NON_VOTER
-				if !useJoint || isRaftLearner {
+				// Witnesses are never turned into outgoing voters, see
+				// internalChangeTypeAddWitness.
+				if !useJoint || isRaftLearner || prevTyp == roachpb.WITNESS {
 					rDesc, _ = updatedDesc.RemoveReplica(chg.target.NodeID, chg.target.StoreID)
 				} else if prevTyp != roachpb.VOTER_FULL {
 					// NB: prevTyp is already known to be VOTER_FULL because of
@@ -2516,18 +2595,20 @@ func recordRangeEventsInLog(
 	logChange logChangeFn,
 ) error {
 	for _, repDesc := range repDescs {
-		isNonVoter := repDesc.Type == roachpb.NON_VOTER
 		var typ roachpb.ReplicaChangeType
-		if added {
+		switch {
+		case added && repDesc.Type == roachpb.NON_VOTER:
+			typ = roachpb.ADD_NON_VOTER
+		case added && repDesc.Type == roachpb.WITNESS:
+			typ = roachpb.ADD_WITNESS
+		case added:
 			typ = roachpb.ADD_VOTER
-			if isNonVoter {
-				typ = roachpb.ADD_NON_VOTER
-			}
-		} else {
+		case repDesc.Type == roachpb.NON_VOTER:
+			typ = roachpb.REMOVE_NON_VOTER
+		case repDesc.Type == roachpb.WITNESS:
+			typ = roachpb.REMOVE_WITNESS
+		default:
 			typ = roachpb.REMOVE_VOTER
-			if isNonVoter {
-				typ = roachpb.REMOVE_NON_VOTER
-			}
 		}
 		if err := logChange(
 			ctx, txn, typ, repDesc, *rangeDesc, reason, details, logAsync,
@@ -2552,6 +2633,13 @@ func (r *Replica) getSenderReplicas(
 		return nil, err
 	}
 
+	// A witness coordinator (i.e. a witness that is the raft leader) doesn't
+	// have the user data of the range, so it must always delegate the snapshot
+	// to one of the full replicas.
+	if coordinator.IsWitness() {
+		return r.getNonWitnessSenderReplicas(recipient)
+	}
+
 	// Unless all nodes are on V23.1, don't delegate. This prevents sending to a
 	// node that doesn't understand the request.
 	if !r.store.ClusterSettings().Version.IsActive(ctx, clusterversion.V23_1) {
@@ -3139,7 +3227,12 @@ func (r *Replica) followerSendSnapshot(
 	// a snapshot for a non-system range. This allows us to send metadata of
 	// sstables in shared storage as opposed to streaming their contents. Keys
 	// in higher levels of the LSM are still streamed in the snapshot.
-	sharedReplicate := r.store.cfg.SharedStorageEnabled && snap.State.Desc.StartKey.AsRawKey().Compare(keys.TableDataMin) >= 0
+	//
+	// Witnesses don't receive the user keys of the range at all, see
+	// kvBatchSnapshotStrategy.Send.
+	recipient, _ := snap.State.Desc.GetReplicaDescriptorByID(req.RecipientReplica.ReplicaID)
+	sharedReplicate := r.store.cfg.SharedStorageEnabled && !recipient.IsWitness() &&
+		snap.State.Desc.StartKey.AsRawKey().Compare(keys.TableDataMin) >= 0
 
 	// Create new snapshot request header using the delegate snapshot request.
 	header := kvserverpb.SnapshotRequest_Header{
@@ -3228,6 +3321,32 @@ func (r *Replica) followerSendSnapshot(
 	return msgAppResp, nil
 }
 
+// getNonWitnessSenderReplicas returns the replicas that a witness coordinator
+// can delegate a snapshot to the given recipient to: all voters and non-voters
+// on healthy stores, ordered by their locality distance to the recipient.
+func (r *Replica) getNonWitnessSenderReplicas(
+	recipient roachpb.ReplicaDescriptor,
+) ([]roachpb.ReplicaDescriptor, error) {
+	storePool := r.store.cfg.StorePool
+	rangeDesc := r.Desc()
+	candidates := rangeDesc.Replicas().Filter(
+		func(rDesc roachpb.ReplicaDescriptor) bool {
+			return rDesc.ReplicaID != recipient.ReplicaID && storePool.IsStoreHealthy(rDesc.StoreID)
+		},
+	).VoterAndNonVoterDescriptors()
+	if len(candidates) == 0 {
+		return nil, errors.Errorf("no non-witness replica to delegate a snapshot to %v to", recipient)
+	}
+	senders := append([]roachpb.ReplicaDescriptor(nil), candidates...)
+	localities := storePool.GetLocalitiesPerReplica(senders...)
+	recipientLocality := storePool.GetLocalitiesPerReplica(recipient)[recipient.ReplicaID]
+	sort.SliceStable(senders, func(i, j int) bool {
+		return recipientLocality.DiversityScore(localities[senders[i].ReplicaID]) <
+			recipientLocality.DiversityScore(localities[senders[j].ReplicaID])
+	})
+	return senders, nil
+}
+
 // replicasCollocated is used in AdminMerge to ensure that the ranges are
 // all collocate on the same set of replicas.
 func replicasCollocated(a, b []roachpb.ReplicaDescriptor) bool {
@@ -16,6 +16,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -36,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -229,6 +231,17 @@ func (r *Replica) checkConsistencyImpl(
 		return resp, nil
 	}
 
+	if consistencyCheckRepairEnabled.Get(&r.ClusterSettings().SV) {
+		if divergent, ok := divergentReplicasToRepair(
+			r.Desc(), r.replicaID, results, shaToIdxs,
+		); ok {
+			r.repairInconsistency(ctx, args, divergent)
+			return resp, nil
+		}
+		log.Warningf(ctx, "consistency check failed; unable to repair since no "+
+			"quorum of replicas agree with the leaseholder")
+	}
+
 	// No checkpoint was requested, so we want to re-run the check with
 	// checkpoints and termination of suspicious nodes. Note that this recursive
 	// call will be terminated in the `args.Checkpoint` branch above.
@@ -263,6 +276,114 @@ func (r *Replica) checkConsistencyImpl(
 	return resp, nil
 }
 
+// divergentReplicasToRepair returns the replicas which disagree with the
+// checksum computed by a quorum of the range's voters, and should be removed to
+// repair the range. Repair is only attempted when the leaseholder is among the
+// agreeing quorum and the range is not in a joint configuration, otherwise
+// false is returned. Replicas whose checksum could not be collected are never
+// considered divergent.
+func divergentReplicasToRepair(
+	desc *roachpb.RangeDescriptor,
+	leaseholder roachpb.ReplicaID,
+	results []ConsistencyCheckResult,
+	shaToIdxs map[string][]int,
+) ([]roachpb.ReplicaDescriptor, bool) {
+	if desc.Replicas().InAtomicReplicationChange() {
+		return nil, false
+	}
+	// Find the checksum computed by the most replicas. If more than one
+	// checksum is tied for most, there is no agreement.
+	var majoritySHA string
+	var tied bool
+	for sha, idxs := range shaToIdxs {
+		if majoritySHA == "" || len(idxs) > len(shaToIdxs[majoritySHA]) {
+			majoritySHA, tied = sha, false
+		} else if len(idxs) == len(shaToIdxs[majoritySHA]) {
+			tied = true
+		}
+	}
+	if majoritySHA == "" || tied {
+		return nil, false
+	}
+
+	var agreeingVoters int
+	var leaseholderAgrees bool
+	for _, idx := range shaToIdxs[majoritySHA] {
+		rDesc := results[idx].Replica
+		if rDesc.Type == roachpb.VOTER_FULL {
+			agreeingVoters++
+		}
+		if rDesc.ReplicaID == leaseholder {
+			leaseholderAgrees = true
+		}
+	}
+	if quorum := len(desc.Replicas().VoterDescriptors())/2 + 1; agreeingVoters < quorum ||
+		!leaseholderAgrees {
+		return nil, false
+	}
+
+	var divergent []roachpb.ReplicaDescriptor
+	for sha, idxs := range shaToIdxs {
+		if sha == majoritySHA {
+			continue
+		}
+		for _, idx := range idxs {
+			divergent = append(divergent, results[idx].Replica)
+		}
+	}
+	sort.Slice(divergent, func(i, j int) bool {
+		return divergent[i].ReplicaID < divergent[j].ReplicaID
+	})
+	return divergent, len(divergent) > 0
+}
+
+// repairInconsistency repairs a range with divergent replicas. The consistency
+// check is re-run to save storage engine checkpoints on every replica, without
+// terminating any node. The divergent replicas are then removed from the range,
+// and the replicate queue replaces them by sending snapshots of the consistent
+// data.
+func (r *Replica) repairInconsistency(
+	ctx context.Context, args kvpb.ComputeChecksumRequest, divergent []roachpb.ReplicaDescriptor,
+) {
+	set := roachpb.MakeReplicaSet(divergent)
+	log.Errorf(ctx, "consistency check failed; fetching details and repairing minority %v", set)
+
+	defer log.TemporarilyDisableFileGCForMainLogger()()
+
+	args.Checkpoint = true
+	if _, pErr := r.checkConsistencyImpl(ctx, args); pErr != nil {
+		log.Errorf(ctx, "replica inconsistency detected; second round failed: %s", pErr)
+	}
+
+	chgs := make(kvpb.ReplicationChanges, 0, len(divergent))
+	for _, rDesc := range divergent {
+		changeType := roachpb.REMOVE_VOTER
+		if rDesc.Type == roachpb.NON_VOTER {
+			changeType = roachpb.REMOVE_NON_VOTER
+		}
+		chgs = append(chgs, kvpb.ReplicationChange{
+			ChangeType: changeType,
+			Target:     roachpb.ReplicationTarget{NodeID: rDesc.NodeID, StoreID: rDesc.StoreID},
+		})
+	}
+	ev := &eventpb.ReplicaInconsistencyRepair{
+		NodeID:          int32(r.NodeID()),
+		RangeID:         int64(r.RangeID),
+		RemovedReplicas: set.String(),
+	}
+	if _, err := r.ChangeReplicas(
+		ctx, r.Desc(), kvserverpb.SnapshotRequest_RECOVERY, kvserverpb.ReasonReplicaInconsistent,
+		fmt.Sprintf("removing replicas %s which diverged from a quorum", set), chgs,
+	); err != nil {
+		log.Errorf(ctx, "unable to remove divergent replicas %v: %v", set, err)
+		ev.ErrorMessage = err.Error()
+	} else {
+		ev.Success = true
+		r.store.replicateQueue.MaybeAddAsync(ctx, r, r.store.Clock().NowAsClockTimestamp())
+	}
+	log.StructuredEvent(ctx, ev)
+}
+
 // A ConsistencyCheckResult contains the outcome of a CollectChecksum call.
 type ConsistencyCheckResult struct {
 	Replica  roachpb.ReplicaDescriptor
@@ -306,7 +427,11 @@ func (r *Replica) runConsistencyCheck(
 	}
 	ccRes := res.(*kvpb.ComputeChecksumResponse)
 
-	replicas := r.Desc().Replicas().Descriptors()
+	// Witnesses don't apply user writes, so their checksum never matches that of
+	// the other replicas.
+	replicas := r.Desc().Replicas().Filter(func(rDesc roachpb.ReplicaDescriptor) bool {
+		return !rDesc.IsWitness()
+	}).Descriptors()
 	resultCh := make(chan ConsistencyCheckResult, len(replicas))
 	results := make([]ConsistencyCheckResult, 0, len(replicas))
 
@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 )
@@ -170,6 +171,117 @@ func TestStoreCheckpointSpans(t *testing.T) {
 	}
 }
 
+func TestDivergentReplicasToRepair(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	repl := func(id roachpb.ReplicaID, typ roachpb.ReplicaType) roachpb.ReplicaDescriptor {
+		return roachpb.ReplicaDescriptor{
+			NodeID:    roachpb.NodeID(id),
+			StoreID:   roachpb.StoreID(id),
+			ReplicaID: id,
+			Type:      typ,
+		}
+	}
+	voters := func(n int) []roachpb.ReplicaDescriptor {
+		var descs []roachpb.ReplicaDescriptor
+		for i := 1; i <= n; i++ {
+			descs = append(descs, repl(roachpb.ReplicaID(i), roachpb.VOTER_FULL))
+		}
+		return descs
+	}
+
+	for _, tc := range []struct {
+		name        string
+		replicas    []roachpb.ReplicaDescriptor
+		leaseholder roachpb.ReplicaID
+		// checksums is the checksum reported by each replica, in the same order
+		// as replicas. An empty checksum denotes a failure to collect it.
+		checksums []string
+		expected  []roachpb.ReplicaID
+	}{
+		{
+			name:        "single divergent voter",
+			replicas:    voters(3),
+			leaseholder: 1,
+			checksums:   []string{"a", "a", "b"},
+			expected:    []roachpb.ReplicaID{3},
+		},
+		{
+			name:        "two divergent voters out of five",
+			replicas:    voters(5),
+			leaseholder: 1,
+			checksums:   []string{"a", "b", "a", "c", "a"},
+			expected:    []roachpb.ReplicaID{2, 4},
+		},
+		{
+			name:        "leaseholder diverges",
+			replicas:    voters(3),
+			leaseholder: 3,
+			checksums:   []string{"a", "a", "b"},
+		},
+		{
+			name:        "no quorum agrees",
+			replicas:    voters(5),
+			leaseholder: 1,
+			checksums:   []string{"a", "a", "b", "c", ""},
+		},
+		{
+			name:        "tied checksums",
+			replicas:    voters(4),
+			leaseholder: 1,
+			checksums:   []string{"a", "a", "b", "b"},
+		},
+		{
+			name: "divergent non-voter",
+			replicas: append(voters(3),
+				repl(4, roachpb.NON_VOTER)),
+			leaseholder: 1,
+			checksums:   []string{"a", "a", "a", "b"},
+			expected:    []roachpb.ReplicaID{4},
+		},
+		{
+			name: "non-voters don't count towards quorum",
+			replicas: append(voters(3),
+				repl(4, roachpb.NON_VOTER), repl(5, roachpb.NON_VOTER)),
+			leaseholder: 1,
+			checksums:   []string{"a", "b", "b", "a", "a"},
+		},
+		{
+			name: "joint configuration",
+			replicas: append(voters(2),
+				repl(3, roachpb.VOTER_INCOMING), repl(4, roachpb.VOTER_DEMOTING_LEARNER)),
+			leaseholder: 1,
+			checksums:   []string{"a", "a", "a", "b"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			desc := roachpb.NewRangeDescriptor(1, roachpb.RKeyMin, roachpb.RKeyMax,
+				roachpb.MakeReplicaSet(tc.replicas))
+			var results []ConsistencyCheckResult
+			shaToIdxs := map[string][]int{}
+			for i, sha := range tc.checksums {
+				result := ConsistencyCheckResult{Replica: tc.replicas[i]}
+				if sha == "" {
+					result.Err = errors.New("boom")
+				} else {
+					result.Response.Checksum = []byte(sha)
+					shaToIdxs[sha] = append(shaToIdxs[sha], i)
+				}
+				results = append(results, result)
+			}
+
+			divergent, ok := divergentReplicasToRepair(desc, tc.leaseholder, results, shaToIdxs)
+			require.Equal(t, len(tc.expected) > 0, ok)
+			var ids []roachpb.ReplicaID
+			for _, rDesc := range divergent {
+				ids = append(ids, rDesc.ReplicaID)
+			}
+			require.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
 func TestGetChecksumNotSuccessfulExitConditions(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
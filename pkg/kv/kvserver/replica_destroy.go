@@ -71,6 +71,15 @@ func (s destroyStatus) Removed() bool {
 const mergedTombstoneReplicaID roachpb.ReplicaID = math.MaxInt32
 
 func (r *Replica) postDestroyRaftMuLocked(ctx context.Context, ms enginepb.MVCCStats) error {
+	// If the store keeps its raft logs in a separate engine, destroying the
+	// replica in the state engine left its raft state in the log engine. The
+	// callers have made the destruction durable, so remove it now.
+	if r.store.separateRaftLogEngine() {
+		if err := kvstorage.ClearRaftState(ctx, r.store.LogEngine(), r.RangeID); err != nil {
+			return err
+		}
+	}
+
 	// NB: we need the nil check below because it's possible that we're GC'ing a
 	// Replica without a replicaID, in which case it does not have a sideloaded
 	// storage.
@@ -111,7 +120,7 @@ func (r *Replica) destroyRaftMuLocked(ctx context.Context, nextReplicaID roachpb
 	startTime := timeutil.Now()
 
 	ms := r.GetMVCCStats()
-	batch := r.store.TODOEngine().NewWriteBatch()
+	batch := r.store.StateEngine().NewWriteBatch()
 	defer batch.Close()
 	desc := r.Desc()
 	inited := desc.IsInitialized()
@@ -126,8 +135,9 @@ func (r *Replica) destroyRaftMuLocked(ctx context.Context, nextReplicaID roachpb
 		ClearReplicatedByRangeID:   inited,
 		ClearUnreplicatedByRangeID: true,
 	}
-	// TODO(sep-raft-log): need both engines separately here.
-	if err := kvstorage.DestroyReplica(ctx, r.RangeID, r.store.TODOEngine(), batch, nextReplicaID, opts); err != nil {
+	// NB: the raft state in a separate raft log engine is removed by
+	// postDestroyRaftMuLocked.
+	if err := kvstorage.DestroyReplica(ctx, r.RangeID, r.store.StateEngine(), batch, nextReplicaID, opts); err != nil {
 		return err
 	}
 	preTime := timeutil.Now()
@@ -12,13 +12,20 @@ package kvserver
 
 import (
 	"context"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvbase"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
 )
 
@@ -34,6 +41,23 @@ var FollowerReadsEnabled = settings.RegisterBoolSetting(
 	settings.WithName("kv.closed_timestamp.follower_reads.enabled"),
 	settings.WithPublic)
 
+// ReadIndexFollowerReadsEnabled controls whether replicas serve reads above
+// their closed timestamp for batches that set Header.AllowReadIndex, after a
+// read index round trip to the leaseholder.
+var ReadIndexFollowerReadsEnabled = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.follower_reads.read_index.enabled",
+	"allow replicas to serve fresh reads that opt into it by confirming a read index with the leaseholder",
+	true,
+)
+
+// readIndexTimeout bounds the time that a follower spends on a read index: the
+// round trip to the leaseholder, which may be slow to respond or be partitioned
+// away, and waiting to apply the lease applied index that the leaseholder
+// returns. A follower that can't confirm a read index quicker than this is
+// better off letting the read be redirected to the leaseholder.
+const readIndexTimeout = 500 * time.Millisecond
+
 // BatchCanBeEvaluatedOnFollower determines if a batch consists exclusively of
 // requests that can be evaluated on a follower replica, given a sufficiently
 // advanced closed timestamp.
@@ -107,6 +131,12 @@ func (r *Replica) canServeFollowerReadRLocked(ctx context.Context, ba *kvpb.Batc
 	maxClosed := r.getCurrentClosedTimestampLocked(ctx, requiredFrontier /* sufficient */)
 	canServeFollowerRead := requiredFrontier.LessEq(maxClosed)
 	tsDiff := requiredFrontier.GoTime().Sub(maxClosed.GoTime())
+	if !canServeFollowerRead && r.confirmedReadIndexRLocked(ctx, ba) {
+		log.Eventf(ctx, "%s; query timestamp confirmed by read index", redact.Safe(kvbase.FollowerReadServingMsg))
+		r.store.metrics.FollowerReadsCount.Inc(1)
+		r.store.metrics.FollowerReadIndexCount.Inc(1)
+		return true
+	}
 	if !canServeFollowerRead {
 		uncertaintyLimitStr := "n/a"
 		if ba.Txn != nil {
@@ -156,3 +186,126 @@ func (r *Replica) GetCurrentClosedTimestamp(ctx context.Context) hlc.Timestamp {
 	defer r.mu.RUnlock()
 	return r.getCurrentClosedTimestampLocked(ctx, hlc.Timestamp{} /* sufficient */)
 }
+
+// readIndexKey is the context key under which maybeConfirmReadIndex records the
+// readIndex that it obtained for a batch.
+type readIndexKey struct{}
+
+// readIndex describes a read index confirmed by the leaseholder of a range:
+// the replica has applied every write to the span at or below the frontier,
+// and no write to the span at or below the timestamp of the read can happen
+// anymore.
+type readIndex struct {
+	rangeID  roachpb.RangeID
+	span     roachpb.RSpan
+	frontier hlc.Timestamp
+	lai      kvpb.LeaseAppliedIndex
+}
+
+// maybeConfirmReadIndex performs a read index round trip to the leaseholder
+// for a read-only batch that opted into it with Header.AllowReadIndex, if this
+// replica is a follower that cannot serve the batch under its closed
+// timestamp. The leaseholder returns a lease applied index that covers all the
+// writes the batch must observe, which the replica waits to apply before it
+// returns a context that lets canServeFollowerReadRLocked accept the batch.
+//
+// Failures are not returned: the batch then proceeds as it otherwise would,
+// and is redirected to the leaseholder.
+func (r *Replica) maybeConfirmReadIndex(
+	ctx context.Context, ba *kvpb.BatchRequest,
+) context.Context {
+	st := r.store.cfg.Settings
+	if !ba.AllowReadIndex || ba.BoundedStaleness != nil ||
+		!ReadIndexFollowerReadsEnabled.Get(&st.SV) || !FollowerReadsEnabled.Get(&st.SV) ||
+		!st.Version.IsActive(ctx, clusterversion.V23_2_ReadIndexFollowerReads) ||
+		!BatchCanBeEvaluatedOnFollower(ba) {
+		return ctx
+	}
+	rSpan, err := keys.Range(ba.Requests)
+	if err != nil {
+		return ctx
+	}
+	requiredFrontier := ba.RequiredFrontier()
+	r.mu.RLock()
+	status := r.leaseStatusForRequestRLocked(ctx, r.Clock().NowAsClockTimestamp(), ba.WriteTimestamp())
+	maxClosed := r.getCurrentClosedTimestampLocked(ctx, requiredFrontier /* sufficient */)
+	r.mu.RUnlock()
+	// Only followers of a valid lease need a read index. Without a valid lease,
+	// the batch goes through the usual lease acquisition.
+	if !status.IsValid() || status.OwnedBy(r.StoreID()) || requiredFrontier.LessEq(maxClosed) {
+		return ctx
+	}
+
+	lai, err := r.readIndex(ctx, ba, rSpan, requiredFrontier)
+	if err != nil {
+		log.VEventf(ctx, 2, "failed to confirm read index: %v", err)
+		r.store.metrics.FollowerReadIndexErrorCount.Inc(1)
+		return ctx
+	}
+	return context.WithValue(ctx, readIndexKey{}, readIndex{
+		rangeID:  r.RangeID,
+		span:     rSpan,
+		frontier: requiredFrontier,
+		lai:      lai,
+	})
+}
+
+// readIndex sends a ReadIndexRequest for the batch to the leaseholder, and
+// waits for the replica to apply the lease applied index that it returns.
+func (r *Replica) readIndex(
+	ctx context.Context, ba *kvpb.BatchRequest, rSpan roachpb.RSpan, frontier hlc.Timestamp,
+) (lai kvpb.LeaseAppliedIndex, _ error) {
+	err := timeutil.RunWithTimeout(ctx, "read index", readIndexTimeout,
+		func(ctx context.Context) error {
+			resp, pErr := kv.SendWrappedWithAdmission(ctx, r.store.DB().NonTransactionalSender(),
+				kvpb.Header{Timestamp: ba.Timestamp}, ba.AdmissionHeader,
+				&kvpb.ReadIndexRequest{
+					RequestHeader:    kvpb.RequestHeaderFromSpan(rSpan.AsRawSpanWithNoLocals()),
+					UncertaintyLimit: frontier,
+				})
+			if pErr != nil {
+				return pErr.GoError()
+			}
+			reply := resp.(*kvpb.ReadIndexResponse)
+			if reply.RangeID != r.RangeID {
+				return errors.Errorf("read index served by r%d", reply.RangeID)
+			}
+			log.VEventf(ctx, 2, "waiting to apply read index %d", reply.LeaseAppliedIndex)
+
+			retryOpts := retry.Options{InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+			for re := retry.StartWithCtx(ctx, retryOpts); re.Next(); {
+				r.mu.RLock()
+				appliedLAI := r.mu.state.LeaseAppliedIndex
+				_, err := r.isDestroyedRLocked()
+				r.mu.RUnlock()
+				if err != nil {
+					return err
+				}
+				if appliedLAI >= reply.LeaseAppliedIndex {
+					lai = reply.LeaseAppliedIndex
+					return nil
+				}
+			}
+			return ctx.Err()
+		})
+	if err != nil {
+		return 0, err
+	}
+	return lai, nil
+}
+
+// confirmedReadIndexRLocked returns whether the batch can be served on this
+// replica thanks to a read index confirmed by maybeConfirmReadIndex.
+func (r *Replica) confirmedReadIndexRLocked(ctx context.Context, ba *kvpb.BatchRequest) bool {
+	ri, ok := ctx.Value(readIndexKey{}).(readIndex)
+	if !ok || ri.rangeID != r.RangeID {
+		return false
+	}
+	// The context of the batch may be passed on to other requests evaluated on
+	// its behalf, so make sure that this is the batch the read index is for.
+	rSpan, err := keys.Range(ba.Requests)
+	if err != nil || !ri.span.ContainsKeyRange(rSpan.Key, rSpan.EndKey) {
+		return false
+	}
+	return ba.RequiredFrontier().LessEq(ri.frontier) && ri.lai <= r.mu.state.LeaseAppliedIndex
+}
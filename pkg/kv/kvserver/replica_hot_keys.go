@@ -0,0 +1,92 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/split"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// HotKeysSampleRate is the fraction of batches whose keys are recorded in
+// the replica's hot key tracker.
+var HotKeysSampleRate = settings.RegisterFloatSetting(
+	settings.SystemOnly,
+	"kv.hot_keys.sample_rate",
+	"the fraction of batch requests which are sampled to find the hottest keys of each range; "+
+		"set to 0 to disable hot key tracking",
+	0.01,
+	settings.NonNegativeFloatWithMaximum(1),
+	settings.WithPublic)
+
+// HotKeysPerRange is the maximum number of hot keys reported for a range.
+const HotKeysPerRange = 10
+
+// recordBatchForHotKeys samples the batch's keys, along with the time the
+// batch spent waiting on latches and locks, for hot key tracking. Point
+// requests to a SQL row are recorded against the row, so that the load on the
+// row's column families is combined. Ranged requests are recorded against
+// their start key. The latch and lock waits are those of the whole batch,
+// which doesn't know which of its keys it waited on, so they are split evenly
+// between the keys rather than charged in full to every one of them.
+func (r *Replica) recordBatchForHotKeys(ba *kvpb.BatchRequest, latchWait, lockWait time.Duration) {
+	rate := HotKeysSampleRate.Get(&r.store.cfg.Settings.SV)
+	if rate <= 0 || rand.Float64() >= rate {
+		return
+	}
+	var numKeys int
+	for _, union := range ba.Requests {
+		if !keys.IsLocal(union.GetInner().Header().Key) {
+			numKeys++
+		}
+	}
+	if numKeys == 0 {
+		return
+	}
+	// Scale the sample up so that the recorded load estimates the load on
+	// the replica.
+	weight := 1 / rate
+	waitWeight := weight / float64(numKeys)
+	now := r.Clock().PhysicalTime()
+	for _, union := range ba.Requests {
+		req := union.GetInner()
+		h := req.Header()
+		if keys.IsLocal(h.Key) {
+			continue
+		}
+		key := h.Key
+		if len(h.EndKey) == 0 {
+			if rowKey, err := keys.EnsureSafeSplitKey(key); err == nil {
+				key = rowKey
+			}
+		}
+		sample := split.HotKeySample{
+			ContentionNanos: waitWeight * float64(lockWait.Nanoseconds()),
+			LatchWaitNanos:  waitWeight * float64(latchWait.Nanoseconds()),
+		}
+		if kvpb.IsReadOnly(req) {
+			sample.Reads = weight
+		} else {
+			sample.Writes = weight
+		}
+		r.hotKeys.Record(now, key, sample)
+	}
+}
+
+// HotKeys returns up to HotKeysPerRange of the replica's most frequently
+// accessed keys, ordered by accesses.
+func (r *Replica) HotKeys() []split.HotKey {
+	return r.hotKeys.TopK(r.Clock().PhysicalTime(), HotKeysPerRange)
+}
@@ -49,7 +49,8 @@ func loadInitializedReplicaForTesting(
 	if !desc.IsInitialized() {
 		return nil, errors.AssertionFailedf("can not load with uninitialized descriptor: %s", desc)
 	}
-	state, err := kvstorage.LoadReplicaState(ctx, store.TODOEngine(), store.StoreID(), desc, replicaID)
+	state, err := kvstorage.LoadReplicaState(
+		ctx, store.StateEngine(), store.LogEngine(), store.StoreID(), desc, replicaID)
 	if err != nil {
 		return nil, err
 	}
@@ -991,7 +991,7 @@ func (r *Replica) handleRaftReadyRaftMuLocked(
 			// ranges, so can be passed to LogStore methods instead of being stored in it.
 			s := logstore.LogStore{
 				RangeID:     r.RangeID,
-				Engine:      r.store.TODOEngine(),
+				Engine:      r.store.LogEngine(),
 				Sideload:    r.raftMu.sideloaded,
 				StateLoader: r.raftMu.stateLoader.StateLoader,
 				SyncWaiter:  r.store.syncWaiter,
@@ -2678,7 +2678,7 @@ func shouldCampaignAfterConfChange(
 		return false
 	}
 	if replDesc, ok := desc.GetReplicaDescriptorByID(roachpb.ReplicaID(raftStatus.Lead)); ok {
-		if replDesc.IsAnyVoter() {
+		if replDesc.IsAnyVoter() || replDesc.IsWitness() {
 			// The leader is still a voter in the descriptor.
 			return false
 		}
@@ -2712,7 +2712,7 @@ func (r *Replica) printRaftTail(
 	end := keys.RaftLogPrefix(r.RangeID).PrefixEnd()
 
 	// NB: raft log does not have intents.
-	it, err := r.store.TODOEngine().NewEngineIterator(storage.IterOptions{LowerBound: start, UpperBound: end})
+	it, err := r.store.LogEngine().NewEngineIterator(storage.IterOptions{LowerBound: start, UpperBound: end})
 	if err != nil {
 		return "", err
 	}
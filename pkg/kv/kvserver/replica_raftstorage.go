@@ -80,7 +80,7 @@ var _ raft.Storage = (*replicaRaftStorage)(nil)
 // exclusive access to r.mu.stateLoader.
 func (r *replicaRaftStorage) InitialState() (raftpb.HardState, raftpb.ConfState, error) {
 	ctx := r.AnnotateCtx(context.TODO())
-	hs, err := r.mu.stateLoader.LoadHardState(ctx, r.store.TODOEngine())
+	hs, err := r.mu.stateLoader.LoadHardState(ctx, r.store.LogEngine())
 	// For uninitialized ranges, membership is unknown at this point.
 	if raft.IsEmptyHardState(hs) || err != nil {
 		return raftpb.HardState{}, raftpb.ConfState{}, err
@@ -107,7 +107,7 @@ func (r *replicaRaftStorage) TypedEntries(
 	if r.raftMu.sideloaded == nil {
 		return nil, errors.New("sideloaded storage is uninitialized")
 	}
-	ents, _, loadedSize, err := logstore.LoadEntries(ctx, r.mu.stateLoader.StateLoader, r.store.TODOEngine(), r.RangeID,
+	ents, _, loadedSize, err := logstore.LoadEntries(ctx, r.mu.stateLoader.StateLoader, r.store.LogEngine(), r.RangeID,
 		r.store.raftEntryCache, r.raftMu.sideloaded, lo, hi, maxBytes)
 	r.store.metrics.RaftStorageReadBytes.Inc(int64(loadedSize))
 	return ents, err
@@ -140,7 +140,7 @@ func (r *replicaRaftStorage) TypedTerm(i kvpb.RaftIndex) (kvpb.RaftTerm, error)
 		return r.mu.lastTermNotDurable, nil
 	}
 	ctx := r.AnnotateCtx(context.TODO())
-	return logstore.LoadTerm(ctx, r.mu.stateLoader.StateLoader, r.store.TODOEngine(), r.RangeID,
+	return logstore.LoadTerm(ctx, r.mu.stateLoader.StateLoader, r.store.LogEngine(), r.RangeID,
 		r.store.raftEntryCache, i)
 }
 
@@ -530,7 +530,8 @@ func (r *Replica) applySnapshot(
 				// "applied by voters" here, since the LEARNER will soon be promoted to
 				// a voting replica.
 				case roachpb.VOTER_FULL, roachpb.VOTER_INCOMING, roachpb.VOTER_DEMOTING_LEARNER,
-					roachpb.VOTER_OUTGOING, roachpb.LEARNER, roachpb.VOTER_DEMOTING_NON_VOTER:
+					roachpb.VOTER_OUTGOING, roachpb.LEARNER, roachpb.VOTER_DEMOTING_NON_VOTER,
+					roachpb.WITNESS:
 					r.store.metrics.RangeSnapshotsAppliedByVoters.Inc(1)
 				case roachpb.NON_VOTER:
 					r.store.metrics.RangeSnapshotsAppliedByNonVoters.Inc(1)
@@ -682,10 +683,27 @@ func (r *Replica) applySnapshot(
 	// has not yet been updated. Any errors past this point must therefore be
 	// treated as fatal.
 
-	state, err := stateloader.Make(desc.RangeID).Load(ctx, r.store.TODOEngine(), desc)
+	// The SSTs wrote the raft state of the replica to the state engine. If the
+	// store keeps its raft logs in a separate engine, move it there, replacing
+	// the raft log that preceded the snapshot.
+	if r.store.separateRaftLogEngine() {
+		if err := kvstorage.MoveRaftState(
+			ctx, r.store.StateEngine(), r.store.LogEngine(), desc.RangeID,
+		); err != nil {
+			log.Fatalf(ctx, "unable to move raft state to raft log engine: %s", err)
+		}
+	}
+
+	sl := stateloader.Make(desc.RangeID)
+	state, err := sl.Load(ctx, r.store.StateEngine(), desc)
 	if err != nil {
 		log.Fatalf(ctx, "unable to load replica state: %s", err)
 	}
+	truncState, err := sl.LoadRaftTruncatedState(ctx, r.store.LogEngine())
+	if err != nil {
+		log.Fatalf(ctx, "unable to load truncated state: %s", err)
+	}
+	state.TruncatedState = &truncState
 
 	if uint64(state.RaftAppliedIndex) != nonemptySnap.Metadata.Index {
 		log.Fatalf(ctx, "snapshot RaftAppliedIndex %d doesn't match its metadata index %d",
@@ -801,7 +819,7 @@ func (r *Replica) applySnapshot(
 	// operation can be expensive. This is safe, as we hold the Replica.raftMu
 	// across both Replica.mu critical sections.
 	r.mu.RLock()
-	r.assertStateRaftMuLockedReplicaMuRLocked(ctx, r.store.TODOEngine())
+	r.assertStateRaftMuLockedReplicaMuRLocked(ctx, r.store.StateEngine())
 	r.mu.RUnlock()
 
 	// The rangefeed processor is listening for the logical ops attached to
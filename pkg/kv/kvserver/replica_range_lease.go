@@ -872,6 +872,14 @@ func (r *Replica) requestLeaseLocked(
 	if err != nil {
 		return r.mu.pendingLeaseRequest.newResolvedHandle(kvpb.NewError(err))
 	}
+	if repDesc.IsWitness() {
+		// A witness can't hold the lease, so don't bother proposing a request
+		// that is going to be rejected. Redirect the client to the other
+		// replicas instead.
+		return r.mu.pendingLeaseRequest.newResolvedHandle(kvpb.NewError(
+			kvpb.NewNotLeaseHolderError(status.Lease, r.store.StoreID(), r.mu.state.Desc,
+				"witness replicas cannot hold the lease")))
+	}
 	return r.mu.pendingLeaseRequest.InitOrJoinRequest(
 		ctx, repDesc, status, r.mu.state.Desc.StartKey.AsRawKey(),
 		false /* transfer */, false /* bypassSafetyChecks */, limiter)
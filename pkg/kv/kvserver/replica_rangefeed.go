@@ -212,6 +212,10 @@ func (r *Replica) RangeFeed(
 	if err != nil {
 		return future.MakeCompletedErrorFuture(err)
 	}
+	predicate, err := rangefeed.NewPredicate(args.Predicate)
+	if err != nil {
+		return future.MakeCompletedErrorFuture(err)
+	}
 
 	if err := r.ensureClosedTimestampStarted(ctx); err != nil {
 		return future.MakeCompletedErrorFuture(err.GoError())
@@ -286,7 +290,7 @@ func (r *Replica) RangeFeed(
 	}
 	var done future.ErrorFuture
 	p := r.registerWithRangefeedRaftMuLocked(
-		ctx, rSpan, args.Timestamp, catchUpIter, args.WithDiff, lockedStream, &done,
+		ctx, rSpan, args.Timestamp, catchUpIter, args.WithDiff, predicate, lockedStream, &done,
 	)
 	r.raftMu.Unlock()
 
@@ -382,6 +386,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	startTS hlc.Timestamp, // exclusive
 	catchUpIter *rangefeed.CatchUpIterator,
 	withDiff bool,
+	predicate *rangefeed.Predicate,
 	stream rangefeed.Stream,
 	done *future.ErrorFuture,
 ) rangefeed.Processor {
@@ -403,7 +408,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	p := r.rangefeedMu.proc
 
 	if p != nil {
-		reg, filter := p.Register(span, startTS, catchUpIter, withDiff, stream, func() { r.maybeDisconnectEmptyRangefeed(p) }, done)
+		reg, filter := p.Register(span, startTS, catchUpIter, withDiff, predicate, stream, func() { r.maybeDisconnectEmptyRangefeed(p) }, done)
 		if reg {
 			// Registered successfully with an existing processor.
 			// Update the rangefeed filter to avoid filtering ops
@@ -489,7 +494,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	// any other goroutines are able to stop the processor. In other words,
 	// this ensures that the only time the registration fails is during
 	// server shutdown.
-	reg, filter := p.Register(span, startTS, catchUpIter, withDiff, stream, func() { r.maybeDisconnectEmptyRangefeed(p) }, done)
+	reg, filter := p.Register(span, startTS, catchUpIter, withDiff, predicate, stream, func() { r.maybeDisconnectEmptyRangefeed(p) }, done)
 	if !reg {
 		select {
 		case <-r.store.Stopper().ShouldQuiesce():
@@ -591,6 +596,8 @@ func populatePrevValsInLogicalOpLog(
 			key, ts, prevValPtr = t.Key, t.Timestamp, &t.PrevValue
 		case *enginepb.MVCCCommitIntentOp:
 			key, ts, prevValPtr = t.Key, t.Timestamp, &t.PrevValue
+		case *enginepb.MVCCExpireValueOp:
+			key, ts, prevValPtr = t.Key, t.Timestamp, &t.PrevValue
 		case *enginepb.MVCCWriteIntentOp,
 			*enginepb.MVCCUpdateIntentOp,
 			*enginepb.MVCCAbortIntentOp,
@@ -669,7 +676,8 @@ func (r *Replica) handleLogicalOpLogRaftMuLocked(
 		case *enginepb.MVCCWriteIntentOp,
 			*enginepb.MVCCUpdateIntentOp,
 			*enginepb.MVCCAbortIntentOp,
-			*enginepb.MVCCAbortTxnOp:
+			*enginepb.MVCCAbortTxnOp,
+			*enginepb.MVCCExpireValueOp:
 			// Nothing to do.
 			continue
 		case *enginepb.MVCCDeleteRangeOp:
@@ -695,10 +703,6 @@ func (r *Replica) handleLogicalOpLogRaftMuLocked(
 		// by any rangefeed registration. We still need to inform the rangefeed
 		// processor of the changes to intents so that it can track unresolved
 		// intents, but we don't need to provide values.
-		//
-		// We could filter out MVCCWriteValueOp operations entirely at this
-		// point if they are not needed by any registration, but as long as we
-		// avoid the value lookup here, doing any more doesn't seem worth it.
 		if !filter.NeedVal(roachpb.Span{Key: key}) {
 			continue
 		}
@@ -723,8 +727,26 @@ func (r *Replica) handleLogicalOpLogRaftMuLocked(
 		*valPtr = valRes.Value.RawBytes
 	}
 
+	// Drop the values that no registration is interested in, so that they
+	// don't take up the memory budget of the rangefeed processor.
+	consumeOps := ops.Ops
+	var filtered bool
+	for i, op := range ops.Ops {
+		if filter.NeedValueOp(op) {
+			if filtered {
+				consumeOps = append(consumeOps, op)
+			}
+			continue
+		}
+		if !filtered {
+			consumeOps = append([]enginepb.MVCCLogicalOp(nil), ops.Ops[:i]...)
+			filtered = true
+		}
+		r.store.metrics.RangeFeedMetrics.RangeFeedEventsFiltered.Inc(1)
+	}
+
 	// Pass the ops to the rangefeed processor.
-	if !p.ConsumeLogicalOps(ctx, ops.Ops...) {
+	if !p.ConsumeLogicalOps(ctx, consumeOps...) {
 		// Consumption failed and the rangefeed was stopped.
 		r.unsetRangefeedProcessor(p)
 	}
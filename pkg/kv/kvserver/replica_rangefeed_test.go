@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
@@ -31,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
@@ -43,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
+	"github.com/gogo/protobuf/proto"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/raft/v3"
 	"go.etcd.io/raft/v3/raftpb"
@@ -1165,6 +1168,106 @@ func TestReplicaRangefeedMVCCHistoryMutationError(t *testing.T) {
 	}
 }
 
+// TestReplicaRangefeedExpiryAboveClosedTimestamp tests that the expiry of a
+// key under a storage TTL is published on a rangefeed above the range's
+// resolved timestamp, even if the closed timestamp of the range leads present
+// time and the GC request that expires the key is sent below it.
+func TestReplicaRangefeedExpiryAboveClosedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	zoneConfig := zonepb.DefaultZoneConfig()
+	zoneConfig.GlobalReads = proto.Bool(true)
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				DefaultZoneConfigOverride: &zoneConfig,
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(sqlDB)
+	tdb.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '20ms'`)
+	tdb.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.side_transport_interval = '20ms'`)
+	tdb.Exec(t, `SET CLUSTER SETTING kv.rangefeed.closed_timestamp_refresh_interval = '20ms'`)
+	store, err := s.GetStores().(*kvserver.Stores).GetStore(s.GetFirstStoreID())
+	require.NoError(t, err)
+
+	key := keys.SystemSQLCodec.TablePrefix(bootstrap.TestingUserDescID(0))
+	_, pErr := kv.SendWrapped(ctx, store.TestSender(), adminSplitArgs(key))
+	require.Nil(t, pErr)
+	repl := store.LookupReplica(roachpb.RKey(key))
+	testutils.SucceedsSoon(t, func() error {
+		if repl.ClosedTimestampPolicy() != roachpb.LEAD_FOR_GLOBAL_READS {
+			return errors.Errorf("expected LEAD_FOR_GLOBAL_READS policy")
+		}
+		return nil
+	})
+	_, pErr = kv.SendWrapped(ctx, store.TestSender(), putArgs(key, []byte("foo")))
+	require.Nil(t, pErr)
+
+	stream := newTestStream()
+	defer stream.Cancel()
+	streamErrC := make(chan error, 1)
+	desc := repl.Desc()
+	go func() {
+		req := kvpb.RangeFeedRequest{
+			Header: kvpb.Header{RangeID: desc.RangeID},
+			Span:   desc.RSpan().AsRawSpanWithNoLocals(),
+		}
+		streamErrC <- waitRangeFeed(store, &req, stream)
+	}()
+
+	// Wait for the resolved timestamp to lead present time.
+	var resolved hlc.Timestamp
+	testutils.SucceedsSoon(t, func() error {
+		if len(streamErrC) > 0 {
+			return errors.Wrap(<-streamErrC, "unexpected rangefeed error")
+		}
+		for _, event := range stream.Events() {
+			if event.Checkpoint != nil {
+				resolved.Forward(event.Checkpoint.ResolvedTS)
+			}
+		}
+		if !s.Clock().Now().Less(resolved) {
+			return errors.Errorf("resolved timestamp %s doesn't lead present time", resolved)
+		}
+		return nil
+	})
+
+	// Expire the key with a GC request at present time, below the closed
+	// timestamp. The key's newest version may have been written in the
+	// future, so every version of it is expired.
+	gcReq := &kvpb.GCRequest{
+		RequestHeader: kvpb.RequestHeader{
+			Key:    desc.StartKey.AsRawKey(),
+			EndKey: desc.EndKey.AsRawKey(),
+		},
+		ExpiredKeys: []kvpb.GCRequest_GCKey{{Key: key, Timestamp: hlc.MaxTimestamp}},
+	}
+	_, pErr = kv.SendWrappedWith(ctx, store.TestSender(), kvpb.Header{
+		RangeID:   desc.RangeID,
+		Timestamp: s.Clock().Now(),
+	}, gcReq)
+	require.Nil(t, pErr)
+
+	testutils.SucceedsSoon(t, func() error {
+		if len(streamErrC) > 0 {
+			return errors.Wrap(<-streamErrC, "unexpected rangefeed error")
+		}
+		for _, event := range stream.Events() {
+			if event.Val != nil && event.Val.Expired {
+				require.Equal(t, key, event.Val.Key)
+				require.True(t, resolved.Less(event.Val.Value.Timestamp),
+					"expiry at %s not above resolved timestamp %s", event.Val.Value.Timestamp, resolved)
+				return nil
+			}
+		}
+		return errors.New("expiry not published")
+	})
+}
+
 // TestReplicaRangefeedPushesTransactions tests that rangefeed detects intents
 // that are holding up its resolved timestamp and periodically pushes them to
 // ensure that its resolved timestamp continues to advance.
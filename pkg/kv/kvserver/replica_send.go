@@ -181,6 +181,7 @@ func (r *Replica) SendWithWriteBytes(
 	var writeBytes *kvadmission.StoreWriteBytes
 	if isReadOnly {
 		log.Event(ctx, "read-only path")
+		ctx = r.maybeConfirmReadIndex(ctx, ba)
 		fn := (*Replica).executeReadOnlyBatch
 		br, _, pErr = r.executeBatchWithConcurrencyRetries(ctx, ba, fn)
 	} else if ba.IsWrite() {
@@ -428,11 +429,17 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 	var lockSpans *lockspanset.LockSpanSet
 	var requestEvalKind concurrency.RequestEvalKind
 	var g *concurrency.Guard
+	// latchWait and lockWait accumulate the time spent waiting by guards which
+	// were released before retrying the batch.
+	var latchWait, lockWait time.Duration
 	defer func() {
 		// NB: wrapped to delay g evaluation to its value when returning.
 		if g != nil {
+			latchWait += g.LatchWait
+			lockWait += g.LockWait
 			r.concMgr.FinishReq(g)
 		}
+		r.recordBatchForHotKeys(ba, latchWait, lockWait)
 	}()
 	pp := poison.Policy_Error
 	if r.signallerForBatch(ba).C() == nil {
@@ -530,6 +537,8 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 				if reuseLatchAndLockSpans {
 					latchSpans, lockSpans = g.TakeSpanSets()
 				}
+				latchWait += g.LatchWait
+				lockWait += g.LockWait
 				r.concMgr.FinishReq(g)
 				g = nil
 			}
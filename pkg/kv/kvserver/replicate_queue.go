@@ -468,17 +468,21 @@ func (metrics *ReplicateQueueMetrics) trackSuccessByAllocatorAction(
 	ctx context.Context, action allocatorimpl.AllocatorAction,
 ) {
 	switch action {
-	case allocatorimpl.AllocatorRemoveVoter, allocatorimpl.AllocatorRemoveNonVoter:
+	case allocatorimpl.AllocatorRemoveVoter, allocatorimpl.AllocatorRemoveNonVoter,
+		allocatorimpl.AllocatorRemoveWitness:
 		metrics.RemoveReplicaSuccessCount.Inc(1)
-	case allocatorimpl.AllocatorAddVoter, allocatorimpl.AllocatorAddNonVoter:
+	case allocatorimpl.AllocatorAddVoter, allocatorimpl.AllocatorAddNonVoter,
+		allocatorimpl.AllocatorAddWitness:
 		metrics.AddReplicaSuccessCount.Inc(1)
 	case allocatorimpl.AllocatorReplaceDeadVoter, allocatorimpl.AllocatorReplaceDeadNonVoter:
 		metrics.ReplaceDeadReplicaSuccessCount.Inc(1)
-	case allocatorimpl.AllocatorRemoveDeadVoter, allocatorimpl.AllocatorRemoveDeadNonVoter:
+	case allocatorimpl.AllocatorRemoveDeadVoter, allocatorimpl.AllocatorRemoveDeadNonVoter,
+		allocatorimpl.AllocatorRemoveDeadWitness:
 		metrics.RemoveDeadReplicaSuccessCount.Inc(1)
 	case allocatorimpl.AllocatorReplaceDecommissioningVoter, allocatorimpl.AllocatorReplaceDecommissioningNonVoter:
 		metrics.ReplaceDecommissioningReplicaSuccessCount.Inc(1)
-	case allocatorimpl.AllocatorRemoveDecommissioningVoter, allocatorimpl.AllocatorRemoveDecommissioningNonVoter:
+	case allocatorimpl.AllocatorRemoveDecommissioningVoter, allocatorimpl.AllocatorRemoveDecommissioningNonVoter,
+		allocatorimpl.AllocatorRemoveDecommissioningWitness:
 		metrics.RemoveDecommissioningReplicaSuccessCount.Inc(1)
 	case allocatorimpl.AllocatorConsiderRebalance, allocatorimpl.AllocatorNoop,
 		allocatorimpl.AllocatorRangeUnavailable, allocatorimpl.AllocatorRemoveLearner,
@@ -496,17 +500,21 @@ func (metrics *ReplicateQueueMetrics) trackErrorByAllocatorAction(
 	ctx context.Context, action allocatorimpl.AllocatorAction,
 ) {
 	switch action {
-	case allocatorimpl.AllocatorRemoveVoter, allocatorimpl.AllocatorRemoveNonVoter:
+	case allocatorimpl.AllocatorRemoveVoter, allocatorimpl.AllocatorRemoveNonVoter,
+		allocatorimpl.AllocatorRemoveWitness:
 		metrics.RemoveReplicaErrorCount.Inc(1)
-	case allocatorimpl.AllocatorAddVoter, allocatorimpl.AllocatorAddNonVoter:
+	case allocatorimpl.AllocatorAddVoter, allocatorimpl.AllocatorAddNonVoter,
+		allocatorimpl.AllocatorAddWitness:
 		metrics.AddReplicaErrorCount.Inc(1)
 	case allocatorimpl.AllocatorReplaceDeadVoter, allocatorimpl.AllocatorReplaceDeadNonVoter:
 		metrics.ReplaceDeadReplicaErrorCount.Inc(1)
-	case allocatorimpl.AllocatorRemoveDeadVoter, allocatorimpl.AllocatorRemoveDeadNonVoter:
+	case allocatorimpl.AllocatorRemoveDeadVoter, allocatorimpl.AllocatorRemoveDeadNonVoter,
+		allocatorimpl.AllocatorRemoveDeadWitness:
 		metrics.RemoveDeadReplicaErrorCount.Inc(1)
 	case allocatorimpl.AllocatorReplaceDecommissioningVoter, allocatorimpl.AllocatorReplaceDecommissioningNonVoter:
 		metrics.ReplaceDecommissioningReplicaErrorCount.Inc(1)
-	case allocatorimpl.AllocatorRemoveDecommissioningVoter, allocatorimpl.AllocatorRemoveDecommissioningNonVoter:
+	case allocatorimpl.AllocatorRemoveDecommissioningVoter, allocatorimpl.AllocatorRemoveDecommissioningNonVoter,
+		allocatorimpl.AllocatorRemoveDecommissioningWitness:
 		metrics.RemoveDecommissioningReplicaErrorCount.Inc(1)
 	case allocatorimpl.AllocatorConsiderRebalance, allocatorimpl.AllocatorNoop,
 		allocatorimpl.AllocatorRangeUnavailable, allocatorimpl.AllocatorRemoveLearner,
@@ -981,6 +989,7 @@ func maybeAnnotateDecommissionErr(err error, action allocatorimpl.AllocatorActio
 func isDecommissionAction(action allocatorimpl.AllocatorAction) bool {
 	return action == allocatorimpl.AllocatorRemoveDecommissioningVoter ||
 		action == allocatorimpl.AllocatorRemoveDecommissioningNonVoter ||
+		action == allocatorimpl.AllocatorRemoveDecommissioningWitness ||
 		action == allocatorimpl.AllocatorReplaceDecommissioningVoter ||
 		action == allocatorimpl.AllocatorReplaceDecommissioningNonVoter
 }
@@ -0,0 +1,189 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package split
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// hotKeysCapacity is the number of keys tracked by HotKeys. It is larger than
+// the number of keys which are reported, so that the reported keys are
+// unlikely to be evicted by a burst of accesses to other keys.
+const hotKeysCapacity = 64
+
+// hotKeysWindow is the duration over which accesses are aggregated, before
+// the tracked keys are reset.
+const hotKeysWindow = time.Minute
+
+// HotKeySample is the (scaled) load recorded against a key by a sampled
+// request.
+type HotKeySample struct {
+	Reads, Writes float64
+	// ContentionNanos is the time the request spent waiting in lock
+	// wait-queues.
+	ContentionNanos float64
+	// LatchWaitNanos is the time the request spent waiting to acquire latches.
+	LatchWaitNanos float64
+}
+
+// HotKey is the estimated per-second load on a key.
+type HotKey struct {
+	Key                      roachpb.Key
+	ReadsPerSecond           float64
+	WritesPerSecond          float64
+	ContentionNanosPerSecond float64
+	LatchWaitNanosPerSecond  float64
+}
+
+type hotKeyEntry struct {
+	key    roachpb.Key
+	sample HotKeySample
+	// count is the estimated number of accesses to the key. When an entry is
+	// evicted to make room for a new key, the new key inherits the count of
+	// the evicted key, which overestimates the accesses to the new key by at
+	// most that count.
+	count float64
+}
+
+// HotKeys tracks the most frequently accessed keys of a replica. It
+// implements the space-saving algorithm: a bounded number of keys are
+// tracked, when a key which isn't tracked is recorded, the least accessed
+// key is replaced. Any key accessed more often than 1/hotKeysCapacity of the
+// time is guaranteed to be tracked.
+//
+// The accesses are aggregated over a window of hotKeysWindow, the keys of the
+// last complete window are reported. The zero value is ready to use.
+type HotKeys struct {
+	mu struct {
+		syncutil.Mutex
+		start   time.Time
+		entries map[string]*hotKeyEntry
+		// last is the hottest keys of the previous window, ordered by
+		// accesses.
+		last []HotKey
+	}
+}
+
+// Record records a sampled access to the key. The key is copied if it is
+// tracked, since it usually aliases the memory of a request.
+func (h *HotKeys) Record(now time.Time, key roachpb.Key, sample HotKeySample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maybeRotateLocked(now)
+
+	if e, ok := h.mu.entries[string(key)]; ok {
+		e.add(sample)
+		return
+	}
+	e := &hotKeyEntry{}
+	if len(h.mu.entries) >= hotKeysCapacity {
+		var min *hotKeyEntry
+		for _, other := range h.mu.entries {
+			if min == nil || other.count < min.count {
+				min = other
+			}
+		}
+		delete(h.mu.entries, string(min.key))
+		e.count = min.count
+	}
+	e.key = append(roachpb.Key(nil), key...)
+	e.add(sample)
+	h.mu.entries[string(e.key)] = e
+}
+
+func (e *hotKeyEntry) add(sample HotKeySample) {
+	e.sample.Reads += sample.Reads
+	e.sample.Writes += sample.Writes
+	e.sample.ContentionNanos += sample.ContentionNanos
+	e.sample.LatchWaitNanos += sample.LatchWaitNanos
+	e.count += sample.Reads + sample.Writes
+}
+
+// TopK returns up to k of the hottest keys, ordered by accesses. The keys of
+// the last complete window are returned, or the keys of the current window
+// when no window has completed yet.
+func (h *HotKeys) TopK(now time.Time, k int) []HotKey {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maybeRotateLocked(now)
+
+	hot := h.mu.last
+	if hot == nil {
+		hot = h.hottestLocked(now.Sub(h.mu.start))
+	}
+	if len(hot) > k {
+		hot = hot[:k]
+	}
+	return append([]HotKey(nil), hot...)
+}
+
+// Reset forgets all recorded accesses.
+func (h *HotKeys) Reset(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mu.start = now
+	h.mu.entries = make(map[string]*hotKeyEntry, hotKeysCapacity)
+	h.mu.last = nil
+}
+
+// maybeRotateLocked starts a new window when the current window has
+// completed. The previous window is retained only when it directly precedes
+// the new one, so that a replica which has gone idle doesn't report stale
+// keys.
+func (h *HotKeys) maybeRotateLocked(now time.Time) {
+	if h.mu.entries == nil {
+		h.mu.start = now
+		h.mu.entries = make(map[string]*hotKeyEntry, hotKeysCapacity)
+		return
+	}
+	elapsed := now.Sub(h.mu.start)
+	if elapsed < hotKeysWindow {
+		return
+	}
+	h.mu.last = nil
+	if elapsed < 2*hotKeysWindow {
+		h.mu.last = h.hottestLocked(elapsed)
+	}
+	h.mu.start = now
+	h.mu.entries = make(map[string]*hotKeyEntry, hotKeysCapacity)
+}
+
+func (h *HotKeys) hottestLocked(elapsed time.Duration) []HotKey {
+	if elapsed < minPerSecondSampleDuration {
+		elapsed = minPerSecondSampleDuration
+	}
+	entries := make([]*hotKeyEntry, 0, len(h.mu.entries))
+	for _, e := range h.mu.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].key.Compare(entries[j].key) < 0
+	})
+	secs := elapsed.Seconds()
+	hot := make([]HotKey, len(entries))
+	for i, e := range entries {
+		hot[i] = HotKey{
+			Key:                      e.key,
+			ReadsPerSecond:           e.sample.Reads / secs,
+			WritesPerSecond:          e.sample.Writes / secs,
+			ContentionNanosPerSecond: e.sample.ContentionNanos / secs,
+			LatchWaitNanosPerSecond:  e.sample.LatchWaitNanos / secs,
+		}
+	}
+	return hot
+}
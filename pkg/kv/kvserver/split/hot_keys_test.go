@@ -0,0 +1,78 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package split
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHotKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var h HotKeys
+	start := time.Unix(0, 0)
+	h.Reset(start)
+
+	// A single hot key is accessed once every 10 accesses, the remaining
+	// accesses are to distinct keys, many more than can be tracked.
+	now := start
+	for i := 0; i < 10000; i++ {
+		now = now.Add(time.Millisecond)
+		if i%10 == 0 {
+			h.Record(now, roachpb.Key("hot"), HotKeySample{
+				Writes:          1,
+				ContentionNanos: 1e3,
+				LatchWaitNanos:  1e2,
+			})
+			continue
+		}
+		h.Record(now, roachpb.Key(fmt.Sprintf("cold-%d", i)), HotKeySample{Reads: 1})
+	}
+
+	// The current window is reported until it completes.
+	hot := h.TopK(now, 3)
+	require.Len(t, hot, 3)
+	require.Equal(t, roachpb.Key("hot"), hot[0].Key)
+	require.InDelta(t, 100, hot[0].WritesPerSecond, 1)
+	require.Zero(t, hot[0].ReadsPerSecond)
+	require.InDelta(t, 1e5, hot[0].ContentionNanosPerSecond, 1e3)
+	require.InDelta(t, 1e4, hot[0].LatchWaitNanosPerSecond, 1e2)
+
+	// Once the window completes, it continues to be reported while the next
+	// window is filled.
+	now = start.Add(hotKeysWindow)
+	h.Record(now, roachpb.Key("next"), HotKeySample{Reads: 1})
+	hot = h.TopK(now.Add(time.Second), 1)
+	require.Len(t, hot, 1)
+	require.Equal(t, roachpb.Key("hot"), hot[0].Key)
+	require.InDelta(t, 1000/hotKeysWindow.Seconds(), hot[0].WritesPerSecond, 1)
+
+	// A replica which goes idle for longer than a window no longer reports
+	// the keys.
+	require.Empty(t, h.TopK(now.Add(3*hotKeysWindow), 1))
+
+	h.Reset(now)
+	require.Empty(t, h.TopK(now, 1))
+
+	// The recorded key doesn't alias the caller's key.
+	key := roachpb.Key("key")
+	h.Record(now, key, HotKeySample{Reads: 1})
+	key[0] = 'x'
+	hot = h.TopK(now, 1)
+	require.Len(t, hot, 1)
+	require.Equal(t, roachpb.Key("key"), hot[0].Key)
+}
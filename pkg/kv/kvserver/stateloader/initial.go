@@ -120,10 +120,10 @@ func WriteInitialRangeState(
 		return err
 	}
 
-	// TODO(sep-raft-log): when the log storage is separated, the below can't be
-	// written in the same batch. Figure out the ordering required here.
+	// NB: with a separate raft log engine, the raft state written below is
+	// moved to it when the store starts, see kvstorage.ReconcileRaftLogEngine.
 	sl := Make(desc.RangeID)
-	if err := sl.SynthesizeRaftState(ctx, readWriter); err != nil {
+	if err := sl.SynthesizeRaftState(ctx, readWriter, readWriter); err != nil {
 		return err
 	}
 	// Maintain the invariant that any replica (uninitialized or initialized),
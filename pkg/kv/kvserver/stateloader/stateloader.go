@@ -378,11 +378,13 @@ func UninitializedReplicaState(rangeID roachpb.RangeID) kvserverpb.ReplicaState
 // SynthesizeRaftState creates a Raft state which synthesizes both a HardState
 // and a lastIndex from pre-seeded data in the engine (typically created via
 // WriteInitialReplicaState and, on a split, perhaps the activity of an
-// uninitialized Raft group)
+// uninitialized Raft group). The existing HardState is read from logReader,
+// which differs from readWriter if the store keeps its raft logs in a separate
+// engine.
 func (rsl StateLoader) SynthesizeRaftState(
-	ctx context.Context, readWriter storage.ReadWriter,
+	ctx context.Context, readWriter storage.ReadWriter, logReader storage.Reader,
 ) error {
-	hs, err := rsl.LoadHardState(ctx, readWriter)
+	hs, err := rsl.LoadHardState(ctx, logReader)
 	if err != nil {
 		return err
 	}
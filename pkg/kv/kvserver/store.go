@@ -543,10 +543,9 @@ func (rs *storeReplicaVisitor) EstimatedCount() int {
 }
 
 // internalEngines contains the engines that support the operations of
-// this Store. At the time of writing, all three fields will be populated
-// with the same Engine. As work on CRDB-220 (separate raft log) proceeds,
-// we will be able to experimentally run with a separate log engine, and
-// ultimately allow doing so in production deployments.
+// this Store. Unless the store is configured with a separate raft log engine
+// (see StoreConfig.RaftLogEngines), all three fields are populated with the
+// same Engine. The todoEngine is always the state engine.
 type internalEngines struct {
 	// stateEngine is the engine that materializes the raft logs on the system.
 	stateEngine storage.Engine
@@ -1226,6 +1225,11 @@ type StoreConfig struct {
 	// shared.Storage instance and can accept shared snapshots.
 	SharedStorageEnabled bool
 
+	// RaftLogEngines maps the engine of a store to the separate engine that
+	// holds its raft log, for the stores that are configured with one. See
+	// kvstorage.ReconcileRaftLogEngine.
+	RaftLogEngines map[storage.Engine]storage.Engine
+
 	// KVAdmissionController is used for admission control.
 	KVAdmissionController kvadmission.Controller
 	// KVFlowController is used for replication admission control.
@@ -1383,6 +1387,10 @@ func NewStore(
 	}
 	iot := ioThresholds{}
 	iot.Replace(nil, 1.0) // init as empty
+	logEngine := eng
+	if e, ok := cfg.RaftLogEngines[eng]; ok {
+		logEngine = e
+	}
 	s := &Store{
 		// NB: do not access these fields directly. Instead, use
 		// the StateEngine, TODOEngine, LogEngine methods.
@@ -1391,7 +1399,7 @@ func NewStore(
 		internalEngines: internalEngines{
 			stateEngine: eng,
 			todoEngine:  eng,
-			logEngine:   eng,
+			logEngine:   logEngine,
 		},
 		cfg:                               cfg,
 		db:                                cfg.DB, // TODO(tschottdorf): remove redundancy.
@@ -2005,11 +2013,15 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 	ctx = s.AnnotateCtx(ctx)
 	log.Event(ctx, "read store identity")
 
-	// Communicate store ID to engine.
-	// TODO(sep-raft-log): do for both engines.
-	if err := s.TODOEngine().SetStoreID(ctx, int32(s.StoreID())); err != nil {
+	// Communicate store ID to engines.
+	if err := s.StateEngine().SetStoreID(ctx, int32(s.StoreID())); err != nil {
 		return err
 	}
+	if s.separateRaftLogEngine() {
+		if err := s.LogEngine().SetStoreID(ctx, int32(s.StoreID())); err != nil {
+			return err
+		}
+	}
 
 	{
 		m := rangefeed.NewSchedulerMetrics(s.cfg.HistogramWindowInterval)
@@ -2088,7 +2100,7 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 	{
 		truncator := s.raftTruncator
 		// When state machine has persisted new RaftAppliedIndex, fire callback.
-		s.TODOEngine().RegisterFlushCompletedCallback(func() {
+		s.StateEngine().RegisterFlushCompletedCallback(func() {
 			truncator.durabilityAdvancedCallback()
 		})
 	}
@@ -2115,9 +2127,15 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 	// concurrently, all initialization must be performed before we start
 	// listening for Raft messages and starting the process Raft loop.
 	//
-	// TODO(sep-raft-log): this will need to learn to stitch and reconcile data from
-	// both engines.
-	repls, err := kvstorage.LoadAndReconcileReplicas(ctx, s.TODOEngine())
+	// Before that, bring the raft state in the log engine in line with the
+	// state engine. This migrates the raft state of a store that is started
+	// with a separate raft log engine for the first time, and repairs the raft
+	// state of replicas whose writes to the two engines were interrupted by a
+	// crash.
+	if err := kvstorage.ReconcileRaftLogEngine(ctx, s.StateEngine(), s.LogEngine()); err != nil {
+		return err
+	}
+	repls, err := kvstorage.LoadAndReconcileReplicas(ctx, s.StateEngine(), s.LogEngine())
 	if err != nil {
 		return err
 	}
@@ -2127,7 +2145,7 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 			continue
 		}
 		// TODO(pavelkalinnikov): integrate into kvstorage.LoadAndReconcileReplicas.
-		state, err := repl.Load(ctx, s.TODOEngine(), s.StoreID())
+		state, err := repl.Load(ctx, s.StateEngine(), s.LogEngine(), s.StoreID())
 		if err != nil {
 			return err
 		}
@@ -2785,6 +2803,14 @@ func (s *Store) LogEngine() storage.Engine {
 	return s.internalEngines.logEngine
 }
 
+// separateRaftLogEngine returns whether the raft log of this store is kept in
+// an engine other than the state engine. When it is, the raft state of a
+// replica can't be written atomically with its applied state, which the
+// callers must account for.
+func (s *Store) separateRaftLogEngine() bool {
+	return s.internalEngines.logEngine != s.internalEngines.stateEngine
+}
+
 // DB accessor.
 func (s *Store) DB() *kv.DB { return s.cfg.DB }
 
@@ -3809,10 +3835,12 @@ func (s *storeForTruncatorImpl) releaseReplicaForTruncator(r replicaForTruncator
 	replica.raftMu.Unlock()
 }
 
-func (s *storeForTruncatorImpl) getEngine() storage.Engine {
-	// TODO(sep-raft-log): we'll need the log engine here but need
-	// to read code to see if more needs to be done.
-	return (*Store)(s).TODOEngine()
+func (s *storeForTruncatorImpl) getStateEngine() storage.Engine {
+	return (*Store)(s).StateEngine()
+}
+
+func (s *storeForTruncatorImpl) getLogEngine() storage.Engine {
+	return (*Store)(s).LogEngine()
 }
 
 func init() {
@@ -209,9 +209,7 @@ func (s *Store) tryGetOrCreateReplica(
 	// Replica for this rangeID, and that's us.
 
 	if err := kvstorage.CreateUninitializedReplica(
-		// TODO(sep-raft-log): needs both engines due to tombstone (which lives on
-		// statemachine).
-		ctx, s.TODOEngine(), s.StoreID(), rangeID, replicaID,
+		ctx, s.StateEngine(), s.LogEngine(), s.StoreID(), rangeID, replicaID,
 	); err != nil {
 		return nil, false, err
 	}
@@ -774,6 +774,13 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 	if sharedReplicate {
 		replicatedFilter = rditer.ReplicatedSpansExcludeUser
 	}
+	// Witnesses only keep the range-local and RangeID-local state of the
+	// replica, so don't send them any user keys.
+	if recipient, ok := header.State.Desc.GetReplicaDescriptorByID(
+		header.RaftMessageRequest.ToReplica.ReplicaID,
+	); ok && recipient.IsWitness() {
+		replicatedFilter = rditer.ReplicatedSpansExcludeUser
+	}
 
 	iterateRKSpansVisitor := func(iter storage.EngineIterator, _ roachpb.Span, keyType storage.IterKeyType) error {
 		timingTag.start("iter")
@@ -77,8 +77,10 @@ func splitPreApply(
 		// going to be extra careful in case future versions of cockroach somehow
 		// promote replicas without ensuring that a snapshot has been received. So
 		// we write it back (and the RaftReplicaID too, since it's an invariant that
-		// it's always present).
+		// it's always present). If the store keeps its raft logs in a separate
+		// engine, the HardState is not in readWriter and is left alone.
 		var hs raftpb.HardState
+		separateLogEngine := r.store.separateRaftLogEngine()
 		if rightRepl != nil {
 			rightRepl.raftMu.Lock()
 			defer rightRepl.raftMu.Unlock()
@@ -88,10 +90,12 @@ func splitPreApply(
 			if rightRepl.IsInitialized() {
 				log.Fatalf(ctx, "unexpectedly found initialized newer RHS of split: %v", rightRepl.Desc())
 			}
-			var err error
-			hs, err = rightRepl.raftMu.stateLoader.LoadHardState(ctx, readWriter)
-			if err != nil {
-				log.Fatalf(ctx, "failed to load hard state for removed rhs: %v", err)
+			if !separateLogEngine {
+				var err error
+				hs, err = rightRepl.raftMu.stateLoader.LoadHardState(ctx, readWriter)
+				if err != nil {
+					log.Fatalf(ctx, "failed to load hard state for removed rhs: %v", err)
+				}
 			}
 		}
 		if err := kvstorage.ClearRangeData(split.RightDesc.RangeID, readWriter, readWriter, kvstorage.ClearRangeDataOptions{
@@ -124,8 +128,10 @@ func splitPreApply(
 			// Cleared the HardState and RaftReplicaID, so rewrite them to the current
 			// values. NB: rightRepl.raftMu is still locked since HardState was read,
 			// so it can't have been rewritten in the meantime (fixed in #75918).
-			if err := rightRepl.raftMu.stateLoader.SetHardState(ctx, readWriter, hs); err != nil {
-				log.Fatalf(ctx, "failed to set hard state with 0 commit index for removed rhs: %v", err)
+			if !separateLogEngine {
+				if err := rightRepl.raftMu.stateLoader.SetHardState(ctx, readWriter, hs); err != nil {
+					log.Fatalf(ctx, "failed to set hard state with 0 commit index for removed rhs: %v", err)
+				}
 			}
 			if err := rightRepl.raftMu.stateLoader.SetRaftReplicaID(
 				ctx, readWriter, rightRepl.ReplicaID()); err != nil {
@@ -137,9 +143,18 @@ func splitPreApply(
 
 	// Update the raft HardState with the new Commit value now that the
 	// replica is initialized (combining it with existing or default
-	// Term and Vote). This is the common case.
+	// Term and Vote). This is the common case. The existing HardState of the
+	// uninitialized RHS is in the log engine, which is readWriter unless the
+	// store keeps its raft logs in a separate engine. In that case, the RHS
+	// raftMu, acquired in Replica.acquireSplitLock, keeps it from changing
+	// until the raft state written here is moved to the log engine in
+	// prepareRightReplicaForSplit.
 	rsl := stateloader.Make(split.RightDesc.RangeID)
-	if err := rsl.SynthesizeRaftState(ctx, readWriter); err != nil {
+	var logReader storage.Reader = readWriter
+	if r.store.separateRaftLogEngine() {
+		logReader = r.store.LogEngine()
+	}
+	if err := rsl.SynthesizeRaftState(ctx, readWriter, logReader); err != nil {
 		log.Fatalf(ctx, "%v", err)
 	}
 	// Write the RaftReplicaID for the RHS to maintain the invariant that any
@@ -244,8 +259,19 @@ func prepareRightReplicaForSplit(
 	}
 	// Finish initialization of the RHS replica.
 
+	// The split trigger wrote the raft state of the RHS to the state engine,
+	// atomically with its applied state. If the store keeps its raft logs in a
+	// separate engine, move it there before loading it. The RHS raftMu is held,
+	// so nothing else writes its raft state concurrently.
+	if r.store.separateRaftLogEngine() {
+		if err := kvstorage.MoveRaftState(
+			ctx, r.store.StateEngine(), r.store.LogEngine(), split.RightDesc.RangeID,
+		); err != nil {
+			log.Fatalf(ctx, "%v", err)
+		}
+	}
 	state, err := kvstorage.LoadReplicaState(
-		ctx, r.store.TODOEngine(), r.StoreID(), &split.RightDesc, rightRepl.replicaID)
+		ctx, r.store.StateEngine(), r.store.LogEngine(), r.StoreID(), &split.RightDesc, rightRepl.replicaID)
 	if err != nil {
 		log.Fatalf(ctx, "%v", err)
 	}
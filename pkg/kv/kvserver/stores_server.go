@@ -211,3 +211,37 @@ func (is Server) SetCompactionConcurrency(
 		})
 	return resp, err
 }
+
+// ReEncryptEngine implements PerStoreServer. It rewrites the files of the
+// store's engine which are encrypted under a retired store key, streaming its
+// progress after each file.
+func (is Server) ReEncryptEngine(
+	req *ReEncryptEngineRequest, stream PerStore_ReEncryptEngineServer,
+) error {
+	return is.execStoreCommand(stream.Context(), req.StoreRequestHeader,
+		func(ctx context.Context, s *Store) error {
+			ctx, cancel := s.stopper.WithCancelOnQuiesce(ctx)
+			defer cancel()
+			var sendErr error
+			err := s.TODOEngine().ReEncrypt(ctx, func(p storage.ReEncryptionProgress) {
+				if sendErr != nil {
+					return
+				}
+				sendErr = stream.Send(&ReEncryptEngineResponse{
+					TotalFiles:     p.TotalFiles,
+					TotalBytes:     p.TotalBytes,
+					RewrittenFiles: p.RewrittenFiles,
+					RewrittenBytes: p.RewrittenBytes,
+				})
+				if sendErr != nil {
+					// Stop re-encrypting once the client can no longer be
+					// informed of the progress.
+					cancel()
+				}
+			})
+			if sendErr != nil {
+				return sendErr
+			}
+			return err
+		})
+}
@@ -677,6 +677,12 @@ func (ts *testState) HasProcessDebugCapability(ctx context.Context, tenID roachp
 	}
 }
 
+func (ts *testState) HasCrossTenantRead(
+	ctx context.Context, tenID roachpb.TenantID,
+) (roachpb.TenantID, bool) {
+	return roachpb.TenantID{}, false
+}
+
 func (ts *testState) HasNodeStatusCapability(_ context.Context, tenID roachpb.TenantID) error {
 	if ts.capabilities[tenID].CanViewNodeInfo {
 		return nil
@@ -803,3 +809,8 @@ func (fakeAuthorizer) BindReader(tenantcapabilities.Reader) {}
 func (fakeAuthorizer) HasProcessDebugCapability(ctx context.Context, tenID roachpb.TenantID) error {
 	return nil
 }
+func (fakeAuthorizer) HasCrossTenantRead(
+	ctx context.Context, tenID roachpb.TenantID,
+) (roachpb.TenantID, bool) {
+	return roachpb.TenantID{}, false
+}
@@ -75,6 +75,11 @@ type Authorizer interface {
 	// HasProcessDebugCapability returns an error if a tenant, referenced by its ID,
 	// is not allowed to debug the running process.
 	HasProcessDebugCapability(ctx context.Context, tenID roachpb.TenantID) error
+
+	// HasCrossTenantRead returns the ID of the tenant whose keyspace a tenant,
+	// referenced by its ID, is allowed to read from in addition to its own,
+	// and false if there is no such tenant.
+	HasCrossTenantRead(ctx context.Context, tenID roachpb.TenantID) (roachpb.TenantID, bool)
 }
 
 // Entry ties together a tenantID with its capabilities.
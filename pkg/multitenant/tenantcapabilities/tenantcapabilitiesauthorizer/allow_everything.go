@@ -73,3 +73,10 @@ func (n *AllowEverythingAuthorizer) HasProcessDebugCapability(
 ) error {
 	return nil
 }
+
+// HasCrossTenantRead implements the tenantcapabilities.Authorizer interface.
+func (n *AllowEverythingAuthorizer) HasCrossTenantRead(
+	ctx context.Context, tenID roachpb.TenantID,
+) (roachpb.TenantID, bool) {
+	return roachpb.TenantID{}, false
+}
@@ -72,3 +72,10 @@ func (n *AllowNothingAuthorizer) HasProcessDebugCapability(
 ) error {
 	return errors.New("operation blocked")
 }
+
+// HasCrossTenantRead implements the tenantcapabilities.Authorizer interface.
+func (n *AllowNothingAuthorizer) HasCrossTenantRead(
+	ctx context.Context, tenID roachpb.TenantID,
+) (roachpb.TenantID, bool) {
+	return roachpb.TenantID{}, false
+}
@@ -217,6 +217,7 @@ var reqMethodToCap = map[kvpb.Method]tenantcapabilities.ID{
 	kvpb.Migrate:                       onlySystemTenant,
 	kvpb.Probe:                         onlySystemTenant,
 	kvpb.QueryResolvedTimestamp:        onlySystemTenant,
+	kvpb.ReadIndex:                     onlySystemTenant,
 	kvpb.RecomputeStats:                onlySystemTenant,
 	kvpb.RequestLease:                  onlySystemTenant,
 	kvpb.Subsume:                       onlySystemTenant,
@@ -349,6 +350,33 @@ func (a *Authorizer) IsExemptFromRateLimiting(ctx context.Context, tenID roachpb
 	return tenantcapabilities.MustGetBoolByID(cp, tenantcapabilities.ExemptFromRateLimiting)
 }
 
+// HasCrossTenantRead implements the tenantcapabilities.Authorizer interface.
+func (a *Authorizer) HasCrossTenantRead(
+	ctx context.Context, tenID roachpb.TenantID,
+) (roachpb.TenantID, bool) {
+	if tenID.IsSystem() {
+		// The system tenant can read everything already.
+		return roachpb.TenantID{}, false
+	}
+	cp, mode := a.getMode(ctx, tenID)
+	switch mode {
+	case authorizerModeOn:
+		break // fallthrough to the next check.
+	case authorizerModeAllowAll, authorizerModeV222:
+		// Cross-tenant reads are granted to a specific tenant, which is only
+		// known through the capabilities.
+		return roachpb.TenantID{}, false
+	default:
+		err := errors.AssertionFailedf("unknown authorizer mode: %d", mode)
+		logcrash.ReportOrPanic(ctx, &a.settings.SV, "%v", err)
+		return roachpb.TenantID{}, false
+	}
+	if cp.ReadFromTenant == nil || !cp.ReadFromTenant.IsSet() {
+		return roachpb.TenantID{}, false
+	}
+	return *cp.ReadFromTenant, true
+}
+
 // getMode retrieves the authorization mode.
 func (a *Authorizer) getMode(
 	ctx context.Context, tid roachpb.TenantID,
@@ -89,6 +89,10 @@ func predNonVoter(rDesc ReplicaDescriptor) bool {
 	return rDesc.Type == NON_VOTER
 }
 
+func predWitness(rDesc ReplicaDescriptor) bool {
+	return rDesc.Type == WITNESS
+}
+
 func predVoterOrNonVoter(rDesc ReplicaDescriptor) bool {
 	return predVoterFullOrIncoming(rDesc) || predNonVoter(rDesc)
 }
@@ -226,6 +230,19 @@ func (d ReplicaSet) NonVoterDescriptors() []ReplicaDescriptor {
 	return d.FilterToDescriptors(predNonVoter)
 }
 
+// Witnesses returns a ReplicaSet containing only the witnesses in `d`.
+// Witnesses are raft voters, but they are not returned by Voters() since they
+// don't apply user writes and can neither hold the lease nor serve reads. They
+// only count towards the quorum(s) of the range.
+func (d ReplicaSet) Witnesses() ReplicaSet {
+	return d.Filter(predWitness)
+}
+
+// WitnessDescriptors returns the witness replica descriptors in the set.
+func (d ReplicaSet) WitnessDescriptors() []ReplicaDescriptor {
+	return d.FilterToDescriptors(predWitness)
+}
+
 // VoterFullAndNonVoterDescriptors returns the descriptors of
 // VOTER_FULL/NON_VOTER replicas in the set. This set will not contain learners
 // or, during an atomic replication change, incoming or outgoing voters.
@@ -322,7 +339,7 @@ func (d ReplicaSet) InAtomicReplicationChange() bool {
 		case VOTER_INCOMING, VOTER_OUTGOING, VOTER_DEMOTING_LEARNER,
 			VOTER_DEMOTING_NON_VOTER:
 			return true
-		case VOTER_FULL, LEARNER, NON_VOTER:
+		case VOTER_FULL, LEARNER, NON_VOTER, WITNESS:
 		default:
 			panic(fmt.Sprintf("unknown replica type %d", rDesc.Type))
 		}
@@ -340,7 +357,10 @@ func (d ReplicaSet) ConfState() raftpb.ConfState {
 	for _, rep := range d.wrapped {
 		id := uint64(rep.ReplicaID)
 		switch rep.Type {
-		case VOTER_FULL:
+		case VOTER_FULL, WITNESS:
+			// Witnesses vote like full voters. They are never added or removed
+			// as part of an atomic replication change, so they are part of both
+			// configs when the config is joint.
 			cs.Voters = append(cs.Voters, id)
 			if joint {
 				cs.VotersOutgoing = append(cs.VotersOutgoing, id)
@@ -435,15 +455,20 @@ func (d ReplicaSet) ReplicationStatus(
 	votersOldGroup := d.FilterToDescriptors(ReplicaDescriptor.IsVoterOldConfig)
 	liveVotersOldGroup := d.FilterToDescriptors(isBoth(ReplicaDescriptor.IsVoterOldConfig, liveFunc))
 
-	n := len(votersOldGroup)
+	// Witnesses are part of both groups. They count towards availability, but
+	// not towards the replication of voters below.
+	witnesses := d.FilterToDescriptors(ReplicaDescriptor.IsWitness)
+	liveWitnesses := d.FilterToDescriptors(isBoth(ReplicaDescriptor.IsWitness, liveFunc))
+
+	n := len(votersOldGroup) + len(witnesses)
 	// Empty groups succeed by default, to match the Raft implementation.
-	availableOutgoingGroup := (n == 0) || (len(liveVotersOldGroup) >= n/2+1)
+	availableOutgoingGroup := (n == 0) || (len(liveVotersOldGroup)+len(liveWitnesses) >= n/2+1)
 
 	votersNewGroup := d.FilterToDescriptors(ReplicaDescriptor.IsVoterNewConfig)
 	liveVotersNewGroup := d.FilterToDescriptors(isBoth(ReplicaDescriptor.IsVoterNewConfig, liveFunc))
 
-	n = len(votersNewGroup)
-	availableIncomingGroup := len(liveVotersNewGroup) >= n/2+1
+	n = len(votersNewGroup) + len(witnesses)
+	availableIncomingGroup := len(liveVotersNewGroup)+len(liveWitnesses) >= n/2+1
 
 	res.Available = availableIncomingGroup && availableOutgoingGroup
 
@@ -506,9 +531,9 @@ func (d ReplicaSet) Subtract(o ReplicaSet) []ReplicaDescriptor {
 // IsAddition returns true if `c` refers to a replica addition operation.
 func (c ReplicaChangeType) IsAddition() bool {
 	switch c {
-	case ADD_NON_VOTER, ADD_VOTER:
+	case ADD_NON_VOTER, ADD_VOTER, ADD_WITNESS:
 		return true
-	case REMOVE_NON_VOTER, REMOVE_VOTER:
+	case REMOVE_NON_VOTER, REMOVE_VOTER, REMOVE_WITNESS:
 		return false
 	default:
 		panic(fmt.Sprintf("unexpected ReplicaChangeType %s", c))
@@ -518,9 +543,9 @@ func (c ReplicaChangeType) IsAddition() bool {
 // IsRemoval returns true if `c` refers a replica removal operation.
 func (c ReplicaChangeType) IsRemoval() bool {
 	switch c {
-	case ADD_NON_VOTER, ADD_VOTER:
+	case ADD_NON_VOTER, ADD_VOTER, ADD_WITNESS:
 		return false
-	case REMOVE_NON_VOTER, REMOVE_VOTER:
+	case REMOVE_NON_VOTER, REMOVE_VOTER, REMOVE_WITNESS:
 		return true
 	default:
 		panic(fmt.Sprintf("unexpected ReplicaChangeType %s", c))
@@ -557,6 +582,8 @@ var ErrReplicaCannotHoldLease = errors.New(`lease target replica cannot hold lea
 // aren't, the CAS call for extending the lease will fail (see
 // wasLastLeaseholder := isExtension in cmd_lease_request.go).
 //
+// Witnesses never receive the lease, since they don't apply user writes.
+//
 // An error is also returned is the replica is not part of `replDescs`.
 // NB: This logic should be in sync with constraint_stats_report as report
 // will check voter constraint violations. When changing this method, you need
@@ -568,6 +595,9 @@ func CheckCanReceiveLease(
 	if !ok {
 		return ErrReplicaNotFound
 	}
+	if repDesc.IsWitness() {
+		return ErrReplicaCannotHoldLease
+	}
 	if !(repDesc.IsVoterNewConfig() ||
 		(repDesc.IsVoterOldConfig() && replDescs.containsVoterIncoming() && wasLastLeaseholder)) {
 		// We allow a demoting / incoming voter to receive the lease if there's an incoming voter.
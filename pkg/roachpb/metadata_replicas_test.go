@@ -182,6 +182,16 @@ func TestReplicaDescriptorsConfState(t *testing.T) {
 			[]ReplicaDescriptor{rd(VOTER_OUTGOING, 1), rd(VOTER_DEMOTING_LEARNER, 2), rd(VOTER_INCOMING, 3), rd(VOTER_INCOMING, 4), rd(LEARNER, 5)},
 			"Voters:[3 4] VotersOutgoing:[1 2] Learners:[5] LearnersNext:[2] AutoLeave:false",
 		},
+		// Witnesses vote in raft just like full voters.
+		{
+			[]ReplicaDescriptor{rd(VOTER_FULL, 1), rd(VOTER_FULL, 2), rd(WITNESS, 3)},
+			"Voters:[1 2 3] VotersOutgoing:[] Learners:[] LearnersNext:[] AutoLeave:false",
+		},
+		// A witness remains part of both configs during a joint change.
+		{
+			[]ReplicaDescriptor{rd(VOTER_FULL, 1), rd(VOTER_INCOMING, 2), rd(VOTER_OUTGOING, 3), rd(WITNESS, 4)},
+			"Voters:[1 2 4] VotersOutgoing:[1 3 4] Learners:[] LearnersNext:[] AutoLeave:false",
+		},
 	}
 
 	for _, test := range tests {
@@ -285,6 +295,19 @@ func TestReplicaDescriptorsCanMakeProgress(t *testing.T) {
 			{false, rd(VOTER_FULL, 4)},
 			{false, rd(LEARNER, 4)},
 		}, true},
+		// Two datacenters with a voter each, plus a witness in a third location.
+		// Losing either datacenter leaves a quorum thanks to the witness.
+		{[]descWithLiveness{
+			{true, rd(VOTER_FULL, 1)},
+			{false, rd(VOTER_FULL, 2)},
+			{true, rd(WITNESS, 3)},
+		}, true},
+		// The witness alone is not a quorum.
+		{[]descWithLiveness{
+			{false, rd(VOTER_FULL, 1)},
+			{false, rd(VOTER_FULL, 2)},
+			{true, rd(WITNESS, 3)},
+		}, false},
 	} {
 		t.Run("", func(t *testing.T) {
 			rds := make([]ReplicaDescriptor, 0, len(test.rds))
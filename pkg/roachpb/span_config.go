@@ -58,9 +58,22 @@ func (s *SpanConfig) HasConfigurationChange(other SpanConfig) bool {
 	return !this.Equal(other)
 }
 
-// TTL returns the implies TTL as a time.Duration.
+// TTL returns the implies TTL as a time.Duration. When a storage TTL is set,
+// the TTL is no longer than it, so that reads at timestamps older than the
+// storage TTL fail instead of observing partially expired data.
 func (s *SpanConfig) TTL() time.Duration {
-	return time.Duration(s.GCPolicy.TTLSeconds) * time.Second
+	ttl := time.Duration(s.GCPolicy.TTLSeconds) * time.Second
+	if storageTTL := s.StorageTTL(); storageTTL > 0 && storageTTL < ttl {
+		return storageTTL
+	}
+	return ttl
+}
+
+// StorageTTL returns the storage TTL as a time.Duration. Live keys whose
+// newest version is older than the storage TTL are expired by garbage
+// collection. A value <= 0 means live keys never expire.
+func (s *SpanConfig) StorageTTL() time.Duration {
+	return time.Duration(s.GCPolicy.StorageTTLSeconds) * time.Second
 }
 
 // ValidateSystemTargetSpanConfig ensures that only protection policies
@@ -79,6 +92,9 @@ func (s *SpanConfig) ValidateSystemTargetSpanConfig() error {
 	if s.GCPolicy.IgnoreStrictEnforcement {
 		return errors.AssertionFailedf("IgnoreStrictEnforcement set on system span config")
 	}
+	if s.GCPolicy.StorageTTLSeconds != 0 {
+		return errors.AssertionFailedf("StorageTTLSeconds set on system span config")
+	}
 	if s.GlobalReads {
 		return errors.AssertionFailedf("GlobalReads set on system span config")
 	}
@@ -88,6 +104,9 @@ func (s *SpanConfig) ValidateSystemTargetSpanConfig() error {
 	if s.NumVoters != 0 {
 		return errors.AssertionFailedf("NumVoters set on system span config")
 	}
+	if s.NumWitnesses != 0 {
+		return errors.AssertionFailedf("NumWitnesses set on system span config")
+	}
 	if len(s.Constraints) != 0 {
 		return errors.AssertionFailedf("Constraints set on system span config")
 	}
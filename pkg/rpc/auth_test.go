@@ -946,6 +946,52 @@ func TestTenantAuthCapabilityChecks(t *testing.T) {
 				},
 				expErr: "tenant does not have capability",
 			},
+			{
+				req: &kvpb.BatchRequest{Requests: makeReqs(
+					makeReqShared(t, prefix(5, "a"), prefix(5, "b")),
+				)},
+				configureAuthorizer: func(authorizer *mockAuthorizer) {
+					authorizer.hasCapabilityForBatch = true
+					authorizer.readFromTenant = roachpb.MustMakeTenantID(5)
+				},
+				expErr: "",
+			},
+			{
+				req: &kvpb.BatchRequest{Requests: makeReqs(
+					makeReqShared(t, prefix(5, "a"), prefix(5, "b")),
+				)},
+				configureAuthorizer: func(authorizer *mockAuthorizer) {
+					authorizer.hasCapabilityForBatch = true
+					authorizer.readFromTenant = roachpb.MustMakeTenantID(6)
+				},
+				expErr: `requested key span /Tenant/5{a-b} not fully contained in tenant keyspace /Tenant/1{0-1}`,
+			},
+			{
+				req: &kvpb.BatchRequest{Requests: makeReqs(
+					&kvpb.PutRequest{RequestHeader: kvpb.RequestHeader{Key: roachpb.Key(prefix(5, "a"))}},
+				)},
+				configureAuthorizer: func(authorizer *mockAuthorizer) {
+					authorizer.hasCapabilityForBatch = true
+					authorizer.readFromTenant = roachpb.MustMakeTenantID(5)
+				},
+				expErr: `requested key span .* not fully contained in tenant keyspace /Tenant/1{0-1}`,
+			},
+		},
+		"/cockroach.roachpb.Internal/RangeLookup": {
+			{
+				req: &kvpb.RangeLookupRequest{Key: roachpb.RKey(prefix(5, "a"))},
+				configureAuthorizer: func(authorizer *mockAuthorizer) {
+					authorizer.readFromTenant = roachpb.MustMakeTenantID(5)
+				},
+				expErr: "",
+			},
+			{
+				req: &kvpb.RangeLookupRequest{Key: roachpb.RKey(prefix(6, "a"))},
+				configureAuthorizer: func(authorizer *mockAuthorizer) {
+					authorizer.readFromTenant = roachpb.MustMakeTenantID(5)
+				},
+				expErr: `requested key /Tenant/6"a" not fully contained in tenant keyspace /Tenant/1{0-1}`,
+			},
 		},
 		"/cockroach.ts.tspb.TimeSeries/Query": {
 			{
@@ -988,6 +1034,14 @@ type mockAuthorizer struct {
 	hasTSDBQueryCapability             bool
 	hasNodelocalStorageCapability      bool
 	hasExemptFromRateLimiterCapability bool
+	readFromTenant                     roachpb.TenantID
+}
+
+// HasCrossTenantRead implements the tenantcapabilities.Authorizer interface.
+func (m mockAuthorizer) HasCrossTenantRead(
+	ctx context.Context, tenID roachpb.TenantID,
+) (roachpb.TenantID, bool) {
+	return m.readFromTenant, m.readFromTenant.IsSet()
 }
 
 func (m mockAuthorizer) HasProcessDebugCapability(
@@ -469,6 +469,23 @@ type hotRangeInfo struct {
 	SchemaName          string           `json:"schema_name"`
 	ReplicaNodeIDs      []roachpb.NodeID `json:"replica_node_ids"`
 	StoreID             roachpb.StoreID  `json:"store_id"`
+	HotKeys             []hotKeyInfo     `json:"hot_keys,omitempty"`
+}
+
+// Hot key details struct describes the load on one of the most frequently
+// accessed keys of a hot range, and the table row or index entry it belongs
+// to.
+//
+// swagger:model hotKeyInfo
+type hotKeyInfo struct {
+	Key                     string  `json:"key"`
+	TableName               string  `json:"table_name,omitempty"`
+	IndexName               string  `json:"index_name,omitempty"`
+	KeyValues               string  `json:"key_values,omitempty"`
+	ReadsPerSecond          float64 `json:"reads_per_second"`
+	WritesPerSecond         float64 `json:"writes_per_second"`
+	ContentionTimePerSecond float64 `json:"contention_time_per_second"`
+	LatchWaitTimePerSecond  float64 `json:"latch_wait_time_per_second"`
 }
 
 // swagger:operation GET /ranges/hot/ listHotRanges
@@ -557,6 +574,18 @@ func (a *apiV2Server) listHotRanges(w http.ResponseWriter, r *http.Request) {
 				SchemaName:          r.SchemaName,
 				StoreID:             r.StoreID,
 			}
+			for _, hk := range r.HotKeys {
+				hotRangeInfos[i].HotKeys = append(hotRangeInfos[i].HotKeys, hotKeyInfo{
+					Key:                     hk.Key.String(),
+					TableName:               hk.TableName,
+					IndexName:               hk.IndexName,
+					KeyValues:               hk.KeyValues,
+					ReadsPerSecond:          hk.ReadsPerSecond,
+					WritesPerSecond:         hk.WritesPerSecond,
+					ContentionTimePerSecond: hk.ContentionTimePerSecond,
+					LatchWaitTimePerSecond:  hk.LatchWaitTimePerSecond,
+				})
+			}
 		}
 		return hotRangeInfos, nil
 	}
@@ -447,6 +447,10 @@ type KVConfig struct {
 	DelayedBootstrapFn func()
 
 	enginesCreated bool
+	// raftLogEngines maps the engines created by CreateEngines to the separate
+	// raft log engines of their stores, for the stores that have one. The
+	// caller of CreateEngines is responsible for closing them.
+	raftLogEngines map[storage.Engine]storage.Engine
 
 	// SnapshotSendLimit is the number of concurrent snapshots a store will send.
 	SnapshotSendLimit int64
@@ -692,9 +696,15 @@ func (e *Engines) Close() {
 }
 
 // CreateEngines creates Engines based on the specs in cfg.Stores.
+//
+// Stores that keep their raft logs in a separate directory get a separate raft
+// log engine, which is recorded in cfg.raftLogEngines.
 func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 	var engines Engines
 	defer engines.Close()
+	var raftLogEngines Engines
+	defer raftLogEngines.Close()
+	raftLogEngineByStore := map[storage.Engine]storage.Engine{}
 
 	if cfg.enginesCreated {
 		return Engines{}, errors.Errorf("engines already created")
@@ -853,6 +863,23 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 		}
 		detail(redact.Sprintf("store %d: %+v", i, eng.Properties()))
 		engines = append(engines, eng)
+
+		if spec.RaftLogPath != "" {
+			if err := vfs.Default.MkdirAll(spec.RaftLogPath, 0755); err != nil {
+				return Engines{}, errors.Wrap(err, "creating raft log directory")
+			}
+			logEng, err := storage.Open(ctx, storage.Filesystem(spec.RaftLogPath), cfg.Settings,
+				storage.EncryptionAtRest(spec.EncryptionOptions),
+				storage.Caches(pebbleCache, tableCache),
+				storage.RaftLogEngine,
+			)
+			if err != nil {
+				return Engines{}, errors.Wrapf(err, "store %d: opening raft log engine", i)
+			}
+			detail(redact.Sprintf("store %d: raft log engine in %s", i, spec.RaftLogPath))
+			raftLogEngines = append(raftLogEngines, logEng)
+			raftLogEngineByStore[eng] = logEng
+		}
 	}
 
 	if tableCache != nil {
@@ -868,9 +895,11 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 		log.Infof(ctx, "%v", s)
 	}
 
+	cfg.raftLogEngines = raftLogEngineByStore
 	// Clear out engines because we have deferred engines.Close().
 	enginesCopy := engines
 	engines = nil
+	raftLogEngines = nil
 	return enginesCopy, nil
 }
 
@@ -11,9 +11,23 @@
 package server
 
 import (
+	"context"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/split"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catenumpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
 )
 
 // HotRangesRequestNodeTimeout controls the timeout of a serverpb.HotRangesRequest.
@@ -28,3 +42,120 @@ var HotRangesRequestNodeTimeout = settings.RegisterDurationSetting(
 	time.Minute*5,
 	settings.NonNegativeDuration,
 	settings.WithPublic)
+
+// makeHotKeys converts the hot keys reported by a replica to their status API
+// representation.
+func makeHotKeys(hotKeys []split.HotKey) []serverpb.HotKey {
+	if len(hotKeys) == 0 {
+		return nil
+	}
+	res := make([]serverpb.HotKey, len(hotKeys))
+	for i, hk := range hotKeys {
+		res[i] = serverpb.HotKey{
+			Key:                     hk.Key,
+			ReadsPerSecond:          hk.ReadsPerSecond,
+			WritesPerSecond:         hk.WritesPerSecond,
+			ContentionTimePerSecond: hk.ContentionNanosPerSecond,
+			LatchWaitTimePerSecond:  hk.LatchWaitNanosPerSecond,
+		}
+	}
+	return res
+}
+
+// decodeHotKeys populates the table, index and key column values of the hot
+// keys of the given ranges which belong to one of the tenant's SQL tables.
+// Keys which can't be decoded are reported as is. The table descriptors are
+// resolved in a single transaction, and each of them is looked up only once.
+func (s *systemStatusServer) decodeHotKeys(
+	ctx context.Context, ranges []*serverpb.HotRangesResponseV2_HotRange,
+) {
+	var hotKeys []*serverpb.HotKey
+	for _, r := range ranges {
+		for i := range r.HotKeys {
+			hotKeys = append(hotKeys, &r.HotKeys[i])
+		}
+	}
+	if len(hotKeys) == 0 {
+		return
+	}
+	codec := s.sqlServer.execCfg.Codec
+	if err := s.sqlServer.distSQLServer.DB.DescsTxn(
+		ctx, func(ctx context.Context, txn descs.Txn) error {
+			// A nil entry records a table whose descriptor couldn't be
+			// retrieved, so that the failure is only logged once.
+			descsByID := make(map[descpb.ID]catalog.TableDescriptor)
+			for _, hk := range hotKeys {
+				hk.TableName, hk.IndexName, hk.KeyValues = "", "", ""
+				_, tableID, ok := decodeTableID(codec, hk.Key)
+				if !ok {
+					continue
+				}
+				id := descpb.ID(tableID)
+				desc, found := descsByID[id]
+				if !found {
+					var err error
+					desc, err = txn.Descriptors().ByID(txn.KV()).WithoutNonPublic().Get().Table(ctx, id)
+					if err != nil {
+						log.Warningf(ctx, "cannot get table descriptor %d for hot keys: %v", id, err)
+						desc = nil
+					}
+					descsByID[id] = desc
+				}
+				if desc == nil {
+					continue
+				}
+				hk.TableName = desc.GetName()
+				var err error
+				if hk.IndexName, hk.KeyValues, err = decodeIndexKeyValues(codec, desc, hk.Key); err != nil {
+					log.Warningf(ctx, "cannot decode hot key %s: %v", hk.Key, err)
+				}
+			}
+			return nil
+		}); err != nil {
+		log.Warningf(ctx, "failed to decode hot keys: %v", err)
+	}
+}
+
+// decodeIndexKeyValues returns the name of the index that the key belongs to
+// and the values of the index's key columns, followed by the primary key
+// columns which aren't part of a secondary index's key. The key may be a
+// prefix of an index key, in which case only the values of the leading
+// columns are returned.
+func decodeIndexKeyValues(
+	codec keys.SQLCodec, desc catalog.TableDescriptor, key roachpb.Key,
+) (indexName, values string, _ error) {
+	_, _, indexID, err := codec.DecodeIndexPrefix(key)
+	if err != nil {
+		return "", "", err
+	}
+	index, err := catalog.MustFindIndexByID(desc, descpb.IndexID(indexID))
+	if err != nil {
+		return "", "", err
+	}
+	if index.GetType() != descpb.IndexDescriptor_FORWARD {
+		return "", "", errors.Newf("cannot decode the key of %s index %q", index.GetType(), index.GetName())
+	}
+	colTypes := make([]*types.T, 0, index.NumKeyColumns()+index.NumKeySuffixColumns())
+	colDirs := make([]catenumpb.IndexColumn_Direction, 0, cap(colTypes))
+	for i := 0; i < index.NumKeyColumns(); i++ {
+		col, err := catalog.MustFindColumnByID(desc, index.GetKeyColumnID(i))
+		if err != nil {
+			return "", "", err
+		}
+		colTypes = append(colTypes, col.GetType())
+		colDirs = append(colDirs, index.GetKeyColumnDirection(i))
+	}
+	for i := 0; i < index.NumKeySuffixColumns(); i++ {
+		col, err := catalog.MustFindColumnByID(desc, index.GetKeySuffixColumnID(i))
+		if err != nil {
+			return "", "", err
+		}
+		colTypes = append(colTypes, col.GetType())
+		colDirs = append(colDirs, catenumpb.IndexColumn_ASC)
+	}
+	datums, err := rowenc.DecodeIndexKeyToDatums(codec, colTypes, colDirs, key, &tree.DatumAlloc{})
+	if err != nil {
+		return index.GetName(), "", err
+	}
+	return index.GetName(), tree.AsString(&datums), nil
+}
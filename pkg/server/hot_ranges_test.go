@@ -0,0 +1,154 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/desctestutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeIndexKeyValues(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{
+		DefaultTestTenant: base.TestIsSpecificToStorageLayerAndNeedsASystemTenant,
+	})
+	defer srv.Stopper().Stop(ctx)
+
+	codec := keys.SystemSQLCodec
+	runner := sqlutils.MakeSQLRunner(sqlDB)
+	runner.Exec(t, `CREATE DATABASE test`)
+	runner.Exec(t, `CREATE TABLE test.t (
+  a INT, b STRING, c INT, j JSONB,
+  PRIMARY KEY (a, b),
+  INDEX c_idx (c DESC),
+  INVERTED INDEX j_idx (j)
+)`)
+	desc := desctestutils.TestingGetPublicTableDescriptor(kvDB, codec, "test", "t")
+	tableID := uint32(desc.GetID())
+
+	encodeKey := func(t *testing.T, indexID int, row string) roachpb.Key {
+		var key []byte
+		runner.QueryRow(t, `SELECT crdb_internal.encode_key($1, $2, `+row+`)`, tableID, indexID).Scan(&key)
+		return key
+	}
+
+	for _, tc := range []struct {
+		name   string
+		key    func(t *testing.T) roachpb.Key
+		index  string
+		values string
+		err    string
+	}{
+		{
+			name:   "primary",
+			key:    func(t *testing.T) roachpb.Key { return encodeKey(t, 1, `(1, 'x')`) },
+			index:  "t_pkey",
+			values: `(1, 'x')`,
+		},
+		{
+			name:   "secondary with key suffix",
+			key:    func(t *testing.T) roachpb.Key { return encodeKey(t, 2, `(3, 1, 'x')`) },
+			index:  "c_idx",
+			values: `(3, 1, 'x')`,
+		},
+		{
+			name: "prefix",
+			key: func(t *testing.T) roachpb.Key {
+				return encoding.EncodeVarintDescending(codec.IndexPrefix(tableID, 2), 3)
+			},
+			index:  "c_idx",
+			values: `(3)`,
+		},
+		{
+			name:   "index prefix only",
+			key:    func(t *testing.T) roachpb.Key { return codec.IndexPrefix(tableID, 2) },
+			index:  "c_idx",
+			values: `()`,
+		},
+		{
+			name: "inverted",
+			key:  func(t *testing.T) roachpb.Key { return codec.IndexPrefix(tableID, 3) },
+			err:  `cannot decode the key of INVERTED index "j_idx"`,
+		},
+		{
+			name: "unknown index",
+			key:  func(t *testing.T) roachpb.Key { return codec.IndexPrefix(tableID, 42) },
+			err:  `index-id "42" does not exist`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			index, values, err := decodeIndexKeyValues(codec, desc, tc.key(t))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.index, index)
+			require.Equal(t, tc.values, values)
+		})
+	}
+}
+
+func TestDecodeHotKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{
+		DefaultTestTenant: base.TestIsSpecificToStorageLayerAndNeedsASystemTenant,
+	})
+	defer srv.Stopper().Stop(ctx)
+	s := srv.StatusServer().(*systemStatusServer)
+
+	runner := sqlutils.MakeSQLRunner(sqlDB)
+	runner.Exec(t, `CREATE TABLE t1 (k INT PRIMARY KEY)`)
+	runner.Exec(t, `CREATE TABLE t2 (k STRING PRIMARY KEY, v INT, INDEX v_idx (v))`)
+	encodeKey := func(query string) roachpb.Key {
+		var key []byte
+		runner.QueryRow(t, query).Scan(&key)
+		return key
+	}
+	t1Key := encodeKey(`SELECT crdb_internal.encode_key('t1'::REGCLASS::INT, 1, (7,))`)
+	t2Key := encodeKey(`SELECT crdb_internal.encode_key('t2'::REGCLASS::INT, 2, (5, 'a'))`)
+	metaKey := keys.Meta2KeyMax
+
+	ranges := []*serverpb.HotRangesResponseV2_HotRange{
+		{HotKeys: []serverpb.HotKey{{Key: t1Key}, {Key: metaKey}}},
+		{},
+		{HotKeys: []serverpb.HotKey{{Key: t2Key}, {Key: t1Key}}},
+	}
+	s.decodeHotKeys(ctx, ranges)
+
+	require.Equal(t, []serverpb.HotKey{
+		{Key: t1Key, TableName: "t1", IndexName: "t1_pkey", KeyValues: `(7)`},
+		{Key: metaKey},
+	}, ranges[0].HotKeys)
+	require.Empty(t, ranges[1].HotKeys)
+	require.Equal(t, []serverpb.HotKey{
+		{Key: t2Key, TableName: "t2", IndexName: "v_idx", KeyValues: `(5, 'a')`},
+		{Key: t1Key, TableName: "t1", IndexName: "t1_pkey", KeyValues: `(7)`},
+	}, ranges[2].HotKeys)
+}
@@ -296,6 +296,11 @@ func NewServer(cfg Config, stopper *stop.Stopper) (serverctl.ServerStartupInterf
 		return nil, errors.Wrap(err, "failed to create engines")
 	}
 	stopper.AddCloser(&engines)
+	raftLogEngines := make(Engines, 0, len(cfg.raftLogEngines))
+	for _, logEng := range cfg.raftLogEngines {
+		raftLogEngines = append(raftLogEngines, logEng)
+	}
+	stopper.AddCloser(&raftLogEngines)
 
 	// Loss of quorum recovery store is created and pending plan is applied to
 	// engines as soon as engines are created and before any data is read in a
@@ -851,6 +856,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (serverctl.ServerStartupInterf
 		KVMemoryMonitor:              kvMemoryMonitor,
 		RangefeedBudgetFactory:       rangeReedBudgetFactory,
 		SharedStorageEnabled:         cfg.SharedStorage != "",
+		RaftLogEngines:               cfg.raftLogEngines,
 		SystemConfigProvider:         systemConfigWatcher,
 		SpanConfigSubscriber:         spanConfig.subscriber,
 		SnapshotApplyLimit:           cfg.SnapshotApplyLimit,
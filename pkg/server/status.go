@@ -2814,9 +2814,11 @@ func (s *systemStatusServer) HotRangesV2(
 						ReplicaNodeIds:      replicaNodeIDs,
 						LeaseholderNodeID:   r.LeaseholderNodeID,
 						StoreID:             store.StoreID,
+						HotKeys:             r.HotKeys,
 					})
 				}
 			}
+			s.decodeHotKeys(ctx, ranges)
 			response.Ranges = ranges
 			response.ErrorsByNodeID[requestedNodeID] = resp.ErrorMessage
 			return response, nil
@@ -2901,6 +2903,7 @@ func (s *systemStatusServer) localHotRanges(
 			if err == nil {
 				lease, _ := replica.GetLease()
 				storeResp.HotRanges[i].LeaseholderNodeID = lease.Replica.NodeID
+				storeResp.HotRanges[i].HotKeys = makeHotKeys(replica.HotKeys())
 			}
 			storeResp.HotRanges[i].Desc = *r.Desc
 			storeResp.HotRanges[i].QueriesPerSecond = r.QPS
@@ -49,20 +49,24 @@ var fields = [config.NumFields]Field{
 	globalReads,
 	numVoters,
 	numReplicas,
+	numWitnesses,
 	gcTTLSeconds,
+	storageTTLSeconds,
 	constraints,
 	voterConstraints,
 	leasePreferences,
 }
 
 const (
-	rangeMaxBytes    = int64Field(config.RangeMaxBytes)
-	rangeMinBytes    = int64Field(config.RangeMinBytes)
-	globalReads      = boolField(config.GlobalReads)
-	numReplicas      = int32Field(config.NumReplicas)
-	numVoters        = int32Field(config.NumVoters)
-	gcTTLSeconds     = int32Field(config.GCTTL)
-	constraints      = constraintsConjunctionField(config.Constraints)
-	voterConstraints = constraintsConjunctionField(config.VoterConstraints)
-	leasePreferences = leasePreferencesField(config.LeasePreferences)
+	rangeMaxBytes     = int64Field(config.RangeMaxBytes)
+	rangeMinBytes     = int64Field(config.RangeMinBytes)
+	globalReads       = boolField(config.GlobalReads)
+	numReplicas       = int32Field(config.NumReplicas)
+	numVoters         = int32Field(config.NumVoters)
+	numWitnesses      = int32Field(config.NumWitnesses)
+	gcTTLSeconds      = int32Field(config.GCTTL)
+	storageTTLSeconds = int32Field(config.StorageTTL)
+	constraints       = constraintsConjunctionField(config.Constraints)
+	voterConstraints  = constraintsConjunctionField(config.VoterConstraints)
+	leasePreferences  = leasePreferencesField(config.LeasePreferences)
 )
@@ -35,8 +35,12 @@ func (f int32Field) FieldBound(b *Bounds) ValueBounds {
 			return b.NumReplicas
 		case numVoters:
 			return b.NumVoters
+		case numWitnesses:
+			return b.NumWitnesses
 		case gcTTLSeconds:
 			return b.GCTTLSeconds
+		case storageTTLSeconds:
+			return b.StorageTTLSeconds
 		default:
 			// This is safe because we test that all the fields in the proto have
 			// a corresponding field, and we call this for each of them, and the user
@@ -60,8 +64,12 @@ func (f int32Field) fieldValue(c *roachpb.SpanConfig) *int32 {
 		return &c.NumReplicas
 	case numVoters:
 		return &c.NumVoters
+	case numWitnesses:
+		return &c.NumWitnesses
 	case gcTTLSeconds:
 		return &c.GCPolicy.TTLSeconds
+	case storageTTLSeconds:
+		return &c.GCPolicy.StorageTTLSeconds
 	default:
 		// This is safe because we test that all the fields in the proto have
 		// a corresponding field, and we call this for each of them, and the user
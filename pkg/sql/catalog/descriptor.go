@@ -759,6 +759,10 @@ type TableDescriptor interface {
 	// IsSchemaLocked returns true if we don't allow performing schema changes
 	// on this table descriptor.
 	IsSchemaLocked() bool
+	// ExternalRowData indicates where the row data for this descriptor is
+	// stored if it is stored outside the span of this table in this tenant's
+	// keyspace. It returns nil if the row data is stored locally.
+	ExternalRowData() *descpb.ExternalRowData
 }
 
 // MutableTableDescriptor is both a MutableDescriptor and a TableDescriptor.
@@ -347,6 +347,7 @@ func NewColBatchScan(
 	kvFetcher := row.NewKVFetcher(
 		flowCtx.Txn,
 		bsHeader,
+		&spec.FetchSpec,
 		spec.Reverse,
 		spec.LockingStrength,
 		spec.LockingWaitPolicy,
@@ -543,6 +543,9 @@ func NewColIndexJoin(
 	if err != nil {
 		return nil, err
 	}
+	// The streamer reads through txn, which cannot see the keyspace of a table
+	// with external row data.
+	useStreamer = useStreamer && spec.FetchSpec.External == nil
 	if useStreamer {
 		if streamerBudgetAcc == nil {
 			return nil, errors.AssertionFailedf("streamer budget account is nil when the Streamer API is desired")
@@ -577,7 +580,8 @@ func NewColIndexJoin(
 	} else {
 		kvFetcher = row.NewKVFetcher(
 			txn,
-			nil,   /* bsHeader */
+			nil, /* bsHeader */
+			&spec.FetchSpec,
 			false, /* reverse */
 			spec.LockingStrength,
 			spec.LockingWaitPolicy,
@@ -218,6 +218,7 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalKVFlowTokenDeductions:              crdbInternalKVFlowTokenDeductions,
 		catconstants.CrdbInternalRepairableCatalogCorruptionsViewID: crdbInternalRepairableCatalogCorruptions,
 		catconstants.CrdbInternalKVProtectedTS:                      crdbInternalKVProtectedTSTable,
+		catconstants.CrdbInternalClusterHotKeysTableID:              crdbInternalClusterHotKeysTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -8766,3 +8767,54 @@ func populateFlowTokensResponse(
 	}
 	return nil
 }
+
+var crdbInternalClusterHotKeysTable = virtualSchemaTable{
+	comment: `the most frequently accessed keys of the cluster's hot ranges, as sampled by
+their leaseholders. Querying this table is an expensive operation since it
+creates a cluster-wide RPC-fanout.`,
+	schema: `
+CREATE TABLE crdb_internal.cluster_hot_keys (
+  range_id                   INT NOT NULL,
+  node_id                    INT NOT NULL,
+  store_id                   INT NOT NULL,
+  key                        BYTES NOT NULL,
+  pretty_key                 STRING NOT NULL,
+  table_name                 STRING NOT NULL,
+  index_name                 STRING NOT NULL,
+  key_values                 STRING NOT NULL, -- The decoded values of the index's key columns.
+  reads_per_second           FLOAT NOT NULL,
+  writes_per_second          FLOAT NOT NULL,
+  contention_time_per_second FLOAT NOT NULL, -- Nanoseconds spent per second in lock wait-queues.
+  latch_wait_time_per_second FLOAT NOT NULL  -- Nanoseconds spent per second waiting for latches.
+)`,
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.CheckPrivilege(ctx, syntheticprivilege.GlobalPrivilegeObject, privilege.VIEWCLUSTERMETADATA); err != nil {
+			return err
+		}
+		resp, err := p.ExecCfg().TenantStatusServer.HotRangesV2(ctx, &serverpb.HotRangesRequest{})
+		if err != nil {
+			return err
+		}
+		for _, r := range resp.Ranges {
+			for _, hk := range r.HotKeys {
+				if err := addRow(
+					tree.NewDInt(tree.DInt(r.RangeID)),
+					tree.NewDInt(tree.DInt(r.NodeID)),
+					tree.NewDInt(tree.DInt(r.StoreID)),
+					tree.NewDBytes(tree.DBytes(hk.Key)),
+					tree.NewDString(hk.Key.String()),
+					tree.NewDString(hk.TableName),
+					tree.NewDString(hk.IndexName),
+					tree.NewDString(hk.KeyValues),
+					tree.NewDFloat(tree.DFloat(hk.ReadsPerSecond)),
+					tree.NewDFloat(tree.DFloat(hk.WritesPerSecond)),
+					tree.NewDFloat(tree.DFloat(hk.ContentionTimePerSecond)),
+					tree.NewDFloat(tree.DFloat(hk.LatchWaitTimePerSecond)),
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catenumpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/fetchpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/colflow"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsql"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra/execagg"
@@ -4719,6 +4720,62 @@ func maybeMoveSingleFlowToGateway(planCtx *PlanningCtx, plan *PhysicalPlan, rowC
 	}
 }
 
+// pinExternalRowDataTimestamps rewrites the fetch specs of the plan which read
+// tables with external row data so that each table is read at a single
+// timestamp for the whole statement. A table is read at the timestamp of the
+// descriptor the statement leased for it, which is pinned the first time the
+// table is planned so that subqueries and postqueries read it at the same
+// timestamp even if they lease a newer version. If the statement has an AS OF
+// SYSTEM TIME clause, every table is read at its read timestamp instead.
+func pinExternalRowDataTimestamps(planCtx *PlanningCtx, plan *PhysicalPlan) {
+	var specs []*fetchpb.IndexFetchSpec
+	for i := range plan.Processors {
+		core := &plan.Processors[i].Spec.Core
+		switch {
+		case core.TableReader != nil:
+			specs = append(specs, &core.TableReader.FetchSpec)
+		case core.JoinReader != nil:
+			specs = append(specs, &core.JoinReader.FetchSpec)
+		case core.InvertedJoiner != nil:
+			specs = append(specs, &core.InvertedJoiner.FetchSpec)
+		case core.ZigzagJoiner != nil:
+			for j := range core.ZigzagJoiner.Sides {
+				specs = append(specs, &core.ZigzagJoiner.Sides[j].FetchSpec)
+			}
+		}
+	}
+	var readTS hlc.Timestamp
+	if p := planCtx.planner; p != nil && p.EvalContext().AsOfSystemTime != nil && p.Txn() != nil {
+		readTS = p.Txn().ReadTimestamp()
+	}
+	for _, spec := range specs {
+		if spec.External == nil {
+			continue
+		}
+		asOf := readTS
+		if asOf.IsEmpty() {
+			asOf = spec.External.AsOf
+			if p := planCtx.planner; p != nil {
+				if pinned, ok := p.curPlan.externalRowDataAsOf[spec.TableID]; ok {
+					asOf = pinned
+				} else {
+					if p.curPlan.externalRowDataAsOf == nil {
+						p.curPlan.externalRowDataAsOf = make(map[descpb.ID]hlc.Timestamp)
+					}
+					p.curPlan.externalRowDataAsOf[spec.TableID] = asOf
+				}
+			}
+		}
+		if spec.External.AsOf != asOf {
+			// The spec shares the ExternalRowData with the leased descriptor,
+			// so it must not be modified in place.
+			ext := *spec.External
+			ext.AsOf = asOf
+			spec.External = &ext
+		}
+	}
+}
+
 // FinalizePlan adds a final "result" stage and a final projection if necessary
 // as well as populates the endpoints of the plan.
 func FinalizePlan(ctx context.Context, planCtx *PlanningCtx, plan *PhysicalPlan) {
@@ -4758,6 +4815,8 @@ func finalizePlanWithRowCount(
 		Type: execinfrapb.StreamEndpointSpec_SYNC_RESPONSE,
 	})
 
+	pinExternalRowDataTimestamps(planCtx, plan)
+
 	// Assign processor IDs.
 	for i, p := range plan.Processors {
 		plan.Processors[i].Spec.ProcessorID = int32(i)
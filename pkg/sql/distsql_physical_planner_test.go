@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangecache"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -1518,3 +1519,73 @@ func TestClosestInstances(t *testing.T) {
 		})
 	}
 }
+
+// TestPinExternalRowDataTimestamps verifies that each table with external row
+// data is read at the timestamp of the descriptor the statement first leased
+// for it, or at the AS OF SYSTEM TIME of the statement if it has one.
+func TestPinExternalRowDataTimestamps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+	makePlan := func(asOfByTable map[descpb.ID]hlc.Timestamp) *PhysicalPlan {
+		plan := &PhysicalPlan{PhysicalInfrastructure: &physicalplan.PhysicalInfrastructure{}}
+		for id, asOf := range asOfByTable {
+			var core execinfrapb.ProcessorCoreUnion
+			core.TableReader = &execinfrapb.TableReaderSpec{}
+			core.TableReader.FetchSpec.TableID = id
+			core.TableReader.FetchSpec.External = &descpb.ExternalRowData{AsOf: asOf, TableID: id}
+			plan.Processors = append(plan.Processors, physicalplan.Processor{
+				Spec: execinfrapb.ProcessorSpec{Core: core},
+			})
+		}
+		return plan
+	}
+	readAsOf := func(plan *PhysicalPlan) map[descpb.ID]hlc.Timestamp {
+		res := make(map[descpb.ID]hlc.Timestamp)
+		for _, p := range plan.Processors {
+			spec := p.Spec.Core.TableReader.FetchSpec
+			res[spec.TableID] = spec.External.AsOf
+		}
+		return res
+	}
+
+	t.Run("leased", func(t *testing.T) {
+		planCtx := &PlanningCtx{planner: &planner{}}
+
+		// Each table is read at the timestamp of its own descriptor.
+		plan := makePlan(map[descpb.ID]hlc.Timestamp{1: ts(10), 2: ts(20)})
+		leased := plan.Processors[0].Spec.Core.TableReader.FetchSpec.External
+		pinExternalRowDataTimestamps(planCtx, plan)
+		require.Equal(t, map[descpb.ID]hlc.Timestamp{1: ts(10), 2: ts(20)}, readAsOf(plan))
+
+		// A later plan of the statement which leased a newer version of a
+		// table reads it at the timestamp pinned by the first plan.
+		plan = makePlan(map[descpb.ID]hlc.Timestamp{1: ts(30), 3: ts(40)})
+		pinExternalRowDataTimestamps(planCtx, plan)
+		require.Equal(t, map[descpb.ID]hlc.Timestamp{1: ts(10), 3: ts(40)}, readAsOf(plan))
+		require.NotSame(t, leased, plan.Processors[0].Spec.Core.TableReader.FetchSpec.External)
+	})
+
+	t.Run("as-of-system-time", func(t *testing.T) {
+		stopper := stop.NewStopper()
+		defer stopper.Stop(ctx)
+		clock := hlc.NewClockForTesting(nil)
+		factory := kv.MakeMockTxnSenderFactory(
+			func(context.Context, *roachpb.Transaction, *kvpb.BatchRequest) (*kvpb.BatchResponse, *kvpb.Error) {
+				return nil, nil
+			})
+		db := kv.NewDB(log.MakeTestingAmbientCtxWithNewTracer(), factory, clock, stopper)
+		txn := kv.NewTxn(ctx, db, 0 /* gatewayNodeID */)
+		require.NoError(t, txn.SetFixedTimestamp(ctx, ts(5)))
+
+		p := &planner{txn: txn}
+		p.EvalContext().AsOfSystemTime = &eval.AsOfSystemTime{Timestamp: ts(5)}
+		planCtx := &PlanningCtx{planner: p}
+
+		plan := makePlan(map[descpb.ID]hlc.Timestamp{1: ts(10), 2: ts(20)})
+		pinExternalRowDataTimestamps(planCtx, plan)
+		require.Equal(t, map[descpb.ID]hlc.Timestamp{1: ts(5), 2: ts(5)}, readAsOf(plan))
+	})
+}
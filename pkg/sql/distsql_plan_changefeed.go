@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser/statements"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -182,6 +183,36 @@ func PlanCDCExpression(
 	}, nil
 }
 
+// ResolveCDCLookupTable resolves the table referenced by a lookup join in the
+// CDC expression, and verifies that the user has SELECT privilege on it.
+//
+// Lookup tables are not part of the plan produced by PlanCDCExpression;
+// instead, CDC fetches rows from those tables itself and supplies them to the
+// plan as extra columns. localPlanner is assumed to be an instance of planner
+// created specifically for planning CDC expressions.
+func ResolveCDCLookupTable(
+	ctx context.Context, localPlanner interface{}, name *tree.TableName,
+) (catalog.TableDescriptor, error) {
+	p, ok := localPlanner.(*planner)
+	if !ok {
+		return nil, errors.AssertionFailedf("expected planner, found %T", localPlanner)
+	}
+
+	lflags := tree.ObjectLookupFlags{
+		Required:             true,
+		DesiredObjectKind:    tree.TableObject,
+		DesiredTableDescKind: tree.ResolveRequireTableDesc,
+	}
+	_, desc, err := resolver.ResolveExistingTableObject(ctx, p, name, lflags)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
 // RunCDCEvaluation runs plan previously prepared by PlanCDCExpression.
 // Data is pushed into this flow from source, which generates data for the
 // specified table columns.
@@ -407,13 +407,17 @@ func init() {
 		&tree.AlterBackupSchedule{},
 		&tree.AlterTenantReplication{},
 		&tree.Backup{},
+		&tree.CompactBackup{},
+		&tree.VerifyBackup{},
 		&tree.ShowBackup{},
+		&tree.ShowBackupTable{},
 		&tree.Restore{},
 		&tree.CreateChangefeed{},
 		&tree.ScheduledChangefeed{},
 		&tree.Import{},
 		&tree.ScheduledBackup{},
 		&tree.CreateTenantFromReplication{},
+		&tree.CreateLogicalReplicationStream{},
 	} {
 		typ := optbuilder.OpaqueReadOnly
 		if tree.CanModifySchema(stmt) {
@@ -101,6 +101,16 @@ func (b *Builder) buildDataSource(
 
 		return outScope
 
+	case *tree.BackupTableSource:
+		// A table read out of a backup is planned as the statement which reads
+		// it, used as a statement source. Its columns are qualified by the name
+		// of the table unless the source is aliased.
+		outScope = b.buildDataSource(
+			&tree.StatementSource{Statement: source.ShowBackupTable()}, indexFlags, locking, inScope,
+		)
+		outScope.setTableAlias(tree.Name(source.Table.Object()))
+		return outScope
+
 	case *tree.JoinTableExpr:
 		return b.buildJoin(source, locking, inScope)
 
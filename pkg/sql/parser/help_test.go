@@ -555,6 +555,12 @@ func TestContextualHelp(t *testing.T) {
 		{`BACKUP DATABASE ??`, `BACKUP`},
 		{`BACKUP foo TO 'bar' AS OF SYSTEM ??`, `BACKUP`},
 
+		{`COMPACT BACKUP ??`, `COMPACT BACKUP`},
+		{`COMPACT BACKUP FROM LATEST IN 'foo' ??`, `COMPACT BACKUP`},
+
+		{`VERIFY BACKUP ??`, `VERIFY BACKUP`},
+		{`VERIFY BACKUP FROM LATEST IN 'foo' ??`, `VERIFY BACKUP`},
+
 		{`RESTORE foo FROM 'bar' ??`, `RESTORE`},
 		{`RESTORE DATABASE ??`, `RESTORE`},
 
@@ -571,6 +577,9 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE CHANGEFEED FOR foo ??`, `CREATE CHANGEFEED`},
 		{`CREATE CHANGEFEED FOR foo INTO 'sink' ??`, `CREATE CHANGEFEED`},
 
+		{`CREATE LOGICAL REPLICATION ??`, `CREATE LOGICAL REPLICATION STREAM`},
+		{`CREATE LOGICAL REPLICATION STREAM FROM TABLE foo ON 'bar' ??`, `CREATE LOGICAL REPLICATION STREAM`},
+
 		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
 		{`ALTER FUNCTION ??`, `ALTER FUNCTION`},
 		{`DROP FUNCTION ??`, `DROP FUNCTION`},
@@ -909,11 +909,11 @@ func (u *sqlSymUnion) beginTransaction() *tree.BeginTransaction {
 %token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC AS_JSON AT_AT
 %token <str> ASENSITIVE ASYMMETRIC AT ATOMIC ATTRIBUTE AUTHORIZATION AUTOMATIC AVAILABILITY
 
-%token <str> BACKUP BACKUPS BACKWARD BATCH BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
+%token <str> BACKFILL BACKUP BACKUPS BACKWARD BATCH BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
-%token <str> CACHE CALL CALLED CANCEL CANCELQUERY CAPABILITIES CAPABILITY CASCADE CASE CAST CBRT CHANGEFEED CHAR
+%token <str> CACHE CALL CALLED CANCEL CANCELQUERY CAPABILITIES CAPABILITY CASCADE CASE CAST CBRT CHANGEFEED CHANGE_LOG CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK CHECK_FILES CLOSE
 %token <str> CLUSTER CLUSTERS COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
 %token <str> COMMITTED COMPACT COMPLETE COMPLETIONS CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
@@ -924,7 +924,7 @@ func (u *sqlSymUnion) beginTransaction() *tree.BeginTransaction {
 %token <str> CURRENT_USER CURSOR CYCLE
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEBUG_IDS DEBUG_PAUSE_ON DEC DEBUG_DUMP_METADATA_SST DECIMAL DEFAULT DEFAULTS DEFINER
-%token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DELIMITER DEPENDS DESC DESTINATION DETACHED DETAILS
+%token <str> DEALLOCATE DECLARE DEDUPLICATE DEFERRABLE DEFERRED DELETE DELIMITER DEPENDS DESC DESTINATION DETACHED DETAILS
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
 %token <str> ELSE ENCODING ENCRYPTED ENCRYPTION_INFO_DIR ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
@@ -961,7 +961,7 @@ func (u *sqlSymUnion) beginTransaction() *tree.BeginTransaction {
 %token <str> LABEL LANGUAGE LAST LATERAL LATEST LC_CTYPE LC_COLLATE
 %token <str> LEADING LEASE LEAST LEAKPROOF LEFT LESS LEVEL LIKE LIMIT
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
-%token <str> LIST LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
+%token <str> LIST LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGICAL LOGIN LOOKUP LOW LSHIFT
 
 %token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MODIFYSQLCLUSTERSETTING MONTH MOVE
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
@@ -1009,7 +1009,7 @@ func (u *sqlSymUnion) beginTransaction() *tree.BeginTransaction {
 %token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLISTEN UNLOGGED UNSAFE_RESTORE_INCOMPATIBLE_VERSION UNSPLIT
 %token <str> UPDATE UPDATES_CLUSTER_MONITORING_METRICS UPSERT UNSET UNTIL USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VERIFY_BACKUP_TABLE_DATA VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED VIEWDEBUG
+%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VERIFY VERIFY_BACKUP_TABLE_DATA VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED VIEWDEBUG
 %token <str> VIEWCLUSTERMETADATA VIEWCLUSTERSETTING VIRTUAL VISIBLE INVISIBLE VISIBILITY VOLATILE VOTERS
 %token <str> VIRTUAL_CLUSTER_NAME VIRTUAL_CLUSTER
 
@@ -1176,11 +1176,13 @@ func (u *sqlSymUnion) beginTransaction() *tree.BeginTransaction {
 
 %type <tree.Statement> comment_stmt
 %type <tree.Statement> commit_stmt
+%type <tree.Statement> compact_backup_stmt
 %type <tree.Statement> copy_stmt
 
 %type <tree.Statement> create_stmt
 %type <tree.Statement> create_schedule_stmt
 %type <tree.Statement> create_changefeed_stmt create_schedule_for_changefeed_stmt
+%type <tree.Statement> create_logical_replication_stream_stmt
 %type <tree.Statement> create_ddl_stmt
 %type <tree.Statement> create_database_stmt
 %type <tree.Statement> create_extension_stmt
@@ -1325,6 +1327,7 @@ func (u *sqlSymUnion) beginTransaction() *tree.BeginTransaction {
 %type <tree.Statement> update_stmt
 %type <tree.Statement> upsert_stmt
 %type <tree.Statement> use_stmt
+%type <tree.Statement> verify_backup_stmt
 
 %type <tree.Statement> close_cursor_stmt
 %type <tree.Statement> declare_cursor_stmt
@@ -3187,6 +3190,8 @@ opt_clear_data:
 //    detached: execute backup job asynchronously, without waiting for its completion
 //    incremental_location: specify a different path to store the incremental backup
 //    include_all_virtual_clusters: enable backups of all virtual clusters during a cluster backup
+//    change_log: continuously write changes to the targets to the collection, for point-in-time restore
+//    deduplicate: share the data files of full backups that did not change with other backups of the collection
 //
 // %SeeAlso: RESTORE, WEBDOCS/backup.html
 backup_stmt:
@@ -3235,6 +3240,64 @@ backup_stmt:
   }
 | BACKUP error // SHOW HELP: BACKUP
 
+// %Help: COMPACT BACKUP - merge layers of a backup chain
+// %Category: CCL
+// %Text:
+// COMPACT BACKUP FROM <subdir> IN <destination...>
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Merges incremental layers of the backup chain in <subdir> into a single
+// layer, without reading from the cluster.
+//
+// Options:
+//    start_time: start time of the first layer to compact (default: the end of the full backup)
+//    end_time: end time of the last layer to compact (default: the end of the chain)
+//    into_full: merge the full backup and the layers into a new full backup
+//    incremental_location: the path that stores the incremental layers
+//    encryption_passphrase="secret": decrypt and encrypt the backup
+//    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt and encrypt the backup using KMS
+//    detached: execute the job asynchronously, without waiting for its completion
+//
+// %SeeAlso: BACKUP, SHOW BACKUP
+compact_backup_stmt:
+  COMPACT BACKUP FROM string_or_placeholder IN string_or_placeholder_opt_list opt_with_options
+  {
+    $$.val = &tree.CompactBackup{
+      Subdir: $4.expr(),
+      To: $6.stringOrPlaceholderOptList(),
+      Options: $7.kvOptions(),
+    }
+  }
+| COMPACT BACKUP error // SHOW HELP: COMPACT BACKUP
+
+// %Help: VERIFY BACKUP - check that a backup can be restored
+// %Category: CCL
+// %Text:
+// VERIFY BACKUP FROM <subdir> IN <destination...>
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Reads every file of the backup chain in <subdir>, checking the checksums of
+// its values and the order of its keys.
+//
+// Options:
+//    fingerprint: also compare the data of each table in the backup with the table in this cluster as of the end time of the backup
+//    incremental_location: the path that stores the incremental layers
+//    encryption_passphrase="secret": decrypt the backup
+//    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt the backup using KMS
+//    detached: execute the job asynchronously, without waiting for its completion
+//
+// %SeeAlso: SHOW BACKUP, RESTORE
+verify_backup_stmt:
+  VERIFY BACKUP FROM string_or_placeholder IN string_or_placeholder_opt_list opt_with_options
+  {
+    $$.val = &tree.VerifyBackup{
+      Subdir: $4.expr(),
+      To: $6.stringOrPlaceholderOptList(),
+      Options: $7.kvOptions(),
+    }
+  }
+| VERIFY BACKUP error // SHOW HELP: VERIFY BACKUP
+
 opt_backup_targets:
   /* EMPTY -- full cluster */
   {
@@ -3329,6 +3392,22 @@ backup_options:
   {
     $$.val = &tree.BackupOptions{UpdatesClusterMonitoringMetrics: $3.expr()}
   }
+| CHANGE_LOG
+  {
+    $$.val = &tree.BackupOptions{ChangeLog: tree.MakeDBool(true)}
+  }
+| CHANGE_LOG '=' a_expr
+  {
+    $$.val = &tree.BackupOptions{ChangeLog: $3.expr()}
+  }
+| DEDUPLICATE
+  {
+    $$.val = &tree.BackupOptions{Deduplicate: tree.MakeDBool(true)}
+  }
+| DEDUPLICATE '=' a_expr
+  {
+    $$.val = &tree.BackupOptions{Deduplicate: $3.expr()}
+  }
 
 include_all_clusters:
   INCLUDE_ALL_SECONDARY_TENANTS { /* SKIP DOC */ }
@@ -4451,10 +4530,36 @@ create_stmt:
 | create_extension_stmt  // EXTEND WITH HELP: CREATE EXTENSION
 | create_external_connection_stmt // EXTEND WITH HELP: CREATE EXTERNAL CONNECTION
 | create_virtual_cluster_stmt     // EXTEND WITH HELP: CREATE VIRTUAL CLUSTER
+| create_logical_replication_stream_stmt // EXTEND WITH HELP: CREATE LOGICAL REPLICATION STREAM
 | create_schedule_stmt   // help texts in sub-rule
 | create_unsupported     {}
 | CREATE error           // SHOW HELP: CREATE
 
+// %Help: CREATE LOGICAL REPLICATION STREAM - replicate a table from another cluster
+// %Category: CCL
+// %Text:
+// CREATE LOGICAL REPLICATION STREAM FROM TABLE <source_table> ON <location>
+//        INTO TABLE <table> [ WITH <option> [= <value>] [, ...] ]
+//
+// Applies the changes made to <source_table> in the cluster at <location> to
+// <table>, which may itself be written to and replicated back to the source.
+//
+// Options:
+//    cursor="<timestamp>": replicate changes made after the timestamp, without an initial scan
+//
+// %SeeAlso: CREATE CHANGEFEED
+create_logical_replication_stream_stmt:
+  CREATE LOGICAL REPLICATION STREAM FROM TABLE db_object_name ON string_or_placeholder INTO TABLE db_object_name opt_with_options
+  {
+    $$.val = &tree.CreateLogicalReplicationStream{
+      From: $7.unresolvedObjectName().ToTableName(),
+      PGURL: $9.expr(),
+      Into: $12.unresolvedObjectName().ToTableName(),
+      Options: $13.kvOptions(),
+    }
+  }
+| CREATE LOGICAL REPLICATION error // SHOW HELP: CREATE LOGICAL REPLICATION STREAM
+
 // %Help: CREATE VIRTUAL CLUSTER - create a new virtual cluster
 // %Category: Experimental
 // %Text:
@@ -4556,6 +4661,10 @@ replication_options:
   {
     $$.val = &tree.TenantReplicationOptions{Retention: $3.expr()}
   }
+| READ VIRTUAL CLUSTER
+  {
+    $$.val = &tree.TenantReplicationOptions{EnableReaderTenant: true}
+  }
 
 // %Help: CREATE SCHEDULE
 // %Category: Group
@@ -5328,7 +5437,18 @@ changefeed_target:
     }
   }
 
-changefeed_target_expr: insert_target
+// changefeed_target_expr is the FROM clause of a changefeed expression: the
+// target table, optionally followed by lookup joins against other tables.
+changefeed_target_expr:
+  insert_target
+| changefeed_target_expr JOIN insert_target ON a_expr
+  {
+    $$.val = &tree.JoinTableExpr{Left: $1.tblExpr(), Right: $3.tblExpr(), Cond: &tree.OnJoinCond{Expr: $5.expr()}}
+  }
+| changefeed_target_expr join_type JOIN insert_target ON a_expr
+  {
+    $$.val = &tree.JoinTableExpr{JoinType: $2, Left: $1.tblExpr(), Right: $4.tblExpr(), Cond: &tree.OnJoinCond{Expr: $6.expr()}}
+  }
 
 opt_table_prefix:
   TABLE
@@ -5825,6 +5945,7 @@ preparable_stmt:
   alter_stmt     // help texts in sub-rule
 | backup_stmt    // EXTEND WITH HELP: BACKUP
 | cancel_stmt    // help texts in sub-rule
+| compact_backup_stmt // EXTEND WITH HELP: COMPACT BACKUP
 | create_stmt    // help texts in sub-rule
 | delete_stmt    // EXTEND WITH HELP: DELETE
 | drop_stmt      // help texts in sub-rule
@@ -5846,6 +5967,7 @@ preparable_stmt:
 | truncate_stmt     // EXTEND WITH HELP: TRUNCATE
 | update_stmt       // EXTEND WITH HELP: UPDATE
 | upsert_stmt       // EXTEND WITH HELP: UPSERT
+| verify_backup_stmt // EXTEND WITH HELP: VERIFY BACKUP
 
 // These are statements that can be used as a data source using the special
 // syntax with brackets. These are a subset of preparable_stmt.
@@ -5884,7 +6006,7 @@ explain_option_list:
 // %Help: ALTER CHANGEFEED - alter an existing changefeed
 // %Category: CCL
 // %Text:
-// ALTER CHANGEFEED <job_id> {{ADD|DROP <targets...>} | SET <options...>}...
+// ALTER CHANGEFEED <job_id> {{ADD|DROP <targets...>} | SET <options...> | BACKFILL <target> [WHERE <predicate>] [AS OF SYSTEM TIME <expr>]}...
 alter_changefeed_stmt:
   ALTER CHANGEFEED a_expr alter_changefeed_cmds
   {
@@ -5933,6 +6055,15 @@ alter_changefeed_cmd:
       Options: $2.nameList(),
     }
   }
+  // ALTER CHANGEFEED <job_id> BACKFILL [TABLE] ... [WHERE ...] [AS OF SYSTEM TIME ...]
+| BACKFILL changefeed_target opt_where_clause opt_as_of_clause
+  {
+    $$.val = &tree.AlterChangefeedBackfill{
+      Target: $2.changefeedTarget(),
+      Where:  tree.NewWhere(tree.AstWhere, $3.expr()),
+      AsOf:   $4.asOfClause(),
+    }
+  }
 
 // %Help: ALTER BACKUP - alter an existing backup's encryption keys
 // %Category: CCL
@@ -6746,6 +6877,7 @@ alter_virtual_cluster_service_stmt:
 // ALTER VIRTUAL CLUSTER <virtual_cluster_spec> COMPLETE REPLICATION TO LATEST
 // ALTER VIRTUAL CLUSTER <virtual_cluster_spec> COMPLETE REPLICATION TO SYSTEM TIME 'time'
 // ALTER VIRTUAL CLUSTER <virtual_cluster_spec> SET REPLICATION opt=value,...
+// ALTER VIRTUAL CLUSTER <virtual_cluster_spec> START REPLICATION OF <virtual_cluster_spec> ON <location> [ WITH OPTIONS ... ]
 alter_virtual_cluster_replication_stmt:
   ALTER virtual_cluster virtual_cluster_spec PAUSE REPLICATION
   {
@@ -6791,6 +6923,16 @@ alter_virtual_cluster_replication_stmt:
       Options: *$6.tenantReplicationOptions(),
     }
   }
+| ALTER virtual_cluster virtual_cluster_spec START REPLICATION OF d_expr ON d_expr opt_with_replication_options
+  {
+    /* SKIP DOC */
+    $$.val = &tree.AlterTenantReplication{
+      TenantSpec: $3.tenantSpec(),
+      ReplicationSourceTenantName: &tree.TenantSpec{IsName: true, Expr: $7.expr()},
+      ReplicationSourceAddress: $9.expr(),
+      Options: *$10.tenantReplicationOptions(),
+    }
+  }
 
 
 // %Help: ALTER VIRTUAL CLUSTER SETTING - alter cluster setting overrides for virtual clusters
@@ -7590,7 +7732,9 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// SHOW BACKUP TABLE <tablename> FROM <subdir> IN <collection> [AS OF SYSTEM TIME <expr>] [WHERE <expr>] [WITH <options>]
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
   SHOW BACKUPS IN string_or_placeholder_opt_list
@@ -7609,6 +7753,17 @@ show_backup_stmt:
 			Options: *$8.showBackupOptions(),
 		}
 	}
+| SHOW BACKUP TABLE table_name FROM string_or_placeholder IN string_or_placeholder_opt_list opt_as_of_clause opt_where_clause opt_with_show_backup_options
+	{
+		$$.val = &tree.ShowBackupTable{
+			Table:        $4.unresolvedObjectName(),
+			Path:         $6.expr(),
+			InCollection: $8.stringOrPlaceholderOptList(),
+			AsOf:         $9.asOfClause(),
+			Where:        tree.NewWhere(tree.AstWhere, $10.expr()),
+			Options:      *$11.showBackupOptions(),
+		}
+	}
 | SHOW BACKUP string_or_placeholder IN string_or_placeholder_opt_list opt_with_show_backup_options
 	{
 		$$.val = &tree.ShowBackup{
@@ -13299,6 +13454,23 @@ table_ref:
   {
     $$.val = &tree.AliasedTableExpr{Expr: &tree.StatementSource{ Statement: $2.stmt() }, Ordinality: $4.bool(), As: $5.aliasClause() }
   }
+// The following syntax is a CockroachDB extension:
+//     SELECT ... FROM [BACKUP 's3://...' AS OF SYSTEM TIME ts].db.table WHERE ...
+// It reads the rows of a table directly out of the files of a backup
+// collection, without restoring it.
+| '[' BACKUP string_or_placeholder_opt_list opt_as_of_clause opt_with_show_backup_options ']' '.' table_name opt_ordinality opt_alias_clause
+  {
+    $$.val = &tree.AliasedTableExpr{
+      Expr: &tree.BackupTableSource{
+        Table:        $8.unresolvedObjectName(),
+        InCollection: $3.stringOrPlaceholderOptList(),
+        AsOf:         $4.asOfClause(),
+        Options:      *$5.showBackupOptions(),
+      },
+      Ordinality: $9.bool(),
+      As:         $10.aliasClause(),
+    }
+  }
 
 numeric_table_ref:
   '[' iconst64 opt_tableref_col_list alias_clause ']'
@@ -16643,6 +16815,7 @@ unreserved_keyword:
 | ATTRIBUTE
 | AUTOMATIC
 | AVAILABILITY
+| BACKFILL
 | BACKUP
 | BACKUPS
 | BACKWARD
@@ -16662,6 +16835,7 @@ unreserved_keyword:
 | CAPABILITY
 | CASCADE
 | CHANGEFEED
+| CHANGE_LOG
 | CHECK_FILES
 | CLOSE
 | CLUSTER
@@ -16705,6 +16879,7 @@ unreserved_keyword:
 | DEBUG_PAUSE_ON
 | DEBUG_DUMP_METADATA_SST
 | DECLARE
+| DEDUPLICATE
 | DELETE
 | DEFAULTS
 | DEFERRED
@@ -16821,6 +16996,7 @@ unreserved_keyword:
 | LIST
 | LOCAL
 | LOCKED
+| LOGICAL
 | LOGIN
 | LOCALITY
 | LOOKUP
@@ -17068,6 +17244,7 @@ unreserved_keyword:
 | VALIDATE
 | VALUE
 | VARYING
+| VERIFY
 | VERIFY_BACKUP_TABLE_DATA
 | VIEW
 | VIEWACTIVITY
@@ -17118,6 +17295,7 @@ bare_label_keywords:
 | AUTHORIZATION
 | AUTOMATIC
 | AVAILABILITY
+| BACKFILL
 | BACKUP
 | BACKUPS
 | BACKWARD
@@ -17145,6 +17323,7 @@ bare_label_keywords:
 | CASE
 | CAST
 | CHANGEFEED
+| CHANGE_LOG
 | CHARACTERISTICS
 | CHECK
 | CHECK_FILES
@@ -17204,6 +17383,7 @@ bare_label_keywords:
 | DEC
 | DECIMAL
 | DECLARE
+| DEDUPLICATE
 | DEFAULT
 | DEFAULTS
 | DEFERRABLE
@@ -17364,6 +17544,7 @@ bare_label_keywords:
 | LOCALTIME
 | LOCALTIMESTAMP
 | LOCKED
+| LOGICAL
 | LOGIN
 | LOOKUP
 | LOW
@@ -17650,6 +17831,7 @@ bare_label_keywords:
 | VARBIT
 | VARCHAR
 | VARIADIC
+| VERIFY
 | VERIFY_BACKUP_TABLE_DATA
 | VIEW
 | VIEWACTIVITY
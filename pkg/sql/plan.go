@@ -16,6 +16,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/sql/auditlogging"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execstats"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
@@ -24,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/errors"
 )
 
@@ -324,6 +326,12 @@ type planTop struct {
 	// diagrams, are saved here.
 	distSQLFlowInfos []flowInfo
 
+	// externalRowDataAsOf maps each table with external row data read by this
+	// statement to the timestamp at which it is read, including by subqueries
+	// and postqueries. The timestamp of a table is pinned by the first physical
+	// plan that reads it (see pinExternalRowDataTimestamps).
+	externalRowDataAsOf map[descpb.ID]hlc.Timestamp
+
 	instrumentation *instrumentationHelper
 }
 
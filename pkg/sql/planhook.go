@@ -131,6 +131,7 @@ type PlanHookState interface {
 	Txn() *kv.Txn
 	LookupTenantInfo(ctx context.Context, tenantSpec *tree.TenantSpec, op string) (*mtinfopb.TenantInfo, error)
 	GetAvailableTenantID(ctx context.Context, name roachpb.TenantName) (roachpb.TenantID, error)
+	CreateReaderTenant(ctx context.Context, name roachpb.TenantName, readFrom roachpb.TenantID) (roachpb.TenantID, error)
 	InternalSQLTxn() descs.Txn
 }
 
@@ -449,8 +449,15 @@ func (rf *Fetcher) Init(ctx context.Context, args FetcherInitArgs) error {
 			kvPairsRead:                &kvPairsRead,
 			batchRequestsIssued:        &batchRequestsIssued,
 		}
+		if args.Spec.External != nil {
+			fetcherArgs.externalSpec = args.Spec
+		}
 		if args.Txn != nil {
-			fetcherArgs.sendFn = makeTxnKVFetcherDefaultSendFunc(args.Txn, &batchRequestsIssued)
+			if args.Spec.External != nil {
+				fetcherArgs.sendFn = makeExternalSpanSendFunc(args.Spec.External, args.Txn.DB(), &batchRequestsIssued)
+			} else {
+				fetcherArgs.sendFn = makeTxnKVFetcherDefaultSendFunc(args.Txn, &batchRequestsIssued)
+			}
 			fetcherArgs.admission.requestHeader = args.Txn.AdmissionHeader()
 			fetcherArgs.admission.responseQ = args.Txn.DB().SQLKVResponseAdmissionQ
 			fetcherArgs.admission.pacerFactory = args.Txn.DB().AdmissionPacerFactory
@@ -476,7 +483,12 @@ func (rf *Fetcher) Init(ctx context.Context, args FetcherInitArgs) error {
 // Consider using GetBatchRequestsIssued if that information is needed.
 func (rf *Fetcher) SetTxn(txn *kv.Txn) error {
 	var batchRequestsIssued int64
-	sendFn := makeTxnKVFetcherDefaultSendFunc(txn, &batchRequestsIssued)
+	var sendFn sendFunc
+	if ext := rf.args.Spec.External; ext != nil {
+		sendFn = makeExternalSpanSendFunc(ext, txn.DB(), &batchRequestsIssued)
+	} else {
+		sendFn = makeTxnKVFetcherDefaultSendFunc(txn, &batchRequestsIssued)
+	}
 	return rf.setTxnAndSendFn(txn, sendFn)
 }
 
@@ -588,6 +600,10 @@ func (rf *Fetcher) StartInconsistentScan(
 	if rf.args.WillUseKVProvider {
 		return errors.AssertionFailedf("StartInconsistentScan is called instead of ConsumeKVProvider")
 	}
+	if rf.args.Spec.External != nil {
+		return errors.Newf("inconsistent scans are not supported on table %q with external row data",
+			rf.args.Spec.TableName)
+	}
 	if len(spans) == 0 {
 		return errors.AssertionFailedf("no spans")
 	}
@@ -17,6 +17,7 @@ import (
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
@@ -31,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
 	"github.com/cockroachdb/cockroach/pkg/util/buildutil"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/errors"
@@ -174,6 +176,11 @@ type txnKVFetcher struct {
 	scanFormat     kvpb.ScanFormat
 	indexFetchSpec *fetchpb.IndexFetchSpec
 
+	// externalSpec, if set, is the fetch spec of a table with external row
+	// data. The spans are rewritten into the keyspace of the external tenant
+	// before they are read.
+	externalSpec *fetchpb.IndexFetchSpec
+
 	reverse bool
 	// lockStrength represents the locking mode to use when fetching KVs.
 	lockStrength lock.Strength
@@ -284,8 +291,77 @@ func makeTxnKVFetcherDefaultSendFunc(txn *kv.Txn, batchRequestsIssued *int64) se
 	}
 }
 
+// makeExternalSpanSendFunc returns a sendFunc that sends the batches in a
+// separate transaction with the timestamp fixed at ext.AsOf. It is used to
+// read tables with external row data, which live outside the keyspace of the
+// caller's transaction. The physical planner pins ext.AsOf so that each table
+// is read at a single timestamp for the whole statement.
+func makeExternalSpanSendFunc(
+	ext *descpb.ExternalRowData, db *kv.DB, batchRequestsIssued *int64,
+) sendFunc {
+	return func(ctx context.Context, ba *kvpb.BatchRequest) (*kvpb.BatchResponse, error) {
+		if !ba.IsReadOnly() || ba.IsLocking() {
+			return nil, errors.AssertionFailedf(
+				"unexpected non-read-only or locking batch for external row data: %s", ba)
+		}
+		log.VEventf(ctx, 2, "reading external row data of tenant %s as of %s", ext.TenantID, ext.AsOf)
+		txn := kv.NewTxn(ctx, db, 0 /* gatewayNodeID */)
+		if err := txn.SetFixedTimestamp(ctx, ext.AsOf); err != nil {
+			return nil, err
+		}
+		res, err := txn.Send(ctx, ba)
+		if err != nil {
+			return nil, err.GoError()
+		}
+		*batchRequestsIssued++
+		return res, nil
+	}
+}
+
+// rewriteExternalSpans rewrites, in place, spans over the given table in the
+// local keyspace into the corresponding spans in the keyspace of the tenant
+// and table described by ext.
+func rewriteExternalSpans(
+	spans roachpb.Spans, localTableID descpb.ID, ext *descpb.ExternalRowData,
+) error {
+	prefix := keys.MakeSQLCodec(ext.TenantID).TablePrefix(uint32(ext.TableID))
+	rewriteKey := func(k roachpb.Key) (roachpb.Key, error) {
+		rest, _, err := keys.DecodeTenantPrefix(k)
+		if err != nil {
+			return nil, err
+		}
+		rest, tableID, err := encoding.DecodeUvarintAscending(rest)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case descpb.ID(tableID) == localTableID:
+			newKey := make(roachpb.Key, 0, len(prefix)+len(rest))
+			return append(append(newKey, prefix...), rest...), nil
+		case descpb.ID(tableID) > localTableID && len(rest) == 0:
+			// This is the end key of a span covering the whole table.
+			return prefix.PrefixEnd(), nil
+		default:
+			return nil, errors.AssertionFailedf("key %s is outside of table %d", k, localTableID)
+		}
+	}
+	for i := range spans {
+		var err error
+		if spans[i].Key, err = rewriteKey(spans[i].Key); err != nil {
+			return err
+		}
+		if len(spans[i].EndKey) > 0 {
+			if spans[i].EndKey, err = rewriteKey(spans[i].EndKey); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 type newTxnKVFetcherArgs struct {
 	sendFn                     sendFunc
+	externalSpec               *fetchpb.IndexFetchSpec
 	reverse                    bool
 	lockStrength               descpb.ScanLockingStrength
 	lockWaitPolicy             descpb.ScanLockingWaitPolicy
@@ -311,7 +387,8 @@ type newTxnKVFetcherArgs struct {
 // is non-nil.
 func newTxnKVFetcherInternal(args newTxnKVFetcherArgs) *txnKVFetcher {
 	f := &txnKVFetcher{
-		sendFn: args.sendFn,
+		sendFn:       args.sendFn,
+		externalSpec: args.externalSpec,
 		// Default to BATCH_RESPONSE. The caller will override if needed.
 		scanFormat:                 kvpb.BATCH_RESPONSE,
 		reverse:                    args.reverse,
@@ -489,6 +566,12 @@ func (f *txnKVFetcher) SetupNextFetch(
 	f.batchBytesLimit = batchBytesLimit
 	f.firstBatchKeyLimit = firstBatchKeyLimit
 
+	if f.externalSpec != nil {
+		if err := rewriteExternalSpans(spans, f.externalSpec.TableID, f.externalSpec.External); err != nil {
+			return err
+		}
+	}
+
 	// Account for the memory of the spans that we're taking the ownership of.
 	if f.acc != nil {
 		newSpansAccountedFor := spans.MemUsage()
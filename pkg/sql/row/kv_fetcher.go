@@ -46,6 +46,10 @@ var _ storage.NextKVer = &KVFetcher{}
 
 // newTxnKVFetcher creates a new txnKVFetcher.
 //
+// If spec is non-nil and describes a table with external row data, the reads
+// are served from the external keyspace at the fixed timestamp of the external
+// row data rather than by txn.
+//
 // If acc is non-nil, this fetcher will track its fetches and must be Closed.
 // The fetcher only grows and shrinks the account according to its own use, so
 // the memory account can be shared by the caller with other components (as long
@@ -53,6 +57,7 @@ var _ storage.NextKVer = &KVFetcher{}
 func newTxnKVFetcher(
 	txn *kv.Txn,
 	bsHeader *kvpb.BoundedStalenessHeader,
+	spec *fetchpb.IndexFetchSpec,
 	reverse bool,
 	lockStrength descpb.ScanLockingStrength,
 	lockWaitPolicy descpb.ScanLockingWaitPolicy,
@@ -63,8 +68,12 @@ func newTxnKVFetcher(
 ) *txnKVFetcher {
 	var sendFn sendFunc
 	var batchRequestsIssued int64
+	var externalSpec *fetchpb.IndexFetchSpec
 	// Avoid the heap allocation by allocating sendFn specifically in the if.
-	if bsHeader == nil {
+	if spec != nil && spec.External != nil {
+		externalSpec = spec
+		sendFn = makeExternalSpanSendFunc(spec.External, txn.DB(), &batchRequestsIssued)
+	} else if bsHeader == nil {
 		sendFn = makeTxnKVFetcherDefaultSendFunc(txn, &batchRequestsIssued)
 	} else {
 		negotiated := false
@@ -91,6 +100,7 @@ func newTxnKVFetcher(
 
 	fetcherArgs := newTxnKVFetcherArgs{
 		sendFn:                     sendFn,
+		externalSpec:               externalSpec,
 		reverse:                    reverse,
 		lockStrength:               lockStrength,
 		lockWaitPolicy:             lockWaitPolicy,
@@ -130,7 +140,7 @@ func NewDirectKVBatchFetcher(
 	forceProductionKVBatchSize bool,
 ) KVBatchFetcher {
 	f := newTxnKVFetcher(
-		txn, bsHeader, reverse, lockStrength, lockWaitPolicy, lockDurability,
+		txn, bsHeader, spec, reverse, lockStrength, lockWaitPolicy, lockDurability,
 		lockTimeout, acc, forceProductionKVBatchSize,
 	)
 	f.scanFormat = kvpb.COL_BATCH_RESPONSE
@@ -138,7 +148,8 @@ func NewDirectKVBatchFetcher(
 	return f
 }
 
-// NewKVFetcher creates a new KVFetcher.
+// NewKVFetcher creates a new KVFetcher. spec is only consulted for whether
+// the table has external row data and may be nil.
 //
 // If acc is non-nil, this fetcher will track its fetches and must be Closed.
 // The fetcher only grows and shrinks the account according to its own use, so
@@ -147,6 +158,7 @@ func NewDirectKVBatchFetcher(
 func NewKVFetcher(
 	txn *kv.Txn,
 	bsHeader *kvpb.BoundedStalenessHeader,
+	spec *fetchpb.IndexFetchSpec,
 	reverse bool,
 	lockStrength descpb.ScanLockingStrength,
 	lockWaitPolicy descpb.ScanLockingWaitPolicy,
@@ -156,7 +168,7 @@ func NewKVFetcher(
 	forceProductionKVBatchSize bool,
 ) *KVFetcher {
 	return newKVFetcher(newTxnKVFetcher(
-		txn, bsHeader, reverse, lockStrength, lockWaitPolicy, lockDurability,
+		txn, bsHeader, spec, reverse, lockStrength, lockWaitPolicy, lockDurability,
 		lockTimeout, acc, forceProductionKVBatchSize,
 	))
 }
@@ -48,10 +48,19 @@ func InitIndexFetchSpec(
 		GeoConfig:           index.GetGeoConfig(),
 	}
 
+	// Tables with external row data are read from another tenant's keyspace,
+	// so the key prefix is computed with that tenant's codec.
+	keyTableID := s.TableID
+	if ext := table.ExternalRowData(); ext != nil {
+		s.External = ext
+		codec = keys.MakeSQLCodec(ext.TenantID)
+		keyTableID = ext.TableID
+	}
+
 	maxKeysPerRow := table.IndexKeysPerRow(index)
 	s.MaxKeysPerRow = uint32(maxKeysPerRow)
 	s.KeyPrefixLength = uint32(len(codec.TenantPrefix()) +
-		encoding.EncodedLengthUvarintAscending(uint64(s.TableID)) +
+		encoding.EncodedLengthUvarintAscending(uint64(keyTableID)) +
 		encoding.EncodedLengthUvarintAscending(uint64(index.GetID())))
 
 	s.FamilyDefaultColumns = table.FamilyDefaultColumns()
@@ -346,6 +346,9 @@ func newJoinReader(
 	if err != nil {
 		return nil, err
 	}
+	// The streamer reads through txn, which cannot see the keyspace of a table
+	// with external row data.
+	useStreamer = useStreamer && spec.FetchSpec.External == nil
 
 	errorOnLookup := spec.RemoteOnlyLookups &&
 		flowCtx.EvalCtx.Planner != nil && flowCtx.EvalCtx.Planner.EnforceHomeRegion()
@@ -812,8 +812,18 @@ type StreamManagerFactory interface {
 // on the production side.
 type ReplicationStreamManager interface {
 	// StartReplicationStream starts a stream replication job for the specified
-	// tenant on the producer side.
-	StartReplicationStream(ctx context.Context, tenantName roachpb.TenantName) (streampb.ReplicationProducerSpec, error)
+	// tenant on the producer side. The request identifies the consumer and is
+	// only set when a former source of the tenant is failing back.
+	StartReplicationStream(
+		ctx context.Context, tenantName roachpb.TenantName, req streampb.ReplicationProducerRequest,
+	) (streampb.ReplicationProducerSpec, error)
+
+	// StartReplicationStreamForTables starts a stream replication job on the
+	// producer side for the tables named in the request, which belong to the
+	// current tenant.
+	StartReplicationStreamForTables(
+		ctx context.Context, req streampb.ReplicationProducerRequest,
+	) (streampb.ReplicationProducerSpec, error)
 
 	// SetupSpanConfigsStream creates and plans a replication stream to stream the span config updates for a specific tenant.
 	SetupSpanConfigsStream(ctx context.Context, tenantName roachpb.TenantName) (ValueGenerator, error)
@@ -50,11 +50,13 @@ func (*AlterChangefeedAddTarget) alterChangefeedCmd()    {}
 func (*AlterChangefeedDropTarget) alterChangefeedCmd()   {}
 func (*AlterChangefeedSetOptions) alterChangefeedCmd()   {}
 func (*AlterChangefeedUnsetOptions) alterChangefeedCmd() {}
+func (*AlterChangefeedBackfill) alterChangefeedCmd()     {}
 
 var _ AlterChangefeedCmd = &AlterChangefeedAddTarget{}
 var _ AlterChangefeedCmd = &AlterChangefeedDropTarget{}
 var _ AlterChangefeedCmd = &AlterChangefeedSetOptions{}
 var _ AlterChangefeedCmd = &AlterChangefeedUnsetOptions{}
+var _ AlterChangefeedCmd = &AlterChangefeedBackfill{}
 
 // AlterChangefeedAddTarget represents an ADD <targets> command
 type AlterChangefeedAddTarget struct {
@@ -104,3 +106,24 @@ func (node *AlterChangefeedUnsetOptions) Format(ctx *FmtCtx) {
 	ctx.WriteString(" UNSET ")
 	ctx.FormatNode(&node.Options)
 }
+
+// AlterChangefeedBackfill represents a BACKFILL <target> command
+type AlterChangefeedBackfill struct {
+	Target ChangefeedTarget
+	Where  *Where
+	AsOf   AsOfClause
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterChangefeedBackfill) Format(ctx *FmtCtx) {
+	ctx.WriteString(" BACKFILL ")
+	ctx.FormatNode(&node.Target)
+	if node.Where != nil {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(node.Where)
+	}
+	if node.AsOf.Expr != nil {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(&node.AsOf)
+	}
+}
@@ -48,6 +48,8 @@ type BackupOptions struct {
 	IncrementalStorage              StringOrPlaceholderOptList
 	ExecutionLocality               Expr
 	UpdatesClusterMonitoringMetrics Expr
+	ChangeLog                       Expr
+	Deduplicate                     Expr
 }
 
 var _ NodeFormatter = &BackupOptions{}
@@ -126,6 +128,56 @@ func (node Backup) Coverage() DescriptorCoverage {
 	return RequestedDescriptors
 }
 
+// CompactBackup represents a COMPACT BACKUP statement, which merges layers of
+// an existing backup chain into a new layer.
+type CompactBackup struct {
+	// Subdir is the full backup in the collection whose chain is compacted.
+	Subdir Expr
+	// To is set to the root directory of the backup collection.
+	To      StringOrPlaceholderOptList
+	Options KVOptions
+}
+
+var _ Statement = &CompactBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *CompactBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("COMPACT BACKUP FROM ")
+	ctx.FormatNode(node.Subdir)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(&node.To)
+	if node.Options != nil {
+		ctx.WriteString(" WITH OPTIONS (")
+		ctx.FormatNode(&node.Options)
+		ctx.WriteString(")")
+	}
+}
+
+// VerifyBackup represents a VERIFY BACKUP statement, which reads the files of
+// a backup chain to check that it can be restored.
+type VerifyBackup struct {
+	// Subdir is the full backup in the collection whose chain is verified.
+	Subdir Expr
+	// To is set to the root directory of the backup collection.
+	To      StringOrPlaceholderOptList
+	Options KVOptions
+}
+
+var _ Statement = &VerifyBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *VerifyBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("VERIFY BACKUP FROM ")
+	ctx.FormatNode(node.Subdir)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(&node.To)
+	if node.Options != nil {
+		ctx.WriteString(" WITH OPTIONS (")
+		ctx.FormatNode(&node.Options)
+		ctx.WriteString(")")
+	}
+}
+
 // RestoreOptions describes options for the RESTORE execution.
 type RestoreOptions struct {
 	EncryptionPassphrase             Expr
@@ -327,6 +379,18 @@ func (o *BackupOptions) Format(ctx *FmtCtx) {
 		ctx.WriteString("updates_cluster_monitoring_metrics = ")
 		ctx.FormatNode(o.UpdatesClusterMonitoringMetrics)
 	}
+
+	if o.ChangeLog != nil {
+		maybeAddSep()
+		ctx.WriteString("change_log = ")
+		ctx.FormatNode(o.ChangeLog)
+	}
+
+	if o.Deduplicate != nil {
+		maybeAddSep()
+		ctx.WriteString("deduplicate = ")
+		ctx.FormatNode(o.Deduplicate)
+	}
 }
 
 // CombineWith merges other backup options into this backup options struct.
@@ -387,6 +451,22 @@ func (o *BackupOptions) CombineWith(other *BackupOptions) error {
 	} else {
 		o.UpdatesClusterMonitoringMetrics = other.UpdatesClusterMonitoringMetrics
 	}
+
+	if o.ChangeLog != nil {
+		if other.ChangeLog != nil {
+			return errors.New("change_log option specified multiple times")
+		}
+	} else {
+		o.ChangeLog = other.ChangeLog
+	}
+
+	if o.Deduplicate != nil {
+		if other.Deduplicate != nil {
+			return errors.New("deduplicate option specified multiple times")
+		}
+	} else {
+		o.Deduplicate = other.Deduplicate
+	}
 	return nil
 }
 
@@ -400,7 +480,9 @@ func (o BackupOptions) IsDefault() bool {
 		cmp.Equal(o.IncrementalStorage, options.IncrementalStorage) &&
 		o.ExecutionLocality == options.ExecutionLocality &&
 		o.IncludeAllSecondaryTenants == options.IncludeAllSecondaryTenants &&
-		o.UpdatesClusterMonitoringMetrics == options.UpdatesClusterMonitoringMetrics
+		o.UpdatesClusterMonitoringMetrics == options.UpdatesClusterMonitoringMetrics &&
+		o.ChangeLog == options.ChangeLog &&
+		o.Deduplicate == options.Deduplicate
 }
 
 // Format implements the NodeFormatter interface.
@@ -100,7 +100,8 @@ func (cts *ChangefeedTargets) Format(ctx *FmtCtx) {
 }
 
 // ChangefeedTargetFromTableExpr returns ChangefeedTarget for the
-// specified table expression.
+// specified table expression. If the expression contains lookup joins,
+// the target is the left-most table of the join.
 func ChangefeedTargetFromTableExpr(e TableExpr) (ChangefeedTarget, error) {
 	switch t := e.(type) {
 	case TablePattern:
@@ -109,6 +110,8 @@ func ChangefeedTargetFromTableExpr(e TableExpr) (ChangefeedTarget, error) {
 		if tn, ok := t.Expr.(*TableName); ok {
 			return ChangefeedTarget{TableName: tn}, nil
 		}
+	case *JoinTableExpr:
+		return ChangefeedTargetFromTableExpr(t.Left)
 	}
 	return ChangefeedTarget{}, pgerror.Newf(
 		pgcode.InvalidName, "unsupported changefeed target type")
@@ -2236,6 +2236,9 @@ type CreateTenantFromReplication struct {
 // TenantReplicationOptions  options for the CREATE VIRTUAL CLUSTER FROM REPLICATION command.
 type TenantReplicationOptions struct {
 	Retention Expr
+	// EnableReaderTenant, if set, creates a companion reader virtual cluster
+	// that serves read-only queries of the replicated data.
+	EnableReaderTenant bool
 }
 
 var _ NodeFormatter = &TenantReplicationOptions{}
@@ -2284,6 +2287,12 @@ func (o *TenantReplicationOptions) Format(ctx *FmtCtx) {
 			ctx.WriteByte(')')
 		}
 	}
+	if o.EnableReaderTenant {
+		if o.Retention != nil {
+			ctx.WriteString(", ")
+		}
+		ctx.WriteString("READ VIRTUAL CLUSTER")
+	}
 }
 
 // CombineWith merges other TenantReplicationOptions into this struct.
@@ -2296,13 +2305,21 @@ func (o *TenantReplicationOptions) CombineWith(other *TenantReplicationOptions)
 	} else {
 		o.Retention = other.Retention
 	}
+	if o.EnableReaderTenant {
+		if other.EnableReaderTenant {
+			return errors.New("READ VIRTUAL CLUSTER option specified multiple times")
+		}
+	} else {
+		o.EnableReaderTenant = other.EnableReaderTenant
+	}
 	return nil
 }
 
 // IsDefault returns true if this backup options struct has default value.
 func (o TenantReplicationOptions) IsDefault() bool {
 	options := TenantReplicationOptions{}
-	return o.Retention == options.Retention
+	return o.Retention == options.Retention &&
+		o.EnableReaderTenant == options.EnableReaderTenant
 }
 
 type SuperRegion struct {
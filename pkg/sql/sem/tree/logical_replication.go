@@ -0,0 +1,40 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// CreateLogicalReplicationStream represents a CREATE LOGICAL REPLICATION
+// STREAM statement.
+type CreateLogicalReplicationStream struct {
+	// From is the name of the table in the source cluster.
+	From TableName
+	// PGURL is the address of the source cluster.
+	PGURL Expr
+	// Into is the name of the table in this cluster.
+	Into    TableName
+	Options KVOptions
+}
+
+var _ Statement = &CreateLogicalReplicationStream{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateLogicalReplicationStream) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE LOGICAL REPLICATION STREAM FROM TABLE ")
+	ctx.FormatNode(&node.From)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(node.PGURL)
+	ctx.WriteString(" INTO TABLE ")
+	ctx.FormatNode(&node.Into)
+	if node.Options != nil {
+		ctx.WriteString(" WITH OPTIONS (")
+		ctx.FormatNode(&node.Options)
+		ctx.WriteString(")")
+	}
+}
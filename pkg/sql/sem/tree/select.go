@@ -284,12 +284,13 @@ type TableExpr interface {
 	WalkTableExpr(Visitor) TableExpr
 }
 
-func (*AliasedTableExpr) tableExpr() {}
-func (*ParenTableExpr) tableExpr()   {}
-func (*JoinTableExpr) tableExpr()    {}
-func (*RowsFromExpr) tableExpr()     {}
-func (*Subquery) tableExpr()         {}
-func (*StatementSource) tableExpr()  {}
+func (*AliasedTableExpr) tableExpr()  {}
+func (*ParenTableExpr) tableExpr()    {}
+func (*JoinTableExpr) tableExpr()     {}
+func (*RowsFromExpr) tableExpr()      {}
+func (*Subquery) tableExpr()          {}
+func (*StatementSource) tableExpr()   {}
+func (*BackupTableSource) tableExpr() {}
 
 // StatementSource encapsulates one of the other statements as a data source.
 type StatementSource struct {
@@ -303,6 +304,47 @@ func (node *StatementSource) Format(ctx *FmtCtx) {
 	ctx.WriteByte(']')
 }
 
+// BackupTableSource is a table read directly out of the files of a backup
+// collection, without restoring it:
+//
+//	[BACKUP 's3://...' AS OF SYSTEM TIME ts].db.table
+type BackupTableSource struct {
+	Table        *UnresolvedObjectName
+	InCollection StringOrPlaceholderOptList
+	AsOf         AsOfClause
+	Options      ShowBackupOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *BackupTableSource) Format(ctx *FmtCtx) {
+	ctx.WriteString("[BACKUP ")
+	ctx.FormatNode(&node.InCollection)
+	if node.AsOf.Expr != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(&node.AsOf)
+	}
+	if !node.Options.IsDefault() {
+		ctx.WriteString(" WITH OPTIONS (")
+		ctx.FormatNode(&node.Options)
+		ctx.WriteString(")")
+	}
+	ctx.WriteString("].")
+	ctx.FormatNode(node.Table)
+}
+
+// ShowBackupTable returns the statement which reads the table out of the
+// backup collection. The table is read out of the latest backup in the
+// collection, or the latest one which covers the AS OF SYSTEM TIME if set.
+func (node *BackupTableSource) ShowBackupTable() *ShowBackupTable {
+	return &ShowBackupTable{
+		Table:        node.Table,
+		Path:         NewStrVal("LATEST"),
+		InCollection: node.InCollection,
+		AsOf:         node.AsOf,
+		Options:      node.Options,
+	}
+}
+
 // IndexID is a custom type for IndexDescriptor IDs.
 type IndexID = catid.IndexID
 
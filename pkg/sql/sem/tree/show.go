@@ -145,6 +145,42 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 	}
 }
 
+// ShowBackupTable represents a SHOW BACKUP TABLE statement, which returns the
+// rows of a table in a backup without restoring it. The rows may be filtered
+// by a WHERE clause, which also restricts the primary key spans read out of
+// the backup.
+type ShowBackupTable struct {
+	Table        *UnresolvedObjectName
+	Path         Expr
+	InCollection StringOrPlaceholderOptList
+	AsOf         AsOfClause
+	Where        *Where
+	Options      ShowBackupOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowBackupTable) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW BACKUP TABLE ")
+	ctx.FormatNode(node.Table)
+	ctx.WriteString(" FROM ")
+	ctx.FormatNode(node.Path)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(&node.InCollection)
+	if node.AsOf.Expr != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(&node.AsOf)
+	}
+	if node.Where != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(node.Where)
+	}
+	if !node.Options.IsDefault() {
+		ctx.WriteString(" WITH OPTIONS (")
+		ctx.FormatNode(&node.Options)
+		ctx.WriteString(")")
+	}
+}
+
 type ShowBackupOptions struct {
 	AsJson               bool
 	CheckFiles           bool
@@ -173,7 +173,7 @@ func CanWriteData(stmt Statement) bool {
 	case *Split, *Unsplit, *Relocate, *RelocateRange, *Scatter:
 		return true
 	// Replication operations.
-	case *CreateTenantFromReplication, *AlterTenantReplication:
+	case *CreateTenantFromReplication, *AlterTenantReplication, *CreateLogicalReplicationStream:
 		return true
 	}
 	return false
@@ -194,7 +194,7 @@ func ReturnsAtMostOneRow(stmt Statement) bool {
 	case *Scatter:
 		return true
 	// Replication operations.
-	case *CreateTenantFromReplication, *AlterTenantReplication:
+	case *CreateTenantFromReplication, *AlterTenantReplication, *CreateLogicalReplicationStream:
 		return true
 	}
 	return false
@@ -235,6 +235,8 @@ type CCLOnlyStatement interface {
 var _ CCLOnlyStatement = &AlterBackup{}
 var _ CCLOnlyStatement = &AlterBackupSchedule{}
 var _ CCLOnlyStatement = &Backup{}
+var _ CCLOnlyStatement = &CompactBackup{}
+var _ CCLOnlyStatement = &VerifyBackup{}
 var _ CCLOnlyStatement = &ShowBackup{}
 var _ CCLOnlyStatement = &Restore{}
 var _ CCLOnlyStatement = &CreateChangefeed{}
@@ -243,6 +245,7 @@ var _ CCLOnlyStatement = &Import{}
 var _ CCLOnlyStatement = &Export{}
 var _ CCLOnlyStatement = &ScheduledBackup{}
 var _ CCLOnlyStatement = &CreateTenantFromReplication{}
+var _ CCLOnlyStatement = &CreateLogicalReplicationStream{}
 
 // StatementReturnType implements the Statement interface.
 func (*AlterChangefeed) StatementReturnType() StatementReturnType { return Rows }
@@ -802,6 +805,17 @@ func (*CommitTransaction) StatementType() StatementType { return TypeTCL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CommitTransaction) StatementTag() string { return "COMMIT" }
 
+// StatementReturnType implements the Statement interface.
+func (*CompactBackup) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*CompactBackup) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CompactBackup) StatementTag() string { return "COMPACT BACKUP" }
+
+func (*CompactBackup) cclOnlyStatement() {}
+
 // StatementReturnType implements the Statement interface.
 func (*CopyFrom) StatementReturnType() StatementReturnType { return CopyIn }
 
@@ -836,6 +850,19 @@ func (n *CreateChangefeed) StatementTag() string {
 
 func (*CreateChangefeed) cclOnlyStatement() {}
 
+// StatementReturnType implements the Statement interface.
+func (*CreateLogicalReplicationStream) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*CreateLogicalReplicationStream) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateLogicalReplicationStream) StatementTag() string {
+	return "CREATE LOGICAL REPLICATION STREAM"
+}
+
+func (*CreateLogicalReplicationStream) cclOnlyStatement() {}
+
 // StatementReturnType implements the Statement interface.
 func (*ScheduledChangefeed) StatementReturnType() StatementReturnType { return Rows }
 
@@ -1640,6 +1667,17 @@ func (*ShowBackup) StatementTag() string { return "SHOW BACKUP" }
 
 func (*ShowBackup) cclOnlyStatement() {}
 
+// StatementReturnType implements the Statement interface.
+func (*ShowBackupTable) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*ShowBackupTable) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowBackupTable) StatementTag() string { return "SHOW BACKUP TABLE" }
+
+func (*ShowBackupTable) cclOnlyStatement() {}
+
 // StatementReturnType implements the Statement interface.
 func (*ShowDatabases) StatementReturnType() StatementReturnType { return Rows }
 
@@ -2103,6 +2141,17 @@ func (*ValuesClause) StatementType() StatementType { return TypeDML }
 // StatementTag returns a short string identifying the type of statement.
 func (*ValuesClause) StatementTag() string { return "VALUES" }
 
+// StatementReturnType implements the Statement interface.
+func (*VerifyBackup) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*VerifyBackup) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*VerifyBackup) StatementTag() string { return "VERIFY BACKUP" }
+
+func (*VerifyBackup) cclOnlyStatement() {}
+
 // StatementReturnType implements the Statement interface.
 func (*CreateRoutine) StatementReturnType() StatementReturnType { return DDL }
 
@@ -2244,11 +2293,13 @@ func (n *CommentOnSchema) String() string                     { return AsString(
 func (n *CommentOnIndex) String() string                      { return AsString(n) }
 func (n *CommentOnTable) String() string                      { return AsString(n) }
 func (n *CommitTransaction) String() string                   { return AsString(n) }
+func (n *CompactBackup) String() string                       { return AsString(n) }
 func (n *CopyFrom) String() string                            { return AsString(n) }
 func (n *CopyTo) String() string                              { return AsString(n) }
 func (n *CreateChangefeed) String() string                    { return AsString(n) }
 func (n *CreateDatabase) String() string                      { return AsString(n) }
 func (n *CreateExtension) String() string                     { return AsString(n) }
+func (n *CreateLogicalReplicationStream) String() string      { return AsString(n) }
 func (n *CreateRoutine) String() string                       { return AsString(n) }
 func (n *CreateIndex) String() string                         { return AsString(n) }
 func (n *CreateRole) String() string                          { return AsString(n) }
@@ -2318,6 +2369,7 @@ func (n *SetTransaction) String() string                      { return AsString(
 func (n *SetTracing) String() string                          { return AsString(n) }
 func (n *SetVar) String() string                              { return AsString(n) }
 func (n *ShowBackup) String() string                          { return AsString(n) }
+func (n *ShowBackupTable) String() string                     { return AsString(n) }
 func (n *ShowClusterSetting) String() string                  { return AsString(n) }
 func (n *ShowClusterSettingList) String() string              { return AsString(n) }
 func (n *ShowTenantClusterSetting) String() string            { return AsString(n) }
@@ -2378,3 +2430,4 @@ func (n *UnionClause) String() string                         { return AsString(
 func (n *Unsplit) String() string                             { return AsString(n) }
 func (n *Update) String() string                              { return AsString(n) }
 func (n *ValuesClause) String() string                        { return AsString(n) }
+func (n *VerifyBackup) String() string                        { return AsString(n) }
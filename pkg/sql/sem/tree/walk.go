@@ -599,6 +599,9 @@ func (expr *StatementSource) WalkTableExpr(v Visitor) TableExpr {
 	return expr
 }
 
+// WalkTableExpr implements the TableExpr interface.
+func (expr *BackupTableSource) WalkTableExpr(_ Visitor) TableExpr { return expr }
+
 // WalkTableExpr implements the TableExpr interface.
 func (expr *TableName) WalkTableExpr(_ Visitor) TableExpr { return expr }
 
@@ -1032,6 +1035,22 @@ func (n *AlterTenantReplication) walkStmt(v Visitor) Statement {
 			ret.Cutover.Timestamp = e
 		}
 	}
+	if n.ReplicationSourceAddress != nil {
+		ts, changed := walkTenantSpec(v, n.ReplicationSourceTenantName)
+		if changed {
+			if ret == n {
+				ret = n.copyNode()
+			}
+			ret.ReplicationSourceTenantName = ts
+		}
+		e, changed := WalkExpr(v, n.ReplicationSourceAddress)
+		if changed {
+			if ret == n {
+				ret = n.copyNode()
+			}
+			ret.ReplicationSourceAddress = e
+		}
+	}
 	if n.Options.Retention != nil {
 		e, changed := WalkExpr(v, n.Options.Retention)
 		if changed {
@@ -17,6 +17,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -116,6 +117,23 @@ func init() {
 			requiredType: types.Int,
 			setter:       func(c *zonepb.ZoneConfig, d tree.Datum) { c.NumVoters = proto.Int32(int32(tree.MustBeDInt(d))) },
 		},
+		{
+			field:        config.NumWitnesses,
+			requiredType: types.Int,
+			setter:       func(c *zonepb.ZoneConfig, d tree.Datum) { c.NumWitnesses = proto.Int32(int32(tree.MustBeDInt(d))) },
+			checkAllowed: func(ctx context.Context, execCfg *ExecutorConfig, d tree.Datum) error {
+				if tree.MustBeDInt(d) == 0 {
+					// Always allow witnesses to be removed.
+					return nil
+				}
+				if !execCfg.Settings.Version.IsActive(ctx, clusterversion.V23_2_WitnessReplicas) {
+					return pgerror.Newf(pgcode.FeatureNotSupported,
+						"num_witnesses cannot be set until the cluster is upgraded to %s",
+						clusterversion.ByKey(clusterversion.V23_2_WitnessReplicas))
+				}
+				return nil
+			},
+		},
 		{
 			field:        config.GCTTL,
 			requiredType: types.Int,
@@ -123,6 +141,23 @@ func init() {
 				c.GC = &zonepb.GCPolicy{TTLSeconds: int32(tree.MustBeDInt(d))}
 			},
 		},
+		{
+			field:        config.StorageTTL,
+			requiredType: types.Int,
+			setter:       func(c *zonepb.ZoneConfig, d tree.Datum) { c.StorageTTLSeconds = proto.Int32(int32(tree.MustBeDInt(d))) },
+			checkAllowed: func(ctx context.Context, execCfg *ExecutorConfig, d tree.Datum) error {
+				if tree.MustBeDInt(d) == 0 {
+					// Always allow the storage TTL to be removed.
+					return nil
+				}
+				if !execCfg.Settings.Version.IsActive(ctx, clusterversion.V23_2_StorageTTL) {
+					return pgerror.Newf(pgcode.FeatureNotSupported,
+						"storage_ttl_seconds cannot be set until the cluster is upgraded to %s",
+						clusterversion.ByKey(clusterversion.V23_2_StorageTTL))
+				}
+				return nil
+			},
+		},
 		{
 			field:        config.Constraints,
 			requiredType: types.String,
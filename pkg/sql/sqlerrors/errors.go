@@ -47,6 +47,14 @@ func NewSchemaChangeOnLockedTableErr(tableName string) error {
 			"\"ALTER TABLE %v SET (schema_locked = true);\"", tableName, tableName)
 }
 
+// NewModifyExternalRowDataTableErr creates an error signaling that a
+// statement attempted to modify a table whose row data is read from another
+// tenant, such as a table in the reader tenant of a replication stream.
+func NewModifyExternalRowDataTableErr(tableName string) error {
+	return pgerror.Newf(pgcode.ReadOnlySQLTransaction,
+		`table %q is read-only because its data is read from another virtual cluster`, tableName)
+}
+
 // NewTransactionAbortedError creates an error for trying to run a command in
 // the context of transaction that's in the aborted state. Any statement other
 // than ROLLBACK TO SAVEPOINT will return this error.
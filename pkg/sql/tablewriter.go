@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -163,6 +164,9 @@ func (tb *tableWriterBase) init(
 	if txn.Type() != kv.RootTxn {
 		return errors.AssertionFailedf("unexpectedly non-root txn is used by the table writer")
 	}
+	if tableDesc.ExternalRowData() != nil {
+		return sqlerrors.NewModifyExternalRowDataTableErr(tableDesc.GetName())
+	}
 	tb.txn = txn
 	tb.desc = tableDesc
 	tb.lockTimeout = 0
@@ -76,6 +76,23 @@ func (p *planner) CreateTenant(
 	return p.createTenantInternal(ctx, ctcfg, &configTemplate)
 }
 
+// CreateReaderTenant is part of the PlanHookState interface. It creates a
+// tenant with shared service mode that is allowed to read the keyspace of
+// readFrom. It is used for the reader tenant of a replication stream into
+// readFrom.
+func (p *planner) CreateReaderTenant(
+	ctx context.Context, name roachpb.TenantName, readFrom roachpb.TenantID,
+) (roachpb.TenantID, error) {
+	nameStr := string(name)
+	serviceMode := mtinfopb.ServiceModeShared.String()
+	configTemplate := mtinfopb.TenantInfoWithUsage{}
+	configTemplate.Capabilities.ReadFromTenant = &readFrom
+	return p.createTenantInternal(ctx, createTenantConfig{
+		Name:        &nameStr,
+		ServiceMode: &serviceMode,
+	}, &configTemplate)
+}
+
 type createTenantConfig struct {
 	ID          *uint64 `json:"id,omitempty"`
 	Name        *string `json:"name,omitempty"`
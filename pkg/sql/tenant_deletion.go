@@ -69,6 +69,42 @@ func (p *planner) DropTenantByID(
 	)
 }
 
+// DropReaderTenant revokes the capability of the reader tenant of a
+// replication stream to read the replicated tenant, and drops it. It is called
+// when the stream is cut over, canceled or fails, after which the replicated
+// data the reader tenant serves is neither kept up to date nor protected from
+// garbage collection. It is a no-op if the reader tenant was already dropped.
+func DropReaderTenant(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	txn isql.Txn,
+	user username.SQLUsername,
+	readerTenantID roachpb.TenantID,
+) error {
+	info, err := GetTenantRecordByID(ctx, txn, readerTenantID, execCfg.Settings)
+	if err != nil {
+		if pgerror.GetPGCode(err) == pgcode.UndefinedObject {
+			return nil
+		}
+		return err
+	}
+	if info.DataState == mtinfopb.DataStateDrop {
+		return nil
+	}
+	info.Capabilities.ReadFromTenant = nil
+	return dropTenantInternal(
+		ctx,
+		execCfg.Settings,
+		txn,
+		execCfg.JobRegistry,
+		nil, /* sessionJobs */
+		user,
+		info,
+		false, /* synchronousImmediateDrop */
+		true,  /* ignoreServiceMode */
+	)
+}
+
 func dropTenantInternal(
 	ctx context.Context,
 	settings *cluster.Settings,
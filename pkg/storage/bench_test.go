@@ -133,6 +133,32 @@ func BenchmarkMVCCGarbageCollect(b *testing.B) {
 	}
 }
 
+// BenchmarkStorageTTLExpiry compares the bytes written to the engine to
+// remove expired keys under a storage TTL with those written to delete and
+// later garbage collect the same keys, as row-level TTL does.
+func BenchmarkStorageTTLExpiry(b *testing.B) {
+	skip.UnderShort(b)
+	defer log.Scope(b).Close(b)
+
+	ctx := context.Background()
+	for _, method := range []storageTTLExpiryMethod{
+		rowLevelTTLExpiry, storageTTLPointExpiry, storageTTLSpanExpiry,
+	} {
+		b.Run(fmt.Sprintf("method=%s", method), func(b *testing.B) {
+			for _, numKeys := range []int{1024, 65536} {
+				b.Run(fmt.Sprintf("numKeys=%d", numKeys), func(b *testing.B) {
+					for _, valueBytes := range []int{64, 1024} {
+						b.Run(fmt.Sprintf("valueBytes=%d", valueBytes), func(b *testing.B) {
+							runStorageTTLExpiry(ctx, b, setupPebbleInMemPebbleForLatestRelease,
+								method, numKeys, valueBytes)
+						})
+					}
+				})
+			}
+		})
+	}
+}
+
 func BenchmarkMVCCExportToSST(b *testing.B) {
 	skip.UnderShort(b)
 	defer log.Scope(b).Close(b)
@@ -1474,6 +1500,149 @@ func runMVCCGarbageCollect(
 	}
 }
 
+type storageTTLExpiryMethod int
+
+const (
+	// rowLevelTTLExpiry deletes the keys with MVCC tombstones and garbage
+	// collects them once they're below the GC threshold. It omits the intents
+	// written and resolved by the SQL deletes of row-level TTL, so it
+	// understates its cost.
+	rowLevelTTLExpiry storageTTLExpiryMethod = iota
+	// storageTTLPointExpiry expires the keys one by one with MVCCExpire.
+	storageTTLPointExpiry
+	// storageTTLSpanExpiry expires the keys with a single MVCCExpireSpan.
+	storageTTLSpanExpiry
+)
+
+func (m storageTTLExpiryMethod) String() string {
+	switch m {
+	case rowLevelTTLExpiry:
+		return "row-level-ttl"
+	case storageTTLPointExpiry:
+		return "storage-ttl-point"
+	case storageTTLSpanExpiry:
+		return "storage-ttl-span"
+	default:
+		return fmt.Sprintf("storageTTLExpiryMethod(%d)", int(m))
+	}
+}
+
+// engineBytesWritten returns the bytes written by the engine to its WAL and
+// sstables.
+func engineBytesWritten(eng Engine) uint64 {
+	m := eng.GetMetrics()
+	total := m.Total()
+	return m.WAL.BytesWritten + total.BytesFlushed + total.BytesCompacted
+}
+
+// runStorageTTLExpiry writes numKeys keys, removes them with the given method,
+// and then flushes and compacts the engine. It reports the bytes written by
+// the removal, flush and compaction per removed key.
+func runStorageTTLExpiry(
+	ctx context.Context,
+	b *testing.B,
+	emk engineMaker,
+	method storageTTLExpiryMethod,
+	numKeys, valueBytes int,
+) {
+	rng, _ := randutil.NewTestRand()
+	ts := hlc.Timestamp{}.Add(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(), 0)
+	// The keys are written at ts, deleted at ts+(1,0) by row-level TTL, and
+	// expired or garbage collected at ts+(2,0).
+	deleteTS := ts.Add(1, 0)
+	now := ts.Add(2, 0)
+
+	ttlKeys := make([]roachpb.Key, numKeys)
+	for i := range ttlKeys {
+		ttlKeys[i] = roachpb.Key(fmt.Sprintf("key-%08d", i))
+	}
+
+	var bytesWritten uint64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		eng := emk(b, "storage_ttl")
+		batch := eng.NewBatch()
+		for _, key := range ttlKeys {
+			val := roachpb.MakeValueFromBytes(randutil.RandBytes(rng, valueBytes))
+			if err := MVCCPut(ctx, batch, key, ts, val, MVCCWriteOptions{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := batch.Commit(false /* sync */); err != nil {
+			b.Fatal(err)
+		}
+		batch.Close()
+		if err := eng.Flush(); err != nil {
+			b.Fatal(err)
+		}
+		before := engineBytesWritten(eng)
+		b.StartTimer()
+
+		switch method {
+		case rowLevelTTLExpiry:
+			batch := eng.NewBatch()
+			gcKeys := make([]kvpb.GCRequest_GCKey, 0, len(ttlKeys))
+			for _, key := range ttlKeys {
+				if _, err := MVCCDelete(ctx, batch, key, deleteTS, MVCCWriteOptions{}); err != nil {
+					b.Fatal(err)
+				}
+				gcKeys = append(gcKeys, kvpb.GCRequest_GCKey{Key: key, Timestamp: deleteTS})
+			}
+			if err := batch.Commit(false /* sync */); err != nil {
+				b.Fatal(err)
+			}
+			batch.Close()
+			batch = eng.NewBatch()
+			if err := MVCCGarbageCollect(ctx, batch, &enginepb.MVCCStats{}, gcKeys, now); err != nil {
+				b.Fatal(err)
+			}
+			if err := batch.Commit(false /* sync */); err != nil {
+				b.Fatal(err)
+			}
+			batch.Close()
+		case storageTTLPointExpiry:
+			batch := eng.NewBatch()
+			expireKeys := make([]kvpb.GCRequest_GCKey, 0, len(ttlKeys))
+			for _, key := range ttlKeys {
+				expireKeys = append(expireKeys, kvpb.GCRequest_GCKey{Key: key, Timestamp: ts})
+			}
+			if _, err := MVCCExpire(ctx, batch, &enginepb.MVCCStats{}, expireKeys, now); err != nil {
+				b.Fatal(err)
+			}
+			if err := batch.Commit(false /* sync */); err != nil {
+				b.Fatal(err)
+			}
+			batch.Close()
+		case storageTTLSpanExpiry:
+			batch := eng.NewBatch()
+			if _, err := MVCCExpireSpan(ctx, batch, &enginepb.MVCCStats{}, kvpb.GCRequest_GCRangeKey{
+				StartKey:  ttlKeys[0],
+				EndKey:    ttlKeys[len(ttlKeys)-1].Next(),
+				Timestamp: ts,
+			}, now); err != nil {
+				b.Fatal(err)
+			}
+			if err := batch.Commit(false /* sync */); err != nil {
+				b.Fatal(err)
+			}
+			batch.Close()
+		}
+		if err := eng.Flush(); err != nil {
+			b.Fatal(err)
+		}
+		if err := eng.Compact(); err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		bytesWritten += engineBytesWritten(eng) - before
+		eng.Close()
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(bytesWritten)/float64(b.N*numKeys), "bytes-written/key")
+}
+
 func runBatchApplyBatchRepr(
 	ctx context.Context,
 	b *testing.B,
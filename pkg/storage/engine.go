@@ -937,6 +937,11 @@ type Engine interface {
 	// GetEnvStats retrieves stats about the engine's environment
 	// For RocksDB, this includes details of at-rest encryption.
 	GetEnvStats() (*EnvStats, error)
+	// ReEncrypt rewrites the files, such as sstables and WAL, that are
+	// encrypted under a data key generated under a store key which is no
+	// longer active, so that no file depends on the retired store key. It is
+	// an error to call ReEncrypt when encryption-at-rest is not enabled.
+	ReEncrypt(ctx context.Context, progress func(ReEncryptionProgress)) error
 	// GetAuxiliaryDir returns a path under which files can be stored
 	// persistently, and from which data can be ingested by the engine.
 	//
@@ -1355,6 +1360,20 @@ type EnvStats struct {
 	EncryptionStatus []byte
 }
 
+// ReEncryptionProgress reports the progress of Engine.ReEncrypt.
+type ReEncryptionProgress struct {
+	// TotalFiles is the number of files which were encrypted under a retired
+	// key when re-encryption began.
+	TotalFiles uint64
+	// TotalBytes is the size of the files in TotalFiles.
+	TotalBytes uint64
+	// RewrittenFiles is the number of files rewritten so far, including files
+	// that were removed by the engine before they were rewritten.
+	RewrittenFiles uint64
+	// RewrittenBytes is the size of the files in RewrittenFiles.
+	RewrittenBytes uint64
+}
+
 // EncryptionRegistries contains the encryption-related registries:
 // Both are serialized protobufs.
 type EncryptionRegistries struct {
@@ -219,6 +219,39 @@ func (f *fingerprintWriter) stripIndexPrefix(key []byte) []byte {
 	return remainder
 }
 
+// PointKeyFingerprinter fingerprints point keys the same way as an
+// ExportRequest that fingerprints its span, for callers that read the keys from
+// somewhere other than the engine, such as the files of a backup. Along with
+// FingerprintRangekeys, it computes the fingerprint that
+// crdb_internal.fingerprint returns for the same keys.
+type PointKeyFingerprinter struct {
+	fw fingerprintWriter
+}
+
+// MakePointKeyFingerprinter returns a PointKeyFingerprinter that uses the
+// given options.
+func MakePointKeyFingerprinter(opts MVCCExportFingerprintOptions) PointKeyFingerprinter {
+	return PointKeyFingerprinter{fw: fingerprintWriter{
+		hasher:  fnv.New64(),
+		xorAgg:  &uintXorAggregate{},
+		options: opts,
+	}}
+}
+
+// Add adds a point key to the fingerprint. The value must not include the
+// MVCCValue header, which ExportRequests do not export.
+func (p *PointKeyFingerprinter) Add(key MVCCKey, value []byte) error {
+	if key.Timestamp.IsEmpty() {
+		return p.fw.PutUnversioned(key.Key, value)
+	}
+	return p.fw.PutRawMVCC(key, value)
+}
+
+// Fingerprint returns the fingerprint of the point keys added so far.
+func (p *PointKeyFingerprinter) Fingerprint() uint64 {
+	return p.fw.xorAgg.result()
+}
+
 // FingerprintRangekeys iterates over the provided SSTs, that are expected to
 // contain only rangekeys, and maintains a XOR aggregate of each rangekey's
 // fingerprint.
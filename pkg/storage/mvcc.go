@@ -76,6 +76,18 @@ var minWALSyncInterval = settings.RegisterDurationSetting(
 	settings.NonNegativeDurationWithMaximum(1*time.Second),
 )
 
+// raftLogEngineMinWALSyncInterval replaces minWALSyncInterval for engines
+// opened with the RaftLogEngine option. The raft log engine syncs on every
+// append of raft entries, so it benefits from a different tradeoff between
+// latency and the number of syncs than the state engine.
+var raftLogEngineMinWALSyncInterval = settings.RegisterDurationSetting(
+	settings.SystemOnly,
+	"storage.raft_log_engine.min_wal_sync_interval",
+	"minimum duration between syncs of the WAL of a separate raft log engine",
+	0*time.Millisecond,
+	settings.NonNegativeDurationWithMaximum(1*time.Second),
+)
+
 // MVCCRangeTombstonesEnabledInMixedClusters enables writing of MVCC range
 // tombstones. Currently, this is used for schema GC and import cancellation
 // rollbacks.
@@ -6095,6 +6107,204 @@ func MVCCGarbageCollect(
 	return nil
 }
 
+// MVCCExpire removes all versions of the given keys whose newest version is at
+// or below the key's timestamp, including a live newest version. It is used
+// to expire keys under a storage TTL. Keys whose newest version is above the
+// key's timestamp, is an intent or inline value, or which are covered by an
+// MVCC range key are skipped, and left for regular garbage collection.
+//
+// The removal of a live version is logged as an expiry at the given
+// timestamp, so that rangefeeds can publish it. As the versions are removed
+// without writing a tombstone, readers at timestamps at or above the expired
+// versions will no longer see them. The number of live keys which were
+// expired is returned; the versions of deleted keys are removed too, but
+// aren't counted.
+func MVCCExpire(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	keys []kvpb.GCRequest_GCKey,
+	timestamp hlc.Timestamp,
+) (int64, error) {
+	var count int64
+	defer func() {
+		log.Eventf(ctx, "done with expiring %d keys, expired %d", len(keys), count)
+	}()
+
+	for _, k := range keys {
+		expired, err := mvccExpireKey(rw, ms, k, timestamp)
+		if err != nil {
+			return 0, err
+		}
+		if expired {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MVCCExpireSpan expires the keys in the span whose newest version is at or
+// below the span's timestamp, like MVCCExpire. If every key in the span is
+// expired, and the span contains no intents, inline values or MVCC range
+// keys, the versions are removed using a single Pebble range tombstone rather
+// than a point tombstone per version. Otherwise, the expired keys are removed
+// individually by MVCCExpire. The number of live keys which were expired is
+// returned.
+func MVCCExpireSpan(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	span kvpb.GCRequest_GCRangeKey,
+	timestamp hlc.Timestamp,
+) (int64, error) {
+	iter, err := rw.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{
+		LowerBound: span.StartKey,
+		UpperBound: span.EndKey,
+		KeyTypes:   IterKeyTypePointsAndRanges,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	start := MakeMVCCMetadataKey(span.StartKey)
+	allExpired := true
+	var expired []kvpb.GCRequest_GCKey
+	var live []roachpb.Key
+	for iter.SeekGE(start); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return 0, err
+		} else if !ok {
+			break
+		}
+		hasPoint, hasRange := iter.HasPointAndRange()
+		if hasRange {
+			allExpired = false
+		}
+		if !hasPoint || hasRange {
+			continue
+		}
+		key := iter.UnsafeKey()
+		if !key.IsValue() || span.Timestamp.Less(key.Timestamp) {
+			allExpired = false
+			continue
+		}
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return 0, err
+		}
+		isTombstone, err := EncodedMVCCValueIsTombstone(v)
+		if err != nil {
+			return 0, err
+		}
+		expired = append(expired, kvpb.GCRequest_GCKey{
+			Key:       key.Key.Clone(),
+			Timestamp: span.Timestamp,
+		})
+		if !isTombstone {
+			live = append(live, expired[len(expired)-1].Key)
+		}
+	}
+	if !allExpired {
+		return MVCCExpire(ctx, rw, ms, expired, timestamp)
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	if ms != nil {
+		iter.SeekGE(start)
+		stats, err := ComputeStatsForIter(iter, timestamp.WallTime)
+		if err != nil {
+			return 0, err
+		}
+		ms.Subtract(stats)
+	}
+	if err := rw.ClearMVCCRange(
+		span.StartKey, span.EndKey, true /* pointKeys */, false, /* rangeKeys */
+	); err != nil {
+		return 0, err
+	}
+	for _, key := range live {
+		rw.LogLogicalOp(MVCCExpireValueOpType, MVCCLogicalOpDetails{
+			Key:       key,
+			Timestamp: timestamp,
+		})
+	}
+	log.Eventf(ctx, "done with expiring span %s, expired %d", roachpb.Span{
+		Key: span.StartKey, EndKey: span.EndKey,
+	}, len(live))
+	return int64(len(live)), nil
+}
+
+func mvccExpireKey(
+	rw ReadWriter, ms *enginepb.MVCCStats, k kvpb.GCRequest_GCKey, timestamp hlc.Timestamp,
+) (bool, error) {
+	iter, err := rw.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{
+		LowerBound: k.Key,
+		UpperBound: k.Key.Next(),
+		KeyTypes:   IterKeyTypePointsAndRanges,
+	})
+	if err != nil {
+		return false, err
+	}
+	defer iter.Close()
+
+	start := MakeMVCCMetadataKey(k.Key)
+	iter.SeekGE(start)
+	if ok, err := iter.Valid(); err != nil || !ok {
+		return false, err
+	}
+	// The range keys are truncated to the iterator bounds, so any range key
+	// overlapping the key is found at the first position.
+	if hasPoint, hasRange := iter.HasPointAndRange(); !hasPoint || hasRange {
+		return false, nil
+	}
+	newest := iter.UnsafeKey()
+	if !newest.IsValue() || k.Timestamp.Less(newest.Timestamp) {
+		return false, nil
+	}
+	v, err := iter.UnsafeValue()
+	if err != nil {
+		return false, err
+	}
+	isTombstone, err := EncodedMVCCValueIsTombstone(v)
+	if err != nil {
+		return false, err
+	}
+
+	if ms != nil {
+		stats, err := ComputeStatsForIter(iter, timestamp.WallTime)
+		if err != nil {
+			return false, err
+		}
+		ms.Subtract(stats)
+		iter.SeekGE(start)
+	}
+	for ; ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return false, err
+		} else if !ok {
+			break
+		}
+		if err := rw.ClearMVCC(iter.UnsafeKey(), ClearOptions{
+			ValueSizeKnown: true,
+			ValueSize:      uint32(iter.ValueLen()),
+		}); err != nil {
+			return false, err
+		}
+	}
+
+	if isTombstone {
+		return false, nil
+	}
+	rw.LogLogicalOp(MVCCExpireValueOpType, MVCCLogicalOpDetails{
+		Key:       k.Key,
+		Timestamp: timestamp,
+	})
+	return true, nil
+}
+
 // CollectableGCRangeKey is a struct containing range key as well as span
 // boundaries locked for particular range key.
 // Range GC needs a latch span as it needs to expand iteration beyond the
@@ -47,6 +47,8 @@ const (
 	MVCCAbortIntentOpType
 	// MVCCDeleteRangeOpType corresponds to the MVCCDeleteRangeOp variant.
 	MVCCDeleteRangeOpType
+	// MVCCExpireValueOpType corresponds to the MVCCExpireValueOp variant.
+	MVCCExpireValueOpType
 )
 
 // MVCCLogicalOpDetails contains details about the occurrence of an MVCC logical
@@ -156,6 +158,14 @@ func (ol *OpLoggerBatch) logLogicalOp(op MVCCLogicalOpType, details MVCCLogicalO
 			EndKey:    details.EndKey,
 			Timestamp: details.Timestamp,
 		})
+	case MVCCExpireValueOpType:
+		if !details.Safe {
+			ol.opsAlloc, details.Key = ol.opsAlloc.Copy(details.Key, 0)
+		}
+		ol.recordOp(&enginepb.MVCCExpireValueOp{
+			Key:       details.Key,
+			Timestamp: details.Timestamp,
+		})
 	default:
 		panic(fmt.Sprintf("unexpected op type %v", op))
 	}
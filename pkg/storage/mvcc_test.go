@@ -4897,6 +4897,165 @@ func TestMVCCGarbageCollect(t *testing.T) {
 	require.NoError(t, engine.Compact())
 }
 
+// TestMVCCExpire verifies that expiring a key removes all of its versions,
+// including a live newest version, logs the removal of the live version, and
+// keeps the stats accurate.
+func TestMVCCExpire(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	engine := NewDefaultInMemForTesting()
+	defer engine.Close()
+
+	ms := &enginepb.MVCCStats{}
+	ts1 := hlc.Timestamp{WallTime: 1e9}
+	ts2 := hlc.Timestamp{WallTime: 2e9}
+	ts3 := hlc.Timestamp{WallTime: 3e9}
+	now := hlc.Timestamp{WallTime: 5e9}
+	val := roachpb.MakeValueFromString("value")
+
+	put := func(key string, ts hlc.Timestamp) {
+		require.NoError(t, MVCCPut(ctx, engine, roachpb.Key(key), ts, val, MVCCWriteOptions{Stats: ms}))
+	}
+	put("a", ts1)
+	put("a", ts2)
+	put("b", ts1)
+	put("b", ts3)
+	put("c", ts1)
+	_, err := MVCCDelete(ctx, engine, roachpb.Key("c"), ts2, MVCCWriteOptions{Stats: ms})
+	require.NoError(t, err)
+	txn := makeTxn(*txn1, ts2)
+	put("d", ts1)
+	require.NoError(t, MVCCPut(ctx, engine, roachpb.Key("d"), txn.WriteTimestamp, val,
+		MVCCWriteOptions{Txn: txn, Stats: ms}))
+	put("e", ts1)
+	require.NoError(t, MVCCDeleteRangeUsingTombstone(ctx, engine, ms, roachpb.Key("e"),
+		roachpb.Key("f"), ts2, hlc.ClockTimestamp{}, nil, nil, false, 0, nil))
+
+	batch := engine.NewBatch()
+	defer batch.Close()
+	ol := NewOpLoggerBatch(batch)
+	expired, err := MVCCExpire(ctx, ol, ms, []kvpb.GCRequest_GCKey{
+		// Live, all versions expire.
+		{Key: roachpb.Key("a"), Timestamp: ts2},
+		// The newest version is above the timestamp, nothing expires.
+		{Key: roachpb.Key("b"), Timestamp: ts2},
+		// Deleted, all versions are removed but there is nothing to log.
+		{Key: roachpb.Key("c"), Timestamp: ts2},
+		// Intents and keys covered by range keys are skipped.
+		{Key: roachpb.Key("d"), Timestamp: ts2},
+		{Key: roachpb.Key("e"), Timestamp: ts2},
+		// Keys that don't exist are a no-op.
+		{Key: roachpb.Key("x"), Timestamp: ts2},
+	}, now)
+	require.NoError(t, err)
+	// Only a was live.
+	require.EqualValues(t, 1, expired)
+	require.NoError(t, batch.Commit(true /* sync */))
+
+	var expOp enginepb.MVCCLogicalOp
+	expOp.MustSetValue(&enginepb.MVCCExpireValueOp{Key: roachpb.Key("a"), Timestamp: now})
+	require.Equal(t, []enginepb.MVCCLogicalOp{expOp}, ol.LogicalOps())
+
+	kvs, err := Scan(engine, localMax, keyMax, 0)
+	require.NoError(t, err)
+	var gotKeys []MVCCKey
+	for _, kv := range kvs {
+		gotKeys = append(gotKeys, kv.Key)
+	}
+	require.Equal(t, []MVCCKey{
+		mvccVersionKey(roachpb.Key("b"), ts3),
+		mvccVersionKey(roachpb.Key("b"), ts1),
+		MakeMVCCMetadataKey(roachpb.Key("d")),
+		mvccVersionKey(roachpb.Key("d"), ts2),
+		mvccVersionKey(roachpb.Key("d"), ts1),
+		mvccVersionKey(roachpb.Key("e"), ts1),
+	}, gotKeys)
+
+	expMS, err := ComputeStats(engine, localMax, roachpb.KeyMax, now.WallTime)
+	require.NoError(t, err)
+	assertEq(t, engine, "after expire", ms, &expMS)
+}
+
+// TestMVCCExpireSpan verifies that expiring a span whose keys are all expired
+// clears it with a range tombstone, and that a span with a key which isn't
+// expired falls back to expiring the expired keys individually.
+func TestMVCCExpireSpan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	engine := NewDefaultInMemForTesting()
+	defer engine.Close()
+
+	ms := &enginepb.MVCCStats{}
+	ts1 := hlc.Timestamp{WallTime: 1e9}
+	ts2 := hlc.Timestamp{WallTime: 2e9}
+	ts3 := hlc.Timestamp{WallTime: 3e9}
+	now := hlc.Timestamp{WallTime: 5e9}
+	val := roachpb.MakeValueFromString("value")
+
+	put := func(key string, ts hlc.Timestamp) {
+		require.NoError(t, MVCCPut(ctx, engine, roachpb.Key(key), ts, val, MVCCWriteOptions{Stats: ms}))
+	}
+	// a-d are all expired at ts2, c is deleted.
+	put("a", ts1)
+	put("a", ts2)
+	put("b", ts1)
+	put("c", ts1)
+	_, err := MVCCDelete(ctx, engine, roachpb.Key("c"), ts2, MVCCWriteOptions{Stats: ms})
+	require.NoError(t, err)
+	put("d", ts2)
+	// In e-h, g was written after ts2.
+	put("e", ts1)
+	put("f", ts1)
+	put("g", ts3)
+	put("h", ts1)
+
+	expire := func(start, end string) ([]enginepb.MVCCLogicalOp, int64) {
+		batch := engine.NewBatch()
+		defer batch.Close()
+		ol := NewOpLoggerBatch(batch)
+		expired, err := MVCCExpireSpan(ctx, ol, ms, kvpb.GCRequest_GCRangeKey{
+			StartKey:  roachpb.Key(start),
+			EndKey:    roachpb.Key(end),
+			Timestamp: ts2,
+		}, now)
+		require.NoError(t, err)
+		require.NoError(t, batch.Commit(true /* sync */))
+		return ol.LogicalOps(), expired
+	}
+	expOps := func(keys ...string) []enginepb.MVCCLogicalOp {
+		var ops []enginepb.MVCCLogicalOp
+		for _, key := range keys {
+			var op enginepb.MVCCLogicalOp
+			op.MustSetValue(&enginepb.MVCCExpireValueOp{Key: roachpb.Key(key), Timestamp: now})
+			ops = append(ops, op)
+		}
+		return ops
+	}
+
+	ops, expired := expire("a", "e")
+	require.EqualValues(t, 3, expired)
+	require.Equal(t, expOps("a", "b", "d"), ops)
+	ops, expired = expire("e", "z")
+	require.EqualValues(t, 3, expired)
+	require.Equal(t, expOps("e", "f", "h"), ops)
+
+	kvs, err := Scan(engine, localMax, keyMax, 0)
+	require.NoError(t, err)
+	var gotKeys []MVCCKey
+	for _, kv := range kvs {
+		gotKeys = append(gotKeys, kv.Key)
+	}
+	require.Equal(t, []MVCCKey{mvccVersionKey(roachpb.Key("g"), ts3)}, gotKeys)
+
+	expMS, err := ComputeStats(engine, localMax, roachpb.KeyMax, now.WallTime)
+	require.NoError(t, err)
+	assertEq(t, engine, "after expire", ms, &expMS)
+}
+
 // TestMVCCGarbageCollectNonDeleted verifies that the first value for
 // a key cannot be GC'd if it's not deleted.
 func TestMVCCGarbageCollectNonDeleted(t *testing.T) {
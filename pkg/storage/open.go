@@ -64,6 +64,14 @@ var ForceWriterParallelism ConfigOption = func(cfg *engineConfig) error {
 	return nil
 }
 
+// RaftLogEngine configures an engine that holds only the raft logs of a store,
+// separately from its state engine. Its WAL sync policy is controlled by the
+// storage.raft_log_engine.min_wal_sync_interval setting.
+var RaftLogEngine ConfigOption = func(cfg *engineConfig) error {
+	cfg.minWALSyncInterval = raftLogEngineMinWALSyncInterval
+	return nil
+}
+
 // ForTesting configures the engine for use in testing. It may randomize some
 // config options to improve test coverage.
 var ForTesting ConfigOption = func(cfg *engineConfig) error {
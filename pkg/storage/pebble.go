@@ -763,6 +763,9 @@ type PebbleConfig struct {
 
 	// onClose is a slice of functions to be invoked before the engine is closed.
 	onClose []func(*Pebble)
+	// minWALSyncInterval, if set, overrides the rocksdb.min_wal_sync_interval
+	// setting for this engine.
+	minWALSyncInterval *settings.DurationSetting
 }
 
 // EncryptionStatsHandler provides encryption related stats.
@@ -873,6 +876,17 @@ type EncryptionEnv struct {
 	FS vfs.FS
 	// StatsHandler exposes encryption-at-rest state for observability.
 	StatsHandler EncryptionStatsHandler
+	// ReEncrypter rewrites files encrypted under retired keys.
+	ReEncrypter EncryptionReEncrypter
+}
+
+// EncryptionReEncrypter rewrites the files of an encryption-at-rest
+// environment that are encrypted under a data key generated under a store key
+// that is no longer active.
+type EncryptionReEncrypter interface {
+	// ReEncrypt rewrites the files encrypted under a retired key under the
+	// active data key, calling progress after each file is rewritten.
+	ReEncrypt(ctx context.Context, progress func(ReEncryptionProgress)) error
 }
 
 var _ Engine = &Pebble{}
@@ -1054,8 +1068,12 @@ func NewPebble(ctx context.Context, cfg PebbleConfig) (p *Pebble, err error) {
 	logCtx = logtags.AddTag(logCtx, "pebble", nil)
 
 	opts.ErrorIfNotExists = cfg.MustExist
+	walSyncInterval := minWALSyncInterval
+	if cfg.minWALSyncInterval != nil {
+		walSyncInterval = cfg.minWALSyncInterval
+	}
 	opts.WALMinSyncInterval = func() time.Duration {
-		return minWALSyncInterval.Get(&cfg.Settings.SV)
+		return walSyncInterval.Get(&cfg.Settings.SV)
 	}
 	opts.Experimental.EnableValueBlocks = func() bool {
 		version := cfg.Settings.Version.ActiveVersionOrEmpty(logCtx)
@@ -1993,6 +2011,19 @@ func (p *Pebble) GetEncryptionRegistries() (*EncryptionRegistries, error) {
 	return rv, nil
 }
 
+// ReEncrypt implements the Engine interface.
+func (p *Pebble) ReEncrypt(ctx context.Context, progress func(ReEncryptionProgress)) error {
+	if p.encryption == nil || p.encryption.ReEncrypter == nil {
+		return errors.New("encryption-at-rest is not enabled on this store")
+	}
+	// Flushing the memtables makes the WAL files written before the store key
+	// was rotated obsolete, so that they're removed rather than rewritten.
+	if err := p.db.Flush(); err != nil {
+		return err
+	}
+	return p.encryption.ReEncrypter.ReEncrypt(ctx, progress)
+}
+
 // GetEnvStats implements the Engine interface.
 func (p *Pebble) GetEnvStats() (*EnvStats, error) {
 	// TODO(sumeer): make the stats complete. There are no bytes stats. The TotalFiles is missing
@@ -0,0 +1,161 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"github.com/apache/arrow/go/v11/parquet"
+	"github.com/apache/arrow/go/v11/parquet/file"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+// Read reads a parquet file and returns the contained metadata and datums.
+//
+// To use this function, the Writer must be configured to write CRDB-specific
+// metadata for the reader. See WithReaderMetadata.
+//
+// NB: The returned datums may not be hydrated or identical to the ones
+// which were written. See comment on ValidateDatum for more info.
+func Read(r parquet.ReaderAtSeeker) (meta ReadDatumsMetadata, datums [][]tree.Datum, err error) {
+	reader, err := file.NewParquetReader(r)
+	if err != nil {
+		return ReadDatumsMetadata{}, nil, err
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			err = errors.CombineErrors(err, closeErr)
+		}
+	}()
+
+	var readDatums [][]tree.Datum
+
+	typFamiliesMeta := reader.MetaData().KeyValueMetadata().FindValue(typeFamilyMetaKey)
+	if typFamiliesMeta == nil {
+		return ReadDatumsMetadata{}, nil,
+			errors.AssertionFailedf("missing type family metadata. ensure the writer is configured" +
+				" to write reader metadata. see WithReaderMetadata()")
+	}
+	typFamilies, err := deserializeIntArray(*typFamiliesMeta)
+	if err != nil {
+		return ReadDatumsMetadata{}, nil, err
+	}
+
+	tupleColumnsMeta := reader.MetaData().KeyValueMetadata().FindValue(tupleIndexesMetaKey)
+	if tupleColumnsMeta == nil {
+		return ReadDatumsMetadata{}, nil,
+			errors.AssertionFailedf("missing tuple index metadata. ensure the writer is configured" +
+				" to write reader metadata. see WithReaderMetadata()")
+	}
+	tupleColumns, err := deserialize2DIntArray(*tupleColumnsMeta)
+	if err != nil {
+		return ReadDatumsMetadata{}, nil, err
+	}
+
+	typOidsMeta := reader.MetaData().KeyValueMetadata().FindValue(typeOidMetaKey)
+	if typOidsMeta == nil {
+		return ReadDatumsMetadata{}, nil,
+			errors.AssertionFailedf("missing type oid metadata. ensure the writer is configured" +
+				" to write reader metadata. see WithReaderMetadata()")
+	}
+	typOids, err := deserializeIntArray(*typOidsMeta)
+	if err != nil {
+		return ReadDatumsMetadata{}, nil, err
+	}
+
+	colNames := make([]string, 0, reader.MetaData().Schema.NumColumns())
+	for colIdx := 0; colIdx < reader.MetaData().Schema.NumColumns(); colIdx++ {
+		colNames = append(colNames, reader.MetaData().Schema.Column(colIdx).Name())
+	}
+
+	startingRowIdx := 0
+	for rg := 0; rg < reader.NumRowGroups(); rg++ {
+		rgr := reader.RowGroup(rg)
+		rowsInRowGroup := rgr.NumRows()
+		for i := int64(0); i < rowsInRowGroup; i++ {
+			readDatums = append(readDatums, make([]tree.Datum, rgr.NumColumns()))
+		}
+
+		for colIdx := 0; colIdx < rgr.NumColumns(); colIdx++ {
+			col, err := rgr.Column(colIdx)
+			if err != nil {
+				return ReadDatumsMetadata{}, nil, err
+			}
+
+			dec, err := decoderFromFamilyAndType(oid.Oid(typOids[colIdx]), types.Family(typFamilies[colIdx]))
+			if err != nil {
+				return ReadDatumsMetadata{}, nil, err
+			}
+
+			// Based on how we define the schemas for these columns, we can determine if they are arrays or
+			// part of tuples. See comments above arrayEntryNonNilDefLevel and tupleFieldNonNilDefLevel for
+			// more info.
+			isArray := col.Descriptor().MaxDefinitionLevel() == 3
+			isTuple := col.Descriptor().MaxDefinitionLevel() == 2
+
+			datumsForColInRowGroup, err := readColInRowGroup(col, dec, rowsInRowGroup, isArray, isTuple)
+			if err != nil {
+				return ReadDatumsMetadata{}, nil, err
+			}
+			decodeValuesIntoDatumsHelper(datumsForColInRowGroup, readDatums, colIdx, startingRowIdx)
+		}
+		startingRowIdx += int(rowsInRowGroup)
+	}
+
+	for i := 0; i < len(readDatums); i++ {
+		readDatums[i] = squashTuples(readDatums[i], tupleColumns, colNames)
+	}
+
+	meta = makeDatumMeta(reader, readDatums)
+	meta.ColumnNames, meta.ColumnTypes = scalarColumns(reader, typOids, typFamilies)
+	return meta, readDatums, nil
+}
+
+// scalarColumns returns the name and type of each column in the file, or nil
+// if the file has any array or tuple columns, whose types are not fully
+// recorded in the file's metadata.
+func scalarColumns(reader *file.Reader, typOids, typFamilies []int) ([]string, []*types.T) {
+	sch := reader.MetaData().Schema
+	names := make([]string, 0, sch.NumColumns())
+	typs := make([]*types.T, 0, sch.NumColumns())
+	for colIdx := 0; colIdx < sch.NumColumns(); colIdx++ {
+		col := sch.Column(colIdx)
+		if col.MaxDefinitionLevel() != 1 {
+			return nil, nil
+		}
+		typ, err := typeFromOidAndFamily(oid.Oid(typOids[colIdx]), types.Family(typFamilies[colIdx]))
+		if err != nil {
+			return nil, nil
+		}
+		names = append(names, col.Name())
+		typs = append(typs, typ)
+	}
+	return names, typs
+}
+
+// typeFromOidAndFamily returns a type with the given oid and family. The type
+// is only suitable for writing the datums returned by Read back to parquet:
+// user defined types and collated strings are not hydrated, since their
+// metadata is not recorded in the file.
+func typeFromOidAndFamily(typOid oid.Oid, family types.Family) (*types.T, error) {
+	switch family {
+	case types.EnumFamily:
+		return types.MakeEnum(typOid, 0), nil
+	case types.CollatedStringFamily:
+		return types.MakeCollatedString(types.String, ""), nil
+	}
+	typ, ok := types.OidToType[typOid]
+	if !ok || typ.Family() != family {
+		return nil, errors.AssertionFailedf("could not determine type from oid %d and family %d", typOid, family)
+	}
+	return typ, nil
+}
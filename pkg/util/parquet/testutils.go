@@ -25,7 +25,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/errors"
-	"github.com/lib/pq/oid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,7 +58,7 @@ func ReadFileAndVerifyDatums(
 // ReadFile reads a parquet file and returns the contained metadata and datums.
 //
 // To use this function, the Writer must be configured to write CRDB-specific
-// metadata for the reader. See NewWriter() and buildutil.CrdbTestBuild.
+// metadata for the reader. See Read.
 //
 // NB: The returned datums may not be hydrated or identical to the ones
 // which were written. See comment on ValidateDatum for more info.
@@ -68,94 +67,7 @@ func ReadFile(parquetFile string) (meta ReadDatumsMetadata, datums [][]tree.Datu
 	if err != nil {
 		return ReadDatumsMetadata{}, nil, err
 	}
-
-	reader, err := file.NewParquetReader(f)
-	if err != nil {
-		return ReadDatumsMetadata{}, nil, err
-	}
-	defer func() {
-		if closeErr := reader.Close(); closeErr != nil {
-			err = errors.CombineErrors(err, closeErr)
-		}
-	}()
-
-	var readDatums [][]tree.Datum
-
-	typFamiliesMeta := reader.MetaData().KeyValueMetadata().FindValue(typeFamilyMetaKey)
-	if typFamiliesMeta == nil {
-		return ReadDatumsMetadata{}, nil,
-			errors.AssertionFailedf("missing type family metadata. ensure the writer is configured" +
-				" to write reader metadata. see NewWriter() and buildutil.CrdbTestBuild")
-	}
-	typFamilies, err := deserializeIntArray(*typFamiliesMeta)
-	if err != nil {
-		return ReadDatumsMetadata{}, nil, err
-	}
-
-	tupleColumnsMeta := reader.MetaData().KeyValueMetadata().FindValue(tupleIndexesMetaKey)
-	tupleColumns, err := deserialize2DIntArray(*tupleColumnsMeta)
-	if err != nil {
-		return ReadDatumsMetadata{}, nil, err
-	}
-
-	typOidsMeta := reader.MetaData().KeyValueMetadata().FindValue(typeOidMetaKey)
-	if typOidsMeta == nil {
-		return ReadDatumsMetadata{}, nil,
-			errors.AssertionFailedf("missing type oid metadata. ensure the writer is configured" +
-				" to write reader metadata. see NewWriter() and buildutil.CrdbTestBuild)")
-	}
-	typOids, err := deserializeIntArray(*typOidsMeta)
-	if err != nil {
-		return ReadDatumsMetadata{}, nil, err
-	}
-
-	var colNames []string
-	startingRowIdx := 0
-	for rg := 0; rg < reader.NumRowGroups(); rg++ {
-		rgr := reader.RowGroup(rg)
-		rowsInRowGroup := rgr.NumRows()
-		for i := int64(0); i < rowsInRowGroup; i++ {
-			readDatums = append(readDatums, make([]tree.Datum, rgr.NumColumns()))
-		}
-		if rg == 0 {
-			colNames = make([]string, 0, rgr.NumColumns())
-		}
-
-		for colIdx := 0; colIdx < rgr.NumColumns(); colIdx++ {
-			col, err := rgr.Column(colIdx)
-			if err != nil {
-				return ReadDatumsMetadata{}, nil, err
-			}
-
-			if rg == 0 {
-				colNames = append(colNames, col.Descriptor().Name())
-			}
-
-			dec, err := decoderFromFamilyAndType(oid.Oid(typOids[colIdx]), types.Family(typFamilies[colIdx]))
-			if err != nil {
-				return ReadDatumsMetadata{}, nil, err
-			}
-
-			// Based on how we define the schemas for these columns, we can determine if they are arrays or
-			// part of tuples. See comments above arrayEntryNonNilDefLevel and tupleFieldNonNilDefLevel for
-			// more info.
-			isArray := col.Descriptor().MaxDefinitionLevel() == 3
-			isTuple := col.Descriptor().MaxDefinitionLevel() == 2
-
-			datumsForColInRowGroup, err := readColInRowGroup(col, dec, rowsInRowGroup, isArray, isTuple)
-			if err != nil {
-				return ReadDatumsMetadata{}, nil, err
-			}
-			decodeValuesIntoDatumsHelper(datumsForColInRowGroup, readDatums, colIdx, startingRowIdx)
-		}
-		startingRowIdx += int(rowsInRowGroup)
-	}
-
-	for i := 0; i < len(readDatums); i++ {
-		readDatums[i] = squashTuples(readDatums[i], tupleColumns, colNames)
-	}
-
-	return makeDatumMeta(reader, readDatums), readDatums, nil
+	return Read(f)
 }
 
 // ReadDatumsMetadata contains metadata from the parquet file which was read.
@@ -169,6 +81,11 @@ type ReadDatumsMetadata struct {
 	NumCols int
 	// NumRowGroups is the number of row groups in the file.
 	NumRowGroups int
+	// ColumnNames and ColumnTypes are the name and type of each column in the
+	// file. They are only set by Read, and only if the file has no array or
+	// tuple columns.
+	ColumnNames []string
+	ColumnTypes []*types.T
 }
 
 func makeDatumMeta(reader *file.Reader, readDatums [][]tree.Datum) ReadDatumsMetadata {
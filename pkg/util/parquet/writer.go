@@ -27,6 +27,7 @@ type config struct {
 	maxRowGroupLength int64
 	version           parquet.Version
 	compression       compress.Compression
+	readerMetadata    bool
 
 	// Arbitrary kv metadata.
 	metadata metadata.KeyValueMetadata
@@ -92,6 +93,16 @@ func WithMetadata(m map[string]string) Option {
 	}
 }
 
+// WithReaderMetadata configures the Writer to write the CRDB-specific
+// metadata which is required to read the file back into datums with Read.
+// This metadata is always written in test builds.
+func WithReaderMetadata() Option {
+	return func(c *config) error {
+		c.readerMetadata = true
+		return nil
+	}
+}
+
 var allowedVersions = map[string]parquet.Version{
 	"v1.0": parquet.V1_0,
 	"v2.4": parquet.V1_0,
@@ -180,9 +191,8 @@ func NewWriter(sch *SchemaDefinition, sink io.Writer, opts ...Option) (*Writer,
 			return nil, err
 		}
 	}
-	// Add additional metadata required to use the reader utility functions in
-	// testutils.go.
-	if buildutil.CrdbTestBuild {
+	// Add additional metadata required to read the file with Read.
+	if buildutil.CrdbTestBuild || cfg.readerMetadata {
 		if err := WithMetadata(MakeReaderMetadata(sch)).apply(&cfg); err != nil {
 			return nil, err
 		}